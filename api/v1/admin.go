@@ -0,0 +1,49 @@
+// 在该模块中定义管理员接口的请求和响应结构体
+
+package v1
+
+// AdminNodeItem 节点列表项
+type AdminNodeItem struct {
+	NodeId           string  `json:"nodeId" example:"node_3kTMd92x"`
+	Region           string  `json:"region" example:"cn-east"`
+	PublicAddr       string  `json:"publicAddr" example:"203.0.113.10:7777"`
+	Capacity         int     `json:"capacity" example:"500"`
+	Version          string  `json:"version" example:"1.4.2"`
+	Status           string  `json:"status" example:"online"` // online/offline
+	Draining         bool    `json:"draining" example:"false"`
+	LastHeartbeat    string  `json:"lastHeartbeat" example:"2025-06-01 12:00:00"`
+	ClientsConnected int     `json:"clientsConnected" example:"120"`
+	CpuUsage         float64 `json:"cpuUsage" example:"35.5"`
+	MemUsage         float64 `json:"memUsage" example:"42.1"`
+	RxBps            int64   `json:"rxBps" example:"1048576"`
+	TxBps            int64   `json:"txBps" example:"2097152"`
+}
+
+type ListNodesResponseData struct {
+	Nodes []AdminNodeItem `json:"nodes"`
+}
+
+type ListNodesResponse struct {
+	Response
+	Data ListNodesResponseData
+}
+
+// CreateBootstrapTokenRequest 生成节点注册使用的一次性引导令牌
+type CreateBootstrapTokenRequest struct {
+	Region string `json:"region" example:"cn-east"`
+}
+
+type CreateBootstrapTokenResponseData struct {
+	Token     string `json:"token" example:"5f2b9c..."` // 令牌只返回一次，服务端仅保存哈希
+	ExpiresAt string `json:"expiresAt" example:"2025-06-02 12:00:00"`
+}
+
+type CreateBootstrapTokenResponse struct {
+	Response
+	Data CreateBootstrapTokenResponseData
+}
+
+// DrainNodeRequest 设置节点下线状态
+type DrainNodeRequest struct {
+	Draining bool `json:"draining" example:"true"`
+}
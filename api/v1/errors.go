@@ -18,4 +18,30 @@ var (
 	ErrVnetClientsLimitExceeded = newError(1006, "VNet clients limit would be exceeded after downgrade.")
 	ErrOriginalPasswordNotMatch = newError(1007, "The original password does not match.")
 	ErrUsernameConflict         = newError(1008, "Username is already in use by another user.")
+	ErrTrafficExhausted         = newError(1009, "Remaining traffic is exhausted, please top up first.")
+	ErrVnetClientsFull          = newError(1010, "The vnet has reached its clients limit.")
+	ErrVnetAddressExhausted     = newError(1011, "No free virtual IP address left in the vnet.")
+	ErrVirtualIpUnavailable     = newError(1012, "The virtual IP is outside the vnet range or already in use.")
+	ErrInvalidIpRange           = newError(1013, "The IP range must be a private CIDR of /16 to /30 (IPv6 /64 to /120).")
+	ErrIpRangeOverlap           = newError(1014, "The IP range overlaps another vnet of yours.")
+	ErrIpRangeInUse             = newError(1015, "Cannot change the IP range while clients are online, disable the vnet first.")
+	ErrIpPoolExhausted          = newError(1016, "No free subnet left in the address pool, please specify an IP range.")
+	ErrNodeStatic               = newError(1017, "The node is configured statically, remove it from node.keys instead.")
+	ErrInvalidAclRule           = newError(1018, "The ACL rule is invalid, check its source, destination, protocol and ports.")
+	ErrAclRuleLimitExceeded     = newError(1019, "The vnet has reached the ACL rules limit of your plan.")
+	ErrMemberPendingApproval    = newError(1020, "The device is waiting for the owner's approval.")
+	ErrMemberBanned             = newError(1021, "The device or its address has been banned from this vnet.")
+	ErrInviteUnavailable        = newError(1022, "The invite has expired, been revoked or reached its usage limit.")
+	ErrVnetNodeUnassigned       = newError(1023, "The vnet has not been assigned to a node yet, enable it and try again later.")
+	ErrVnetTypeMismatch         = newError(1024, "The operation is not available for this type of vnet.")
+	ErrInvalidWireGuardKey      = newError(1025, "The WireGuard public key is invalid or already registered by another device.")
+	ErrInvalidRoute             = newError(1026, "The route prefix is invalid, use a CIDR such as 192.168.10.0/24 or 0.0.0.0/0 for an exit node.")
+	ErrRouteConflict            = newError(1027, "The route overlaps the vnet IP range or another approved route.")
+	ErrRouteLimitExceeded       = newError(1028, "The vnet has reached its routes limit.")
+	ErrInvalidPaymentSignature  = newError(1029, "The payment notification signature is invalid.")
+	ErrOrderStatus              = newError(1030, "The order cannot be changed in its current status.")
+	ErrPlanFeatureUnavailable   = newError(1031, "Your plan does not include this feature, upgrade to use it.")
+	ErrClientIdInUse            = newError(1032, "The client ID has already redeemed an invite, submit its current invite key to redeem again.")
+	ErrDeviceKeyInvalid         = newError(1033, "The device key is missing or does not match the device registered under this client ID.")
+	ErrPaymentUnavailable       = newError(1034, "Online payment is not available at the moment, please contact support.")
 )
@@ -0,0 +1,157 @@
+// 在该模块中定义中继节点（relay/supernode）控制面的请求和响应结构体
+// 这些结构体同时用于 gRPC 接口（JSON 编码）与面向节点的 HTTP 接口
+
+package v1
+
+// NodeVnetConfig 下发给节点的虚拟网络完整配置
+type NodeVnetConfig struct {
+	VnetId       string          `json:"vnetId" example:"1234"`
+	Type         string          `json:"type" example:"n2n"` // n2n 或 wireguard，WireGuard 虚拟网络不使用令牌与密码，按 Peers 中登记的公钥接入
+	Enabled      bool            `json:"enabled" example:"true"`
+	Token        string          `json:"token" example:"1234"`
+	IpRange      string          `json:"ipRange" example:"192.168.1.0/24"`
+	EnableDHCP   bool            `json:"enableDHCP" example:"true"`
+	ClientsLimit int             `json:"clientsLimit" example:"10"`
+	Revision     int64           `json:"revision" example:"3"`
+	Acl          *NodeVnetAcl    `json:"acl,omitempty"`    // 成员之间的访问控制规则，为空表示不限制
+	Peers        *NodeVnetPeers  `json:"peers,omitempty"`  // WireGuard 虚拟网络的对端列表，其他类型为空
+	Routes       *NodeVnetRoutes `json:"routes,omitempty"` // 已批准的子网路由与出口节点，为空表示没有路由
+}
+
+// NodeVnetRoutes 编译后的路由表
+// 发往 Prefix 的流量转发给通告该路由的设备；WireGuard 虚拟网络中路由前缀同时加入该设备的 AllowedIps
+type NodeVnetRoutes struct {
+	Revision int64       `json:"revision" example:"2"`
+	Routes   []NodeRoute `json:"routes"`
+}
+
+// NodeRoute 单条路由
+type NodeRoute struct {
+	Prefix   string `json:"prefix" example:"192.168.10.0/24"`
+	ClientId string `json:"clientId" example:"client_1"`    // 下一跳设备
+	Exit     bool   `json:"exit,omitempty" example:"false"` // 出口节点，前缀为默认路由
+}
+
+// NodeVnetPeers WireGuard 虚拟网络的对端列表
+// 节点作为中心与每台设备建立隧道，并在设备之间按 AllowedIps 转发流量
+type NodeVnetPeers struct {
+	Revision int64               `json:"revision" example:"3"`
+	Peers    []NodeWireGuardPeer `json:"peers"`
+}
+
+// NodeWireGuardPeer 单台设备的 WireGuard 对端配置
+type NodeWireGuardPeer struct {
+	ClientId   string   `json:"clientId" example:"client_1"`
+	PublicKey  string   `json:"publicKey" example:"xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg="`
+	AllowedIps []string `json:"allowedIps" example:"10.0.0.2/32"`
+}
+
+// NodeVnetAcl 编译后的访问控制规则
+// 节点按顺序匹配，第一条匹配的规则决定放行或拒绝，没有规则匹配时放行
+type NodeVnetAcl struct {
+	Revision int64         `json:"revision" example:"2"`
+	Rules    []NodeAclRule `json:"rules"`
+}
+
+// NodeAclRule 编译后的单条规则，标签已展开为设备标识
+type NodeAclRule struct {
+	Action      string        `json:"action" example:"deny"`
+	Source      NodeAclTarget `json:"source"`
+	Destination NodeAclTarget `json:"destination"`
+	Protocol    string        `json:"protocol" example:"tcp"`
+	PortFrom    int           `json:"portFrom" example:"22"` // 目标端口范围，均为 0 表示所有端口
+	PortTo      int           `json:"portTo" example:"22"`
+}
+
+// NodeAclTarget 规则匹配的成员，满足任一条件即匹配
+type NodeAclTarget struct {
+	Any       bool     `json:"any,omitempty" example:"false"`
+	ClientIds []string `json:"clientIds,omitempty"`
+	Cidrs     []string `json:"cidrs,omitempty"`
+}
+
+// NodeVnetSummary 节点虚拟网络列表项
+type NodeVnetSummary struct {
+	VnetId     string `json:"vnetId" example:"1234"`
+	Enabled    bool   `json:"enabled" example:"true"`
+	Revision   int64  `json:"revision" example:"3"`
+	NeedUpdate bool   `json:"needUpdate" example:"true"`
+}
+
+type ListNodeVnetsRequest struct {
+}
+
+type ListNodeVnetsResponseData struct {
+	Vnets    []NodeVnetSummary `json:"vnets"`
+	Revision int64             `json:"revision" example:"42"` // 列表对应的事件版本号，可作为订阅变更的起点
+}
+
+type GetNodeVnetConfigRequest struct {
+	VnetId string `json:"vnetId" binding:"required" example:"1234"`
+}
+
+type GetNodeVnetConfigResponseData struct {
+	Config NodeVnetConfig `json:"config"`
+}
+
+// AckNodeVnetConfigRequest 节点确认已应用某个配置版本
+type AckNodeVnetConfigRequest struct {
+	VnetId   string `json:"vnetId" binding:"required" example:"1234"`
+	Revision int64  `json:"revision" binding:"required" example:"3"`
+}
+
+type AckNodeVnetConfigResponseData struct {
+	VnetId     string `json:"vnetId" example:"1234"`
+	Revision   int64  `json:"revision" example:"3"` // 当前最新版本号
+	NeedUpdate bool   `json:"needUpdate" example:"false"`
+}
+
+// WatchNodeVnetsRequest 订阅虚拟网络变更事件
+type WatchNodeVnetsRequest struct {
+	FromRevision int64 `json:"fromRevision" form:"fromRevision" example:"42"` // 从该版本号之后开始推送，断线重连时传入最后处理的版本号
+}
+
+// NodeVnetEvent 虚拟网络变更事件
+type NodeVnetEvent struct {
+	Revision     int64           `json:"revision" example:"43"`
+	Type         string          `json:"type" example:"update"` // create/update/enable/disable/delete/kick/resync，resync 表示续传的版本号已过保留期，须重新拉取列表后订阅
+	VnetId       string          `json:"vnetId" example:"1234"`
+	VnetRevision int64           `json:"vnetRevision" example:"3"`
+	ClientId     string          `json:"clientId,omitempty" example:"client_1"` // kick 事件中需要断开的客户端
+	Config       *NodeVnetConfig `json:"config,omitempty"`                      // 事件发生时的配置快照，删除与断开事件为空
+	CreatedAt    string          `json:"createdAt" example:"2025-06-01 12:00:00"`
+}
+
+// EnrollNodeRequest 节点使用一次性引导令牌注册
+type EnrollNodeRequest struct {
+	BootstrapToken string `json:"bootstrapToken" binding:"required" example:"5f2b9c..."`
+	Region         string `json:"region" example:"cn-east"` // 为空时使用引导令牌指定的区域
+	PublicAddr     string `json:"publicAddr" binding:"required" example:"203.0.113.10:7777"`
+	Capacity       int    `json:"capacity" binding:"min=0" example:"500"`
+	Version        string `json:"version" example:"1.4.2"`
+}
+
+type EnrollNodeResponseData struct {
+	NodeId     string `json:"nodeId" example:"node_3kTMd92x"`
+	NodeKey    string `json:"nodeKey" example:"9c1f..."`    // 节点密钥只在注册时返回一次，之后通过 X-Node-Id 与 Authorization 请求头认证
+	SigningKey string `json:"signingKey" example:"4e7a..."` // 用于在本地校验客户端会话凭证的签名密钥
+}
+
+// NodeHeartbeatRequest 节点定期上报状态与负载
+type NodeHeartbeatRequest struct {
+	PublicAddr       string  `json:"publicAddr" example:"203.0.113.10:7777"`
+	WgPublicKey      string  `json:"wgPublicKey" binding:"max=64" example:"HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw="` // 节点的 WireGuard 公钥，不支持 WireGuard 的节点留空
+	Capacity         int     `json:"capacity" binding:"min=0" example:"500"`
+	Version          string  `json:"version" example:"1.4.2"`
+	ClientsConnected int     `json:"clientsConnected" binding:"min=0" example:"120"`
+	CpuUsage         float64 `json:"cpuUsage" binding:"min=0,max=100" example:"35.5"`
+	MemUsage         float64 `json:"memUsage" binding:"min=0,max=100" example:"42.1"`
+	RxBps            int64   `json:"rxBps" binding:"min=0" example:"1048576"`
+	TxBps            int64   `json:"txBps" binding:"min=0" example:"2097152"`
+}
+
+type NodeHeartbeatResponseData struct {
+	Draining   bool   `json:"draining" example:"false"` // 节点已被管理员下线，不会再承接新的虚拟网络
+	ServerTime int64  `json:"serverTime" example:"1717200000"`
+	SigningKey string `json:"signingKey" example:"4e7a..."` // 用于在本地校验客户端会话凭证的签名密钥，随心跳下发以便控制面轮换密钥后节点及时更新
+}
@@ -0,0 +1,487 @@
+// 在该模块中定义节点控制面的 gRPC 服务
+// 项目未引入 protoc，服务描述按 protoc-gen-go-grpc 的生成格式手工编写，
+// 消息体使用 pkg/server/grpc 中注册的 JSON 编解码器
+
+package v1
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	NodeService_ListVnets_FullMethodName       = "/hyacinth.v1.NodeService/ListVnets"
+	NodeService_GetVnetConfig_FullMethodName   = "/hyacinth.v1.NodeService/GetVnetConfig"
+	NodeService_AckVnetConfig_FullMethodName   = "/hyacinth.v1.NodeService/AckVnetConfig"
+	NodeService_WatchVnets_FullMethodName      = "/hyacinth.v1.NodeService/WatchVnets"
+	NodeService_ReportUsage_FullMethodName     = "/hyacinth.v1.NodeService/ReportUsage"
+	NodeService_ClientJoin_FullMethodName      = "/hyacinth.v1.NodeService/ClientJoin"
+	NodeService_ClientLeave_FullMethodName     = "/hyacinth.v1.NodeService/ClientLeave"
+	NodeService_ClientHeartbeat_FullMethodName = "/hyacinth.v1.NodeService/ClientHeartbeat"
+	NodeService_AdmitClient_FullMethodName     = "/hyacinth.v1.NodeService/AdmitClient"
+	NodeService_Heartbeat_FullMethodName       = "/hyacinth.v1.NodeService/Heartbeat"
+	NodeService_ClientChallenge_FullMethodName = "/hyacinth.v1.NodeService/ClientChallenge"
+)
+
+// NodeServiceClient 节点侧使用的客户端
+type NodeServiceClient interface {
+	ListVnets(ctx context.Context, in *ListNodeVnetsRequest, opts ...grpc.CallOption) (*ListNodeVnetsResponseData, error)
+	GetVnetConfig(ctx context.Context, in *GetNodeVnetConfigRequest, opts ...grpc.CallOption) (*GetNodeVnetConfigResponseData, error)
+	AckVnetConfig(ctx context.Context, in *AckNodeVnetConfigRequest, opts ...grpc.CallOption) (*AckNodeVnetConfigResponseData, error)
+	WatchVnets(ctx context.Context, in *WatchNodeVnetsRequest, opts ...grpc.CallOption) (NodeService_WatchVnetsClient, error)
+	ReportUsage(ctx context.Context, in *ReportUsageRequest, opts ...grpc.CallOption) (*ReportUsageResponseData, error)
+	ClientJoin(ctx context.Context, in *ClientJoinRequest, opts ...grpc.CallOption) (*ClientJoinResponseData, error)
+	ClientLeave(ctx context.Context, in *ClientLeaveRequest, opts ...grpc.CallOption) (*ClientLeaveResponseData, error)
+	ClientHeartbeat(ctx context.Context, in *ClientHeartbeatRequest, opts ...grpc.CallOption) (*ClientHeartbeatResponseData, error)
+	AdmitClient(ctx context.Context, in *AdmitClientRequest, opts ...grpc.CallOption) (*AdmitClientResponseData, error)
+	Heartbeat(ctx context.Context, in *NodeHeartbeatRequest, opts ...grpc.CallOption) (*NodeHeartbeatResponseData, error)
+	ClientChallenge(ctx context.Context, in *ClientChallengeRequest, opts ...grpc.CallOption) (*ClientChallengeResponseData, error)
+}
+
+type nodeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNodeServiceClient(cc grpc.ClientConnInterface) NodeServiceClient {
+	return &nodeServiceClient{cc}
+}
+
+func (c *nodeServiceClient) ListVnets(ctx context.Context, in *ListNodeVnetsRequest, opts ...grpc.CallOption) (*ListNodeVnetsResponseData, error) {
+	out := new(ListNodeVnetsResponseData)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	if err := c.cc.Invoke(ctx, NodeService_ListVnets_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) GetVnetConfig(ctx context.Context, in *GetNodeVnetConfigRequest, opts ...grpc.CallOption) (*GetNodeVnetConfigResponseData, error) {
+	out := new(GetNodeVnetConfigResponseData)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	if err := c.cc.Invoke(ctx, NodeService_GetVnetConfig_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) AckVnetConfig(ctx context.Context, in *AckNodeVnetConfigRequest, opts ...grpc.CallOption) (*AckNodeVnetConfigResponseData, error) {
+	out := new(AckNodeVnetConfigResponseData)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	if err := c.cc.Invoke(ctx, NodeService_AckVnetConfig_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) ReportUsage(ctx context.Context, in *ReportUsageRequest, opts ...grpc.CallOption) (*ReportUsageResponseData, error) {
+	out := new(ReportUsageResponseData)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	if err := c.cc.Invoke(ctx, NodeService_ReportUsage_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) ClientJoin(ctx context.Context, in *ClientJoinRequest, opts ...grpc.CallOption) (*ClientJoinResponseData, error) {
+	out := new(ClientJoinResponseData)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	if err := c.cc.Invoke(ctx, NodeService_ClientJoin_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) ClientLeave(ctx context.Context, in *ClientLeaveRequest, opts ...grpc.CallOption) (*ClientLeaveResponseData, error) {
+	out := new(ClientLeaveResponseData)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	if err := c.cc.Invoke(ctx, NodeService_ClientLeave_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) ClientHeartbeat(ctx context.Context, in *ClientHeartbeatRequest, opts ...grpc.CallOption) (*ClientHeartbeatResponseData, error) {
+	out := new(ClientHeartbeatResponseData)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	if err := c.cc.Invoke(ctx, NodeService_ClientHeartbeat_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) AdmitClient(ctx context.Context, in *AdmitClientRequest, opts ...grpc.CallOption) (*AdmitClientResponseData, error) {
+	out := new(AdmitClientResponseData)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	if err := c.cc.Invoke(ctx, NodeService_AdmitClient_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) Heartbeat(ctx context.Context, in *NodeHeartbeatRequest, opts ...grpc.CallOption) (*NodeHeartbeatResponseData, error) {
+	out := new(NodeHeartbeatResponseData)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	if err := c.cc.Invoke(ctx, NodeService_Heartbeat_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) ClientChallenge(ctx context.Context, in *ClientChallengeRequest, opts ...grpc.CallOption) (*ClientChallengeResponseData, error) {
+	out := new(ClientChallengeResponseData)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	if err := c.cc.Invoke(ctx, NodeService_ClientChallenge_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) WatchVnets(ctx context.Context, in *WatchNodeVnetsRequest, opts ...grpc.CallOption) (NodeService_WatchVnetsClient, error) {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype("json")}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NodeService_ServiceDesc.Streams[0], NodeService_WatchVnets_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeServiceWatchVnetsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NodeService_WatchVnetsClient interface {
+	Recv() (*NodeVnetEvent, error)
+	grpc.ClientStream
+}
+
+type nodeServiceWatchVnetsClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeServiceWatchVnetsClient) Recv() (*NodeVnetEvent, error) {
+	m := new(NodeVnetEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NodeServiceServer 服务端需要实现的接口
+type NodeServiceServer interface {
+	ListVnets(context.Context, *ListNodeVnetsRequest) (*ListNodeVnetsResponseData, error)
+	GetVnetConfig(context.Context, *GetNodeVnetConfigRequest) (*GetNodeVnetConfigResponseData, error)
+	AckVnetConfig(context.Context, *AckNodeVnetConfigRequest) (*AckNodeVnetConfigResponseData, error)
+	WatchVnets(*WatchNodeVnetsRequest, NodeService_WatchVnetsServer) error
+	ReportUsage(context.Context, *ReportUsageRequest) (*ReportUsageResponseData, error)
+	ClientJoin(context.Context, *ClientJoinRequest) (*ClientJoinResponseData, error)
+	ClientLeave(context.Context, *ClientLeaveRequest) (*ClientLeaveResponseData, error)
+	ClientHeartbeat(context.Context, *ClientHeartbeatRequest) (*ClientHeartbeatResponseData, error)
+	AdmitClient(context.Context, *AdmitClientRequest) (*AdmitClientResponseData, error)
+	Heartbeat(context.Context, *NodeHeartbeatRequest) (*NodeHeartbeatResponseData, error)
+	ClientChallenge(context.Context, *ClientChallengeRequest) (*ClientChallengeResponseData, error)
+}
+
+// UnimplementedNodeServiceServer 可嵌入以保持向前兼容
+type UnimplementedNodeServiceServer struct {
+}
+
+func (UnimplementedNodeServiceServer) ListVnets(context.Context, *ListNodeVnetsRequest) (*ListNodeVnetsResponseData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVnets not implemented")
+}
+func (UnimplementedNodeServiceServer) GetVnetConfig(context.Context, *GetNodeVnetConfigRequest) (*GetNodeVnetConfigResponseData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVnetConfig not implemented")
+}
+func (UnimplementedNodeServiceServer) AckVnetConfig(context.Context, *AckNodeVnetConfigRequest) (*AckNodeVnetConfigResponseData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AckVnetConfig not implemented")
+}
+func (UnimplementedNodeServiceServer) ReportUsage(context.Context, *ReportUsageRequest) (*ReportUsageResponseData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportUsage not implemented")
+}
+func (UnimplementedNodeServiceServer) ClientJoin(context.Context, *ClientJoinRequest) (*ClientJoinResponseData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClientJoin not implemented")
+}
+func (UnimplementedNodeServiceServer) ClientLeave(context.Context, *ClientLeaveRequest) (*ClientLeaveResponseData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClientLeave not implemented")
+}
+func (UnimplementedNodeServiceServer) ClientHeartbeat(context.Context, *ClientHeartbeatRequest) (*ClientHeartbeatResponseData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClientHeartbeat not implemented")
+}
+func (UnimplementedNodeServiceServer) AdmitClient(context.Context, *AdmitClientRequest) (*AdmitClientResponseData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdmitClient not implemented")
+}
+func (UnimplementedNodeServiceServer) Heartbeat(context.Context, *NodeHeartbeatRequest) (*NodeHeartbeatResponseData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedNodeServiceServer) ClientChallenge(context.Context, *ClientChallengeRequest) (*ClientChallengeResponseData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClientChallenge not implemented")
+}
+func (UnimplementedNodeServiceServer) WatchVnets(*WatchNodeVnetsRequest, NodeService_WatchVnetsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchVnets not implemented")
+}
+
+func RegisterNodeServiceServer(s grpc.ServiceRegistrar, srv NodeServiceServer) {
+	s.RegisterService(&NodeService_ServiceDesc, srv)
+}
+
+func _NodeService_ListVnets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNodeVnetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).ListVnets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_ListVnets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).ListVnets(ctx, req.(*ListNodeVnetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_GetVnetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodeVnetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetVnetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_GetVnetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetVnetConfig(ctx, req.(*GetNodeVnetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_AckVnetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckNodeVnetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).AckVnetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_AckVnetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).AckVnetConfig(ctx, req.(*AckNodeVnetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_ReportUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).ReportUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_ReportUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).ReportUsage(ctx, req.(*ReportUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_ClientJoin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientJoinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).ClientJoin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_ClientJoin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).ClientJoin(ctx, req.(*ClientJoinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_ClientLeave_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientLeaveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).ClientLeave(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_ClientLeave_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).ClientLeave(ctx, req.(*ClientLeaveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_ClientHeartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientHeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).ClientHeartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_ClientHeartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).ClientHeartbeat(ctx, req.(*ClientHeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_AdmitClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdmitClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).AdmitClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_AdmitClient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).AdmitClient(ctx, req.(*AdmitClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeHeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).Heartbeat(ctx, req.(*NodeHeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_ClientChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).ClientChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_ClientChallenge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).ClientChallenge(ctx, req.(*ClientChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_WatchVnets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchNodeVnetsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeServiceServer).WatchVnets(m, &nodeServiceWatchVnetsServer{stream})
+}
+
+type NodeService_WatchVnetsServer interface {
+	Send(*NodeVnetEvent) error
+	grpc.ServerStream
+}
+
+type nodeServiceWatchVnetsServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeServiceWatchVnetsServer) Send(m *NodeVnetEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// NodeService_ServiceDesc 节点控制面服务描述
+var NodeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "hyacinth.v1.NodeService",
+	HandlerType: (*NodeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListVnets",
+			Handler:    _NodeService_ListVnets_Handler,
+		},
+		{
+			MethodName: "GetVnetConfig",
+			Handler:    _NodeService_GetVnetConfig_Handler,
+		},
+		{
+			MethodName: "AckVnetConfig",
+			Handler:    _NodeService_AckVnetConfig_Handler,
+		},
+		{
+			MethodName: "ReportUsage",
+			Handler:    _NodeService_ReportUsage_Handler,
+		},
+		{
+			MethodName: "ClientJoin",
+			Handler:    _NodeService_ClientJoin_Handler,
+		},
+		{
+			MethodName: "ClientLeave",
+			Handler:    _NodeService_ClientLeave_Handler,
+		},
+		{
+			MethodName: "ClientHeartbeat",
+			Handler:    _NodeService_ClientHeartbeat_Handler,
+		},
+		{
+			MethodName: "AdmitClient",
+			Handler:    _NodeService_AdmitClient_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _NodeService_Heartbeat_Handler,
+		},
+		{
+			MethodName: "ClientChallenge",
+			Handler:    _NodeService_ClientChallenge_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchVnets",
+			Handler:       _NodeService_WatchVnets_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/v1/node_grpc.go",
+}
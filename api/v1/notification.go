@@ -0,0 +1,21 @@
+package v1
+
+// NotificationItem 站内通知
+type NotificationItem struct {
+	Id        uint   `json:"id" example:"1"`
+	Type      string `json:"type" example:"privilege_expired"`
+	Title     string `json:"title" example:"套餐已到期"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"createdAt" example:"2025-06-01 12:00:00"`
+	ReadAt    string `json:"readAt,omitempty" example:"2025-06-01 12:05:00"` // 为空表示未读
+}
+
+type GetNotificationsResponseData struct {
+	Notifications []NotificationItem `json:"notifications"`
+	Unread        int64              `json:"unread" example:"1"`
+}
+
+type GetNotificationsResponse struct {
+	Response
+	Data GetNotificationsResponseData
+}
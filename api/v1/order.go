@@ -0,0 +1,48 @@
+package v1
+
+// OrderItem 购买套餐的订单，金额以分为单位
+type OrderItem struct {
+	OrderId     string `json:"orderId" example:"order_123"`
+	OrgId       string `json:"orgId,omitempty" example:"org_123"` // 为组织购买时非空
+	PackageType int    `json:"packageType" example:"3"`
+	Duration    int    `json:"duration" example:"1"`
+	Amount      int64  `json:"amount" example:"3000"`
+	Currency    string `json:"currency" example:"CNY"`
+	Status      string `json:"status" example:"pending_payment"` // created、pending_payment、paid、fulfilled、cancelled、refunded、refund_required
+	ExpiresAt   string `json:"expiresAt" example:"2025-06-01 12:30:00"`
+	PaidAt      string `json:"paidAt,omitempty" example:"2025-06-01 12:05:00"`
+	FulfilledAt string `json:"fulfilledAt,omitempty" example:"2025-06-01 12:05:00"`
+	CreatedAt   string `json:"createdAt" example:"2025-06-01 12:00:00"`
+}
+
+type GetOrderResponse struct {
+	Response
+	Data OrderItem
+}
+
+type GetOrdersResponseData struct {
+	Orders []OrderItem `json:"orders"`
+}
+
+type GetOrdersResponse struct {
+	Response
+	Data GetOrdersResponseData
+}
+
+// PreviewPurchaseResponseData 购买套餐的预览，确认前展示应付金额与生效时间，金额以分为单位
+// 升级立即生效，原套餐的剩余价值按新套餐的价格折算为时长；降级在当前特权到期时生效
+type PreviewPurchaseResponseData struct {
+	Kind        string `json:"kind" example:"upgrade"` // new、renew、upgrade、downgrade
+	PackageType int    `json:"packageType" example:"3"`
+	Duration    int    `json:"duration" example:"1"`
+	Amount      int64  `json:"amount" example:"3000"`
+	Currency    string `json:"currency" example:"CNY"`
+	EffectiveAt string `json:"effectiveAt" example:"2025-06-01 12:00:00"` // 新套餐或续费的时长开始生效的时间
+	ExpiresAt   string `json:"expiresAt" example:"2025-07-11 12:00:00"`   // 生效后的特权到期时间
+	CreditDays  int    `json:"creditDays" example:"10"`                   // 升级时剩余价值折算成的天数，不足一天不计
+}
+
+type PreviewPurchaseResponse struct {
+	Response
+	Data PreviewPurchaseResponseData
+}
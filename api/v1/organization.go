@@ -0,0 +1,56 @@
+package v1
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=64" example:"my team"`
+}
+
+// OrganizationItem 组织及其权益，流量池由组织的全部虚拟网络共用
+type OrganizationItem struct {
+	OrgId                  string  `json:"orgId" example:"org_123"`
+	Name                   string  `json:"name" example:"my team"`
+	Role                   string  `json:"role" example:"owner"` // 当前用户在组织中的角色：owner、admin、member
+	UserGroup              int     `json:"userGroup" example:"3"`
+	UserGroupName          string  `json:"userGroupName" example:"白银用户"`
+	PrivilegeExpiry        *string `json:"privilegeExpiry" example:"2025-07-01 12:00:00"`
+	AvailableTraffic       string  `json:"availableTraffic" example:"200.00 GB"`
+	CurrentCount           int     `json:"currentCount" example:"2"` // 运行中的虚拟网络数量
+	MaxLimit               int     `json:"maxLimit" example:"5"`
+	MaxClientsLimitPerVNet int     `json:"maxClientsLimitPerVNet" example:"10"`
+	CreatedAt              string  `json:"createdAt" example:"2025-06-01 12:00:00"`
+}
+
+type GetOrganizationResponse struct {
+	Response
+	Data OrganizationItem
+}
+
+type GetOrganizationsResponseData struct {
+	Organizations []OrganizationItem `json:"organizations"`
+}
+
+type GetOrganizationsResponse struct {
+	Response
+	Data GetOrganizationsResponseData
+}
+
+// SetOrganizationMemberRequest 按用户名或邮箱添加成员，用户已是成员时修改其角色
+type SetOrganizationMemberRequest struct {
+	Account string `json:"account" binding:"required,max=128" example:"alan"`
+	Role    string `json:"role" binding:"required,oneof=member admin" example:"member"`
+}
+
+type OrganizationMemberItem struct {
+	UserId    string `json:"userId" example:"user_123"`
+	Username  string `json:"username" example:"alan"`
+	Role      string `json:"role" example:"member"`
+	CreatedAt string `json:"createdAt" example:"2025-06-01 12:00:00"`
+}
+
+type GetOrganizationMembersResponseData struct {
+	Members []OrganizationMemberItem `json:"members"`
+}
+
+type GetOrganizationMembersResponse struct {
+	Response
+	Data GetOrganizationMembersResponseData
+}
@@ -0,0 +1,26 @@
+package v1
+
+// PlanItem 套餐，价格以分为单位，流量以字节为单位
+type PlanItem struct {
+	UserGroup       int      `json:"userGroup" example:"3"`
+	Name            string   `json:"name" example:"白银用户"`
+	Description     string   `json:"description,omitempty"`
+	MonthlyPrice    int64    `json:"monthlyPrice" example:"3000"`
+	Currency        string   `json:"currency" example:"CNY"`
+	Durations       []int    `json:"durations" example:"1,3,6,12"` // 可购买的时长（月数），免费套餐为空
+	MonthlyTraffic  int64    `json:"monthlyTraffic" example:"214748364800"`
+	VnetLimit       int      `json:"vnetLimit" example:"5"`
+	ClientsPerVnet  int      `json:"clientsPerVnet" example:"10"`
+	AclRulesPerVnet int      `json:"aclRulesPerVnet" example:"100"`
+	Features        []string `json:"features" example:"wireguard,routes"`
+	Purchasable     bool     `json:"purchasable" example:"true"`
+}
+
+type GetPlansResponseData struct {
+	Plans []PlanItem `json:"plans"`
+}
+
+type GetPlansResponse struct {
+	Response
+	Data GetPlansResponseData
+}
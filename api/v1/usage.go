@@ -19,3 +19,33 @@ type GetUsageResponse struct {
 	Response
 	Data GetUsageResponseData
 }
+
+// UsageRecord 节点上报的单条流量记录
+type UsageRecord struct {
+	VnetId   string `json:"vnetId" binding:"required" example:"vnet_123"`
+	ClientId string `json:"clientId" example:"client_1"` // 可选，空值表示虚拟网络汇总
+	BytesIn  int64  `json:"bytesIn" binding:"min=0" example:"1024"`
+	BytesOut int64  `json:"bytesOut" binding:"min=0" example:"2048"`
+}
+
+// ReportUsageRequest 节点批量上报流量
+type ReportUsageRequest struct {
+	BatchId     string        `json:"batchId" binding:"required,max=64" example:"relay-1-1717200000"` // 批次ID，重试时保持不变
+	WindowStart int64         `json:"windowStart" binding:"required" example:"1717200000"`            // 统计窗口开始时间（Unix秒）
+	WindowEnd   int64         `json:"windowEnd" binding:"required" example:"1717200060"`              // 统计窗口结束时间（Unix秒）
+	Records     []UsageRecord `json:"records" binding:"required,max=1000,dive"`
+}
+
+// RejectedUsageRecord 被拒绝的流量记录
+type RejectedUsageRecord struct {
+	Index  int    `json:"index" example:"0"`
+	VnetId string `json:"vnetId" example:"vnet_123"`
+	Reason string `json:"reason" example:"vnet not found"`
+}
+
+type ReportUsageResponseData struct {
+	BatchId   string                `json:"batchId" example:"relay-1-1717200000"`
+	Accepted  int                   `json:"accepted" example:"10"`
+	Rejected  []RejectedUsageRecord `json:"rejected"`
+	Duplicate bool                  `json:"duplicate" example:"false"` // 为 true 表示该批次此前已处理，本次未重复入库
+}
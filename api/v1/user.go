@@ -51,13 +51,20 @@ type GetProfileResponse struct {
 
 // PurchasePackageRequest 购买增值服务套餐请求
 type PurchasePackageRequest struct {
-	PackageType int `json:"packageType" binding:"required,min=2,max=4" example:"2"` // 2=青铜 3=白银 4=黄金
-	Duration    int `json:"duration" binding:"min=1,max=12" example:"1"`            // 购买时长（月数），1-12个月
+	PackageType int `json:"packageType" binding:"required,min=1" example:"2"` // 套餐对应的用户组，见 GET /plans
+	Duration    int `json:"duration" binding:"min=1" example:"1"`             // 购买时长（月数），须为套餐可购买的时长之一
+}
+
+// PurchasePackageResponseData 购买增值服务套餐响应数据，用户前往支付地址付款后套餐生效
+type PurchasePackageResponseData struct {
+	Order  OrderItem `json:"order"`
+	PayUrl string    `json:"payUrl" example:"http://127.0.0.1:8000/v1/payment/mock/checkout?orderId=order_123"`
 }
 
 // PurchasePackageResponse 购买增值服务套餐响应
 type PurchasePackageResponse struct {
 	Response
+	Data PurchasePackageResponseData
 }
 
 // GetUserGroupResponseData 获取用户组信息响应数据
@@ -1,14 +1,16 @@
 package v1
 
 type VnetProfile struct {
-	VnetId       string `json:"vnetId" example:"1234"`
-	Comment      string `json:"comment" example:"我的虚拟网络"`
-	Enabled      bool   `json:"enabled" example:"true"`
-	Token        string `json:"token" example:"1234"`
-	Password     string `json:"password" example:"1234"`
-	IpRange      string `json:"ipRange" example:"192.168.1.0/24"`
-	EnableDHCP   bool   `json:"enableDHCP" example:"true"`
-	ClientsLimit int    `json:"clientsLimit" example:"10"`
+	VnetId          string `json:"vnetId" example:"1234"`
+	Comment         string `json:"comment" example:"我的虚拟网络"`
+	Enabled         bool   `json:"enabled" example:"true"`
+	Token           string `json:"token" example:"1234"`
+	Password        string `json:"password,omitempty" example:"1234"` // 仅用于设置，不会在响应中返回；更新时留空表示不修改
+	IpRange         string `json:"ipRange" example:"192.168.1.0/24"`  // 私有地址网段，创建时留空则自动分配
+	EnableDHCP      bool   `json:"enableDHCP" example:"true"`
+	ClientsLimit    int    `json:"clientsLimit" example:"10"`
+	RequireApproval bool   `json:"requireApproval" example:"false"` // 新设备接入前需经所有者批准
+	Region          string `json:"region" example:"cn-east"`        // 优先使用的节点区域，留空表示不限
 }
 
 type GetVnetRequest struct {
@@ -24,7 +26,13 @@ type GetVnetByVnetIdResponseData struct {
 type GetVnetByUserIdResponseItem struct {
 	VnetId string `json:"vnetId" example:"1234"`
 	VnetProfile
-	ClientsOnline int `json:"clientsOnline" example:"5"`
+	ClientsOnline int    `json:"clientsOnline" example:"5"`
+	HasPassword   bool   `json:"hasPassword" example:"true"`
+	SuspendReason string `json:"suspendReason,omitempty" example:"traffic_exhausted"` // 系统自动停用的原因：traffic_exhausted 流量耗尽、plan_limit 套餐到期后超出数量限制，为空表示未被自动停用
+	NodeId        string `json:"nodeId,omitempty" example:"node_3kTMd92x"`            // 承载该虚拟网络的中继节点，为空表示尚未分配
+	Role          string `json:"role" example:"owner"`                                // 当前用户的角色：owner、admin、operator、viewer
+	OrgId         string `json:"orgId,omitempty" example:"org_123"`                   // 所属组织，为空表示个人虚拟网络
+	Type          string `json:"type" example:"n2n"`                                  // 虚拟网络类型：n2n、wireguard
 }
 
 type GetVnetResponseData struct {
@@ -43,10 +51,23 @@ type GetVnetByUserIdResponse struct {
 
 type UpdateVnetRequest struct {
 	VnetProfile
+	RemovePassword bool `json:"removePassword" example:"false"` // 取消密码，Password 为空时生效
 }
 
 type CreateVnetRequest struct {
 	VnetProfile
+	Type  string `json:"type" binding:"omitempty,oneof=n2n wireguard" example:"n2n"` // 虚拟网络类型，默认 n2n，创建后不能修改；wireguard 类型不使用令牌与密码，设备需登记公钥后接入
+	OrgId string `json:"orgId" binding:"max=64" example:"org_123"`                   // 所属组织，留空创建个人虚拟网络；组织的虚拟网络按组织的权益计算限制
+}
+
+type CreateVnetResponseData struct {
+	VnetId  string `json:"vnetId" example:"vnet_123"`
+	IpRange string `json:"ipRange" example:"10.0.0.0/24"` // 规范化或自动分配后的网段
+}
+
+type CreateVnetResponse struct {
+	Response
+	Data CreateVnetResponseData
 }
 
 type DeleteVnetRequest struct {
@@ -61,6 +82,11 @@ type DisableVnetRequest struct {
 	VnetID string `json:"vnetId" binding:"required" example:"1234"`
 }
 
+// GetVNetLimitInfoRequest 指定组织时返回组织的限制
+type GetVNetLimitInfoRequest struct {
+	OrgId string `form:"orgId" example:"org_123"`
+}
+
 type GetVNetLimitInfoResponseData struct {
 	CurrentCount           int `json:"currentCount" example:"2"`
 	MaxLimit               int `json:"maxLimit" example:"5"`
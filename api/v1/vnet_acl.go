@@ -0,0 +1,48 @@
+package v1
+
+// AclRuleRequest 创建或修改访问控制规则
+// 成员选择器可以是 *（所有成员）、tag:<标签>、client:<设备标识>，或虚拟地址/CIDR
+type AclRuleRequest struct {
+	Priority    *int   `json:"priority" binding:"omitempty,min=0,max=1000000" example:"100"` // 数值越小越先匹配，为空时排在最后
+	Action      string `json:"action" binding:"required,oneof=allow deny" example:"deny"`
+	Source      string `json:"source" binding:"required,max=128" example:"tag:guest"`
+	Destination string `json:"destination" binding:"required,max=128" example:"192.168.1.0/28"`
+	Protocol    string `json:"protocol" binding:"omitempty,oneof=any tcp udp icmp" example:"tcp"` // 为空表示 any
+	Ports       string `json:"ports" binding:"max=16" example:"22"`                               // 目标端口或端口范围，如 22、8000-9000，为空表示所有端口，仅 tcp/udp 可用
+	Comment     string `json:"comment" binding:"max=128" example:"禁止访客登录服务器"`
+}
+
+// AclRuleItem 访问控制规则
+type AclRuleItem struct {
+	RuleId      string `json:"ruleId" example:"acl_1234"`
+	Priority    int    `json:"priority" example:"100"`
+	Action      string `json:"action" example:"deny"`
+	Source      string `json:"source" example:"tag:guest"`
+	Destination string `json:"destination" example:"192.168.1.0/28"`
+	Protocol    string `json:"protocol" example:"tcp"`
+	Ports       string `json:"ports" example:"22"`
+	Comment     string `json:"comment" example:"禁止访客登录服务器"`
+}
+
+// AclMemberTags 成员及其标签
+type AclMemberTags struct {
+	ClientId string   `json:"clientId" example:"client_1"`
+	Tags     []string `json:"tags" example:"guest"`
+}
+
+type GetVnetAclResponseData struct {
+	Revision int64           `json:"revision" example:"2"` // 规则版本号，与下发给节点的版本一致
+	MaxRules int             `json:"maxRules" example:"10"`
+	Rules    []AclRuleItem   `json:"rules"`
+	Members  []AclMemberTags `json:"members"`
+}
+
+type GetVnetAclResponse struct {
+	Response
+	Data GetVnetAclResponseData
+}
+
+// SetMemberTagsRequest 设置成员的全部标签，为空表示清除
+type SetMemberTagsRequest struct {
+	Tags []string `json:"tags" binding:"max=16" example:"guest"`
+}
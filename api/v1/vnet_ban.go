@@ -0,0 +1,35 @@
+package v1
+
+// CreateVnetBanRequest 封禁设备或公网地址，二者只能指定其一
+type CreateVnetBanRequest struct {
+	ClientId string `json:"clientId" binding:"max=64" example:"client_1"`
+	Ip       string `json:"ip" binding:"max=64" example:"203.0.113.5"` // 公网 IP 或 CIDR
+	Reason   string `json:"reason" binding:"max=128" example:"发送垃圾流量"`
+	Duration int64  `json:"duration" binding:"min=0" example:"86400"` // 封禁时长（秒），0 表示永久
+}
+
+// UpdateVnetBanRequest 修改封禁的原因与时长
+type UpdateVnetBanRequest struct {
+	Reason   string `json:"reason" binding:"max=128" example:"发送垃圾流量"`
+	Duration int64  `json:"duration" binding:"min=0" example:"86400"` // 从现在起重新计算的封禁时长（秒），0 表示永久
+}
+
+// VnetBanItem 封禁记录
+type VnetBanItem struct {
+	BanId     string `json:"banId" example:"ban_1234"`
+	ClientId  string `json:"clientId" example:"client_1"`
+	Ip        string `json:"ip" example:""`
+	Reason    string `json:"reason" example:"发送垃圾流量"`
+	CreatedAt string `json:"createdAt" example:"2025-06-01 12:00:00"`
+	ExpiresAt string `json:"expiresAt" example:"2025-06-02 12:00:00"` // 永久封禁为空
+	Active    bool   `json:"active" example:"true"`                   // 封禁是否仍然有效
+}
+
+type GetVnetBansResponseData struct {
+	Bans []VnetBanItem `json:"bans"`
+}
+
+type GetVnetBansResponse struct {
+	Response
+	Data GetVnetBansResponseData
+}
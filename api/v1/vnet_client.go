@@ -0,0 +1,128 @@
+package v1
+
+// VnetClientItem 虚拟网络中的在线客户端
+type VnetClientItem struct {
+	ClientId       string `json:"clientId" example:"client_1"`
+	VirtualIp      string `json:"virtualIp" example:"192.168.1.2"`
+	MacAddress     string `json:"macAddress" example:"02:42:ac:11:00:02"`
+	PublicEndpoint string `json:"publicEndpoint" example:"203.0.113.5:51820"`
+	NodeId         string `json:"nodeId" example:"relay-1"`
+	ConnectedAt    string `json:"connectedAt" example:"2025-06-01 12:00:00"`
+	LastSeen       string `json:"lastSeen" example:"2025-06-01 12:05:00"`
+}
+
+type GetVnetClientsResponseData struct {
+	Clients []VnetClientItem `json:"clients"`
+}
+
+type GetVnetClientsResponse struct {
+	Response
+	Data GetVnetClientsResponseData
+}
+
+// ClientJoinRequest 节点上报客户端加入虚拟网络，须提交准入时签发的会话凭证
+type ClientJoinRequest struct {
+	VnetId         string `json:"vnetId" binding:"required" example:"vnet_123"`
+	ClientId       string `json:"clientId" binding:"required,max=64" example:"client_1"`
+	SessionToken   string `json:"sessionToken" binding:"required" example:"eyJhbGciOiJIUzI1NiIs..."` // 准入时签发的会话凭证，会话地址取自凭证
+	MacAddress     string `json:"macAddress" example:"02:42:ac:11:00:02"`
+	PublicEndpoint string `json:"publicEndpoint" example:"203.0.113.5:51820"`
+}
+
+type ClientJoinResponseData struct {
+	VirtualIp   string `json:"virtualIp" example:"192.168.1.2"`
+	ConnectedAt string `json:"connectedAt" example:"2025-06-01 12:00:00"`
+}
+
+// ClientLeaveRequest 节点上报客户端离开虚拟网络
+type ClientLeaveRequest struct {
+	VnetId   string `json:"vnetId" binding:"required" example:"vnet_123"`
+	ClientId string `json:"clientId" binding:"required" example:"client_1"`
+}
+
+type ClientLeaveResponseData struct {
+}
+
+// ClientSessionRef 标识一个客户端会话
+type ClientSessionRef struct {
+	VnetId   string `json:"vnetId" binding:"required" example:"vnet_123"`
+	ClientId string `json:"clientId" binding:"required" example:"client_1"`
+}
+
+// ClientHeartbeatRequest 节点批量上报仍在线的客户端
+type ClientHeartbeatRequest struct {
+	Clients []ClientSessionRef `json:"clients" binding:"max=5000,dive"`
+}
+
+type ClientHeartbeatResponseData struct {
+	Refreshed int                `json:"refreshed" example:"10"`
+	Unknown   []ClientSessionRef `json:"unknown"` // 控制面不存在的会话（已过期或从未加入），节点应重新上报加入
+}
+
+// ClientChallengeRequest 节点在客户端接入前为其获取挑战
+type ClientChallengeRequest struct {
+	Token    string `json:"token" binding:"required" example:"1234"`
+	ClientId string `json:"clientId" binding:"required,max=64" example:"client_1"`
+}
+
+// ClientChallengeResponseData 挑战与密码派生参数，客户端据此计算应答（见 pkg/vnetpass）
+// 令牌不存在时同样返回挑战，避免探测令牌，准入时再统一失败
+type ClientChallengeResponseData struct {
+	PasswordRequired bool   `json:"passwordRequired" example:"true"`
+	Challenge        string `json:"challenge" example:"eyJhbGciOiJIUzI1NiIs..."` // 使用节点密钥签名的短期挑战
+	Kdf              string `json:"kdf" example:"argon2id"`
+	Salt             string `json:"salt" example:"c2FsdHNhbHRzYWx0c2FsdA=="` // Base64
+	Time             uint32 `json:"time" example:"2"`
+	Memory           uint32 `json:"memory" example:"19456"` // KiB
+	Threads          uint8  `json:"threads" example:"1"`
+	ExpiresAt        int64  `json:"expiresAt" example:"1717200060"` // 挑战过期时间（Unix秒）
+}
+
+// AdmitClientRequest 节点在客户端接入前请求准入
+type AdmitClientRequest struct {
+	Token          string `json:"token" binding:"required" example:"1234"`
+	Challenge      string `json:"challenge" example:"eyJhbGciOiJIUzI1NiIs..."`           // 通过 /node/clients/challenge 获取的挑战
+	Proof          string `json:"proof" example:"q2v1b3..."`                             // 客户端对挑战的应答（Base64）
+	Password       string `json:"password" example:"1234"`                               // 已废弃：密码明文，仅用于尚不支持挑战应答的旧版节点，需开启 vnet.legacy_password
+	InviteKey      string `json:"inviteKey" example:"5f2b..."`                           // 兑换邀请获得的接入密钥，提供时代替密码
+	ClientId       string `json:"clientId" binding:"required,max=64" example:"client_1"` // 设备标识，同一设备重复准入时沿用原地址
+	DeviceKey      string `json:"deviceKey" binding:"max=128" example:"9c1e..."`         // 设备本地生成并保存的随机密钥，开启接入审批的虚拟网络必须提供，审批记录与之绑定
+	MacAddress     string `json:"macAddress" example:"02:42:ac:11:00:02"`
+	PublicEndpoint string `json:"publicEndpoint" example:"203.0.113.5:51820"`
+	VirtualIp      string `json:"virtualIp" example:"192.168.1.2"` // 未开启 DHCP 的虚拟网络由客户端指定地址
+}
+
+type AdmitClientResponseData struct {
+	VnetId         string `json:"vnetId" example:"vnet_123"`
+	VirtualIp      string `json:"virtualIp" example:"192.168.1.2"`
+	IpRange        string `json:"ipRange" example:"192.168.1.0/24"`
+	SessionToken   string `json:"sessionToken" example:"eyJhbGciOiJIUzI1NiIs..."` // 使用节点密钥签名的短期会话凭证
+	ExpiresAt      int64  `json:"expiresAt" example:"1717200600"`                 // 会话凭证过期时间（Unix秒）
+	LeaseExpiresAt int64  `json:"leaseExpiresAt" example:"1717243200"`            // 地址租约过期时间（Unix秒），静态地址为 0
+}
+
+// IpLeaseItem 虚拟网络地址租约
+type IpLeaseItem struct {
+	ClientId   string `json:"clientId" example:"client_1"`
+	Address    string `json:"address" example:"192.168.1.2"`
+	MacAddress string `json:"macAddress" example:"02:42:ac:11:00:02"`
+	Static     bool   `json:"static" example:"false"`                  // 是否为地址保留
+	ExpiresAt  string `json:"expiresAt" example:"2025-06-01 12:00:00"` // 静态租约为空
+	Active     bool   `json:"active" example:"true"`                   // 租约是否仍然有效
+}
+
+type GetVnetLeasesResponseData struct {
+	Leases []IpLeaseItem `json:"leases"`
+}
+
+type GetVnetLeasesResponse struct {
+	Response
+	Data GetVnetLeasesResponseData
+}
+
+// ReserveAddressRequest 为设备保留固定地址
+type ReserveAddressRequest struct {
+	ClientId   string `json:"clientId" binding:"required,max=64" example:"client_1"`
+	Address    string `json:"address" binding:"required" example:"192.168.1.10"`
+	MacAddress string `json:"macAddress" example:"02:42:ac:11:00:02"`
+}
@@ -0,0 +1,41 @@
+package v1
+
+// SetVnetCollaboratorRequest 授予或修改协作者角色，用户不是协作者时添加
+type SetVnetCollaboratorRequest struct {
+	Account string `json:"account" binding:"required,max=128" example:"alice"` // 用户名或邮箱
+	Role    string `json:"role" binding:"required,oneof=viewer operator admin" example:"operator"`
+}
+
+type VnetCollaboratorItem struct {
+	UserId    string `json:"userId" example:"user_123"`
+	Username  string `json:"username" example:"alice"`
+	Role      string `json:"role" example:"operator"`      // owner、admin、operator、viewer
+	GrantedBy string `json:"grantedBy" example:"user_456"` // 所有者为空
+	CreatedAt string `json:"createdAt" example:"2025-06-01 12:00:00"`
+}
+
+type GetVnetCollaboratorsResponseData struct {
+	Collaborators []VnetCollaboratorItem `json:"collaborators"` // 第一项为所有者
+}
+
+type GetVnetCollaboratorsResponse struct {
+	Response
+	Data GetVnetCollaboratorsResponseData
+}
+
+type VnetAuditLogItem struct {
+	ActorId   string `json:"actorId" example:"user_456"`
+	Action    string `json:"action" example:"collaborator.grant"`
+	TargetId  string `json:"targetId" example:"user_123"`
+	Detail    string `json:"detail" example:"viewer -> operator"`
+	CreatedAt string `json:"createdAt" example:"2025-06-01 12:00:00"`
+}
+
+type GetVnetAuditLogsResponseData struct {
+	Logs []VnetAuditLogItem `json:"logs"`
+}
+
+type GetVnetAuditLogsResponse struct {
+	Response
+	Data GetVnetAuditLogsResponseData
+}
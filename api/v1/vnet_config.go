@@ -0,0 +1,6 @@
+package v1
+
+type GetVnetConfigRequest struct {
+	Format   string `form:"format" binding:"max=32" example:"n2n"`        // 配置格式：n2n、wireguard、json，默认 json
+	ClientId string `form:"clientId" binding:"max=64" example:"client_1"` // 可选，指定设备时使用其保留地址
+}
@@ -0,0 +1,62 @@
+package v1
+
+type CreateVnetInviteRequest struct {
+	Comment     string `json:"comment" binding:"max=128" example:"给室友的邀请"`
+	MaxUses     int    `json:"maxUses" binding:"min=0,max=10000" example:"5"` // 可兑换的设备数量，0 表示不限
+	Duration    int64  `json:"duration" binding:"min=0" example:"604800"`     // 有效期（秒），0 表示不过期
+	AutoApprove bool   `json:"autoApprove" example:"true"`                    // 兑换的设备无需接入审批
+}
+
+// VnetInviteRedemptionItem 设备兑换邀请的记录
+type VnetInviteRedemptionItem struct {
+	ClientId   string `json:"clientId" example:"client_1"`
+	Name       string `json:"name" example:"laptop"`
+	Ip         string `json:"ip" example:"203.0.113.5"`
+	RedeemedAt string `json:"redeemedAt" example:"2025-06-01 12:00:00"`
+}
+
+type VnetInviteItem struct {
+	InviteId    string                     `json:"inviteId" example:"inv_1234"`
+	Code        string                     `json:"code" example:"3kTMd9fZq2Lx8WcR7vBn"`
+	Comment     string                     `json:"comment" example:"给室友的邀请"`
+	MaxUses     int                        `json:"maxUses" example:"5"`
+	Uses        int                        `json:"uses" example:"1"`
+	AutoApprove bool                       `json:"autoApprove" example:"true"`
+	CreatedAt   string                     `json:"createdAt" example:"2025-06-01 12:00:00"`
+	ExpiresAt   string                     `json:"expiresAt" example:"2025-06-08 12:00:00"` // 不过期时为空
+	RevokedAt   string                     `json:"revokedAt" example:""`                    // 未撤销时为空
+	Active      bool                       `json:"active" example:"true"`                   // 邀请是否仍可兑换
+	Redemptions []VnetInviteRedemptionItem `json:"redemptions"`
+}
+
+type GetVnetInvitesResponseData struct {
+	Invites []VnetInviteItem `json:"invites"`
+}
+
+type GetVnetInvitesResponse struct {
+	Response
+	Data GetVnetInvitesResponseData
+}
+
+type RedeemInviteRequest struct {
+	Code      string `json:"code" binding:"required,max=64" example:"3kTMd9fZq2Lx8WcR7vBn"`
+	ClientId  string `json:"clientId" binding:"required,max=64" example:"client_1"` // 兑换得到的凭据仅限该设备使用
+	Name      string `json:"name" binding:"max=64" example:"laptop"`
+	InviteKey string `json:"inviteKey,omitempty" binding:"max=128" example:"5f2b..."` // 该设备已兑换过时须提交当前的接入密钥，兑换后更换为新密钥
+	DeviceKey string `json:"deviceKey,omitempty" binding:"max=128" example:"9c1e..."` // 设备本地保存的随机密钥，自动批准的邀请将审批与之绑定
+}
+
+// RedeemInviteResponseData 接入凭据，客户端接入时提交令牌与接入密钥，无需密码
+type RedeemInviteResponseData struct {
+	VnetId          string `json:"vnetId" example:"vnet_123"`
+	Comment         string `json:"comment" example:"my vnet"` // 虚拟网络的备注
+	Token           string `json:"token" example:"1234"`
+	ClientId        string `json:"clientId" example:"client_1"`
+	InviteKey       string `json:"inviteKey" example:"5f2b..."`
+	RequireApproval bool   `json:"requireApproval" example:"false"` // 接入后是否仍需等待所有者审批
+}
+
+type RedeemInviteResponse struct {
+	Response
+	Data RedeemInviteResponseData
+}
@@ -0,0 +1,20 @@
+package v1
+
+// VnetMemberItem 设备的审批记录
+type VnetMemberItem struct {
+	ClientId       string `json:"clientId" example:"client_1"`
+	Status         string `json:"status" example:"pending"` // pending 待审批，approved 已批准，banned 已封禁
+	MacAddress     string `json:"macAddress" example:"02:42:ac:11:00:02"`
+	PublicEndpoint string `json:"publicEndpoint" example:"1.2.3.4:5678"`
+	LastSeen       string `json:"lastSeen" example:"2025-06-01 12:00:00"`   // 最近一次申请接入的时间
+	ReviewedAt     string `json:"reviewedAt" example:"2025-06-01 12:05:00"` // 待审批时为空
+}
+
+type GetVnetMembersResponseData struct {
+	Members []VnetMemberItem `json:"members"`
+}
+
+type GetVnetMembersResponse struct {
+	Response
+	Data GetVnetMembersResponseData
+}
@@ -0,0 +1,41 @@
+package v1
+
+// RegisterVnetPeerRequest 为设备登记 WireGuard 公钥，设备已登记时替换其公钥（轮换密钥）
+// 私钥应由客户端生成，只提交公钥；无法生成密钥的客户端可以设置 generate，由服务端代为生成
+type RegisterVnetPeerRequest struct {
+	ClientId  string `json:"clientId" binding:"required,max=64" example:"client_1"`
+	Name      string `json:"name" binding:"max=64" example:"笔记本"`
+	PublicKey string `json:"publicKey" binding:"max=64" example:"xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg="` // Base64 编码的公钥，generate 为 true 时忽略
+	Generate  bool   `json:"generate" example:"false"`                                                          // 由服务端生成密钥对，私钥只在响应中返回一次
+}
+
+// VnetPeerItem 登记的设备
+type VnetPeerItem struct {
+	ClientId   string   `json:"clientId" example:"client_1"`
+	Name       string   `json:"name" example:"笔记本"`
+	PublicKey  string   `json:"publicKey" example:"xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg="`
+	Address    string   `json:"address" example:"10.0.0.2"`
+	AllowedIps []string `json:"allowedIps" example:"10.0.0.2/32"` // 设备自身地址对应的网段；设备通告并已批准的路由在下发给节点时一并加入
+	CreatedAt  string   `json:"createdAt" example:"2025-06-01 12:00:00"`
+}
+
+type RegisterVnetPeerResponseData struct {
+	VnetPeerItem
+	PrivateKey string `json:"privateKey,omitempty" example:"yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk="` // 仅在服务端生成密钥时返回，不会保存，请立即写入客户端配置
+}
+
+type RegisterVnetPeerResponse struct {
+	Response
+	Data RegisterVnetPeerResponseData
+}
+
+type GetVnetPeersResponseData struct {
+	Revision      int64          `json:"revision" example:"3"`                                                 // 对端列表版本号，与下发给节点的版本一致
+	NodePublicKey string         `json:"nodePublicKey" example:"HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw="` // 承载节点的公钥，客户端以节点为唯一对端，为空表示节点尚未分配或未上报
+	Peers         []VnetPeerItem `json:"peers"`
+}
+
+type GetVnetPeersResponse struct {
+	Response
+	Data GetVnetPeersResponseData
+}
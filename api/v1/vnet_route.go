@@ -0,0 +1,31 @@
+package v1
+
+// AdvertiseRouteRequest 为设备通告路由，通告后需所有者或管理员批准才会生效
+type AdvertiseRouteRequest struct {
+	ClientId string `json:"clientId" binding:"required,max=64" example:"client_1"`
+	Prefix   string `json:"prefix" binding:"required,max=64" example:"192.168.10.0/24"` // 设备背后的局域网网段；0.0.0.0/0 或 ::/0 表示将设备设为出口节点
+	Comment  string `json:"comment" binding:"max=128" example:"办公室局域网"`
+}
+
+// VnetRouteItem 设备通告的路由
+type VnetRouteItem struct {
+	RouteId    string `json:"routeId" example:"route_1234"`
+	ClientId   string `json:"clientId" example:"client_1"`
+	Prefix     string `json:"prefix" example:"192.168.10.0/24"`
+	Exit       bool   `json:"exit" example:"false"`      // 出口节点
+	Status     string `json:"status" example:"approved"` // pending、approved
+	Comment    string `json:"comment" example:"办公室局域网"`
+	CreatedAt  string `json:"createdAt" example:"2025-06-01 12:00:00"`
+	ApprovedAt string `json:"approvedAt,omitempty" example:"2025-06-01 12:30:00"`
+}
+
+type GetVnetRoutesResponseData struct {
+	Revision  int64           `json:"revision" example:"2"` // 路由表版本号，与下发给节点的版本一致
+	MaxRoutes int             `json:"maxRoutes" example:"32"`
+	Routes    []VnetRouteItem `json:"routes"`
+}
+
+type GetVnetRoutesResponse struct {
+	Response
+	Data GetVnetRoutesResponseData
+}
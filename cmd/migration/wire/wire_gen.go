@@ -19,7 +19,7 @@ import (
 
 func NewWire(viperViper *viper.Viper, logger *log.Logger) (*app.App, func(), error) {
 	db := repository.NewDB(viperViper, logger)
-	migrateServer := server.NewMigrateServer(db, logger)
+	migrateServer := server.NewMigrateServer(db, viperViper, logger)
 	appApp := newApp(migrateServer)
 	return appApp, func() {
 	}, nil
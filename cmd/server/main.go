@@ -39,6 +39,7 @@ func main() {
 		panic(err)
 	}
 	logger.Info("server start", zap.String("host", fmt.Sprintf("http://%s:%d", conf.GetString("http.host"), conf.GetInt("http.port"))))
+	logger.Info("grpc server start", zap.String("addr", fmt.Sprintf("%s:%d", conf.GetString("grpc.host"), conf.GetInt("grpc.port"))))
 	logger.Info("docs addr", zap.String("addr", fmt.Sprintf("http://%s:%d/swagger/index.html", conf.GetString("http.host"), conf.GetInt("http.port"))))
 	if err = app.Run(context.Background()); err != nil {
 		panic(err)
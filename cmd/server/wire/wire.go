@@ -12,6 +12,8 @@ import (
 	"hyacinth-backend/pkg/app"
 	"hyacinth-backend/pkg/jwt"
 	"hyacinth-backend/pkg/log"
+	"hyacinth-backend/pkg/payment"
+	"hyacinth-backend/pkg/server/grpc"
 	"hyacinth-backend/pkg/server/http"
 	"hyacinth-backend/pkg/sid"
 
@@ -28,6 +30,21 @@ var repositorySet = wire.NewSet(
 
 	repository.NewUsageRepository,
 	repository.NewVnetRepository,
+	repository.NewVnetEventRepository,
+	repository.NewVnetClientRepository,
+	repository.NewIpLeaseRepository,
+	repository.NewNodeRepository,
+	repository.NewVnetAclRepository,
+	repository.NewVnetMemberRepository,
+	repository.NewVnetBanRepository,
+	repository.NewVnetInviteRepository,
+	repository.NewVnetCollaboratorRepository,
+	repository.NewOrganizationRepository,
+	repository.NewVnetPeerRepository,
+	repository.NewVnetRouteRepository,
+	repository.NewOrderRepository,
+	repository.NewPlanRepository,
+	repository.NewNotificationRepository,
 )
 
 var serviceSet = wire.NewSet(
@@ -35,11 +52,35 @@ var serviceSet = wire.NewSet(
 	service.NewUserService,
 	service.NewUsageService,
 	service.NewVnetService,
+	service.NewVnetEventService,
+	service.NewNodeService,
+	service.NewVnetClientService,
+	service.NewIpamService,
+	service.NewVnetAclService,
+	service.NewVnetMemberService,
+	service.NewVnetBanService,
+	service.NewVnetInviteService,
+	service.NewVnetCollaboratorService,
+	service.NewOrganizationService,
+	service.NewVnetConfigService,
+	service.NewVnetPeerService,
+	service.NewVnetRouteService,
+	service.NewOrderService,
+	service.NewPlanService,
+	service.NewNotificationService,
 )
 
 var handlerSet = wire.NewSet(
 	handler.NewHandler,
 	handler.NewUserHandler,
+	handler.NewNodeRPCHandler,
+	handler.NewNodeHandler,
+	handler.NewVnetHandler,
+	handler.NewAdminHandler,
+	handler.NewOrganizationHandler,
+	handler.NewOrderHandler,
+	handler.NewPlanHandler,
+	handler.NewNotificationHandler,
 )
 
 var jobSet = wire.NewSet(
@@ -48,17 +89,19 @@ var jobSet = wire.NewSet(
 )
 var serverSet = wire.NewSet(
 	server.NewHTTPServer,
+	server.NewGRPCServer,
 	server.NewJobServer,
 )
 
 // build App
 func newApp(
 	httpServer *http.Server,
+	grpcServer *grpc.Server,
 	jobServer *server.JobServer,
 	// task *server.Task,
 ) *app.App {
 	return app.NewApp(
-		app.WithServer(httpServer, jobServer),
+		app.WithServer(httpServer, grpcServer, jobServer),
 		app.WithName("demo-server"),
 	)
 }
@@ -72,6 +115,7 @@ func NewWire(*viper.Viper, *log.Logger) (*app.App, func(), error) {
 		serverSet,
 		sid.NewSid,
 		jwt.NewJwt,
+		payment.NewPaymentProvider,
 		newApp,
 	))
 }
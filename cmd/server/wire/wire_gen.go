@@ -17,6 +17,8 @@ import (
 	"hyacinth-backend/pkg/app"
 	"hyacinth-backend/pkg/jwt"
 	"hyacinth-backend/pkg/log"
+	"hyacinth-backend/pkg/payment"
+	"hyacinth-backend/pkg/server/grpc"
 	"hyacinth-backend/pkg/server/http"
 	"hyacinth-backend/pkg/sid"
 )
@@ -32,38 +34,80 @@ func NewWire(viperViper *viper.Viper, logger *log.Logger) (*app.App, func(), err
 	sidSid := sid.NewSid()
 	serviceService := service.NewService(transaction, logger, sidSid, jwtJWT)
 	userRepository := repository.NewUserRepository(repositoryRepository)
-	userService := service.NewUserService(serviceService, userRepository)
-	usageRepository := repository.NewUsageRepository(repositoryRepository)
-	usageService := service.NewUsageService(serviceService, usageRepository)
 	vnetRepository := repository.NewVnetRepository(repositoryRepository)
-	vnetService := service.NewVnetService(serviceService, vnetRepository)
-	userHandler := handler.NewUserHandler(handlerHandler, userService, usageService, vnetService)
-	httpServer := server.NewHTTPServer(logger, viperViper, jwtJWT, userHandler)
+	vnetEventRepository := repository.NewVnetEventRepository(repositoryRepository)
+	vnetEventService := service.NewVnetEventService(serviceService, vnetEventRepository)
+	vnetClientRepository := repository.NewVnetClientRepository(repositoryRepository)
+	ipLeaseRepository := repository.NewIpLeaseRepository(repositoryRepository)
+	organizationRepository := repository.NewOrganizationRepository(repositoryRepository)
+	ipamService := service.NewIpamService(serviceService, viperViper, vnetRepository, userRepository, vnetClientRepository, ipLeaseRepository, organizationRepository)
+	vnetCollaboratorRepository := repository.NewVnetCollaboratorRepository(repositoryRepository)
+	planRepository := repository.NewPlanRepository(repositoryRepository)
+	planService := service.NewPlanService(serviceService, viperViper, planRepository)
+	vnetRouteRepository := repository.NewVnetRouteRepository(repositoryRepository)
+	vnetService := service.NewVnetService(serviceService, vnetRepository, userRepository, vnetEventService, ipamService, vnetCollaboratorRepository, organizationRepository, vnetRouteRepository, planService)
+	userService := service.NewUserService(serviceService, userRepository, vnetService, planService)
+	usageRepository := repository.NewUsageRepository(repositoryRepository)
+	usageService := service.NewUsageService(serviceService, usageRepository, vnetRepository, userRepository, organizationRepository, vnetService)
+	organizationService := service.NewOrganizationService(serviceService, userRepository, organizationRepository, vnetRepository, vnetService, planService)
+	orderRepository := repository.NewOrderRepository(repositoryRepository)
+	paymentProvider := payment.NewPaymentProvider(viperViper)
+	orderService := service.NewOrderService(serviceService, viperViper, orderRepository, userRepository, organizationRepository, vnetRepository, userService, organizationService, planService, paymentProvider)
+	userHandler := handler.NewUserHandler(handlerHandler, userService, usageService, vnetService, organizationService, orderService)
+	nodeRepository := repository.NewNodeRepository(repositoryRepository)
+	nodeService := service.NewNodeService(serviceService, viperViper, vnetRepository, vnetEventService, nodeRepository)
+	vnetMemberRepository := repository.NewVnetMemberRepository(repositoryRepository)
+	vnetBanRepository := repository.NewVnetBanRepository(repositoryRepository)
+	vnetInviteRepository := repository.NewVnetInviteRepository(repositoryRepository)
+	vnetClientService := service.NewVnetClientService(serviceService, viperViper, vnetRepository, userRepository, vnetClientRepository, ipamService, ipLeaseRepository, nodeRepository, vnetMemberRepository, vnetBanRepository, vnetInviteRepository, organizationRepository, planService)
+	nodeHandler := handler.NewNodeHandler(handlerHandler, nodeService, usageService, vnetClientService)
+	vnetAclRepository := repository.NewVnetAclRepository(repositoryRepository)
+	vnetAclService := service.NewVnetAclService(serviceService, vnetRepository, userRepository, vnetAclRepository, vnetEventService, organizationRepository, planService)
+	vnetMemberService := service.NewVnetMemberService(serviceService, vnetRepository, vnetMemberRepository)
+	vnetBanService := service.NewVnetBanService(serviceService, vnetRepository, vnetClientRepository, vnetMemberRepository, vnetBanRepository, vnetEventService)
+	vnetInviteService := service.NewVnetInviteService(serviceService, vnetRepository, vnetBanRepository, vnetInviteRepository, vnetMemberService)
+	vnetCollaboratorService := service.NewVnetCollaboratorService(serviceService, userRepository, vnetCollaboratorRepository)
+	vnetConfigService := service.NewVnetConfigService(serviceService, nodeRepository, ipLeaseRepository)
+	vnetPeerRepository := repository.NewVnetPeerRepository(repositoryRepository)
+	vnetPeerService := service.NewVnetPeerService(serviceService, vnetRepository, vnetPeerRepository, ipamService, ipLeaseRepository, nodeRepository, vnetEventService, userRepository, organizationRepository, planService)
+	vnetRouteService := service.NewVnetRouteService(serviceService, vnetRepository, vnetRouteRepository, vnetEventService)
+	vnetHandler := handler.NewVnetHandler(handlerHandler, vnetService, vnetClientService, ipamService, vnetAclService, vnetMemberService, vnetBanService, vnetInviteService, vnetCollaboratorService, vnetConfigService, vnetPeerService, vnetRouteService)
+	adminHandler := handler.NewAdminHandler(handlerHandler, nodeService)
+	organizationHandler := handler.NewOrganizationHandler(handlerHandler, organizationService, orderService)
+	orderHandler := handler.NewOrderHandler(handlerHandler, orderService)
+	planHandler := handler.NewPlanHandler(handlerHandler, planService)
+	notificationRepository := repository.NewNotificationRepository(repositoryRepository)
+	notificationService := service.NewNotificationService(serviceService, notificationRepository)
+	notificationHandler := handler.NewNotificationHandler(handlerHandler, notificationService)
+	httpServer := server.NewHTTPServer(logger, viperViper, jwtJWT, nodeService, userHandler, nodeHandler, vnetHandler, adminHandler, organizationHandler, orderHandler, planHandler, notificationHandler)
+	nodeRPCHandler := handler.NewNodeRPCHandler(handlerHandler, nodeService, usageService, vnetClientService)
+	grpcServer := server.NewGRPCServer(logger, viperViper, nodeService, nodeRPCHandler)
 	jobJob := job.NewJob(transaction, logger, sidSid)
 	userJob := job.NewUserJob(jobJob, userRepository)
 	jobServer := server.NewJobServer(logger, userJob)
-	appApp := newApp(httpServer, jobServer)
+	appApp := newApp(httpServer, grpcServer, jobServer)
 	return appApp, func() {
 	}, nil
 }
 
 // wire.go:
 
-var repositorySet = wire.NewSet(repository.NewDB, repository.NewRepository, repository.NewTransaction, repository.NewUserRepository, repository.NewUsageRepository, repository.NewVnetRepository)
+var repositorySet = wire.NewSet(repository.NewDB, repository.NewRepository, repository.NewTransaction, repository.NewUserRepository, repository.NewUsageRepository, repository.NewVnetRepository, repository.NewVnetEventRepository, repository.NewVnetClientRepository, repository.NewIpLeaseRepository, repository.NewNodeRepository, repository.NewVnetAclRepository, repository.NewVnetMemberRepository, repository.NewVnetBanRepository, repository.NewVnetInviteRepository, repository.NewVnetCollaboratorRepository, repository.NewOrganizationRepository, repository.NewVnetPeerRepository, repository.NewVnetRouteRepository, repository.NewOrderRepository, repository.NewPlanRepository, repository.NewNotificationRepository)
 
-var serviceSet = wire.NewSet(service.NewService, service.NewUserService, service.NewUsageService, service.NewVnetService)
+var serviceSet = wire.NewSet(service.NewService, service.NewUserService, service.NewUsageService, service.NewVnetService, service.NewVnetEventService, service.NewNodeService, service.NewVnetClientService, service.NewIpamService, service.NewVnetAclService, service.NewVnetMemberService, service.NewVnetBanService, service.NewVnetInviteService, service.NewVnetCollaboratorService, service.NewOrganizationService, service.NewVnetConfigService, service.NewVnetPeerService, service.NewVnetRouteService, service.NewOrderService, service.NewPlanService, service.NewNotificationService)
 
-var handlerSet = wire.NewSet(handler.NewHandler, handler.NewUserHandler)
+var handlerSet = wire.NewSet(handler.NewHandler, handler.NewUserHandler, handler.NewNodeRPCHandler, handler.NewNodeHandler, handler.NewVnetHandler, handler.NewAdminHandler, handler.NewOrganizationHandler, handler.NewOrderHandler, handler.NewPlanHandler, handler.NewNotificationHandler)
 
 var jobSet = wire.NewSet(job.NewJob, job.NewUserJob)
 
-var serverSet = wire.NewSet(server.NewHTTPServer, server.NewJobServer)
+var serverSet = wire.NewSet(server.NewHTTPServer, server.NewGRPCServer, server.NewJobServer)
 
 // build App
 func newApp(
 	httpServer *http.Server,
+	grpcServer *grpc.Server,
 	jobServer *server.JobServer,
 
 ) *app.App {
-	return app.NewApp(app.WithServer(httpServer, jobServer), app.WithName("demo-server"))
+	return app.NewApp(app.WithServer(httpServer, grpcServer, jobServer), app.WithName("demo-server"))
 }
@@ -6,8 +6,10 @@ package wire
 import (
 	"hyacinth-backend/internal/repository"
 	"hyacinth-backend/internal/server"
+	"hyacinth-backend/internal/service"
 	"hyacinth-backend/internal/task"
 	"hyacinth-backend/pkg/app"
+	"hyacinth-backend/pkg/jwt"
 	"hyacinth-backend/pkg/log"
 	"hyacinth-backend/pkg/sid"
 	"github.com/google/wire"
@@ -20,11 +22,38 @@ var repositorySet = wire.NewSet(
 	repository.NewRepository,
 	repository.NewTransaction,
 	repository.NewUserRepository,
+	repository.NewVnetClientRepository,
+	repository.NewIpLeaseRepository,
+	repository.NewNodeRepository,
+	repository.NewVnetRepository,
+	repository.NewVnetEventRepository,
+	repository.NewOrderRepository,
+	repository.NewVnetCollaboratorRepository,
+	repository.NewOrganizationRepository,
+	repository.NewVnetRouteRepository,
+	repository.NewPlanRepository,
+	repository.NewTrafficResetRepository,
+	repository.NewNotificationRepository,
+)
+
+var serviceSet = wire.NewSet(
+	service.NewService,
+	service.NewVnetEventService,
+	service.NewSchedulerService,
+	service.NewIpamService,
+	service.NewVnetService,
+	service.NewPlanService,
+	service.NewSubscriptionService,
 )
 
 var taskSet = wire.NewSet(
 	task.NewTask,
 	task.NewUserTask,
+	task.NewVnetClientTask,
+	task.NewIpLeaseTask,
+	task.NewVnetEventTask,
+	task.NewNodeTask,
+	task.NewOrderTask,
 )
 var serverSet = wire.NewSet(
 	server.NewTaskServer,
@@ -43,9 +72,11 @@ func newApp(
 func NewWire(*viper.Viper, *log.Logger) (*app.App, func(), error) {
 	panic(wire.Build(
 		repositorySet,
+		serviceSet,
 		taskSet,
 		serverSet,
 		newApp,
 		sid.NewSid,
+		jwt.NewJwt,
 	))
 }
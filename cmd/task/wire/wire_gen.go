@@ -11,8 +11,10 @@ import (
 	"github.com/spf13/viper"
 	"hyacinth-backend/internal/repository"
 	"hyacinth-backend/internal/server"
+	"hyacinth-backend/internal/service"
 	"hyacinth-backend/internal/task"
 	"hyacinth-backend/pkg/app"
+	"hyacinth-backend/pkg/jwt"
 	"hyacinth-backend/pkg/log"
 	"hyacinth-backend/pkg/sid"
 )
@@ -25,9 +27,34 @@ func NewWire(viperViper *viper.Viper, logger *log.Logger) (*app.App, func(), err
 	transaction := repository.NewTransaction(repositoryRepository)
 	sidSid := sid.NewSid()
 	taskTask := task.NewTask(transaction, logger, sidSid)
+	jwtJWT := jwt.NewJwt(viperViper)
+	serviceService := service.NewService(transaction, logger, sidSid, jwtJWT)
 	userRepository := repository.NewUserRepository(repositoryRepository)
-	userTask := task.NewUserTask(taskTask, userRepository)
-	taskServer := server.NewTaskServer(logger, userTask)
+	organizationRepository := repository.NewOrganizationRepository(repositoryRepository)
+	trafficResetRepository := repository.NewTrafficResetRepository(repositoryRepository)
+	planRepository := repository.NewPlanRepository(repositoryRepository)
+	planService := service.NewPlanService(serviceService, viperViper, planRepository)
+	vnetRepository := repository.NewVnetRepository(repositoryRepository)
+	vnetEventRepository := repository.NewVnetEventRepository(repositoryRepository)
+	vnetEventService := service.NewVnetEventService(serviceService, vnetEventRepository)
+	vnetClientRepository := repository.NewVnetClientRepository(repositoryRepository)
+	ipLeaseRepository := repository.NewIpLeaseRepository(repositoryRepository)
+	ipamService := service.NewIpamService(serviceService, viperViper, vnetRepository, userRepository, vnetClientRepository, ipLeaseRepository, organizationRepository)
+	vnetCollaboratorRepository := repository.NewVnetCollaboratorRepository(repositoryRepository)
+	vnetRouteRepository := repository.NewVnetRouteRepository(repositoryRepository)
+	vnetService := service.NewVnetService(serviceService, vnetRepository, userRepository, vnetEventService, ipamService, vnetCollaboratorRepository, organizationRepository, vnetRouteRepository, planService)
+	notificationRepository := repository.NewNotificationRepository(repositoryRepository)
+	subscriptionService := service.NewSubscriptionService(serviceService, viperViper, userRepository, organizationRepository, trafficResetRepository, notificationRepository, planService, vnetService, vnetEventService)
+	userTask := task.NewUserTask(taskTask, subscriptionService)
+	vnetClientTask := task.NewVnetClientTask(taskTask, viperViper, vnetClientRepository)
+	ipLeaseTask := task.NewIpLeaseTask(taskTask, ipLeaseRepository)
+	vnetEventTask := task.NewVnetEventTask(taskTask, viperViper, vnetEventRepository)
+	nodeRepository := repository.NewNodeRepository(repositoryRepository)
+	schedulerService := service.NewSchedulerService(serviceService, viperViper, vnetRepository, nodeRepository, vnetEventService)
+	nodeTask := task.NewNodeTask(taskTask, viperViper, nodeRepository, schedulerService)
+	orderRepository := repository.NewOrderRepository(repositoryRepository)
+	orderTask := task.NewOrderTask(taskTask, orderRepository)
+	taskServer := server.NewTaskServer(logger, userTask, vnetClientTask, ipLeaseTask, vnetEventTask, nodeTask, orderTask)
 	appApp := newApp(taskServer)
 	return appApp, func() {
 	}, nil
@@ -35,9 +62,11 @@ func NewWire(viperViper *viper.Viper, logger *log.Logger) (*app.App, func(), err
 
 // wire.go:
 
-var repositorySet = wire.NewSet(repository.NewDB, repository.NewRepository, repository.NewTransaction, repository.NewUserRepository)
+var repositorySet = wire.NewSet(repository.NewDB, repository.NewRepository, repository.NewTransaction, repository.NewUserRepository, repository.NewVnetClientRepository, repository.NewIpLeaseRepository, repository.NewNodeRepository, repository.NewVnetRepository, repository.NewVnetEventRepository, repository.NewOrderRepository, repository.NewVnetCollaboratorRepository, repository.NewOrganizationRepository, repository.NewVnetRouteRepository, repository.NewPlanRepository, repository.NewTrafficResetRepository, repository.NewNotificationRepository)
 
-var taskSet = wire.NewSet(task.NewTask, task.NewUserTask)
+var serviceSet = wire.NewSet(service.NewService, service.NewVnetEventService, service.NewSchedulerService, service.NewIpamService, service.NewVnetService, service.NewPlanService, service.NewSubscriptionService)
+
+var taskSet = wire.NewSet(task.NewTask, task.NewUserTask, task.NewVnetClientTask, task.NewIpLeaseTask, task.NewVnetEventTask, task.NewNodeTask, task.NewOrderTask)
 
 var serverSet = wire.NewSet(server.NewTaskServer)
 
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminHandler 管理员接口
+type AdminHandler struct {
+	*Handler
+	nodeService service.NodeService
+}
+
+func NewAdminHandler(
+	handler *Handler,
+	nodeService service.NodeService,
+) *AdminHandler {
+	return &AdminHandler{
+		Handler:     handler,
+		nodeService: nodeService,
+	}
+}
+
+// ListNodes godoc
+// @Summary 获取节点列表
+// @Schemes
+// @Description 获取所有中继节点的区域、容量、版本、在线状态与最近一次心跳上报的负载
+// @Tags 管理员模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} v1.ListNodesResponse
+// @Router /admin/nodes [get]
+func (h *AdminHandler) ListNodes(ctx *gin.Context) {
+	nodes, err := h.nodeService.ListNodes(ctx)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("nodeService.ListNodes error", zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+
+	items := make([]v1.AdminNodeItem, 0, len(*nodes))
+	for _, node := range *nodes {
+		item := v1.AdminNodeItem{
+			NodeId:           node.NodeId,
+			Region:           node.Region,
+			PublicAddr:       node.PublicAddr,
+			Capacity:         node.Capacity,
+			Version:          node.Version,
+			Status:           node.Status,
+			Draining:         node.Draining,
+			ClientsConnected: node.ClientsConnected,
+			CpuUsage:         node.CpuUsage,
+			MemUsage:         node.MemUsage,
+			RxBps:            node.RxBps,
+			TxBps:            node.TxBps,
+		}
+		if node.LastHeartbeat != nil {
+			item.LastHeartbeat = node.LastHeartbeat.Format("2006-01-02 15:04:05")
+		}
+		items = append(items, item)
+	}
+	v1.HandleSuccess(ctx, v1.ListNodesResponseData{Nodes: items})
+}
+
+// CreateBootstrapToken godoc
+// @Summary 生成节点引导令牌
+// @Schemes
+// @Description 生成一次性引导令牌，新节点使用该令牌调用注册接口获取节点ID与密钥
+// @Tags 管理员模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body v1.CreateBootstrapTokenRequest true "params"
+// @Success 200 {object} v1.CreateBootstrapTokenResponse
+// @Router /admin/nodes/bootstrap-tokens [post]
+func (h *AdminHandler) CreateBootstrapToken(ctx *gin.Context) {
+	var req v1.CreateBootstrapTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	resp, err := h.nodeService.CreateBootstrapToken(ctx, &req)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("nodeService.CreateBootstrapToken error", zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+	v1.HandleSuccess(ctx, resp)
+}
+
+// DrainNode godoc
+// @Summary 设置节点下线状态
+// @Schemes
+// @Description 下线的节点继续服务已分配的虚拟网络，但不再承接新的虚拟网络
+// @Tags 管理员模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param nodeId path string true "节点ID"
+// @Param request body v1.DrainNodeRequest true "params"
+// @Success 200 {object} v1.Response
+// @Router /admin/nodes/{nodeId}/drain [put]
+func (h *AdminHandler) DrainNode(ctx *gin.Context) {
+	var req v1.DrainNodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	nodeId := ctx.Param("nodeId")
+	if err := h.nodeService.SetDraining(ctx, nodeId, req.Draining); err != nil {
+		h.handleAdminError(ctx, "nodeService.SetDraining", err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// RemoveNode godoc
+// @Summary 移除节点
+// @Schemes
+// @Description 移除注册的节点，节点密钥立即失效，其承载的虚拟网络等待重新分配
+// @Tags 管理员模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param nodeId path string true "节点ID"
+// @Success 200 {object} v1.Response
+// @Router /admin/nodes/{nodeId} [delete]
+func (h *AdminHandler) RemoveNode(ctx *gin.Context) {
+	nodeId := ctx.Param("nodeId")
+	if err := h.nodeService.RemoveNode(ctx, nodeId); err != nil {
+		h.handleAdminError(ctx, "nodeService.RemoveNode", err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// handleAdminError 将业务错误转换为 HTTP 响应，非预期错误记录日志
+func (h *AdminHandler) handleAdminError(ctx *gin.Context, op string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrNotFound):
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+	case errors.Is(err, v1.ErrNodeStatic):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrNodeStatic, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
+}
@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/middleware"
+	"hyacinth-backend/internal/service"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// NodeHandler 面向中继节点的 HTTP 接口
+type NodeHandler struct {
+	*Handler
+	nodeService       service.NodeService
+	usageService      service.UsageService
+	vnetClientService service.VnetClientService
+}
+
+func NewNodeHandler(
+	handler *Handler,
+	nodeService service.NodeService,
+	usageService service.UsageService,
+	vnetClientService service.VnetClientService,
+) *NodeHandler {
+	return &NodeHandler{
+		Handler:           handler,
+		nodeService:       nodeService,
+		usageService:      usageService,
+		vnetClientService: vnetClientService,
+	}
+}
+
+// WatchVnets godoc
+// @Summary 订阅虚拟网络变更事件（SSE）
+// @Schemes
+// @Description 以 Server-Sent Events 推送分配给当前节点的虚拟网络变更事件，事件ID即版本号，断线重连时通过 fromRevision 或 Last-Event-ID 续传
+// @Description 续传的版本号早于事件保留期时推送 resync 事件后断开，节点须重新拉取虚拟网络列表，从列表返回的版本号订阅
+// @Tags 节点模块
+// @Produce text/event-stream
+// @Param X-Node-Id header string true "节点ID"
+// @Param fromRevision query int false "从该版本号之后开始推送"
+// @Success 200 {object} v1.NodeVnetEvent
+// @Router /node/vnets/watch [get]
+func (h *NodeHandler) WatchVnets(ctx *gin.Context) {
+	var req v1.WatchNodeVnetsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	// 浏览器式 SSE 客户端重连时会自动携带 Last-Event-ID
+	if lastEventId := ctx.GetHeader("Last-Event-ID"); lastEventId != "" {
+		revision, err := strconv.ParseInt(lastEventId, 10, 64)
+		if err != nil {
+			v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+			return
+		}
+		req.FromRevision = revision
+	}
+	if req.FromRevision < 0 {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	nodeId := GetNodeIdFromCtx(ctx)
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	err := h.nodeService.WatchVnets(ctx.Request.Context(), nodeId, req.FromRevision, func(event *v1.NodeVnetEvent) error {
+		// resync 事件没有版本号，不写入事件ID，避免客户端以此续传
+		id := ""
+		if event.Revision > 0 {
+			id = strconv.FormatInt(event.Revision, 10)
+		}
+		ctx.Render(-1, sse.Event{
+			Id:    id,
+			Event: "vnet",
+			Data:  event,
+		})
+		ctx.Writer.Flush()
+		return nil
+	})
+	if err != nil && ctx.Request.Context().Err() == nil {
+		h.logger.WithContext(ctx).Error("nodeService.WatchVnets error", zap.String("nodeId", nodeId), zap.Error(err))
+	}
+}
+
+// ReportUsage godoc
+// @Summary 节点批量上报流量
+// @Schemes
+// @Description 节点按统计窗口上报各虚拟网络（及客户端）的流量，相同 batchId 的重试只会入库一次
+// @Tags 节点模块
+// @Accept json
+// @Produce json
+// @Param X-Node-Id header string true "节点ID"
+// @Param request body v1.ReportUsageRequest true "流量上报请求参数"
+// @Success 200 {object} v1.ReportUsageResponseData
+// @Router /node/usage [post]
+func (h *NodeHandler) ReportUsage(ctx *gin.Context) {
+	var req v1.ReportUsageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.usageService.ReportUsage(ctx, nodeId, &req)
+	if err != nil {
+		if errors.Is(err, v1.ErrBadRequest) {
+			v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+			return
+		}
+		h.logger.WithContext(ctx).Error("usageService.ReportUsage error", zap.String("nodeId", nodeId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+	v1.HandleSuccess(ctx, resp)
+}
+
+// ClientJoin godoc
+// @Summary 上报客户端加入虚拟网络
+// @Schemes
+// @Description 节点在客户端接入后凭准入时签发的会话凭证上报会话信息，会话地址取自凭证；同一客户端重复上报时覆盖原会话
+// @Tags 节点模块
+// @Accept json
+// @Produce json
+// @Param X-Node-Id header string true "节点ID"
+// @Param request body v1.ClientJoinRequest true "客户端信息"
+// @Success 200 {object} v1.ClientJoinResponseData
+// @Router /node/clients/join [post]
+func (h *NodeHandler) ClientJoin(ctx *gin.Context) {
+	var req v1.ClientJoinRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.vnetClientService.ClientJoin(ctx, nodeId, &req)
+	if err != nil {
+		h.handleNodeError(ctx, "vnetClientService.ClientJoin", nodeId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, resp)
+}
+
+// ClientLeave godoc
+// @Summary 上报客户端离开虚拟网络
+// @Schemes
+// @Description 节点在客户端断开后上报，会话不存在时同样返回成功
+// @Tags 节点模块
+// @Accept json
+// @Produce json
+// @Param X-Node-Id header string true "节点ID"
+// @Param request body v1.ClientLeaveRequest true "客户端会话"
+// @Success 200 {object} v1.Response
+// @Router /node/clients/leave [post]
+func (h *NodeHandler) ClientLeave(ctx *gin.Context) {
+	var req v1.ClientLeaveRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	nodeId := GetNodeIdFromCtx(ctx)
+	if err := h.vnetClientService.ClientLeave(ctx, nodeId, &req); err != nil {
+		h.handleNodeError(ctx, "vnetClientService.ClientLeave", nodeId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// ClientHeartbeat godoc
+// @Summary 批量上报在线客户端心跳
+// @Schemes
+// @Description 节点定期上报仍在线的客户端，超过有效期未上报的会话会被清理；返回控制面不存在的会话，节点应重新上报加入
+// @Tags 节点模块
+// @Accept json
+// @Produce json
+// @Param X-Node-Id header string true "节点ID"
+// @Param request body v1.ClientHeartbeatRequest true "在线客户端列表"
+// @Success 200 {object} v1.ClientHeartbeatResponseData
+// @Router /node/clients/heartbeat [post]
+func (h *NodeHandler) ClientHeartbeat(ctx *gin.Context) {
+	var req v1.ClientHeartbeatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.vnetClientService.ClientHeartbeat(ctx, nodeId, &req)
+	if err != nil {
+		h.handleNodeError(ctx, "vnetClientService.ClientHeartbeat", nodeId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, resp)
+}
+
+// ClientChallenge godoc
+// @Summary 获取客户端接入挑战
+// @Schemes
+// @Description 节点在客户端接入前获取挑战与密码派生参数，客户端在本地计算应答，密码明文不离开客户端
+// @Tags 节点模块
+// @Accept json
+// @Produce json
+// @Param X-Node-Id header string true "节点ID"
+// @Param request body v1.ClientChallengeRequest true "挑战请求参数"
+// @Success 200 {object} v1.ClientChallengeResponseData
+// @Router /node/clients/challenge [post]
+func (h *NodeHandler) ClientChallenge(ctx *gin.Context) {
+	var req v1.ClientChallengeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.vnetClientService.ClientChallenge(ctx, nodeId, &req)
+	if err != nil {
+		h.handleNodeError(ctx, "vnetClientService.ClientChallenge", nodeId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, resp)
+}
+
+// AdmitClient godoc
+// @Summary 客户端接入准入
+// @Schemes
+// @Description 节点携带客户端提供的接入令牌与挑战应答请求准入，通过后返回分配的虚拟地址与短期会话凭证（使用节点密钥签名）；开启接入审批的虚拟网络须提供设备密钥
+// @Tags 节点模块
+// @Accept json
+// @Produce json
+// @Param X-Node-Id header string true "节点ID"
+// @Param request body v1.AdmitClientRequest true "准入请求参数"
+// @Success 200 {object} v1.AdmitClientResponseData
+// @Router /node/clients/admit [post]
+func (h *NodeHandler) AdmitClient(ctx *gin.Context) {
+	var req v1.AdmitClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.vnetClientService.AdmitClient(ctx, nodeId, &req)
+	if err != nil {
+		h.handleNodeError(ctx, "vnetClientService.AdmitClient", nodeId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, resp)
+}
+
+// Enroll godoc
+// @Summary 节点注册
+// @Schemes
+// @Description 新节点使用管理员生成的一次性引导令牌注册，返回节点ID与密钥；该接口无需节点认证
+// @Tags 节点模块
+// @Accept json
+// @Produce json
+// @Param request body v1.EnrollNodeRequest true "注册请求参数"
+// @Success 200 {object} v1.EnrollNodeResponseData
+// @Router /node/enroll [post]
+func (h *NodeHandler) Enroll(ctx *gin.Context) {
+	var req v1.EnrollNodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	resp, err := h.nodeService.Enroll(ctx, &req)
+	if err != nil {
+		h.handleNodeError(ctx, "nodeService.Enroll", "", err)
+		return
+	}
+	// 响应包含节点密钥，不写入日志
+	middleware.MarkSensitiveResponse(ctx)
+	v1.HandleSuccess(ctx, resp)
+}
+
+// Heartbeat godoc
+// @Summary 节点心跳
+// @Schemes
+// @Description 节点定期上报版本、容量与负载，超过有效期未上报的节点会被标记为离线
+// @Tags 节点模块
+// @Accept json
+// @Produce json
+// @Param X-Node-Id header string true "节点ID"
+// @Param request body v1.NodeHeartbeatRequest true "节点状态"
+// @Success 200 {object} v1.NodeHeartbeatResponseData
+// @Router /node/heartbeat [post]
+func (h *NodeHandler) Heartbeat(ctx *gin.Context) {
+	var req v1.NodeHeartbeatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.nodeService.Heartbeat(ctx, nodeId, &req)
+	if err != nil {
+		h.handleNodeError(ctx, "nodeService.Heartbeat", nodeId, err)
+		return
+	}
+	// 响应包含签名密钥，不写入日志
+	middleware.MarkSensitiveResponse(ctx)
+	v1.HandleSuccess(ctx, resp)
+}
+
+// handleNodeError 将业务错误转换为 HTTP 响应，非预期错误记录日志
+func (h *NodeHandler) handleNodeError(ctx *gin.Context, op string, nodeId string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+	case errors.Is(err, v1.ErrUnauthorized):
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+	case errors.Is(err, v1.ErrTrafficExhausted), errors.Is(err, v1.ErrVnetClientsFull), errors.Is(err, v1.ErrVnetAddressExhausted),
+		errors.Is(err, v1.ErrMemberPendingApproval), errors.Is(err, v1.ErrMemberBanned), errors.Is(err, v1.ErrDeviceKeyInvalid):
+		v1.HandleError(ctx, http.StatusForbidden, err, nil)
+	case errors.Is(err, v1.ErrVirtualIpUnavailable):
+		v1.HandleError(ctx, http.StatusConflict, v1.ErrVirtualIpUnavailable, nil)
+	case errors.Is(err, v1.ErrForbidden):
+		v1.HandleError(ctx, http.StatusForbidden, v1.ErrForbidden, nil)
+	case errors.Is(err, v1.ErrNotFound):
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.String("nodeId", nodeId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/service"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NodeRPCHandler 实现节点控制面的 gRPC 接口
+type NodeRPCHandler struct {
+	*Handler
+	nodeService       service.NodeService
+	usageService      service.UsageService
+	vnetClientService service.VnetClientService
+}
+
+func NewNodeRPCHandler(
+	handler *Handler,
+	nodeService service.NodeService,
+	usageService service.UsageService,
+	vnetClientService service.VnetClientService,
+) *NodeRPCHandler {
+	return &NodeRPCHandler{
+		Handler:           handler,
+		nodeService:       nodeService,
+		usageService:      usageService,
+		vnetClientService: vnetClientService,
+	}
+}
+
+var _ v1.NodeServiceServer = (*NodeRPCHandler)(nil)
+
+// GetNodeIdFromCtx 获取经过认证的节点ID
+func GetNodeIdFromCtx(ctx context.Context) string {
+	v, _ := ctx.Value("nodeId").(string)
+	return v
+}
+
+// ListVnets 列出分配给当前节点的虚拟网络
+func (h *NodeRPCHandler) ListVnets(ctx context.Context, req *v1.ListNodeVnetsRequest) (*v1.ListNodeVnetsResponseData, error) {
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.nodeService.ListVnets(ctx, nodeId)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("nodeService.ListVnets error", zap.String("nodeId", nodeId), zap.Error(err))
+		return nil, rpcError(err)
+	}
+	return resp, nil
+}
+
+// GetVnetConfig 获取虚拟网络的完整配置
+func (h *NodeRPCHandler) GetVnetConfig(ctx context.Context, req *v1.GetNodeVnetConfigRequest) (*v1.GetNodeVnetConfigResponseData, error) {
+	if req.VnetId == "" {
+		return nil, rpcError(v1.ErrBadRequest)
+	}
+	resp, err := h.nodeService.GetVnetConfig(ctx, GetNodeIdFromCtx(ctx), req.VnetId)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return resp, nil
+}
+
+// AckVnetConfig 确认节点已应用指定版本的配置
+func (h *NodeRPCHandler) AckVnetConfig(ctx context.Context, req *v1.AckNodeVnetConfigRequest) (*v1.AckNodeVnetConfigResponseData, error) {
+	if req.VnetId == "" || req.Revision <= 0 {
+		return nil, rpcError(v1.ErrBadRequest)
+	}
+	resp, err := h.nodeService.AckVnetConfig(ctx, GetNodeIdFromCtx(ctx), req)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return resp, nil
+}
+
+// WatchVnets 以服务端流推送虚拟网络变更事件
+func (h *NodeRPCHandler) WatchVnets(req *v1.WatchNodeVnetsRequest, stream v1.NodeService_WatchVnetsServer) error {
+	ctx := stream.Context()
+	nodeId := GetNodeIdFromCtx(ctx)
+	err := h.nodeService.WatchVnets(ctx, nodeId, req.FromRevision, stream.Send)
+	if err != nil && ctx.Err() == nil {
+		h.logger.WithContext(ctx).Error("nodeService.WatchVnets error", zap.String("nodeId", nodeId), zap.Error(err))
+		return rpcError(err)
+	}
+	return nil
+}
+
+// ReportUsage 批量上报流量
+func (h *NodeRPCHandler) ReportUsage(ctx context.Context, req *v1.ReportUsageRequest) (*v1.ReportUsageResponseData, error) {
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.usageService.ReportUsage(ctx, nodeId, req)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("usageService.ReportUsage error", zap.String("nodeId", nodeId), zap.Error(err))
+		return nil, rpcError(err)
+	}
+	return resp, nil
+}
+
+// ClientJoin 凭会话凭证上报客户端加入虚拟网络
+func (h *NodeRPCHandler) ClientJoin(ctx context.Context, req *v1.ClientJoinRequest) (*v1.ClientJoinResponseData, error) {
+	if req.VnetId == "" || req.ClientId == "" || len(req.ClientId) > 64 || req.SessionToken == "" {
+		return nil, rpcError(v1.ErrBadRequest)
+	}
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.vnetClientService.ClientJoin(ctx, nodeId, req)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("vnetClientService.ClientJoin error", zap.String("nodeId", nodeId), zap.Error(err))
+		return nil, rpcError(err)
+	}
+	return resp, nil
+}
+
+// ClientLeave 上报客户端离开虚拟网络
+func (h *NodeRPCHandler) ClientLeave(ctx context.Context, req *v1.ClientLeaveRequest) (*v1.ClientLeaveResponseData, error) {
+	if req.VnetId == "" || req.ClientId == "" {
+		return nil, rpcError(v1.ErrBadRequest)
+	}
+	nodeId := GetNodeIdFromCtx(ctx)
+	if err := h.vnetClientService.ClientLeave(ctx, nodeId, req); err != nil {
+		h.logger.WithContext(ctx).Error("vnetClientService.ClientLeave error", zap.String("nodeId", nodeId), zap.Error(err))
+		return nil, rpcError(err)
+	}
+	return &v1.ClientLeaveResponseData{}, nil
+}
+
+// ClientHeartbeat 批量刷新在线客户端
+func (h *NodeRPCHandler) ClientHeartbeat(ctx context.Context, req *v1.ClientHeartbeatRequest) (*v1.ClientHeartbeatResponseData, error) {
+	if len(req.Clients) > 5000 {
+		return nil, rpcError(v1.ErrBadRequest)
+	}
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.vnetClientService.ClientHeartbeat(ctx, nodeId, req)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("vnetClientService.ClientHeartbeat error", zap.String("nodeId", nodeId), zap.Error(err))
+		return nil, rpcError(err)
+	}
+	return resp, nil
+}
+
+// ClientChallenge 获取客户端接入挑战
+func (h *NodeRPCHandler) ClientChallenge(ctx context.Context, req *v1.ClientChallengeRequest) (*v1.ClientChallengeResponseData, error) {
+	if req.Token == "" || req.ClientId == "" || len(req.ClientId) > 64 {
+		return nil, rpcError(v1.ErrBadRequest)
+	}
+	resp, err := h.vnetClientService.ClientChallenge(ctx, GetNodeIdFromCtx(ctx), req)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return resp, nil
+}
+
+// AdmitClient 客户端接入准入
+func (h *NodeRPCHandler) AdmitClient(ctx context.Context, req *v1.AdmitClientRequest) (*v1.AdmitClientResponseData, error) {
+	if req.Token == "" || req.ClientId == "" || len(req.ClientId) > 64 || len(req.DeviceKey) > 128 {
+		return nil, rpcError(v1.ErrBadRequest)
+	}
+	resp, err := h.vnetClientService.AdmitClient(ctx, GetNodeIdFromCtx(ctx), req)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return resp, nil
+}
+
+// Heartbeat 上报节点状态与负载
+func (h *NodeRPCHandler) Heartbeat(ctx context.Context, req *v1.NodeHeartbeatRequest) (*v1.NodeHeartbeatResponseData, error) {
+	if req.Capacity < 0 || req.ClientsConnected < 0 || req.CpuUsage < 0 || req.MemUsage < 0 || req.RxBps < 0 || req.TxBps < 0 {
+		return nil, rpcError(v1.ErrBadRequest)
+	}
+	nodeId := GetNodeIdFromCtx(ctx)
+	resp, err := h.nodeService.Heartbeat(ctx, nodeId, req)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("nodeService.Heartbeat error", zap.String("nodeId", nodeId), zap.Error(err))
+		return nil, rpcError(err)
+	}
+	return resp, nil
+}
+
+// rpcError 将业务错误转换为 gRPC 状态码
+func rpcError(err error) error {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, v1.ErrUnauthorized):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, v1.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, v1.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, v1.ErrTrafficExhausted), errors.Is(err, v1.ErrVnetClientsFull), errors.Is(err, v1.ErrVnetAddressExhausted):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, v1.ErrVirtualIpUnavailable):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, v1.ErrMemberPendingApproval), errors.Is(err, v1.ErrMemberBanned), errors.Is(err, v1.ErrDeviceKeyInvalid):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Internal, v1.ErrInternalServerError.Error())
+	}
+}
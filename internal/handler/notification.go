@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// NotificationHandler 站内通知
+type NotificationHandler struct {
+	*Handler
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(
+	handler *Handler,
+	notificationService service.NotificationService,
+) *NotificationHandler {
+	return &NotificationHandler{
+		Handler:             handler,
+		notificationService: notificationService,
+	}
+}
+
+// GetNotifications godoc
+// @Summary 获取站内通知
+// @Schemes
+// @Description 获取当前用户最近的站内通知（如套餐到期降级）与未读数量，按时间倒序
+// @Tags 用户模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} v1.GetNotificationsResponse
+// @Router /user/notifications [get]
+func (h *NotificationHandler) GetNotifications(ctx *gin.Context) {
+	userId := GetUserIdFromCtx(ctx)
+	if userId == "" {
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+		return
+	}
+
+	data, err := h.notificationService.GetNotifications(ctx, userId)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("notificationService.GetNotifications error", zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// MarkNotificationsRead godoc
+// @Summary 标记站内通知已读
+// @Schemes
+// @Description 将当前用户的未读通知全部标记为已读
+// @Tags 用户模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} v1.Response
+// @Router /user/notifications/read [post]
+func (h *NotificationHandler) MarkNotificationsRead(ctx *gin.Context) {
+	userId := GetUserIdFromCtx(ctx)
+	if userId == "" {
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+		return
+	}
+
+	if err := h.notificationService.MarkAllRead(ctx, userId); err != nil {
+		h.logger.WithContext(ctx).Error("notificationService.MarkAllRead error", zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
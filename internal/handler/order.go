@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OrderHandler 订单查询与支付渠道回调
+type OrderHandler struct {
+	*Handler
+	orderService service.OrderService
+}
+
+func NewOrderHandler(
+	handler *Handler,
+	orderService service.OrderService,
+) *OrderHandler {
+	return &OrderHandler{
+		Handler:      handler,
+		orderService: orderService,
+	}
+}
+
+// GetOrders godoc
+// @Summary 获取订单列表
+// @Schemes
+// @Description 获取当前用户的全部订单，包括为组织购买的订单，按创建时间倒序
+// @Tags 订单模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} v1.GetOrdersResponse
+// @Router /orders [get]
+func (h *OrderHandler) GetOrders(ctx *gin.Context) {
+	userId := GetUserIdFromCtx(ctx)
+	if userId == "" {
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+		return
+	}
+
+	data, err := h.orderService.GetOrders(ctx, userId)
+	if err != nil {
+		h.handleOrderError(ctx, "orderService.GetOrders", "", err)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// GetOrder godoc
+// @Summary 获取订单
+// @Schemes
+// @Description 获取订单详情，付款后可据此查询套餐是否已生效
+// @Tags 订单模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param orderId path string true "订单ID"
+// @Success 200 {object} v1.GetOrderResponse
+// @Router /orders/{orderId} [get]
+func (h *OrderHandler) GetOrder(ctx *gin.Context) {
+	userId := GetUserIdFromCtx(ctx)
+	if userId == "" {
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+		return
+	}
+
+	item, err := h.orderService.GetOrder(ctx, userId, ctx.Param("orderId"))
+	if err != nil {
+		h.handleOrderError(ctx, "orderService.GetOrder", ctx.Param("orderId"), err)
+		return
+	}
+	v1.HandleSuccess(ctx, item)
+}
+
+// CancelOrder godoc
+// @Summary 取消订单
+// @Schemes
+// @Description 取消尚未付款的订单，已付款的订单不能取消
+// @Tags 订单模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param orderId path string true "订单ID"
+// @Success 200 {object} v1.Response
+// @Router /orders/{orderId}/cancel [post]
+func (h *OrderHandler) CancelOrder(ctx *gin.Context) {
+	userId := GetUserIdFromCtx(ctx)
+	if userId == "" {
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+		return
+	}
+
+	if err := h.orderService.CancelOrder(ctx, userId, ctx.Param("orderId")); err != nil {
+		h.handleOrderError(ctx, "orderService.CancelOrder", ctx.Param("orderId"), err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// PaymentNotify godoc
+// @Summary 支付渠道回调
+// @Schemes
+// @Description 支付渠道在用户付款或退款后回调，请求体与签名按渠道约定校验；处理失败时返回非 200，渠道会重试
+// @Tags 订单模块
+// @Accept json
+// @Produce json
+// @Param provider path string true "渠道标识，如 mock"
+// @Success 200 {object} v1.Response
+// @Router /payment/notify/{provider} [post]
+func (h *OrderHandler) PaymentNotify(ctx *gin.Context) {
+	body, err := ctx.GetRawData()
+	if err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	if err := h.orderService.HandleNotification(ctx, ctx.Param("provider"), ctx.Request.Header, body); err != nil {
+		h.handleOrderError(ctx, "orderService.HandleNotification", ctx.Param("provider"), err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// MockCheckout godoc
+// @Summary 模拟支付
+// @Schemes
+// @Description 本地模拟网关的支付页，访问即完成订单的付款并使套餐生效，仅在 payment.provider 为 mock 时可用
+// @Tags 订单模块
+// @Accept json
+// @Produce json
+// @Param orderId query string true "订单ID"
+// @Success 200 {object} v1.GetOrderResponse
+// @Router /payment/mock/checkout [get]
+func (h *OrderHandler) MockCheckout(ctx *gin.Context) {
+	orderId := ctx.Query("orderId")
+	if orderId == "" {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	item, err := h.orderService.CompleteMockPayment(ctx, orderId)
+	if err != nil {
+		h.handleOrderError(ctx, "orderService.CompleteMockPayment", orderId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, item)
+}
+
+// handleOrderError 将订单与支付回调的错误转换为响应
+func (h *OrderHandler) handleOrderError(ctx *gin.Context, op string, id string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+	case errors.Is(err, v1.ErrInvalidPaymentSignature):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrInvalidPaymentSignature, nil)
+	case errors.Is(err, v1.ErrOrderStatus):
+		v1.HandleError(ctx, http.StatusConflict, v1.ErrOrderStatus, nil)
+	case errors.Is(err, v1.ErrNotFound):
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.String("id", id), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
+}
@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OrganizationHandler 组织及其成员的用户接口
+type OrganizationHandler struct {
+	*Handler
+	organizationService service.OrganizationService
+	orderService        service.OrderService
+}
+
+func NewOrganizationHandler(
+	handler *Handler,
+	organizationService service.OrganizationService,
+	orderService service.OrderService,
+) *OrganizationHandler {
+	return &OrganizationHandler{
+		Handler:             handler,
+		organizationService: organizationService,
+		orderService:        orderService,
+	}
+}
+
+// GetOrganizations godoc
+// @Summary 获取组织列表
+// @Schemes
+// @Description 获取当前用户加入的全部组织及其权益
+// @Tags 组织模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} v1.GetOrganizationsResponse
+// @Router /org [get]
+func (h *OrganizationHandler) GetOrganizations(ctx *gin.Context) {
+	userId := GetUserIdFromCtx(ctx)
+	if userId == "" {
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+		return
+	}
+
+	data, err := h.organizationService.GetOrganizations(ctx, userId)
+	if err != nil {
+		h.handleOrganizationError(ctx, "organizationService.GetOrganizations", "", err)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// CreateOrganization godoc
+// @Summary 创建组织
+// @Schemes
+// @Description 创建组织，创建者成为所有者；新组织为普通用户组且没有流量，需要购买套餐
+// @Tags 组织模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body v1.CreateOrganizationRequest true "params"
+// @Success 200 {object} v1.GetOrganizationResponse
+// @Router /org [post]
+func (h *OrganizationHandler) CreateOrganization(ctx *gin.Context) {
+	userId := GetUserIdFromCtx(ctx)
+	if userId == "" {
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+		return
+	}
+	var req v1.CreateOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	item, err := h.organizationService.CreateOrganization(ctx, userId, &req)
+	if err != nil {
+		h.handleOrganizationError(ctx, "organizationService.CreateOrganization", "", err)
+		return
+	}
+	v1.HandleSuccess(ctx, item)
+}
+
+// GetOrganization godoc
+// @Summary 获取组织详情
+// @Schemes
+// @Description 获取组织的用户组、流量与虚拟网络数量，组织成员均可查看
+// @Tags 组织模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param orgId path string true "组织ID"
+// @Success 200 {object} v1.GetOrganizationResponse
+// @Router /org/{orgId} [get]
+func (h *OrganizationHandler) GetOrganization(ctx *gin.Context) {
+	org, role, ok := authorizeOrg(ctx, h.organizationService, ctx.Param("orgId"), model.OrgRoleMember)
+	if !ok {
+		return
+	}
+
+	item, err := h.organizationService.GetOrganization(ctx, org, role)
+	if err != nil {
+		h.handleOrganizationError(ctx, "organizationService.GetOrganization", org.OrgId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, item)
+}
+
+// PurchaseOrganizationPackage godoc
+// @Summary 为组织购买套餐
+// @Schemes
+// @Description 为组织购买套餐，规则与个人购买相同，返回订单与支付地址，付款后套餐生效于组织；需要组织的管理员或所有者
+// @Tags 组织模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param orgId path string true "组织ID"
+// @Param request body v1.PurchasePackageRequest true "params"
+// @Success 200 {object} v1.PurchasePackageResponse
+// @Router /org/{orgId}/purchase [post]
+func (h *OrganizationHandler) PurchaseOrganizationPackage(ctx *gin.Context) {
+	var req v1.PurchasePackageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	org, _, ok := authorizeOrg(ctx, h.organizationService, ctx.Param("orgId"), model.OrgRoleAdmin)
+	if !ok {
+		return
+	}
+
+	data, err := h.orderService.CreateOrder(ctx, GetUserIdFromCtx(ctx), org.OrgId, &req)
+	if err != nil {
+		h.handleOrganizationError(ctx, "orderService.CreateOrder", org.OrgId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// PreviewOrganizationPurchase godoc
+// @Summary 预览为组织购买套餐
+// @Schemes
+// @Description 确认购买前预览应付金额与生效时间，规则与个人相同；需要组织的管理员或所有者
+// @Tags 组织模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param orgId path string true "组织ID"
+// @Param request body v1.PurchasePackageRequest true "params"
+// @Success 200 {object} v1.PreviewPurchaseResponse
+// @Router /org/{orgId}/purchase/preview [post]
+func (h *OrganizationHandler) PreviewOrganizationPurchase(ctx *gin.Context) {
+	var req v1.PurchasePackageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	org, _, ok := authorizeOrg(ctx, h.organizationService, ctx.Param("orgId"), model.OrgRoleAdmin)
+	if !ok {
+		return
+	}
+
+	data, err := h.orderService.PreviewOrder(ctx, GetUserIdFromCtx(ctx), org.OrgId, &req)
+	if err != nil {
+		h.handleOrganizationError(ctx, "orderService.PreviewOrder", org.OrgId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// GetOrganizationMembers godoc
+// @Summary 获取组织成员
+// @Schemes
+// @Description 获取组织的全部成员及其角色，组织成员均可查看
+// @Tags 组织模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param orgId path string true "组织ID"
+// @Success 200 {object} v1.GetOrganizationMembersResponse
+// @Router /org/{orgId}/members [get]
+func (h *OrganizationHandler) GetOrganizationMembers(ctx *gin.Context) {
+	org, _, ok := authorizeOrg(ctx, h.organizationService, ctx.Param("orgId"), model.OrgRoleMember)
+	if !ok {
+		return
+	}
+
+	data, err := h.organizationService.GetMembers(ctx, org.OrgId)
+	if err != nil {
+		h.handleOrganizationError(ctx, "organizationService.GetMembers", org.OrgId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// SetOrganizationMember godoc
+// @Summary 设置组织成员
+// @Schemes
+// @Description 按用户名或邮箱添加成员，已是成员时修改角色；只能授予比自己低的角色
+// @Tags 组织模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param orgId path string true "组织ID"
+// @Param request body v1.SetOrganizationMemberRequest true "params"
+// @Success 200 {object} v1.Response
+// @Router /org/{orgId}/members [put]
+func (h *OrganizationHandler) SetOrganizationMember(ctx *gin.Context) {
+	var req v1.SetOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	org, role, ok := authorizeOrg(ctx, h.organizationService, ctx.Param("orgId"), model.OrgRoleAdmin)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.SetMember(ctx, org, GetUserIdFromCtx(ctx), role, &req); err != nil {
+		h.handleOrganizationError(ctx, "organizationService.SetMember", org.OrgId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// RemoveOrganizationMember godoc
+// @Summary 移除组织成员
+// @Schemes
+// @Description 移除角色比自己低的成员，成员也可以移除自己以退出组织；所有者不能退出
+// @Tags 组织模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param orgId path string true "组织ID"
+// @Param userId path string true "成员的用户ID"
+// @Success 200 {object} v1.Response
+// @Router /org/{orgId}/members/{userId} [delete]
+func (h *OrganizationHandler) RemoveOrganizationMember(ctx *gin.Context) {
+	org, role, ok := authorizeOrg(ctx, h.organizationService, ctx.Param("orgId"), model.OrgRoleMember)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(ctx, org, GetUserIdFromCtx(ctx), role, ctx.Param("userId")); err != nil {
+		h.handleOrganizationError(ctx, "organizationService.RemoveMember", org.OrgId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// handleOrganizationError 将组织管理的错误转换为响应
+func (h *OrganizationHandler) handleOrganizationError(ctx *gin.Context, op string, orgId string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+	case errors.Is(err, v1.ErrVnetClientsLimitExceeded):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetClientsLimitExceeded, nil)
+	case errors.Is(err, v1.ErrVnetLimitExceeded):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetLimitExceeded, nil)
+	case errors.Is(err, v1.ErrCannotDowngrade):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrCannotDowngrade, nil)
+	case errors.Is(err, v1.ErrForbidden):
+		v1.HandleError(ctx, http.StatusForbidden, v1.ErrForbidden, nil)
+	case errors.Is(err, v1.ErrNotFound):
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.String("orgId", orgId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
+}
+
+// authorizeOrg 校验当前用户在组织中至少拥有 role 角色，返回组织与当前用户的角色，校验失败时已写入错误响应
+func authorizeOrg(ctx *gin.Context, organizationService service.OrganizationService, orgId string, role string) (*model.Organization, string, bool) {
+	userId := GetUserIdFromCtx(ctx)
+	if userId == "" {
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+		return nil, "", false
+	}
+	if orgId == "" {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return nil, "", false
+	}
+
+	org, actual, err := organizationService.Authorize(ctx, orgId, userId, role)
+	switch {
+	case errors.Is(err, v1.ErrForbidden):
+		v1.HandleError(ctx, http.StatusForbidden, v1.ErrForbidden, nil)
+		return nil, "", false
+	case err != nil:
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+		return nil, "", false
+	}
+	return org, actual, true
+}
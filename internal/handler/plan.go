@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PlanHandler 套餐目录
+type PlanHandler struct {
+	*Handler
+	planService service.PlanService
+}
+
+func NewPlanHandler(
+	handler *Handler,
+	planService service.PlanService,
+) *PlanHandler {
+	return &PlanHandler{
+		Handler:     handler,
+		planService: planService,
+	}
+}
+
+// GetPlans godoc
+// @Summary 获取套餐列表
+// @Schemes
+// @Description 获取商店页展示的套餐及其价格、可购买时长、额度与功能，包括免费套餐，不需要登录
+// @Tags 订单模块
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.GetPlansResponse
+// @Router /plans [get]
+func (h *PlanHandler) GetPlans(ctx *gin.Context) {
+	data, err := h.planService.GetPlans(ctx)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("planService.GetPlans error", zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
@@ -4,12 +4,14 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
 	"time"
 
 	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
 	"hyacinth-backend/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -18,9 +20,11 @@ import (
 
 type UserHandler struct {
 	*Handler
-	userService  service.UserService
-	usageService service.UsageService
-	vnetService  service.VnetService
+	userService         service.UserService
+	usageService        service.UsageService
+	vnetService         service.VnetService
+	organizationService service.OrganizationService
+	orderService        service.OrderService
 }
 
 func NewUserHandler(
@@ -28,12 +32,16 @@ func NewUserHandler(
 	userService service.UserService,
 	usageService service.UsageService,
 	vnetService service.VnetService,
+	organizationService service.OrganizationService,
+	orderService service.OrderService,
 ) *UserHandler {
 	return &UserHandler{
-		Handler:      handler,
-		userService:  userService,
-		usageService: usageService,
-		vnetService:  vnetService,
+		Handler:             handler,
+		userService:         userService,
+		usageService:        usageService,
+		vnetService:         vnetService,
+		organizationService: organizationService,
+		orderService:        orderService,
 	}
 }
 
@@ -196,7 +204,7 @@ func (h *UserHandler) UpdateProfile(ctx *gin.Context) {
 // PurchasePackage godoc
 // @Summary 购买增值服务套餐
 // @Schemes
-// @Description 购买增值服务套餐，传入套餐号(2=青铜,3=白银,4=黄金)和购买时长(1-12个月)
+// @Description 购买增值服务套餐，传入套餐对应的用户组与购买时长，可购买的套餐与时长见 GET /plans；返回订单与支付地址，付款后套餐生效
 // @Tags 用户模块
 // @Accept json
 // @Produce json
@@ -217,52 +225,66 @@ func (h *UserHandler) PurchasePackage(ctx *gin.Context) {
 		return
 	}
 
-	// 获取当前用户信息
-	user, err := h.userService.GetUserByID(ctx, userId)
+	// 降级时虚拟网络不能超过新套餐的限制，由下单时检查
+	data, err := h.orderService.CreateOrder(ctx, userId, "", &req)
 	if err != nil {
-		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		h.handlePurchaseError(ctx, "orderService.CreateOrder", userId, err)
 		return
 	}
 
-	// 如果是降级套餐，需要检查当前虚拟网络的最大连接数设置是否超过新套餐限制
-	if user.UserGroup > req.PackageType {
-		// 获取新套餐的最大在线人数限制
-		var newMaxClientsLimit int
-		switch req.PackageType {
-		case 1: // 普通用户
-			newMaxClientsLimit = 3
-		case 2: // 青铜用户
-			newMaxClientsLimit = 5
-		case 3: // 白银用户
-			newMaxClientsLimit = 10
-		case 4: // 黄金用户
-			newMaxClientsLimit = 999999 // 无限制
-		default:
-			newMaxClientsLimit = 3
-		}
+	v1.HandleSuccess(ctx, data)
+}
 
-		// 获取用户的所有虚拟网络
-		vnets, err := h.vnetService.GetVnetByUserId(ctx, userId)
-		if err != nil {
-			v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
-			return
-		}
+// PreviewPurchase godoc
+// @Summary 预览购买增值服务套餐
+// @Schemes
+// @Description 确认购买前预览应付金额与生效时间：升级立即生效，当前套餐的剩余价值折算为新套餐的时长；降级在当前特权到期时生效；校验规则与购买相同
+// @Tags 用户模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body v1.PurchasePackageRequest true "购买套餐请求参数"
+// @Success 200 {object} v1.PreviewPurchaseResponse
+// @Router /user/purchase/preview [post]
+func (h *UserHandler) PreviewPurchase(ctx *gin.Context) {
+	userId := GetUserIdFromCtx(ctx)
+	if userId == "" {
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+		return
+	}
 
-		// 检查是否有虚拟网络的最大连接数设置超过新套餐限制
-		for _, vnet := range *vnets {
-			if vnet.ClientsLimit > newMaxClientsLimit {
-				v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetClientsLimitExceeded, nil)
-				return
-			}
-		}
+	var req v1.PurchasePackageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
 	}
 
-	if err := h.userService.PurchasePackage(ctx, userId, &req); err != nil {
-		v1.HandleError(ctx, http.StatusInternalServerError, err, nil)
+	data, err := h.orderService.PreviewOrder(ctx, userId, "", &req)
+	if err != nil {
+		h.handlePurchaseError(ctx, "orderService.PreviewOrder", userId, err)
 		return
 	}
 
-	v1.HandleSuccess(ctx, nil)
+	v1.HandleSuccess(ctx, data)
+}
+
+// handlePurchaseError 将下单与预览的错误转换为响应
+func (h *UserHandler) handlePurchaseError(ctx *gin.Context, op string, userId string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+	case errors.Is(err, v1.ErrVnetClientsLimitExceeded):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetClientsLimitExceeded, nil)
+	case errors.Is(err, v1.ErrVnetLimitExceeded):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetLimitExceeded, nil)
+	case errors.Is(err, v1.ErrCannotDowngrade):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrCannotDowngrade, nil)
+	case errors.Is(err, v1.ErrNotFound):
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.String("userId", userId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
 }
 
 // GetUsage godoc
@@ -274,7 +296,7 @@ func (h *UserHandler) PurchasePackage(ctx *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Param userId query string false "用户ID，可选" "1"
-// @Param vnetId query string false "虚拟网络ID，可选，空值表示所有虚拟网络" "vnet_123"
+// @Param vnetId query string false "虚拟网络ID，可选，空值表示当前用户的所有虚拟网络，需至少拥有查看者角色" "vnet_123"
 // @Param range query string true "时间范围：24h(24小时), 7d(7天), 30d(30天), month(按月), all(全部)" "30d"
 // @Success 200 {object} v1.GetUsageResponse
 // @Router /usage [get]
@@ -290,6 +312,13 @@ func (h *UserHandler) GetUsage(ctx *gin.Context) {
 		return
 	}
 	req.UserId = userId
+	if req.VnetId != "" {
+		// 虚拟网络的流量记录在创建者名下，协作者按角色查看整个虚拟网络的使用量
+		if _, _, ok := authorizeVnetId(ctx, h.vnetService, userId, req.VnetId, model.VnetRoleViewer); !ok {
+			return
+		}
+		req.UserId = ""
+	}
 
 	usage, err := h.usageService.GetUsage(ctx, &req)
 	if err != nil {
@@ -302,7 +331,7 @@ func (h *UserHandler) GetUsage(ctx *gin.Context) {
 // GetVNetList godoc
 // @Summary 获取用户的虚拟网络列表
 // @Schemes
-// @Description 获取当前用户的所有虚拟网络
+// @Description 获取当前用户的所有虚拟网络，包括其他用户共享给当前用户的
 // @Tags 虚拟网络模块
 // @Accept json
 // @Produce json
@@ -322,25 +351,56 @@ func (h *UserHandler) GetVNetList(ctx *gin.Context) {
 		return
 	}
 
-	// 转换为API响应格式
-	var responseItems []v1.GetVnetByUserIdResponseItem
+	shared, roles, err := h.vnetService.GetSharedVnets(ctx, userId)
+	if err != nil {
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+
+	// 自己的虚拟网络在前，组织的与共享的在后；自己创建的组织虚拟网络随组织列出
+	var all []model.Vnet
 	if vnets != nil {
 		for _, vnet := range *vnets {
-			responseItems = append(responseItems, v1.GetVnetByUserIdResponseItem{
-				VnetId: vnet.VnetId,
-				VnetProfile: v1.VnetProfile{
-					VnetId:       vnet.VnetId,
-					Comment:      vnet.Comment,
-					Enabled:      vnet.Enabled,
-					Token:        vnet.Token,
-					Password:     vnet.Password,
-					IpRange:      vnet.IpRange,
-					EnableDHCP:   vnet.EnableDHCP,
-					ClientsLimit: vnet.ClientsLimit,
-				},
-				ClientsOnline: vnet.ClientsOnline,
-			})
+			if vnet.OrgId == "" {
+				all = append(all, vnet)
+			}
+		}
+	}
+	all = append(all, *shared...)
+
+	// 转换为API响应格式
+	var responseItems []v1.GetVnetByUserIdResponseItem
+	for _, vnet := range all {
+		role := model.VnetRoleOwner
+		if vnet.OrgId != "" || vnet.UserId != userId {
+			role = roles[vnet.VnetId]
 		}
+		item := v1.GetVnetByUserIdResponseItem{
+			VnetId: vnet.VnetId,
+			OrgId:  vnet.OrgId,
+			Type:   vnet.Type,
+			VnetProfile: v1.VnetProfile{
+				VnetId:          vnet.VnetId,
+				Comment:         vnet.Comment,
+				Enabled:         vnet.Enabled,
+				Token:           vnet.Token,
+				IpRange:         vnet.IpRange,
+				EnableDHCP:      vnet.EnableDHCP,
+				RequireApproval: vnet.RequireApproval,
+				ClientsLimit:    vnet.ClientsLimit,
+				Region:          vnet.Region,
+			},
+			ClientsOnline: vnet.ClientsOnline,
+			HasPassword:   vnet.PasswordHash != "",
+			SuspendReason: vnet.SuspendReason,
+			NodeId:        vnet.NodeId,
+			Role:          role,
+		}
+		// 查看者不能获取接入令牌
+		if role == model.VnetRoleViewer {
+			item.Token = ""
+		}
+		responseItems = append(responseItems, item)
 	}
 
 	response := v1.GetVnetResponseData{
@@ -359,7 +419,7 @@ func (h *UserHandler) GetVNetList(ctx *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Param request body v1.CreateVnetRequest true "创建虚拟网络请求参数"
-// @Success 200 {object} v1.Response
+// @Success 200 {object} v1.CreateVnetResponse
 // @Router /vnet [post]
 func (h *UserHandler) CreateVNet(ctx *gin.Context) {
 	userId := GetUserIdFromCtx(ctx)
@@ -375,45 +435,66 @@ func (h *UserHandler) CreateVNet(ctx *gin.Context) {
 		return
 	}
 
+	// 组织成员可以在组织下创建虚拟网络
+	if req.OrgId != "" {
+		if _, _, ok := authorizeOrg(ctx, h.organizationService, req.OrgId, model.OrgRoleMember); !ok {
+			return
+		}
+	}
+
 	// 生成唯一的VnetId
 	req.VnetId = generateVnetId(userId)
 
-	// 检查vnet名称是否已存在
-	exists, err := h.vnetService.CheckVnetTokenExists(ctx, req.Token, "")
+	// 检查vnet名称是否已存在，WireGuard 虚拟网络不使用令牌
+	if req.Type != model.VnetTypeWireGuard {
+		exists, err := h.vnetService.CheckVnetTokenExists(ctx, req.Token, "")
+		if err != nil {
+			v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+			return
+		}
+		if exists {
+			v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetTokenAlreadyUse, nil)
+			return
+		}
+	}
+
+	// 获取权益来源用于验证，组织的虚拟网络按组织的权益计算
+	owner, err := h.vnetService.GetSubscriber(ctx, userId, req.OrgId)
 	if err != nil {
 		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
 		return
 	}
-	if exists {
-		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetTokenAlreadyUse, nil)
-		return
-	}
 
-	// 获取用户信息用于验证
-	user, err := h.userService.GetUserByID(ctx, userId)
-	if err != nil {
-		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	// WireGuard 虚拟网络需要套餐开通该功能
+	if req.Type == model.VnetTypeWireGuard && !owner.HasFeature(model.PlanFeatureWireGuard) {
+		v1.HandleError(ctx, http.StatusForbidden, v1.ErrPlanFeatureUnavailable, nil)
 		return
 	}
 
 	// 检查客户端数量限制
-	maxClientsLimit := user.GetMaxClientsLimitPerVNet()
+	maxClientsLimit := owner.GetMaxClientsLimitPerVNet()
 	if req.ClientsLimit > maxClientsLimit {
 		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetClientsLimitExceeded, nil)
 		return
 	}
 
+	// 流量耗尽时不允许启用虚拟网络
+	if req.Enabled && owner.GetRemainingTraffic() <= 0 {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrTrafficExhausted, nil)
+		return
+	}
+
 	// 检查虚拟网络数量限制
 	if req.Enabled {
 		// 获取当前运行中的虚拟网络数量
-		currentRunningCount, err := h.vnetService.GetRunningVnetCount(ctx, userId)
+		currentRunningCount, err := h.runningVnetCount(ctx, userId, req.OrgId)
 		if err != nil {
 			v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
 			return
 		}
 
 		// 检查是否超过限制
-		if currentRunningCount >= user.GetVirtualNetworkLimit() {
+		if currentRunningCount >= owner.GetVirtualNetworkLimit() {
 			// 如果超过限制，返回错误给前端
 			v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetLimitExceeded, nil)
 			return
@@ -421,11 +502,18 @@ func (h *UserHandler) CreateVNet(ctx *gin.Context) {
 	}
 
 	if err := h.vnetService.CreateVnet(ctx, &req, userId); err != nil {
+		if isIpRangeError(err) {
+			v1.HandleError(ctx, http.StatusBadRequest, err, nil)
+			return
+		}
 		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
 		return
 	}
 
-	v1.HandleSuccess(ctx, nil)
+	v1.HandleSuccess(ctx, v1.CreateVnetResponseData{
+		VnetId:  req.VnetId,
+		IpRange: req.IpRange,
+	})
 }
 
 // UpdateVNet godoc
@@ -464,42 +552,43 @@ func (h *UserHandler) UpdateVNet(ctx *gin.Context) {
 
 	// fmt.Println("UpdateVNet request:", req)
 
-	// 验证VNet是否属于当前用户
-	vnet, err := h.vnetService.GetVnetByVnetId(ctx, vnetId)
-	if err != nil {
-		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
-		return
-	}
-	if vnet.UserId != userId {
-		v1.HandleError(ctx, http.StatusForbidden, v1.ErrForbidden, nil)
+	// 所有者与管理员可以修改设置
+	vnet, _, ok := authorizeVnet(ctx, h.vnetService, model.VnetRoleAdmin)
+	if !ok {
 		return
 	}
 
-	// 获取用户信息用于验证
-	user, err := h.userService.GetUserByID(ctx, userId)
+	// 限制按所有者的权益计算，组织的虚拟网络按组织的权益计算
+	owner, err := h.vnetService.GetSubscriber(ctx, vnet.UserId, vnet.OrgId)
 	if err != nil {
 		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
 		return
 	}
 
 	// 检查客户端数量限制
-	maxClientsLimit := user.GetMaxClientsLimitPerVNet()
-	if maxClientsLimit != 999999 && req.ClientsLimit > maxClientsLimit {
+	maxClientsLimit := owner.GetMaxClientsLimitPerVNet()
+	if req.ClientsLimit > maxClientsLimit {
 		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetClientsLimitExceeded, nil)
 		return
 	}
 
+	// 流量耗尽时不允许启用虚拟网络
+	if req.Enabled && !vnet.Enabled && owner.GetRemainingTraffic() <= 0 {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrTrafficExhausted, nil)
+		return
+	}
+
 	// 检查虚拟网络数量限制（如果要启用网络）
 	if req.Enabled && !vnet.Enabled {
 		// 获取当前运行中的虚拟网络数量
-		currentRunningCount, err := h.vnetService.GetRunningVnetCount(ctx, userId)
+		currentRunningCount, err := h.runningVnetCount(ctx, vnet.UserId, vnet.OrgId)
 		if err != nil {
 			v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
 			return
 		}
 
 		// 检查是否超过限制
-		if currentRunningCount >= user.GetVirtualNetworkLimit() {
+		if currentRunningCount >= owner.GetVirtualNetworkLimit() {
 			// 如果超过限制，返回错误给前端
 			v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetLimitExceeded, nil)
 			return
@@ -510,17 +599,23 @@ func (h *UserHandler) UpdateVNet(ctx *gin.Context) {
 	// fmt.Println(req.Token, vnetId)
 	// fmt.Println(">>>>>>>>>>>>>>>>>>>>>>>>>>>>>")
 
-	exists, err := h.vnetService.CheckVnetTokenExists(ctx, req.Token, vnetId)
-	if err != nil {
-		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
-		return
-	}
-	if exists {
-		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetTokenAlreadyUse, nil)
-		return
+	if vnet.Type != model.VnetTypeWireGuard {
+		exists, err := h.vnetService.CheckVnetTokenExists(ctx, req.Token, vnetId)
+		if err != nil {
+			v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+			return
+		}
+		if exists {
+			v1.HandleError(ctx, http.StatusBadRequest, v1.ErrVnetTokenAlreadyUse, nil)
+			return
+		}
 	}
 
 	if err := h.vnetService.UpdateVnet(ctx, &req); err != nil {
+		if isIpRangeError(err) {
+			v1.HandleError(ctx, http.StatusBadRequest, err, nil)
+			return
+		}
 		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
 		return
 	}
@@ -552,14 +647,8 @@ func (h *UserHandler) DeleteVNet(ctx *gin.Context) {
 		return
 	}
 
-	// 验证VNet是否属于当前用户
-	vnet, err := h.vnetService.GetVnetByVnetId(ctx, vnetId)
-	if err != nil {
-		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
-		return
-	}
-	if vnet.UserId != userId {
-		v1.HandleError(ctx, http.StatusForbidden, v1.ErrForbidden, nil)
+	// 只有所有者可以删除
+	if _, _, ok := authorizeVnet(ctx, h.vnetService, model.VnetRoleOwner); !ok {
 		return
 	}
 
@@ -610,11 +699,12 @@ func (h *UserHandler) GetUserGroup(ctx *gin.Context) {
 // GetVNetLimitInfo godoc
 // @Summary 获取用户的虚拟网络限制信息
 // @Schemes
-// @Description 获取当前用户的虚拟网络限制和使用情况
+// @Description 获取当前用户的虚拟网络限制和使用情况，指定组织时获取组织的限制
 // @Tags 虚拟网络模块
 // @Accept json
 // @Produce json
 // @Security Bearer
+// @Param orgId query string false "组织ID，可选"
 // @Success 200 {object} v1.GetVNetLimitInfoResponse
 // @Router /vnet/limit [get]
 func (h *UserHandler) GetVNetLimitInfo(ctx *gin.Context) {
@@ -624,15 +714,26 @@ func (h *UserHandler) GetVNetLimitInfo(ctx *gin.Context) {
 		return
 	}
 
-	// 获取用户信息
-	user, err := h.userService.GetUserByID(ctx, userId)
+	var req v1.GetVNetLimitInfoRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	if req.OrgId != "" {
+		if _, _, ok := authorizeOrg(ctx, h.organizationService, req.OrgId, model.OrgRoleMember); !ok {
+			return
+		}
+	}
+
+	// 获取权益来源
+	owner, err := h.vnetService.GetSubscriber(ctx, userId, req.OrgId)
 	if err != nil {
 		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
 		return
 	}
 
 	// 获取当前运行中的虚拟网络数量
-	currentRunningCount, err := h.vnetService.GetRunningVnetCount(ctx, userId)
+	currentRunningCount, err := h.runningVnetCount(ctx, userId, req.OrgId)
 	if err != nil {
 		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
 		return
@@ -641,14 +742,28 @@ func (h *UserHandler) GetVNetLimitInfo(ctx *gin.Context) {
 	// 构造响应数据
 	response := v1.GetVNetLimitInfoResponseData{
 		CurrentCount:           currentRunningCount,
-		MaxLimit:               user.GetVirtualNetworkLimit(),
-		UserGroup:              user.UserGroup,
-		MaxClientsLimitPerVNet: user.GetMaxClientsLimitPerVNet(),
+		MaxLimit:               owner.GetVirtualNetworkLimit(),
+		UserGroup:              owner.GetUserGroup(),
+		MaxClientsLimitPerVNet: owner.GetMaxClientsLimitPerVNet(),
 	}
 
 	v1.HandleSuccess(ctx, response)
 }
 
+// runningVnetCount 获取运行中的虚拟网络数量，组织的虚拟网络按组织统计
+func (h *UserHandler) runningVnetCount(ctx *gin.Context, userId string, orgId string) (int, error) {
+	if orgId != "" {
+		return h.vnetService.GetOrgRunningVnetCount(ctx, orgId)
+	}
+	return h.vnetService.GetRunningVnetCount(ctx, userId)
+}
+
+// isIpRangeError 判断是否为网段校验错误，这类错误原样返回给用户
+func isIpRangeError(err error) bool {
+	return errors.Is(err, v1.ErrInvalidIpRange) || errors.Is(err, v1.ErrIpRangeOverlap) ||
+		errors.Is(err, v1.ErrIpRangeInUse) || errors.Is(err, v1.ErrIpPoolExhausted) || errors.Is(err, v1.ErrRouteConflict)
+}
+
 // 生成VnetId的辅助函数
 func generateVnetId(userId string) string {
 	// 这里可以使用更复杂的ID生成逻辑
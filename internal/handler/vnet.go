@@ -0,0 +1,1290 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/middleware"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// VnetHandler 虚拟网络下属资源（成员、会话等）的用户接口
+type VnetHandler struct {
+	*Handler
+	vnetService             service.VnetService
+	vnetClientService       service.VnetClientService
+	ipamService             service.IpamService
+	vnetAclService          service.VnetAclService
+	vnetMemberService       service.VnetMemberService
+	vnetBanService          service.VnetBanService
+	vnetInviteService       service.VnetInviteService
+	vnetCollaboratorService service.VnetCollaboratorService
+	vnetConfigService       service.VnetConfigService
+	vnetPeerService         service.VnetPeerService
+	vnetRouteService        service.VnetRouteService
+}
+
+func NewVnetHandler(
+	handler *Handler,
+	vnetService service.VnetService,
+	vnetClientService service.VnetClientService,
+	ipamService service.IpamService,
+	vnetAclService service.VnetAclService,
+	vnetMemberService service.VnetMemberService,
+	vnetBanService service.VnetBanService,
+	vnetInviteService service.VnetInviteService,
+	vnetCollaboratorService service.VnetCollaboratorService,
+	vnetConfigService service.VnetConfigService,
+	vnetPeerService service.VnetPeerService,
+	vnetRouteService service.VnetRouteService,
+) *VnetHandler {
+	return &VnetHandler{
+		Handler:                 handler,
+		vnetService:             vnetService,
+		vnetClientService:       vnetClientService,
+		ipamService:             ipamService,
+		vnetAclService:          vnetAclService,
+		vnetMemberService:       vnetMemberService,
+		vnetBanService:          vnetBanService,
+		vnetInviteService:       vnetInviteService,
+		vnetCollaboratorService: vnetCollaboratorService,
+		vnetConfigService:       vnetConfigService,
+		vnetPeerService:         vnetPeerService,
+		vnetRouteService:        vnetRouteService,
+	}
+}
+
+// GetVnetClients godoc
+// @Summary 获取虚拟网络在线客户端
+// @Schemes
+// @Description 获取指定虚拟网络当前在线的客户端会话列表，所有者与协作者均可查看
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Success 200 {object} v1.GetVnetClientsResponse
+// @Router /vnet/{vnetId}/clients [get]
+func (h *VnetHandler) GetVnetClients(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleViewer)
+	if !ok {
+		return
+	}
+
+	clients, err := h.vnetClientService.GetVnetClients(ctx, vnet.VnetId)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("vnetClientService.GetVnetClients error", zap.String("vnetId", vnet.VnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+
+	items := make([]v1.VnetClientItem, 0, len(*clients))
+	for _, client := range *clients {
+		items = append(items, v1.VnetClientItem{
+			ClientId:       client.ClientId,
+			VirtualIp:      client.VirtualIp,
+			MacAddress:     client.MacAddress,
+			PublicEndpoint: client.PublicEndpoint,
+			NodeId:         client.NodeId,
+			ConnectedAt:    client.ConnectedAt.Format("2006-01-02 15:04:05"),
+			LastSeen:       client.LastSeen.Format("2006-01-02 15:04:05"),
+		})
+	}
+	v1.HandleSuccess(ctx, v1.GetVnetClientsResponseData{Clients: items})
+}
+
+// GetVnetLeases godoc
+// @Summary 获取虚拟网络地址租约
+// @Schemes
+// @Description 获取指定虚拟网络的动态地址租约与地址保留，所有者与协作者均可查看
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Success 200 {object} v1.GetVnetLeasesResponse
+// @Router /vnet/{vnetId}/leases [get]
+func (h *VnetHandler) GetVnetLeases(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleViewer)
+	if !ok {
+		return
+	}
+
+	leases, err := h.ipamService.GetLeases(ctx, vnet.VnetId)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("ipamService.GetLeases error", zap.String("vnetId", vnet.VnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+
+	now := time.Now()
+	items := make([]v1.IpLeaseItem, 0, len(*leases))
+	for _, lease := range *leases {
+		items = append(items, toIpLeaseItem(&lease, now))
+	}
+	v1.HandleSuccess(ctx, v1.GetVnetLeasesResponseData{Leases: items})
+}
+
+// ReserveAddress godoc
+// @Summary 保留设备地址
+// @Schemes
+// @Description 为指定设备保留固定的虚拟地址，设备每次接入都会获得该地址
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param request body v1.ReserveAddressRequest true "params"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/leases/reservations [post]
+func (h *VnetHandler) ReserveAddress(ctx *gin.Context) {
+	var req v1.ReserveAddressRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+	// WireGuard 设备的地址随公钥登记分配并写入节点的对端配置，不能单独修改
+	if vnet.Type == model.VnetTypeWireGuard {
+		v1.HandleError(ctx, http.StatusConflict, v1.ErrVnetTypeMismatch, nil)
+		return
+	}
+
+	lease, err := h.ipamService.ReserveAddress(ctx, vnet.VnetId, &req)
+	if err != nil {
+		if errors.Is(err, v1.ErrVirtualIpUnavailable) {
+			v1.HandleError(ctx, http.StatusConflict, v1.ErrVirtualIpUnavailable, nil)
+			return
+		}
+		h.logger.WithContext(ctx).Error("ipamService.ReserveAddress error", zap.String("vnetId", vnet.VnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+	v1.HandleSuccess(ctx, toIpLeaseItem(lease, time.Now()))
+}
+
+// DeleteReservation godoc
+// @Summary 取消设备地址保留
+// @Schemes
+// @Description 取消指定设备的地址保留，设备下次接入时重新分配地址
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param clientId path string true "设备标识"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/leases/reservations/{clientId} [delete]
+func (h *VnetHandler) DeleteReservation(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+	// WireGuard 设备的地址随公钥登记分配并写入节点的对端配置，不能单独修改
+	if vnet.Type == model.VnetTypeWireGuard {
+		v1.HandleError(ctx, http.StatusConflict, v1.ErrVnetTypeMismatch, nil)
+		return
+	}
+
+	if err := h.ipamService.DeleteReservation(ctx, vnet.VnetId, ctx.Param("clientId")); err != nil {
+		if errors.Is(err, v1.ErrNotFound) {
+			v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+			return
+		}
+		h.logger.WithContext(ctx).Error("ipamService.DeleteReservation error", zap.String("vnetId", vnet.VnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// GetVnetAcl godoc
+// @Summary 获取虚拟网络访问控制规则
+// @Schemes
+// @Description 获取虚拟网络的访问控制规则（按匹配顺序）与成员标签，第一条匹配的规则生效，没有规则匹配时放行
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Success 200 {object} v1.GetVnetAclResponse
+// @Router /vnet/{vnetId}/acl [get]
+func (h *VnetHandler) GetVnetAcl(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleViewer)
+	if !ok {
+		return
+	}
+
+	data, err := h.vnetAclService.GetAcl(ctx, vnet)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("vnetAclService.GetAcl error", zap.String("vnetId", vnet.VnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// CreateAclRule godoc
+// @Summary 添加访问控制规则
+// @Schemes
+// @Description 添加一条访问控制规则，规则数量受用户组限制
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param request body v1.AclRuleRequest true "params"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/acl/rules [post]
+func (h *VnetHandler) CreateAclRule(ctx *gin.Context) {
+	var req v1.AclRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleAdmin)
+	if !ok {
+		return
+	}
+
+	rule, err := h.vnetAclService.CreateRule(ctx, vnet.VnetId, &req)
+	if err != nil {
+		h.handleAclError(ctx, "vnetAclService.CreateRule", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, rule)
+}
+
+// UpdateAclRule godoc
+// @Summary 修改访问控制规则
+// @Schemes
+// @Description 修改访问控制规则，未指定优先级时保持原有顺序
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param ruleId path string true "规则ID"
+// @Param request body v1.AclRuleRequest true "params"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/acl/rules/{ruleId} [put]
+func (h *VnetHandler) UpdateAclRule(ctx *gin.Context) {
+	var req v1.AclRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleAdmin)
+	if !ok {
+		return
+	}
+
+	rule, err := h.vnetAclService.UpdateRule(ctx, vnet.VnetId, ctx.Param("ruleId"), &req)
+	if err != nil {
+		h.handleAclError(ctx, "vnetAclService.UpdateRule", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, rule)
+}
+
+// DeleteAclRule godoc
+// @Summary 删除访问控制规则
+// @Schemes
+// @Description 删除访问控制规则
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param ruleId path string true "规则ID"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/acl/rules/{ruleId} [delete]
+func (h *VnetHandler) DeleteAclRule(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleAdmin)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetAclService.DeleteRule(ctx, vnet.VnetId, ctx.Param("ruleId")); err != nil {
+		h.handleAclError(ctx, "vnetAclService.DeleteRule", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// SetMemberTags godoc
+// @Summary 设置成员标签
+// @Schemes
+// @Description 设置设备的全部标签，访问控制规则可通过 tag:<标签> 选择成员
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param clientId path string true "设备标识"
+// @Param request body v1.SetMemberTagsRequest true "params"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/acl/tags/{clientId} [put]
+func (h *VnetHandler) SetMemberTags(ctx *gin.Context) {
+	var req v1.SetMemberTagsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleAdmin)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetAclService.SetMemberTags(ctx, vnet.VnetId, ctx.Param("clientId"), req.Tags); err != nil {
+		h.handleAclError(ctx, "vnetAclService.SetMemberTags", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// handleAclError 将访问控制服务的错误转换为响应
+func (h *VnetHandler) handleAclError(ctx *gin.Context, op string, vnetId string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+	case errors.Is(err, v1.ErrInvalidAclRule):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrInvalidAclRule, nil)
+	case errors.Is(err, v1.ErrAclRuleLimitExceeded):
+		v1.HandleError(ctx, http.StatusForbidden, v1.ErrAclRuleLimitExceeded, nil)
+	case errors.Is(err, v1.ErrNotFound):
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.String("vnetId", vnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
+}
+
+// GetVnetMembers godoc
+// @Summary 获取设备审批记录
+// @Schemes
+// @Description 获取虚拟网络中待审批和已批准的设备，可按状态筛选
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param status query string false "状态：pending、approved"
+// @Success 200 {object} v1.GetVnetMembersResponse
+// @Router /vnet/{vnetId}/members [get]
+func (h *VnetHandler) GetVnetMembers(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleViewer)
+	if !ok {
+		return
+	}
+
+	members, err := h.vnetMemberService.GetMembers(ctx, vnet.VnetId, ctx.Query("status"))
+	if err != nil {
+		h.handleMemberError(ctx, "vnetMemberService.GetMembers", vnet.VnetId, err)
+		return
+	}
+	items := make([]v1.VnetMemberItem, 0, len(*members))
+	for _, member := range *members {
+		item := v1.VnetMemberItem{
+			ClientId:       member.ClientId,
+			Status:         member.Status,
+			MacAddress:     member.MacAddress,
+			PublicEndpoint: member.PublicEndpoint,
+			LastSeen:       member.LastSeen.Format("2006-01-02 15:04:05"),
+		}
+		if member.ReviewedAt != nil {
+			item.ReviewedAt = member.ReviewedAt.Format("2006-01-02 15:04:05")
+		}
+		items = append(items, item)
+	}
+	v1.HandleSuccess(ctx, v1.GetVnetMembersResponseData{Members: items})
+}
+
+// ApproveMember godoc
+// @Summary 批准设备接入
+// @Schemes
+// @Description 批准设备接入虚拟网络，之后重新接入无需再次审批；也可预先批准尚未申请的设备。审批结果与设备提交的设备密钥绑定，其他设备使用相同的设备标识不能沿用；预先批准时可指定设备密钥的 SHA-256，未指定时与设备首次接入时提交的密钥绑定
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param clientId path string true "设备标识"
+// @Param deviceKeyHash query string false "设备密钥的 SHA-256（十六进制）"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/members/{clientId}/approve [post]
+func (h *VnetHandler) ApproveMember(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetMemberService.ApproveMember(ctx, vnet.VnetId, ctx.Param("clientId"), ctx.Query("deviceKeyHash")); err != nil {
+		h.handleMemberError(ctx, "vnetMemberService.ApproveMember", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// RejectMember godoc
+// @Summary 拒绝设备接入
+// @Schemes
+// @Description 拒绝待审批的设备，设备之后可以重新申请
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param clientId path string true "设备标识"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/members/{clientId}/reject [post]
+func (h *VnetHandler) RejectMember(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetMemberService.RejectMember(ctx, vnet.VnetId, ctx.Param("clientId")); err != nil {
+		h.handleMemberError(ctx, "vnetMemberService.RejectMember", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// BanMember godoc
+// @Summary 封禁设备
+// @Schemes
+// @Description 永久封禁设备并断开其在线会话，等同于按设备标识添加封禁记录
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param clientId path string true "设备标识"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/members/{clientId}/ban [post]
+func (h *VnetHandler) BanMember(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	clientId := ctx.Param("clientId")
+	if len(clientId) > 64 {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	if _, err := h.vnetBanService.CreateBan(ctx, vnet.VnetId, &v1.CreateVnetBanRequest{ClientId: clientId}); err != nil {
+		h.handleMemberError(ctx, "vnetBanService.CreateBan", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// RemoveMember godoc
+// @Summary 删除设备审批记录
+// @Schemes
+// @Description 删除设备的审批记录，已批准的设备需要重新审批，同时解除与设备密钥的绑定
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param clientId path string true "设备标识"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/members/{clientId} [delete]
+func (h *VnetHandler) RemoveMember(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetMemberService.RemoveMember(ctx, vnet.VnetId, ctx.Param("clientId")); err != nil {
+		h.handleMemberError(ctx, "vnetMemberService.RemoveMember", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// KickClient godoc
+// @Summary 断开客户端
+// @Schemes
+// @Description 断开在线客户端，由承载虚拟网络的节点执行；客户端之后仍可重新接入，如需阻止请封禁
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param clientId path string true "设备标识"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/clients/{clientId}/kick [post]
+func (h *VnetHandler) KickClient(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetBanService.KickClient(ctx, vnet.VnetId, ctx.Param("clientId")); err != nil {
+		h.handleMemberError(ctx, "vnetBanService.KickClient", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// GetVnetBans godoc
+// @Summary 获取封禁列表
+// @Schemes
+// @Description 获取虚拟网络的封禁记录，包括已过期的
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Success 200 {object} v1.GetVnetBansResponse
+// @Router /vnet/{vnetId}/bans [get]
+func (h *VnetHandler) GetVnetBans(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleViewer)
+	if !ok {
+		return
+	}
+
+	bans, err := h.vnetBanService.GetBans(ctx, vnet.VnetId)
+	if err != nil {
+		h.handleMemberError(ctx, "vnetBanService.GetBans", vnet.VnetId, err)
+		return
+	}
+	now := time.Now()
+	items := make([]v1.VnetBanItem, 0, len(*bans))
+	for i := range *bans {
+		items = append(items, toVnetBanItem(&(*bans)[i], now))
+	}
+	v1.HandleSuccess(ctx, v1.GetVnetBansResponseData{Bans: items})
+}
+
+// CreateVnetBan godoc
+// @Summary 添加封禁
+// @Schemes
+// @Description 按设备标识或公网地址封禁，可设置时长，受影响的在线客户端会被断开。按设备封禁同时匹配该设备的设备密钥，设备更换标识后仍被拒绝
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param request body v1.CreateVnetBanRequest true "params"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/bans [post]
+func (h *VnetHandler) CreateVnetBan(ctx *gin.Context) {
+	var req v1.CreateVnetBanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	ban, err := h.vnetBanService.CreateBan(ctx, vnet.VnetId, &req)
+	if err != nil {
+		h.handleMemberError(ctx, "vnetBanService.CreateBan", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, toVnetBanItem(ban, time.Now()))
+}
+
+// UpdateVnetBan godoc
+// @Summary 修改封禁
+// @Schemes
+// @Description 修改封禁的原因与时长，时长从现在起重新计算
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param banId path string true "封禁ID"
+// @Param request body v1.UpdateVnetBanRequest true "params"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/bans/{banId} [put]
+func (h *VnetHandler) UpdateVnetBan(ctx *gin.Context) {
+	var req v1.UpdateVnetBanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	ban, err := h.vnetBanService.UpdateBan(ctx, vnet.VnetId, ctx.Param("banId"), &req)
+	if err != nil {
+		h.handleMemberError(ctx, "vnetBanService.UpdateBan", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, toVnetBanItem(ban, time.Now()))
+}
+
+// DeleteVnetBan godoc
+// @Summary 解除封禁
+// @Schemes
+// @Description 删除封禁记录，设备或地址可以重新接入
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param banId path string true "封禁ID"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/bans/{banId} [delete]
+func (h *VnetHandler) DeleteVnetBan(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetBanService.DeleteBan(ctx, vnet.VnetId, ctx.Param("banId")); err != nil {
+		h.handleMemberError(ctx, "vnetBanService.DeleteBan", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// GetVnetInvites godoc
+// @Summary 获取邀请列表
+// @Schemes
+// @Description 获取虚拟网络的邀请（包括已失效的）及兑换了各邀请的设备
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Success 200 {object} v1.GetVnetInvitesResponse
+// @Router /vnet/{vnetId}/invites [get]
+func (h *VnetHandler) GetVnetInvites(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	data, err := h.vnetInviteService.GetInvites(ctx, vnet.VnetId)
+	if err != nil {
+		h.handleMemberError(ctx, "vnetInviteService.GetInvites", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// CreateVnetInvite godoc
+// @Summary 创建邀请
+// @Schemes
+// @Description 创建邀请码，可设置有效期、可兑换的设备数量以及是否自动批准兑换的设备
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param request body v1.CreateVnetInviteRequest true "params"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/invites [post]
+func (h *VnetHandler) CreateVnetInvite(ctx *gin.Context) {
+	var req v1.CreateVnetInviteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	invite, err := h.vnetInviteService.CreateInvite(ctx, vnet.VnetId, &req)
+	if err != nil {
+		h.handleMemberError(ctx, "vnetInviteService.CreateInvite", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, invite)
+}
+
+// RevokeVnetInvite godoc
+// @Summary 撤销邀请
+// @Schemes
+// @Description 撤销邀请，邀请无法再兑换，通过它兑换的接入密钥同时失效；兑换记录保留
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param inviteId path string true "邀请ID"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/invites/{inviteId} [delete]
+func (h *VnetHandler) RevokeVnetInvite(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetInviteService.RevokeInvite(ctx, vnet.VnetId, ctx.Param("inviteId")); err != nil {
+		h.handleMemberError(ctx, "vnetInviteService.RevokeInvite", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// RedeemInvite godoc
+// @Summary 兑换邀请
+// @Schemes
+// @Description 凭邀请码为设备兑换接入凭据，客户端接入时提交令牌与接入密钥即可，无需密码；同一设备再次兑换时须提交当前的接入密钥
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Param request body v1.RedeemInviteRequest true "params"
+// @Success 200 {object} v1.RedeemInviteResponse
+// @Router /invite/redeem [post]
+func (h *VnetHandler) RedeemInvite(ctx *gin.Context) {
+	var req v1.RedeemInviteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+
+	data, err := h.vnetInviteService.RedeemInvite(ctx, ctx.ClientIP(), &req)
+	if err != nil {
+		h.handleMemberError(ctx, "vnetInviteService.RedeemInvite", "", err)
+		return
+	}
+	// 响应包含入网密钥，不写入日志
+	middleware.MarkSensitiveResponse(ctx)
+	v1.HandleSuccess(ctx, data)
+}
+
+// GetVnetCollaborators godoc
+// @Summary 获取协作者列表
+// @Schemes
+// @Description 获取虚拟网络的所有者与协作者及各自的角色
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Success 200 {object} v1.GetVnetCollaboratorsResponse
+// @Router /vnet/{vnetId}/collaborators [get]
+func (h *VnetHandler) GetVnetCollaborators(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleViewer)
+	if !ok {
+		return
+	}
+
+	data, err := h.vnetCollaboratorService.GetCollaborators(ctx, vnet)
+	if err != nil {
+		h.handleCollaboratorError(ctx, "vnetCollaboratorService.GetCollaborators", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// SetVnetCollaborator godoc
+// @Summary 设置协作者
+// @Schemes
+// @Description 按用户名或邮箱授予其他用户角色，已是协作者时修改角色；只能授予比自己低的角色
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param request body v1.SetVnetCollaboratorRequest true "params"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/collaborators [put]
+func (h *VnetHandler) SetVnetCollaborator(ctx *gin.Context) {
+	var req v1.SetVnetCollaboratorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, role, ok := authorizeVnet(ctx, h.vnetService, model.VnetRoleAdmin)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetCollaboratorService.SetCollaborator(ctx, vnet, GetUserIdFromCtx(ctx), role, &req); err != nil {
+		h.handleCollaboratorError(ctx, "vnetCollaboratorService.SetCollaborator", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// RemoveVnetCollaborator godoc
+// @Summary 移除协作者
+// @Schemes
+// @Description 移除角色比自己低的协作者，协作者也可以移除自己以退出协作
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param userId path string true "协作者的用户ID"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/collaborators/{userId} [delete]
+func (h *VnetHandler) RemoveVnetCollaborator(ctx *gin.Context) {
+	vnet, role, ok := authorizeVnet(ctx, h.vnetService, model.VnetRoleViewer)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetCollaboratorService.RemoveCollaborator(ctx, vnet, GetUserIdFromCtx(ctx), role, ctx.Param("userId")); err != nil {
+		h.handleCollaboratorError(ctx, "vnetCollaboratorService.RemoveCollaborator", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// GetVnetAuditLogs godoc
+// @Summary 获取审计日志
+// @Schemes
+// @Description 获取虚拟网络最近的审计日志，包括协作者角色的变更
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Success 200 {object} v1.GetVnetAuditLogsResponse
+// @Router /vnet/{vnetId}/audit [get]
+func (h *VnetHandler) GetVnetAuditLogs(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleAdmin)
+	if !ok {
+		return
+	}
+
+	data, err := h.vnetCollaboratorService.GetAuditLogs(ctx, vnet.VnetId)
+	if err != nil {
+		h.handleCollaboratorError(ctx, "vnetCollaboratorService.GetAuditLogs", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// handleCollaboratorError 将协作者管理的错误转换为响应
+func (h *VnetHandler) handleCollaboratorError(ctx *gin.Context, op string, vnetId string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+	case errors.Is(err, v1.ErrForbidden):
+		v1.HandleError(ctx, http.StatusForbidden, v1.ErrForbidden, nil)
+	case errors.Is(err, v1.ErrNotFound):
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.String("vnetId", vnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
+}
+
+// GetVnetConfig godoc
+// @Summary 下载客户端配置
+// @Schemes
+// @Description 生成虚拟网络的客户端配置文件，包含承载节点地址、令牌与地址设置；密码以占位符输出。需要操作员及以上角色
+// @Tags 虚拟网络模块
+// @Produce plain
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param format query string false "配置格式：n2n、wireguard、json，默认 json"
+// @Param clientId query string false "设备ID，指定时使用其保留地址"
+// @Success 200 {file} file
+// @Router /vnet/{vnetId}/config [get]
+func (h *VnetHandler) GetVnetConfig(ctx *gin.Context) {
+	var req v1.GetVnetConfigRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	config, err := h.vnetConfigService.RenderConfig(ctx, vnet, &req)
+	if err != nil {
+		h.handleConfigError(ctx, "vnetConfigService.RenderConfig", vnet.VnetId, err)
+		return
+	}
+	ctx.Header("Content-Disposition", `attachment; filename="`+config.FileName+`"`)
+	ctx.Data(http.StatusOK, config.ContentType, config.Content)
+}
+
+// GetVnetConfigQRCode godoc
+// @Summary 获取客户端配置二维码
+// @Schemes
+// @Description 将客户端配置编码为二维码 PNG，供移动端扫码导入；参数与下载配置相同
+// @Tags 虚拟网络模块
+// @Produce png
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param format query string false "配置格式：n2n、wireguard、json，默认 json"
+// @Param clientId query string false "设备ID，指定时使用其保留地址"
+// @Success 200 {file} file
+// @Router /vnet/{vnetId}/config/qr [get]
+func (h *VnetHandler) GetVnetConfigQRCode(ctx *gin.Context) {
+	var req v1.GetVnetConfigRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	png, err := h.vnetConfigService.RenderQRCode(ctx, vnet, &req)
+	if err != nil {
+		h.handleConfigError(ctx, "vnetConfigService.RenderQRCode", vnet.VnetId, err)
+		return
+	}
+	ctx.Data(http.StatusOK, "image/png", png)
+}
+
+// handleConfigError 将客户端配置生成的错误转换为响应
+func (h *VnetHandler) handleConfigError(ctx *gin.Context, op string, vnetId string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+	case errors.Is(err, v1.ErrVnetNodeUnassigned):
+		v1.HandleError(ctx, http.StatusConflict, v1.ErrVnetNodeUnassigned, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.String("vnetId", vnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
+}
+
+// GetVnetPeers godoc
+// @Summary 获取 WireGuard 设备列表
+// @Schemes
+// @Description 获取 WireGuard 虚拟网络登记的设备公钥、地址与承载节点的公钥，所有者与协作者均可查看
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Success 200 {object} v1.GetVnetPeersResponse
+// @Router /vnet/{vnetId}/peers [get]
+func (h *VnetHandler) GetVnetPeers(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleViewer)
+	if !ok {
+		return
+	}
+
+	data, err := h.vnetPeerService.GetPeers(ctx, vnet)
+	if err != nil {
+		h.handlePeerError(ctx, "vnetPeerService.GetPeers", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// RegisterVnetPeer godoc
+// @Summary 登记 WireGuard 设备公钥
+// @Schemes
+// @Description 为设备登记公钥并分配固定地址，设备已登记时替换公钥；私钥应由客户端生成，设置 generate 时由服务端生成，私钥只返回一次。需要操作员及以上角色
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param request body v1.RegisterVnetPeerRequest true "params"
+// @Success 200 {object} v1.RegisterVnetPeerResponse
+// @Router /vnet/{vnetId}/peers [put]
+func (h *VnetHandler) RegisterVnetPeer(ctx *gin.Context) {
+	var req v1.RegisterVnetPeerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	data, err := h.vnetPeerService.RegisterPeer(ctx, vnet.VnetId, &req)
+	if err != nil {
+		h.handlePeerError(ctx, "vnetPeerService.RegisterPeer", vnet.VnetId, err)
+		return
+	}
+	if data.PrivateKey != "" {
+		middleware.MarkSensitiveResponse(ctx)
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// DeleteVnetPeer godoc
+// @Summary 删除 WireGuard 设备
+// @Schemes
+// @Description 删除设备登记的公钥并释放其地址，节点随即断开该设备。需要操作员及以上角色
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param clientId path string true "设备标识"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/peers/{clientId} [delete]
+func (h *VnetHandler) DeleteVnetPeer(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetPeerService.DeletePeer(ctx, vnet.VnetId, ctx.Param("clientId")); err != nil {
+		h.handlePeerError(ctx, "vnetPeerService.DeletePeer", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// handlePeerError 将 WireGuard 设备管理的错误转换为响应
+func (h *VnetHandler) handlePeerError(ctx *gin.Context, op string, vnetId string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+	case errors.Is(err, v1.ErrInvalidWireGuardKey):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrInvalidWireGuardKey, nil)
+	case errors.Is(err, v1.ErrVnetTypeMismatch):
+		v1.HandleError(ctx, http.StatusConflict, v1.ErrVnetTypeMismatch, nil)
+	case errors.Is(err, v1.ErrVnetClientsFull), errors.Is(err, v1.ErrVnetAddressExhausted):
+		v1.HandleError(ctx, http.StatusForbidden, err, nil)
+	case errors.Is(err, v1.ErrNotFound):
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.String("vnetId", vnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
+}
+
+// GetVnetRoutes godoc
+// @Summary 获取虚拟网络路由
+// @Schemes
+// @Description 获取成员设备通告的子网路由与出口节点，包括待批准的路由，所有者与协作者均可查看
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Success 200 {object} v1.GetVnetRoutesResponse
+// @Router /vnet/{vnetId}/routes [get]
+func (h *VnetHandler) GetVnetRoutes(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleViewer)
+	if !ok {
+		return
+	}
+
+	data, err := h.vnetRouteService.GetRoutes(ctx, vnet)
+	if err != nil {
+		h.handleRouteError(ctx, "vnetRouteService.GetRoutes", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, data)
+}
+
+// AdvertiseRoute godoc
+// @Summary 通告路由
+// @Schemes
+// @Description 为设备通告其背后的局域网网段，或以 0.0.0.0/0、::/0 将设备设为出口节点；路由需经所有者或管理员批准后生效。需要操作员及以上角色，且所有者的套餐开通了路由功能
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param request body v1.AdvertiseRouteRequest true "params"
+// @Success 200 {object} v1.VnetRouteItem
+// @Router /vnet/{vnetId}/routes [post]
+func (h *VnetHandler) AdvertiseRoute(ctx *gin.Context) {
+	var req v1.AdvertiseRouteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return
+	}
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleOperator)
+	if !ok {
+		return
+	}
+	// 子网路由与出口节点需要所有者的套餐开通该功能，已批准的路由不受之后套餐变化的影响
+	owner, err := h.vnetService.GetSubscriber(ctx, vnet.UserId, vnet.OrgId)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("vnetService.GetSubscriber error", zap.String("vnetId", vnet.VnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+		return
+	}
+	if !owner.HasFeature(model.PlanFeatureRoutes) {
+		v1.HandleError(ctx, http.StatusForbidden, v1.ErrPlanFeatureUnavailable, nil)
+		return
+	}
+
+	item, err := h.vnetRouteService.AdvertiseRoute(ctx, vnet.VnetId, &req)
+	if err != nil {
+		h.handleRouteError(ctx, "vnetRouteService.AdvertiseRoute", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, item)
+}
+
+// ApproveRoute godoc
+// @Summary 批准路由
+// @Schemes
+// @Description 批准设备通告的路由，与网段或其他已批准路由冲突时拒绝；批准后路由表随配置下发给节点。需要管理员及以上角色
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param routeId path string true "路由ID"
+// @Success 200 {object} v1.VnetRouteItem
+// @Router /vnet/{vnetId}/routes/{routeId}/approve [post]
+func (h *VnetHandler) ApproveRoute(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleAdmin)
+	if !ok {
+		return
+	}
+
+	item, err := h.vnetRouteService.ApproveRoute(ctx, vnet.VnetId, ctx.Param("routeId"))
+	if err != nil {
+		h.handleRouteError(ctx, "vnetRouteService.ApproveRoute", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, item)
+}
+
+// DeleteRoute godoc
+// @Summary 删除路由
+// @Schemes
+// @Description 拒绝待批准的路由或撤销已批准的路由。需要管理员及以上角色
+// @Tags 虚拟网络模块
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param vnetId path string true "虚拟网络ID"
+// @Param routeId path string true "路由ID"
+// @Success 200 {object} v1.Response
+// @Router /vnet/{vnetId}/routes/{routeId} [delete]
+func (h *VnetHandler) DeleteRoute(ctx *gin.Context) {
+	vnet, ok := h.authorizeVnet(ctx, model.VnetRoleAdmin)
+	if !ok {
+		return
+	}
+
+	if err := h.vnetRouteService.DeleteRoute(ctx, vnet.VnetId, ctx.Param("routeId")); err != nil {
+		h.handleRouteError(ctx, "vnetRouteService.DeleteRoute", vnet.VnetId, err)
+		return
+	}
+	v1.HandleSuccess(ctx, nil)
+}
+
+// handleRouteError 将路由管理的错误转换为响应
+func (h *VnetHandler) handleRouteError(ctx *gin.Context, op string, vnetId string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+	case errors.Is(err, v1.ErrInvalidRoute):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrInvalidRoute, nil)
+	case errors.Is(err, v1.ErrRouteConflict):
+		v1.HandleError(ctx, http.StatusConflict, v1.ErrRouteConflict, nil)
+	case errors.Is(err, v1.ErrRouteLimitExceeded):
+		v1.HandleError(ctx, http.StatusForbidden, v1.ErrRouteLimitExceeded, nil)
+	case errors.Is(err, v1.ErrNotFound):
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.String("vnetId", vnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
+}
+
+func toVnetBanItem(ban *model.VnetBan, now time.Time) v1.VnetBanItem {
+	item := v1.VnetBanItem{
+		BanId:     ban.BanId,
+		ClientId:  ban.ClientId,
+		Ip:        ban.Ip,
+		Reason:    ban.Reason,
+		CreatedAt: ban.CreatedAt.Format("2006-01-02 15:04:05"),
+		Active:    ban.IsActive(now),
+	}
+	if ban.ExpiresAt != nil {
+		item.ExpiresAt = ban.ExpiresAt.Format("2006-01-02 15:04:05")
+	}
+	return item
+}
+
+// handleMemberError 将设备审批、断开、封禁与邀请的错误转换为响应
+func (h *VnetHandler) handleMemberError(ctx *gin.Context, op string, vnetId string, err error) {
+	switch {
+	case errors.Is(err, v1.ErrBadRequest):
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+	case errors.Is(err, v1.ErrNotFound):
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+	case errors.Is(err, v1.ErrMemberBanned):
+		v1.HandleError(ctx, http.StatusForbidden, v1.ErrMemberBanned, nil)
+	case errors.Is(err, v1.ErrInviteUnavailable):
+		v1.HandleError(ctx, http.StatusGone, v1.ErrInviteUnavailable, nil)
+	case errors.Is(err, v1.ErrClientIdInUse):
+		v1.HandleError(ctx, http.StatusConflict, v1.ErrClientIdInUse, nil)
+	case errors.Is(err, v1.ErrDeviceKeyInvalid):
+		v1.HandleError(ctx, http.StatusConflict, v1.ErrDeviceKeyInvalid, nil)
+	default:
+		h.logger.WithContext(ctx).Error(op+" error", zap.String("vnetId", vnetId), zap.Error(err))
+		v1.HandleError(ctx, http.StatusInternalServerError, v1.ErrInternalServerError, nil)
+	}
+}
+
+func toIpLeaseItem(lease *model.IpLease, now time.Time) v1.IpLeaseItem {
+	item := v1.IpLeaseItem{
+		ClientId:   lease.ClientId,
+		Address:    lease.Address,
+		MacAddress: lease.MacAddress,
+		Static:     lease.Static,
+		Active:     lease.IsActive(now),
+	}
+	if lease.ExpiresAt != nil {
+		item.ExpiresAt = lease.ExpiresAt.Format("2006-01-02 15:04:05")
+	}
+	return item
+}
+
+// authorizeVnet 获取路径中的虚拟网络并校验当前用户至少拥有 role 角色，校验失败时已写入错误响应
+func (h *VnetHandler) authorizeVnet(ctx *gin.Context, role string) (*model.Vnet, bool) {
+	vnet, _, ok := authorizeVnet(ctx, h.vnetService, role)
+	return vnet, ok
+}
+
+// authorizeVnet 所有虚拟网络接口共用的权限校验，返回虚拟网络与当前用户的角色，校验失败时已写入错误响应
+func authorizeVnet(ctx *gin.Context, vnetService service.VnetService, role string) (*model.Vnet, string, bool) {
+	userId := GetUserIdFromCtx(ctx)
+	if userId == "" {
+		v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+		return nil, "", false
+	}
+
+	vnetId := ctx.Param("vnetId")
+	if vnetId == "" {
+		v1.HandleError(ctx, http.StatusBadRequest, v1.ErrBadRequest, nil)
+		return nil, "", false
+	}
+	return authorizeVnetId(ctx, vnetService, userId, vnetId, role)
+}
+
+// authorizeVnetId 校验当前用户对指定虚拟网络至少拥有 role 角色，用于虚拟网络ID不在路径中的接口
+func authorizeVnetId(ctx *gin.Context, vnetService service.VnetService, userId string, vnetId string, role string) (*model.Vnet, string, bool) {
+	vnet, actual, err := vnetService.Authorize(ctx, vnetId, userId, role)
+	switch {
+	case errors.Is(err, v1.ErrForbidden):
+		v1.HandleError(ctx, http.StatusForbidden, v1.ErrForbidden, nil)
+		return nil, "", false
+	case err != nil:
+		v1.HandleError(ctx, http.StatusNotFound, v1.ErrNotFound, nil)
+		return nil, "", false
+	}
+	return vnet, actual, true
+}
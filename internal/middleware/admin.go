@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/pkg/jwt"
+	"hyacinth-backend/pkg/log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// AdminAuth 管理员接口鉴权，需在 StrictAuth 之后使用，管理员由配置 admin.user_ids 指定
+func AdminAuth(conf *viper.Viper, logger *log.Logger) gin.HandlerFunc {
+	admins := make(map[string]bool)
+	for _, userId := range conf.GetStringSlice("admin.user_ids") {
+		admins[userId] = true
+	}
+	return func(ctx *gin.Context) {
+		v, _ := ctx.Get("claims")
+		claims, ok := v.(*jwt.MyCustomClaims)
+		if !ok || !admins[claims.UserId] {
+			logger.WithContext(ctx).Warn("admin auth failed", zap.Any("data", map[string]interface{}{
+				"url": ctx.Request.URL,
+			}))
+			v1.HandleError(ctx, http.StatusForbidden, v1.ErrForbidden, nil)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -32,6 +32,14 @@ func RequestLogMiddleware(logger *log.Logger) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+// sensitiveResponseKey 响应包含私钥等敏感内容时设置，日志中不记录响应体
+const sensitiveResponseKey = "sensitive_response"
+
+// MarkSensitiveResponse 标记本次响应包含敏感内容，响应体不会写入日志
+func MarkSensitiveResponse(ctx *gin.Context) {
+	ctx.Set(sensitiveResponseKey, true)
+}
+
 func ResponseLogMiddleware(logger *log.Logger) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: ctx.Writer}
@@ -39,7 +47,11 @@ func ResponseLogMiddleware(logger *log.Logger) gin.HandlerFunc {
 		startTime := time.Now()
 		ctx.Next()
 		duration := time.Since(startTime).String()
-		logger.WithContext(ctx).Info("Response", zap.Any("response_body", blw.body.String()), zap.Any("time", duration))
+		body := blw.body.String()
+		if ctx.GetBool(sensitiveResponseKey) {
+			body = "[redacted]"
+		}
+		logger.WithContext(ctx).Info("Response", zap.Any("response_body", body), zap.Any("time", duration))
 	}
 }
 
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/service"
+	"hyacinth-backend/pkg/log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// 节点认证信息通过元数据传递：
+// x-node-id: 节点ID
+// authorization: Bearer <节点密钥>
+const (
+	nodeIdHeader = "x-node-id"
+	authHeader   = "authorization"
+)
+
+// NodeAuth 面向节点的 HTTP 接口认证，请求头与 gRPC 元数据一致
+func NodeAuth(nodeService service.NodeService, logger *log.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		nodeId := ctx.Request.Header.Get(nodeIdHeader)
+		key := strings.TrimPrefix(ctx.Request.Header.Get(authHeader), "Bearer ")
+		if err := nodeService.Authenticate(ctx, nodeId, key); err != nil {
+			logger.WithContext(ctx).Warn("node auth failed", zap.Any("data", map[string]interface{}{
+				"url":    ctx.Request.URL,
+				"nodeId": nodeId,
+			}))
+			v1.HandleError(ctx, http.StatusUnauthorized, v1.ErrUnauthorized, nil)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set("nodeId", nodeId)
+		logger.WithValue(ctx, zap.String("NodeId", nodeId))
+		ctx.Next()
+	}
+}
+
+// NodeUnaryAuth gRPC 一元调用的节点认证拦截器
+func NodeUnaryAuth(nodeService service.NodeService, logger *log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticateNode(ctx, nodeService, logger, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NodeStreamAuth gRPC 流式调用的节点认证拦截器
+func NodeStreamAuth(nodeService service.NodeService, logger *log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateNode(ss.Context(), nodeService, logger, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &nodeServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticateNode(ctx context.Context, nodeService service.NodeService, logger *log.Logger, method string) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+	nodeId := firstMetadata(md, nodeIdHeader)
+	key := strings.TrimPrefix(firstMetadata(md, authHeader), "Bearer ")
+	if err := nodeService.Authenticate(ctx, nodeId, key); err != nil {
+		logger.WithContext(ctx).Warn("node auth failed", zap.String("method", method), zap.String("nodeId", nodeId))
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return context.WithValue(ctx, "nodeId", nodeId), nil
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// nodeServerStream 用于替换流的上下文，使处理器能读取节点ID
+type nodeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *nodeServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IpLease 虚拟网络内的地址租约
+// 动态租约在到期且客户端离线后回收；静态租约（地址保留）由所有者为指定设备设置，不会过期
+// 唯一索引保证同一地址、同一设备在虚拟网络内各只有一条租约，多实例并发分配时由数据库兜底
+type IpLease struct {
+	gorm.Model
+	VnetId     string     `gorm:"uniqueIndex:idx_ip_lease_vnet_address;uniqueIndex:idx_ip_lease_vnet_client;size:64;not null"`
+	Address    string     `gorm:"uniqueIndex:idx_ip_lease_vnet_address;size:64;not null"`
+	ClientId   string     `gorm:"uniqueIndex:idx_ip_lease_vnet_client;size:64;not null"`
+	MacAddress string     `gorm:"not null;default:''"`
+	Static     bool       `gorm:"not null;default:false"`
+	ExpiresAt  *time.Time `gorm:"index"` // 静态租约为空
+}
+
+func (m *IpLease) TableName() string {
+	return "ip_leases"
+}
+
+// IsActive 租约是否仍然占用地址
+func (m *IpLease) IsActive(now time.Time) bool {
+	return m.Static || (m.ExpiresAt != nil && m.ExpiresAt.After(now))
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 节点在线状态，由心跳维护
+const (
+	NodeStatusOnline  = "online"
+	NodeStatusOffline = "offline"
+)
+
+// Node 承载虚拟网络流量的中继节点
+// 节点通过一次性引导令牌注册后获得密钥；配置文件 node.keys 中的静态节点首次心跳时自动登记
+// 下线（Draining）由管理员设置，表示不再承接新的虚拟网络；在线状态由心跳维护，超时后由定时任务标记为离线
+type Node struct {
+	gorm.Model
+	NodeId           string     `gorm:"unique;size:64;not null"`
+	KeyHash          string     `gorm:"size:64;not null;default:''"` // 节点密钥的 SHA-256，原文只在注册时返回一次；静态节点为空
+	Region           string     `gorm:"index;not null;default:''"`
+	PublicAddr       string     `gorm:"not null;default:''"` // 客户端连接节点使用的公网地址（host:port）
+	WgPublicKey      string     `gorm:"not null;default:''"` // 节点的 WireGuard 公钥，由心跳上报，私钥只保存在节点上
+	Capacity         int        `gorm:"not null;default:0"`  // 可承载的客户端数量，0 表示不限
+	Version          string     `gorm:"not null;default:''"`
+	Status           string     `gorm:"index;not null;default:'offline'"`
+	Draining         bool       `gorm:"not null;default:false"`
+	LastHeartbeat    *time.Time `gorm:"index"`
+	ClientsConnected int        `gorm:"not null;default:0"`
+	CpuUsage         float64    `gorm:"not null;default:0"` // 百分比
+	MemUsage         float64    `gorm:"not null;default:0"` // 百分比
+	RxBps            int64      `gorm:"not null;default:0"`
+	TxBps            int64      `gorm:"not null;default:0"`
+}
+
+func (m *Node) TableName() string {
+	return "nodes"
+}
+
+// NodeBootstrapToken 节点注册使用的一次性引导令牌，只保存令牌的哈希
+type NodeBootstrapToken struct {
+	gorm.Model
+	TokenHash string     `gorm:"unique;size:64;not null"`
+	Region    string     `gorm:"not null;default:''"` // 使用该令牌注册的节点默认所属区域
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time `gorm:"default:null"`
+	NodeId    string     `gorm:"not null;default:''"` // 使用该令牌注册的节点
+}
+
+func (m *NodeBootstrapToken) TableName() string {
+	return "node_bootstrap_tokens"
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 站内通知的类型
+const (
+	// NotificationPrivilegeExpired 套餐到期，已降级为免费套餐
+	NotificationPrivilegeExpired = "privilege_expired"
+	// NotificationPlanChanged 特权到期，已按计划降级为购买的套餐
+	NotificationPlanChanged = "plan_changed"
+)
+
+// Notification 发给用户的站内通知
+type Notification struct {
+	gorm.Model
+	UserId  string     `gorm:"index;size:64;not null"`
+	Type    string     `gorm:"size:32;not null"`
+	Title   string     `gorm:"not null"`
+	Content string     `gorm:"type:text"`
+	ReadAt  *time.Time `gorm:"default:null"` // 为空表示未读
+}
+
+func (m *Notification) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 订单状态
+const (
+	OrderCreated        = "created"         // 已创建，尚未向支付渠道发起支付
+	OrderPendingPayment = "pending_payment" // 等待用户付款
+	OrderPaid           = "paid"            // 已收到渠道的支付通知，套餐尚未生效
+	OrderFulfilled      = "fulfilled"       // 套餐已生效
+	OrderCancelled      = "cancelled"       // 用户取消或超时未付款
+	OrderRefunded       = "refunded"        // 渠道已退款
+	OrderRefundRequired = "refund_required" // 已付款但付款时套餐变更已不能生效，等待管理员退款
+)
+
+// OrderCurrency 订单的结算币种
+const OrderCurrency = "CNY"
+
+// orderTransitions 订单状态允许的迁移
+// 取消后才到账的付款仍然有效，允许从已取消迁移到已支付，避免用户付了款却拿不到套餐
+var orderTransitions = map[string][]string{
+	OrderCreated:        {OrderPendingPayment, OrderCancelled},
+	OrderPendingPayment: {OrderPaid, OrderCancelled},
+	OrderPaid:           {OrderFulfilled, OrderRefunded, OrderRefundRequired},
+	OrderFulfilled:      {OrderRefunded},
+	OrderCancelled:      {OrderPaid},
+	OrderRefundRequired: {OrderRefunded},
+}
+
+// CanTransitOrder 订单能否从 from 迁移到 to
+func CanTransitOrder(from string, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Order 购买套餐的订单，组织的订单 OrgId 非空，套餐在支付后生效于组织
+type Order struct {
+	gorm.Model
+	OrderId     string     `gorm:"unique;size:64;not null"`
+	UserId      string     `gorm:"index;size:64;not null"` // 下单用户
+	OrgId       string     `gorm:"size:64;not null;default:''"`
+	PackageType int        `gorm:"not null"`
+	Duration    int        `gorm:"not null"` // 月数
+	Amount      int64      `gorm:"not null"` // 分
+	Currency    string     `gorm:"not null"`
+	Status      string     `gorm:"index;not null"`
+	Provider    string     `gorm:"not null"`
+	PayUrl      string     `gorm:"type:text"`
+	TradeNo     string     `gorm:"not null;default:''"` // 渠道流水号
+	ExpiresAt   time.Time  `gorm:"not null"`            // 超过该时间未付款自动取消
+	PaidAt      *time.Time `gorm:"default:null"`
+	FulfilledAt *time.Time `gorm:"default:null"`
+}
+
+func (m *Order) TableName() string {
+	return "orders"
+}
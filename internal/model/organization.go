@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 成员在组织中的角色，权限依次递增
+const (
+	OrgRoleMember = "member" // 创建和管理组织的虚拟网络，不能删除
+	OrgRoleAdmin  = "admin"  // 管理成员、购买套餐、删除组织的虚拟网络
+	OrgRoleOwner  = "owner"  // 创建者，唯一，可以任免管理员
+)
+
+var orgRoleRanks = map[string]int{
+	OrgRoleMember: 1,
+	OrgRoleAdmin:  2,
+	OrgRoleOwner:  3,
+}
+
+// OrgRoleRank 角色的级别，未知角色为 0
+func OrgRoleRank(role string) int {
+	return orgRoleRanks[role]
+}
+
+// Organization 组织，持有用户组与流量池，组织的虚拟网络按组织的权益计算限制，流量从组织扣减
+type Organization struct {
+	gorm.Model
+	OrgId            string     `gorm:"unique;size:64;not null"`
+	Name             string     `gorm:"not null"`
+	UserGroup        int        `gorm:"not null;default:1"`
+	PrivilegeExpiry  *time.Time `gorm:"default:null"`
+	RemainingTraffic int64      `gorm:"not null;default:0"` // 新组织没有初始流量，需购买套餐
+	BillingCycle
+	ScheduledPlan
+}
+
+func (m *Organization) TableName() string {
+	return "organizations"
+}
+
+func (m *Organization) GetUserGroup() int {
+	return m.UserGroup
+}
+
+func (m *Organization) IsPrivilegeExpired() bool {
+	return isPrivilegeExpired(m.PrivilegeExpiry)
+}
+
+func (m *Organization) GetRemainingTraffic() int64 {
+	return m.RemainingTraffic
+}
+
+// OrganizationMember 用户在组织中的角色
+type OrganizationMember struct {
+	gorm.Model
+	OrgId  string `gorm:"uniqueIndex:idx_organization_member;size:64;not null"`
+	UserId string `gorm:"uniqueIndex:idx_organization_member;index;size:64;not null"`
+	Role   string `gorm:"not null"`
+}
+
+func (m *OrganizationMember) TableName() string {
+	return "organization_members"
+}
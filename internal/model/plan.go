@@ -0,0 +1,134 @@
+package model
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// FreeUserGroup 免费套餐对应的用户组，新用户、新组织与特权过期后均按此套餐计算权益
+const FreeUserGroup = 1
+
+// 套餐可开通的功能
+const (
+	PlanFeatureWireGuard = "wireguard" // 创建 WireGuard 虚拟网络
+	PlanFeatureRoutes    = "routes"    // 子网路由与出口节点
+)
+
+// Plan 套餐目录中的一个套餐，按用户组区分
+// 由迁移程序按配置中的 plans 写入，数据库中已存在的套餐不会被覆盖，可直接修改数据库调整价格与额度
+type Plan struct {
+	gorm.Model
+	UserGroup       int      `gorm:"unique;not null"`
+	Name            string   `gorm:"not null"`
+	Description     string   `gorm:"type:text"`
+	MonthlyPrice    int64    `gorm:"not null;default:0"`        // 每月价格（分）
+	Durations       []int    `gorm:"type:text;serializer:json"` // 可购买的时长（月数）
+	MonthlyTraffic  int64    `gorm:"not null;default:0"`        // 每月流量（字节），免费套餐使用一次性流量，为 0
+	VnetLimit       int      `gorm:"not null;default:0"`        // 运行中的虚拟网络数量上限
+	ClientsPerVnet  int      `gorm:"not null;default:0"`        // 单个虚拟网络的在线人数上限
+	AclRulesPerVnet int      `gorm:"not null;default:0"`        // 单个虚拟网络的访问控制规则数量上限
+	Features        []string `gorm:"type:text;serializer:json"`
+	Purchasable     bool     `gorm:"not null;default:false"` // 下架的套餐不能再购买，已购买的权益不受影响
+	SortOrder       int      `gorm:"not null;default:0"`
+}
+
+func (m *Plan) TableName() string {
+	return "plans"
+}
+
+// HasFeature 套餐是否开通了功能
+func (m *Plan) HasFeature(feature string) bool {
+	for _, f := range m.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPurchasable 套餐是否在售，免费套餐与没有定价或可购买时长的套餐不能购买
+func (m *Plan) IsPurchasable() bool {
+	return m.Purchasable && m.UserGroup != FreeUserGroup && m.MonthlyPrice > 0 && len(m.Durations) > 0
+}
+
+// CanPurchase 套餐能否按 months 个月购买
+func (m *Plan) CanPurchase(months int) bool {
+	if !m.IsPurchasable() {
+		return false
+	}
+	for _, d := range m.Durations {
+		if d == months {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanCatalog 套餐目录的快照，按用户组查找套餐
+type PlanCatalog struct {
+	plans   []Plan
+	byGroup map[int]*Plan
+	free    *Plan
+}
+
+// NewPlanCatalog 由套餐列表构造目录，按 SortOrder、用户组排序
+// 缺少免费套餐时以没有任何额度的套餐代替，此时只有付费且未过期的账户可以使用虚拟网络
+func NewPlanCatalog(plans []Plan) *PlanCatalog {
+	sorted := make([]Plan, len(plans))
+	copy(sorted, plans)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].SortOrder != sorted[j].SortOrder {
+			return sorted[i].SortOrder < sorted[j].SortOrder
+		}
+		return sorted[i].UserGroup < sorted[j].UserGroup
+	})
+	c := &PlanCatalog{plans: sorted, byGroup: make(map[int]*Plan, len(sorted))}
+	for i := range c.plans {
+		c.byGroup[c.plans[i].UserGroup] = &c.plans[i]
+	}
+	c.free = c.byGroup[FreeUserGroup]
+	if c.free == nil {
+		c.free = &Plan{UserGroup: FreeUserGroup, Name: "普通用户"}
+	}
+	return c
+}
+
+// Plans 目录中的全部套餐
+func (c *PlanCatalog) Plans() []Plan {
+	return c.plans
+}
+
+// Get 获取用户组对应的套餐，不存在时返回 nil
+func (c *PlanCatalog) Get(group int) *Plan {
+	return c.byGroup[group]
+}
+
+// Free 免费套餐
+func (c *PlanCatalog) Free() *Plan {
+	return c.free
+}
+
+// GroupName 获取用户组名称
+func (c *PlanCatalog) GroupName(group int) string {
+	if plan := c.Get(group); plan != nil {
+		return plan.Name
+	}
+	return "未知用户组"
+}
+
+// Effective 获取账户当前生效的套餐，特权过期或用户组已从目录中移除时为免费套餐
+func (c *PlanCatalog) Effective(subscriber Subscriber) *Plan {
+	if subscriber.IsPrivilegeExpired() {
+		return c.free
+	}
+	if plan := c.Get(subscriber.GetUserGroup()); plan != nil {
+		return plan
+	}
+	return c.free
+}
+
+// Entitle 获取账户及其当前生效的套餐
+func (c *PlanCatalog) Entitle(subscriber Subscriber) *Entitlement {
+	return &Entitlement{Subscriber: subscriber, Plan: c.Effective(subscriber)}
+}
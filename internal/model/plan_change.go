@@ -0,0 +1,147 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// 购买套餐的变更类型
+const (
+	PlanChangeNew       = "new"       // 当前没有生效的付费套餐，从现在起生效
+	PlanChangeRenew     = "renew"     // 续费当前套餐，顺延特权时间
+	PlanChangeUpgrade   = "upgrade"   // 升级，立即生效，当前套餐的剩余价值折算为新套餐的时长
+	PlanChangeDowngrade = "downgrade" // 降级，在当前特权到期时生效
+)
+
+// ErrDowngradeScheduled 已有待生效的降级时不能再降级到其他套餐
+var ErrDowngradeScheduled = errors.New("another downgrade is already scheduled")
+
+// ScheduledPlan 已付款、在当前特权到期时生效的降级
+type ScheduledPlan struct {
+	ScheduledGroup  int        `gorm:"not null;default:0;index"` // 降级后的用户组，0 表示没有待生效的降级
+	ScheduledExpiry *time.Time `gorm:"default:null"`             // 降级生效后的特权到期时间
+}
+
+// HasScheduledPlan 是否有待生效的降级
+func (p *ScheduledPlan) HasScheduledPlan() bool {
+	return p.ScheduledGroup != 0 && p.ScheduledExpiry != nil
+}
+
+// IsScheduledPlanDue 当前特权是否已到期，待生效的降级应当生效
+func (p *ScheduledPlan) IsScheduledPlanDue(expiry *time.Time, now time.Time) bool {
+	return p.HasScheduledPlan() && expiry != nil && !expiry.After(now)
+}
+
+// PlanChange 购买套餐对账户的影响
+// 应付金额总是新套餐的月价格乘以月数，变更类型只决定生效时间与到期时间
+type PlanChange struct {
+	Kind            string
+	Plan            *Plan
+	Months          int
+	Amount          int64         // 应付金额（分）
+	EffectiveAt     time.Time     // 新套餐或续费的时长开始生效的时间
+	Expiry          time.Time     // 生效后的特权到期时间
+	Credit          time.Duration // 升级时原套餐（及待生效的降级）的剩余价值折算成的新套餐时长
+	ScheduledExpiry *time.Time    // 续费时顺延后的待生效降级的到期时间
+}
+
+// NewPlanChange 按账户当前的用户组、特权到期时间与待生效的降级计算购买 months 个月 plan 的变更
+// 当前套餐已不在目录中时按没有生效的付费套餐处理；同价的不同套餐按升级处理
+func NewPlanChange(catalog *PlanCatalog, group int, expiry *time.Time, scheduled ScheduledPlan, plan *Plan, months int, now time.Time) (*PlanChange, error) {
+	c := &PlanChange{Plan: plan, Months: months, Amount: plan.MonthlyPrice * int64(months)}
+	current := catalog.Get(group)
+	if group == FreeUserGroup || current == nil || expiry == nil || !expiry.After(now) {
+		c.Kind = PlanChangeNew
+		c.EffectiveAt = now
+		c.Expiry = now.AddDate(0, months, 0)
+		return c, nil
+	}
+
+	switch {
+	case plan.UserGroup == group:
+		c.Kind = PlanChangeRenew
+		c.EffectiveAt = *expiry
+		c.Expiry = expiry.AddDate(0, months, 0)
+		if scheduled.HasScheduledPlan() {
+			// 待生效的降级随之顺延
+			shifted := scheduled.ScheduledExpiry.AddDate(0, months, 0)
+			c.ScheduledExpiry = &shifted
+		}
+	case scheduled.HasScheduledPlan() && plan.UserGroup == scheduled.ScheduledGroup:
+		// 续费待生效的降级套餐
+		c.Kind = PlanChangeDowngrade
+		c.EffectiveAt = *expiry
+		c.Expiry = scheduled.ScheduledExpiry.AddDate(0, months, 0)
+	case plan.MonthlyPrice < current.MonthlyPrice:
+		if scheduled.HasScheduledPlan() {
+			return nil, ErrDowngradeScheduled
+		}
+		c.Kind = PlanChangeDowngrade
+		c.EffectiveAt = *expiry
+		c.Expiry = expiry.AddDate(0, months, 0)
+	default:
+		// 剩余价值以 分·秒 计算，折算为新套餐的时长，待生效的降级一并折算并取消
+		value := int64(expiry.Sub(now)/time.Second) * current.MonthlyPrice
+		if scheduled.HasScheduledPlan() {
+			if next := catalog.Get(scheduled.ScheduledGroup); next != nil {
+				value += int64(scheduled.ScheduledExpiry.Sub(*expiry)/time.Second) * next.MonthlyPrice
+			}
+		}
+		if plan.MonthlyPrice > 0 {
+			c.Credit = time.Duration(value/plan.MonthlyPrice) * time.Second
+		}
+		c.Kind = PlanChangeUpgrade
+		c.EffectiveAt = now
+		c.Expiry = now.AddDate(0, months, 0).Add(c.Credit)
+	}
+	return c, nil
+}
+
+// ApplyPlanChange 使变更生效于用户
+func (u *User) ApplyPlanChange(c *PlanChange) {
+	applyPlanChange(c, &u.UserGroup, &u.PrivilegeExpiry, &u.RemainingTraffic, &u.BillingCycle, &u.ScheduledPlan)
+}
+
+// ApplyPlanChange 使变更生效于组织
+func (m *Organization) ApplyPlanChange(c *PlanChange) {
+	applyPlanChange(c, &m.UserGroup, &m.PrivilegeExpiry, &m.RemainingTraffic, &m.BillingCycle, &m.ScheduledPlan)
+}
+
+// applyPlanChange 新购与升级从生效时间起开始新的计费周期，流量重置为新套餐的月流量；
+// 续费只顺延特权时间；降级只登记为待生效，到期时由 ApplyScheduledPlan 生效
+func applyPlanChange(c *PlanChange, group *int, expiry **time.Time, remaining *int64, cycle *BillingCycle, scheduled *ScheduledPlan) {
+	renewed := c.Expiry
+	switch c.Kind {
+	case PlanChangeNew, PlanChangeUpgrade:
+		*group = c.Plan.UserGroup
+		*expiry = &renewed
+		*remaining = c.Plan.MonthlyTraffic
+		cycle.StartBillingCycle(c.EffectiveAt)
+		*scheduled = ScheduledPlan{}
+	case PlanChangeRenew:
+		*expiry = &renewed
+		if c.ScheduledExpiry != nil {
+			scheduled.ScheduledExpiry = c.ScheduledExpiry
+		}
+	case PlanChangeDowngrade:
+		*scheduled = ScheduledPlan{ScheduledGroup: c.Plan.UserGroup, ScheduledExpiry: &renewed}
+	}
+}
+
+// ApplyScheduledPlan 使用户待生效的降级生效，剩余流量重置为新套餐的月流量 traffic，计费周期从原特权到期时间起算
+func (u *User) ApplyScheduledPlan(traffic int64) {
+	applyScheduledPlan(traffic, &u.UserGroup, &u.PrivilegeExpiry, &u.RemainingTraffic, &u.BillingCycle, &u.ScheduledPlan)
+}
+
+// ApplyScheduledPlan 使组织待生效的降级生效，剩余流量重置为新套餐的月流量 traffic，计费周期从原特权到期时间起算
+func (m *Organization) ApplyScheduledPlan(traffic int64) {
+	applyScheduledPlan(traffic, &m.UserGroup, &m.PrivilegeExpiry, &m.RemainingTraffic, &m.BillingCycle, &m.ScheduledPlan)
+}
+
+func applyScheduledPlan(traffic int64, group *int, expiry **time.Time, remaining *int64, cycle *BillingCycle, scheduled *ScheduledPlan) {
+	cycle.StartBillingCycle(**expiry)
+	*group = scheduled.ScheduledGroup
+	*expiry = scheduled.ScheduledExpiry
+	*remaining = traffic
+	*scheduled = ScheduledPlan{}
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Subscriber 持有用户组与流量池的账户，个人虚拟网络为所有者本人，组织的虚拟网络为组织
+type Subscriber interface {
+	GetUserGroup() int
+	IsPrivilegeExpired() bool
+	GetRemainingTraffic() int64
+}
+
+// Entitlement 账户及其当前生效的套餐
+// 虚拟网络的数量、在线人数与访问控制规则的限制均按其计算
+type Entitlement struct {
+	Subscriber
+	Plan *Plan
+}
+
+// GetVirtualNetworkLimit 获取虚拟网络数量限制
+func (e *Entitlement) GetVirtualNetworkLimit() int {
+	return e.Plan.VnetLimit
+}
+
+// GetMaxClientsLimitPerVNet 获取单个虚拟网络的最大在线人数限制
+func (e *Entitlement) GetMaxClientsLimitPerVNet() int {
+	return e.Plan.ClientsPerVnet
+}
+
+// GetMaxAclRulesPerVNet 获取单个虚拟网络的访问控制规则数量限制
+func (e *Entitlement) GetMaxAclRulesPerVNet() int {
+	return e.Plan.AclRulesPerVnet
+}
+
+// HasFeature 当前生效的套餐是否开通了功能
+func (e *Entitlement) HasFeature(feature string) bool {
+	return e.Plan.HasFeature(feature)
+}
+
+// FormatTraffic 格式化流量显示
+func FormatTraffic(bytes int64) string {
+	gb := float64(bytes) / (1024 * 1024 * 1024)
+	if gb >= 1024 {
+		return fmt.Sprintf("%.2f TB", gb/1024)
+	}
+	return fmt.Sprintf("%.2f GB", gb)
+}
+
+// isPrivilegeExpired 没有特权到期时间视为已过期
+func isPrivilegeExpired(expiry *time.Time) bool {
+	if expiry == nil {
+		return true
+	}
+	return time.Now().After(*expiry)
+}
+
+// BillingCycle 付费套餐的计费周期，每月在起点对应的日期与时刻将剩余流量重置为套餐的月流量
+// 嵌入持有流量池的账户，免费套餐与特权过期的账户没有计费周期
+type BillingCycle struct {
+	BillingAnchor    *time.Time `gorm:"default:null"`       // 计费周期起点
+	NextTrafficReset *time.Time `gorm:"default:null;index"` // 下一次重置流量的时间
+}
+
+// StartBillingCycle 从 now 开始新的计费周期，一个月后首次重置流量
+func (c *BillingCycle) StartBillingCycle(now time.Time) {
+	anchor := now
+	next := now.AddDate(0, 1, 0)
+	c.BillingAnchor = &anchor
+	c.NextTrafficReset = &next
+}
+
+// IsTrafficResetDue 是否到了重置流量的时间，重置时间须早于特权到期时间，到期当天不再重置
+func (c *BillingCycle) IsTrafficResetDue(expiry *time.Time, now time.Time) bool {
+	if c.BillingAnchor == nil || c.NextTrafficReset == nil || expiry == nil {
+		return false
+	}
+	return !c.NextTrafficReset.After(now) && expiry.After(*c.NextTrafficReset)
+}
+
+// AdvanceTrafficReset 返回 now 所在计费周期的起点，并将下一次重置时间推进到下一个周期
+// 任务延迟了多个周期时只对应最近的一个周期，错过的周期不补发
+func (c *BillingCycle) AdvanceTrafficReset(now time.Time) time.Time {
+	start, next := CycleBounds(*c.BillingAnchor, now)
+	c.NextTrafficReset = &next
+	return start
+}
+
+// CycleBounds 以 anchor 为起点按月划分周期，返回 t 所在周期的起点与下一个周期的起点
+// 每个周期的起点都由 anchor 直接推算，月末的起点不会逐月漂移
+func CycleBounds(anchor time.Time, t time.Time) (time.Time, time.Time) {
+	months := (t.Year()-anchor.Year())*12 + int(t.Month()) - int(anchor.Month())
+	for anchor.AddDate(0, months, 0).After(t) {
+		months--
+	}
+	for !anchor.AddDate(0, months+1, 0).After(t) {
+		months++
+	}
+	return anchor.AddDate(0, months, 0), anchor.AddDate(0, months+1, 0)
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 重置流量的账户类型
+const (
+	TrafficResetOwnerUser = "user"
+	TrafficResetOwnerOrg  = "org"
+)
+
+// TrafficReset 月流量重置记录，每个账户的每个计费周期只重置一次
+type TrafficReset struct {
+	gorm.Model
+	OwnerType       string    `gorm:"uniqueIndex:idx_traffic_reset;size:16;not null"`
+	OwnerId         string    `gorm:"uniqueIndex:idx_traffic_reset;size:64;not null"` // 用户ID或组织ID
+	CycleStart      time.Time `gorm:"uniqueIndex:idx_traffic_reset;not null"`         // 重置所属计费周期的起点
+	UserGroup       int       `gorm:"not null"`
+	PreviousTraffic int64     `gorm:"not null;default:0"` // 重置前的剩余流量，未用完的流量不累积
+	Traffic         int64     `gorm:"not null;default:0"` // 重置后的剩余流量
+}
+
+func (m *TrafficReset) TableName() string {
+	return "traffic_resets"
+}
@@ -1,17 +1,38 @@
 package model
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type Usage struct {
 	gorm.Model
-	Id     uint   `gorm:"primaryKey"`
-	UserId string `gorm:"not null"`
-	VnetId string `gorm:""`
-	Usage  int64  `gorm:"not null"`
+	Id       uint   `gorm:"primaryKey"`
+	UserId   string `gorm:"not null"`
+	VnetId   string `gorm:"index;size:64"`
+	Usage    int64  `gorm:"not null"`
+	ClientId string `gorm:"not null;default:''"` // 上报流量的客户端，空值表示整个虚拟网络的汇总
+	NodeId   string `gorm:"not null;default:''"`
+	BatchId  string `gorm:"index;not null;default:''"`
 }
 
 func (m *Usage) TableName() string {
 	return "usages"
 }
+
+// UsageBatch 节点流量上报批次，用于重试时的幂等判断
+type UsageBatch struct {
+	gorm.Model
+	NodeId      string    `gorm:"uniqueIndex:idx_usage_batch_node_batch;size:64;not null"`
+	BatchId     string    `gorm:"uniqueIndex:idx_usage_batch_node_batch;size:64;not null"`
+	WindowStart time.Time `gorm:"not null"`
+	WindowEnd   time.Time `gorm:"not null"`
+	Accepted    int       `gorm:"not null"`
+	Rejected    int       `gorm:"not null"`
+	TotalBytes  int64     `gorm:"not null"`
+}
+
+func (m *UsageBatch) TableName() string {
+	return "usage_batches"
+}
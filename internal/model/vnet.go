@@ -2,19 +2,48 @@ package model
 
 import "gorm.io/gorm"
 
+// 虚拟网络被系统自动停用的原因，用户手动停用时为空
+const (
+	// VnetSuspendTrafficExhausted 所有者流量耗尽，充值后自动恢复
+	VnetSuspendTrafficExhausted = "traffic_exhausted"
+	// VnetSuspendPlanLimit 套餐到期降级后超出了虚拟网络数量限制，需用户手动启用
+	VnetSuspendPlanLimit = "plan_limit"
+)
+
+// 虚拟网络的类型，创建后不能修改
+const (
+	// VnetTypeN2N 客户端使用令牌与密码接入节点
+	VnetTypeN2N = "n2n"
+	// VnetTypeWireGuard 客户端使用登记过公钥的 WireGuard 密钥接入节点
+	VnetTypeWireGuard = "wireguard"
+)
+
 type Vnet struct {
 	gorm.Model
-	VnetId        string `gorm:"unique;not null"`
-	UserId        string `gorm:"not null"`
-	Comment       string
-	Enabled       bool   `gorm:"not null"`
-	Token         string `gorm:"not null"`
-	Password      string `gorm:"not null"`
-	IpRange       string `gorm:"not null"`
-	EnableDHCP    bool   `gorm:"not null"`
-	ClientsLimit  int    `gorm:"not null"`
-	ClientsOnline int    `gorm:"not null"`
-	NeedUpdate    bool   `gorm:"not null"`
+	VnetId          string `gorm:"unique;not null"`
+	UserId          string `gorm:"not null"`
+	OrgId           string `gorm:"index;not null;default:''"` // 所属组织，为空表示个人虚拟网络；组织的虚拟网络中 UserId 为创建者
+	Type            string `gorm:"not null;default:'n2n'"`    // 虚拟网络类型，创建后不能修改
+	Comment         string
+	Enabled         bool   `gorm:"not null"`
+	Token           string `gorm:"not null"`
+	PasswordHash    string `gorm:"not null;default:''"`    // 密码校验值（见 pkg/vnetpass），不保存明文，为空表示无需密码
+	RequireApproval bool   `gorm:"not null;default:false"` // 新设备接入前需经所有者批准
+	IpRange         string `gorm:"not null"`
+	EnableDHCP      bool   `gorm:"not null"`
+	ClientsLimit    int    `gorm:"not null"`
+	ClientsOnline   int    `gorm:"not null"`
+	NeedUpdate      bool   `gorm:"not null"`
+	Region          string `gorm:"not null;default:''"`       // 优先使用的节点区域，空值表示不限
+	NodeId          string `gorm:"index;not null;default:''"` // 承载该虚拟网络的中继节点，由调度任务分配，空值表示尚未分配
+	Revision        int64  `gorm:"not null;default:0"`        // 配置版本号，每次修改递增，节点确认后才清除 NeedUpdate
+	SuspendReason   string `gorm:"not null;default:''"`       // 系统自动停用的原因
+	Acl             string `gorm:"type:text"`                 // 编译后的访问控制规则（JSON），随配置下发给节点
+	AclRevision     int64  `gorm:"not null;default:0"`        // 访问控制规则版本号，每次修改规则或成员标签递增
+	Peers           string `gorm:"type:text"`                 // 编译后的 WireGuard 对端列表（JSON），随配置下发给节点
+	PeerRevision    int64  `gorm:"not null;default:0"`        // 对端列表版本号，每次登记或删除设备公钥递增
+	Routes          string `gorm:"type:text"`                 // 编译后的路由表（JSON），只包含已批准的路由，随配置下发给节点
+	RouteRevision   int64  `gorm:"not null;default:0"`        // 路由表版本号，每次批准或删除已批准的路由递增
 }
 
 func (m *Vnet) TableName() string {
@@ -0,0 +1,50 @@
+package model
+
+import "gorm.io/gorm"
+
+// 访问控制规则的动作
+const (
+	AclActionAllow = "allow"
+	AclActionDeny  = "deny"
+)
+
+// 访问控制规则的协议
+const (
+	AclProtocolAny  = "any"
+	AclProtocolTCP  = "tcp"
+	AclProtocolUDP  = "udp"
+	AclProtocolICMP = "icmp"
+)
+
+// VnetAclRule 虚拟网络成员之间的访问控制规则
+// 按 Priority 从小到大依次匹配，第一条匹配的规则决定放行或拒绝，没有规则匹配时放行
+// Source 与 Destination 为成员选择器：* 表示所有成员，tag:<标签>、client:<设备标识>，或虚拟地址/CIDR
+type VnetAclRule struct {
+	gorm.Model
+	RuleId      string `gorm:"unique;size:64;not null"`
+	VnetId      string `gorm:"index;size:64;not null"`
+	Priority    int    `gorm:"not null"`
+	Action      string `gorm:"not null"`
+	Source      string `gorm:"not null"`
+	Destination string `gorm:"not null"`
+	Protocol    string `gorm:"not null;default:'any'"`
+	PortFrom    int    `gorm:"not null;default:0"` // 端口范围，均为 0 表示所有端口
+	PortTo      int    `gorm:"not null;default:0"`
+	Comment     string `gorm:"not null;default:''"`
+}
+
+func (m *VnetAclRule) TableName() string {
+	return "vnet_acl_rules"
+}
+
+// VnetMemberTag 虚拟网络成员（设备）的标签，供访问控制规则按标签选择成员
+type VnetMemberTag struct {
+	gorm.Model
+	VnetId   string `gorm:"uniqueIndex:idx_vnet_member_tag;size:64;not null"`
+	ClientId string `gorm:"uniqueIndex:idx_vnet_member_tag;size:64;not null"`
+	Tag      string `gorm:"uniqueIndex:idx_vnet_member_tag;size:32;not null"`
+}
+
+func (m *VnetMemberTag) TableName() string {
+	return "vnet_member_tags"
+}
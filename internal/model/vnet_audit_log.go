@@ -0,0 +1,24 @@
+package model
+
+import "gorm.io/gorm"
+
+// 审计日志记录的操作
+const (
+	VnetAuditCollaboratorGrant  = "collaborator.grant"
+	VnetAuditCollaboratorUpdate = "collaborator.update"
+	VnetAuditCollaboratorRevoke = "collaborator.revoke"
+)
+
+// VnetAuditLog 虚拟网络的审计日志，记录谁在何时对谁做了什么
+type VnetAuditLog struct {
+	gorm.Model
+	VnetId   string `gorm:"index;size:64;not null"`
+	ActorId  string `gorm:"not null"` // 执行操作的用户
+	Action   string `gorm:"not null"`
+	TargetId string `gorm:"not null;default:''"` // 操作对象，如协作者的用户 ID
+	Detail   string `gorm:"not null;default:''"` // 操作详情，如角色的变化
+}
+
+func (m *VnetAuditLog) TableName() string {
+	return "vnet_audit_logs"
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VnetBan 虚拟网络的封禁记录，按设备或公网地址封禁，二者只会指定其一
+// 按设备封禁时同时记录设备的设备密钥，设备更换标识后仍会命中；封禁在控制面的接入检查中生效，节点重启后依然有效
+type VnetBan struct {
+	gorm.Model
+	BanId         string     `gorm:"unique;size:64;not null"`
+	VnetId        string     `gorm:"index;size:64;not null"`
+	ClientId      string     `gorm:"not null;default:''"`
+	DeviceKeyHash string     `gorm:"size:64;not null;default:''"` // 被封禁设备的设备密钥 SHA-256，取自其审批记录，设备未提交过密钥时为空
+	Ip            string     `gorm:"not null;default:''"`         // 公网 IP 或 CIDR
+	Reason        string     `gorm:"not null;default:''"`
+	ExpiresAt     *time.Time // 为空表示永久封禁
+}
+
+func (m *VnetBan) TableName() string {
+	return "vnet_bans"
+}
+
+// IsActive 封禁是否仍然有效
+func (m *VnetBan) IsActive(now time.Time) bool {
+	return m.ExpiresAt == nil || m.ExpiresAt.After(now)
+}
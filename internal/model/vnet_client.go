@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VnetClient 虚拟网络中的在线客户端会话
+// 由节点上报加入、离开与心跳，超过有效期未收到心跳的会话由定时任务清理
+// 会话离开或过期后直接物理删除，同一客户端重新加入时覆盖原会话
+type VnetClient struct {
+	gorm.Model
+	VnetId         string    `gorm:"uniqueIndex:idx_vnet_client_vnet_client;size:64;not null"`
+	ClientId       string    `gorm:"uniqueIndex:idx_vnet_client_vnet_client;size:64;not null"`
+	VirtualIp      string    `gorm:"not null;default:''"`
+	MacAddress     string    `gorm:"not null;default:''"`
+	PublicEndpoint string    `gorm:"not null;default:''"` // 客户端的公网地址（ip:port）
+	NodeId         string    `gorm:"index;not null"`
+	ConnectedAt    time.Time `gorm:"not null"`
+	LastSeen       time.Time `gorm:"index;not null"`
+}
+
+func (m *VnetClient) TableName() string {
+	return "vnet_clients"
+}
+
+// VnetClientChallenge 已用于准入的接入挑战，挑战过期前同一挑战的应答不能再次使用
+// 挑战本身无需保存，只记录用过的随机数，过期后由定时任务清理
+type VnetClientChallenge struct {
+	gorm.Model
+	Nonce     string    `gorm:"uniqueIndex;size:64;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
+
+func (m *VnetClientChallenge) TableName() string {
+	return "vnet_client_challenges"
+}
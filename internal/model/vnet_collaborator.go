@@ -0,0 +1,41 @@
+package model
+
+import "gorm.io/gorm"
+
+// 协作者在虚拟网络中的角色，权限依次递增，高级角色包含低级角色的全部权限
+const (
+	VnetRoleViewer   = "viewer"   // 查看设备、租约、访问控制与封禁
+	VnetRoleOperator = "operator" // 管理设备：审批、断开、封禁、地址预留与邀请
+	VnetRoleAdmin    = "admin"    // 修改虚拟网络设置与访问控制，管理级别更低的协作者
+	VnetRoleOwner    = "owner"    // 所有者，不保存为协作者记录，独有删除虚拟网络的权限
+)
+
+var vnetRoleRanks = map[string]int{
+	VnetRoleViewer:   1,
+	VnetRoleOperator: 2,
+	VnetRoleAdmin:    3,
+	VnetRoleOwner:    4,
+}
+
+// VnetRoleRank 角色的级别，未知角色为 0
+func VnetRoleRank(role string) int {
+	return vnetRoleRanks[role]
+}
+
+// IsCollaboratorRole 判断角色能否授予协作者
+func IsCollaboratorRole(role string) bool {
+	return role == VnetRoleViewer || role == VnetRoleOperator || role == VnetRoleAdmin
+}
+
+// VnetCollaborator 其他注册用户在虚拟网络中的角色
+type VnetCollaborator struct {
+	gorm.Model
+	VnetId    string `gorm:"uniqueIndex:idx_vnet_collaborator;size:64;not null"`
+	UserId    string `gorm:"uniqueIndex:idx_vnet_collaborator;index;size:64;not null"`
+	Role      string `gorm:"not null"`
+	GrantedBy string `gorm:"not null;default:''"` // 最近一次授予或修改角色的用户
+}
+
+func (m *VnetCollaborator) TableName() string {
+	return "vnet_collaborators"
+}
@@ -0,0 +1,32 @@
+package model
+
+import "gorm.io/gorm"
+
+// 虚拟网络变更事件类型
+const (
+	VnetEventCreate  = "create"
+	VnetEventUpdate  = "update"
+	VnetEventEnable  = "enable"
+	VnetEventDisable = "disable"
+	VnetEventDelete  = "delete"
+	VnetEventKick    = "kick"   // 断开指定客户端，不改变配置
+	VnetEventResync  = "resync" // 节点续传的版本号之后有事件已被清理，须全量同步，只推送不入库
+)
+
+// VnetEvent 虚拟网络配置变更事件
+// 自增主键即全局有序的事件版本号，节点断线重连时从已处理的版本号之后继续消费
+// 超过保留期的事件由定时任务清理，版本号早于保留期的节点须全量同步
+type VnetEvent struct {
+	gorm.Model
+	VnetId       string `gorm:"index;not null"`
+	UserId       string `gorm:"not null"`
+	NodeId       string `gorm:"index;not null;default:''"`
+	Type         string `gorm:"not null"`
+	VnetRevision int64  `gorm:"not null"`
+	ClientId     string `gorm:"not null;default:''"` // 断开事件的目标客户端
+	Payload      string `gorm:"type:text"`           // 事件发生时的配置快照（JSON）
+}
+
+func (m *VnetEvent) TableName() string {
+	return "vnet_events"
+}
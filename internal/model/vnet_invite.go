@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VnetInvite 虚拟网络的邀请，兑换后获得接入凭据，无需分享密码
+type VnetInvite struct {
+	gorm.Model
+	InviteId    string     `gorm:"unique;size:64;not null"`
+	VnetId      string     `gorm:"index;size:64;not null"`
+	Code        string     `gorm:"unique;size:64;not null"` // 邀请码，可直接放入邀请链接
+	Comment     string     `gorm:"not null;default:''"`
+	MaxUses     int        `gorm:"not null;default:0"` // 可兑换的设备数量，0 表示不限
+	Uses        int        `gorm:"not null;default:0"`
+	AutoApprove bool       `gorm:"not null;default:false"` // 兑换的设备无需接入审批
+	ExpiresAt   *time.Time // 为空表示不过期，过期后已兑换的凭据仍然有效
+	RevokedAt   *time.Time // 撤销后无法兑换，已兑换的凭据同时失效
+}
+
+func (m *VnetInvite) TableName() string {
+	return "vnet_invites"
+}
+
+// IsActive 邀请是否仍可兑换
+func (m *VnetInvite) IsActive(now time.Time) bool {
+	if m.RevokedAt != nil || (m.ExpiresAt != nil && !m.ExpiresAt.After(now)) {
+		return false
+	}
+	return m.MaxUses == 0 || m.Uses < m.MaxUses
+}
+
+// VnetInviteRedemption 设备兑换邀请的记录，每台设备在一个虚拟网络中只保留最近一次兑换
+type VnetInviteRedemption struct {
+	gorm.Model
+	VnetId     string    `gorm:"uniqueIndex:idx_vnet_invite_redemption;size:64;not null"`
+	ClientId   string    `gorm:"uniqueIndex:idx_vnet_invite_redemption;size:64;not null"`
+	InviteId   string    `gorm:"index;size:64;not null"`
+	Name       string    `gorm:"not null;default:''"` // 兑换时填写的设备名称
+	Ip         string    `gorm:"not null;default:''"` // 兑换请求的来源地址
+	KeyHash    string    `gorm:"not null"`            // 接入密钥的 SHA-256
+	RedeemedAt time.Time `gorm:"not null"`
+}
+
+func (m *VnetInviteRedemption) TableName() string {
+	return "vnet_invite_redemptions"
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 设备在虚拟网络中的审批状态
+const (
+	VnetMemberPending  = "pending"  // 等待所有者审批
+	VnetMemberApproved = "approved" // 已批准，之后接入无需再次审批
+)
+
+// VnetMember 设备在虚拟网络中的审批记录，按设备标识区分
+// 设备标识由客户端自行选择，记录与首次使用它的设备密钥绑定，之后只有持有该密钥的设备能沿用审批结果
+// 仅在开启接入审批后产生记录，封禁见 VnetBan
+type VnetMember struct {
+	gorm.Model
+	VnetId         string     `gorm:"uniqueIndex:idx_vnet_member;size:64;not null"`
+	ClientId       string     `gorm:"uniqueIndex:idx_vnet_member;size:64;not null"`
+	Status         string     `gorm:"not null"`
+	MacAddress     string     `gorm:"not null;default:''"`
+	PublicEndpoint string     `gorm:"not null;default:''"` // 最近一次申请接入时的公网地址，供所有者审批时参考
+	LastSeen       time.Time  `gorm:"not null"`            // 最近一次申请接入的时间
+	ReviewedAt     *time.Time // 审批时间，待审批时为空
+	DeviceKeyHash  string     `gorm:"size:64;not null;default:''"` // 设备密钥的 SHA-256，所有者预先批准或开启审批前的记录在设备首次接入时绑定
+}
+
+func (m *VnetMember) TableName() string {
+	return "vnet_members"
+}
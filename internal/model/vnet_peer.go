@@ -0,0 +1,18 @@
+package model
+
+import "gorm.io/gorm"
+
+// VnetPeer WireGuard 虚拟网络中登记的设备公钥
+// 每台设备一条记录，地址为设备在虚拟网络中的静态租约；私钥不保存
+type VnetPeer struct {
+	gorm.Model
+	VnetId    string `gorm:"uniqueIndex:idx_vnet_peer_client;uniqueIndex:idx_vnet_peer_key;size:64;not null"`
+	ClientId  string `gorm:"uniqueIndex:idx_vnet_peer_client;size:64;not null"`
+	PublicKey string `gorm:"uniqueIndex:idx_vnet_peer_key;size:64;not null"` // Base64 编码的公钥
+	Name      string `gorm:"not null;default:''"`
+	Address   string `gorm:"not null"`
+}
+
+func (m *VnetPeer) TableName() string {
+	return "vnet_peers"
+}
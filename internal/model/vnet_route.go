@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 路由的审批状态
+const (
+	VnetRoutePending  = "pending"  // 设备已通告，等待所有者批准
+	VnetRouteApproved = "approved" // 已批准，随虚拟网络配置下发给节点
+)
+
+// VnetRoute 成员设备通告的路由
+// 子网路由使设备背后的局域网可以被其他成员访问；前缀为 0.0.0.0/0 或 ::/0 时设备作为出口节点转发互联网流量
+type VnetRoute struct {
+	gorm.Model
+	RouteId    string     `gorm:"unique;size:64;not null"`
+	VnetId     string     `gorm:"uniqueIndex:idx_vnet_route;size:64;not null"`
+	ClientId   string     `gorm:"uniqueIndex:idx_vnet_route;size:64;not null"`
+	Prefix     string     `gorm:"uniqueIndex:idx_vnet_route;size:64;not null"` // 规范形式的 CIDR
+	Exit       bool       `gorm:"not null;default:false"`                      // 出口节点（默认路由）
+	Status     string     `gorm:"index;not null"`
+	Comment    string     `gorm:"not null;default:''"`
+	ApprovedAt *time.Time // 批准时间，待批准时为空
+}
+
+func (m *VnetRoute) TableName() string {
+	return "vnet_routes"
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IpLeaseRepository interface {
+	GetLeasesByVnetId(ctx context.Context, vnetId string) (*[]model.IpLease, error)
+	GetLeaseByClientId(ctx context.Context, vnetId string, clientId string) (*model.IpLease, error)
+	CreateLease(ctx context.Context, lease *model.IpLease) error
+	UpdateLease(ctx context.Context, lease *model.IpLease) error
+	DeleteLeaseByClientId(ctx context.Context, vnetId string, clientId string) (bool, error)
+	DeleteLeasesByAddress(ctx context.Context, vnetId string, address string, exceptClientId string) error
+	DeleteExpiredLeases(ctx context.Context, now time.Time) (int64, error)
+}
+
+func NewIpLeaseRepository(
+	repository *Repository,
+) IpLeaseRepository {
+	return &ipLeaseRepository{
+		Repository: repository,
+	}
+}
+
+type ipLeaseRepository struct {
+	*Repository
+}
+
+func (r *ipLeaseRepository) GetLeasesByVnetId(ctx context.Context, vnetId string) (*[]model.IpLease, error) {
+	var leases []model.IpLease
+	if err := r.DB(ctx).Where("vnet_id = ?", vnetId).Order("id ASC").Find(&leases).Error; err != nil {
+		return nil, err
+	}
+	return &leases, nil
+}
+
+// GetLeaseByClientId 获取设备在虚拟网络中的租约，不存在时返回 nil
+func (r *ipLeaseRepository) GetLeaseByClientId(ctx context.Context, vnetId string, clientId string) (*model.IpLease, error) {
+	var lease model.IpLease
+	if err := r.DB(ctx).Where("vnet_id = ? AND client_id = ?", vnetId, clientId).First(&lease).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &lease, nil
+}
+
+func (r *ipLeaseRepository) CreateLease(ctx context.Context, lease *model.IpLease) error {
+	return r.DB(ctx).Create(lease).Error
+}
+
+func (r *ipLeaseRepository) UpdateLease(ctx context.Context, lease *model.IpLease) error {
+	return r.DB(ctx).Save(lease).Error
+}
+
+// DeleteLeaseByClientId 删除设备的租约，返回租约是否存在
+func (r *ipLeaseRepository) DeleteLeaseByClientId(ctx context.Context, vnetId string, clientId string) (bool, error) {
+	result := r.DB(ctx).Unscoped().Where("vnet_id = ? AND client_id = ?", vnetId, clientId).Delete(&model.IpLease{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteLeasesByAddress 删除其他设备占用该地址的租约，用于回收已过期的租约地址
+func (r *ipLeaseRepository) DeleteLeasesByAddress(ctx context.Context, vnetId string, address string, exceptClientId string) error {
+	return r.DB(ctx).Unscoped().
+		Where("vnet_id = ? AND address = ? AND client_id <> ?", vnetId, address, exceptClientId).
+		Delete(&model.IpLease{}).Error
+}
+
+// DeleteExpiredLeases 回收已过期且客户端不在线的动态租约，以及所属虚拟网络已删除的租约
+func (r *ipLeaseRepository) DeleteExpiredLeases(ctx context.Context, now time.Time) (int64, error) {
+	online := r.DB(ctx).Model(&model.VnetClient{}).Select("1").
+		Where("vnet_clients.vnet_id = ip_leases.vnet_id AND vnet_clients.client_id = ip_leases.client_id")
+	expired := r.DB(ctx).Unscoped().
+		Where("static = ? AND expires_at < ? AND NOT EXISTS (?)", false, now, online).
+		Delete(&model.IpLease{})
+	if expired.Error != nil {
+		return 0, expired.Error
+	}
+
+	vnets := r.DB(ctx).Model(&model.Vnet{}).Select("vnet_id")
+	orphaned := r.DB(ctx).Unscoped().Where("vnet_id NOT IN (?)", vnets).Delete(&model.IpLease{})
+	if orphaned.Error != nil {
+		return 0, orphaned.Error
+	}
+	return expired.RowsAffected + orphaned.RowsAffected, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NodeRepository interface {
+	CreateNode(ctx context.Context, node *model.Node) error
+	GetNodeByNodeId(ctx context.Context, nodeId string) (*model.Node, error)
+	ListNodes(ctx context.Context) (*[]model.Node, error)
+	UpdateHeartbeat(ctx context.Context, node *model.Node) (bool, error)
+	SetDraining(ctx context.Context, nodeId string, draining bool) (bool, error)
+	DeleteNode(ctx context.Context, nodeId string) (bool, error)
+	MarkOfflineNodes(ctx context.Context, before time.Time) (int64, error)
+	CreateBootstrapToken(ctx context.Context, token *model.NodeBootstrapToken) error
+	ConsumeBootstrapToken(ctx context.Context, tokenHash string, nodeId string, now time.Time) (*model.NodeBootstrapToken, error)
+}
+
+func NewNodeRepository(
+	repository *Repository,
+) NodeRepository {
+	return &nodeRepository{
+		Repository: repository,
+	}
+}
+
+type nodeRepository struct {
+	*Repository
+}
+
+func (r *nodeRepository) CreateNode(ctx context.Context, node *model.Node) error {
+	return r.DB(ctx).Create(node).Error
+}
+
+// GetNodeByNodeId 获取节点，不存在或已被移除时返回 nil
+func (r *nodeRepository) GetNodeByNodeId(ctx context.Context, nodeId string) (*model.Node, error) {
+	var node model.Node
+	if err := r.DB(ctx).Where("node_id = ?", nodeId).First(&node).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &node, nil
+}
+
+func (r *nodeRepository) ListNodes(ctx context.Context) (*[]model.Node, error) {
+	var nodes []model.Node
+	if err := r.DB(ctx).Order("region ASC, node_id ASC").Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	return &nodes, nil
+}
+
+// UpdateHeartbeat 只更新心跳上报的字段，不覆盖管理员设置的下线状态，返回节点是否存在
+func (r *nodeRepository) UpdateHeartbeat(ctx context.Context, node *model.Node) (bool, error) {
+	result := r.DB(ctx).Model(&model.Node{}).Where("node_id = ?", node.NodeId).Updates(map[string]interface{}{
+		"public_addr":       node.PublicAddr,
+		"wg_public_key":     node.WgPublicKey,
+		"capacity":          node.Capacity,
+		"version":           node.Version,
+		"status":            node.Status,
+		"last_heartbeat":    node.LastHeartbeat,
+		"clients_connected": node.ClientsConnected,
+		"cpu_usage":         node.CpuUsage,
+		"mem_usage":         node.MemUsage,
+		"rx_bps":            node.RxBps,
+		"tx_bps":            node.TxBps,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetDraining 设置节点下线状态，返回节点是否存在
+func (r *nodeRepository) SetDraining(ctx context.Context, nodeId string, draining bool) (bool, error) {
+	result := r.DB(ctx).Model(&model.Node{}).Where("node_id = ?", nodeId).Update("draining", draining)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteNode 移除节点，返回节点是否存在
+func (r *nodeRepository) DeleteNode(ctx context.Context, nodeId string) (bool, error) {
+	result := r.DB(ctx).Where("node_id = ?", nodeId).Delete(&model.Node{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MarkOfflineNodes 将最后心跳早于 before 的在线节点标记为离线，返回标记的数量
+func (r *nodeRepository) MarkOfflineNodes(ctx context.Context, before time.Time) (int64, error) {
+	result := r.DB(ctx).Model(&model.Node{}).
+		Where("status = ? AND (last_heartbeat IS NULL OR last_heartbeat < ?)", model.NodeStatusOnline, before).
+		Update("status", model.NodeStatusOffline)
+	return result.RowsAffected, result.Error
+}
+
+func (r *nodeRepository) CreateBootstrapToken(ctx context.Context, token *model.NodeBootstrapToken) error {
+	return r.DB(ctx).Create(token).Error
+}
+
+// ConsumeBootstrapToken 将未使用且未过期的引导令牌标记为已使用，令牌无效时返回 nil
+// 通过带条件的 UPDATE 实现，并发使用同一令牌时只有一个请求成功
+func (r *nodeRepository) ConsumeBootstrapToken(ctx context.Context, tokenHash string, nodeId string, now time.Time) (*model.NodeBootstrapToken, error) {
+	result := r.DB(ctx).Model(&model.NodeBootstrapToken{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Updates(map[string]interface{}{"used_at": now, "node_id": nodeId})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	var token model.NodeBootstrapToken
+	if err := r.DB(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
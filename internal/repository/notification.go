@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"hyacinth-backend/internal/model"
+	"time"
+)
+
+type NotificationRepository interface {
+	CreateNotifications(ctx context.Context, notifications []model.Notification) error
+	GetNotifications(ctx context.Context, userId string, limit int) (*[]model.Notification, error)
+	CountUnread(ctx context.Context, userId string) (int64, error)
+	MarkAllRead(ctx context.Context, userId string, now time.Time) error
+}
+
+func NewNotificationRepository(
+	repository *Repository,
+) NotificationRepository {
+	return &notificationRepository{
+		Repository: repository,
+	}
+}
+
+type notificationRepository struct {
+	*Repository
+}
+
+func (r *notificationRepository) CreateNotifications(ctx context.Context, notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.DB(ctx).Create(&notifications).Error
+}
+
+// GetNotifications 获取用户最近的 limit 条通知，按时间倒序
+func (r *notificationRepository) GetNotifications(ctx context.Context, userId string, limit int) (*[]model.Notification, error) {
+	var notifications []model.Notification
+	if err := r.DB(ctx).Where("user_id = ?", userId).Order("id DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return &notifications, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userId string) (int64, error) {
+	var count int64
+	if err := r.DB(ctx).Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", userId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkAllRead 将用户的未读通知全部标记为已读
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userId string, now time.Time) error {
+	return r.DB(ctx).Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", userId).Update("read_at", now).Error
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, order *model.Order) error
+	UpdateOrder(ctx context.Context, order *model.Order) error
+	GetOrder(ctx context.Context, orderId string) (*model.Order, error)
+	GetOrderForUpdate(ctx context.Context, orderId string) (*model.Order, error)
+	GetOrdersByUserId(ctx context.Context, userId string) (*[]model.Order, error)
+	CancelExpiredOrders(ctx context.Context, now time.Time) (int64, error)
+}
+
+func NewOrderRepository(
+	repository *Repository,
+) OrderRepository {
+	return &orderRepository{
+		Repository: repository,
+	}
+}
+
+type orderRepository struct {
+	*Repository
+}
+
+func (r *orderRepository) CreateOrder(ctx context.Context, order *model.Order) error {
+	return r.DB(ctx).Create(order).Error
+}
+
+func (r *orderRepository) UpdateOrder(ctx context.Context, order *model.Order) error {
+	return r.DB(ctx).Save(order).Error
+}
+
+// GetOrder 获取订单，不存在时返回 nil
+func (r *orderRepository) GetOrder(ctx context.Context, orderId string) (*model.Order, error) {
+	var order model.Order
+	if err := r.DB(ctx).Where("order_id = ?", orderId).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrderForUpdate 在事务中锁定订单记录后读取，用于串行化同一订单的状态迁移，不存在时返回 nil
+func (r *orderRepository) GetOrderForUpdate(ctx context.Context, orderId string) (*model.Order, error) {
+	var order model.Order
+	if err := r.DB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderId).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrdersByUserId 按创建时间倒序获取用户下的全部订单，包括为组织购买的订单
+func (r *orderRepository) GetOrdersByUserId(ctx context.Context, userId string) (*[]model.Order, error) {
+	var orders []model.Order
+	if err := r.DB(ctx).Where("user_id = ?", userId).Order("id DESC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return &orders, nil
+}
+
+// CancelExpiredOrders 取消超时未付款的订单，返回取消的数量
+func (r *orderRepository) CancelExpiredOrders(ctx context.Context, now time.Time) (int64, error) {
+	result := r.DB(ctx).Model(&model.Order{}).
+		Where("status IN ? AND expires_at < ?", []string{model.OrderCreated, model.OrderPendingPayment}, now).
+		Update("status", model.OrderCancelled)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
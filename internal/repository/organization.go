@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrganizationRepository interface {
+	CreateOrganization(ctx context.Context, org *model.Organization) error
+	GetOrganization(ctx context.Context, orgId string) (*model.Organization, error)
+	GetOrganizationForUpdate(ctx context.Context, orgId string) (*model.Organization, error)
+	GetOrganizationsByOrgIds(ctx context.Context, orgIds []string) (*[]model.Organization, error)
+	UpdateOrganization(ctx context.Context, org *model.Organization) error
+	DebitTraffic(ctx context.Context, orgId string, bytes int64) (int64, error)
+	GetTrafficResetDue(ctx context.Context, now time.Time, limit int) (*[]model.Organization, error)
+	GetPrivilegeExpired(ctx context.Context, before time.Time, limit int) (*[]model.Organization, error)
+	GetScheduledPlanDue(ctx context.Context, now time.Time, limit int) (*[]model.Organization, error)
+	GetMembers(ctx context.Context, orgId string) (*[]model.OrganizationMember, error)
+	GetMember(ctx context.Context, orgId string, userId string) (*model.OrganizationMember, error)
+	GetMembershipsByUserId(ctx context.Context, userId string) (*[]model.OrganizationMember, error)
+	SaveMember(ctx context.Context, member *model.OrganizationMember) error
+	DeleteMember(ctx context.Context, orgId string, userId string) (bool, error)
+}
+
+func NewOrganizationRepository(
+	repository *Repository,
+) OrganizationRepository {
+	return &organizationRepository{
+		Repository: repository,
+	}
+}
+
+type organizationRepository struct {
+	*Repository
+}
+
+func (r *organizationRepository) CreateOrganization(ctx context.Context, org *model.Organization) error {
+	return r.DB(ctx).Create(org).Error
+}
+
+// GetOrganization 获取组织，不存在时返回 nil
+func (r *organizationRepository) GetOrganization(ctx context.Context, orgId string) (*model.Organization, error) {
+	var org model.Organization
+	if err := r.DB(ctx).Where("org_id = ?", orgId).First(&org).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// GetOrganizationForUpdate 在事务中锁定组织记录后读取，用于串行化对同一组织的流量相关操作，不存在时返回 nil
+func (r *organizationRepository) GetOrganizationForUpdate(ctx context.Context, orgId string) (*model.Organization, error) {
+	var org model.Organization
+	if err := r.DB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("org_id = ?", orgId).First(&org).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// GetOrganizationsByOrgIds 批量获取组织，不存在的ID会被忽略
+func (r *organizationRepository) GetOrganizationsByOrgIds(ctx context.Context, orgIds []string) (*[]model.Organization, error) {
+	var orgs []model.Organization
+	if len(orgIds) == 0 {
+		return &orgs, nil
+	}
+	if err := r.DB(ctx).Where("org_id IN ?", orgIds).Order("id ASC").Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	return &orgs, nil
+}
+
+// GetTrafficResetDue 获取到了重置流量时间的组织，按重置时间排序，最多 limit 个
+func (r *organizationRepository) GetTrafficResetDue(ctx context.Context, now time.Time, limit int) (*[]model.Organization, error) {
+	var orgs []model.Organization
+	if err := r.DB(ctx).Where("next_traffic_reset <= ? AND privilege_expiry > next_traffic_reset", now).
+		Order("next_traffic_reset ASC").Limit(limit).Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	return &orgs, nil
+}
+
+// GetPrivilegeExpired 获取付费套餐在 before 之前到期、尚未降级的组织，按到期时间排序，最多 limit 个
+// 有待生效的降级的组织由 GetScheduledPlanDue 处理，不在其中
+func (r *organizationRepository) GetPrivilegeExpired(ctx context.Context, before time.Time, limit int) (*[]model.Organization, error) {
+	var orgs []model.Organization
+	if err := r.DB(ctx).Where("user_group <> ? AND privilege_expiry <= ? AND scheduled_group = 0", model.FreeUserGroup, before).
+		Order("privilege_expiry ASC").Limit(limit).Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	return &orgs, nil
+}
+
+// GetScheduledPlanDue 获取特权已到期、有待生效的降级的组织，按到期时间排序，最多 limit 个
+func (r *organizationRepository) GetScheduledPlanDue(ctx context.Context, now time.Time, limit int) (*[]model.Organization, error) {
+	var orgs []model.Organization
+	if err := r.DB(ctx).Where("scheduled_group <> 0 AND privilege_expiry <= ?", now).
+		Order("privilege_expiry ASC").Limit(limit).Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	return &orgs, nil
+}
+
+func (r *organizationRepository) UpdateOrganization(ctx context.Context, org *model.Organization) error {
+	return r.DB(ctx).Save(org).Error
+}
+
+// DebitTraffic 原子扣减组织的剩余流量（最低扣至 0），返回扣减后的剩余流量
+// 应在事务中调用，扣减语句持有的行锁保证读到的是本次扣减后的值
+func (r *organizationRepository) DebitTraffic(ctx context.Context, orgId string, bytes int64) (int64, error) {
+	err := r.DB(ctx).Model(&model.Organization{}).Where("org_id = ?", orgId).
+		Update("remaining_traffic", gorm.Expr("CASE WHEN remaining_traffic > ? THEN remaining_traffic - ? ELSE 0 END", bytes, bytes)).Error
+	if err != nil {
+		return 0, err
+	}
+	var remaining int64
+	if err := r.DB(ctx).Model(&model.Organization{}).Where("org_id = ?", orgId).Select("remaining_traffic").Scan(&remaining).Error; err != nil {
+		return 0, err
+	}
+	return remaining, nil
+}
+
+func (r *organizationRepository) GetMembers(ctx context.Context, orgId string) (*[]model.OrganizationMember, error) {
+	var members []model.OrganizationMember
+	if err := r.DB(ctx).Where("org_id = ?", orgId).Order("id ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return &members, nil
+}
+
+// GetMember 获取用户在组织中的成员记录，不存在时返回 nil
+func (r *organizationRepository) GetMember(ctx context.Context, orgId string, userId string) (*model.OrganizationMember, error) {
+	var member model.OrganizationMember
+	if err := r.DB(ctx).Where("org_id = ? AND user_id = ?", orgId, userId).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetMembershipsByUserId 获取用户加入的全部组织
+func (r *organizationRepository) GetMembershipsByUserId(ctx context.Context, userId string) (*[]model.OrganizationMember, error) {
+	var members []model.OrganizationMember
+	if err := r.DB(ctx).Where("user_id = ?", userId).Order("id ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return &members, nil
+}
+
+func (r *organizationRepository) SaveMember(ctx context.Context, member *model.OrganizationMember) error {
+	return r.DB(ctx).Save(member).Error
+}
+
+// DeleteMember 移除成员，返回记录是否存在
+func (r *organizationRepository) DeleteMember(ctx context.Context, orgId string, userId string) (bool, error) {
+	result := r.DB(ctx).Unscoped().Where("org_id = ? AND user_id = ?", orgId, userId).Delete(&model.OrganizationMember{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
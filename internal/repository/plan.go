@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"hyacinth-backend/internal/model"
+)
+
+type PlanRepository interface {
+	GetPlans(ctx context.Context) (*[]model.Plan, error)
+}
+
+func NewPlanRepository(
+	repository *Repository,
+) PlanRepository {
+	return &planRepository{
+		Repository: repository,
+	}
+}
+
+type planRepository struct {
+	*Repository
+}
+
+// GetPlans 获取套餐目录中的全部套餐，包括已下架的套餐
+func (r *planRepository) GetPlans(ctx context.Context) (*[]model.Plan, error) {
+	var plans []model.Plan
+	if err := r.DB(ctx).Order("sort_order ASC, user_group ASC").Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	return &plans, nil
+}
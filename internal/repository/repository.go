@@ -55,6 +55,10 @@ func (r *Repository) DB(ctx context.Context) *gorm.DB {
 }
 
 func (r *Repository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// 已处于事务中时直接复用，使嵌套调用的业务方法共享同一事务
+	if _, ok := ctx.Value(ctxTxKey).(*gorm.DB); ok {
+		return fn(ctx)
+	}
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		ctx = context.WithValue(ctx, ctxTxKey, tx)
 		return fn(ctx)
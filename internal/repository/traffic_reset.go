@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"hyacinth-backend/internal/model"
+
+	"gorm.io/gorm/clause"
+)
+
+type TrafficResetRepository interface {
+	CreateReset(ctx context.Context, reset *model.TrafficReset) (bool, error)
+}
+
+func NewTrafficResetRepository(
+	repository *Repository,
+) TrafficResetRepository {
+	return &trafficResetRepository{
+		Repository: repository,
+	}
+}
+
+type trafficResetRepository struct {
+	*Repository
+}
+
+// CreateReset 记录一次流量重置，同一账户同一计费周期已有记录时不写入并返回 false
+func (r *trafficResetRepository) CreateReset(ctx context.Context, reset *model.TrafficReset) (bool, error) {
+	result := r.DB(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(reset)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
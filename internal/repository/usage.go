@@ -2,14 +2,23 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	v1 "hyacinth-backend/api/v1"
 	"hyacinth-backend/internal/model"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// usageInsertBatchSize 批量写入流量记录时每条 INSERT 的行数
+const usageInsertBatchSize = 500
+
 type UsageRepository interface {
 	GetUsage(ctx context.Context, userId string, vnetId string, timeRange string) (*[]v1.UsageData, error)
+	CreateUsages(ctx context.Context, usages []model.Usage) error
+	GetUsageBatch(ctx context.Context, nodeId string, batchId string) (*model.UsageBatch, error)
+	CreateUsageBatch(ctx context.Context, batch *model.UsageBatch) error
 }
 
 func NewUsageRepository(
@@ -158,3 +167,28 @@ func (r *usageRepository) GetUsage(ctx context.Context, userId string, vnetId st
 
 	return &results, nil
 }
+
+// CreateUsages 批量写入流量记录
+func (r *usageRepository) CreateUsages(ctx context.Context, usages []model.Usage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+	return r.DB(ctx).CreateInBatches(usages, usageInsertBatchSize).Error
+}
+
+// GetUsageBatch 获取已处理的上报批次，不存在时返回 nil
+func (r *usageRepository) GetUsageBatch(ctx context.Context, nodeId string, batchId string) (*model.UsageBatch, error) {
+	var batch model.UsageBatch
+	if err := r.DB(ctx).Where("node_id = ? AND batch_id = ?", nodeId, batchId).First(&batch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// CreateUsageBatch 记录上报批次，(node_id, batch_id) 唯一索引保证并发重试时只有一个能写入
+func (r *usageRepository) CreateUsageBatch(ctx context.Context, batch *model.UsageBatch) error {
+	return r.DB(ctx).Create(batch).Error
+}
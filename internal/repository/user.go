@@ -5,8 +5,10 @@ import (
 	"errors"
 	v1 "hyacinth-backend/api/v1"
 	"hyacinth-backend/internal/model"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
@@ -15,6 +17,11 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id string) (*model.User, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	GetByIDForUpdate(ctx context.Context, id string) (*model.User, error)
+	DebitTraffic(ctx context.Context, id string, bytes int64) (int64, error)
+	GetTrafficResetDue(ctx context.Context, now time.Time, limit int) (*[]model.User, error)
+	GetPrivilegeExpired(ctx context.Context, before time.Time, limit int) (*[]model.User, error)
+	GetScheduledPlanDue(ctx context.Context, now time.Time, limit int) (*[]model.User, error)
 }
 
 func NewUserRepository(
@@ -75,3 +82,62 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*m
 	}
 	return &user, nil
 }
+
+// GetByIDForUpdate 在事务中锁定用户记录后读取，用于串行化对同一用户的流量相关操作
+func (r *userRepository) GetByIDForUpdate(ctx context.Context, userId string) (*model.User, error) {
+	var user model.User
+	if err := r.DB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userId).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, v1.ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DebitTraffic 原子扣减剩余流量（最低扣至 0），返回扣减后的剩余流量
+// 应在事务中调用，扣减语句持有的行锁保证读到的是本次扣减后的值
+func (r *userRepository) DebitTraffic(ctx context.Context, userId string, bytes int64) (int64, error) {
+	err := r.DB(ctx).Model(&model.User{}).Where("user_id = ?", userId).
+		Update("remaining_traffic", gorm.Expr("CASE WHEN remaining_traffic > ? THEN remaining_traffic - ? ELSE 0 END", bytes, bytes)).Error
+	if err != nil {
+		return 0, err
+	}
+	var remaining int64
+	if err := r.DB(ctx).Model(&model.User{}).Where("user_id = ?", userId).Select("remaining_traffic").Scan(&remaining).Error; err != nil {
+		return 0, err
+	}
+	return remaining, nil
+}
+
+// GetTrafficResetDue 获取到了重置流量时间的用户，按重置时间排序，最多 limit 个
+// 重置时间不早于特权到期时间的用户不返回，由特权到期处理
+func (r *userRepository) GetTrafficResetDue(ctx context.Context, now time.Time, limit int) (*[]model.User, error) {
+	var users []model.User
+	if err := r.DB(ctx).Where("next_traffic_reset <= ? AND privilege_expiry > next_traffic_reset", now).
+		Order("next_traffic_reset ASC").Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return &users, nil
+}
+
+// GetPrivilegeExpired 获取付费套餐在 before 之前到期、尚未降级的用户，按到期时间排序，最多 limit 个
+// 有待生效的降级的用户由 GetScheduledPlanDue 处理，不在其中
+func (r *userRepository) GetPrivilegeExpired(ctx context.Context, before time.Time, limit int) (*[]model.User, error) {
+	var users []model.User
+	if err := r.DB(ctx).Where("user_group <> ? AND privilege_expiry <= ? AND scheduled_group = 0", model.FreeUserGroup, before).
+		Order("privilege_expiry ASC").Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return &users, nil
+}
+
+// GetScheduledPlanDue 获取特权已到期、有待生效的降级的用户，按到期时间排序，最多 limit 个
+func (r *userRepository) GetScheduledPlanDue(ctx context.Context, now time.Time, limit int) (*[]model.User, error) {
+	var users []model.User
+	if err := r.DB(ctx).Where("scheduled_group <> 0 AND privilege_expiry <= ?", now).
+		Order("privilege_expiry ASC").Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return &users, nil
+}
@@ -2,7 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
+	v1 "hyacinth-backend/api/v1"
 	"hyacinth-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type VnetRepository interface {
@@ -15,6 +20,17 @@ type VnetRepository interface {
 	GetOnlineTunnels(ctx context.Context, userId string) (int, error)
 	GetOnlineDevicesCount(ctx context.Context, userId string) (int, error)
 	GetRunningVnetCount(ctx context.Context, userId string) (int, error)
+	GetVnetsByOrgIds(ctx context.Context, orgIds []string) (*[]model.Vnet, error)
+	GetVnetsByOwner(ctx context.Context, userId string, orgId string) (*[]model.Vnet, error)
+	GetRunningVnetCountByOrgId(ctx context.Context, orgId string) (int, error)
+	GetVnetsByNodeId(ctx context.Context, nodeId string) (*[]model.Vnet, error)
+	GetVnetsByVnetIds(ctx context.Context, vnetIds []string) (*[]model.Vnet, error)
+	AckVnetRevision(ctx context.Context, vnetId string, revision int64) (bool, error)
+	GetVnetByToken(ctx context.Context, token string) (*model.Vnet, error)
+	GetVnetByVnetIdForUpdate(ctx context.Context, vnetId string) (*model.Vnet, error)
+	ClearNodeAssignment(ctx context.Context, nodeId string) (int64, error)
+	GetEnabledVnets(ctx context.Context) (*[]model.Vnet, error)
+	GetVnetIdsByLastUsage(ctx context.Context, vnetIds []string) ([]string, error)
 }
 
 func NewVnetRepository(
@@ -100,11 +116,139 @@ func (r *vnetRepository) GetOnlineDevicesCount(ctx context.Context, userId strin
 	return int(totalOnlineDevices), nil
 }
 
+// GetRunningVnetCount 获取用户运行中的个人虚拟网络数量，组织的虚拟网络计入组织
 func (r *vnetRepository) GetRunningVnetCount(ctx context.Context, userId string) (int, error) {
 	var count int64
-	err := r.DB(ctx).Model(&model.Vnet{}).Where("user_id = ? AND enabled = ? AND deleted_at IS NULL", userId, true).Count(&count).Error
+	err := r.DB(ctx).Model(&model.Vnet{}).Where("user_id = ? AND org_id = '' AND enabled = ? AND deleted_at IS NULL", userId, true).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// GetVnetsByOrgIds 获取多个组织的全部虚拟网络
+func (r *vnetRepository) GetVnetsByOrgIds(ctx context.Context, orgIds []string) (*[]model.Vnet, error) {
+	var vnets []model.Vnet
+	if len(orgIds) == 0 {
+		return &vnets, nil
+	}
+	err := r.DB(ctx).Where("org_id IN ?", orgIds).Order("id ASC").Find(&vnets).Error
+	if err != nil {
+		return nil, err
+	}
+	return &vnets, nil
+}
+
+// GetVnetsByOwner 获取同一所有者的虚拟网络：orgId 非空时为该组织的虚拟网络，否则为用户的个人虚拟网络
+func (r *vnetRepository) GetVnetsByOwner(ctx context.Context, userId string, orgId string) (*[]model.Vnet, error) {
+	var vnets []model.Vnet
+	db := r.DB(ctx)
+	if orgId != "" {
+		db = db.Where("org_id = ?", orgId)
+	} else {
+		db = db.Where("user_id = ? AND org_id = ''", userId)
+	}
+	if err := db.Order("id ASC").Find(&vnets).Error; err != nil {
+		return nil, err
+	}
+	return &vnets, nil
+}
+
+func (r *vnetRepository) GetRunningVnetCountByOrgId(ctx context.Context, orgId string) (int, error) {
+	var count int64
+	err := r.DB(ctx).Model(&model.Vnet{}).Where("org_id = ? AND enabled = ? AND deleted_at IS NULL", orgId, true).Count(&count).Error
 	if err != nil {
 		return 0, err
 	}
 	return int(count), nil
 }
+
+// GetVnetsByNodeId 获取分配给指定节点的虚拟网络，尚未分配节点的虚拟网络不对任何节点可见
+func (r *vnetRepository) GetVnetsByNodeId(ctx context.Context, nodeId string) (*[]model.Vnet, error) {
+	var vnets []model.Vnet
+	err := r.DB(ctx).Where("node_id = ? AND deleted_at IS NULL", nodeId).Order("id ASC").Find(&vnets).Error
+	if err != nil {
+		return nil, err
+	}
+	return &vnets, nil
+}
+
+// GetVnetsByVnetIds 批量获取虚拟网络，不存在的ID会被忽略
+func (r *vnetRepository) GetVnetsByVnetIds(ctx context.Context, vnetIds []string) (*[]model.Vnet, error) {
+	var vnets []model.Vnet
+	if len(vnetIds) == 0 {
+		return &vnets, nil
+	}
+	err := r.DB(ctx).Where("vnet_id IN ?", vnetIds).Find(&vnets).Error
+	if err != nil {
+		return nil, err
+	}
+	return &vnets, nil
+}
+
+// AckVnetRevision 仅当版本号与当前版本一致时清除 NeedUpdate，返回是否清除成功
+func (r *vnetRepository) AckVnetRevision(ctx context.Context, vnetId string, revision int64) (bool, error) {
+	result := r.DB(ctx).Model(&model.Vnet{}).
+		Where("vnet_id = ? AND revision = ? AND deleted_at IS NULL", vnetId, revision).
+		Update("need_update", false)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetVnetByToken 按接入令牌获取虚拟网络，不存在时返回 nil
+func (r *vnetRepository) GetVnetByToken(ctx context.Context, token string) (*model.Vnet, error) {
+	var vnet model.Vnet
+	if err := r.DB(ctx).Where("token = ?", token).First(&vnet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &vnet, nil
+}
+
+// GetVnetByVnetIdForUpdate 在事务中锁定虚拟网络记录后读取，用于串行化同一虚拟网络的客户端接入
+func (r *vnetRepository) GetVnetByVnetIdForUpdate(ctx context.Context, vnetId string) (*model.Vnet, error) {
+	var vnet model.Vnet
+	if err := r.DB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("vnet_id = ?", vnetId).First(&vnet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, v1.ErrNotFound
+		}
+		return nil, err
+	}
+	return &vnet, nil
+}
+
+// ClearNodeAssignment 解除虚拟网络与节点的分配关系，返回受影响的虚拟网络数量
+func (r *vnetRepository) ClearNodeAssignment(ctx context.Context, nodeId string) (int64, error) {
+	result := r.DB(ctx).Model(&model.Vnet{}).Where("node_id = ?", nodeId).Update("node_id", "")
+	return result.RowsAffected, result.Error
+}
+
+// GetEnabledVnets 获取所有已启用的虚拟网络，按创建顺序排列
+func (r *vnetRepository) GetEnabledVnets(ctx context.Context) (*[]model.Vnet, error) {
+	var vnets []model.Vnet
+	if err := r.DB(ctx).Where("enabled = ?", true).Order("id ASC").Find(&vnets).Error; err != nil {
+		return nil, err
+	}
+	return &vnets, nil
+}
+
+// GetVnetIdsByLastUsage 按最近一条流量记录的时间从晚到早返回虚拟网络ID，没有流量记录的虚拟网络不返回
+func (r *vnetRepository) GetVnetIdsByLastUsage(ctx context.Context, vnetIds []string) ([]string, error) {
+	var ids []string
+	if len(vnetIds) == 0 {
+		return ids, nil
+	}
+	err := r.DB(ctx).Model(&model.Usage{}).
+		Where("vnet_id IN ?", vnetIds).
+		Group("vnet_id").
+		Order("MAX(created_at) DESC").
+		Pluck("vnet_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type VnetAclRepository interface {
+	GetRules(ctx context.Context, vnetId string) (*[]model.VnetAclRule, error)
+	GetRule(ctx context.Context, vnetId string, ruleId string) (*model.VnetAclRule, error)
+	CountRules(ctx context.Context, vnetId string) (int64, error)
+	CreateRule(ctx context.Context, rule *model.VnetAclRule) error
+	UpdateRule(ctx context.Context, rule *model.VnetAclRule) error
+	DeleteRule(ctx context.Context, vnetId string, ruleId string) (bool, error)
+	GetMemberTags(ctx context.Context, vnetId string) (*[]model.VnetMemberTag, error)
+	SetMemberTags(ctx context.Context, vnetId string, clientId string, tags []string) error
+}
+
+func NewVnetAclRepository(
+	repository *Repository,
+) VnetAclRepository {
+	return &vnetAclRepository{
+		Repository: repository,
+	}
+}
+
+type vnetAclRepository struct {
+	*Repository
+}
+
+// GetRules 按匹配顺序获取虚拟网络的访问控制规则
+func (r *vnetAclRepository) GetRules(ctx context.Context, vnetId string) (*[]model.VnetAclRule, error) {
+	var rules []model.VnetAclRule
+	if err := r.DB(ctx).Where("vnet_id = ?", vnetId).Order("priority ASC, id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// GetRule 获取虚拟网络的一条规则，不存在时返回 nil
+func (r *vnetAclRepository) GetRule(ctx context.Context, vnetId string, ruleId string) (*model.VnetAclRule, error) {
+	var rule model.VnetAclRule
+	if err := r.DB(ctx).Where("vnet_id = ? AND rule_id = ?", vnetId, ruleId).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *vnetAclRepository) CountRules(ctx context.Context, vnetId string) (int64, error) {
+	var count int64
+	if err := r.DB(ctx).Model(&model.VnetAclRule{}).Where("vnet_id = ?", vnetId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *vnetAclRepository) CreateRule(ctx context.Context, rule *model.VnetAclRule) error {
+	return r.DB(ctx).Create(rule).Error
+}
+
+func (r *vnetAclRepository) UpdateRule(ctx context.Context, rule *model.VnetAclRule) error {
+	return r.DB(ctx).Save(rule).Error
+}
+
+// DeleteRule 删除规则，返回规则是否存在
+func (r *vnetAclRepository) DeleteRule(ctx context.Context, vnetId string, ruleId string) (bool, error) {
+	result := r.DB(ctx).Unscoped().Where("vnet_id = ? AND rule_id = ?", vnetId, ruleId).Delete(&model.VnetAclRule{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *vnetAclRepository) GetMemberTags(ctx context.Context, vnetId string) (*[]model.VnetMemberTag, error) {
+	var tags []model.VnetMemberTag
+	if err := r.DB(ctx).Where("vnet_id = ?", vnetId).Order("client_id ASC, tag ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return &tags, nil
+}
+
+// SetMemberTags 替换成员的全部标签
+func (r *vnetAclRepository) SetMemberTags(ctx context.Context, vnetId string, clientId string, tags []string) error {
+	if err := r.DB(ctx).Unscoped().Where("vnet_id = ? AND client_id = ?", vnetId, clientId).Delete(&model.VnetMemberTag{}).Error; err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	records := make([]model.VnetMemberTag, 0, len(tags))
+	for _, tag := range tags {
+		records = append(records, model.VnetMemberTag{VnetId: vnetId, ClientId: clientId, Tag: tag})
+	}
+	return r.DB(ctx).Create(&records).Error
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type VnetBanRepository interface {
+	GetBans(ctx context.Context, vnetId string) (*[]model.VnetBan, error)
+	GetActiveBans(ctx context.Context, vnetId string, now time.Time) (*[]model.VnetBan, error)
+	GetBan(ctx context.Context, vnetId string, banId string) (*model.VnetBan, error)
+	CreateBan(ctx context.Context, ban *model.VnetBan) error
+	UpdateBan(ctx context.Context, ban *model.VnetBan) error
+	DeleteBan(ctx context.Context, vnetId string, banId string) (bool, error)
+}
+
+func NewVnetBanRepository(
+	repository *Repository,
+) VnetBanRepository {
+	return &vnetBanRepository{
+		Repository: repository,
+	}
+}
+
+type vnetBanRepository struct {
+	*Repository
+}
+
+// GetBans 获取虚拟网络的全部封禁记录（包括已过期的），最近创建的在前
+func (r *vnetBanRepository) GetBans(ctx context.Context, vnetId string) (*[]model.VnetBan, error) {
+	var bans []model.VnetBan
+	if err := r.DB(ctx).Where("vnet_id = ?", vnetId).Order("id DESC").Find(&bans).Error; err != nil {
+		return nil, err
+	}
+	return &bans, nil
+}
+
+// GetActiveBans 获取虚拟网络仍然有效的封禁记录
+func (r *vnetBanRepository) GetActiveBans(ctx context.Context, vnetId string, now time.Time) (*[]model.VnetBan, error) {
+	var bans []model.VnetBan
+	if err := r.DB(ctx).Where("vnet_id = ? AND (expires_at IS NULL OR expires_at > ?)", vnetId, now).Find(&bans).Error; err != nil {
+		return nil, err
+	}
+	return &bans, nil
+}
+
+// GetBan 获取虚拟网络的一条封禁记录，不存在时返回 nil
+func (r *vnetBanRepository) GetBan(ctx context.Context, vnetId string, banId string) (*model.VnetBan, error) {
+	var ban model.VnetBan
+	if err := r.DB(ctx).Where("vnet_id = ? AND ban_id = ?", vnetId, banId).First(&ban).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ban, nil
+}
+
+func (r *vnetBanRepository) CreateBan(ctx context.Context, ban *model.VnetBan) error {
+	return r.DB(ctx).Create(ban).Error
+}
+
+func (r *vnetBanRepository) UpdateBan(ctx context.Context, ban *model.VnetBan) error {
+	return r.DB(ctx).Save(ban).Error
+}
+
+// DeleteBan 解除封禁，返回记录是否存在
+func (r *vnetBanRepository) DeleteBan(ctx context.Context, vnetId string, banId string) (bool, error) {
+	result := r.DB(ctx).Unscoped().Where("vnet_id = ? AND ban_id = ?", vnetId, banId).Delete(&model.VnetBan{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"hyacinth-backend/internal/model"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+type VnetClientRepository interface {
+	UpsertVnetClient(ctx context.Context, client *model.VnetClient) error
+	DeleteVnetClient(ctx context.Context, vnetId string, clientId string) (bool, error)
+	GetVnetClientsByVnetId(ctx context.Context, vnetId string) (*[]model.VnetClient, error)
+	GetVnetClientsByNodeId(ctx context.Context, nodeId string) (*[]model.VnetClient, error)
+	TouchVnetClients(ctx context.Context, ids []uint, lastSeen time.Time) error
+	DeleteStaleVnetClients(ctx context.Context, before time.Time) ([]string, error)
+	SyncClientsOnline(ctx context.Context, vnetIds []string) error
+	UseChallenge(ctx context.Context, nonce string, expiresAt time.Time) (bool, error)
+	DeleteExpiredChallenges(ctx context.Context, now time.Time) (int64, error)
+}
+
+func NewVnetClientRepository(
+	repository *Repository,
+) VnetClientRepository {
+	return &vnetClientRepository{
+		Repository: repository,
+	}
+}
+
+type vnetClientRepository struct {
+	*Repository
+}
+
+// UpsertVnetClient 写入客户端会话，同一虚拟网络中相同客户端重新加入时覆盖原会话
+func (r *vnetClientRepository) UpsertVnetClient(ctx context.Context, client *model.VnetClient) error {
+	return r.DB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "vnet_id"}, {Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "virtual_ip", "mac_address", "public_endpoint", "node_id", "connected_at", "last_seen"}),
+	}).Create(client).Error
+}
+
+// DeleteVnetClient 删除客户端会话，返回会话是否存在
+func (r *vnetClientRepository) DeleteVnetClient(ctx context.Context, vnetId string, clientId string) (bool, error) {
+	result := r.DB(ctx).Unscoped().Where("vnet_id = ? AND client_id = ?", vnetId, clientId).Delete(&model.VnetClient{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *vnetClientRepository) GetVnetClientsByVnetId(ctx context.Context, vnetId string) (*[]model.VnetClient, error) {
+	var clients []model.VnetClient
+	if err := r.DB(ctx).Where("vnet_id = ?", vnetId).Order("connected_at ASC").Find(&clients).Error; err != nil {
+		return nil, err
+	}
+	return &clients, nil
+}
+
+func (r *vnetClientRepository) GetVnetClientsByNodeId(ctx context.Context, nodeId string) (*[]model.VnetClient, error) {
+	var clients []model.VnetClient
+	if err := r.DB(ctx).Where("node_id = ?", nodeId).Find(&clients).Error; err != nil {
+		return nil, err
+	}
+	return &clients, nil
+}
+
+// TouchVnetClients 刷新会话的最后心跳时间
+func (r *vnetClientRepository) TouchVnetClients(ctx context.Context, ids []uint, lastSeen time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.DB(ctx).Model(&model.VnetClient{}).Where("id IN ?", ids).Update("last_seen", lastSeen).Error
+}
+
+// DeleteStaleVnetClients 删除最后心跳早于指定时间的会话，返回受影响的虚拟网络ID
+func (r *vnetClientRepository) DeleteStaleVnetClients(ctx context.Context, before time.Time) ([]string, error) {
+	var vnetIds []string
+	if err := r.DB(ctx).Model(&model.VnetClient{}).Where("last_seen < ?", before).Distinct().Pluck("vnet_id", &vnetIds).Error; err != nil {
+		return nil, err
+	}
+	if len(vnetIds) == 0 {
+		return nil, nil
+	}
+	if err := r.DB(ctx).Unscoped().Where("last_seen < ?", before).Delete(&model.VnetClient{}).Error; err != nil {
+		return nil, err
+	}
+	return vnetIds, nil
+}
+
+// SyncClientsOnline 按在线会话数重新计算虚拟网络的在线客户端数量
+func (r *vnetClientRepository) SyncClientsOnline(ctx context.Context, vnetIds []string) error {
+	if len(vnetIds) == 0 {
+		return nil
+	}
+	count := r.DB(ctx).Model(&model.VnetClient{}).Select("COUNT(*)").Where("vnet_clients.vnet_id = vnets.vnet_id")
+	return r.DB(ctx).Model(&model.Vnet{}).Where("vnet_id IN ?", vnetIds).UpdateColumn("clients_online", count).Error
+}
+
+// UseChallenge 记录已用于准入的挑战，挑战此前已被使用时不写入并返回 false
+func (r *vnetClientRepository) UseChallenge(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	result := r.DB(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model.VnetClientChallenge{Nonce: nonce, ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteExpiredChallenges 删除已过期的挑战记录，过期的挑战在校验时即被拒绝，无需继续保存
+func (r *vnetClientRepository) DeleteExpiredChallenges(ctx context.Context, now time.Time) (int64, error) {
+	result := r.DB(ctx).Unscoped().Where("expires_at < ?", now).Delete(&model.VnetClientChallenge{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type VnetCollaboratorRepository interface {
+	GetCollaborators(ctx context.Context, vnetId string) (*[]model.VnetCollaborator, error)
+	GetCollaborator(ctx context.Context, vnetId string, userId string) (*model.VnetCollaborator, error)
+	GetCollaborationsByUserId(ctx context.Context, userId string) (*[]model.VnetCollaborator, error)
+	SaveCollaborator(ctx context.Context, collaborator *model.VnetCollaborator) error
+	DeleteCollaborator(ctx context.Context, vnetId string, userId string) (bool, error)
+	CreateAuditLog(ctx context.Context, log *model.VnetAuditLog) error
+	GetAuditLogs(ctx context.Context, vnetId string, limit int) (*[]model.VnetAuditLog, error)
+}
+
+func NewVnetCollaboratorRepository(
+	repository *Repository,
+) VnetCollaboratorRepository {
+	return &vnetCollaboratorRepository{
+		Repository: repository,
+	}
+}
+
+type vnetCollaboratorRepository struct {
+	*Repository
+}
+
+func (r *vnetCollaboratorRepository) GetCollaborators(ctx context.Context, vnetId string) (*[]model.VnetCollaborator, error) {
+	var collaborators []model.VnetCollaborator
+	if err := r.DB(ctx).Where("vnet_id = ?", vnetId).Order("id ASC").Find(&collaborators).Error; err != nil {
+		return nil, err
+	}
+	return &collaborators, nil
+}
+
+// GetCollaborator 获取用户在虚拟网络中的协作者记录，不存在时返回 nil
+func (r *vnetCollaboratorRepository) GetCollaborator(ctx context.Context, vnetId string, userId string) (*model.VnetCollaborator, error) {
+	var collaborator model.VnetCollaborator
+	if err := r.DB(ctx).Where("vnet_id = ? AND user_id = ?", vnetId, userId).First(&collaborator).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &collaborator, nil
+}
+
+// GetCollaborationsByUserId 获取用户参与协作的全部虚拟网络
+func (r *vnetCollaboratorRepository) GetCollaborationsByUserId(ctx context.Context, userId string) (*[]model.VnetCollaborator, error) {
+	var collaborators []model.VnetCollaborator
+	if err := r.DB(ctx).Where("user_id = ?", userId).Find(&collaborators).Error; err != nil {
+		return nil, err
+	}
+	return &collaborators, nil
+}
+
+func (r *vnetCollaboratorRepository) SaveCollaborator(ctx context.Context, collaborator *model.VnetCollaborator) error {
+	return r.DB(ctx).Save(collaborator).Error
+}
+
+// DeleteCollaborator 移除协作者，返回记录是否存在
+func (r *vnetCollaboratorRepository) DeleteCollaborator(ctx context.Context, vnetId string, userId string) (bool, error) {
+	result := r.DB(ctx).Unscoped().Where("vnet_id = ? AND user_id = ?", vnetId, userId).Delete(&model.VnetCollaborator{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *vnetCollaboratorRepository) CreateAuditLog(ctx context.Context, log *model.VnetAuditLog) error {
+	return r.DB(ctx).Create(log).Error
+}
+
+// GetAuditLogs 获取虚拟网络最近的审计日志，最新的在前
+func (r *vnetCollaboratorRepository) GetAuditLogs(ctx context.Context, vnetId string, limit int) (*[]model.VnetAuditLog, error) {
+	var logs []model.VnetAuditLog
+	if err := r.DB(ctx).Where("vnet_id = ?", vnetId).Order("id DESC").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return &logs, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"hyacinth-backend/internal/model"
+	"time"
+)
+
+type VnetEventRepository interface {
+	Create(ctx context.Context, event *model.VnetEvent) error
+	ListAfter(ctx context.Context, afterRevision int64, limit int) (*[]model.VnetEvent, error)
+	GetLatestRevision(ctx context.Context) (int64, error)
+	GetOldestRevision(ctx context.Context) (int64, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+func NewVnetEventRepository(
+	repository *Repository,
+) VnetEventRepository {
+	return &vnetEventRepository{
+		Repository: repository,
+	}
+}
+
+type vnetEventRepository struct {
+	*Repository
+}
+
+func (r *vnetEventRepository) Create(ctx context.Context, event *model.VnetEvent) error {
+	return r.DB(ctx).Create(event).Error
+}
+
+// ListAfter 按版本号顺序获取指定版本之后的事件
+func (r *vnetEventRepository) ListAfter(ctx context.Context, afterRevision int64, limit int) (*[]model.VnetEvent, error) {
+	var events []model.VnetEvent
+	err := r.DB(ctx).Where("id > ?", afterRevision).Order("id ASC").Limit(limit).Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return &events, nil
+}
+
+func (r *vnetEventRepository) GetLatestRevision(ctx context.Context) (int64, error) {
+	var revision int64
+	err := r.DB(ctx).Model(&model.VnetEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&revision).Error
+	if err != nil {
+		return 0, err
+	}
+	return revision, nil
+}
+
+// GetOldestRevision 获取保留的最早事件的版本号，没有事件时返回 0
+func (r *vnetEventRepository) GetOldestRevision(ctx context.Context) (int64, error) {
+	var revision int64
+	err := r.DB(ctx).Model(&model.VnetEvent{}).Select("COALESCE(MIN(id), 0)").Scan(&revision).Error
+	if err != nil {
+		return 0, err
+	}
+	return revision, nil
+}
+
+// DeleteBefore 删除指定时间之前写入的事件
+// 按版本号删除最早的一段，保留的事件始终连续；最新的事件不删除，避免当前版本号回退
+func (r *vnetEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	var cutoff int64
+	err := r.DB(ctx).Model(&model.VnetEvent{}).Select("COALESCE(MAX(id), 0)").Where("created_at < ?", before).Scan(&cutoff).Error
+	if err != nil {
+		return 0, err
+	}
+	latest, err := r.GetLatestRevision(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if cutoff >= latest {
+		cutoff = latest - 1
+	}
+	if cutoff <= 0 {
+		return 0, nil
+	}
+	result := r.DB(ctx).Unscoped().Where("id <= ?", cutoff).Delete(&model.VnetEvent{})
+	return result.RowsAffected, result.Error
+}
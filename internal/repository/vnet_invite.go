@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type VnetInviteRepository interface {
+	GetInvites(ctx context.Context, vnetId string) (*[]model.VnetInvite, error)
+	GetInvite(ctx context.Context, vnetId string, inviteId string) (*model.VnetInvite, error)
+	GetInviteByCode(ctx context.Context, code string) (*model.VnetInvite, error)
+	CreateInvite(ctx context.Context, invite *model.VnetInvite) error
+	UpdateInvite(ctx context.Context, invite *model.VnetInvite) error
+	GetRedemptions(ctx context.Context, vnetId string) (*[]model.VnetInviteRedemption, error)
+	GetRedemption(ctx context.Context, vnetId string, clientId string) (*model.VnetInviteRedemption, error)
+	SaveRedemption(ctx context.Context, redemption *model.VnetInviteRedemption) error
+}
+
+func NewVnetInviteRepository(
+	repository *Repository,
+) VnetInviteRepository {
+	return &vnetInviteRepository{
+		Repository: repository,
+	}
+}
+
+type vnetInviteRepository struct {
+	*Repository
+}
+
+// GetInvites 获取虚拟网络的全部邀请（包括已失效的），最近创建的在前
+func (r *vnetInviteRepository) GetInvites(ctx context.Context, vnetId string) (*[]model.VnetInvite, error) {
+	var invites []model.VnetInvite
+	if err := r.DB(ctx).Where("vnet_id = ?", vnetId).Order("id DESC").Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return &invites, nil
+}
+
+// GetInvite 获取虚拟网络的一条邀请，不存在时返回 nil
+func (r *vnetInviteRepository) GetInvite(ctx context.Context, vnetId string, inviteId string) (*model.VnetInvite, error) {
+	var invite model.VnetInvite
+	if err := r.DB(ctx).Where("vnet_id = ? AND invite_id = ?", vnetId, inviteId).First(&invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// GetInviteByCode 根据邀请码获取邀请，不存在时返回 nil
+func (r *vnetInviteRepository) GetInviteByCode(ctx context.Context, code string) (*model.VnetInvite, error) {
+	var invite model.VnetInvite
+	if err := r.DB(ctx).Where("code = ?", code).First(&invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *vnetInviteRepository) CreateInvite(ctx context.Context, invite *model.VnetInvite) error {
+	return r.DB(ctx).Create(invite).Error
+}
+
+func (r *vnetInviteRepository) UpdateInvite(ctx context.Context, invite *model.VnetInvite) error {
+	return r.DB(ctx).Save(invite).Error
+}
+
+// GetRedemptions 获取虚拟网络的全部兑换记录，最近兑换的在前
+func (r *vnetInviteRepository) GetRedemptions(ctx context.Context, vnetId string) (*[]model.VnetInviteRedemption, error) {
+	var redemptions []model.VnetInviteRedemption
+	if err := r.DB(ctx).Where("vnet_id = ?", vnetId).Order("redeemed_at DESC, id DESC").Find(&redemptions).Error; err != nil {
+		return nil, err
+	}
+	return &redemptions, nil
+}
+
+// GetRedemption 获取设备的兑换记录，不存在时返回 nil
+func (r *vnetInviteRepository) GetRedemption(ctx context.Context, vnetId string, clientId string) (*model.VnetInviteRedemption, error) {
+	var redemption model.VnetInviteRedemption
+	if err := r.DB(ctx).Where("vnet_id = ? AND client_id = ?", vnetId, clientId).First(&redemption).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &redemption, nil
+}
+
+func (r *vnetInviteRepository) SaveRedemption(ctx context.Context, redemption *model.VnetInviteRedemption) error {
+	return r.DB(ctx).Save(redemption).Error
+}
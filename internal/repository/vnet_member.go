@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type VnetMemberRepository interface {
+	GetMember(ctx context.Context, vnetId string, clientId string) (*model.VnetMember, error)
+	GetMembers(ctx context.Context, vnetId string, status string) (*[]model.VnetMember, error)
+	SaveMember(ctx context.Context, member *model.VnetMember) error
+	DeleteMember(ctx context.Context, vnetId string, clientId string) (bool, error)
+}
+
+func NewVnetMemberRepository(
+	repository *Repository,
+) VnetMemberRepository {
+	return &vnetMemberRepository{
+		Repository: repository,
+	}
+}
+
+type vnetMemberRepository struct {
+	*Repository
+}
+
+// GetMember 获取设备的审批记录，不存在时返回 nil
+func (r *vnetMemberRepository) GetMember(ctx context.Context, vnetId string, clientId string) (*model.VnetMember, error) {
+	var member model.VnetMember
+	if err := r.DB(ctx).Where("vnet_id = ? AND client_id = ?", vnetId, clientId).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetMembers 获取虚拟网络的审批记录，status 为空时返回全部，最近申请的在前
+func (r *vnetMemberRepository) GetMembers(ctx context.Context, vnetId string, status string) (*[]model.VnetMember, error) {
+	var members []model.VnetMember
+	db := r.DB(ctx).Where("vnet_id = ?", vnetId)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if err := db.Order("last_seen DESC, id DESC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return &members, nil
+}
+
+func (r *vnetMemberRepository) SaveMember(ctx context.Context, member *model.VnetMember) error {
+	return r.DB(ctx).Save(member).Error
+}
+
+// DeleteMember 删除设备的审批记录，返回记录是否存在
+func (r *vnetMemberRepository) DeleteMember(ctx context.Context, vnetId string, clientId string) (bool, error) {
+	result := r.DB(ctx).Unscoped().Where("vnet_id = ? AND client_id = ?", vnetId, clientId).Delete(&model.VnetMember{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type VnetPeerRepository interface {
+	GetPeers(ctx context.Context, vnetId string) (*[]model.VnetPeer, error)
+	GetPeer(ctx context.Context, vnetId string, clientId string) (*model.VnetPeer, error)
+	GetPeerByPublicKey(ctx context.Context, vnetId string, publicKey string) (*model.VnetPeer, error)
+	CountPeers(ctx context.Context, vnetId string) (int64, error)
+	SavePeer(ctx context.Context, peer *model.VnetPeer) error
+	DeletePeer(ctx context.Context, vnetId string, clientId string) (bool, error)
+}
+
+func NewVnetPeerRepository(
+	repository *Repository,
+) VnetPeerRepository {
+	return &vnetPeerRepository{
+		Repository: repository,
+	}
+}
+
+type vnetPeerRepository struct {
+	*Repository
+}
+
+// GetPeers 按设备标识排序获取虚拟网络登记的全部设备公钥
+func (r *vnetPeerRepository) GetPeers(ctx context.Context, vnetId string) (*[]model.VnetPeer, error) {
+	var peers []model.VnetPeer
+	if err := r.DB(ctx).Where("vnet_id = ?", vnetId).Order("client_id ASC").Find(&peers).Error; err != nil {
+		return nil, err
+	}
+	return &peers, nil
+}
+
+// GetPeer 获取设备登记的公钥，不存在时返回 nil
+func (r *vnetPeerRepository) GetPeer(ctx context.Context, vnetId string, clientId string) (*model.VnetPeer, error) {
+	var peer model.VnetPeer
+	if err := r.DB(ctx).Where("vnet_id = ? AND client_id = ?", vnetId, clientId).First(&peer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &peer, nil
+}
+
+// GetPeerByPublicKey 获取登记了该公钥的设备，不存在时返回 nil
+func (r *vnetPeerRepository) GetPeerByPublicKey(ctx context.Context, vnetId string, publicKey string) (*model.VnetPeer, error) {
+	var peer model.VnetPeer
+	if err := r.DB(ctx).Where("vnet_id = ? AND public_key = ?", vnetId, publicKey).First(&peer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &peer, nil
+}
+
+func (r *vnetPeerRepository) CountPeers(ctx context.Context, vnetId string) (int64, error) {
+	var count int64
+	if err := r.DB(ctx).Model(&model.VnetPeer{}).Where("vnet_id = ?", vnetId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *vnetPeerRepository) SavePeer(ctx context.Context, peer *model.VnetPeer) error {
+	return r.DB(ctx).Save(peer).Error
+}
+
+// DeletePeer 删除设备登记的公钥，返回记录是否存在
+func (r *vnetPeerRepository) DeletePeer(ctx context.Context, vnetId string, clientId string) (bool, error) {
+	result := r.DB(ctx).Unscoped().Where("vnet_id = ? AND client_id = ?", vnetId, clientId).Delete(&model.VnetPeer{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"hyacinth-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type VnetRouteRepository interface {
+	GetRoutes(ctx context.Context, vnetId string, status string) (*[]model.VnetRoute, error)
+	GetRoute(ctx context.Context, vnetId string, routeId string) (*model.VnetRoute, error)
+	CountRoutes(ctx context.Context, vnetId string) (int64, error)
+	CreateRoute(ctx context.Context, route *model.VnetRoute) error
+	UpdateRoute(ctx context.Context, route *model.VnetRoute) error
+	DeleteRoute(ctx context.Context, vnetId string, routeId string) (bool, error)
+}
+
+func NewVnetRouteRepository(
+	repository *Repository,
+) VnetRouteRepository {
+	return &vnetRouteRepository{
+		Repository: repository,
+	}
+}
+
+type vnetRouteRepository struct {
+	*Repository
+}
+
+// GetRoutes 按设备与前缀排序获取虚拟网络的路由，status 为空时返回全部
+func (r *vnetRouteRepository) GetRoutes(ctx context.Context, vnetId string, status string) (*[]model.VnetRoute, error) {
+	var routes []model.VnetRoute
+	db := r.DB(ctx).Where("vnet_id = ?", vnetId)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if err := db.Order("client_id ASC, prefix ASC").Find(&routes).Error; err != nil {
+		return nil, err
+	}
+	return &routes, nil
+}
+
+// GetRoute 获取虚拟网络的一条路由，不存在时返回 nil
+func (r *vnetRouteRepository) GetRoute(ctx context.Context, vnetId string, routeId string) (*model.VnetRoute, error) {
+	var route model.VnetRoute
+	if err := r.DB(ctx).Where("vnet_id = ? AND route_id = ?", vnetId, routeId).First(&route).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &route, nil
+}
+
+func (r *vnetRouteRepository) CountRoutes(ctx context.Context, vnetId string) (int64, error) {
+	var count int64
+	if err := r.DB(ctx).Model(&model.VnetRoute{}).Where("vnet_id = ?", vnetId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *vnetRouteRepository) CreateRoute(ctx context.Context, route *model.VnetRoute) error {
+	return r.DB(ctx).Create(route).Error
+}
+
+func (r *vnetRouteRepository) UpdateRoute(ctx context.Context, route *model.VnetRoute) error {
+	return r.DB(ctx).Save(route).Error
+}
+
+// DeleteRoute 删除路由，返回路由是否存在
+func (r *vnetRouteRepository) DeleteRoute(ctx context.Context, vnetId string, routeId string) (bool, error) {
+	result := r.DB(ctx).Unscoped().Where("vnet_id = ? AND route_id = ?", vnetId, routeId).Delete(&model.VnetRoute{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
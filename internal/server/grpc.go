@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/handler"
+	"hyacinth-backend/internal/middleware"
+	"hyacinth-backend/internal/service"
+	"hyacinth-backend/pkg/log"
+	"hyacinth-backend/pkg/server/grpc"
+
+	"github.com/spf13/viper"
+	ggrpc "google.golang.org/grpc"
+)
+
+// NewGRPCServer 面向中继节点的控制面服务，所有调用均需节点认证
+// 请求元数据中携带节点密钥，未配置 TLS 时只能监听回环地址，由前置的 TLS 终结代理对外提供服务
+func NewGRPCServer(
+	logger *log.Logger,
+	conf *viper.Viper,
+	nodeService service.NodeService,
+	nodeRPCHandler *handler.NodeRPCHandler,
+) *grpc.Server {
+	host := conf.GetString("grpc.host")
+	s := grpc.NewServer(
+		logger,
+		grpc.WithServerHost(host),
+		grpc.WithServerPort(conf.GetInt("grpc.port")),
+		grpc.WithTLS(conf.GetString("grpc.tls.cert_file"), conf.GetString("grpc.tls.key_file")),
+		grpc.WithServerOptions(
+			ggrpc.ChainUnaryInterceptor(middleware.NodeUnaryAuth(nodeService, logger)),
+			ggrpc.ChainStreamInterceptor(middleware.NodeStreamAuth(nodeService, logger)),
+		),
+	)
+
+	if !s.TLSEnabled() && !isLoopback(host) {
+		logger.Sugar().Fatalf("grpc.host %q is not a loopback address, configure grpc.tls or serve behind a TLS terminator", host)
+	}
+
+	v1.RegisterNodeServiceServer(s, nodeRPCHandler)
+
+	return s
+}
+
+// isLoopback 监听地址是否仅限本机访问
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
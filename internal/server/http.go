@@ -11,8 +11,10 @@ import (
 	"hyacinth-backend/docs"
 	"hyacinth-backend/internal/handler"
 	"hyacinth-backend/internal/middleware"
+	"hyacinth-backend/internal/service"
 	"hyacinth-backend/pkg/jwt"
 	"hyacinth-backend/pkg/log"
+	"hyacinth-backend/pkg/payment"
 	"hyacinth-backend/pkg/server/http"
 
 	"github.com/gin-gonic/gin"
@@ -25,7 +27,15 @@ func NewHTTPServer(
 	logger *log.Logger,
 	conf *viper.Viper,
 	jwt *jwt.JWT,
+	nodeService service.NodeService,
 	userHandler *handler.UserHandler,
+	nodeHandler *handler.NodeHandler,
+	vnetHandler *handler.VnetHandler,
+	adminHandler *handler.AdminHandler,
+	organizationHandler *handler.OrganizationHandler,
+	orderHandler *handler.OrderHandler,
+	planHandler *handler.PlanHandler,
+	notificationHandler *handler.NotificationHandler,
 ) *http.Server {
 	gin.SetMode(gin.DebugMode)
 	s := http.NewServer(
@@ -64,6 +74,17 @@ func NewHTTPServer(
 		{
 			noAuthRouter.POST("/register", userHandler.Register)
 			noAuthRouter.POST("/login", userHandler.Login)
+			// 节点注册凭一次性引导令牌认证
+			noAuthRouter.POST("/node/enroll", nodeHandler.Enroll)
+			// 兑换邀请凭邀请码认证
+			noAuthRouter.POST("/invite/redeem", vnetHandler.RedeemInvite)
+			// 支付渠道回调凭渠道签名认证
+			noAuthRouter.POST("/payment/notify/:provider", orderHandler.PaymentNotify)
+			// 模拟支付页不需要认证即可将订单标记为已支付，只在开发与测试环境使用模拟网关时注册
+			if payment.MockAllowed(conf) {
+				noAuthRouter.GET("/payment/mock/checkout", orderHandler.MockCheckout)
+			}
+			noAuthRouter.GET("/plans", planHandler.GetPlans)
 		}
 		// Non-strict permission routing group
 		noStrictAuthRouter := v1.Group("/").Use(middleware.NoStrictAuth(jwt, logger))
@@ -79,6 +100,12 @@ func NewHTTPServer(
 			strictAuthRouter.PUT("/user", userHandler.UpdateProfile)
 			strictAuthRouter.PUT("/user/password", userHandler.ChangePassword)
 			strictAuthRouter.POST("/user/purchase", userHandler.PurchasePackage)
+			strictAuthRouter.POST("/user/purchase/preview", userHandler.PreviewPurchase)
+			strictAuthRouter.GET("/user/notifications", notificationHandler.GetNotifications)
+			strictAuthRouter.POST("/user/notifications/read", notificationHandler.MarkNotificationsRead)
+			strictAuthRouter.GET("/orders", orderHandler.GetOrders)
+			strictAuthRouter.GET("/orders/:orderId", orderHandler.GetOrder)
+			strictAuthRouter.POST("/orders/:orderId/cancel", orderHandler.CancelOrder)
 
 			// User VNet operations
 			strictAuthRouter.GET("/vnet", userHandler.GetVNetList)
@@ -86,6 +113,73 @@ func NewHTTPServer(
 			strictAuthRouter.POST("/vnet", userHandler.CreateVNet)
 			strictAuthRouter.PUT("/vnet/:vnetId", userHandler.UpdateVNet)
 			strictAuthRouter.DELETE("/vnet/:vnetId", userHandler.DeleteVNet)
+			strictAuthRouter.GET("/vnet/:vnetId/clients", vnetHandler.GetVnetClients)
+			strictAuthRouter.GET("/vnet/:vnetId/leases", vnetHandler.GetVnetLeases)
+			strictAuthRouter.POST("/vnet/:vnetId/leases/reservations", vnetHandler.ReserveAddress)
+			strictAuthRouter.DELETE("/vnet/:vnetId/leases/reservations/:clientId", vnetHandler.DeleteReservation)
+			strictAuthRouter.GET("/vnet/:vnetId/acl", vnetHandler.GetVnetAcl)
+			strictAuthRouter.POST("/vnet/:vnetId/acl/rules", vnetHandler.CreateAclRule)
+			strictAuthRouter.PUT("/vnet/:vnetId/acl/rules/:ruleId", vnetHandler.UpdateAclRule)
+			strictAuthRouter.DELETE("/vnet/:vnetId/acl/rules/:ruleId", vnetHandler.DeleteAclRule)
+			strictAuthRouter.PUT("/vnet/:vnetId/acl/tags/:clientId", vnetHandler.SetMemberTags)
+			strictAuthRouter.GET("/vnet/:vnetId/members", vnetHandler.GetVnetMembers)
+			strictAuthRouter.POST("/vnet/:vnetId/members/:clientId/approve", vnetHandler.ApproveMember)
+			strictAuthRouter.POST("/vnet/:vnetId/members/:clientId/reject", vnetHandler.RejectMember)
+			strictAuthRouter.POST("/vnet/:vnetId/members/:clientId/ban", vnetHandler.BanMember)
+			strictAuthRouter.DELETE("/vnet/:vnetId/members/:clientId", vnetHandler.RemoveMember)
+			strictAuthRouter.POST("/vnet/:vnetId/clients/:clientId/kick", vnetHandler.KickClient)
+			strictAuthRouter.GET("/vnet/:vnetId/bans", vnetHandler.GetVnetBans)
+			strictAuthRouter.POST("/vnet/:vnetId/bans", vnetHandler.CreateVnetBan)
+			strictAuthRouter.PUT("/vnet/:vnetId/bans/:banId", vnetHandler.UpdateVnetBan)
+			strictAuthRouter.DELETE("/vnet/:vnetId/bans/:banId", vnetHandler.DeleteVnetBan)
+			strictAuthRouter.GET("/vnet/:vnetId/invites", vnetHandler.GetVnetInvites)
+			strictAuthRouter.POST("/vnet/:vnetId/invites", vnetHandler.CreateVnetInvite)
+			strictAuthRouter.DELETE("/vnet/:vnetId/invites/:inviteId", vnetHandler.RevokeVnetInvite)
+			strictAuthRouter.GET("/vnet/:vnetId/collaborators", vnetHandler.GetVnetCollaborators)
+			strictAuthRouter.PUT("/vnet/:vnetId/collaborators", vnetHandler.SetVnetCollaborator)
+			strictAuthRouter.DELETE("/vnet/:vnetId/collaborators/:userId", vnetHandler.RemoveVnetCollaborator)
+			strictAuthRouter.GET("/vnet/:vnetId/audit", vnetHandler.GetVnetAuditLogs)
+			strictAuthRouter.GET("/vnet/:vnetId/config", vnetHandler.GetVnetConfig)
+			strictAuthRouter.GET("/vnet/:vnetId/config/qr", vnetHandler.GetVnetConfigQRCode)
+			strictAuthRouter.GET("/vnet/:vnetId/peers", vnetHandler.GetVnetPeers)
+			strictAuthRouter.PUT("/vnet/:vnetId/peers", vnetHandler.RegisterVnetPeer)
+			strictAuthRouter.DELETE("/vnet/:vnetId/peers/:clientId", vnetHandler.DeleteVnetPeer)
+			strictAuthRouter.GET("/vnet/:vnetId/routes", vnetHandler.GetVnetRoutes)
+			strictAuthRouter.POST("/vnet/:vnetId/routes", vnetHandler.AdvertiseRoute)
+			strictAuthRouter.POST("/vnet/:vnetId/routes/:routeId/approve", vnetHandler.ApproveRoute)
+			strictAuthRouter.DELETE("/vnet/:vnetId/routes/:routeId", vnetHandler.DeleteRoute)
+
+			// Organizations
+			strictAuthRouter.GET("/org", organizationHandler.GetOrganizations)
+			strictAuthRouter.POST("/org", organizationHandler.CreateOrganization)
+			strictAuthRouter.GET("/org/:orgId", organizationHandler.GetOrganization)
+			strictAuthRouter.POST("/org/:orgId/purchase", organizationHandler.PurchaseOrganizationPackage)
+			strictAuthRouter.POST("/org/:orgId/purchase/preview", organizationHandler.PreviewOrganizationPurchase)
+			strictAuthRouter.GET("/org/:orgId/members", organizationHandler.GetOrganizationMembers)
+			strictAuthRouter.PUT("/org/:orgId/members", organizationHandler.SetOrganizationMember)
+			strictAuthRouter.DELETE("/org/:orgId/members/:userId", organizationHandler.RemoveOrganizationMember)
+		}
+
+		// Relay node routing group, authenticated by node credentials
+		nodeRouter := v1.Group("/node").Use(middleware.NodeAuth(nodeService, logger))
+		{
+			nodeRouter.POST("/heartbeat", nodeHandler.Heartbeat)
+			nodeRouter.GET("/vnets/watch", nodeHandler.WatchVnets)
+			nodeRouter.POST("/usage", nodeHandler.ReportUsage)
+			nodeRouter.POST("/clients/challenge", nodeHandler.ClientChallenge)
+			nodeRouter.POST("/clients/admit", nodeHandler.AdmitClient)
+			nodeRouter.POST("/clients/join", nodeHandler.ClientJoin)
+			nodeRouter.POST("/clients/leave", nodeHandler.ClientLeave)
+			nodeRouter.POST("/clients/heartbeat", nodeHandler.ClientHeartbeat)
+		}
+
+		// Admin routing group
+		adminRouter := v1.Group("/admin").Use(middleware.StrictAuth(jwt, logger), middleware.AdminAuth(conf, logger))
+		{
+			adminRouter.GET("/nodes", adminHandler.ListNodes)
+			adminRouter.POST("/nodes/bootstrap-tokens", adminHandler.CreateBootstrapToken)
+			adminRouter.PUT("/nodes/:nodeId/drain", adminHandler.DrainNode)
+			adminRouter.DELETE("/nodes/:nodeId", adminHandler.RemoveNode)
 		}
 	}
 
@@ -2,37 +2,264 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"hyacinth-backend/internal/model"
 	"hyacinth-backend/pkg/log"
+	"hyacinth-backend/pkg/vnetpass"
 	"os"
+	"time"
 )
 
 type MigrateServer struct {
-	db  *gorm.DB
-	log *log.Logger
+	db   *gorm.DB
+	conf *viper.Viper
+	log  *log.Logger
 }
 
-func NewMigrateServer(db *gorm.DB, log *log.Logger) *MigrateServer {
+func NewMigrateServer(db *gorm.DB, conf *viper.Viper, log *log.Logger) *MigrateServer {
 	return &MigrateServer{
-		db:  db,
-		log: log,
+		db:   db,
+		conf: conf,
+		log:  log,
 	}
 }
 func (m *MigrateServer) Start(ctx context.Context) error {
 	if err := m.db.AutoMigrate(
 		&model.User{},
 		&model.Usage{},
+		&model.UsageBatch{},
 		&model.Vnet{},
+		&model.VnetEvent{},
+		&model.VnetClient{},
+		&model.VnetClientChallenge{},
+		&model.IpLease{},
+		&model.Node{},
+		&model.NodeBootstrapToken{},
+		&model.VnetAclRule{},
+		&model.VnetMemberTag{},
+		&model.VnetMember{},
+		&model.VnetBan{},
+		&model.VnetInvite{},
+		&model.VnetInviteRedemption{},
+		&model.VnetCollaborator{},
+		&model.VnetAuditLog{},
+		&model.Organization{},
+		&model.OrganizationMember{},
+		&model.VnetPeer{},
+		&model.VnetRoute{},
+		&model.Order{},
+		&model.Plan{},
+		&model.TrafficReset{},
+		&model.Notification{},
 	); err != nil {
 		m.log.Error("user migrate error", zap.Error(err))
 		return err
 	}
 	m.log.Info("AutoMigrate success")
+	if err := m.migrateVnetPasswords(ctx); err != nil {
+		m.log.Error("vnet password migrate error", zap.Error(err))
+		return err
+	}
+	if err := m.migrateNodeKeys(ctx); err != nil {
+		m.log.Error("node key migrate error", zap.Error(err))
+		return err
+	}
+	if err := m.seedPlans(ctx); err != nil {
+		m.log.Error("plan seed error", zap.Error(err))
+		return err
+	}
+	if err := m.backfillBillingCycles(ctx); err != nil {
+		m.log.Error("billing cycle backfill error", zap.Error(err))
+		return err
+	}
 	os.Exit(0)
 	return nil
 }
+
+// migrateVnetPasswords 将旧版本明文保存的虚拟网络密码转换为校验值，然后删除明文列
+// 同时清除历史变更事件快照中的密码明文；中途失败可重新执行
+func (m *MigrateServer) migrateVnetPasswords(ctx context.Context) error {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasColumn(&model.Vnet{}, "password") {
+		return nil
+	}
+
+	var legacy []struct {
+		VnetId   string
+		Password string
+	}
+	// 包括已删除的虚拟网络
+	if err := db.Table("vnets").Select("vnet_id, password").Where("password <> '' AND password_hash = ''").Find(&legacy).Error; err != nil {
+		return err
+	}
+	for _, vnet := range legacy {
+		hash, err := vnetpass.Hash(vnet.Password)
+		if err != nil {
+			return err
+		}
+		if err := db.Table("vnets").Where("vnet_id = ?", vnet.VnetId).Update("password_hash", hash).Error; err != nil {
+			return err
+		}
+	}
+
+	var events []model.VnetEvent
+	err := db.Unscoped().Where("payload LIKE ?", `%"password":%`).FindInBatches(&events, 500, func(_ *gorm.DB, _ int) error {
+		for _, event := range events {
+			var payload map[string]interface{}
+			if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+				continue
+			}
+			delete(payload, "password")
+			data, err := json.Marshal(payload)
+			if err != nil {
+				return err
+			}
+			if err := db.Model(&model.VnetEvent{}).Unscoped().Where("id = ?", event.ID).Update("payload", string(data)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	if err := db.Migrator().DropColumn(&model.Vnet{}, "password"); err != nil {
+		return err
+	}
+	m.log.Info("vnet passwords migrated", zap.Int("vnets", len(legacy)))
+	return nil
+}
+
+// migrateNodeKeys 将旧版本明文保存的节点密钥转换为哈希，然后删除明文列；中途失败可重新执行
+func (m *MigrateServer) migrateNodeKeys(ctx context.Context) error {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasColumn(&model.Node{}, "key") {
+		return nil
+	}
+
+	var legacy []struct {
+		NodeId string
+		Key    string
+	}
+	if err := db.Table("nodes").Select("node_id, `key`").Where("`key` <> '' AND key_hash = ''").Find(&legacy).Error; err != nil {
+		return err
+	}
+	for _, node := range legacy {
+		sum := sha256.Sum256([]byte(node.Key))
+		if err := db.Table("nodes").Where("node_id = ?", node.NodeId).Update("key_hash", hex.EncodeToString(sum[:])).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := db.Migrator().DropColumn(&model.Node{}, "key"); err != nil {
+		return err
+	}
+	m.log.Info("node keys migrated", zap.Int("nodes", len(legacy)))
+	return nil
+}
+
+// planSeed 配置文件 plans 中的套餐，流量以 GB 为单位
+type planSeed struct {
+	UserGroup        int      `mapstructure:"user_group"`
+	Name             string   `mapstructure:"name"`
+	Description      string   `mapstructure:"description"`
+	MonthlyPrice     int64    `mapstructure:"monthly_price"`
+	Durations        []int    `mapstructure:"durations"`
+	MonthlyTrafficGB int64    `mapstructure:"monthly_traffic_gb"`
+	VnetLimit        int      `mapstructure:"vnet_limit"`
+	ClientsPerVnet   int      `mapstructure:"clients_per_vnet"`
+	AclRulesPerVnet  int      `mapstructure:"acl_rules_per_vnet"`
+	Features         []string `mapstructure:"features"`
+	Purchasable      bool     `mapstructure:"purchasable"`
+	SortOrder        int      `mapstructure:"sort_order"`
+}
+
+// seedPlans 按配置写入数据库中还没有的套餐，已存在（包括已删除）的用户组保持数据库中的设置
+func (m *MigrateServer) seedPlans(ctx context.Context) error {
+	var seeds []planSeed
+	if err := m.conf.UnmarshalKey("plans", &seeds); err != nil {
+		return err
+	}
+	db := m.db.WithContext(ctx)
+	created := 0
+	for _, seed := range seeds {
+		var count int64
+		if err := db.Model(&model.Plan{}).Unscoped().Where("user_group = ?", seed.UserGroup).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		plan := &model.Plan{
+			UserGroup:       seed.UserGroup,
+			Name:            seed.Name,
+			Description:     seed.Description,
+			MonthlyPrice:    seed.MonthlyPrice,
+			Durations:       seed.Durations,
+			MonthlyTraffic:  seed.MonthlyTrafficGB * 1024 * 1024 * 1024,
+			VnetLimit:       seed.VnetLimit,
+			ClientsPerVnet:  seed.ClientsPerVnet,
+			AclRulesPerVnet: seed.AclRulesPerVnet,
+			Features:        seed.Features,
+			Purchasable:     seed.Purchasable,
+			SortOrder:       seed.SortOrder,
+		}
+		if err := db.Create(plan).Error; err != nil {
+			return err
+		}
+		created++
+	}
+	m.log.Info("plans seeded", zap.Int("created", created))
+	return nil
+}
+
+// backfillBillingCycles 为旧版本购买、特权未过期的付费用户与组织补充计费周期
+// 购买时间没有记录，以特权到期时间为周期起点推算，每月在到期日对应的日期重置流量
+func (m *MigrateServer) backfillBillingCycles(ctx context.Context) error {
+	db := m.db.WithContext(ctx)
+	now := time.Now()
+	backfill := func(table string, key string) (int, error) {
+		var rows []struct {
+			OwnerId         string
+			PrivilegeExpiry time.Time
+		}
+		err := db.Table(table).
+			Select(key+" AS owner_id, privilege_expiry").
+			Where("user_group <> ? AND privilege_expiry > ? AND billing_anchor IS NULL AND deleted_at IS NULL", model.FreeUserGroup, now).
+			Find(&rows).Error
+		if err != nil {
+			return 0, err
+		}
+		for _, row := range rows {
+			_, next := model.CycleBounds(row.PrivilegeExpiry, now)
+			err := db.Table(table).Where(key+" = ?", row.OwnerId).Updates(map[string]interface{}{
+				"billing_anchor":     row.PrivilegeExpiry,
+				"next_traffic_reset": next,
+			}).Error
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(rows), nil
+	}
+	users, err := backfill("users", "user_id")
+	if err != nil {
+		return err
+	}
+	orgs, err := backfill("organizations", "org_id")
+	if err != nil {
+		return err
+	}
+	m.log.Info("billing cycles backfilled", zap.Int("users", users), zap.Int("organizations", orgs))
+	return nil
+}
+
 func (m *MigrateServer) Stop(ctx context.Context) error {
 	m.log.Info("AutoMigrate stop")
 	return nil
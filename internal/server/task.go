@@ -10,18 +10,33 @@ import (
 )
 
 type TaskServer struct {
-	log       *log.Logger
-	scheduler *gocron.Scheduler
-	userTask  task.UserTask
+	log            *log.Logger
+	scheduler      *gocron.Scheduler
+	userTask       task.UserTask
+	vnetClientTask task.VnetClientTask
+	ipLeaseTask    task.IpLeaseTask
+	vnetEventTask  task.VnetEventTask
+	nodeTask       task.NodeTask
+	orderTask      task.OrderTask
 }
 
 func NewTaskServer(
 	log *log.Logger,
 	userTask task.UserTask,
+	vnetClientTask task.VnetClientTask,
+	ipLeaseTask task.IpLeaseTask,
+	vnetEventTask task.VnetEventTask,
+	nodeTask task.NodeTask,
+	orderTask task.OrderTask,
 ) *TaskServer {
 	return &TaskServer{
-		log:      log,
-		userTask: userTask,
+		log:            log,
+		userTask:       userTask,
+		vnetClientTask: vnetClientTask,
+		ipLeaseTask:    ipLeaseTask,
+		vnetEventTask:  vnetEventTask,
+		nodeTask:       nodeTask,
+		orderTask:      orderTask,
 	}
 }
 func (t *TaskServer) Start(ctx context.Context) error {
@@ -34,15 +49,114 @@ func (t *TaskServer) Start(ctx context.Context) error {
 	// if you are in China, you will need to change the time zone as follows
 	t.scheduler = gocron.NewScheduler(time.FixedZone("PRC", 8*60*60))
 
-	//_, err := t.scheduler.Every("3s").Do(func()
-	_, err := t.scheduler.CronWithSeconds("0/3 * * * * *").Do(func() {
-		err := t.userTask.CheckUser(ctx)
+	// 重置到了计费周期的付费用户与组织的月流量，重复执行不会重复重置
+	_, err := t.scheduler.CronWithSeconds("45 * * * * *").Do(func() {
+		err := t.userTask.ResetMonthlyTraffic(ctx)
 		if err != nil {
-			t.log.Error("CheckUser error", zap.Error(err))
+			t.log.Error("ResetMonthlyTraffic error", zap.Error(err))
 		}
 	})
 	if err != nil {
-		t.log.Error("CheckUser error", zap.Error(err))
+		t.log.Error("ResetMonthlyTraffic error", zap.Error(err))
+	}
+
+	// 特权到期时切换到已购买的降级套餐，停用超出新套餐限制的虚拟网络
+	_, err = t.scheduler.CronWithSeconds("40 * * * * *").Do(func() {
+		err := t.userTask.ApplyScheduledPlans(ctx)
+		if err != nil {
+			t.log.Error("ApplyScheduledPlans error", zap.Error(err))
+		}
+	})
+	if err != nil {
+		t.log.Error("ApplyScheduledPlans error", zap.Error(err))
+	}
+
+	// 降级特权到期超过宽限期的付费用户与组织，停用超出免费套餐限制的虚拟网络
+	_, err = t.scheduler.CronWithSeconds("50 * * * * *").Do(func() {
+		err := t.userTask.ExpirePrivileges(ctx)
+		if err != nil {
+			t.log.Error("ExpirePrivileges error", zap.Error(err))
+		}
+	})
+	if err != nil {
+		t.log.Error("ExpirePrivileges error", zap.Error(err))
+	}
+
+	// 清理心跳超时的客户端会话
+	_, err = t.scheduler.CronWithSeconds("0/30 * * * * *").Do(func() {
+		err := t.vnetClientTask.ExpireStaleClients(ctx)
+		if err != nil {
+			t.log.Error("ExpireStaleClients error", zap.Error(err))
+		}
+	})
+	if err != nil {
+		t.log.Error("ExpireStaleClients error", zap.Error(err))
+	}
+
+	// 清理已过期的接入挑战记录
+	_, err = t.scheduler.CronWithSeconds("20 * * * * *").Do(func() {
+		err := t.vnetClientTask.DeleteExpiredChallenges(ctx)
+		if err != nil {
+			t.log.Error("DeleteExpiredChallenges error", zap.Error(err))
+		}
+	})
+	if err != nil {
+		t.log.Error("DeleteExpiredChallenges error", zap.Error(err))
+	}
+
+	// 回收过期的地址租约
+	_, err = t.scheduler.CronWithSeconds("0 * * * * *").Do(func() {
+		err := t.ipLeaseTask.ReclaimExpiredLeases(ctx)
+		if err != nil {
+			t.log.Error("ReclaimExpiredLeases error", zap.Error(err))
+		}
+	})
+	if err != nil {
+		t.log.Error("ReclaimExpiredLeases error", zap.Error(err))
+	}
+
+	// 清理超过保留期的虚拟网络变更事件
+	_, err = t.scheduler.CronWithSeconds("30 10 * * * *").Do(func() {
+		err := t.vnetEventTask.PruneEvents(ctx)
+		if err != nil {
+			t.log.Error("PruneEvents error", zap.Error(err))
+		}
+	})
+	if err != nil {
+		t.log.Error("PruneEvents error", zap.Error(err))
+	}
+
+	// 标记心跳超时的节点为离线
+	_, err = t.scheduler.CronWithSeconds("0/15 * * * * *").Do(func() {
+		err := t.nodeTask.MarkOfflineNodes(ctx)
+		if err != nil {
+			t.log.Error("MarkOfflineNodes error", zap.Error(err))
+		}
+	})
+	if err != nil {
+		t.log.Error("MarkOfflineNodes error", zap.Error(err))
+	}
+
+	// 将虚拟网络分配到中继节点，节点失效时故障转移
+	_, err = t.scheduler.CronWithSeconds("5/15 * * * * *").Do(func() {
+		err := t.nodeTask.ScheduleVnets(ctx)
+		if err != nil {
+			t.log.Error("ScheduleVnets error", zap.Error(err))
+		}
+	})
+	if err != nil {
+		t.log.Error("ScheduleVnets error", zap.Error(err))
+	}
+
+	// 取消超时未付款的订单
+	_, err = t.scheduler.CronWithSeconds("30 * * * * *").Do(func() {
+		err := t.orderTask.CancelExpiredOrders(ctx)
+		if err != nil {
+			t.log.Error("CancelExpiredOrders error", zap.Error(err))
+		}
+	})
+	if err != nil {
+		t.log.Error("CancelExpiredOrders error", zap.Error(err))
 	}
 
 	t.scheduler.StartBlocking()
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"net/netip"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// defaultLeaseDuration 未配置 vnet.lease_duration 时动态租约的时长
+const defaultLeaseDuration = 12 * time.Hour
+
+// 未配置 vnet.ip_pool 时自动分配网段使用的地址池与网段大小
+const (
+	defaultIpPool       = "10.0.0.0/8"
+	defaultIpPoolPrefix = 24
+)
+
+// 虚拟网络网段的前缀长度范围，过大的网段浪费地址池，过小的网段容纳不了客户端
+const (
+	minIpv4RangeBits = 16
+	maxIpv4RangeBits = 30
+	minIpv6RangeBits = 64
+	maxIpv6RangeBits = 120
+)
+
+// IpamService 虚拟网络地址管理
+type IpamService interface {
+	AcquireLease(ctx context.Context, vnet *model.Vnet, clientId string, macAddress string, requestedIp string) (*model.IpLease, error)
+	GetLeases(ctx context.Context, vnetId string) (*[]model.IpLease, error)
+	ReserveAddress(ctx context.Context, vnetId string, req *v1.ReserveAddressRequest) (*model.IpLease, error)
+	AssignAddress(ctx context.Context, vnet *model.Vnet, clientId string) (*model.IpLease, error)
+	DeleteReservation(ctx context.Context, vnetId string, clientId string) error
+	ResolveIpRange(ctx context.Context, userId string, orgId string, vnetId string, ipRange string) (string, error)
+	MigrateLeases(ctx context.Context, vnetId string, ipRange string) error
+}
+
+func NewIpamService(
+	service *Service,
+	conf *viper.Viper,
+	vnetRepository repository.VnetRepository,
+	userRepository repository.UserRepository,
+	vnetClientRepository repository.VnetClientRepository,
+	ipLeaseRepository repository.IpLeaseRepository,
+	organizationRepository repository.OrganizationRepository,
+) IpamService {
+	leaseDuration := conf.GetDuration("vnet.lease_duration")
+	if leaseDuration <= 0 {
+		leaseDuration = defaultLeaseDuration
+	}
+	pool, err := netip.ParsePrefix(conf.GetString("vnet.ip_pool"))
+	if err != nil {
+		pool = netip.MustParsePrefix(defaultIpPool)
+	}
+	poolPrefix := conf.GetInt("vnet.ip_pool_prefix")
+	if poolPrefix <= 0 {
+		poolPrefix = defaultIpPoolPrefix
+	}
+	return &ipamService{
+		Service:                service,
+		leaseDuration:          leaseDuration,
+		ipPool:                 pool.Masked(),
+		ipPoolPrefix:           poolPrefix,
+		vnetRepository:         vnetRepository,
+		userRepository:         userRepository,
+		vnetClientRepository:   vnetClientRepository,
+		ipLeaseRepository:      ipLeaseRepository,
+		organizationRepository: organizationRepository,
+	}
+}
+
+type ipamService struct {
+	*Service
+	leaseDuration          time.Duration
+	ipPool                 netip.Prefix
+	ipPoolPrefix           int
+	vnetRepository         repository.VnetRepository
+	userRepository         repository.UserRepository
+	organizationRepository repository.OrganizationRepository
+	vnetClientRepository   repository.VnetClientRepository
+	ipLeaseRepository      repository.IpLeaseRepository
+}
+
+// AcquireLease 为接入的设备分配或续期地址租约，需在已锁定虚拟网络记录的事务中调用
+// 设备有静态租约时始终使用保留地址；开启 DHCP 时优先沿用设备原地址，否则分配第一个空闲地址；
+// 未开启 DHCP 时使用客户端自行指定的地址，并登记租约以检测冲突
+func (s *ipamService) AcquireLease(ctx context.Context, vnet *model.Vnet, clientId string, macAddress string, requestedIp string) (*model.IpLease, error) {
+	prefix, err := netip.ParsePrefix(vnet.IpRange)
+	if err != nil {
+		return nil, v1.ErrVnetAddressExhausted
+	}
+	prefix = prefix.Masked()
+
+	now := time.Now()
+	taken, own, err := s.takenAddresses(ctx, vnet.VnetId, clientId, now)
+	if err != nil {
+		return nil, err
+	}
+	if own != nil && own.Static {
+		return own, nil
+	}
+
+	var address string
+	switch {
+	case !vnet.EnableDHCP:
+		addr, err := netip.ParseAddr(requestedIp)
+		if err != nil || !isHostAddr(prefix, addr) || taken[addr.String()] {
+			return nil, v1.ErrVirtualIpUnavailable
+		}
+		address = addr.String()
+	case own != nil && !taken[own.Address] && containsHost(prefix, own.Address):
+		address = own.Address
+	default:
+		if address, err = firstFreeAddr(prefix, taken); err != nil {
+			return nil, err
+		}
+	}
+
+	// 地址可能仍登记在其他设备已过期的租约上，先回收再写入
+	if err := s.ipLeaseRepository.DeleteLeasesByAddress(ctx, vnet.VnetId, address, clientId); err != nil {
+		return nil, err
+	}
+	expiresAt := now.Add(s.leaseDuration)
+	if own != nil {
+		own.Address = address
+		own.MacAddress = macAddress
+		own.ExpiresAt = &expiresAt
+		if err := s.ipLeaseRepository.UpdateLease(ctx, own); err != nil {
+			return nil, err
+		}
+		return own, nil
+	}
+	lease := &model.IpLease{
+		VnetId:     vnet.VnetId,
+		Address:    address,
+		ClientId:   clientId,
+		MacAddress: macAddress,
+		ExpiresAt:  &expiresAt,
+	}
+	if err := s.ipLeaseRepository.CreateLease(ctx, lease); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+func (s *ipamService) GetLeases(ctx context.Context, vnetId string) (*[]model.IpLease, error) {
+	return s.ipLeaseRepository.GetLeasesByVnetId(ctx, vnetId)
+}
+
+// ReserveAddress 为设备保留固定地址，设备已有的租约会被替换
+func (s *ipamService) ReserveAddress(ctx context.Context, vnetId string, req *v1.ReserveAddressRequest) (*model.IpLease, error) {
+	var lease *model.IpLease
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		prefix, err := netip.ParsePrefix(vnet.IpRange)
+		if err != nil {
+			return v1.ErrVirtualIpUnavailable
+		}
+		addr, err := netip.ParseAddr(req.Address)
+		if err != nil || !isHostAddr(prefix.Masked(), addr) {
+			return v1.ErrVirtualIpUnavailable
+		}
+		taken, own, err := s.takenAddresses(ctx, vnetId, req.ClientId, time.Now())
+		if err != nil {
+			return err
+		}
+		if taken[addr.String()] {
+			return v1.ErrVirtualIpUnavailable
+		}
+
+		if err := s.ipLeaseRepository.DeleteLeasesByAddress(ctx, vnetId, addr.String(), req.ClientId); err != nil {
+			return err
+		}
+		if own == nil {
+			own = &model.IpLease{VnetId: vnetId, ClientId: req.ClientId}
+		}
+		own.Address = addr.String()
+		own.MacAddress = req.MacAddress
+		own.Static = true
+		own.ExpiresAt = nil
+		lease = own
+		if own.ID == 0 {
+			return s.ipLeaseRepository.CreateLease(ctx, own)
+		}
+		return s.ipLeaseRepository.UpdateLease(ctx, own)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// AssignAddress 为设备分配固定地址，需在已锁定虚拟网络记录的事务中调用
+// WireGuard 设备的地址写入节点的对端配置，必须长期不变，因此直接登记为静态租约；
+// 设备已有保留地址时沿用，已有动态租约且地址空闲时转为静态，否则分配第一个空闲地址
+func (s *ipamService) AssignAddress(ctx context.Context, vnet *model.Vnet, clientId string) (*model.IpLease, error) {
+	prefix, err := netip.ParsePrefix(vnet.IpRange)
+	if err != nil {
+		return nil, v1.ErrVnetAddressExhausted
+	}
+	prefix = prefix.Masked()
+
+	taken, own, err := s.takenAddresses(ctx, vnet.VnetId, clientId, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if own != nil && own.Static && containsHost(prefix, own.Address) {
+		return own, nil
+	}
+
+	var address string
+	if own != nil && !taken[own.Address] && containsHost(prefix, own.Address) {
+		address = own.Address
+	} else if address, err = firstFreeAddr(prefix, taken); err != nil {
+		return nil, err
+	}
+
+	if err := s.ipLeaseRepository.DeleteLeasesByAddress(ctx, vnet.VnetId, address, clientId); err != nil {
+		return nil, err
+	}
+	if own == nil {
+		own = &model.IpLease{VnetId: vnet.VnetId, ClientId: clientId}
+	}
+	own.Address = address
+	own.Static = true
+	own.ExpiresAt = nil
+	if own.ID == 0 {
+		if err := s.ipLeaseRepository.CreateLease(ctx, own); err != nil {
+			return nil, err
+		}
+		return own, nil
+	}
+	if err := s.ipLeaseRepository.UpdateLease(ctx, own); err != nil {
+		return nil, err
+	}
+	return own, nil
+}
+
+// DeleteReservation 取消设备的地址保留，设备下次接入时重新分配
+func (s *ipamService) DeleteReservation(ctx context.Context, vnetId string, clientId string) error {
+	lease, err := s.ipLeaseRepository.GetLeaseByClientId(ctx, vnetId, clientId)
+	if err != nil {
+		return err
+	}
+	if lease == nil || !lease.Static {
+		return v1.ErrNotFound
+	}
+	_, err = s.ipLeaseRepository.DeleteLeaseByClientId(ctx, vnetId, clientId)
+	return err
+}
+
+// ResolveIpRange 校验并规范化虚拟网络网段，为空时从地址池中分配，需在事务中调用
+// 同一所有者（orgId 非空时为组织，否则为用户个人）的虚拟网络网段互不重叠，便于日后互通；
+// 锁定所有者记录保证多实例并发创建时不会分配到相同网段
+func (s *ipamService) ResolveIpRange(ctx context.Context, userId string, orgId string, vnetId string, ipRange string) (string, error) {
+	if orgId != "" {
+		org, err := s.organizationRepository.GetOrganizationForUpdate(ctx, orgId)
+		if err != nil {
+			return "", err
+		}
+		if org == nil {
+			return "", v1.ErrNotFound
+		}
+	} else if _, err := s.userRepository.GetByIDForUpdate(ctx, userId); err != nil {
+		return "", err
+	}
+	vnets, err := s.vnetRepository.GetVnetsByOwner(ctx, userId, orgId)
+	if err != nil {
+		return "", err
+	}
+	existing := make([]netip.Prefix, 0, len(*vnets))
+	for _, vnet := range *vnets {
+		if vnet.VnetId == vnetId {
+			continue
+		}
+		// 历史数据中无法解析的网段不参与重叠检查
+		if prefix, err := netip.ParsePrefix(vnet.IpRange); err == nil {
+			existing = append(existing, prefix.Masked())
+		}
+	}
+
+	if ipRange == "" {
+		return s.allocateIpRange(existing)
+	}
+	prefix, err := parseIpRange(ipRange)
+	if err != nil {
+		return "", err
+	}
+	if overlapsAny(prefix, existing) {
+		return "", v1.ErrIpRangeOverlap
+	}
+	return prefix.String(), nil
+}
+
+// MigrateLeases 虚拟网络更换网段后清理新网段之外的租约，相关设备下次接入时重新分配地址，需在事务中调用
+func (s *ipamService) MigrateLeases(ctx context.Context, vnetId string, ipRange string) error {
+	prefix, err := netip.ParsePrefix(ipRange)
+	if err != nil {
+		return v1.ErrInvalidIpRange
+	}
+	leases, err := s.ipLeaseRepository.GetLeasesByVnetId(ctx, vnetId)
+	if err != nil {
+		return err
+	}
+	for _, lease := range *leases {
+		if containsHost(prefix.Masked(), lease.Address) {
+			continue
+		}
+		if _, err := s.ipLeaseRepository.DeleteLeaseByClientId(ctx, vnetId, lease.ClientId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allocateIpRange 按顺序返回地址池中第一个与已有网段不重叠的子网
+func (s *ipamService) allocateIpRange(existing []netip.Prefix) (string, error) {
+	if s.ipPoolPrefix < s.ipPool.Bits() || s.ipPoolPrefix > s.ipPool.Addr().BitLen() {
+		return "", v1.ErrIpPoolExhausted
+	}
+	for addr := s.ipPool.Addr(); s.ipPool.Contains(addr); {
+		candidate := netip.PrefixFrom(addr, s.ipPoolPrefix)
+		if !overlapsAny(candidate, existing) {
+			return candidate.String(), nil
+		}
+		last := lastAddr(candidate)
+		if !last.Next().IsValid() {
+			break
+		}
+		addr = last.Next()
+	}
+	return "", v1.ErrIpPoolExhausted
+}
+
+// takenAddresses 统计其他设备占用的地址（有效租约与在线会话），并返回该设备自身的租约
+func (s *ipamService) takenAddresses(ctx context.Context, vnetId string, clientId string, now time.Time) (map[string]bool, *model.IpLease, error) {
+	leases, err := s.ipLeaseRepository.GetLeasesByVnetId(ctx, vnetId)
+	if err != nil {
+		return nil, nil, err
+	}
+	clients, err := s.vnetClientRepository.GetVnetClientsByVnetId(ctx, vnetId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taken := make(map[string]bool, len(*leases)+len(*clients))
+	var own *model.IpLease
+	for i := range *leases {
+		lease := &(*leases)[i]
+		if lease.ClientId == clientId {
+			own = lease
+			continue
+		}
+		if lease.IsActive(now) {
+			taken[lease.Address] = true
+		}
+	}
+	// 租约过期但仍在线的客户端继续占用其地址
+	for _, client := range *clients {
+		if client.ClientId != clientId && client.VirtualIp != "" {
+			taken[client.VirtualIp] = true
+		}
+	}
+	return taken, own, nil
+}
+
+// isHostAddr 判断地址是否为网段内可分配的主机地址（排除网络地址与 IPv4 广播地址）
+func isHostAddr(prefix netip.Prefix, addr netip.Addr) bool {
+	if !prefix.Contains(addr) || addr == prefix.Addr() {
+		return false
+	}
+	if addr.Is4() && prefix.Bits() < 31 && !prefix.Contains(addr.Next()) {
+		return false
+	}
+	return true
+}
+
+func containsHost(prefix netip.Prefix, address string) bool {
+	addr, err := netip.ParseAddr(address)
+	return err == nil && isHostAddr(prefix, addr)
+}
+
+// firstFreeAddr 按顺序返回网段中第一个未被占用的主机地址
+func firstFreeAddr(prefix netip.Prefix, taken map[string]bool) (string, error) {
+	for addr := prefix.Addr().Next(); isHostAddr(prefix, addr); addr = addr.Next() {
+		if !taken[addr.String()] {
+			return addr.String(), nil
+		}
+	}
+	return "", v1.ErrVnetAddressExhausted
+}
+
+// parseIpRange 解析网段并校验为大小合适的私有地址网段，返回去除主机位后的网段
+func parseIpRange(ipRange string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(ipRange)
+	if err != nil {
+		return netip.Prefix{}, v1.ErrInvalidIpRange
+	}
+	prefix = prefix.Masked()
+	minBits, maxBits := minIpv4RangeBits, maxIpv4RangeBits
+	if prefix.Addr().Is6() {
+		minBits, maxBits = minIpv6RangeBits, maxIpv6RangeBits
+	}
+	if prefix.Bits() < minBits || prefix.Bits() > maxBits {
+		return netip.Prefix{}, v1.ErrInvalidIpRange
+	}
+	// 私有地址块均不小于 /16（IPv6 为 /7），对齐的网段不会跨越地址块，首地址为私有地址即整个网段为私有地址
+	if !prefix.Addr().IsPrivate() {
+		return netip.Prefix{}, v1.ErrInvalidIpRange
+	}
+	return prefix, nil
+}
+
+// sameIpRange 判断两个网段去除主机位后是否相同
+func sameIpRange(a string, b string) bool {
+	pa, errA := netip.ParsePrefix(a)
+	pb, errB := netip.ParsePrefix(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return pa.Masked() == pb.Masked()
+}
+
+func overlapsAny(prefix netip.Prefix, existing []netip.Prefix) bool {
+	for _, other := range existing {
+		if prefix.Overlaps(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastAddr 返回网段中的最后一个地址
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Masked().Addr().AsSlice()
+	for i := prefix.Bits(); i < len(bytes)*8; i++ {
+		bytes[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"hyacinth-backend/pkg/jwt"
+	"hyacinth-backend/pkg/wgkey"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// defaultBootstrapTokenTTL 未配置 node.bootstrap_token_ttl 时引导令牌的有效期
+const defaultBootstrapTokenTTL = 24 * time.Hour
+
+// NodeService 面向中继节点的控制面业务
+type NodeService interface {
+	Authenticate(ctx context.Context, nodeId string, key string) error
+	ListVnets(ctx context.Context, nodeId string) (*v1.ListNodeVnetsResponseData, error)
+	GetVnetConfig(ctx context.Context, nodeId string, vnetId string) (*v1.GetNodeVnetConfigResponseData, error)
+	AckVnetConfig(ctx context.Context, nodeId string, req *v1.AckNodeVnetConfigRequest) (*v1.AckNodeVnetConfigResponseData, error)
+	WatchVnets(ctx context.Context, nodeId string, fromRevision int64, send func(event *v1.NodeVnetEvent) error) error
+	Enroll(ctx context.Context, req *v1.EnrollNodeRequest) (*v1.EnrollNodeResponseData, error)
+	Heartbeat(ctx context.Context, nodeId string, req *v1.NodeHeartbeatRequest) (*v1.NodeHeartbeatResponseData, error)
+	ListNodes(ctx context.Context) (*[]model.Node, error)
+	CreateBootstrapToken(ctx context.Context, req *v1.CreateBootstrapTokenRequest) (*v1.CreateBootstrapTokenResponseData, error)
+	SetDraining(ctx context.Context, nodeId string, draining bool) error
+	RemoveNode(ctx context.Context, nodeId string) error
+}
+
+func NewNodeService(
+	service *Service,
+	conf *viper.Viper,
+	vnetRepository repository.VnetRepository,
+	vnetEventService VnetEventService,
+	nodeRepository repository.NodeRepository,
+) NodeService {
+	tokenTTL := conf.GetDuration("node.bootstrap_token_ttl")
+	if tokenTTL <= 0 {
+		tokenTTL = defaultBootstrapTokenTTL
+	}
+	return &nodeService{
+		Service:           service,
+		keyring:           newNodeKeyring(conf, nodeRepository),
+		bootstrapTokenTTL: tokenTTL,
+		vnetRepository:    vnetRepository,
+		vnetEventService:  vnetEventService,
+		nodeRepository:    nodeRepository,
+	}
+}
+
+type nodeService struct {
+	*Service
+	keyring           *nodeKeyring
+	bootstrapTokenTTL time.Duration
+	vnetRepository    repository.VnetRepository
+	vnetEventService  VnetEventService
+	nodeRepository    repository.NodeRepository
+}
+
+func (s *nodeService) Authenticate(ctx context.Context, nodeId string, key string) error {
+	if nodeId == "" || key == "" {
+		return v1.ErrUnauthorized
+	}
+	ok, err := s.keyring.Verify(ctx, nodeId, key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return v1.ErrUnauthorized
+	}
+	return nil
+}
+
+func (s *nodeService) ListVnets(ctx context.Context, nodeId string) (*v1.ListNodeVnetsResponseData, error) {
+	// 先读取事件版本号再读取列表，之后发生的变更都能通过订阅重放，不会遗漏
+	revision, err := s.vnetEventService.GetLatestRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vnets, err := s.vnetRepository.GetVnetsByNodeId(ctx, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]v1.NodeVnetSummary, 0, len(*vnets))
+	for _, vnet := range *vnets {
+		items = append(items, v1.NodeVnetSummary{
+			VnetId:     vnet.VnetId,
+			Enabled:    vnet.Enabled,
+			Revision:   vnet.Revision,
+			NeedUpdate: vnet.NeedUpdate,
+		})
+	}
+	return &v1.ListNodeVnetsResponseData{Vnets: items, Revision: revision}, nil
+}
+
+func (s *nodeService) GetVnetConfig(ctx context.Context, nodeId string, vnetId string) (*v1.GetNodeVnetConfigResponseData, error) {
+	vnet, err := getAssignedVnet(ctx, s.vnetRepository, nodeId, vnetId)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.GetNodeVnetConfigResponseData{
+		Config: vnetToNodeConfig(vnet),
+	}, nil
+}
+
+func (s *nodeService) AckVnetConfig(ctx context.Context, nodeId string, req *v1.AckNodeVnetConfigRequest) (*v1.AckNodeVnetConfigResponseData, error) {
+	vnet, err := getAssignedVnet(ctx, s.vnetRepository, nodeId, req.VnetId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Revision > vnet.Revision {
+		return nil, v1.ErrBadRequest
+	}
+
+	// 只有确认的是最新版本时才清除 NeedUpdate，确认旧版本不影响状态
+	needUpdate := vnet.NeedUpdate
+	if req.Revision == vnet.Revision {
+		cleared, err := s.vnetRepository.AckVnetRevision(ctx, vnet.VnetId, req.Revision)
+		if err != nil {
+			return nil, err
+		}
+		// 确认期间配置又被修改时 cleared 为 false，保持待更新状态
+		needUpdate = !cleared
+	}
+
+	return &v1.AckNodeVnetConfigResponseData{
+		VnetId:     vnet.VnetId,
+		Revision:   vnet.Revision,
+		NeedUpdate: needUpdate,
+	}, nil
+}
+
+// WatchVnets 推送 fromRevision 之后的变更事件，直到连接断开
+func (s *nodeService) WatchVnets(ctx context.Context, nodeId string, fromRevision int64, send func(event *v1.NodeVnetEvent) error) error {
+	if fromRevision < 0 {
+		return v1.ErrBadRequest
+	}
+	return s.vnetEventService.Watch(ctx, nodeId, fromRevision, send)
+}
+
+// Enroll 使用一次性引导令牌注册节点，生成节点ID与密钥
+func (s *nodeService) Enroll(ctx context.Context, req *v1.EnrollNodeRequest) (*v1.EnrollNodeResponseData, error) {
+	id, err := s.sid.GenString()
+	if err != nil {
+		return nil, err
+	}
+	key, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	node := &model.Node{
+		NodeId:     "node_" + id,
+		KeyHash:    hashToken(key),
+		Region:     req.Region,
+		PublicAddr: req.PublicAddr,
+		Capacity:   req.Capacity,
+		Version:    req.Version,
+		Status:     model.NodeStatusOffline,
+	}
+
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		token, err := s.nodeRepository.ConsumeBootstrapToken(ctx, hashToken(req.BootstrapToken), node.NodeId, now)
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			return v1.ErrUnauthorized
+		}
+		if node.Region == "" {
+			node.Region = token.Region
+		}
+		return s.nodeRepository.CreateNode(ctx, node)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.EnrollNodeResponseData{
+		NodeId:     node.NodeId,
+		NodeKey:    key,
+		SigningKey: s.keyring.SigningKey(node.NodeId),
+	}, nil
+}
+
+// Heartbeat 记录节点状态与负载并标记为在线，配置文件中的静态节点首次心跳时自动登记
+func (s *nodeService) Heartbeat(ctx context.Context, nodeId string, req *v1.NodeHeartbeatRequest) (*v1.NodeHeartbeatResponseData, error) {
+	var wgPublicKey string
+	if req.WgPublicKey != "" {
+		key, err := wgkey.ParsePublicKey(req.WgPublicKey)
+		if err != nil {
+			return nil, v1.ErrBadRequest
+		}
+		wgPublicKey = key
+	}
+	now := time.Now()
+	node := &model.Node{
+		NodeId:           nodeId,
+		PublicAddr:       req.PublicAddr,
+		WgPublicKey:      wgPublicKey,
+		Capacity:         req.Capacity,
+		Version:          req.Version,
+		Status:           model.NodeStatusOnline,
+		LastHeartbeat:    &now,
+		ClientsConnected: req.ClientsConnected,
+		CpuUsage:         req.CpuUsage,
+		MemUsage:         req.MemUsage,
+		RxBps:            req.RxBps,
+		TxBps:            req.TxBps,
+	}
+	found, err := s.nodeRepository.UpdateHeartbeat(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if !s.keyring.IsStatic(nodeId) {
+			return nil, v1.ErrNotFound
+		}
+		if err := s.nodeRepository.CreateNode(ctx, node); err != nil {
+			return nil, err
+		}
+	}
+
+	current, err := s.nodeRepository.GetNodeByNodeId(ctx, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.NodeHeartbeatResponseData{
+		Draining:   current != nil && current.Draining,
+		ServerTime: now.Unix(),
+		SigningKey: s.keyring.SigningKey(nodeId),
+	}, nil
+}
+
+func (s *nodeService) ListNodes(ctx context.Context) (*[]model.Node, error) {
+	return s.nodeRepository.ListNodes(ctx)
+}
+
+// CreateBootstrapToken 生成一次性引导令牌，服务端只保存哈希
+func (s *nodeService) CreateBootstrapToken(ctx context.Context, req *v1.CreateBootstrapTokenRequest) (*v1.CreateBootstrapTokenResponseData, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.bootstrapTokenTTL)
+	err = s.nodeRepository.CreateBootstrapToken(ctx, &model.NodeBootstrapToken{
+		TokenHash: hashToken(token),
+		Region:    req.Region,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.CreateBootstrapTokenResponseData{
+		Token:     token,
+		ExpiresAt: expiresAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+// SetDraining 设置节点下线状态，下线的节点继续服务已分配的虚拟网络，但不再承接新的虚拟网络
+func (s *nodeService) SetDraining(ctx context.Context, nodeId string, draining bool) error {
+	found, err := s.nodeRepository.SetDraining(ctx, nodeId, draining)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return v1.ErrNotFound
+	}
+	return nil
+}
+
+// RemoveNode 移除注册的节点并解除其虚拟网络分配，节点密钥随之失效
+func (s *nodeService) RemoveNode(ctx context.Context, nodeId string) error {
+	if s.keyring.IsStatic(nodeId) {
+		return v1.ErrNodeStatic
+	}
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		found, err := s.nodeRepository.DeleteNode(ctx, nodeId)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return v1.ErrNotFound
+		}
+		_, err = s.vnetRepository.ClearNodeAssignment(ctx, nodeId)
+		return err
+	})
+}
+
+// nodeKeyring 节点密钥来源：配置文件 node.keys 中的静态节点，以及通过引导令牌注册的节点
+// 注册节点只保存密钥的哈希；挑战与会话凭证使用由控制面密钥与节点ID派生的签名密钥
+type nodeKeyring struct {
+	static         map[string]string
+	signingSecret  []byte
+	nodeRepository repository.NodeRepository
+}
+
+func newNodeKeyring(conf *viper.Viper, nodeRepository repository.NodeRepository) *nodeKeyring {
+	secret := conf.GetString("node.signing_secret")
+	if secret == "" {
+		secret = conf.GetString("security.jwt.key")
+	}
+	return &nodeKeyring{
+		static:         conf.GetStringMapString("node.keys"),
+		signingSecret:  []byte(secret),
+		nodeRepository: nodeRepository,
+	}
+}
+
+// Verify 校验节点密钥，节点不存在或已被移除时返回 false
+func (k *nodeKeyring) Verify(ctx context.Context, nodeId string, key string) (bool, error) {
+	if expected, ok := k.static[nodeId]; ok {
+		return subtle.ConstantTimeCompare([]byte(expected), []byte(key)) == 1, nil
+	}
+	node, err := k.nodeRepository.GetNodeByNodeId(ctx, nodeId)
+	if err != nil {
+		return false, err
+	}
+	if node == nil || node.KeyHash == "" {
+		return false, nil
+	}
+	return subtle.ConstantTimeCompare([]byte(node.KeyHash), []byte(hashToken(key))) == 1, nil
+}
+
+// Exists 判断节点是否为静态节点或仍处于注册状态
+func (k *nodeKeyring) Exists(ctx context.Context, nodeId string) (bool, error) {
+	if _, ok := k.static[nodeId]; ok {
+		return true, nil
+	}
+	node, err := k.nodeRepository.GetNodeByNodeId(ctx, nodeId)
+	if err != nil {
+		return false, err
+	}
+	return node != nil && node.KeyHash != "", nil
+}
+
+// SigningKey 返回节点的挑战与会话凭证签名密钥
+func (k *nodeKeyring) SigningKey(nodeId string) string {
+	return jwt.NodeSigningKey(k.signingSecret, nodeId)
+}
+
+func (k *nodeKeyring) IsStatic(nodeId string) bool {
+	_, ok := k.static[nodeId]
+	return ok
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// getAssignedVnet 获取虚拟网络并校验其分配给了该节点，尚未分配节点的虚拟网络对所有节点都不可见
+func getAssignedVnet(ctx context.Context, vnetRepository repository.VnetRepository, nodeId string, vnetId string) (*model.Vnet, error) {
+	vnet, err := vnetRepository.GetVnetByVnetId(ctx, vnetId)
+	if err != nil {
+		return nil, err
+	}
+	if vnet == nil || vnet.VnetId == "" {
+		return nil, v1.ErrNotFound
+	}
+	if nodeId == "" || vnet.NodeId != nodeId {
+		return nil, v1.ErrForbidden
+	}
+	return vnet, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"time"
+)
+
+// notificationListLimit 通知列表最多返回的条数
+const notificationListLimit = 50
+
+// NotificationService 站内通知，由系统任务写入，用户查看后标记为已读
+type NotificationService interface {
+	GetNotifications(ctx context.Context, userId string) (*v1.GetNotificationsResponseData, error)
+	MarkAllRead(ctx context.Context, userId string) error
+}
+
+func NewNotificationService(
+	service *Service,
+	notificationRepository repository.NotificationRepository,
+) NotificationService {
+	return &notificationService{
+		Service:                service,
+		notificationRepository: notificationRepository,
+	}
+}
+
+type notificationService struct {
+	*Service
+	notificationRepository repository.NotificationRepository
+}
+
+// GetNotifications 获取最近的通知与未读数量
+func (s *notificationService) GetNotifications(ctx context.Context, userId string) (*v1.GetNotificationsResponseData, error) {
+	notifications, err := s.notificationRepository.GetNotifications(ctx, userId, notificationListLimit)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.notificationRepository.CountUnread(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	data := &v1.GetNotificationsResponseData{
+		Notifications: make([]v1.NotificationItem, 0, len(*notifications)),
+		Unread:        unread,
+	}
+	for i := range *notifications {
+		data.Notifications = append(data.Notifications, toNotificationItem(&(*notifications)[i]))
+	}
+	return data, nil
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userId string) error {
+	return s.notificationRepository.MarkAllRead(ctx, userId, time.Now())
+}
+
+func toNotificationItem(notification *model.Notification) v1.NotificationItem {
+	item := v1.NotificationItem{
+		Id:        notification.ID,
+		Type:      notification.Type,
+		Title:     notification.Title,
+		Content:   notification.Content,
+		CreatedAt: notification.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if notification.ReadAt != nil {
+		item.ReadAt = notification.ReadAt.Format("2006-01-02 15:04:05")
+	}
+	return item
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"hyacinth-backend/pkg/payment"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// defaultOrderTTL 未配置 payment.order_ttl 时订单等待付款的时长
+const defaultOrderTTL = 30 * time.Minute
+
+// OrderService 购买套餐的订单与支付
+// 下单时向支付渠道发起支付并返回支付地址；渠道回调通知付款后订单标记为已支付，
+// 随后履约使套餐生效并标记为已履约。回调可能重复或乱序到达，每笔订单的套餐只会生效一次
+type OrderService interface {
+	CreateOrder(ctx context.Context, userId string, orgId string, req *v1.PurchasePackageRequest) (*v1.PurchasePackageResponseData, error)
+	PreviewOrder(ctx context.Context, userId string, orgId string, req *v1.PurchasePackageRequest) (*v1.PreviewPurchaseResponseData, error)
+	GetOrders(ctx context.Context, userId string) (*v1.GetOrdersResponseData, error)
+	GetOrder(ctx context.Context, userId string, orderId string) (*v1.OrderItem, error)
+	CancelOrder(ctx context.Context, userId string, orderId string) error
+	HandleNotification(ctx context.Context, provider string, header http.Header, body []byte) error
+	CompleteMockPayment(ctx context.Context, orderId string) (*v1.OrderItem, error)
+}
+
+func NewOrderService(
+	service *Service,
+	conf *viper.Viper,
+	orderRepository repository.OrderRepository,
+	userRepository repository.UserRepository,
+	organizationRepository repository.OrganizationRepository,
+	vnetRepository repository.VnetRepository,
+	userService UserService,
+	organizationService OrganizationService,
+	planService PlanService,
+	paymentProvider payment.PaymentProvider,
+) OrderService {
+	orderTTL := conf.GetDuration("payment.order_ttl")
+	if orderTTL <= 0 {
+		orderTTL = defaultOrderTTL
+	}
+	return &orderService{
+		Service:                service,
+		orderTTL:               orderTTL,
+		orderRepository:        orderRepository,
+		userRepository:         userRepository,
+		organizationRepository: organizationRepository,
+		vnetRepository:         vnetRepository,
+		userService:            userService,
+		organizationService:    organizationService,
+		planService:            planService,
+		paymentProvider:        paymentProvider,
+	}
+}
+
+type orderService struct {
+	*Service
+	orderTTL               time.Duration
+	orderRepository        repository.OrderRepository
+	userRepository         repository.UserRepository
+	organizationRepository repository.OrganizationRepository
+	vnetRepository         repository.VnetRepository
+	userService            UserService
+	organizationService    OrganizationService
+	planService            PlanService
+	paymentProvider        payment.PaymentProvider
+}
+
+// CreateOrder 为用户或组织创建订单并发起支付，orgId 为空表示个人购买
+// 降级时虚拟网络不符合新套餐的限制在下单时拒绝，免得付款后无法生效；未配置支付渠道时不能下单
+func (s *orderService) CreateOrder(ctx context.Context, userId string, orgId string, req *v1.PurchasePackageRequest) (*v1.PurchasePackageResponseData, error) {
+	if !payment.Available(s.paymentProvider) {
+		return nil, v1.ErrPaymentUnavailable
+	}
+	change, err := s.planChange(ctx, userId, orgId, req)
+	if err != nil {
+		return nil, err
+	}
+	plan := change.Plan
+
+	orderId, err := s.sid.GenString()
+	if err != nil {
+		return nil, err
+	}
+	order := &model.Order{
+		OrderId:     "order_" + orderId,
+		UserId:      userId,
+		OrgId:       orgId,
+		PackageType: req.PackageType,
+		Duration:    req.Duration,
+		Amount:      change.Amount,
+		Currency:    model.OrderCurrency,
+		Status:      model.OrderCreated,
+		Provider:    s.paymentProvider.Name(),
+		ExpiresAt:   time.Now().Add(s.orderTTL),
+	}
+	if err := s.orderRepository.CreateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	// 发起支付失败时订单停留在已创建状态，超时后自动取消
+	payUrl, err := s.paymentProvider.CreatePayment(ctx, &payment.Payment{
+		OrderId:   order.OrderId,
+		Subject:   fmt.Sprintf("%s套餐 %d 个月", plan.Name, req.Duration),
+		Amount:    order.Amount,
+		Currency:  order.Currency,
+		ExpiresAt: order.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	order.Status = model.OrderPendingPayment
+	order.PayUrl = payUrl
+	if err := s.orderRepository.UpdateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	return &v1.PurchasePackageResponseData{Order: toOrderItem(order), PayUrl: payUrl}, nil
+}
+
+// PreviewOrder 预览购买套餐的金额与生效时间，校验规则与下单相同
+// 付款前账户可能发生变化，实际生效时按付款时的状态重新计算
+func (s *orderService) PreviewOrder(ctx context.Context, userId string, orgId string, req *v1.PurchasePackageRequest) (*v1.PreviewPurchaseResponseData, error) {
+	change, err := s.planChange(ctx, userId, orgId, req)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.PreviewPurchaseResponseData{
+		Kind:        change.Kind,
+		PackageType: change.Plan.UserGroup,
+		Duration:    change.Months,
+		Amount:      change.Amount,
+		Currency:    model.OrderCurrency,
+		EffectiveAt: change.EffectiveAt.Format("2006-01-02 15:04:05"),
+		ExpiresAt:   change.Expiry.Format("2006-01-02 15:04:05"),
+		CreditDays:  int(change.Credit / (24 * time.Hour)),
+	}, nil
+}
+
+func (s *orderService) GetOrders(ctx context.Context, userId string) (*v1.GetOrdersResponseData, error) {
+	orders, err := s.orderRepository.GetOrdersByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	data := &v1.GetOrdersResponseData{Orders: make([]v1.OrderItem, 0, len(*orders))}
+	for i := range *orders {
+		data.Orders = append(data.Orders, toOrderItem(&(*orders)[i]))
+	}
+	return data, nil
+}
+
+// GetOrder 获取用户下的订单，其他用户的订单视为不存在
+func (s *orderService) GetOrder(ctx context.Context, userId string, orderId string) (*v1.OrderItem, error) {
+	order, err := s.orderRepository.GetOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || order.UserId != userId {
+		return nil, v1.ErrNotFound
+	}
+	item := toOrderItem(order)
+	return &item, nil
+}
+
+// CancelOrder 取消尚未付款的订单
+func (s *orderService) CancelOrder(ctx context.Context, userId string, orderId string) error {
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		order, err := s.orderRepository.GetOrderForUpdate(ctx, orderId)
+		if err != nil {
+			return err
+		}
+		if order == nil || order.UserId != userId {
+			return v1.ErrNotFound
+		}
+		if !model.CanTransitOrder(order.Status, model.OrderCancelled) {
+			return v1.ErrOrderStatus
+		}
+		order.Status = model.OrderCancelled
+		return s.orderRepository.UpdateOrder(ctx, order)
+	})
+}
+
+// HandleNotification 处理支付渠道的回调，签名无效的通知直接拒绝
+// 付款通知重复到达时不会重复生效；付款时套餐变更已不能生效的订单转为待退款，不再重试，
+// 其他原因履约失败时返回错误，订单保持原状态，渠道重试回调时再次处理
+func (s *orderService) HandleNotification(ctx context.Context, provider string, header http.Header, body []byte) error {
+	if !payment.Available(s.paymentProvider) || provider != s.paymentProvider.Name() {
+		return v1.ErrNotFound
+	}
+	n, err := s.paymentProvider.ParseNotification(header, body)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return v1.ErrInvalidPaymentSignature
+		}
+		return v1.ErrBadRequest
+	}
+
+	switch n.Status {
+	case payment.StatusPaid:
+		return s.settle(ctx, n)
+	case payment.StatusRefunded:
+		return s.markRefunded(ctx, n)
+	default:
+		return v1.ErrBadRequest
+	}
+}
+
+// CompleteMockPayment 本地模拟网关的支付页，直接为订单生成一条签名的付款通知并按回调处理
+// 只有使用模拟网关时可用
+func (s *orderService) CompleteMockPayment(ctx context.Context, orderId string) (*v1.OrderItem, error) {
+	gateway, ok := s.paymentProvider.(*payment.MockGateway)
+	if !ok {
+		return nil, v1.ErrNotFound
+	}
+	order, err := s.orderRepository.GetOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, v1.ErrNotFound
+	}
+	if order.Status != model.OrderPendingPayment || time.Now().After(order.ExpiresAt) {
+		return nil, v1.ErrOrderStatus
+	}
+
+	tradeNo, err := s.sid.GenString()
+	if err != nil {
+		return nil, err
+	}
+	header, body, err := gateway.SignNotification(&payment.Notification{
+		OrderId: order.OrderId,
+		TradeNo: "mock_" + tradeNo,
+		Amount:  order.Amount,
+		Status:  payment.StatusPaid,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.HandleNotification(ctx, gateway.Name(), header, body); err != nil {
+		return nil, err
+	}
+	return s.GetOrder(ctx, order.UserId, order.OrderId)
+}
+
+// settle 在同一事务中将订单标记为已支付并使套餐生效，已履约、已退款或待退款的订单视为重复通知
+// 付款时按当前账户状态重新校验套餐变更，例如另一笔降级订单已先生效时，转为待退款而不是停留在已支付反复重试
+func (s *orderService) settle(ctx context.Context, n *payment.Notification) error {
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		order, err := s.orderRepository.GetOrderForUpdate(ctx, n.OrderId)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return v1.ErrNotFound
+		}
+		if n.Amount != order.Amount {
+			s.logger.WithContext(ctx).Warn("payment amount mismatch", zap.String("orderId", order.OrderId), zap.Int64("amount", order.Amount), zap.Int64("paid", n.Amount))
+			return v1.ErrBadRequest
+		}
+		switch order.Status {
+		case model.OrderFulfilled, model.OrderRefunded, model.OrderRefundRequired:
+			return nil
+		case model.OrderPaid:
+			// 已支付但尚未履约的订单，直接重新履约
+		default:
+			if !model.CanTransitOrder(order.Status, model.OrderPaid) {
+				return v1.ErrOrderStatus
+			}
+			now := time.Now()
+			order.Status = model.OrderPaid
+			order.PaidAt = &now
+			order.TradeNo = n.TradeNo
+		}
+
+		req := &v1.PurchasePackageRequest{PackageType: order.PackageType, Duration: order.Duration}
+		_, err = s.planChange(ctx, order.UserId, order.OrgId, req)
+		if err == nil {
+			if order.OrgId != "" {
+				err = s.organizationService.PurchasePackage(ctx, order.OrgId, req)
+			} else {
+				err = s.userService.PurchasePackage(ctx, order.UserId, req)
+			}
+		}
+		if isPlanChangeRejected(err) {
+			s.logger.WithContext(ctx).Warn("order cannot be fulfilled, refund required", zap.String("orderId", order.OrderId), zap.Error(err))
+			order.Status = model.OrderRefundRequired
+			return s.orderRepository.UpdateOrder(ctx, order)
+		}
+		if err != nil {
+			s.logger.WithContext(ctx).Error("order fulfilment error", zap.String("orderId", order.OrderId), zap.Error(err))
+			return err
+		}
+		now := time.Now()
+		order.Status = model.OrderFulfilled
+		order.FulfilledAt = &now
+		return s.orderRepository.UpdateOrder(ctx, order)
+	})
+}
+
+// markRefunded 将订单标记为已退款，已生效的套餐不会自动收回，需要时由管理员处理
+func (s *orderService) markRefunded(ctx context.Context, n *payment.Notification) error {
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		order, err := s.orderRepository.GetOrderForUpdate(ctx, n.OrderId)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return v1.ErrNotFound
+		}
+		if order.Status == model.OrderRefunded {
+			return nil
+		}
+		if !model.CanTransitOrder(order.Status, model.OrderRefunded) {
+			return v1.ErrOrderStatus
+		}
+		order.Status = model.OrderRefunded
+		return s.orderRepository.UpdateOrder(ctx, order)
+	})
+}
+
+// planChange 校验套餐与时长，按个人或组织当前的套餐计算购买的变更
+// 降级或新套餐的限制低于当前套餐时，检查虚拟网络是否符合新套餐的限制
+func (s *orderService) planChange(ctx context.Context, userId string, orgId string, req *v1.PurchasePackageRequest) (*model.PlanChange, error) {
+	catalog, err := s.planService.GetCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan := catalog.Get(req.PackageType)
+	if plan == nil || !plan.CanPurchase(req.Duration) {
+		return nil, v1.ErrBadRequest
+	}
+
+	var (
+		group     int
+		expiry    *time.Time
+		scheduled model.ScheduledPlan
+	)
+	if orgId != "" {
+		org, err := s.organizationRepository.GetOrganization(ctx, orgId)
+		if err != nil {
+			return nil, err
+		}
+		if org == nil {
+			return nil, v1.ErrNotFound
+		}
+		group, expiry, scheduled = org.UserGroup, org.PrivilegeExpiry, org.ScheduledPlan
+	} else {
+		user, err := s.userRepository.GetByID(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+		group, expiry, scheduled = user.UserGroup, user.PrivilegeExpiry, user.ScheduledPlan
+	}
+
+	change, err := model.NewPlanChange(catalog, group, expiry, scheduled, plan, req.Duration, time.Now())
+	if err != nil {
+		return nil, planChangeError(err)
+	}
+	if change.Kind == model.PlanChangeDowngrade || isLowerLimit(catalog, group, plan) {
+		if err := s.checkDowngrade(ctx, userId, orgId, plan); err != nil {
+			return nil, err
+		}
+	}
+	return change, nil
+}
+
+// checkDowngrade 检查个人或组织的虚拟网络设置的在线人数与运行中的数量是否超过新套餐的限制
+func (s *orderService) checkDowngrade(ctx context.Context, userId string, orgId string, plan *model.Plan) error {
+	var vnets []model.Vnet
+	if orgId != "" {
+		orgVnets, err := s.vnetRepository.GetVnetsByOrgIds(ctx, []string{orgId})
+		if err != nil {
+			return err
+		}
+		vnets = *orgVnets
+	} else {
+		userVnets, err := s.vnetRepository.GetVnetByUserId(ctx, userId)
+		if err != nil {
+			return err
+		}
+		// 组织的虚拟网络按组织的套餐计算
+		for _, vnet := range *userVnets {
+			if vnet.OrgId == "" {
+				vnets = append(vnets, vnet)
+			}
+		}
+	}
+
+	running := 0
+	for _, vnet := range vnets {
+		if vnet.ClientsLimit > plan.ClientsPerVnet {
+			return v1.ErrVnetClientsLimitExceeded
+		}
+		if vnet.Enabled {
+			running++
+		}
+	}
+	if running > plan.VnetLimit {
+		return v1.ErrVnetLimitExceeded
+	}
+	return nil
+}
+
+// isPlanChangeRejected 套餐变更是否因账户状态或套餐目录不再允许而被拒绝，重试也不会成功
+func isPlanChangeRejected(err error) bool {
+	for _, rejected := range []error{v1.ErrBadRequest, v1.ErrNotFound, v1.ErrCannotDowngrade, v1.ErrVnetLimitExceeded, v1.ErrVnetClientsLimitExceeded} {
+		if errors.Is(err, rejected) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLowerLimit 新套餐的在线人数或虚拟网络数量限制是否低于用户组当前套餐的限制，用户组已不在目录中时按免费套餐比较
+func isLowerLimit(catalog *model.PlanCatalog, group int, plan *model.Plan) bool {
+	current := catalog.Get(group)
+	if current == nil {
+		current = catalog.Free()
+	}
+	return plan.ClientsPerVnet < current.ClientsPerVnet || plan.VnetLimit < current.VnetLimit
+}
+
+func toOrderItem(order *model.Order) v1.OrderItem {
+	item := v1.OrderItem{
+		OrderId:     order.OrderId,
+		OrgId:       order.OrgId,
+		PackageType: order.PackageType,
+		Duration:    order.Duration,
+		Amount:      order.Amount,
+		Currency:    order.Currency,
+		Status:      order.Status,
+		ExpiresAt:   order.ExpiresAt.Format("2006-01-02 15:04:05"),
+		CreatedAt:   order.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if order.PaidAt != nil {
+		item.PaidAt = order.PaidAt.Format("2006-01-02 15:04:05")
+	}
+	if order.FulfilledAt != nil {
+		item.FulfilledAt = order.FulfilledAt.Format("2006-01-02 15:04:05")
+	}
+	return item
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// OrganizationService 组织及其成员
+// 组织持有用户组与流量池，组织的虚拟网络按组织的权益计算限制，流量从组织扣减
+// 成员只能添加或修改比自己角色低的成员；成员可以随时退出，所有者除外
+type OrganizationService interface {
+	Authorize(ctx context.Context, orgId string, userId string, role string) (*model.Organization, string, error)
+	CreateOrganization(ctx context.Context, userId string, req *v1.CreateOrganizationRequest) (*v1.OrganizationItem, error)
+	GetOrganizations(ctx context.Context, userId string) (*v1.GetOrganizationsResponseData, error)
+	GetOrganization(ctx context.Context, org *model.Organization, role string) (*v1.OrganizationItem, error)
+	GetMembers(ctx context.Context, orgId string) (*v1.GetOrganizationMembersResponseData, error)
+	SetMember(ctx context.Context, org *model.Organization, actorId string, actorRole string, req *v1.SetOrganizationMemberRequest) error
+	RemoveMember(ctx context.Context, org *model.Organization, actorId string, actorRole string, userId string) error
+	PurchasePackage(ctx context.Context, orgId string, req *v1.PurchasePackageRequest) error
+}
+
+func NewOrganizationService(
+	service *Service,
+	userRepository repository.UserRepository,
+	organizationRepository repository.OrganizationRepository,
+	vnetRepository repository.VnetRepository,
+	vnetService VnetService,
+	planService PlanService,
+) OrganizationService {
+	return &organizationService{
+		Service:                service,
+		userRepository:         userRepository,
+		organizationRepository: organizationRepository,
+		vnetRepository:         vnetRepository,
+		vnetService:            vnetService,
+		planService:            planService,
+	}
+}
+
+type organizationService struct {
+	*Service
+	userRepository         repository.UserRepository
+	organizationRepository repository.OrganizationRepository
+	vnetRepository         repository.VnetRepository
+	vnetService            VnetService
+	planService            PlanService
+}
+
+// Authorize 校验用户在组织中至少拥有 role 角色，返回组织与用户的实际角色
+func (s *organizationService) Authorize(ctx context.Context, orgId string, userId string, role string) (*model.Organization, string, error) {
+	org, err := s.organizationRepository.GetOrganization(ctx, orgId)
+	if err != nil {
+		return nil, "", err
+	}
+	if org == nil {
+		return nil, "", v1.ErrNotFound
+	}
+	member, err := s.organizationRepository.GetMember(ctx, orgId, userId)
+	if err != nil {
+		return nil, "", err
+	}
+	if member == nil || model.OrgRoleRank(member.Role) < model.OrgRoleRank(role) {
+		return nil, "", v1.ErrForbidden
+	}
+	return org, member.Role, nil
+}
+
+// CreateOrganization 创建组织，创建者成为所有者
+func (s *organizationService) CreateOrganization(ctx context.Context, userId string, req *v1.CreateOrganizationRequest) (*v1.OrganizationItem, error) {
+	orgId, err := s.sid.GenString()
+	if err != nil {
+		return nil, err
+	}
+	org := &model.Organization{
+		OrgId:     "org_" + orgId,
+		Name:      req.Name,
+		UserGroup: model.FreeUserGroup,
+	}
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.organizationRepository.CreateOrganization(ctx, org); err != nil {
+			return err
+		}
+		return s.organizationRepository.SaveMember(ctx, &model.OrganizationMember{
+			OrgId:  org.OrgId,
+			UserId: userId,
+			Role:   model.OrgRoleOwner,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrganization(ctx, org, model.OrgRoleOwner)
+}
+
+// GetOrganizations 获取用户加入的全部组织
+func (s *organizationService) GetOrganizations(ctx context.Context, userId string) (*v1.GetOrganizationsResponseData, error) {
+	memberships, err := s.organizationRepository.GetMembershipsByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[string]string, len(*memberships))
+	orgIds := make([]string, 0, len(*memberships))
+	for _, membership := range *memberships {
+		roles[membership.OrgId] = membership.Role
+		orgIds = append(orgIds, membership.OrgId)
+	}
+	orgs, err := s.organizationRepository.GetOrganizationsByOrgIds(ctx, orgIds)
+	if err != nil {
+		return nil, err
+	}
+	data := &v1.GetOrganizationsResponseData{Organizations: make([]v1.OrganizationItem, 0, len(*orgs))}
+	for i := range *orgs {
+		org := &(*orgs)[i]
+		item, err := s.GetOrganization(ctx, org, roles[org.OrgId])
+		if err != nil {
+			return nil, err
+		}
+		data.Organizations = append(data.Organizations, *item)
+	}
+	return data, nil
+}
+
+// GetOrganization 获取组织的权益与虚拟网络数量
+func (s *organizationService) GetOrganization(ctx context.Context, org *model.Organization, role string) (*v1.OrganizationItem, error) {
+	running, err := s.vnetRepository.GetRunningVnetCountByOrgId(ctx, org.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := s.planService.GetCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan := catalog.Effective(org)
+	item := &v1.OrganizationItem{
+		OrgId:                  org.OrgId,
+		Name:                   org.Name,
+		Role:                   role,
+		UserGroup:              org.UserGroup,
+		UserGroupName:          catalog.GroupName(org.UserGroup),
+		AvailableTraffic:       model.FormatTraffic(org.RemainingTraffic),
+		CurrentCount:           running,
+		MaxLimit:               plan.VnetLimit,
+		MaxClientsLimitPerVNet: plan.ClientsPerVnet,
+		CreatedAt:              org.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if org.PrivilegeExpiry != nil {
+		expiry := org.PrivilegeExpiry.Format("2006-01-02 15:04:05")
+		item.PrivilegeExpiry = &expiry
+	}
+	return item, nil
+}
+
+func (s *organizationService) GetMembers(ctx context.Context, orgId string) (*v1.GetOrganizationMembersResponseData, error) {
+	members, err := s.organizationRepository.GetMembers(ctx, orgId)
+	if err != nil {
+		return nil, err
+	}
+	data := &v1.GetOrganizationMembersResponseData{Members: make([]v1.OrganizationMemberItem, 0, len(*members))}
+	for _, member := range *members {
+		item := v1.OrganizationMemberItem{
+			UserId:    member.UserId,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if item.Username, err = lookupUsername(ctx, s.userRepository, member.UserId); err != nil {
+			return nil, err
+		}
+		data.Members = append(data.Members, item)
+	}
+	return data, nil
+}
+
+// SetMember 按用户名或邮箱添加成员，用户已是成员时修改其角色
+func (s *organizationService) SetMember(ctx context.Context, org *model.Organization, actorId string, actorRole string, req *v1.SetOrganizationMemberRequest) error {
+	if req.Role != model.OrgRoleMember && req.Role != model.OrgRoleAdmin {
+		return v1.ErrBadRequest
+	}
+	if model.OrgRoleRank(req.Role) >= model.OrgRoleRank(actorRole) {
+		return v1.ErrForbidden
+	}
+	var user *model.User
+	var err error
+	if strings.Contains(req.Account, "@") {
+		user, err = s.userRepository.GetByEmail(ctx, req.Account)
+	} else {
+		user, err = s.userRepository.GetByUsername(ctx, req.Account)
+	}
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return v1.ErrNotFound
+	}
+	if user.UserId == actorId {
+		return v1.ErrBadRequest
+	}
+
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		member, err := s.organizationRepository.GetMember(ctx, org.OrgId, user.UserId)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			member = &model.OrganizationMember{OrgId: org.OrgId, UserId: user.UserId}
+		} else if model.OrgRoleRank(member.Role) >= model.OrgRoleRank(actorRole) {
+			return v1.ErrForbidden
+		}
+		member.Role = req.Role
+		return s.organizationRepository.SaveMember(ctx, member)
+	})
+}
+
+// RemoveMember 移除成员，成员也可以移除自己以退出组织，所有者不能退出
+func (s *organizationService) RemoveMember(ctx context.Context, org *model.Organization, actorId string, actorRole string, userId string) error {
+	member, err := s.organizationRepository.GetMember(ctx, org.OrgId, userId)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return v1.ErrNotFound
+	}
+	if member.Role == model.OrgRoleOwner {
+		return v1.ErrForbidden
+	}
+	if userId != actorId && model.OrgRoleRank(member.Role) >= model.OrgRoleRank(actorRole) {
+		return v1.ErrForbidden
+	}
+	_, err = s.organizationRepository.DeleteMember(ctx, org.OrgId, userId)
+	return err
+}
+
+// PurchasePackage 使套餐生效于组织，规则与个人相同，由订单付款后履约时调用
+func (s *organizationService) PurchasePackage(ctx context.Context, orgId string, req *v1.PurchasePackageRequest) error {
+	catalog, err := s.planService.GetCatalog(ctx)
+	if err != nil {
+		return err
+	}
+	plan := catalog.Get(req.PackageType)
+	if req.Duration <= 0 || plan == nil || plan.UserGroup == model.FreeUserGroup {
+		return v1.ErrBadRequest
+	}
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		org, err := s.organizationRepository.GetOrganizationForUpdate(ctx, orgId)
+		if err != nil {
+			return err
+		}
+		if org == nil {
+			return v1.ErrNotFound
+		}
+		change, err := model.NewPlanChange(catalog, org.UserGroup, org.PrivilegeExpiry, org.ScheduledPlan, plan, req.Duration, time.Now())
+		if err != nil {
+			return planChangeError(err)
+		}
+		org.ApplyPlanChange(change)
+		return s.organizationRepository.UpdateOrganization(ctx, org)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 充值后恢复因流量耗尽而停用的虚拟网络，失败时仅记录日志，不影响本次购买
+	if err := s.vnetService.SyncOrgTrafficSuspension(ctx, orgId); err != nil {
+		s.logger.WithContext(ctx).Error("vnetService.SyncOrgTrafficSuspension error", zap.String("orgId", orgId), zap.Error(err))
+	}
+	return nil
+}
+
+// getSubscriber 获取虚拟网络的权益来源及其当前生效的套餐：组织的虚拟网络为组织，个人虚拟网络为用户本人
+func getSubscriber(ctx context.Context, userRepository repository.UserRepository, organizationRepository repository.OrganizationRepository, planService PlanService, userId string, orgId string) (*model.Entitlement, error) {
+	catalog, err := planService.GetCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if orgId != "" {
+		org, err := organizationRepository.GetOrganization(ctx, orgId)
+		if err != nil {
+			return nil, err
+		}
+		if org == nil {
+			return nil, v1.ErrNotFound
+		}
+		return catalog.Entitle(org), nil
+	}
+	user, err := userRepository.GetByID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.Entitle(user), nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// defaultPlanCacheTTL 未配置 plan.cache_ttl 时套餐目录的缓存时长
+const defaultPlanCacheTTL = time.Minute
+
+// PlanService 套餐目录
+// 套餐的价格、可购买时长、额度与功能保存在数据库中，修改后在缓存过期时生效，无需发布新版本
+type PlanService interface {
+	GetPlans(ctx context.Context) (*v1.GetPlansResponseData, error)
+	GetCatalog(ctx context.Context) (*model.PlanCatalog, error)
+}
+
+func NewPlanService(
+	service *Service,
+	conf *viper.Viper,
+	planRepository repository.PlanRepository,
+) PlanService {
+	cacheTTL := conf.GetDuration("plan.cache_ttl")
+	if cacheTTL <= 0 {
+		cacheTTL = defaultPlanCacheTTL
+	}
+	return &planService{
+		Service:        service,
+		cacheTTL:       cacheTTL,
+		planRepository: planRepository,
+	}
+}
+
+type planService struct {
+	*Service
+	cacheTTL       time.Duration
+	planRepository repository.PlanRepository
+
+	mu       sync.Mutex
+	catalog  *model.PlanCatalog
+	loadedAt time.Time
+}
+
+// GetPlans 获取商店页展示的套餐：免费套餐与可购买的套餐
+func (s *planService) GetPlans(ctx context.Context) (*v1.GetPlansResponseData, error) {
+	catalog, err := s.GetCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data := &v1.GetPlansResponseData{Plans: make([]v1.PlanItem, 0, len(catalog.Plans()))}
+	for _, plan := range catalog.Plans() {
+		if plan.UserGroup != model.FreeUserGroup && !plan.IsPurchasable() {
+			continue
+		}
+		data.Plans = append(data.Plans, toPlanItem(&plan))
+	}
+	return data, nil
+}
+
+// GetCatalog 获取套餐目录，缓存过期后重新从数据库加载；加载失败时返回错误，不使用过期的缓存
+func (s *planService) GetCatalog(ctx context.Context) (*model.PlanCatalog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.catalog != nil && time.Since(s.loadedAt) < s.cacheTTL {
+		return s.catalog, nil
+	}
+	plans, err := s.planRepository.GetPlans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.catalog = model.NewPlanCatalog(*plans)
+	s.loadedAt = time.Now()
+	return s.catalog, nil
+}
+
+// planChangeError 将计算套餐变更的错误转换为接口错误
+func planChangeError(err error) error {
+	if errors.Is(err, model.ErrDowngradeScheduled) {
+		return v1.ErrCannotDowngrade
+	}
+	return err
+}
+
+func toPlanItem(plan *model.Plan) v1.PlanItem {
+	item := v1.PlanItem{
+		UserGroup:       plan.UserGroup,
+		Name:            plan.Name,
+		Description:     plan.Description,
+		MonthlyPrice:    plan.MonthlyPrice,
+		Currency:        model.OrderCurrency,
+		Durations:       plan.Durations,
+		MonthlyTraffic:  plan.MonthlyTraffic,
+		VnetLimit:       plan.VnetLimit,
+		ClientsPerVnet:  plan.ClientsPerVnet,
+		AclRulesPerVnet: plan.AclRulesPerVnet,
+		Features:        plan.Features,
+		Purchasable:     plan.IsPurchasable(),
+	}
+	if item.Durations == nil {
+		item.Durations = []int{}
+	}
+	if item.Features == nil {
+		item.Features = []string{}
+	}
+	return item
+}
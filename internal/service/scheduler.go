@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// defaultSchedulerMaxMoves 未配置 scheduler.max_moves 时每轮调度最多重新分配的虚拟网络数量
+const defaultSchedulerMaxMoves = 20
+
+// 虚拟网络需要重新分配的原因，数值越小越优先处理
+const (
+	scheduleFailover   = iota // 所在节点离线或已被移除
+	scheduleUnassigned        // 尚未分配节点
+	scheduleRebalance         // 所在节点超出容量或不在优先区域
+)
+
+// SchedulerService 将已启用的虚拟网络分配到中继节点
+type SchedulerService interface {
+	Reconcile(ctx context.Context) (int, error)
+}
+
+func NewSchedulerService(
+	service *Service,
+	conf *viper.Viper,
+	vnetRepository repository.VnetRepository,
+	nodeRepository repository.NodeRepository,
+	vnetEventService VnetEventService,
+) SchedulerService {
+	maxMoves := conf.GetInt("scheduler.max_moves")
+	if maxMoves <= 0 {
+		maxMoves = defaultSchedulerMaxMoves
+	}
+	return &schedulerService{
+		Service:          service,
+		maxMoves:         maxMoves,
+		vnetRepository:   vnetRepository,
+		nodeRepository:   nodeRepository,
+		vnetEventService: vnetEventService,
+	}
+}
+
+type schedulerService struct {
+	*Service
+	maxMoves         int
+	vnetRepository   repository.VnetRepository
+	nodeRepository   repository.NodeRepository
+	vnetEventService VnetEventService
+}
+
+// nodeLoad 调度过程中节点的负载
+// 负载按已分配虚拟网络的客户端数量上限之和计算，保证所有虚拟网络满员时节点也不超过容量
+type nodeLoad struct {
+	node *model.Node
+	load int
+}
+
+// schedulable 节点在线且未下线时才能承接新的虚拟网络
+func (n *nodeLoad) schedulable() bool {
+	return n.node.Status == model.NodeStatusOnline && !n.node.Draining
+}
+
+// fits 判断节点能否再承载 clients 个客户端，容量为 0 表示不限
+func (n *nodeLoad) fits(clients int) bool {
+	return n.node.Capacity == 0 || n.load+clients <= n.node.Capacity
+}
+
+func (n *nodeLoad) overloaded() bool {
+	return n.node.Capacity > 0 && n.load > n.node.Capacity
+}
+
+// utilization 节点的容量占用比例，不限容量的节点视为 0
+func (n *nodeLoad) utilization() float64 {
+	if n.node.Capacity == 0 {
+		return 0
+	}
+	return float64(n.load) / float64(n.node.Capacity)
+}
+
+type vnetPlacement struct {
+	vnet   *model.Vnet
+	reason int
+}
+
+// Reconcile 执行一轮调度，返回重新分配的虚拟网络数量
+// 依次处理所在节点失效的、尚未分配的和需要再平衡的虚拟网络，每轮最多迁移 scheduler.max_moves 个，避免大量虚拟网络同时切换节点
+// 分配变更时先通知原节点移除，再通知新节点加载
+func (s *schedulerService) Reconcile(ctx context.Context) (int, error) {
+	nodes, err := s.nodeRepository.ListNodes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	vnets, err := s.vnetRepository.GetEnabledVnets(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	loads := make(map[string]*nodeLoad, len(*nodes))
+	for i := range *nodes {
+		loads[(*nodes)[i].NodeId] = &nodeLoad{node: &(*nodes)[i]}
+	}
+	for _, vnet := range *vnets {
+		if n, ok := loads[vnet.NodeId]; ok {
+			n.load += vnet.ClientsLimit
+		}
+	}
+
+	placements := make([][]vnetPlacement, scheduleRebalance+1)
+	for i := range *vnets {
+		vnet := &(*vnets)[i]
+		current, assigned := loads[vnet.NodeId]
+		switch {
+		case vnet.NodeId == "":
+			placements[scheduleUnassigned] = append(placements[scheduleUnassigned], vnetPlacement{vnet, scheduleUnassigned})
+		case !assigned || current.node.Status != model.NodeStatusOnline:
+			placements[scheduleFailover] = append(placements[scheduleFailover], vnetPlacement{vnet, scheduleFailover})
+		case current.node.Draining:
+			// 下线中的节点继续服务已分配的虚拟网络
+		case current.overloaded() || (vnet.Region != "" && current.node.Region != vnet.Region):
+			placements[scheduleRebalance] = append(placements[scheduleRebalance], vnetPlacement{vnet, scheduleRebalance})
+		}
+	}
+
+	moved := 0
+	defer func() {
+		if moved > 0 {
+			s.vnetEventService.Notify()
+		}
+	}()
+	for _, group := range placements {
+		for _, p := range group {
+			if moved >= s.maxMoves {
+				s.logger.Warn("scheduler move limit reached", zap.Int("maxMoves", s.maxMoves))
+				return moved, nil
+			}
+			source := loads[p.vnet.NodeId]
+			target := pickNode(loads, p)
+			if target == nil {
+				if p.reason != scheduleRebalance {
+					s.logger.Warn("no node available for vnet", zap.String("vnetId", p.vnet.VnetId), zap.String("region", p.vnet.Region))
+				}
+				continue
+			}
+			ok, err := s.assign(ctx, p.vnet, target.node.NodeId)
+			if err != nil {
+				return moved, err
+			}
+			if !ok {
+				continue
+			}
+			if source != nil {
+				source.load -= p.vnet.ClientsLimit
+			}
+			target.load += p.vnet.ClientsLimit
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// pickNode 为虚拟网络选择目标节点，优先选择指定区域内占用比例最低的节点，返回 nil 表示没有合适的节点
+func pickNode(loads map[string]*nodeLoad, p vnetPlacement) *nodeLoad {
+	var best, bestInRegion *nodeLoad
+	for nodeId, n := range loads {
+		if nodeId == p.vnet.NodeId || !n.schedulable() || !n.fits(p.vnet.ClientsLimit) {
+			continue
+		}
+		if better(n, best) {
+			best = n
+		}
+		if p.vnet.Region != "" && n.node.Region == p.vnet.Region && better(n, bestInRegion) {
+			bestInRegion = n
+		}
+	}
+	if p.reason != scheduleRebalance {
+		if bestInRegion != nil {
+			return bestInRegion
+		}
+		return best
+	}
+
+	// 再平衡只在能改善现状时进行：超出容量时迁出到有空余的节点，不在优先区域时只迁入优先区域
+	// 之前的迁移可能已经消除了超载
+	source := loads[p.vnet.NodeId]
+	switch {
+	case source.overloaded():
+		if bestInRegion != nil {
+			return bestInRegion
+		}
+		return best
+	case p.vnet.Region != "" && source.node.Region != p.vnet.Region:
+		return bestInRegion
+	}
+	return nil
+}
+
+// better 比较两个候选节点，占用比例低者优先，其次负载低者，最后按节点ID保证结果稳定
+func better(n *nodeLoad, than *nodeLoad) bool {
+	if than == nil {
+		return true
+	}
+	if n.utilization() != than.utilization() {
+		return n.utilization() < than.utilization()
+	}
+	if n.load != than.load {
+		return n.load < than.load
+	}
+	return n.node.NodeId < than.node.NodeId
+}
+
+// assign 将虚拟网络分配到节点并记录变更事件
+// 锁定记录后确认虚拟网络仍处于读取时的状态，已被删除、停用或重新分配时跳过，返回是否完成分配
+func (s *schedulerService) assign(ctx context.Context, vnet *model.Vnet, nodeId string) (bool, error) {
+	assigned := false
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		current, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnet.VnetId)
+		if errors.Is(err, v1.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !current.Enabled || current.NodeId != vnet.NodeId {
+			return nil
+		}
+
+		previous := *current
+		current.NodeId = nodeId
+		current.NeedUpdate = true
+		current.Revision++
+		previous.Revision = current.Revision
+		if err := s.vnetRepository.UpdateVnet(ctx, current); err != nil {
+			return err
+		}
+		// 尚未分配的虚拟网络不会下发给任何节点，只需通知原节点移除
+		if previous.NodeId != "" {
+			if err := s.vnetEventService.Record(ctx, model.VnetEventDelete, &previous); err != nil {
+				return err
+			}
+		}
+		if err := s.vnetEventService.Record(ctx, model.VnetEventCreate, current); err != nil {
+			return err
+		}
+		assigned = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if assigned {
+		s.logger.Info("vnet assigned", zap.String("vnetId", vnet.VnetId), zap.String("from", vnet.NodeId), zap.String("to", nodeId))
+	}
+	return assigned, nil
+}
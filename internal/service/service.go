@@ -1,6 +1,8 @@
 package service
 
 import (
+	"time"
+
 	"hyacinth-backend/internal/repository"
 	"hyacinth-backend/pkg/jwt"
 	"hyacinth-backend/pkg/log"
@@ -27,3 +29,12 @@ func NewService(
 		tm:     tm,
 	}
 }
+
+// expiryAfter 根据时长（秒）计算过期时间，0 表示永不过期
+func expiryAfter(duration int64, now time.Time) *time.Time {
+	if duration <= 0 {
+		return nil
+	}
+	expiresAt := now.Add(time.Duration(duration) * time.Second)
+	return &expiresAt
+}
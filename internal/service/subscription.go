@@ -0,0 +1,460 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// trafficResetBatch 每轮最多处理的到期账户数量，其余留给下一轮
+const trafficResetBatch = 200
+
+// defaultExpiryGrace 未配置 subscription.expiry_grace 时特权到期后保留原用户组的宽限期
+const defaultExpiryGrace = 72 * time.Hour
+
+// SubscriptionService 付费套餐的计费周期与到期处理
+// 每月在计费周期起点对应的时刻将用户与组织的剩余流量重置为套餐的月流量，并记录重置历史；
+// 特权到期超过宽限期后降级为免费套餐，并将虚拟网络调整到免费套餐的限制以内；
+// 已购买降级的账户在特权到期时直接切换到购买的套餐，不经过宽限期
+type SubscriptionService interface {
+	ResetMonthlyTraffic(ctx context.Context) error
+	ExpirePrivileges(ctx context.Context) error
+	ApplyScheduledPlans(ctx context.Context) error
+}
+
+func NewSubscriptionService(
+	service *Service,
+	conf *viper.Viper,
+	userRepository repository.UserRepository,
+	organizationRepository repository.OrganizationRepository,
+	trafficResetRepository repository.TrafficResetRepository,
+	notificationRepository repository.NotificationRepository,
+	planService PlanService,
+	vnetService VnetService,
+	vnetEventService VnetEventService,
+) SubscriptionService {
+	// 宽限期可以配置为 0，到期后立即降级
+	expiryGrace := defaultExpiryGrace
+	if conf.IsSet("subscription.expiry_grace") {
+		expiryGrace = conf.GetDuration("subscription.expiry_grace")
+	}
+	return &subscriptionService{
+		Service:                service,
+		expiryGrace:            expiryGrace,
+		userRepository:         userRepository,
+		organizationRepository: organizationRepository,
+		trafficResetRepository: trafficResetRepository,
+		notificationRepository: notificationRepository,
+		planService:            planService,
+		vnetService:            vnetService,
+		vnetEventService:       vnetEventService,
+	}
+}
+
+type subscriptionService struct {
+	*Service
+	expiryGrace            time.Duration
+	userRepository         repository.UserRepository
+	organizationRepository repository.OrganizationRepository
+	trafficResetRepository repository.TrafficResetRepository
+	notificationRepository repository.NotificationRepository
+	planService            PlanService
+	vnetService            VnetService
+	vnetEventService       VnetEventService
+}
+
+// ResetMonthlyTraffic 重置到了计费周期的用户与组织的流量，单个账户失败时记录日志并继续处理其他账户
+// 重复执行或任务延迟跨越了多个周期时，每个账户的每个周期最多重置一次
+func (s *subscriptionService) ResetMonthlyTraffic(ctx context.Context) error {
+	now := time.Now()
+	users, err := s.userRepository.GetTrafficResetDue(ctx, now, trafficResetBatch)
+	if err != nil {
+		return err
+	}
+	for _, user := range *users {
+		if err := s.resetUserTraffic(ctx, user.UserId, now); err != nil {
+			s.logger.WithContext(ctx).Error("resetUserTraffic error", zap.String("userId", user.UserId), zap.Error(err))
+		}
+	}
+
+	orgs, err := s.organizationRepository.GetTrafficResetDue(ctx, now, trafficResetBatch)
+	if err != nil {
+		return err
+	}
+	for _, org := range *orgs {
+		if err := s.resetOrgTraffic(ctx, org.OrgId, now); err != nil {
+			s.logger.WithContext(ctx).Error("resetOrgTraffic error", zap.String("orgId", org.OrgId), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// resetUserTraffic 锁定用户记录后重新判断并重置流量，重置后恢复因流量耗尽而停用的个人虚拟网络
+func (s *subscriptionService) resetUserTraffic(ctx context.Context, userId string, now time.Time) error {
+	refilled := false
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByIDForUpdate(ctx, userId)
+		if err != nil {
+			return err
+		}
+		if !user.IsTrafficResetDue(user.PrivilegeExpiry, now) {
+			return nil
+		}
+		refilled, err = s.resetTraffic(ctx, model.TrafficResetOwnerUser, user.UserId, user, &user.BillingCycle, &user.RemainingTraffic, now)
+		if err != nil {
+			return err
+		}
+		return s.userRepository.Update(ctx, user)
+	})
+	if err != nil {
+		return err
+	}
+	if refilled {
+		if err := s.vnetService.SyncTrafficSuspension(ctx, userId); err != nil {
+			s.logger.WithContext(ctx).Error("vnetService.SyncTrafficSuspension error", zap.String("userId", userId), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// resetOrgTraffic 锁定组织记录后重新判断并重置流量，重置后恢复因流量耗尽而停用的组织虚拟网络
+func (s *subscriptionService) resetOrgTraffic(ctx context.Context, orgId string, now time.Time) error {
+	refilled := false
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		org, err := s.organizationRepository.GetOrganizationForUpdate(ctx, orgId)
+		if err != nil {
+			return err
+		}
+		if org == nil || !org.IsTrafficResetDue(org.PrivilegeExpiry, now) {
+			return nil
+		}
+		refilled, err = s.resetTraffic(ctx, model.TrafficResetOwnerOrg, org.OrgId, org, &org.BillingCycle, &org.RemainingTraffic, now)
+		if err != nil {
+			return err
+		}
+		return s.organizationRepository.UpdateOrganization(ctx, org)
+	})
+	if err != nil {
+		return err
+	}
+	if refilled {
+		if err := s.vnetService.SyncOrgTrafficSuspension(ctx, orgId); err != nil {
+			s.logger.WithContext(ctx).Error("vnetService.SyncOrgTrafficSuspension error", zap.String("orgId", orgId), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// resetTraffic 推进下一次重置时间，并在本周期还没有重置记录时将剩余流量重置为套餐的月流量，需在已锁定账户记录的事务中调用
+// 用户组已从套餐目录中移除或套餐没有月流量时只推进时间，不清空剩余流量
+func (s *subscriptionService) resetTraffic(ctx context.Context, ownerType string, ownerId string, subscriber model.Subscriber, cycle *model.BillingCycle, remaining *int64, now time.Time) (bool, error) {
+	catalog, err := s.planService.GetCatalog(ctx)
+	if err != nil {
+		return false, err
+	}
+	cycleStart := cycle.AdvanceTrafficReset(now)
+	plan := catalog.Get(subscriber.GetUserGroup())
+	if plan == nil || plan.MonthlyTraffic <= 0 {
+		s.logger.WithContext(ctx).Warn("traffic reset skipped, plan has no monthly traffic", zap.String("ownerId", ownerId), zap.Int("userGroup", subscriber.GetUserGroup()))
+		return false, nil
+	}
+	created, err := s.trafficResetRepository.CreateReset(ctx, &model.TrafficReset{
+		OwnerType:       ownerType,
+		OwnerId:         ownerId,
+		CycleStart:      cycleStart,
+		UserGroup:       subscriber.GetUserGroup(),
+		PreviousTraffic: *remaining,
+		Traffic:         plan.MonthlyTraffic,
+	})
+	if err != nil || !created {
+		return false, err
+	}
+	*remaining = plan.MonthlyTraffic
+	return true, nil
+}
+
+// ExpirePrivileges 将特权到期超过宽限期的用户与组织降级为免费套餐，单个账户失败时记录日志并继续处理其他账户
+// 宽限期内账户的权益已按免费套餐计算，但保留原用户组，续费后原有的虚拟网络不受影响
+func (s *subscriptionService) ExpirePrivileges(ctx context.Context) error {
+	now := time.Now()
+	before := now.Add(-s.expiryGrace)
+	users, err := s.userRepository.GetPrivilegeExpired(ctx, before, trafficResetBatch)
+	if err != nil {
+		return err
+	}
+	for _, user := range *users {
+		if err := s.expireUser(ctx, user.UserId, before); err != nil {
+			s.logger.WithContext(ctx).Error("expireUser error", zap.String("userId", user.UserId), zap.Error(err))
+		}
+	}
+
+	orgs, err := s.organizationRepository.GetPrivilegeExpired(ctx, before, trafficResetBatch)
+	if err != nil {
+		return err
+	}
+	for _, org := range *orgs {
+		if err := s.expireOrg(ctx, org.OrgId, before); err != nil {
+			s.logger.WithContext(ctx).Error("expireOrg error", zap.String("orgId", org.OrgId), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// expireUser 锁定用户记录后重新判断，降级并调整个人虚拟网络，同一事务中通知用户
+func (s *subscriptionService) expireUser(ctx context.Context, userId string, before time.Time) error {
+	changed := false
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByIDForUpdate(ctx, userId)
+		if err != nil {
+			return err
+		}
+		if !isPrivilegeExpiredBefore(user.UserGroup, user.PrivilegeExpiry, before) || user.HasScheduledPlan() {
+			return nil
+		}
+		catalog, err := s.planService.GetCatalog(ctx)
+		if err != nil {
+			return err
+		}
+		previous := catalog.GroupName(user.UserGroup)
+		user.UserGroup = model.FreeUserGroup
+		user.BillingCycle = model.BillingCycle{}
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+		result, err := s.vnetService.ApplyPlanLimits(ctx, userId, "", catalog.Free())
+		if err != nil {
+			return err
+		}
+		changed = result.Changed()
+
+		content := fmt.Sprintf("您的%s套餐已于 %s 到期，已降级为%s。", previous, user.PrivilegeExpiry.Format("2006-01-02 15:04"), catalog.Free().Name)
+		return s.notificationRepository.CreateNotifications(ctx, []model.Notification{{
+			UserId:  userId,
+			Type:    model.NotificationPrivilegeExpired,
+			Title:   "套餐已到期",
+			Content: content + describePlanLimitResult(result, catalog.Free()),
+		}})
+	})
+	if err != nil {
+		return err
+	}
+	if changed {
+		s.vnetEventService.Notify()
+	}
+	return nil
+}
+
+// expireOrg 锁定组织记录后重新判断，降级并调整组织的虚拟网络，同一事务中通知组织的所有者与管理员
+func (s *subscriptionService) expireOrg(ctx context.Context, orgId string, before time.Time) error {
+	changed := false
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		org, err := s.organizationRepository.GetOrganizationForUpdate(ctx, orgId)
+		if err != nil {
+			return err
+		}
+		if org == nil || !isPrivilegeExpiredBefore(org.UserGroup, org.PrivilegeExpiry, before) || org.HasScheduledPlan() {
+			return nil
+		}
+		catalog, err := s.planService.GetCatalog(ctx)
+		if err != nil {
+			return err
+		}
+		previous := catalog.GroupName(org.UserGroup)
+		org.UserGroup = model.FreeUserGroup
+		org.BillingCycle = model.BillingCycle{}
+		if err := s.organizationRepository.UpdateOrganization(ctx, org); err != nil {
+			return err
+		}
+		result, err := s.vnetService.ApplyPlanLimits(ctx, "", orgId, catalog.Free())
+		if err != nil {
+			return err
+		}
+		changed = result.Changed()
+
+		content := fmt.Sprintf("组织「%s」的%s套餐已于 %s 到期，已降级为%s。", org.Name, previous, org.PrivilegeExpiry.Format("2006-01-02 15:04"), catalog.Free().Name) +
+			describePlanLimitResult(result, catalog.Free())
+		return s.notifyOrgAdmins(ctx, orgId, model.NotificationPrivilegeExpired, "组织套餐已到期", content)
+	})
+	if err != nil {
+		return err
+	}
+	if changed {
+		s.vnetEventService.Notify()
+	}
+	return nil
+}
+
+// ApplyScheduledPlans 将特权已到期、有待生效的降级的用户与组织切换到购买的套餐，单个账户失败时记录日志并继续处理其他账户
+// 切换后从原特权到期时间开始新的计费周期，并将虚拟网络调整到新套餐的限制以内
+func (s *subscriptionService) ApplyScheduledPlans(ctx context.Context) error {
+	now := time.Now()
+	users, err := s.userRepository.GetScheduledPlanDue(ctx, now, trafficResetBatch)
+	if err != nil {
+		return err
+	}
+	for _, user := range *users {
+		if err := s.applyUserScheduledPlan(ctx, user.UserId, now); err != nil {
+			s.logger.WithContext(ctx).Error("applyUserScheduledPlan error", zap.String("userId", user.UserId), zap.Error(err))
+		}
+	}
+
+	orgs, err := s.organizationRepository.GetScheduledPlanDue(ctx, now, trafficResetBatch)
+	if err != nil {
+		return err
+	}
+	for _, org := range *orgs {
+		if err := s.applyOrgScheduledPlan(ctx, org.OrgId, now); err != nil {
+			s.logger.WithContext(ctx).Error("applyOrgScheduledPlan error", zap.String("orgId", org.OrgId), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// applyUserScheduledPlan 锁定用户记录后重新判断，切换到购买的套餐并调整个人虚拟网络，同一事务中通知用户
+func (s *subscriptionService) applyUserScheduledPlan(ctx context.Context, userId string, now time.Time) error {
+	changed := false
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByIDForUpdate(ctx, userId)
+		if err != nil {
+			return err
+		}
+		if !user.IsScheduledPlanDue(user.PrivilegeExpiry, now) {
+			return nil
+		}
+		catalog, err := s.planService.GetCatalog(ctx)
+		if err != nil {
+			return err
+		}
+		previous, expired := catalog.GroupName(user.UserGroup), *user.PrivilegeExpiry
+		plan := scheduledPlan(catalog, user.ScheduledGroup)
+		user.ApplyScheduledPlan(plan.MonthlyTraffic)
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+		result, err := s.vnetService.ApplyPlanLimits(ctx, userId, "", plan)
+		if err != nil {
+			return err
+		}
+		changed = result.Changed()
+
+		content := fmt.Sprintf("您的%s套餐已于 %s 到期，已按您的购买变更为%s，有效期至 %s。", previous, expired.Format("2006-01-02 15:04"), plan.Name, user.PrivilegeExpiry.Format("2006-01-02 15:04"))
+		return s.notificationRepository.CreateNotifications(ctx, []model.Notification{{
+			UserId:  userId,
+			Type:    model.NotificationPlanChanged,
+			Title:   "套餐已变更",
+			Content: content + describePlanLimitResult(result, plan),
+		}})
+	})
+	if err != nil {
+		return err
+	}
+	if changed {
+		s.vnetEventService.Notify()
+	}
+	return nil
+}
+
+// applyOrgScheduledPlan 锁定组织记录后重新判断，切换到购买的套餐并调整组织的虚拟网络，同一事务中通知组织的所有者与管理员
+func (s *subscriptionService) applyOrgScheduledPlan(ctx context.Context, orgId string, now time.Time) error {
+	changed := false
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		org, err := s.organizationRepository.GetOrganizationForUpdate(ctx, orgId)
+		if err != nil {
+			return err
+		}
+		if org == nil || !org.IsScheduledPlanDue(org.PrivilegeExpiry, now) {
+			return nil
+		}
+		catalog, err := s.planService.GetCatalog(ctx)
+		if err != nil {
+			return err
+		}
+		previous, expired := catalog.GroupName(org.UserGroup), *org.PrivilegeExpiry
+		plan := scheduledPlan(catalog, org.ScheduledGroup)
+		org.ApplyScheduledPlan(plan.MonthlyTraffic)
+		if err := s.organizationRepository.UpdateOrganization(ctx, org); err != nil {
+			return err
+		}
+		result, err := s.vnetService.ApplyPlanLimits(ctx, "", orgId, plan)
+		if err != nil {
+			return err
+		}
+		changed = result.Changed()
+
+		content := fmt.Sprintf("组织「%s」的%s套餐已于 %s 到期，已按购买变更为%s，有效期至 %s。", org.Name, previous, expired.Format("2006-01-02 15:04"), plan.Name, org.PrivilegeExpiry.Format("2006-01-02 15:04")) +
+			describePlanLimitResult(result, plan)
+		return s.notifyOrgAdmins(ctx, orgId, model.NotificationPlanChanged, "组织套餐已变更", content)
+	})
+	if err != nil {
+		return err
+	}
+	if changed {
+		s.vnetEventService.Notify()
+	}
+	return nil
+}
+
+// notifyOrgAdmins 通知组织的所有者与管理员
+func (s *subscriptionService) notifyOrgAdmins(ctx context.Context, orgId string, typ string, title string, content string) error {
+	members, err := s.organizationRepository.GetMembers(ctx, orgId)
+	if err != nil {
+		return err
+	}
+	var notifications []model.Notification
+	for _, member := range *members {
+		if model.OrgRoleRank(member.Role) < model.OrgRoleRank(model.OrgRoleAdmin) {
+			continue
+		}
+		notifications = append(notifications, model.Notification{
+			UserId:  member.UserId,
+			Type:    typ,
+			Title:   title,
+			Content: content,
+		})
+	}
+	return s.notificationRepository.CreateNotifications(ctx, notifications)
+}
+
+// scheduledPlan 待生效的降级对应的套餐，用户组已从套餐目录中移除时按免费套餐的额度计算
+func scheduledPlan(catalog *model.PlanCatalog, group int) *model.Plan {
+	if plan := catalog.Get(group); plan != nil {
+		return plan
+	}
+	return catalog.Free()
+}
+
+// isPrivilegeExpiredBefore 付费用户组的特权是否在 before 之前到期
+func isPrivilegeExpiredBefore(group int, expiry *time.Time, before time.Time) bool {
+	return group != model.FreeUserGroup && expiry != nil && !expiry.After(before)
+}
+
+// describePlanLimitResult 通知中说明被停用与调低在线人数上限的虚拟网络
+func describePlanLimitResult(result *PlanLimitResult, plan *model.Plan) string {
+	var b strings.Builder
+	if len(result.Disabled) > 0 {
+		fmt.Fprintf(&b, "%s最多同时运行 %d 个虚拟网络，以下虚拟网络已停用：%s。", plan.Name, plan.VnetLimit, vnetNames(result.Disabled))
+	}
+	if len(result.Clamped) > 0 {
+		fmt.Fprintf(&b, "以下虚拟网络的在线人数上限已调整为 %d：%s。", plan.ClientsPerVnet, vnetNames(result.Clamped))
+	}
+	return b.String()
+}
+
+// vnetNames 虚拟网络的备注，没有备注时使用虚拟网络ID
+func vnetNames(vnets []model.Vnet) string {
+	names := make([]string, 0, len(vnets))
+	for _, vnet := range vnets {
+		if vnet.Comment != "" {
+			names = append(names, vnet.Comment)
+		} else {
+			names = append(names, vnet.VnetId)
+		}
+	}
+	return strings.Join(names, "、")
+}
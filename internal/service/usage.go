@@ -3,28 +3,58 @@ package service
 import (
 	"context"
 	v1 "hyacinth-backend/api/v1"
-
-	// "hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/model"
 	"hyacinth-backend/internal/repository"
+	"math"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxUsageRecordsPerBatch 单个批次最多包含的流量记录数
+	maxUsageRecordsPerBatch = 1000
+	// maxUsageWindow 单个批次统计窗口的最大跨度
+	maxUsageWindow = 24 * time.Hour
+	// maxUsageClockSkew 允许的节点时钟偏差
+	maxUsageClockSkew = 5 * time.Minute
+	// maxUsageBytesPerSecond 单条记录每个方向的流量上限按 100 Gbit/s 的线速折算，超出视为节点上报错误
+	// 按最大窗口与最大记录数计算，累加后仍远小于 int64 的范围
+	maxUsageBytesPerSecond = 100 * 1000 * 1000 * 1000 / 8
 )
 
 type UsageService interface {
 	GetUsage(ctx context.Context, req *v1.GetUsageRequest) (*v1.GetUsageResponseData, error)
+	ReportUsage(ctx context.Context, nodeId string, req *v1.ReportUsageRequest) (*v1.ReportUsageResponseData, error)
 }
 
 func NewUsageService(
 	service *Service,
 	usageRepository repository.UsageRepository,
+	vnetRepository repository.VnetRepository,
+	userRepository repository.UserRepository,
+	organizationRepository repository.OrganizationRepository,
+	vnetService VnetService,
 ) UsageService {
 	return &usageService{
-		Service:         service,
-		usageRepository: usageRepository,
+		Service:                service,
+		usageRepository:        usageRepository,
+		vnetRepository:         vnetRepository,
+		userRepository:         userRepository,
+		organizationRepository: organizationRepository,
+		vnetService:            vnetService,
 	}
 }
 
 type usageService struct {
 	*Service
-	usageRepository repository.UsageRepository
+	usageRepository        repository.UsageRepository
+	vnetRepository         repository.VnetRepository
+	userRepository         repository.UserRepository
+	organizationRepository repository.OrganizationRepository
+	vnetService            VnetService
 }
 
 func (s *usageService) GetUsage(ctx context.Context, req *v1.GetUsageRequest) (*v1.GetUsageResponseData, error) {
@@ -36,3 +66,206 @@ func (s *usageService) GetUsage(ctx context.Context, req *v1.GetUsageRequest) (*
 		Usages: *usages,
 	}, nil
 }
+
+// ReportUsage 写入节点上报的流量，同一节点的同一批次只会入库一次
+func (s *usageService) ReportUsage(ctx context.Context, nodeId string, req *v1.ReportUsageRequest) (*v1.ReportUsageResponseData, error) {
+	if err := validateUsageReport(req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.usageRepository.GetUsageBatch(ctx, nodeId, req.BatchId)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return duplicateUsageBatch(existing), nil
+	}
+
+	vnetIds := make([]string, 0, len(req.Records))
+	seen := make(map[string]bool)
+	for _, record := range req.Records {
+		if !seen[record.VnetId] {
+			seen[record.VnetId] = true
+			vnetIds = append(vnetIds, record.VnetId)
+		}
+	}
+	vnets, err := s.vnetRepository.GetVnetsByVnetIds(ctx, vnetIds)
+	if err != nil {
+		return nil, err
+	}
+	vnetMap := make(map[string]*model.Vnet, len(*vnets))
+	for i := range *vnets {
+		vnetMap[(*vnets)[i].VnetId] = &(*vnets)[i]
+	}
+
+	// 流量记录的时间取统计窗口的开始时间，使其落入实际发生的统计区间
+	windowStart := time.Unix(req.WindowStart, 0)
+	usages := make([]model.Usage, 0, len(req.Records))
+	rejected := []v1.RejectedUsageRecord{}
+	userBytes := make(map[string]int64)
+	// 组织的虚拟网络从组织的流量池扣减
+	orgBytes := make(map[string]int64)
+	var totalBytes int64
+	for i, record := range req.Records {
+		vnet, ok := vnetMap[record.VnetId]
+		reason := ""
+		switch {
+		case !ok:
+			reason = "vnet not found"
+		case vnet.NodeId != nodeId:
+			reason = "vnet not assigned to this node"
+		case !vnet.Enabled:
+			reason = "vnet disabled"
+		}
+		if reason != "" {
+			rejected = append(rejected, v1.RejectedUsageRecord{Index: i, VnetId: record.VnetId, Reason: reason})
+			continue
+		}
+
+		bytes := record.BytesIn + record.BytesOut
+		if bytes == 0 {
+			continue
+		}
+		// 溢出后的负数会使扣减变为充值，任何累加溢出都拒绝整个批次
+		if totalBytes, ok = addUsageBytes(totalBytes, bytes); !ok {
+			return nil, v1.ErrBadRequest
+		}
+		if vnet.OrgId != "" {
+			if orgBytes[vnet.OrgId], ok = addUsageBytes(orgBytes[vnet.OrgId], bytes); !ok {
+				return nil, v1.ErrBadRequest
+			}
+		} else {
+			if userBytes[vnet.UserId], ok = addUsageBytes(userBytes[vnet.UserId], bytes); !ok {
+				return nil, v1.ErrBadRequest
+			}
+		}
+		usages = append(usages, model.Usage{
+			Model:    gorm.Model{CreatedAt: windowStart},
+			UserId:   vnet.UserId,
+			VnetId:   vnet.VnetId,
+			Usage:    bytes,
+			ClientId: record.ClientId,
+			NodeId:   nodeId,
+			BatchId:  req.BatchId,
+		})
+	}
+
+	batch := &model.UsageBatch{
+		NodeId:      nodeId,
+		BatchId:     req.BatchId,
+		WindowStart: windowStart,
+		WindowEnd:   time.Unix(req.WindowEnd, 0),
+		Accepted:    len(req.Records) - len(rejected),
+		Rejected:    len(rejected),
+		TotalBytes:  totalBytes,
+	}
+	// 按用户ID、组织ID排序扣减，避免并发批次以不同顺序锁定记录而死锁
+	userIds := make([]string, 0, len(userBytes))
+	for userId := range userBytes {
+		userIds = append(userIds, userId)
+	}
+	sort.Strings(userIds)
+	orgIds := make([]string, 0, len(orgBytes))
+	for orgId := range orgBytes {
+		orgIds = append(orgIds, orgId)
+	}
+	sort.Strings(orgIds)
+
+	var exhausted, exhaustedOrgs []string
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		// 先写入批次记录，并发重试的请求会在唯一索引上冲突并整体回滚
+		if err := s.usageRepository.CreateUsageBatch(ctx, batch); err != nil {
+			return err
+		}
+		if err := s.usageRepository.CreateUsages(ctx, usages); err != nil {
+			return err
+		}
+		exhausted = exhausted[:0]
+		for _, userId := range userIds {
+			remaining, err := s.userRepository.DebitTraffic(ctx, userId, userBytes[userId])
+			if err != nil {
+				return err
+			}
+			if remaining <= 0 {
+				exhausted = append(exhausted, userId)
+			}
+		}
+		exhaustedOrgs = exhaustedOrgs[:0]
+		for _, orgId := range orgIds {
+			remaining, err := s.organizationRepository.DebitTraffic(ctx, orgId, orgBytes[orgId])
+			if err != nil {
+				return err
+			}
+			if remaining <= 0 {
+				exhaustedOrgs = append(exhaustedOrgs, orgId)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if existing, getErr := s.usageRepository.GetUsageBatch(ctx, nodeId, req.BatchId); getErr == nil && existing != nil {
+			return duplicateUsageBatch(existing), nil
+		}
+		return nil, err
+	}
+
+	// 流量已入库，停用失败只记录日志，下一批次上报时会再次尝试
+	for _, userId := range exhausted {
+		if err := s.vnetService.SyncTrafficSuspension(ctx, userId); err != nil {
+			s.logger.WithContext(ctx).Error("vnetService.SyncTrafficSuspension error", zap.String("userId", userId), zap.Error(err))
+		}
+	}
+	for _, orgId := range exhaustedOrgs {
+		if err := s.vnetService.SyncOrgTrafficSuspension(ctx, orgId); err != nil {
+			s.logger.WithContext(ctx).Error("vnetService.SyncOrgTrafficSuspension error", zap.String("orgId", orgId), zap.Error(err))
+		}
+	}
+
+	return &v1.ReportUsageResponseData{
+		BatchId:  req.BatchId,
+		Accepted: batch.Accepted,
+		Rejected: rejected,
+	}, nil
+}
+
+func validateUsageReport(req *v1.ReportUsageRequest) error {
+	if req.BatchId == "" || len(req.BatchId) > 64 {
+		return v1.ErrBadRequest
+	}
+	if len(req.Records) == 0 || len(req.Records) > maxUsageRecordsPerBatch {
+		return v1.ErrBadRequest
+	}
+	start := time.Unix(req.WindowStart, 0)
+	end := time.Unix(req.WindowEnd, 0)
+	if req.WindowStart <= 0 || !end.After(start) || end.Sub(start) > maxUsageWindow {
+		return v1.ErrBadRequest
+	}
+	if end.After(time.Now().Add(maxUsageClockSkew)) {
+		return v1.ErrBadRequest
+	}
+	// 每个方向的流量不能超过统计窗口内线速可以传输的量
+	maxBytes := int64(end.Sub(start)/time.Second) * maxUsageBytesPerSecond
+	for _, record := range req.Records {
+		if record.VnetId == "" || record.BytesIn < 0 || record.BytesOut < 0 || record.BytesIn > maxBytes || record.BytesOut > maxBytes {
+			return v1.ErrBadRequest
+		}
+	}
+	return nil
+}
+
+// addUsageBytes 累加非负的流量，溢出时返回 false
+func addUsageBytes(total int64, bytes int64) (int64, bool) {
+	if bytes > math.MaxInt64-total {
+		return total, false
+	}
+	return total + bytes, true
+}
+
+func duplicateUsageBatch(batch *model.UsageBatch) *v1.ReportUsageResponseData {
+	return &v1.ReportUsageResponseData{
+		BatchId:   batch.BatchId,
+		Accepted:  batch.Accepted,
+		Rejected:  []v1.RejectedUsageRecord{},
+		Duplicate: true,
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"hyacinth-backend/internal/repository"
 	"time"
 
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"sync"
 )
@@ -30,9 +31,13 @@ type UserService interface {
 func NewUserService(
 	service *Service,
 	userRepo repository.UserRepository,
+	vnetService VnetService,
+	planService PlanService,
 ) UserService {
 	return &userService{
 		userRepo:      userRepo,
+		vnetService:   vnetService,
+		planService:   planService,
 		Service:       service,
 		registerMutex: sync.Mutex{},
 	}
@@ -40,6 +45,8 @@ func NewUserService(
 
 type userService struct {
 	userRepo      repository.UserRepository
+	vnetService   VnetService
+	planService   PlanService
 	registerMutex sync.Mutex // 确保注册操作的线程安全
 	*Service
 }
@@ -78,7 +85,7 @@ func (s *userService) Register(ctx context.Context, req *v1.RegisterRequest) err
 		Username:         req.Username,
 		Email:            req.Email,
 		Password:         string(hashedPassword),
-		UserGroup:        1,                              // 默认为普通用户
+		UserGroup:        model.FreeUserGroup,            // 默认为普通用户
 		RemainingTraffic: model.DefaultTrafficForNewUser, // 为新用户提供默认初始流量
 	}
 	// Transaction demo
@@ -121,6 +128,10 @@ func (s *userService) GetProfile(ctx context.Context, userId string) (*v1.GetPro
 	if err != nil {
 		return nil, err
 	}
+	catalog, err := s.planService.GetCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	activeTunnels := 0
 
@@ -139,7 +150,7 @@ func (s *userService) GetProfile(ctx context.Context, userId string) (*v1.GetPro
 		Username:         user.Username,
 		Email:            user.Email,
 		UserGroup:        user.UserGroup,
-		UserGroupName:    user.GetUserGroupName(),
+		UserGroupName:    catalog.GroupName(user.UserGroup),
 		PrivilegeExpiry:  privilegeExpiryStr,
 		IsVip:            user.IsVip(),
 		ActiveTunnels:    activeTunnels,
@@ -192,8 +203,10 @@ func (s *userService) ChangePassword(ctx context.Context, userId string, req *v1
 	return nil
 }
 
+// PurchasePackage 使套餐生效于用户，由订单付款后履约时调用，用户购买套餐需经 OrderService 下单支付
+// 升级立即生效并折算剩余价值，降级登记为在当前特权到期时生效，见 model.NewPlanChange
 func (s *userService) PurchasePackage(ctx context.Context, userId string, req *v1.PurchasePackageRequest) error {
-	user, err := s.userRepo.GetByID(ctx, userId)
+	user, err := s.userRepo.GetByIDForUpdate(ctx, userId)
 	if err != nil {
 		return err
 	}
@@ -204,46 +217,30 @@ func (s *userService) PurchasePackage(ctx context.Context, userId string, req *v
 		return v1.ErrBadRequest
 	}
 
-	now := time.Now()
-
-	// 定义不同套餐的流量额度（字节）
-	var monthlyTraffic int64
-	switch req.PackageType {
-	case 2: // 青铜用户 - 50GB/月
-		monthlyTraffic = model.BronzeMonthlyTraffic
-	case 3: // 白银用户 - 200GB/月
-		monthlyTraffic = model.SilverMonthlyTraffic
-	case 4: // 黄金用户 - 无限流量（设置为1TB）
-		monthlyTraffic = model.GoldMonthlyTraffic
-	default:
+	// 只能使付费套餐生效，已下架的套餐仍可为付款在下架前的订单履约
+	catalog, err := s.planService.GetCatalog(ctx)
+	if err != nil {
+		return err
+	}
+	plan := catalog.Get(req.PackageType)
+	if plan == nil || plan.UserGroup == model.FreeUserGroup {
 		return v1.ErrBadRequest
 	}
+	change, err := model.NewPlanChange(catalog, user.UserGroup, user.PrivilegeExpiry, user.ScheduledPlan, plan, duration, time.Now())
+	if err != nil {
+		return planChangeError(err)
+	}
+	user.ApplyPlanChange(change)
 
-	// 计算总流量（购买时长 * 月流量）
-	totalTraffic := monthlyTraffic
-
-	if req.PackageType == user.UserGroup {
-		// 相同用户组，特权时间增加指定月数，流量不变
-		if user.PrivilegeExpiry == nil || user.PrivilegeExpiry.Before(now) {
-			// 如果没有特权或已过期，从当前时间开始计算
-			expiry := now.AddDate(0, duration, 0)
-			user.PrivilegeExpiry = &expiry
-			user.RemainingTraffic = totalTraffic
-		} else {
-			// 如果特权未过期，在现有基础上增加指定月数
-			expiry := user.PrivilegeExpiry.AddDate(0, duration, 0)
-			user.PrivilegeExpiry = &expiry
-			// user.RemainingTraffic += totalTraffic
-		}
-	} else {
-		// 购买不同的用户组（升级或降级），重置为指定时长，设置新的流量额度
-		user.UserGroup = req.PackageType
-		expiry := now.AddDate(0, duration, 0)
-		user.PrivilegeExpiry = &expiry
-		user.RemainingTraffic = totalTraffic
+	if err = s.userRepo.Update(ctx, user); err != nil {
+		return err
 	}
 
-	return s.userRepo.Update(ctx, user)
+	// 充值后恢复因流量耗尽而停用的虚拟网络，失败时仅记录日志，不影响本次购买
+	if err = s.vnetService.SyncTrafficSuspension(ctx, userId); err != nil {
+		s.logger.WithContext(ctx).Error("vnetService.SyncTrafficSuspension error", zap.String("userId", userId), zap.Error(err))
+	}
+	return nil
 }
 
 func (s *userService) GetUserByID(ctx context.Context, userId string) (*model.User, error) {
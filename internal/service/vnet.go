@@ -5,12 +5,16 @@ import (
 	v1 "hyacinth-backend/api/v1"
 	"hyacinth-backend/internal/model"
 	"hyacinth-backend/internal/repository"
+	"hyacinth-backend/pkg/vnetpass"
+	"sort"
 	"sync"
 )
 
 type VnetService interface {
 	GetVnetByUserId(ctx context.Context, id string) (*[]model.Vnet, error)
 	GetVnetByVnetId(ctx context.Context, id string) (*model.Vnet, error)
+	Authorize(ctx context.Context, vnetId string, userId string, role string) (*model.Vnet, string, error)
+	GetSharedVnets(ctx context.Context, userId string) (*[]model.Vnet, map[string]string, error)
 	UpdateVnet(ctx context.Context, req *v1.UpdateVnetRequest) error
 	CreateVnet(ctx context.Context, req *v1.CreateVnetRequest, userId string) error
 	DeleteVnet(ctx context.Context, req *v1.DeleteVnetRequest) error
@@ -20,22 +24,59 @@ type VnetService interface {
 	GetOnlineTunnels(ctx context.Context, userId string) (int, error)
 	GetOnlineDevicesCount(ctx context.Context, userId string) (int, error)
 	GetRunningVnetCount(ctx context.Context, userId string) (int, error)
+	GetOrgRunningVnetCount(ctx context.Context, orgId string) (int, error)
+	GetSubscriber(ctx context.Context, userId string, orgId string) (*model.Entitlement, error)
+	SyncTrafficSuspension(ctx context.Context, userId string) error
+	SyncOrgTrafficSuspension(ctx context.Context, orgId string) error
+	ApplyPlanLimits(ctx context.Context, userId string, orgId string, plan *model.Plan) (*PlanLimitResult, error)
+}
+
+// PlanLimitResult 按套餐限制调整虚拟网络的结果
+type PlanLimitResult struct {
+	Disabled []model.Vnet // 超出数量限制而停用的虚拟网络
+	Clamped  []model.Vnet // 在线人数上限被调低的虚拟网络
+}
+
+// Changed 是否有虚拟网络被修改
+func (r *PlanLimitResult) Changed() bool {
+	return len(r.Disabled) > 0 || len(r.Clamped) > 0
 }
 
 func NewVnetService(
 	service *Service,
 	vnetRepository repository.VnetRepository,
+	userRepository repository.UserRepository,
+	vnetEventService VnetEventService,
+	ipamService IpamService,
+	vnetCollaboratorRepository repository.VnetCollaboratorRepository,
+	organizationRepository repository.OrganizationRepository,
+	vnetRouteRepository repository.VnetRouteRepository,
+	planService PlanService,
 ) VnetService {
 	return &vnetService{
-		Service:        service,
-		vnetRepository: vnetRepository,
+		Service:                    service,
+		vnetRepository:             vnetRepository,
+		userRepository:             userRepository,
+		vnetEventService:           vnetEventService,
+		ipamService:                ipamService,
+		vnetCollaboratorRepository: vnetCollaboratorRepository,
+		organizationRepository:     organizationRepository,
+		vnetRouteRepository:        vnetRouteRepository,
+		planService:                planService,
 	}
 }
 
 type vnetService struct {
 	*Service
-	vnetLock       sync.Mutex
-	vnetRepository repository.VnetRepository
+	vnetLock                   sync.Mutex
+	vnetRepository             repository.VnetRepository
+	userRepository             repository.UserRepository
+	vnetEventService           VnetEventService
+	ipamService                IpamService
+	vnetCollaboratorRepository repository.VnetCollaboratorRepository
+	organizationRepository     repository.OrganizationRepository
+	vnetRouteRepository        repository.VnetRouteRepository
+	planService                PlanService
 }
 
 func (s *vnetService) GetVnetByUserId(ctx context.Context, id string) (*[]model.Vnet, error) {
@@ -46,83 +87,521 @@ func (s *vnetService) GetVnetByVnetId(ctx context.Context, id string) (*model.Vn
 	return s.vnetRepository.GetVnetByVnetId(ctx, id)
 }
 
+// Authorize 校验用户对虚拟网络至少拥有 role 角色，返回虚拟网络与用户的实际角色
+// 虚拟网络不存在时返回 ErrNotFound，无权访问或角色不足时返回 ErrForbidden
+func (s *vnetService) Authorize(ctx context.Context, vnetId string, userId string, role string) (*model.Vnet, string, error) {
+	vnet, err := s.vnetRepository.GetVnetByVnetId(ctx, vnetId)
+	if err != nil {
+		return nil, "", err
+	}
+	if vnet == nil || vnet.VnetId == "" {
+		return nil, "", v1.ErrNotFound
+	}
+	actual, err := s.vnetRole(ctx, vnet, userId)
+	if err != nil {
+		return nil, "", err
+	}
+	if actual == "" || model.VnetRoleRank(actual) < model.VnetRoleRank(role) {
+		return nil, "", v1.ErrForbidden
+	}
+	return vnet, actual, nil
+}
+
+// vnetRole 获取用户在虚拟网络中的角色，无权访问时返回空
+// 个人虚拟网络的所有者为 owner；组织的虚拟网络按组织角色计算，用户同时是协作者时取较高的角色
+func (s *vnetService) vnetRole(ctx context.Context, vnet *model.Vnet, userId string) (string, error) {
+	if vnet.OrgId == "" && vnet.UserId == userId {
+		return model.VnetRoleOwner, nil
+	}
+	role := ""
+	if vnet.OrgId != "" {
+		member, err := s.organizationRepository.GetMember(ctx, vnet.OrgId, userId)
+		if err != nil {
+			return "", err
+		}
+		if member != nil {
+			role = orgVnetRole(member.Role)
+		}
+	}
+	collaborator, err := s.vnetCollaboratorRepository.GetCollaborator(ctx, vnet.VnetId, userId)
+	if err != nil {
+		return "", err
+	}
+	if collaborator != nil && model.VnetRoleRank(collaborator.Role) > model.VnetRoleRank(role) {
+		role = collaborator.Role
+	}
+	return role, nil
+}
+
+// orgVnetRole 组织成员在组织的虚拟网络中的角色：所有者与管理员拥有全部权限，普通成员不能删除
+func orgVnetRole(orgRole string) string {
+	if model.OrgRoleRank(orgRole) >= model.OrgRoleRank(model.OrgRoleAdmin) {
+		return model.VnetRoleOwner
+	}
+	return model.VnetRoleAdmin
+}
+
+// GetSharedVnets 获取用户可以访问但不属于其个人的虚拟网络：所在组织的虚拟网络与其他用户共享的虚拟网络，
+// 以及用户在各虚拟网络中的角色
+func (s *vnetService) GetSharedVnets(ctx context.Context, userId string) (*[]model.Vnet, map[string]string, error) {
+	memberships, err := s.organizationRepository.GetMembershipsByUserId(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	orgRoles := make(map[string]string, len(*memberships))
+	orgIds := make([]string, 0, len(*memberships))
+	for _, membership := range *memberships {
+		orgRoles[membership.OrgId] = membership.Role
+		orgIds = append(orgIds, membership.OrgId)
+	}
+	vnets, err := s.vnetRepository.GetVnetsByOrgIds(ctx, orgIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	roles := make(map[string]string, len(*vnets))
+	for _, vnet := range *vnets {
+		roles[vnet.VnetId] = orgVnetRole(orgRoles[vnet.OrgId])
+	}
+
+	collaborations, err := s.vnetCollaboratorRepository.GetCollaborationsByUserId(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	vnetIds := make([]string, 0, len(*collaborations))
+	for _, collaboration := range *collaborations {
+		role, ok := roles[collaboration.VnetId]
+		if model.VnetRoleRank(collaboration.Role) > model.VnetRoleRank(role) {
+			roles[collaboration.VnetId] = collaboration.Role
+		}
+		if !ok {
+			vnetIds = append(vnetIds, collaboration.VnetId)
+		}
+	}
+	shared, err := s.vnetRepository.GetVnetsByVnetIds(ctx, vnetIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	*vnets = append(*vnets, *shared...)
+	return vnets, roles, nil
+}
+
+// UpdateVnet 修改虚拟网络设置，在事务中加锁读取最新记录后修改，
+// 整行保存时不会覆盖调度器分配的节点、在线数量等并发写入的字段
 func (s *vnetService) UpdateVnet(ctx context.Context, req *v1.UpdateVnetRequest) error {
-	vnet, err := s.vnetRepository.GetVnetByVnetId(ctx, req.VnetId)
+	passwordHash, err := hashVnetPassword(req.Password)
 	if err != nil {
 		return err
 	}
-	vnet.Comment = req.Comment
-	vnet.Enabled = req.Enabled
-	vnet.Token = req.Token
-	vnet.Password = req.Password
-	vnet.IpRange = req.IpRange
-	vnet.EnableDHCP = req.EnableDHCP
-	vnet.ClientsLimit = req.ClientsLimit
-	vnet.NeedUpdate = true
-	if err := s.vnetRepository.UpdateVnet(ctx, vnet); err != nil {
+	s.vnetLock.Lock()
+	defer s.vnetLock.Unlock()
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, req.VnetId)
+		if err != nil {
+			return err
+		}
+		wasEnabled := vnet.Enabled
+		vnet.Comment = req.Comment
+		vnet.Enabled = req.Enabled
+		// WireGuard 虚拟网络按设备公钥接入，不使用令牌与密码
+		if vnet.Type != model.VnetTypeWireGuard {
+			vnet.Token = req.Token
+			// 密码留空时保持不变
+			if passwordHash != "" {
+				vnet.PasswordHash = passwordHash
+			} else if req.RemovePassword {
+				vnet.PasswordHash = ""
+			}
+		}
+		vnet.EnableDHCP = req.EnableDHCP
+		vnet.ClientsLimit = req.ClientsLimit
+		vnet.RequireApproval = req.RequireApproval
+		vnet.Region = req.Region
+		if vnet.Enabled {
+			vnet.SuspendReason = ""
+		}
+		// 网段留空或未变化时保持原网段
+		if req.IpRange == "" || sameIpRange(req.IpRange, vnet.IpRange) {
+			return s.updateWithEvent(ctx, vnet, model.VnetEventUpdate)
+		}
+
+		// 更换网段会使在线客户端的地址失效，只允许在没有客户端在线时进行，并迁移已有租约
+		if wasEnabled && vnet.ClientsOnline > 0 {
+			return v1.ErrIpRangeInUse
+		}
+		// WireGuard 设备的地址写在各自的配置文件中，已登记设备后不能更换网段
+		if vnet.Type == model.VnetTypeWireGuard {
+			leases, err := s.ipamService.GetLeases(ctx, vnet.VnetId)
+			if err != nil {
+				return err
+			}
+			if len(*leases) > 0 {
+				return v1.ErrIpRangeInUse
+			}
+		}
+		ipRange, err := s.ipamService.ResolveIpRange(ctx, vnet.UserId, vnet.OrgId, vnet.VnetId, req.IpRange)
+		if err != nil {
+			return err
+		}
+		if ipRange != vnet.IpRange {
+			// 新网段不能与已批准的子网路由重叠，否则节点无法确定下一跳
+			approved, err := s.vnetRouteRepository.GetRoutes(ctx, vnet.VnetId, model.VnetRouteApproved)
+			if err != nil {
+				return err
+			}
+			for i := range *approved {
+				if err := checkRouteConflict(&model.Vnet{IpRange: ipRange}, &(*approved)[i], nil); err != nil {
+					return err
+				}
+			}
+			if err := s.ipamService.MigrateLeases(ctx, vnet.VnetId, ipRange); err != nil {
+				return err
+			}
+			vnet.IpRange = ipRange
+		}
+		return s.updateWithEvent(ctx, vnet, model.VnetEventUpdate)
+	})
+	if err != nil {
 		return err
 	}
+	s.vnetEventService.Notify()
 	return nil
 }
 
+// CreateVnet 创建虚拟网络，网段经校验后规范化，留空时自动分配并回填到请求中
 func (s *vnetService) CreateVnet(ctx context.Context, req *v1.CreateVnetRequest, userId string) error {
+	vnetType := req.Type
+	if vnetType == "" {
+		vnetType = model.VnetTypeN2N
+	}
+	// WireGuard 虚拟网络按设备公钥接入，不使用令牌与密码
+	if vnetType == model.VnetTypeWireGuard {
+		req.Token = ""
+		req.Password = ""
+	}
+	passwordHash, err := hashVnetPassword(req.Password)
+	if err != nil {
+		return err
+	}
 	s.vnetLock.Lock()
 	defer s.vnetLock.Unlock()
 	vnet := &model.Vnet{
-		VnetId:       req.VnetId,
-		UserId:       userId,
-		Comment:      req.Comment,
-		Enabled:      req.Enabled,
-		Token:        req.Token,
-		Password:     req.Password,
-		IpRange:      req.IpRange,
-		EnableDHCP:   req.EnableDHCP,
-		ClientsLimit: req.ClientsLimit,
-		NeedUpdate:   true,
-	}
-	if err := s.vnetRepository.CreateVnet(ctx, vnet); err != nil {
+		VnetId:          req.VnetId,
+		UserId:          userId,
+		OrgId:           req.OrgId,
+		Type:            vnetType,
+		Comment:         req.Comment,
+		Enabled:         req.Enabled,
+		Token:           req.Token,
+		PasswordHash:    passwordHash,
+		IpRange:         req.IpRange,
+		EnableDHCP:      req.EnableDHCP,
+		ClientsLimit:    req.ClientsLimit,
+		RequireApproval: req.RequireApproval,
+		Region:          req.Region,
+		NeedUpdate:      true,
+		Revision:        1,
+	}
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		ipRange, err := s.ipamService.ResolveIpRange(ctx, userId, vnet.OrgId, vnet.VnetId, req.IpRange)
+		if err != nil {
+			return err
+		}
+		vnet.IpRange = ipRange
+		if err := s.vnetRepository.CreateVnet(ctx, vnet); err != nil {
+			return err
+		}
+		return s.vnetEventService.Record(ctx, model.VnetEventCreate, vnet)
+	})
+	if err != nil {
 		return err
 	}
+	req.IpRange = vnet.IpRange
+	s.vnetEventService.Notify()
 	return nil
 }
 
 func (s *vnetService) DeleteVnet(ctx context.Context, req *v1.DeleteVnetRequest) error {
 	s.vnetLock.Lock()
 	defer s.vnetLock.Unlock()
-	vnet, err := s.vnetRepository.GetVnetByVnetId(ctx, req.VnetID)
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		// 加锁读取，删除事件的配置版本接在并发写入的版本之后
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, req.VnetID)
+		if err != nil {
+			return err
+		}
+		vnet.Revision++
+		if err := s.vnetRepository.DeleteVnet(ctx, vnet.VnetId); err != nil {
+			return err
+		}
+		return s.vnetEventService.Record(ctx, model.VnetEventDelete, vnet)
+	})
 	if err != nil {
 		return err
 	}
-	if err := s.vnetRepository.DeleteVnet(ctx, vnet.VnetId); err != nil {
-		return err
-	}
+	s.vnetEventService.Notify()
 	return nil
 }
 
 func (s *vnetService) EnableVnet(ctx context.Context, req *v1.EnableVnetRequest) error {
-	vnet, err := s.vnetRepository.GetVnetByVnetId(ctx, req.VnetID)
+	return s.updateLocked(ctx, req.VnetID, model.VnetEventEnable, func(vnet *model.Vnet) {
+		vnet.Enabled = true
+		vnet.SuspendReason = ""
+	})
+}
+
+func (s *vnetService) DisableVnet(ctx context.Context, req *v1.DisableVnetRequest) error {
+	return s.updateLocked(ctx, req.VnetID, model.VnetEventDisable, func(vnet *model.Vnet) {
+		vnet.Enabled = false
+		// 用户手动停用后不再随流量充值自动恢复
+		vnet.SuspendReason = ""
+	})
+}
+
+// SyncTrafficSuspension 根据所有者的剩余流量停用或恢复其个人虚拟网络，组织的虚拟网络见 SyncOrgTrafficSuspension
+// 锁定用户记录后再判断，与流量扣减、充值串行执行
+func (s *vnetService) SyncTrafficSuspension(ctx context.Context, userId string) error {
+	return s.syncTrafficSuspension(ctx, func(ctx context.Context) (model.Subscriber, []model.Vnet, error) {
+		user, err := s.userRepository.GetByIDForUpdate(ctx, userId)
+		if err != nil {
+			return nil, nil, err
+		}
+		vnets, err := s.vnetRepository.GetVnetByUserId(ctx, userId)
+		if err != nil {
+			return nil, nil, err
+		}
+		personal := make([]model.Vnet, 0, len(*vnets))
+		for _, vnet := range *vnets {
+			if vnet.OrgId == "" {
+				personal = append(personal, vnet)
+			}
+		}
+		return user, personal, nil
+	})
+}
+
+// SyncOrgTrafficSuspension 根据组织的剩余流量停用或恢复组织的虚拟网络
+// 锁定组织记录后再判断，与流量扣减、充值串行执行
+func (s *vnetService) SyncOrgTrafficSuspension(ctx context.Context, orgId string) error {
+	return s.syncTrafficSuspension(ctx, func(ctx context.Context) (model.Subscriber, []model.Vnet, error) {
+		org, err := s.organizationRepository.GetOrganizationForUpdate(ctx, orgId)
+		if err != nil {
+			return nil, nil, err
+		}
+		if org == nil {
+			return nil, nil, v1.ErrNotFound
+		}
+		vnets, err := s.vnetRepository.GetVnetsByOrgIds(ctx, []string{orgId})
+		if err != nil {
+			return nil, nil, err
+		}
+		return org, *vnets, nil
+	})
+}
+
+// syncTrafficSuspension 流量耗尽时停用全部运行中的虚拟网络并标记原因；
+// 流量充足时恢复因流量耗尽而停用的虚拟网络（不超过数量限制）
+// load 在事务中锁定权益来源并返回其虚拟网络，修改前逐个加锁重新读取，避免整行保存时覆盖并发写入的字段
+func (s *vnetService) syncTrafficSuspension(ctx context.Context, load func(ctx context.Context) (model.Subscriber, []model.Vnet, error)) error {
+	s.vnetLock.Lock()
+	defer s.vnetLock.Unlock()
+	changed := false
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		subscriber, vnets, err := load(ctx)
+		if err != nil {
+			return err
+		}
+		sort.Slice(vnets, func(i, j int) bool { return vnets[i].ID < vnets[j].ID })
+
+		if subscriber.GetRemainingTraffic() <= 0 {
+			for i := range vnets {
+				if !vnets[i].Enabled {
+					continue
+				}
+				vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnets[i].VnetId)
+				if err != nil {
+					return err
+				}
+				vnet.Enabled = false
+				vnet.SuspendReason = model.VnetSuspendTrafficExhausted
+				if err := s.updateWithEvent(ctx, vnet, model.VnetEventDisable); err != nil {
+					return err
+				}
+				changed = true
+			}
+			return nil
+		}
+
+		catalog, err := s.planService.GetCatalog(ctx)
+		if err != nil {
+			return err
+		}
+		running := 0
+		for _, vnet := range vnets {
+			if vnet.Enabled {
+				running++
+			}
+		}
+		limit := catalog.Effective(subscriber).VnetLimit
+		for i := range vnets {
+			if vnets[i].Enabled || vnets[i].SuspendReason != model.VnetSuspendTrafficExhausted {
+				continue
+			}
+			if running >= limit {
+				break
+			}
+			vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnets[i].VnetId)
+			if err != nil {
+				return err
+			}
+			vnet.Enabled = true
+			vnet.SuspendReason = ""
+			if err := s.updateWithEvent(ctx, vnet, model.VnetEventEnable); err != nil {
+				return err
+			}
+			running++
+			changed = true
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	vnet.Enabled = true
-	vnet.NeedUpdate = true
-	if err := s.vnetRepository.UpdateVnet(ctx, vnet); err != nil {
-		return err
+	if changed {
+		s.vnetEventService.Notify()
 	}
 	return nil
 }
 
-func (s *vnetService) DisableVnet(ctx context.Context, req *v1.DisableVnetRequest) error {
-	vnet, err := s.vnetRepository.GetVnetByVnetId(ctx, req.VnetID)
+// ApplyPlanLimits 将个人（orgId 为空）或组织的虚拟网络调整到 plan 的限制以内：
+// 运行中的虚拟网络超出数量限制时按最近使用时间保留，最久未使用的先停用，从未使用过的按创建顺序保留较早的；
+// 在线人数上限超出套餐限制的调低到套餐限制。修改的虚拟网络标记为待下发
+// 需在已锁定权益来源的事务中调用，提交后由调用方调用 VnetEventService.Notify
+func (s *vnetService) ApplyPlanLimits(ctx context.Context, userId string, orgId string, plan *model.Plan) (*PlanLimitResult, error) {
+	var vnets []model.Vnet
+	if orgId == "" {
+		all, err := s.vnetRepository.GetVnetByUserId(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+		for _, vnet := range *all {
+			if vnet.OrgId == "" {
+				vnets = append(vnets, vnet)
+			}
+		}
+	} else {
+		all, err := s.vnetRepository.GetVnetsByOrgIds(ctx, []string{orgId})
+		if err != nil {
+			return nil, err
+		}
+		vnets = *all
+	}
+
+	var running []*model.Vnet
+	var runningIds []string
+	for i := range vnets {
+		if vnets[i].Enabled {
+			running = append(running, &vnets[i])
+			runningIds = append(runningIds, vnets[i].VnetId)
+		}
+	}
+	disabled := make(map[string]bool)
+	if len(running) > plan.VnetLimit {
+		recent, err := s.vnetRepository.GetVnetIdsByLastUsage(ctx, runningIds)
+		if err != nil {
+			return nil, err
+		}
+		// 最近使用的虚拟网络排名最大，没有流量记录的为 0
+		lastUsage := make(map[string]int, len(recent))
+		for i, vnetId := range recent {
+			lastUsage[vnetId] = len(recent) - i
+		}
+		sort.SliceStable(running, func(i, j int) bool {
+			li, lj := lastUsage[running[i].VnetId], lastUsage[running[j].VnetId]
+			if li != lj {
+				return li > lj
+			}
+			return running[i].ID < running[j].ID
+		})
+		for _, vnet := range running[plan.VnetLimit:] {
+			disabled[vnet.VnetId] = true
+		}
+	}
+
+	result := &PlanLimitResult{}
+	for i := range vnets {
+		if !disabled[vnets[i].VnetId] && vnets[i].ClientsLimit <= plan.ClientsPerVnet {
+			continue
+		}
+		// 加锁重新读取后修改，避免整行保存时覆盖并发写入的字段
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnets[i].VnetId)
+		if err != nil {
+			return nil, err
+		}
+		eventType := ""
+		if disabled[vnet.VnetId] {
+			vnet.Enabled = false
+			vnet.SuspendReason = model.VnetSuspendPlanLimit
+			eventType = model.VnetEventDisable
+		}
+		clamped := vnet.ClientsLimit > plan.ClientsPerVnet
+		if clamped {
+			vnet.ClientsLimit = plan.ClientsPerVnet
+			if eventType == "" {
+				eventType = model.VnetEventUpdate
+			}
+		}
+		if eventType == "" {
+			continue
+		}
+		if err := s.updateWithEvent(ctx, vnet, eventType); err != nil {
+			return nil, err
+		}
+		if disabled[vnet.VnetId] {
+			result.Disabled = append(result.Disabled, *vnet)
+		}
+		if clamped {
+			result.Clamped = append(result.Clamped, *vnet)
+		}
+	}
+	return result, nil
+}
+
+// updateLocked 在事务中加锁读取虚拟网络并经 mutate 修改，递增配置版本、保存并记录变更事件
+// 基于最新记录修改，整行保存时不会覆盖调度器分配的节点、在线数量等并发写入的字段
+func (s *vnetService) updateLocked(ctx context.Context, vnetId string, eventType string, mutate func(vnet *model.Vnet)) error {
+	s.vnetLock.Lock()
+	defer s.vnetLock.Unlock()
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		mutate(vnet)
+		return s.updateWithEvent(ctx, vnet, eventType)
+	})
 	if err != nil {
 		return err
 	}
-	vnet.Enabled = false
+	s.vnetEventService.Notify()
+	return nil
+}
+
+// updateWithEvent 递增配置版本、保存并记录变更事件，需在事务中调用
+func (s *vnetService) updateWithEvent(ctx context.Context, vnet *model.Vnet, eventType string) error {
 	vnet.NeedUpdate = true
+	vnet.Revision++
 	if err := s.vnetRepository.UpdateVnet(ctx, vnet); err != nil {
 		return err
 	}
-	return nil
+	return s.vnetEventService.Record(ctx, eventType, vnet)
+}
+
+// hashVnetPassword 生成密码校验值，密码为空时返回空值
+func hashVnetPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	return vnetpass.Hash(password)
 }
 
 func (s *vnetService) CheckVnetTokenExists(ctx context.Context, token string, excludeVnetId string) (bool, error) {
@@ -140,3 +619,12 @@ func (s *vnetService) GetOnlineDevicesCount(ctx context.Context, userId string)
 func (s *vnetService) GetRunningVnetCount(ctx context.Context, userId string) (int, error) {
 	return s.vnetRepository.GetRunningVnetCount(ctx, userId)
 }
+
+func (s *vnetService) GetOrgRunningVnetCount(ctx context.Context, orgId string) (int, error) {
+	return s.vnetRepository.GetRunningVnetCountByOrgId(ctx, orgId)
+}
+
+// GetSubscriber 获取虚拟网络的权益来源及其当前生效的套餐：组织的虚拟网络为组织，个人虚拟网络为用户本人
+func (s *vnetService) GetSubscriber(ctx context.Context, userId string, orgId string) (*model.Entitlement, error) {
+	return getSubscriber(ctx, s.userRepository, s.organizationRepository, s.planService, userId, orgId)
+}
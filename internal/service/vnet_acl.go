@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// aclPriorityStep 未指定优先级时新规则与最后一条规则的间隔，便于之后在中间插入规则
+const aclPriorityStep = 10
+
+// aclTagPattern 成员标签的格式
+var aclTagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// VnetAclService 虚拟网络成员之间的访问控制
+// 规则或成员标签每次变更都会重新编译出完整的规则集，递增版本号并随虚拟网络配置下发给节点
+type VnetAclService interface {
+	GetAcl(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetAclResponseData, error)
+	CreateRule(ctx context.Context, vnetId string, req *v1.AclRuleRequest) (*v1.AclRuleItem, error)
+	UpdateRule(ctx context.Context, vnetId string, ruleId string, req *v1.AclRuleRequest) (*v1.AclRuleItem, error)
+	DeleteRule(ctx context.Context, vnetId string, ruleId string) error
+	SetMemberTags(ctx context.Context, vnetId string, clientId string, tags []string) error
+}
+
+func NewVnetAclService(
+	service *Service,
+	vnetRepository repository.VnetRepository,
+	userRepository repository.UserRepository,
+	vnetAclRepository repository.VnetAclRepository,
+	vnetEventService VnetEventService,
+	organizationRepository repository.OrganizationRepository,
+	planService PlanService,
+) VnetAclService {
+	return &vnetAclService{
+		Service:                service,
+		vnetRepository:         vnetRepository,
+		userRepository:         userRepository,
+		vnetAclRepository:      vnetAclRepository,
+		vnetEventService:       vnetEventService,
+		organizationRepository: organizationRepository,
+		planService:            planService,
+	}
+}
+
+type vnetAclService struct {
+	*Service
+	vnetRepository         repository.VnetRepository
+	userRepository         repository.UserRepository
+	vnetAclRepository      repository.VnetAclRepository
+	vnetEventService       VnetEventService
+	organizationRepository repository.OrganizationRepository
+	planService            PlanService
+}
+
+func (s *vnetAclService) GetAcl(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetAclResponseData, error) {
+	owner, err := getSubscriber(ctx, s.userRepository, s.organizationRepository, s.planService, vnet.UserId, vnet.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.vnetAclRepository.GetRules(ctx, vnet.VnetId)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.vnetAclRepository.GetMemberTags(ctx, vnet.VnetId)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &v1.GetVnetAclResponseData{
+		Revision: vnet.AclRevision,
+		MaxRules: owner.GetMaxAclRulesPerVNet(),
+		Rules:    make([]v1.AclRuleItem, 0, len(*rules)),
+		Members:  make([]v1.AclMemberTags, 0),
+	}
+	for i := range *rules {
+		data.Rules = append(data.Rules, toAclRuleItem(&(*rules)[i]))
+	}
+	// 标签已按设备标识排序
+	for _, tag := range *tags {
+		if n := len(data.Members); n > 0 && data.Members[n-1].ClientId == tag.ClientId {
+			data.Members[n-1].Tags = append(data.Members[n-1].Tags, tag.Tag)
+			continue
+		}
+		data.Members = append(data.Members, v1.AclMemberTags{ClientId: tag.ClientId, Tags: []string{tag.Tag}})
+	}
+	return data, nil
+}
+
+// CreateRule 添加规则，规则数量受所有者（组织的虚拟网络为组织）的用户组限制
+func (s *vnetAclService) CreateRule(ctx context.Context, vnetId string, req *v1.AclRuleRequest) (*v1.AclRuleItem, error) {
+	rule := &model.VnetAclRule{VnetId: vnetId}
+	if err := applyAclRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	ruleId, err := s.sid.GenString()
+	if err != nil {
+		return nil, err
+	}
+	rule.RuleId = "acl_" + ruleId
+
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		owner, err := getSubscriber(ctx, s.userRepository, s.organizationRepository, s.planService, vnet.UserId, vnet.OrgId)
+		if err != nil {
+			return err
+		}
+		count, err := s.vnetAclRepository.CountRules(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		if count >= int64(owner.GetMaxAclRulesPerVNet()) {
+			return v1.ErrAclRuleLimitExceeded
+		}
+		if req.Priority == nil {
+			rules, err := s.vnetAclRepository.GetRules(ctx, vnetId)
+			if err != nil {
+				return err
+			}
+			rule.Priority = aclPriorityStep
+			if n := len(*rules); n > 0 {
+				rule.Priority = (*rules)[n-1].Priority + aclPriorityStep
+			}
+		}
+		if err := s.vnetAclRepository.CreateRule(ctx, rule); err != nil {
+			return err
+		}
+		return s.publish(ctx, vnet)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.vnetEventService.Notify()
+	item := toAclRuleItem(rule)
+	return &item, nil
+}
+
+// UpdateRule 修改规则，未指定优先级时保持原有顺序
+func (s *vnetAclService) UpdateRule(ctx context.Context, vnetId string, ruleId string, req *v1.AclRuleRequest) (*v1.AclRuleItem, error) {
+	var rule *model.VnetAclRule
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		rule, err = s.vnetAclRepository.GetRule(ctx, vnetId, ruleId)
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			return v1.ErrNotFound
+		}
+		if err := applyAclRuleRequest(rule, req); err != nil {
+			return err
+		}
+		if err := s.vnetAclRepository.UpdateRule(ctx, rule); err != nil {
+			return err
+		}
+		return s.publish(ctx, vnet)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.vnetEventService.Notify()
+	item := toAclRuleItem(rule)
+	return &item, nil
+}
+
+func (s *vnetAclService) DeleteRule(ctx context.Context, vnetId string, ruleId string) error {
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		deleted, err := s.vnetAclRepository.DeleteRule(ctx, vnetId, ruleId)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return v1.ErrNotFound
+		}
+		return s.publish(ctx, vnet)
+	})
+	if err != nil {
+		return err
+	}
+	s.vnetEventService.Notify()
+	return nil
+}
+
+// SetMemberTags 替换成员的全部标签，引用这些标签的规则随之重新编译
+func (s *vnetAclService) SetMemberTags(ctx context.Context, vnetId string, clientId string, tags []string) error {
+	if clientId == "" || len(clientId) > 64 {
+		return v1.ErrBadRequest
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !aclTagPattern.MatchString(tag) {
+			return v1.ErrBadRequest
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		if err := s.vnetAclRepository.SetMemberTags(ctx, vnetId, clientId, normalized); err != nil {
+			return err
+		}
+		return s.publish(ctx, vnet)
+	})
+	if err != nil {
+		return err
+	}
+	s.vnetEventService.Notify()
+	return nil
+}
+
+// publish 重新编译已锁定虚拟网络的规则集，递增规则与配置版本并记录变更事件，需在事务中调用
+func (s *vnetAclService) publish(ctx context.Context, vnet *model.Vnet) error {
+	rules, err := s.vnetAclRepository.GetRules(ctx, vnet.VnetId)
+	if err != nil {
+		return err
+	}
+	tags, err := s.vnetAclRepository.GetMemberTags(ctx, vnet.VnetId)
+	if err != nil {
+		return err
+	}
+
+	vnet.AclRevision++
+	acl := CompileAcl(*rules, *tags, vnet.AclRevision)
+	encoded, err := json.Marshal(acl)
+	if err != nil {
+		return err
+	}
+	vnet.Acl = string(encoded)
+	vnet.NeedUpdate = true
+	vnet.Revision++
+	if err := s.vnetRepository.UpdateVnet(ctx, vnet); err != nil {
+		return err
+	}
+	return s.vnetEventService.Record(ctx, model.VnetEventUpdate, vnet)
+}
+
+// CompileAcl 将规则按匹配顺序编译为下发给节点的规则集
+// 标签展开为当前拥有该标签的设备，展开后不匹配任何成员的规则不会生效，直接省略
+func CompileAcl(rules []model.VnetAclRule, tags []model.VnetMemberTag, revision int64) v1.NodeVnetAcl {
+	members := make(map[string][]string)
+	for _, tag := range tags {
+		members[tag.Tag] = append(members[tag.Tag], tag.ClientId)
+	}
+
+	acl := v1.NodeVnetAcl{Revision: revision, Rules: make([]v1.NodeAclRule, 0, len(rules))}
+	for _, rule := range rules {
+		source, ok := compileAclTarget(rule.Source, members)
+		if !ok {
+			continue
+		}
+		destination, ok := compileAclTarget(rule.Destination, members)
+		if !ok {
+			continue
+		}
+		acl.Rules = append(acl.Rules, v1.NodeAclRule{
+			Action:      rule.Action,
+			Source:      source,
+			Destination: destination,
+			Protocol:    rule.Protocol,
+			PortFrom:    rule.PortFrom,
+			PortTo:      rule.PortTo,
+		})
+	}
+	return acl
+}
+
+// compileAclTarget 展开成员选择器，选择器不匹配任何成员时返回 false
+func compileAclTarget(selector string, members map[string][]string) (v1.NodeAclTarget, bool) {
+	switch {
+	case selector == "*":
+		return v1.NodeAclTarget{Any: true}, true
+	case strings.HasPrefix(selector, "tag:"):
+		clientIds := members[strings.TrimPrefix(selector, "tag:")]
+		if len(clientIds) == 0 {
+			return v1.NodeAclTarget{}, false
+		}
+		sorted := append([]string(nil), clientIds...)
+		sort.Strings(sorted)
+		return v1.NodeAclTarget{ClientIds: sorted}, true
+	case strings.HasPrefix(selector, "client:"):
+		return v1.NodeAclTarget{ClientIds: []string{strings.TrimPrefix(selector, "client:")}}, true
+	default:
+		return v1.NodeAclTarget{Cidrs: []string{selector}}, true
+	}
+}
+
+// applyAclRuleRequest 校验规则并以规范形式写入 rule
+func applyAclRuleRequest(rule *model.VnetAclRule, req *v1.AclRuleRequest) error {
+	source, err := normalizeAclSelector(req.Source)
+	if err != nil {
+		return err
+	}
+	destination, err := normalizeAclSelector(req.Destination)
+	if err != nil {
+		return err
+	}
+	protocol := strings.ToLower(req.Protocol)
+	if protocol == "" {
+		protocol = model.AclProtocolAny
+	}
+	portFrom, portTo, err := parseAclPorts(req.Ports)
+	if err != nil {
+		return err
+	}
+	switch protocol {
+	case model.AclProtocolTCP, model.AclProtocolUDP:
+	case model.AclProtocolAny, model.AclProtocolICMP:
+		// 端口只对 tcp/udp 有意义
+		if portFrom != 0 {
+			return v1.ErrInvalidAclRule
+		}
+	default:
+		return v1.ErrInvalidAclRule
+	}
+	if req.Action != model.AclActionAllow && req.Action != model.AclActionDeny {
+		return v1.ErrInvalidAclRule
+	}
+
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	rule.Action = req.Action
+	rule.Source = source
+	rule.Destination = destination
+	rule.Protocol = protocol
+	rule.PortFrom = portFrom
+	rule.PortTo = portTo
+	rule.Comment = req.Comment
+	return nil
+}
+
+// normalizeAclSelector 校验成员选择器并转换为规范形式，地址统一转换为网络地址形式的 CIDR
+func normalizeAclSelector(selector string) (string, error) {
+	selector = strings.TrimSpace(selector)
+	switch {
+	case selector == "*":
+		return selector, nil
+	case strings.HasPrefix(selector, "tag:"):
+		tag := strings.ToLower(strings.TrimPrefix(selector, "tag:"))
+		if !aclTagPattern.MatchString(tag) {
+			return "", v1.ErrInvalidAclRule
+		}
+		return "tag:" + tag, nil
+	case strings.HasPrefix(selector, "client:"):
+		clientId := strings.TrimPrefix(selector, "client:")
+		if clientId == "" || len(clientId) > 64 {
+			return "", v1.ErrInvalidAclRule
+		}
+		return selector, nil
+	case strings.Contains(selector, "/"):
+		prefix, err := netip.ParsePrefix(selector)
+		if err != nil {
+			return "", v1.ErrInvalidAclRule
+		}
+		return prefix.Masked().String(), nil
+	default:
+		addr, err := netip.ParseAddr(selector)
+		if err != nil || addr.Zone() != "" {
+			return "", v1.ErrInvalidAclRule
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()).String(), nil
+	}
+}
+
+// parseAclPorts 解析端口或端口范围，为空时返回 0, 0 表示所有端口
+func parseAclPorts(ports string) (int, int, error) {
+	ports = strings.TrimSpace(ports)
+	if ports == "" {
+		return 0, 0, nil
+	}
+	from, to, isRange := strings.Cut(ports, "-")
+	if !isRange {
+		to = from
+	}
+	portFrom, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, v1.ErrInvalidAclRule
+	}
+	portTo, err := strconv.Atoi(strings.TrimSpace(to))
+	if err != nil {
+		return 0, 0, v1.ErrInvalidAclRule
+	}
+	if portFrom < 1 || portTo > 65535 || portFrom > portTo {
+		return 0, 0, v1.ErrInvalidAclRule
+	}
+	return portFrom, portTo, nil
+}
+
+// formatAclPorts 将端口范围格式化为接口中使用的形式，与 parseAclPorts 相反
+func formatAclPorts(portFrom int, portTo int) string {
+	switch {
+	case portFrom == 0:
+		return ""
+	case portFrom == portTo:
+		return strconv.Itoa(portFrom)
+	default:
+		return strconv.Itoa(portFrom) + "-" + strconv.Itoa(portTo)
+	}
+}
+
+func toAclRuleItem(rule *model.VnetAclRule) v1.AclRuleItem {
+	return v1.AclRuleItem{
+		RuleId:      rule.RuleId,
+		Priority:    rule.Priority,
+		Action:      rule.Action,
+		Source:      rule.Source,
+		Destination: rule.Destination,
+		Protocol:    rule.Protocol,
+		Ports:       formatAclPorts(rule.PortFrom, rule.PortTo),
+		Comment:     rule.Comment,
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"net/netip"
+	"time"
+)
+
+// VnetBanService 断开与封禁虚拟网络中的设备
+// 断开通过变更事件推送给承载虚拟网络的节点；封禁保存在控制面，由接入检查执行
+type VnetBanService interface {
+	GetBans(ctx context.Context, vnetId string) (*[]model.VnetBan, error)
+	CreateBan(ctx context.Context, vnetId string, req *v1.CreateVnetBanRequest) (*model.VnetBan, error)
+	UpdateBan(ctx context.Context, vnetId string, banId string, req *v1.UpdateVnetBanRequest) (*model.VnetBan, error)
+	DeleteBan(ctx context.Context, vnetId string, banId string) error
+	KickClient(ctx context.Context, vnetId string, clientId string) error
+}
+
+func NewVnetBanService(
+	service *Service,
+	vnetRepository repository.VnetRepository,
+	vnetClientRepository repository.VnetClientRepository,
+	vnetMemberRepository repository.VnetMemberRepository,
+	vnetBanRepository repository.VnetBanRepository,
+	vnetEventService VnetEventService,
+) VnetBanService {
+	return &vnetBanService{
+		Service:              service,
+		vnetRepository:       vnetRepository,
+		vnetClientRepository: vnetClientRepository,
+		vnetMemberRepository: vnetMemberRepository,
+		vnetBanRepository:    vnetBanRepository,
+		vnetEventService:     vnetEventService,
+	}
+}
+
+type vnetBanService struct {
+	*Service
+	vnetRepository       repository.VnetRepository
+	vnetClientRepository repository.VnetClientRepository
+	vnetMemberRepository repository.VnetMemberRepository
+	vnetBanRepository    repository.VnetBanRepository
+	vnetEventService     VnetEventService
+}
+
+func (s *vnetBanService) GetBans(ctx context.Context, vnetId string) (*[]model.VnetBan, error) {
+	return s.vnetBanRepository.GetBans(ctx, vnetId)
+}
+
+// CreateBan 封禁设备或公网地址，并断开受影响的在线客户端
+// 封禁设备时同时删除其审批记录，解除封禁后需要重新审批
+func (s *vnetBanService) CreateBan(ctx context.Context, vnetId string, req *v1.CreateVnetBanRequest) (*model.VnetBan, error) {
+	ban := &model.VnetBan{VnetId: vnetId, ClientId: req.ClientId, Reason: req.Reason}
+	switch {
+	case (req.ClientId == "") == (req.Ip == ""):
+		return nil, v1.ErrBadRequest
+	case req.Ip != "":
+		ip, ok := normalizeBanIp(req.Ip)
+		if !ok {
+			return nil, v1.ErrBadRequest
+		}
+		ban.Ip = ip
+	}
+	ban.ExpiresAt = expiryAfter(req.Duration, time.Now())
+	banId, err := s.sid.GenString()
+	if err != nil {
+		return nil, err
+	}
+	ban.BanId = "ban_" + banId
+
+	kicked := false
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		// 设备标识 -> 设备密钥哈希，封禁记录被封禁设备的密钥，使用同一密钥的其他标识一并封禁
+		deviceKeys := make(map[string]string)
+		if ban.ClientId != "" {
+			members, err := s.vnetMemberRepository.GetMembers(ctx, vnetId, "")
+			if err != nil {
+				return err
+			}
+			for _, member := range *members {
+				deviceKeys[member.ClientId] = member.DeviceKeyHash
+			}
+			ban.DeviceKeyHash = deviceKeys[ban.ClientId]
+		}
+		if err := s.vnetBanRepository.CreateBan(ctx, ban); err != nil {
+			return err
+		}
+		if ban.ClientId != "" {
+			if _, err := s.vnetMemberRepository.DeleteMember(ctx, vnetId, ban.ClientId); err != nil {
+				return err
+			}
+			for clientId, keyHash := range deviceKeys {
+				if clientId == ban.ClientId || !banMatches(ban, clientId, keyHash, "") {
+					continue
+				}
+				if _, err := s.vnetMemberRepository.DeleteMember(ctx, vnetId, clientId); err != nil {
+					return err
+				}
+			}
+		}
+
+		clients, err := s.vnetClientRepository.GetVnetClientsByVnetId(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		for _, client := range *clients {
+			if !banMatches(ban, client.ClientId, deviceKeys[client.ClientId], client.PublicEndpoint) {
+				continue
+			}
+			if err := s.disconnect(ctx, vnet, client.ClientId); err != nil {
+				return err
+			}
+			kicked = true
+		}
+		if !kicked {
+			return nil
+		}
+		return s.vnetClientRepository.SyncClientsOnline(ctx, []string{vnetId})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if kicked {
+		s.vnetEventService.Notify()
+	}
+	return ban, nil
+}
+
+// UpdateBan 修改封禁原因与时长，时长从现在起重新计算
+func (s *vnetBanService) UpdateBan(ctx context.Context, vnetId string, banId string, req *v1.UpdateVnetBanRequest) (*model.VnetBan, error) {
+	ban, err := s.vnetBanRepository.GetBan(ctx, vnetId, banId)
+	if err != nil {
+		return nil, err
+	}
+	if ban == nil {
+		return nil, v1.ErrNotFound
+	}
+	ban.Reason = req.Reason
+	ban.ExpiresAt = expiryAfter(req.Duration, time.Now())
+	if err := s.vnetBanRepository.UpdateBan(ctx, ban); err != nil {
+		return nil, err
+	}
+	return ban, nil
+}
+
+func (s *vnetBanService) DeleteBan(ctx context.Context, vnetId string, banId string) error {
+	deleted, err := s.vnetBanRepository.DeleteBan(ctx, vnetId, banId)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return v1.ErrNotFound
+	}
+	return nil
+}
+
+// KickClient 断开在线客户端，客户端之后仍可重新接入
+func (s *vnetBanService) KickClient(ctx context.Context, vnetId string, clientId string) error {
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		clients, err := s.vnetClientRepository.GetVnetClientsByVnetId(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		online := false
+		for _, client := range *clients {
+			if client.ClientId == clientId {
+				online = true
+				break
+			}
+		}
+		if !online {
+			return v1.ErrNotFound
+		}
+		if err := s.disconnect(ctx, vnet, clientId); err != nil {
+			return err
+		}
+		return s.vnetClientRepository.SyncClientsOnline(ctx, []string{vnetId})
+	})
+	if err != nil {
+		return err
+	}
+	s.vnetEventService.Notify()
+	return nil
+}
+
+// disconnect 删除客户端会话并通知节点断开，需在事务中调用
+// 节点未及时处理事件时，下次心跳也会因会话不存在而断开该客户端
+func (s *vnetBanService) disconnect(ctx context.Context, vnet *model.Vnet, clientId string) error {
+	if _, err := s.vnetClientRepository.DeleteVnetClient(ctx, vnet.VnetId, clientId); err != nil {
+		return err
+	}
+	return s.vnetEventService.RecordKick(ctx, vnet, clientId)
+}
+
+// normalizeBanIp 校验封禁的 IP 或 CIDR 并转换为规范形式
+func normalizeBanIp(ip string) (string, bool) {
+	if prefix, err := netip.ParsePrefix(ip); err == nil {
+		return netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()).Masked().String(), true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || addr.Zone() != "" {
+		return "", false
+	}
+	return addr.Unmap().String(), true
+}
+
+// endpointAddr 解析客户端公网地址（ip:port 或 ip）中的 IP
+func endpointAddr(endpoint string) (netip.Addr, bool) {
+	if addrPort, err := netip.ParseAddrPort(endpoint); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(endpoint)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// banMatches 判断封禁是否适用于设备，deviceKeyHash 为设备提交的设备密钥哈希，未提交时为空
+func banMatches(ban *model.VnetBan, clientId string, deviceKeyHash string, endpoint string) bool {
+	if ban.ClientId != "" {
+		return ban.ClientId == clientId || (ban.DeviceKeyHash != "" && ban.DeviceKeyHash == deviceKeyHash)
+	}
+	addr, ok := endpointAddr(endpoint)
+	if !ok {
+		return false
+	}
+	if prefix, err := netip.ParsePrefix(ban.Ip); err == nil {
+		return prefix.Contains(addr)
+	}
+	banned, err := netip.ParseAddr(ban.Ip)
+	return err == nil && banned == addr
+}
+
+// deviceKeyHash 计算设备提交的设备密钥的哈希，未提交时为空
+func deviceKeyHash(deviceKey string) string {
+	if deviceKey == "" {
+		return ""
+	}
+	return hashToken(deviceKey)
+}
+
+// isBanned 判断设备是否命中任一有效封禁
+func isBanned(bans []model.VnetBan, clientId string, deviceKeyHash string, endpoint string) bool {
+	for i := range bans {
+		if banMatches(&bans[i], clientId, deviceKeyHash, endpoint) {
+			return true
+		}
+	}
+	return false
+}
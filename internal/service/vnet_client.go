@@ -0,0 +1,528 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"hyacinth-backend/pkg/jwt"
+	"hyacinth-backend/pkg/vnetpass"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// defaultSessionTTL 未配置 vnet.session_ttl 时会话凭证的有效期
+const defaultSessionTTL = 10 * time.Minute
+
+// challengeTTL 接入挑战的有效期，客户端需在此期间内完成应答
+const challengeTTL = time.Minute
+
+// VnetClientService 虚拟网络客户端会话
+type VnetClientService interface {
+	ClientChallenge(ctx context.Context, nodeId string, req *v1.ClientChallengeRequest) (*v1.ClientChallengeResponseData, error)
+	AdmitClient(ctx context.Context, nodeId string, req *v1.AdmitClientRequest) (*v1.AdmitClientResponseData, error)
+	ClientJoin(ctx context.Context, nodeId string, req *v1.ClientJoinRequest) (*v1.ClientJoinResponseData, error)
+	ClientLeave(ctx context.Context, nodeId string, req *v1.ClientLeaveRequest) error
+	ClientHeartbeat(ctx context.Context, nodeId string, req *v1.ClientHeartbeatRequest) (*v1.ClientHeartbeatResponseData, error)
+	GetVnetClients(ctx context.Context, vnetId string) (*[]model.VnetClient, error)
+}
+
+func NewVnetClientService(
+	service *Service,
+	conf *viper.Viper,
+	vnetRepository repository.VnetRepository,
+	userRepository repository.UserRepository,
+	vnetClientRepository repository.VnetClientRepository,
+	ipamService IpamService,
+	ipLeaseRepository repository.IpLeaseRepository,
+	nodeRepository repository.NodeRepository,
+	vnetMemberRepository repository.VnetMemberRepository,
+	vnetBanRepository repository.VnetBanRepository,
+	vnetInviteRepository repository.VnetInviteRepository,
+	organizationRepository repository.OrganizationRepository,
+	planService PlanService,
+) VnetClientService {
+	sessionTTL := conf.GetDuration("vnet.session_ttl")
+	if sessionTTL <= 0 {
+		sessionTTL = defaultSessionTTL
+	}
+	return &vnetClientService{
+		Service:                service,
+		keyring:                newNodeKeyring(conf, nodeRepository),
+		sessionTTL:             sessionTTL,
+		legacyPassword:         conf.GetBool("vnet.legacy_password"),
+		vnetRepository:         vnetRepository,
+		userRepository:         userRepository,
+		vnetClientRepository:   vnetClientRepository,
+		ipamService:            ipamService,
+		ipLeaseRepository:      ipLeaseRepository,
+		vnetMemberRepository:   vnetMemberRepository,
+		vnetBanRepository:      vnetBanRepository,
+		vnetInviteRepository:   vnetInviteRepository,
+		organizationRepository: organizationRepository,
+		planService:            planService,
+	}
+}
+
+type vnetClientService struct {
+	*Service
+	keyring                *nodeKeyring // 节点签名密钥，用于签发挑战与会话凭证
+	sessionTTL             time.Duration
+	legacyPassword         bool // 是否接受旧版节点转发的密码明文
+	vnetRepository         repository.VnetRepository
+	userRepository         repository.UserRepository
+	vnetClientRepository   repository.VnetClientRepository
+	ipamService            IpamService
+	ipLeaseRepository      repository.IpLeaseRepository
+	vnetMemberRepository   repository.VnetMemberRepository
+	vnetBanRepository      repository.VnetBanRepository
+	vnetInviteRepository   repository.VnetInviteRepository
+	organizationRepository repository.OrganizationRepository
+	planService            PlanService
+}
+
+// ClientChallenge 为客户端签发接入挑战，并返回其计算应答所需的密码派生参数
+func (s *vnetClientService) ClientChallenge(ctx context.Context, nodeId string, req *v1.ClientChallengeRequest) (*v1.ClientChallengeResponseData, error) {
+	ok, err := s.keyring.Exists(ctx, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, v1.ErrUnauthorized
+	}
+	signingKey := s.keyring.SigningKey(nodeId)
+	vnet, err := s.vnetRepository.GetVnetByToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &v1.ClientChallengeResponseData{Kdf: "argon2id"}
+	vnetId := ""
+	switch {
+	case vnet == nil:
+		// 令牌不存在时返回由令牌确定的伪造参数，与设置了密码的虚拟网络无法区分
+		mac := hmac.New(sha256.New, []byte(signingKey))
+		mac.Write([]byte(req.Token))
+		data.PasswordRequired = true
+		data.Salt = base64.StdEncoding.EncodeToString(mac.Sum(nil)[:16])
+		data.Time, data.Memory, data.Threads = vnetpass.DefaultTime, vnetpass.DefaultMemory, vnetpass.DefaultThreads
+	case vnet.PasswordHash != "":
+		verifier, err := vnetpass.Parse(vnet.PasswordHash)
+		if err != nil {
+			return nil, err
+		}
+		vnetId = vnet.VnetId
+		data.PasswordRequired = true
+		data.Salt = base64.StdEncoding.EncodeToString(verifier.Salt)
+		data.Time, data.Memory, data.Threads = verifier.Time, verifier.Memory, verifier.Threads
+	default:
+		vnetId = vnet.VnetId
+	}
+
+	nonce, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(challengeTTL)
+	data.Challenge, err = jwt.GenChallenge([]byte(signingKey), nodeId, vnetId, req.ClientId, nonce, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	data.ExpiresAt = expiresAt.Unix()
+	return data, nil
+}
+
+// verifyPassword 校验客户端的密码应答，返回准入成功时需记为已使用的挑战
+// 应答须针对签发给本节点、同一虚拟网络与设备且尚未过期的挑战，每个挑战只能用于一次准入，
+// 截获的应答在挑战过期前也无法重放；开启 vnet.legacy_password 时旧版节点可直接转发密码明文
+func (s *vnetClientService) verifyPassword(signingKey string, nodeId string, vnet *model.Vnet, req *v1.AdmitClientRequest) (bool, *jwt.ChallengeClaims) {
+	if vnet.PasswordHash == "" {
+		return true, nil
+	}
+	verifier, err := vnetpass.Parse(vnet.PasswordHash)
+	if err != nil {
+		return false, nil
+	}
+	if req.Proof == "" {
+		return s.legacyPassword && req.Password != "" && verifier.VerifyPassword(req.Password), nil
+	}
+	claims, err := jwt.ParseChallenge([]byte(signingKey), req.Challenge, nodeId)
+	if err != nil || claims.VnetId != vnet.VnetId || claims.ClientId != req.ClientId || claims.ID == "" || claims.ExpiresAt == nil {
+		return false, nil
+	}
+	proof, err := base64.StdEncoding.DecodeString(req.Proof)
+	if err != nil {
+		return false, nil
+	}
+	if !verifier.VerifyProof(vnetpass.AuthMessage(req.Token, req.ClientId, req.Challenge), proof) {
+		return false, nil
+	}
+	return true, claims
+}
+
+// verifyInviteKey 校验设备兑换邀请获得的接入密钥，邀请被撤销后密钥失效
+func (s *vnetClientService) verifyInviteKey(ctx context.Context, vnet *model.Vnet, req *v1.AdmitClientRequest) (bool, error) {
+	redemption, err := s.vnetInviteRepository.GetRedemption(ctx, vnet.VnetId, req.ClientId)
+	if err != nil || redemption == nil {
+		return false, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(req.InviteKey)), []byte(redemption.KeyHash)) != 1 {
+		return false, nil
+	}
+	invite, err := s.vnetInviteRepository.GetInvite(ctx, vnet.VnetId, redemption.InviteId)
+	if err != nil || invite == nil {
+		return false, err
+	}
+	return invite.RevokedAt == nil, nil
+}
+
+// AdmitClient 校验客户端的接入令牌与密码应答（或邀请的接入密钥），并在名额允许时为其预留会话和地址租约
+// 锁定虚拟网络记录后再统计在线会话，并发接入不会超出客户端数量限制
+// 被封禁的设备或公网地址拒绝接入；开启接入审批时，未经批准的设备记录为待审批并拒绝接入
+func (s *vnetClientService) AdmitClient(ctx context.Context, nodeId string, req *v1.AdmitClientRequest) (*v1.AdmitClientResponseData, error) {
+	ok, err := s.keyring.Exists(ctx, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, v1.ErrUnauthorized
+	}
+	signingKey := s.keyring.SigningKey(nodeId)
+	vnet, err := s.vnetRepository.GetVnetByToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	// 令牌不存在与密码错误返回相同的错误，避免探测令牌
+	if vnet == nil {
+		return nil, v1.ErrUnauthorized
+	}
+	var challenge *jwt.ChallengeClaims
+	if req.InviteKey != "" {
+		ok, err = s.verifyInviteKey(ctx, vnet, req)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ok, challenge = s.verifyPassword(signingKey, nodeId, vnet, req)
+	}
+	if !ok {
+		return nil, v1.ErrUnauthorized
+	}
+	if vnet.NodeId != nodeId {
+		return nil, v1.ErrForbidden
+	}
+
+	var lease *model.IpLease
+	pending := false
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err = s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnet.VnetId)
+		if err != nil {
+			return err
+		}
+		if !vnet.Enabled {
+			return v1.ErrForbidden
+		}
+		// 组织的虚拟网络按组织的流量与权益计算
+		owner, err := getSubscriber(ctx, s.userRepository, s.organizationRepository, s.planService, vnet.UserId, vnet.OrgId)
+		if err != nil {
+			return err
+		}
+		if owner.GetRemainingTraffic() <= 0 {
+			return v1.ErrTrafficExhausted
+		}
+		bans, err := s.vnetBanRepository.GetActiveBans(ctx, vnet.VnetId, time.Now())
+		if err != nil {
+			return err
+		}
+		if isBanned(*bans, req.ClientId, deviceKeyHash(req.DeviceKey), req.PublicEndpoint) {
+			return v1.ErrMemberBanned
+		}
+		clients, err := s.vnetClientRepository.GetVnetClientsByVnetId(ctx, vnet.VnetId)
+		if err != nil {
+			return err
+		}
+		// 待审批记录需要提交，因此不以错误的形式返回
+		if pending, err = s.reviewMember(ctx, vnet, req, *clients); err != nil || pending {
+			return err
+		}
+
+		if !hasClientSlot(owner, vnet, *clients, req.ClientId) {
+			return v1.ErrVnetClientsFull
+		}
+		// 挑战与准入在同一事务中记为已使用，因名额不足等原因拒绝准入时挑战不会被消耗
+		if challenge != nil {
+			used, err := s.vnetClientRepository.UseChallenge(ctx, challenge.ID, challenge.ExpiresAt.Time)
+			if err != nil {
+				return err
+			}
+			if !used {
+				return v1.ErrUnauthorized
+			}
+		}
+		if lease, err = s.ipamService.AcquireLease(ctx, vnet, req.ClientId, req.MacAddress, req.VirtualIp); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		err = s.vnetClientRepository.UpsertVnetClient(ctx, &model.VnetClient{
+			VnetId:         vnet.VnetId,
+			ClientId:       req.ClientId,
+			VirtualIp:      lease.Address,
+			MacAddress:     req.MacAddress,
+			PublicEndpoint: req.PublicEndpoint,
+			NodeId:         nodeId,
+			ConnectedAt:    now,
+			LastSeen:       now,
+		})
+		if err != nil {
+			return err
+		}
+		return s.vnetClientRepository.SyncClientsOnline(ctx, []string{vnet.VnetId})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, v1.ErrMemberPendingApproval
+	}
+
+	expiresAt := time.Now().Add(s.sessionTTL)
+	sessionToken, err := jwt.GenSessionToken([]byte(signingKey), nodeId, vnet.VnetId, req.ClientId, lease.Address, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	data := &v1.AdmitClientResponseData{
+		VnetId:       vnet.VnetId,
+		VirtualIp:    lease.Address,
+		IpRange:      vnet.IpRange,
+		SessionToken: sessionToken,
+		ExpiresAt:    expiresAt.Unix(),
+	}
+	if lease.ExpiresAt != nil {
+		data.LeaseExpiresAt = lease.ExpiresAt.Unix()
+	}
+	return data, nil
+}
+
+// reviewMember 根据设备的审批记录判断能否接入，返回设备是否需要等待审批，需在事务中调用
+// 设备标识由客户端自行选择，因此审批记录与设备密钥绑定：尚未绑定的记录（新设备、所有者预先批准或开启审批前的设备）
+// 在首次接入时绑定提交的密钥，之后以同一标识接入须提交相同的密钥，否则拒绝，他人无法冒用已批准设备的标识
+// 开启审批后，未经批准的设备记录为待审批，
+// 已有在线会话的设备视为已批准（在开启审批前接入），避免开启审批时断开现有成员
+func (s *vnetClientService) reviewMember(ctx context.Context, vnet *model.Vnet, req *v1.AdmitClientRequest, clients []model.VnetClient) (bool, error) {
+	if !vnet.RequireApproval {
+		return false, nil
+	}
+	if req.DeviceKey == "" {
+		return false, v1.ErrDeviceKeyInvalid
+	}
+	member, err := s.vnetMemberRepository.GetMember(ctx, vnet.VnetId, req.ClientId)
+	if err != nil {
+		return false, err
+	}
+	keyHash := hashToken(req.DeviceKey)
+	if member != nil && member.DeviceKeyHash != "" && subtle.ConstantTimeCompare([]byte(keyHash), []byte(member.DeviceKeyHash)) != 1 {
+		return false, v1.ErrDeviceKeyInvalid
+	}
+	if member != nil && member.Status == model.VnetMemberApproved {
+		if member.DeviceKeyHash != "" {
+			return false, nil
+		}
+		// 兑换过邀请的设备标识只能由持有其接入密钥的设备绑定，他人无法凭密码抢先绑定自动批准的记录
+		if req.InviteKey == "" {
+			redemption, err := s.vnetInviteRepository.GetRedemption(ctx, vnet.VnetId, req.ClientId)
+			if err != nil {
+				return false, err
+			}
+			if redemption != nil {
+				return false, v1.ErrDeviceKeyInvalid
+			}
+		}
+		member.DeviceKeyHash = keyHash
+		return false, s.vnetMemberRepository.SaveMember(ctx, member)
+	}
+
+	online := false
+	for _, client := range clients {
+		if client.ClientId == req.ClientId {
+			online = true
+			break
+		}
+	}
+	now := time.Now()
+	if member == nil {
+		member = &model.VnetMember{VnetId: vnet.VnetId, ClientId: req.ClientId, Status: model.VnetMemberPending}
+	}
+	member.DeviceKeyHash = keyHash
+	member.MacAddress = req.MacAddress
+	member.PublicEndpoint = req.PublicEndpoint
+	member.LastSeen = now
+	if online {
+		member.Status = model.VnetMemberApproved
+		member.ReviewedAt = &now
+	}
+	if err := s.vnetMemberRepository.SaveMember(ctx, member); err != nil {
+		return false, err
+	}
+	return !online, nil
+}
+
+// clientsLimit 取虚拟网络设置与所有者当前权益（特权过期后按普通用户计算）中较小的客户端数量限制
+func clientsLimit(owner *model.Entitlement, vnet *model.Vnet) int {
+	limit := owner.GetMaxClientsLimitPerVNet()
+	if vnet.ClientsLimit > 0 && vnet.ClientsLimit < limit {
+		limit = vnet.ClientsLimit
+	}
+	return limit
+}
+
+// hasClientSlot 判断设备能否在虚拟网络中占用一个名额，同一设备重新接入时不重复占用
+func hasClientSlot(owner *model.Entitlement, vnet *model.Vnet, clients []model.VnetClient, clientId string) bool {
+	others := 0
+	for _, client := range clients {
+		if client.ClientId != clientId {
+			others++
+		}
+	}
+	return others < clientsLimit(owner, vnet)
+}
+
+// ClientJoin 节点凭准入时签发的会话凭证上报客户端加入，会话地址取自凭证且须仍是设备当前的租约
+// 准入之后会话可能已过期或设备已被封禁、撤销批准，因此重新检查封禁、审批、流量与客户端数量限制
+func (s *vnetClientService) ClientJoin(ctx context.Context, nodeId string, req *v1.ClientJoinRequest) (*v1.ClientJoinResponseData, error) {
+	ok, err := s.keyring.Exists(ctx, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, v1.ErrUnauthorized
+	}
+	signingKey := s.keyring.SigningKey(nodeId)
+	claims, err := jwt.ParseSessionToken([]byte(signingKey), req.SessionToken, nodeId)
+	if err != nil || claims.VnetId != req.VnetId || claims.ClientId != req.ClientId {
+		return nil, v1.ErrUnauthorized
+	}
+
+	now := time.Now()
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, claims.VnetId)
+		if err != nil {
+			return err
+		}
+		if vnet == nil || vnet.NodeId != nodeId || !vnet.Enabled {
+			return v1.ErrForbidden
+		}
+		// 租约已过期或地址已分配给其他设备时凭证失效，节点需重新准入
+		lease, err := s.ipLeaseRepository.GetLeaseByClientId(ctx, vnet.VnetId, claims.ClientId)
+		if err != nil {
+			return err
+		}
+		if lease == nil || lease.Address != claims.VirtualIp || (lease.ExpiresAt != nil && !lease.ExpiresAt.After(now)) {
+			return v1.ErrUnauthorized
+		}
+		owner, err := getSubscriber(ctx, s.userRepository, s.organizationRepository, s.planService, vnet.UserId, vnet.OrgId)
+		if err != nil {
+			return err
+		}
+		if owner.GetRemainingTraffic() <= 0 {
+			return v1.ErrTrafficExhausted
+		}
+		bans, err := s.vnetBanRepository.GetActiveBans(ctx, vnet.VnetId, now)
+		if err != nil {
+			return err
+		}
+		if isBanned(*bans, req.ClientId, "", req.PublicEndpoint) {
+			return v1.ErrMemberBanned
+		}
+		clients, err := s.vnetClientRepository.GetVnetClientsByVnetId(ctx, vnet.VnetId)
+		if err != nil {
+			return err
+		}
+		if vnet.RequireApproval {
+			member, err := s.vnetMemberRepository.GetMember(ctx, vnet.VnetId, req.ClientId)
+			if err != nil {
+				return err
+			}
+			if member == nil || member.Status != model.VnetMemberApproved {
+				return v1.ErrMemberPendingApproval
+			}
+		}
+		if !hasClientSlot(owner, vnet, *clients, req.ClientId) {
+			return v1.ErrVnetClientsFull
+		}
+
+		err = s.vnetClientRepository.UpsertVnetClient(ctx, &model.VnetClient{
+			VnetId:         vnet.VnetId,
+			ClientId:       req.ClientId,
+			VirtualIp:      lease.Address,
+			MacAddress:     req.MacAddress,
+			PublicEndpoint: req.PublicEndpoint,
+			NodeId:         nodeId,
+			ConnectedAt:    now,
+			LastSeen:       now,
+		})
+		if err != nil {
+			return err
+		}
+		return s.vnetClientRepository.SyncClientsOnline(ctx, []string{vnet.VnetId})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ClientJoinResponseData{
+		VirtualIp:   claims.VirtualIp,
+		ConnectedAt: now.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+// ClientLeave 删除客户端会话，会话不存在时视为成功，便于节点重试
+func (s *vnetClientService) ClientLeave(ctx context.Context, nodeId string, req *v1.ClientLeaveRequest) error {
+	if _, err := getAssignedVnet(ctx, s.vnetRepository, nodeId, req.VnetId); err != nil {
+		return err
+	}
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		existed, err := s.vnetClientRepository.DeleteVnetClient(ctx, req.VnetId, req.ClientId)
+		if err != nil || !existed {
+			return err
+		}
+		return s.vnetClientRepository.SyncClientsOnline(ctx, []string{req.VnetId})
+	})
+}
+
+// ClientHeartbeat 刷新节点上仍在线的客户端会话，返回控制面已不存在的会话
+func (s *vnetClientService) ClientHeartbeat(ctx context.Context, nodeId string, req *v1.ClientHeartbeatRequest) (*v1.ClientHeartbeatResponseData, error) {
+	clients, err := s.vnetClientRepository.GetVnetClientsByNodeId(ctx, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make(map[v1.ClientSessionRef]uint, len(*clients))
+	for _, client := range *clients {
+		sessions[v1.ClientSessionRef{VnetId: client.VnetId, ClientId: client.ClientId}] = client.ID
+	}
+
+	ids := make([]uint, 0, len(req.Clients))
+	unknown := []v1.ClientSessionRef{}
+	for _, ref := range req.Clients {
+		if id, ok := sessions[ref]; ok {
+			ids = append(ids, id)
+		} else {
+			unknown = append(unknown, ref)
+		}
+	}
+	if err := s.vnetClientRepository.TouchVnetClients(ctx, ids, time.Now()); err != nil {
+		return nil, err
+	}
+	return &v1.ClientHeartbeatResponseData{
+		Refreshed: len(ids),
+		Unknown:   unknown,
+	}, nil
+}
+
+func (s *vnetClientService) GetVnetClients(ctx context.Context, vnetId string) (*[]model.VnetClient, error) {
+	return s.vnetClientRepository.GetVnetClientsByVnetId(ctx, vnetId)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"strings"
+)
+
+// auditLogLimit 审计日志每次返回的条数
+const auditLogLimit = 200
+
+// VnetCollaboratorService 虚拟网络的协作者与审计日志
+// 用户只能授予比自己低的角色，也只能修改或移除角色比自己低的协作者；协作者可以随时退出
+// 角色变更与审计日志在同一事务中写入
+type VnetCollaboratorService interface {
+	GetCollaborators(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetCollaboratorsResponseData, error)
+	SetCollaborator(ctx context.Context, vnet *model.Vnet, actorId string, actorRole string, req *v1.SetVnetCollaboratorRequest) error
+	RemoveCollaborator(ctx context.Context, vnet *model.Vnet, actorId string, actorRole string, userId string) error
+	GetAuditLogs(ctx context.Context, vnetId string) (*v1.GetVnetAuditLogsResponseData, error)
+}
+
+func NewVnetCollaboratorService(
+	service *Service,
+	userRepository repository.UserRepository,
+	vnetCollaboratorRepository repository.VnetCollaboratorRepository,
+) VnetCollaboratorService {
+	return &vnetCollaboratorService{
+		Service:                    service,
+		userRepository:             userRepository,
+		vnetCollaboratorRepository: vnetCollaboratorRepository,
+	}
+}
+
+type vnetCollaboratorService struct {
+	*Service
+	userRepository             repository.UserRepository
+	vnetCollaboratorRepository repository.VnetCollaboratorRepository
+}
+
+// GetCollaborators 获取虚拟网络的所有者与协作者，组织的虚拟网络只有协作者
+func (s *vnetCollaboratorService) GetCollaborators(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetCollaboratorsResponseData, error) {
+	collaborators, err := s.vnetCollaboratorRepository.GetCollaborators(ctx, vnet.VnetId)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]v1.VnetCollaboratorItem, 0, len(*collaborators)+1)
+	// 组织的虚拟网络由组织成员管理，没有个人所有者
+	if vnet.OrgId == "" {
+		owner := v1.VnetCollaboratorItem{UserId: vnet.UserId, Role: model.VnetRoleOwner, CreatedAt: vnet.CreatedAt.Format("2006-01-02 15:04:05")}
+		if owner.Username, err = lookupUsername(ctx, s.userRepository, vnet.UserId); err != nil {
+			return nil, err
+		}
+		items = append(items, owner)
+	}
+	for _, collaborator := range *collaborators {
+		item := v1.VnetCollaboratorItem{
+			UserId:    collaborator.UserId,
+			Role:      collaborator.Role,
+			GrantedBy: collaborator.GrantedBy,
+			CreatedAt: collaborator.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if item.Username, err = lookupUsername(ctx, s.userRepository, collaborator.UserId); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return &v1.GetVnetCollaboratorsResponseData{Collaborators: items}, nil
+}
+
+// SetCollaborator 按用户名或邮箱授予角色，用户已是协作者时修改其角色
+func (s *vnetCollaboratorService) SetCollaborator(ctx context.Context, vnet *model.Vnet, actorId string, actorRole string, req *v1.SetVnetCollaboratorRequest) error {
+	if !model.IsCollaboratorRole(req.Role) {
+		return v1.ErrBadRequest
+	}
+	if model.VnetRoleRank(req.Role) >= model.VnetRoleRank(actorRole) {
+		return v1.ErrForbidden
+	}
+	var user *model.User
+	var err error
+	if strings.Contains(req.Account, "@") {
+		user, err = s.userRepository.GetByEmail(ctx, req.Account)
+	} else {
+		user, err = s.userRepository.GetByUsername(ctx, req.Account)
+	}
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return v1.ErrNotFound
+	}
+	// 所有者与操作者本人的角色不能通过授予修改
+	if user.UserId == vnet.UserId || user.UserId == actorId {
+		return v1.ErrBadRequest
+	}
+
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		collaborator, err := s.vnetCollaboratorRepository.GetCollaborator(ctx, vnet.VnetId, user.UserId)
+		if err != nil {
+			return err
+		}
+		log := &model.VnetAuditLog{VnetId: vnet.VnetId, ActorId: actorId, TargetId: user.UserId}
+		if collaborator == nil {
+			collaborator = &model.VnetCollaborator{VnetId: vnet.VnetId, UserId: user.UserId}
+			log.Action = model.VnetAuditCollaboratorGrant
+			log.Detail = req.Role
+		} else {
+			if model.VnetRoleRank(collaborator.Role) >= model.VnetRoleRank(actorRole) {
+				return v1.ErrForbidden
+			}
+			if collaborator.Role == req.Role {
+				return nil
+			}
+			log.Action = model.VnetAuditCollaboratorUpdate
+			log.Detail = collaborator.Role + " -> " + req.Role
+		}
+		collaborator.Role = req.Role
+		collaborator.GrantedBy = actorId
+		if err := s.vnetCollaboratorRepository.SaveCollaborator(ctx, collaborator); err != nil {
+			return err
+		}
+		return s.vnetCollaboratorRepository.CreateAuditLog(ctx, log)
+	})
+}
+
+// RemoveCollaborator 移除协作者，协作者也可以移除自己以退出协作
+func (s *vnetCollaboratorService) RemoveCollaborator(ctx context.Context, vnet *model.Vnet, actorId string, actorRole string, userId string) error {
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		collaborator, err := s.vnetCollaboratorRepository.GetCollaborator(ctx, vnet.VnetId, userId)
+		if err != nil {
+			return err
+		}
+		if collaborator == nil {
+			return v1.ErrNotFound
+		}
+		if userId != actorId && model.VnetRoleRank(collaborator.Role) >= model.VnetRoleRank(actorRole) {
+			return v1.ErrForbidden
+		}
+		if _, err := s.vnetCollaboratorRepository.DeleteCollaborator(ctx, vnet.VnetId, userId); err != nil {
+			return err
+		}
+		return s.vnetCollaboratorRepository.CreateAuditLog(ctx, &model.VnetAuditLog{
+			VnetId:   vnet.VnetId,
+			ActorId:  actorId,
+			Action:   model.VnetAuditCollaboratorRevoke,
+			TargetId: userId,
+			Detail:   collaborator.Role,
+		})
+	})
+}
+
+func (s *vnetCollaboratorService) GetAuditLogs(ctx context.Context, vnetId string) (*v1.GetVnetAuditLogsResponseData, error) {
+	logs, err := s.vnetCollaboratorRepository.GetAuditLogs(ctx, vnetId, auditLogLimit)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]v1.VnetAuditLogItem, 0, len(*logs))
+	for _, log := range *logs {
+		items = append(items, v1.VnetAuditLogItem{
+			ActorId:   log.ActorId,
+			Action:    log.Action,
+			TargetId:  log.TargetId,
+			Detail:    log.Detail,
+			CreatedAt: log.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return &v1.GetVnetAuditLogsResponseData{Logs: items}, nil
+}
+
+// lookupUsername 获取用户名，用户已注销时返回空
+func lookupUsername(ctx context.Context, userRepository repository.UserRepository, userId string) (string, error) {
+	user, err := userRepository.GetByID(ctx, userId)
+	if errors.Is(err, v1.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return user.Username, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"hyacinth-backend/pkg/clientconf"
+	"net/netip"
+	"regexp"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// defaultConfigFormat 未指定格式时使用的客户端配置格式
+	defaultConfigFormat = "json"
+	// qrCodeScale 二维码每个模块的像素数
+	qrCodeScale = 8
+)
+
+// configClientIdPattern 配置中可写入的设备标识，只允许常见的标识字符，避免在配置中注入额外的参数
+var configClientIdPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:@-]{0,63}$`)
+
+// ClientConfig 渲染好的客户端配置文件
+type ClientConfig struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// VnetConfigService 生成虚拟网络的客户端配置
+// 配置包含承载节点的地址、令牌与地址设置；密码不保存明文，以占位符输出
+// WireGuard 虚拟网络默认输出 WireGuard 配置，设备私钥由客户端持有，以占位符输出
+type VnetConfigService interface {
+	RenderConfig(ctx context.Context, vnet *model.Vnet, req *v1.GetVnetConfigRequest) (*ClientConfig, error)
+	RenderQRCode(ctx context.Context, vnet *model.Vnet, req *v1.GetVnetConfigRequest) ([]byte, error)
+}
+
+func NewVnetConfigService(
+	service *Service,
+	nodeRepository repository.NodeRepository,
+	ipLeaseRepository repository.IpLeaseRepository,
+) VnetConfigService {
+	return &vnetConfigService{
+		Service:           service,
+		nodeRepository:    nodeRepository,
+		ipLeaseRepository: ipLeaseRepository,
+	}
+}
+
+type vnetConfigService struct {
+	*Service
+	nodeRepository    repository.NodeRepository
+	ipLeaseRepository repository.IpLeaseRepository
+}
+
+// RenderConfig 按请求的格式渲染配置，未注册的格式或不合法的设备标识返回 ErrBadRequest
+func (s *vnetConfigService) RenderConfig(ctx context.Context, vnet *model.Vnet, req *v1.GetVnetConfigRequest) (*ClientConfig, error) {
+	if req.ClientId != "" && !configClientIdPattern.MatchString(req.ClientId) {
+		return nil, v1.ErrBadRequest
+	}
+	format := req.Format
+	if format == "" {
+		format = defaultConfigFormat
+		if vnet.Type == model.VnetTypeWireGuard {
+			format = model.VnetTypeWireGuard
+		}
+	}
+	renderer, ok := clientconf.Get(format)
+	if !ok {
+		return nil, v1.ErrBadRequest
+	}
+	profile, err := s.profile(ctx, vnet, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	content, err := renderer.Render(profile)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientConfig{
+		FileName:    renderer.FileName(profile),
+		ContentType: renderer.ContentType(),
+		Content:     content,
+	}, nil
+}
+
+// RenderQRCode 将同一份配置编码为二维码 PNG，供移动端扫码导入
+func (s *vnetConfigService) RenderQRCode(ctx context.Context, vnet *model.Vnet, req *v1.GetVnetConfigRequest) ([]byte, error) {
+	config, err := s.RenderConfig(ctx, vnet, req)
+	if err != nil {
+		return nil, err
+	}
+	// 编码只会因内容超出最大版本的容量而失败
+	code, err := qrcode.New(string(config.Content), qrcode.Medium)
+	if err != nil {
+		return nil, v1.ErrBadRequest
+	}
+	// 尺寸为负数时按每个模块的像素数渲染，四周保留规范要求的空白区
+	return code.PNG(-qrCodeScale)
+}
+
+// profile 汇总虚拟网络、承载节点与设备保留地址
+func (s *vnetConfigService) profile(ctx context.Context, vnet *model.Vnet, clientId string) (*clientconf.Profile, error) {
+	if vnet.NodeId == "" {
+		return nil, v1.ErrVnetNodeUnassigned
+	}
+	node, err := s.nodeRepository.GetNodeByNodeId(ctx, vnet.NodeId)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil || node.PublicAddr == "" {
+		return nil, v1.ErrVnetNodeUnassigned
+	}
+	prefix, err := netip.ParsePrefix(vnet.IpRange)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &clientconf.Profile{
+		VnetId:          vnet.VnetId,
+		Comment:         vnet.Comment,
+		Token:           vnet.Token,
+		RequirePassword: vnet.PasswordHash != "",
+		ServerAddr:      node.PublicAddr,
+		NodeId:          node.NodeId,
+		IpRange:         prefix.Masked().String(),
+		PrefixLen:       prefix.Bits(),
+		EnableDHCP:      vnet.EnableDHCP,
+		ClientId:        clientId,
+		RequireApproval: vnet.RequireApproval,
+		PeerPublicKey:   node.WgPublicKey,
+	}
+	if clientId != "" {
+		lease, err := s.ipLeaseRepository.GetLeaseByClientId(ctx, vnet.VnetId, clientId)
+		if err != nil {
+			return nil, err
+		}
+		if lease != nil && lease.Static {
+			profile.Address = lease.Address
+		}
+	}
+	return profile, nil
+}
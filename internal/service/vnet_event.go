@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"sync"
+	"time"
+)
+
+const (
+	// vnetEventBatchSize 每次从数据库读取的事件数量
+	vnetEventBatchSize = 100
+	// vnetEventPollInterval 轮询间隔，保证其它实例写入的事件也能被及时推送
+	vnetEventPollInterval = 2 * time.Second
+	// vnetEventGapTimeout 遇到版本号空洞时的最长等待时间
+	// 并发事务可能乱序提交，空洞在超时前视为尚未提交的事件，超时后视为已回滚
+	vnetEventGapTimeout = 5 * time.Second
+)
+
+// VnetEventService 虚拟网络变更事件流
+type VnetEventService interface {
+	Record(ctx context.Context, eventType string, vnet *model.Vnet) error
+	RecordKick(ctx context.Context, vnet *model.Vnet, clientId string) error
+	Notify()
+	GetLatestRevision(ctx context.Context) (int64, error)
+	Watch(ctx context.Context, nodeId string, fromRevision int64, send func(event *v1.NodeVnetEvent) error) error
+}
+
+func NewVnetEventService(
+	service *Service,
+	vnetEventRepository repository.VnetEventRepository,
+) VnetEventService {
+	return &vnetEventService{
+		Service:             service,
+		vnetEventRepository: vnetEventRepository,
+		notifyCh:            make(chan struct{}),
+	}
+}
+
+type vnetEventService struct {
+	*Service
+	vnetEventRepository repository.VnetEventRepository
+	notifyMutex         sync.Mutex
+	notifyCh            chan struct{} // 每次有新事件时关闭并替换，用于唤醒所有订阅者
+}
+
+// Record 记录一条变更事件，应与虚拟网络的修改在同一事务中调用
+func (s *vnetEventService) Record(ctx context.Context, eventType string, vnet *model.Vnet) error {
+	event := &model.VnetEvent{
+		VnetId:       vnet.VnetId,
+		UserId:       vnet.UserId,
+		NodeId:       vnet.NodeId,
+		Type:         eventType,
+		VnetRevision: vnet.Revision,
+	}
+	if eventType != model.VnetEventDelete {
+		payload, err := json.Marshal(vnetToNodeConfig(vnet))
+		if err != nil {
+			return err
+		}
+		event.Payload = string(payload)
+	}
+	return s.vnetEventRepository.Create(ctx, event)
+}
+
+// RecordKick 记录断开客户端的事件，由承载虚拟网络的节点执行，应与会话的删除在同一事务中调用
+func (s *vnetEventService) RecordKick(ctx context.Context, vnet *model.Vnet, clientId string) error {
+	return s.vnetEventRepository.Create(ctx, &model.VnetEvent{
+		VnetId:       vnet.VnetId,
+		UserId:       vnet.UserId,
+		NodeId:       vnet.NodeId,
+		Type:         model.VnetEventKick,
+		VnetRevision: vnet.Revision,
+		ClientId:     clientId,
+	})
+}
+
+// Notify 事务提交后调用，唤醒本实例上的订阅者
+func (s *vnetEventService) Notify() {
+	s.notifyMutex.Lock()
+	defer s.notifyMutex.Unlock()
+	close(s.notifyCh)
+	s.notifyCh = make(chan struct{})
+}
+
+func (s *vnetEventService) wait() <-chan struct{} {
+	s.notifyMutex.Lock()
+	defer s.notifyMutex.Unlock()
+	return s.notifyCh
+}
+
+func (s *vnetEventService) GetLatestRevision(ctx context.Context) (int64, error) {
+	return s.vnetEventRepository.GetLatestRevision(ctx)
+}
+
+// Watch 按版本号顺序推送 fromRevision 之后与该节点相关的事件，直到 ctx 结束或发送失败
+// fromRevision 之后的事件已被清理时只推送一条 resync 事件后结束，节点须重新拉取列表并从新的版本号订阅
+func (s *vnetEventService) Watch(ctx context.Context, nodeId string, fromRevision int64, send func(event *v1.NodeVnetEvent) error) error {
+	oldest, err := s.vnetEventRepository.GetOldestRevision(ctx)
+	if err != nil {
+		return err
+	}
+	if oldest > fromRevision+1 {
+		return send(&v1.NodeVnetEvent{Type: model.VnetEventResync})
+	}
+
+	cursor := fromRevision
+	for {
+		// 先取得唤醒通道再读取，避免读取与等待之间产生的事件被遗漏
+		wakeup := s.wait()
+
+		events, err := s.vnetEventRepository.ListAfter(ctx, cursor, vnetEventBatchSize)
+		if err != nil {
+			return err
+		}
+
+		blocked := false
+		for _, event := range *events {
+			revision := int64(event.ID)
+			if revision != cursor+1 && time.Since(event.CreatedAt) < vnetEventGapTimeout {
+				blocked = true
+				break
+			}
+			cursor = revision
+			if event.NodeId != nodeId {
+				continue
+			}
+			if err := send(vnetEventToAPI(&event)); err != nil {
+				return err
+			}
+		}
+
+		if len(*events) == vnetEventBatchSize && !blocked {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wakeup:
+		case <-time.After(vnetEventPollInterval):
+		}
+	}
+}
+
+func vnetEventToAPI(event *model.VnetEvent) *v1.NodeVnetEvent {
+	result := &v1.NodeVnetEvent{
+		Revision:     int64(event.ID),
+		Type:         event.Type,
+		VnetId:       event.VnetId,
+		VnetRevision: event.VnetRevision,
+		ClientId:     event.ClientId,
+		CreatedAt:    event.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if event.Payload != "" {
+		var config v1.NodeVnetConfig
+		if err := json.Unmarshal([]byte(event.Payload), &config); err == nil {
+			result.Config = &config
+		}
+	}
+	return result
+}
+
+// vnetToNodeConfig 将虚拟网络转换为下发给节点的配置
+func vnetToNodeConfig(vnet *model.Vnet) v1.NodeVnetConfig {
+	config := v1.NodeVnetConfig{
+		VnetId:       vnet.VnetId,
+		Type:         vnet.Type,
+		Enabled:      vnet.Enabled,
+		Token:        vnet.Token,
+		IpRange:      vnet.IpRange,
+		EnableDHCP:   vnet.EnableDHCP,
+		ClientsLimit: vnet.ClientsLimit,
+		Revision:     vnet.Revision,
+	}
+	if vnet.Acl != "" {
+		var acl v1.NodeVnetAcl
+		if err := json.Unmarshal([]byte(vnet.Acl), &acl); err == nil {
+			config.Acl = &acl
+		}
+	}
+	if vnet.Type == model.VnetTypeWireGuard {
+		// 尚未登记设备时下发空列表，节点据此清空对端
+		peers := v1.NodeVnetPeers{Revision: vnet.PeerRevision, Peers: []v1.NodeWireGuardPeer{}}
+		if vnet.Peers != "" {
+			var compiled v1.NodeVnetPeers
+			if err := json.Unmarshal([]byte(vnet.Peers), &compiled); err == nil {
+				peers = compiled
+			}
+		}
+		config.Peers = &peers
+	}
+	if vnet.Routes != "" {
+		var routes v1.NodeVnetRoutes
+		if err := json.Unmarshal([]byte(vnet.Routes), &routes); err == nil {
+			config.Routes = &routes
+		}
+	}
+	// WireGuard 按 AllowedIps 选择对端，子网路由与出口节点的前缀需加入下一跳设备的 AllowedIps
+	if config.Peers != nil && config.Routes != nil {
+		for i := range config.Peers.Peers {
+			peer := &config.Peers.Peers[i]
+			for _, route := range config.Routes.Routes {
+				if route.ClientId == peer.ClientId {
+					peer.AllowedIps = append(peer.AllowedIps, route.Prefix)
+				}
+			}
+		}
+	}
+	return config
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"time"
+)
+
+// inviteCodeRandomLength 邀请码中随机部分的长度
+const inviteCodeRandomLength = 16
+
+// VnetInviteService 虚拟网络的邀请
+// 设备兑换邀请后获得仅限自身使用的接入密钥，所有者可以按邀请撤销，无需更换令牌或密码
+type VnetInviteService interface {
+	GetInvites(ctx context.Context, vnetId string) (*v1.GetVnetInvitesResponseData, error)
+	CreateInvite(ctx context.Context, vnetId string, req *v1.CreateVnetInviteRequest) (*v1.VnetInviteItem, error)
+	RevokeInvite(ctx context.Context, vnetId string, inviteId string) error
+	RedeemInvite(ctx context.Context, ip string, req *v1.RedeemInviteRequest) (*v1.RedeemInviteResponseData, error)
+}
+
+func NewVnetInviteService(
+	service *Service,
+	vnetRepository repository.VnetRepository,
+	vnetBanRepository repository.VnetBanRepository,
+	vnetInviteRepository repository.VnetInviteRepository,
+	vnetMemberService VnetMemberService,
+) VnetInviteService {
+	return &vnetInviteService{
+		Service:              service,
+		vnetRepository:       vnetRepository,
+		vnetBanRepository:    vnetBanRepository,
+		vnetInviteRepository: vnetInviteRepository,
+		vnetMemberService:    vnetMemberService,
+	}
+}
+
+type vnetInviteService struct {
+	*Service
+	vnetRepository       repository.VnetRepository
+	vnetBanRepository    repository.VnetBanRepository
+	vnetInviteRepository repository.VnetInviteRepository
+	vnetMemberService    VnetMemberService
+}
+
+// GetInvites 获取虚拟网络的全部邀请及各自的兑换记录
+func (s *vnetInviteService) GetInvites(ctx context.Context, vnetId string) (*v1.GetVnetInvitesResponseData, error) {
+	invites, err := s.vnetInviteRepository.GetInvites(ctx, vnetId)
+	if err != nil {
+		return nil, err
+	}
+	redemptions, err := s.vnetInviteRepository.GetRedemptions(ctx, vnetId)
+	if err != nil {
+		return nil, err
+	}
+	byInvite := make(map[string][]v1.VnetInviteRedemptionItem)
+	for _, redemption := range *redemptions {
+		byInvite[redemption.InviteId] = append(byInvite[redemption.InviteId], v1.VnetInviteRedemptionItem{
+			ClientId:   redemption.ClientId,
+			Name:       redemption.Name,
+			Ip:         redemption.Ip,
+			RedeemedAt: redemption.RedeemedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	now := time.Now()
+	data := &v1.GetVnetInvitesResponseData{Invites: make([]v1.VnetInviteItem, 0, len(*invites))}
+	for i := range *invites {
+		invite := &(*invites)[i]
+		item := toVnetInviteItem(invite, now)
+		if items, ok := byInvite[invite.InviteId]; ok {
+			item.Redemptions = items
+		}
+		data.Invites = append(data.Invites, item)
+	}
+	return data, nil
+}
+
+func (s *vnetInviteService) CreateInvite(ctx context.Context, vnetId string, req *v1.CreateVnetInviteRequest) (*v1.VnetInviteItem, error) {
+	inviteId, err := s.sid.GenString()
+	if err != nil {
+		return nil, err
+	}
+	code, err := s.sid.GenCode(inviteCodeRandomLength)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	invite := &model.VnetInvite{
+		InviteId:    "inv_" + inviteId,
+		VnetId:      vnetId,
+		Code:        code,
+		Comment:     req.Comment,
+		MaxUses:     req.MaxUses,
+		AutoApprove: req.AutoApprove,
+		ExpiresAt:   expiryAfter(req.Duration, now),
+	}
+	if err := s.vnetInviteRepository.CreateInvite(ctx, invite); err != nil {
+		return nil, err
+	}
+	item := toVnetInviteItem(invite, now)
+	return &item, nil
+}
+
+// RevokeInvite 撤销邀请，邀请无法再兑换，通过它兑换的接入密钥也随之失效
+// 已接入的设备不受影响，如需立即断开请使用断开或封禁
+func (s *vnetInviteService) RevokeInvite(ctx context.Context, vnetId string, inviteId string) error {
+	invite, err := s.vnetInviteRepository.GetInvite(ctx, vnetId, inviteId)
+	if err != nil {
+		return err
+	}
+	if invite == nil {
+		return v1.ErrNotFound
+	}
+	if invite.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	invite.RevokedAt = &now
+	return s.vnetInviteRepository.UpdateInvite(ctx, invite)
+}
+
+// RedeemInvite 兑换邀请，为设备生成接入密钥，设置了自动批准的邀请同时批准该设备
+// 同一设备重复兑换同一邀请时更换接入密钥，不重复计入兑换次数
+func (s *vnetInviteService) RedeemInvite(ctx context.Context, ip string, req *v1.RedeemInviteRequest) (*v1.RedeemInviteResponseData, error) {
+	found, err := s.vnetInviteRepository.GetInviteByCode(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, v1.ErrNotFound
+	}
+	key, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	var data *v1.RedeemInviteResponseData
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, found.VnetId)
+		if err != nil {
+			return err
+		}
+		// 锁定虚拟网络后重新读取，并发兑换不会超出次数限制
+		invite, err := s.vnetInviteRepository.GetInvite(ctx, vnet.VnetId, found.InviteId)
+		if err != nil {
+			return err
+		}
+		if invite == nil {
+			return v1.ErrNotFound
+		}
+		now := time.Now()
+		if invite.RevokedAt != nil || (invite.ExpiresAt != nil && !invite.ExpiresAt.After(now)) {
+			return v1.ErrInviteUnavailable
+		}
+
+		bans, err := s.vnetBanRepository.GetActiveBans(ctx, vnet.VnetId, now)
+		if err != nil {
+			return err
+		}
+		if isBanned(*bans, req.ClientId, deviceKeyHash(req.DeviceKey), ip) {
+			return v1.ErrMemberBanned
+		}
+
+		redemption, err := s.vnetInviteRepository.GetRedemption(ctx, vnet.VnetId, req.ClientId)
+		if err != nil {
+			return err
+		}
+		// 设备已兑换过时须证明持有当前的接入密钥，否则任何人都能以他人的设备ID兑换并顶替其凭据与审批
+		if redemption != nil && subtle.ConstantTimeCompare([]byte(hashToken(req.InviteKey)), []byte(redemption.KeyHash)) != 1 {
+			return v1.ErrClientIdInUse
+		}
+		if redemption == nil || redemption.InviteId != invite.InviteId {
+			if !invite.IsActive(now) {
+				return v1.ErrInviteUnavailable
+			}
+			invite.Uses++
+			if err := s.vnetInviteRepository.UpdateInvite(ctx, invite); err != nil {
+				return err
+			}
+		}
+		if redemption == nil {
+			redemption = &model.VnetInviteRedemption{VnetId: vnet.VnetId, ClientId: req.ClientId}
+		}
+		redemption.InviteId = invite.InviteId
+		redemption.Name = req.Name
+		redemption.Ip = ip
+		redemption.KeyHash = hashToken(key)
+		redemption.RedeemedAt = now
+		if err := s.vnetInviteRepository.SaveRedemption(ctx, redemption); err != nil {
+			return err
+		}
+
+		requireApproval := vnet.RequireApproval
+		if invite.AutoApprove {
+			// 审批与兑换者提交的设备密钥绑定；未提交时该设备标识只能由持有接入密钥的设备接入并绑定
+			err := s.vnetMemberService.ApproveMember(ctx, vnet.VnetId, req.ClientId, deviceKeyHash(req.DeviceKey))
+			if errors.Is(err, v1.ErrDeviceKeyInvalid) {
+				return v1.ErrClientIdInUse
+			}
+			if err != nil {
+				return err
+			}
+			requireApproval = false
+		}
+		data = &v1.RedeemInviteResponseData{
+			VnetId:          vnet.VnetId,
+			Comment:         vnet.Comment,
+			Token:           vnet.Token,
+			ClientId:        req.ClientId,
+			InviteKey:       key,
+			RequireApproval: requireApproval,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func toVnetInviteItem(invite *model.VnetInvite, now time.Time) v1.VnetInviteItem {
+	item := v1.VnetInviteItem{
+		InviteId:    invite.InviteId,
+		Code:        invite.Code,
+		Comment:     invite.Comment,
+		MaxUses:     invite.MaxUses,
+		Uses:        invite.Uses,
+		AutoApprove: invite.AutoApprove,
+		CreatedAt:   invite.CreatedAt.Format("2006-01-02 15:04:05"),
+		Active:      invite.IsActive(now),
+		Redemptions: []v1.VnetInviteRedemptionItem{},
+	}
+	if invite.ExpiresAt != nil {
+		item.ExpiresAt = invite.ExpiresAt.Format("2006-01-02 15:04:05")
+	}
+	if invite.RevokedAt != nil {
+		item.RevokedAt = invite.RevokedAt.Format("2006-01-02 15:04:05")
+	}
+	return item
+}
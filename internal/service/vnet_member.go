@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"regexp"
+	"time"
+)
+
+// deviceKeyHashPattern 设备密钥的 SHA-256（十六进制）
+var deviceKeyHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// VnetMemberService 虚拟网络的设备审批
+// 操作均先锁定虚拟网络记录，与客户端接入时的审批检查串行执行
+type VnetMemberService interface {
+	GetMembers(ctx context.Context, vnetId string, status string) (*[]model.VnetMember, error)
+	ApproveMember(ctx context.Context, vnetId string, clientId string, deviceKeyHash string) error
+	RejectMember(ctx context.Context, vnetId string, clientId string) error
+	RemoveMember(ctx context.Context, vnetId string, clientId string) error
+}
+
+func NewVnetMemberService(
+	service *Service,
+	vnetRepository repository.VnetRepository,
+	vnetMemberRepository repository.VnetMemberRepository,
+) VnetMemberService {
+	return &vnetMemberService{
+		Service:              service,
+		vnetRepository:       vnetRepository,
+		vnetMemberRepository: vnetMemberRepository,
+	}
+}
+
+type vnetMemberService struct {
+	*Service
+	vnetRepository       repository.VnetRepository
+	vnetMemberRepository repository.VnetMemberRepository
+}
+
+func (s *vnetMemberService) GetMembers(ctx context.Context, vnetId string, status string) (*[]model.VnetMember, error) {
+	switch status {
+	case "", model.VnetMemberPending, model.VnetMemberApproved:
+	default:
+		return nil, v1.ErrBadRequest
+	}
+	return s.vnetMemberRepository.GetMembers(ctx, vnetId, status)
+}
+
+// ApproveMember 批准设备接入，也可用于预先批准尚未申请的设备，记录不存在时创建
+// deviceKeyHash 非空时审批与该设备密钥绑定，记录已绑定其他设备密钥时返回 ErrDeviceKeyInvalid；
+// 为空时沿用记录已绑定的密钥，预先批准的记录在设备首次接入时绑定
+func (s *vnetMemberService) ApproveMember(ctx context.Context, vnetId string, clientId string, deviceKeyHash string) error {
+	if clientId == "" || len(clientId) > 64 {
+		return v1.ErrBadRequest
+	}
+	if deviceKeyHash != "" && !deviceKeyHashPattern.MatchString(deviceKeyHash) {
+		return v1.ErrBadRequest
+	}
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId); err != nil {
+			return err
+		}
+		member, err := s.vnetMemberRepository.GetMember(ctx, vnetId, clientId)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if member == nil {
+			member = &model.VnetMember{VnetId: vnetId, ClientId: clientId, LastSeen: now}
+		}
+		if deviceKeyHash != "" {
+			if member.DeviceKeyHash != "" && member.DeviceKeyHash != deviceKeyHash {
+				return v1.ErrDeviceKeyInvalid
+			}
+			member.DeviceKeyHash = deviceKeyHash
+		}
+		member.Status = model.VnetMemberApproved
+		member.ReviewedAt = &now
+		return s.vnetMemberRepository.SaveMember(ctx, member)
+	})
+}
+
+// RejectMember 拒绝待审批的设备，删除其申请记录，设备之后可以重新申请
+func (s *vnetMemberService) RejectMember(ctx context.Context, vnetId string, clientId string) error {
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId); err != nil {
+			return err
+		}
+		member, err := s.vnetMemberRepository.GetMember(ctx, vnetId, clientId)
+		if err != nil {
+			return err
+		}
+		if member == nil || member.Status != model.VnetMemberPending {
+			return v1.ErrNotFound
+		}
+		_, err = s.vnetMemberRepository.DeleteMember(ctx, vnetId, clientId)
+		return err
+	})
+}
+
+// RemoveMember 删除设备的审批记录，已批准的设备需重新审批
+func (s *vnetMemberService) RemoveMember(ctx context.Context, vnetId string, clientId string) error {
+	return s.tm.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId); err != nil {
+			return err
+		}
+		deleted, err := s.vnetMemberRepository.DeleteMember(ctx, vnetId, clientId)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return v1.ErrNotFound
+		}
+		return nil
+	})
+}
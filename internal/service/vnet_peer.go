@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"hyacinth-backend/pkg/wgkey"
+	"net/netip"
+)
+
+// VnetPeerService WireGuard 虚拟网络的设备公钥管理
+// 设备以节点为唯一对端，节点持有全部设备的公钥并在设备之间转发流量；
+// 设备登记或删除后重新编译对端列表，递增版本号并随虚拟网络配置下发给节点
+type VnetPeerService interface {
+	GetPeers(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetPeersResponseData, error)
+	RegisterPeer(ctx context.Context, vnetId string, req *v1.RegisterVnetPeerRequest) (*v1.RegisterVnetPeerResponseData, error)
+	DeletePeer(ctx context.Context, vnetId string, clientId string) error
+}
+
+func NewVnetPeerService(
+	service *Service,
+	vnetRepository repository.VnetRepository,
+	vnetPeerRepository repository.VnetPeerRepository,
+	ipamService IpamService,
+	ipLeaseRepository repository.IpLeaseRepository,
+	nodeRepository repository.NodeRepository,
+	vnetEventService VnetEventService,
+	userRepository repository.UserRepository,
+	organizationRepository repository.OrganizationRepository,
+	planService PlanService,
+) VnetPeerService {
+	return &vnetPeerService{
+		Service:                service,
+		vnetRepository:         vnetRepository,
+		vnetPeerRepository:     vnetPeerRepository,
+		ipamService:            ipamService,
+		ipLeaseRepository:      ipLeaseRepository,
+		nodeRepository:         nodeRepository,
+		vnetEventService:       vnetEventService,
+		userRepository:         userRepository,
+		organizationRepository: organizationRepository,
+		planService:            planService,
+	}
+}
+
+type vnetPeerService struct {
+	*Service
+	vnetRepository         repository.VnetRepository
+	vnetPeerRepository     repository.VnetPeerRepository
+	ipamService            IpamService
+	ipLeaseRepository      repository.IpLeaseRepository
+	nodeRepository         repository.NodeRepository
+	vnetEventService       VnetEventService
+	userRepository         repository.UserRepository
+	organizationRepository repository.OrganizationRepository
+	planService            PlanService
+}
+
+// GetPeers 获取登记的设备与承载节点的公钥
+func (s *vnetPeerService) GetPeers(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetPeersResponseData, error) {
+	if vnet.Type != model.VnetTypeWireGuard {
+		return nil, v1.ErrVnetTypeMismatch
+	}
+	peers, err := s.vnetPeerRepository.GetPeers(ctx, vnet.VnetId)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &v1.GetVnetPeersResponseData{
+		Revision: vnet.PeerRevision,
+		Peers:    make([]v1.VnetPeerItem, 0, len(*peers)),
+	}
+	if vnet.NodeId != "" {
+		node, err := s.nodeRepository.GetNodeByNodeId(ctx, vnet.NodeId)
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			data.NodePublicKey = node.WgPublicKey
+		}
+	}
+	for i := range *peers {
+		data.Peers = append(data.Peers, toVnetPeerItem(&(*peers)[i]))
+	}
+	return data, nil
+}
+
+// RegisterPeer 登记设备公钥并为其分配固定地址，设备已登记时替换公钥
+// 设置 generate 时由服务端生成密钥对，私钥只在返回值中出现一次，不保存也不记录
+func (s *vnetPeerService) RegisterPeer(ctx context.Context, vnetId string, req *v1.RegisterVnetPeerRequest) (*v1.RegisterVnetPeerResponseData, error) {
+	var privateKey, publicKey string
+	var err error
+	if req.Generate {
+		privateKey, publicKey, err = wgkey.GenerateKeyPair()
+		if err != nil {
+			return nil, err
+		}
+	} else if publicKey, err = wgkey.ParsePublicKey(req.PublicKey); err != nil {
+		return nil, v1.ErrInvalidWireGuardKey
+	}
+
+	var peer *model.VnetPeer
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		if vnet.Type != model.VnetTypeWireGuard {
+			return v1.ErrVnetTypeMismatch
+		}
+		// 同一公钥只能属于一台设备，否则节点无法区分流量来源
+		owner, err := s.vnetPeerRepository.GetPeerByPublicKey(ctx, vnetId, publicKey)
+		if err != nil {
+			return err
+		}
+		if owner != nil && owner.ClientId != req.ClientId {
+			return v1.ErrInvalidWireGuardKey
+		}
+		peer, err = s.vnetPeerRepository.GetPeer(ctx, vnetId, req.ClientId)
+		if err != nil {
+			return err
+		}
+		if peer == nil {
+			// 与客户端接入相同，按虚拟网络设置与所有者当前权益中较小的限制计算
+			owner, err := getSubscriber(ctx, s.userRepository, s.organizationRepository, s.planService, vnet.UserId, vnet.OrgId)
+			if err != nil {
+				return err
+			}
+			count, err := s.vnetPeerRepository.CountPeers(ctx, vnetId)
+			if err != nil {
+				return err
+			}
+			if count >= int64(clientsLimit(owner, vnet)) {
+				return v1.ErrVnetClientsFull
+			}
+			peer = &model.VnetPeer{VnetId: vnetId, ClientId: req.ClientId}
+		}
+		lease, err := s.ipamService.AssignAddress(ctx, vnet, req.ClientId)
+		if err != nil {
+			return err
+		}
+		peer.Name = req.Name
+		peer.PublicKey = publicKey
+		peer.Address = lease.Address
+		if err := s.vnetPeerRepository.SavePeer(ctx, peer); err != nil {
+			return err
+		}
+		return s.publish(ctx, vnet)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.vnetEventService.Notify()
+	return &v1.RegisterVnetPeerResponseData{
+		VnetPeerItem: toVnetPeerItem(peer),
+		PrivateKey:   privateKey,
+	}, nil
+}
+
+// DeletePeer 删除设备登记的公钥并释放其地址，节点随即断开该设备
+func (s *vnetPeerService) DeletePeer(ctx context.Context, vnetId string, clientId string) error {
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		if vnet.Type != model.VnetTypeWireGuard {
+			return v1.ErrVnetTypeMismatch
+		}
+		found, err := s.vnetPeerRepository.DeletePeer(ctx, vnetId, clientId)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return v1.ErrNotFound
+		}
+		if _, err := s.ipLeaseRepository.DeleteLeaseByClientId(ctx, vnetId, clientId); err != nil {
+			return err
+		}
+		return s.publish(ctx, vnet)
+	})
+	if err != nil {
+		return err
+	}
+	s.vnetEventService.Notify()
+	return nil
+}
+
+// publish 重新编译对端列表并写入虚拟网络，记录变更事件，需在已锁定虚拟网络记录的事务中调用
+func (s *vnetPeerService) publish(ctx context.Context, vnet *model.Vnet) error {
+	peers, err := s.vnetPeerRepository.GetPeers(ctx, vnet.VnetId)
+	if err != nil {
+		return err
+	}
+
+	vnet.PeerRevision++
+	encoded, err := json.Marshal(CompilePeers(*peers, vnet.PeerRevision))
+	if err != nil {
+		return err
+	}
+	vnet.Peers = string(encoded)
+	vnet.NeedUpdate = true
+	vnet.Revision++
+	if err := s.vnetRepository.UpdateVnet(ctx, vnet); err != nil {
+		return err
+	}
+	return s.vnetEventService.Record(ctx, model.VnetEventUpdate, vnet)
+}
+
+// CompilePeers 将登记的设备编译为下发给节点的对端列表，每台设备只允许使用自己的地址
+func CompilePeers(peers []model.VnetPeer, revision int64) v1.NodeVnetPeers {
+	compiled := v1.NodeVnetPeers{Revision: revision, Peers: make([]v1.NodeWireGuardPeer, 0, len(peers))}
+	for i := range peers {
+		compiled.Peers = append(compiled.Peers, v1.NodeWireGuardPeer{
+			ClientId:   peers[i].ClientId,
+			PublicKey:  peers[i].PublicKey,
+			AllowedIps: peerAllowedIps(peers[i].Address),
+		})
+	}
+	return compiled
+}
+
+// peerAllowedIps 设备地址对应的单地址网段（IPv4 为 /32，IPv6 为 /128）
+func peerAllowedIps(address string) []string {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return []string{}
+	}
+	return []string{netip.PrefixFrom(addr, addr.BitLen()).String()}
+}
+
+func toVnetPeerItem(peer *model.VnetPeer) v1.VnetPeerItem {
+	return v1.VnetPeerItem{
+		ClientId:   peer.ClientId,
+		Name:       peer.Name,
+		PublicKey:  peer.PublicKey,
+		Address:    peer.Address,
+		AllowedIps: peerAllowedIps(peer.Address),
+		CreatedAt:  peer.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
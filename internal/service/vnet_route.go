@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+	"net/netip"
+	"time"
+)
+
+// maxRoutesPerVnet 每个虚拟网络最多保存的路由数量（含待批准）
+const maxRoutesPerVnet = 32
+
+// VnetRouteService 成员设备的子网路由与出口节点
+// 设备通告的路由需经批准才会生效；批准或删除已批准的路由时重新编译路由表，递增版本号并随虚拟网络配置下发给节点
+type VnetRouteService interface {
+	GetRoutes(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetRoutesResponseData, error)
+	AdvertiseRoute(ctx context.Context, vnetId string, req *v1.AdvertiseRouteRequest) (*v1.VnetRouteItem, error)
+	ApproveRoute(ctx context.Context, vnetId string, routeId string) (*v1.VnetRouteItem, error)
+	DeleteRoute(ctx context.Context, vnetId string, routeId string) error
+}
+
+func NewVnetRouteService(
+	service *Service,
+	vnetRepository repository.VnetRepository,
+	vnetRouteRepository repository.VnetRouteRepository,
+	vnetEventService VnetEventService,
+) VnetRouteService {
+	return &vnetRouteService{
+		Service:             service,
+		vnetRepository:      vnetRepository,
+		vnetRouteRepository: vnetRouteRepository,
+		vnetEventService:    vnetEventService,
+	}
+}
+
+type vnetRouteService struct {
+	*Service
+	vnetRepository      repository.VnetRepository
+	vnetRouteRepository repository.VnetRouteRepository
+	vnetEventService    VnetEventService
+}
+
+func (s *vnetRouteService) GetRoutes(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetRoutesResponseData, error) {
+	routes, err := s.vnetRouteRepository.GetRoutes(ctx, vnet.VnetId, "")
+	if err != nil {
+		return nil, err
+	}
+	data := &v1.GetVnetRoutesResponseData{
+		Revision:  vnet.RouteRevision,
+		MaxRoutes: maxRoutesPerVnet,
+		Routes:    make([]v1.VnetRouteItem, 0, len(*routes)),
+	}
+	for i := range *routes {
+		data.Routes = append(data.Routes, toVnetRouteItem(&(*routes)[i]))
+	}
+	return data, nil
+}
+
+// AdvertiseRoute 为设备通告路由，路由处于待批准状态，不会下发给节点
+// 明显与网段或已批准路由冲突的路由直接拒绝，免得所有者批准时才发现
+func (s *vnetRouteService) AdvertiseRoute(ctx context.Context, vnetId string, req *v1.AdvertiseRouteRequest) (*v1.VnetRouteItem, error) {
+	prefix, err := parseRoutePrefix(req.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	routeId, err := s.sid.GenString()
+	if err != nil {
+		return nil, err
+	}
+	route := &model.VnetRoute{
+		RouteId:  "route_" + routeId,
+		VnetId:   vnetId,
+		ClientId: req.ClientId,
+		Prefix:   prefix.String(),
+		Exit:     prefix.Bits() == 0,
+		Status:   model.VnetRoutePending,
+		Comment:  req.Comment,
+	}
+
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		routes, err := s.vnetRouteRepository.GetRoutes(ctx, vnetId, "")
+		if err != nil {
+			return err
+		}
+		if len(*routes) >= maxRoutesPerVnet {
+			return v1.ErrRouteLimitExceeded
+		}
+		for _, other := range *routes {
+			if other.ClientId == route.ClientId && other.Prefix == route.Prefix {
+				return v1.ErrRouteConflict
+			}
+		}
+		if err := checkRouteConflict(vnet, route, *routes); err != nil {
+			return err
+		}
+		return s.vnetRouteRepository.CreateRoute(ctx, route)
+	})
+	if err != nil {
+		return nil, err
+	}
+	item := toVnetRouteItem(route)
+	return &item, nil
+}
+
+// ApproveRoute 批准路由并下发新的路由表，已批准的路由直接返回
+func (s *vnetRouteService) ApproveRoute(ctx context.Context, vnetId string, routeId string) (*v1.VnetRouteItem, error) {
+	var route *model.VnetRoute
+	published := false
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		route, err = s.vnetRouteRepository.GetRoute(ctx, vnetId, routeId)
+		if err != nil {
+			return err
+		}
+		if route == nil {
+			return v1.ErrNotFound
+		}
+		if route.Status == model.VnetRouteApproved {
+			return nil
+		}
+		// 通告之后网段或其他路由可能已经变化，批准时重新检查
+		approved, err := s.vnetRouteRepository.GetRoutes(ctx, vnetId, model.VnetRouteApproved)
+		if err != nil {
+			return err
+		}
+		if err := checkRouteConflict(vnet, route, *approved); err != nil {
+			return err
+		}
+		now := time.Now()
+		route.Status = model.VnetRouteApproved
+		route.ApprovedAt = &now
+		if err := s.vnetRouteRepository.UpdateRoute(ctx, route); err != nil {
+			return err
+		}
+		published = true
+		return s.publish(ctx, vnet)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if published {
+		s.vnetEventService.Notify()
+	}
+	item := toVnetRouteItem(route)
+	return &item, nil
+}
+
+// DeleteRoute 删除路由，删除已批准的路由时下发新的路由表
+func (s *vnetRouteService) DeleteRoute(ctx context.Context, vnetId string, routeId string) error {
+	published := false
+	err := s.tm.Transaction(ctx, func(ctx context.Context) error {
+		vnet, err := s.vnetRepository.GetVnetByVnetIdForUpdate(ctx, vnetId)
+		if err != nil {
+			return err
+		}
+		route, err := s.vnetRouteRepository.GetRoute(ctx, vnetId, routeId)
+		if err != nil {
+			return err
+		}
+		if route == nil {
+			return v1.ErrNotFound
+		}
+		if _, err := s.vnetRouteRepository.DeleteRoute(ctx, vnetId, routeId); err != nil {
+			return err
+		}
+		if route.Status != model.VnetRouteApproved {
+			return nil
+		}
+		published = true
+		return s.publish(ctx, vnet)
+	})
+	if err != nil {
+		return err
+	}
+	if published {
+		s.vnetEventService.Notify()
+	}
+	return nil
+}
+
+// publish 重新编译路由表并写入虚拟网络，记录变更事件，需在已锁定虚拟网络记录的事务中调用
+func (s *vnetRouteService) publish(ctx context.Context, vnet *model.Vnet) error {
+	routes, err := s.vnetRouteRepository.GetRoutes(ctx, vnet.VnetId, model.VnetRouteApproved)
+	if err != nil {
+		return err
+	}
+
+	vnet.RouteRevision++
+	encoded, err := json.Marshal(CompileRoutes(*routes, vnet.RouteRevision))
+	if err != nil {
+		return err
+	}
+	vnet.Routes = string(encoded)
+	vnet.NeedUpdate = true
+	vnet.Revision++
+	if err := s.vnetRepository.UpdateVnet(ctx, vnet); err != nil {
+		return err
+	}
+	return s.vnetEventService.Record(ctx, model.VnetEventUpdate, vnet)
+}
+
+// CompileRoutes 将已批准的路由编译为下发给节点的路由表
+func CompileRoutes(routes []model.VnetRoute, revision int64) v1.NodeVnetRoutes {
+	compiled := v1.NodeVnetRoutes{Revision: revision, Routes: make([]v1.NodeRoute, 0, len(routes))}
+	for _, route := range routes {
+		compiled.Routes = append(compiled.Routes, v1.NodeRoute{
+			Prefix:   route.Prefix,
+			ClientId: route.ClientId,
+			Exit:     route.Exit,
+		})
+	}
+	return compiled
+}
+
+// checkRouteConflict 检查路由与虚拟网络网段、其他已批准路由是否冲突，others 中的待批准路由不参与比较
+// 子网路由不能与网段或其他子网路由重叠，否则节点无法确定下一跳；每个地址族只能有一个出口节点
+func checkRouteConflict(vnet *model.Vnet, route *model.VnetRoute, others []model.VnetRoute) error {
+	prefix, err := netip.ParsePrefix(route.Prefix)
+	if err != nil {
+		return v1.ErrInvalidRoute
+	}
+	if !route.Exit {
+		if ipRange, err := netip.ParsePrefix(vnet.IpRange); err == nil && ipRange.Overlaps(prefix) {
+			return v1.ErrRouteConflict
+		}
+	}
+	for _, other := range others {
+		if other.Status != model.VnetRouteApproved || other.RouteId == route.RouteId || other.Exit != route.Exit {
+			continue
+		}
+		otherPrefix, err := netip.ParsePrefix(other.Prefix)
+		if err != nil {
+			continue
+		}
+		if prefix.Overlaps(otherPrefix) {
+			return v1.ErrRouteConflict
+		}
+	}
+	return nil
+}
+
+// parseRoutePrefix 校验路由前缀并转换为网络地址形式，前缀长度为 0 的默认路由表示出口节点
+func parseRoutePrefix(value string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil || prefix.Addr().Zone() != "" {
+		return netip.Prefix{}, v1.ErrInvalidRoute
+	}
+	if prefix.Addr().Is4In6() {
+		return netip.Prefix{}, v1.ErrInvalidRoute
+	}
+	return prefix.Masked(), nil
+}
+
+func toVnetRouteItem(route *model.VnetRoute) v1.VnetRouteItem {
+	item := v1.VnetRouteItem{
+		RouteId:   route.RouteId,
+		ClientId:  route.ClientId,
+		Prefix:    route.Prefix,
+		Exit:      route.Exit,
+		Status:    route.Status,
+		Comment:   route.Comment,
+		CreatedAt: route.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if route.ApprovedAt != nil {
+		item.ApprovedAt = route.ApprovedAt.Format("2006-01-02 15:04:05")
+	}
+	return item
+}
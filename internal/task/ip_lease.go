@@ -0,0 +1,40 @@
+package task
+
+import (
+	"context"
+	"hyacinth-backend/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type IpLeaseTask interface {
+	ReclaimExpiredLeases(ctx context.Context) error
+}
+
+func NewIpLeaseTask(
+	task *Task,
+	ipLeaseRepo repository.IpLeaseRepository,
+) IpLeaseTask {
+	return &ipLeaseTask{
+		Task:        task,
+		ipLeaseRepo: ipLeaseRepo,
+	}
+}
+
+type ipLeaseTask struct {
+	*Task
+	ipLeaseRepo repository.IpLeaseRepository
+}
+
+// ReclaimExpiredLeases 回收过期且客户端已离线的动态租约，释放其占用的地址
+func (t ipLeaseTask) ReclaimExpiredLeases(ctx context.Context) error {
+	count, err := t.ipLeaseRepo.DeleteExpiredLeases(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		t.logger.Info("ReclaimExpiredLeases", zap.Int64("leases", count))
+	}
+	return nil
+}
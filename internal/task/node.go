@@ -0,0 +1,68 @@
+package task
+
+import (
+	"context"
+	"hyacinth-backend/internal/repository"
+	"hyacinth-backend/internal/service"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// defaultHeartbeatTTL 未配置 node.heartbeat_ttl 时节点心跳的有效期
+const defaultHeartbeatTTL = 60 * time.Second
+
+type NodeTask interface {
+	MarkOfflineNodes(ctx context.Context) error
+	ScheduleVnets(ctx context.Context) error
+}
+
+func NewNodeTask(
+	task *Task,
+	conf *viper.Viper,
+	nodeRepo repository.NodeRepository,
+	schedulerService service.SchedulerService,
+) NodeTask {
+	ttl := conf.GetDuration("node.heartbeat_ttl")
+	if ttl <= 0 {
+		ttl = defaultHeartbeatTTL
+	}
+	return &nodeTask{
+		Task:             task,
+		heartbeatTTL:     ttl,
+		nodeRepo:         nodeRepo,
+		schedulerService: schedulerService,
+	}
+}
+
+type nodeTask struct {
+	*Task
+	heartbeatTTL     time.Duration
+	nodeRepo         repository.NodeRepository
+	schedulerService service.SchedulerService
+}
+
+// MarkOfflineNodes 将超过有效期未收到心跳的节点标记为离线
+func (t nodeTask) MarkOfflineNodes(ctx context.Context) error {
+	count, err := t.nodeRepo.MarkOfflineNodes(ctx, time.Now().Add(-t.heartbeatTTL))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		t.logger.Info("MarkOfflineNodes", zap.Int64("nodes", count))
+	}
+	return nil
+}
+
+// ScheduleVnets 为尚未分配或所在节点失效的虚拟网络分配节点，并逐步迁出超载节点
+func (t nodeTask) ScheduleVnets(ctx context.Context) error {
+	moved, err := t.schedulerService.Reconcile(ctx)
+	if err != nil {
+		return err
+	}
+	if moved > 0 {
+		t.logger.Info("ScheduleVnets", zap.Int("vnets", moved))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package task
+
+import (
+	"context"
+	"hyacinth-backend/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type OrderTask interface {
+	CancelExpiredOrders(ctx context.Context) error
+}
+
+func NewOrderTask(
+	task *Task,
+	orderRepo repository.OrderRepository,
+) OrderTask {
+	return &orderTask{
+		Task:      task,
+		orderRepo: orderRepo,
+	}
+}
+
+type orderTask struct {
+	*Task
+	orderRepo repository.OrderRepository
+}
+
+// CancelExpiredOrders 取消超时未付款的订单，之后到账的付款仍会被接受并履约
+func (t orderTask) CancelExpiredOrders(ctx context.Context) error {
+	count, err := t.orderRepo.CancelExpiredOrders(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		t.logger.Info("CancelExpiredOrders", zap.Int64("orders", count))
+	}
+	return nil
+}
@@ -2,30 +2,41 @@ package task
 
 import (
 	"context"
-	"hyacinth-backend/internal/repository"
+	"hyacinth-backend/internal/service"
 )
 
 type UserTask interface {
-	CheckUser(ctx context.Context) error
+	ResetMonthlyTraffic(ctx context.Context) error
+	ExpirePrivileges(ctx context.Context) error
+	ApplyScheduledPlans(ctx context.Context) error
 }
 
 func NewUserTask(
 	task *Task,
-	userRepo repository.UserRepository,
+	subscriptionService service.SubscriptionService,
 ) UserTask {
 	return &userTask{
-		userRepo: userRepo,
-		Task:     task,
+		subscriptionService: subscriptionService,
+		Task:                task,
 	}
 }
 
 type userTask struct {
-	userRepo repository.UserRepository
+	subscriptionService service.SubscriptionService
 	*Task
 }
 
-func (t userTask) CheckUser(ctx context.Context) error {
-	// do something
-	t.logger.Info("CheckUser")
-	return nil
+// ResetMonthlyTraffic 在计费周期起点对应的时刻重置付费用户与组织的月流量
+func (t userTask) ResetMonthlyTraffic(ctx context.Context) error {
+	return t.subscriptionService.ResetMonthlyTraffic(ctx)
+}
+
+// ExpirePrivileges 将特权到期超过宽限期的付费用户与组织降级为免费套餐
+func (t userTask) ExpirePrivileges(ctx context.Context) error {
+	return t.subscriptionService.ExpirePrivileges(ctx)
+}
+
+// ApplyScheduledPlans 将特权已到期、已购买降级的用户与组织切换到购买的套餐
+func (t userTask) ApplyScheduledPlans(ctx context.Context) error {
+	return t.subscriptionService.ApplyScheduledPlans(ctx)
 }
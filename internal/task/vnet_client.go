@@ -0,0 +1,73 @@
+package task
+
+import (
+	"context"
+	"hyacinth-backend/internal/repository"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// defaultClientTTL 未配置 vnet.client_ttl 时客户端会话的有效期
+const defaultClientTTL = 90 * time.Second
+
+type VnetClientTask interface {
+	ExpireStaleClients(ctx context.Context) error
+	DeleteExpiredChallenges(ctx context.Context) error
+}
+
+func NewVnetClientTask(
+	task *Task,
+	conf *viper.Viper,
+	vnetClientRepo repository.VnetClientRepository,
+) VnetClientTask {
+	ttl := conf.GetDuration("vnet.client_ttl")
+	if ttl <= 0 {
+		ttl = defaultClientTTL
+	}
+	return &vnetClientTask{
+		Task:           task,
+		clientTTL:      ttl,
+		vnetClientRepo: vnetClientRepo,
+	}
+}
+
+type vnetClientTask struct {
+	*Task
+	clientTTL      time.Duration
+	vnetClientRepo repository.VnetClientRepository
+}
+
+// ExpireStaleClients 清理超过有效期未收到心跳的客户端会话，并更新相关虚拟网络的在线数量
+func (t vnetClientTask) ExpireStaleClients(ctx context.Context) error {
+	before := time.Now().Add(-t.clientTTL)
+	var vnetIds []string
+	err := t.tm.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		vnetIds, err = t.vnetClientRepo.DeleteStaleVnetClients(ctx, before)
+		if err != nil {
+			return err
+		}
+		return t.vnetClientRepo.SyncClientsOnline(ctx, vnetIds)
+	})
+	if err != nil {
+		return err
+	}
+	if len(vnetIds) > 0 {
+		t.logger.Info("ExpireStaleClients", zap.Int("vnets", len(vnetIds)))
+	}
+	return nil
+}
+
+// DeleteExpiredChallenges 清理已过期的接入挑战记录
+func (t vnetClientTask) DeleteExpiredChallenges(ctx context.Context) error {
+	count, err := t.vnetClientRepo.DeleteExpiredChallenges(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		t.logger.Info("DeleteExpiredChallenges", zap.Int64("challenges", count))
+	}
+	return nil
+}
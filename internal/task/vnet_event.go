@@ -0,0 +1,51 @@
+package task
+
+import (
+	"context"
+	"hyacinth-backend/internal/repository"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// defaultEventRetention 未配置 vnet.event_retention 时变更事件的保留时长
+const defaultEventRetention = 7 * 24 * time.Hour
+
+type VnetEventTask interface {
+	PruneEvents(ctx context.Context) error
+}
+
+func NewVnetEventTask(
+	task *Task,
+	conf *viper.Viper,
+	vnetEventRepo repository.VnetEventRepository,
+) VnetEventTask {
+	retention := conf.GetDuration("vnet.event_retention")
+	if retention <= 0 {
+		retention = defaultEventRetention
+	}
+	return &vnetEventTask{
+		Task:          task,
+		retention:     retention,
+		vnetEventRepo: vnetEventRepo,
+	}
+}
+
+type vnetEventTask struct {
+	*Task
+	retention     time.Duration
+	vnetEventRepo repository.VnetEventRepository
+}
+
+// PruneEvents 清理超过保留期的变更事件，版本号早于保留期的节点订阅时会收到 resync 事件并全量同步
+func (t vnetEventTask) PruneEvents(ctx context.Context) error {
+	count, err := t.vnetEventRepo.DeleteBefore(ctx, time.Now().Add(-t.retention))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		t.logger.Info("PruneEvents", zap.Int64("events", count))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Package clientconf 将虚拟网络的接入信息渲染为各类客户端可直接导入的配置文件
+//
+// 每种格式实现 Renderer 并在 init 中调用 Register 注册，新增格式无需修改调用方。
+package clientconf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// 控制面无法得知的值在配置中以占位符输出，由用户导入后自行填写
+const (
+	PasswordPlaceholder   = "<password>"
+	AddressPlaceholder    = "<address>"
+	PrivateKeyPlaceholder = "<private key>"
+	PublicKeyPlaceholder  = "<node public key>"
+)
+
+// Profile 渲染客户端配置所需的接入信息
+type Profile struct {
+	VnetId          string
+	Comment         string
+	Token           string
+	RequirePassword bool   // 虚拟网络设置了密码，控制面不保存明文，配置中使用占位符
+	ServerAddr      string // 承载节点的公网地址（host:port）
+	NodeId          string
+	IpRange         string
+	PrefixLen       int
+	EnableDHCP      bool
+	Address         string // 设备的保留地址，未保留时为空
+	ClientId        string
+	RequireApproval bool
+	PrivateKey      string // 设备的 WireGuard 私钥，未知时为空
+	PeerPublicKey   string // 节点的 WireGuard 公钥，未知时为空
+}
+
+// Renderer 一种客户端配置格式
+type Renderer interface {
+	// Format 格式标识，用于请求参数
+	Format() string
+	ContentType() string
+	// FileName 下载时使用的文件名
+	FileName(p *Profile) string
+	Render(p *Profile) ([]byte, error)
+}
+
+var (
+	mu        sync.RWMutex
+	renderers = make(map[string]Renderer)
+)
+
+// Register 注册格式，同名格式后注册的覆盖先注册的
+func Register(r Renderer) {
+	mu.Lock()
+	defer mu.Unlock()
+	renderers[r.Format()] = r
+}
+
+// Get 按格式标识获取渲染器
+func Get(format string) (Renderer, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	r, ok := renderers[format]
+	return r, ok
+}
+
+// Formats 已注册的全部格式，按名称排序
+func Formats() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// password 配置中的密码，未设置密码时为空
+func (p *Profile) password() string {
+	if p.RequirePassword {
+		return PasswordPlaceholder
+	}
+	return ""
+}
+
+// cidr 设备地址及前缀长度，未保留地址时为占位符
+func (p *Profile) cidr() string {
+	if p.Address == "" {
+		return AddressPlaceholder
+	}
+	return p.Address + "/" + strconv.Itoa(p.PrefixLen)
+}
+
+// singleLine 将换行等控制字符替换为空格，用户填写的备注等内容不能在逐行解析的配置中注入额外的参数
+func singleLine(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
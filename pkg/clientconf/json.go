@@ -0,0 +1,60 @@
+package clientconf
+
+import "encoding/json"
+
+// jsonProfileVersion JSON 配置的格式版本，字段不兼容变更时递增
+const jsonProfileVersion = 1
+
+func init() {
+	Register(jsonRenderer{})
+}
+
+// jsonRenderer 自有客户端与移动端使用的 JSON 配置，二维码默认使用该格式
+type jsonRenderer struct{}
+
+type jsonProfile struct {
+	Version         int    `json:"version"`
+	VnetId          string `json:"vnetId"`
+	Comment         string `json:"comment"`
+	Server          string `json:"server"`
+	NodeId          string `json:"nodeId"`
+	Token           string `json:"token"`
+	Password        string `json:"password,omitempty"`
+	IpRange         string `json:"ipRange"`
+	Dhcp            bool   `json:"dhcp"`
+	Address         string `json:"address,omitempty"`
+	ClientId        string `json:"clientId,omitempty"`
+	RequireApproval bool   `json:"requireApproval"`
+}
+
+func (jsonRenderer) Format() string {
+	return "json"
+}
+
+func (jsonRenderer) ContentType() string {
+	return "application/json"
+}
+
+func (jsonRenderer) FileName(p *Profile) string {
+	return p.VnetId + ".json"
+}
+
+func (jsonRenderer) Render(p *Profile) ([]byte, error) {
+	profile := jsonProfile{
+		Version:         jsonProfileVersion,
+		VnetId:          p.VnetId,
+		Comment:         p.Comment,
+		Server:          p.ServerAddr,
+		NodeId:          p.NodeId,
+		Token:           p.Token,
+		Password:        p.password(),
+		IpRange:         p.IpRange,
+		Dhcp:            p.EnableDHCP,
+		ClientId:        p.ClientId,
+		RequireApproval: p.RequireApproval,
+	}
+	if p.Address != "" {
+		profile.Address = p.cidr()
+	}
+	return json.Marshal(profile)
+}
@@ -0,0 +1,48 @@
+package clientconf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+func init() {
+	Register(n2nRenderer{})
+}
+
+// n2nRenderer n2n edge 的配置文件，每行一个命令行参数，使用 edge <file> 启动
+type n2nRenderer struct{}
+
+func (n2nRenderer) Format() string {
+	return "n2n"
+}
+
+func (n2nRenderer) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (n2nRenderer) FileName(p *Profile) string {
+	return p.VnetId + ".edge.conf"
+}
+
+func (n2nRenderer) Render(p *Profile) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s (%s)\n", singleLine(p.Comment), p.VnetId)
+	fmt.Fprintf(&buf, "-c=%s\n", singleLine(p.Token))
+	if p.RequirePassword {
+		fmt.Fprintf(&buf, "-k=%s\n", p.password())
+	}
+	fmt.Fprintf(&buf, "-l=%s\n", p.ServerAddr)
+	switch {
+	case p.Address != "":
+		fmt.Fprintf(&buf, "-a=static:%s\n", p.cidr())
+	case p.EnableDHCP:
+		buf.WriteString("-a=dhcp:0.0.0.0\n-r\n")
+	default:
+		// 未开启 DHCP 且没有保留地址时，需要在网段内自选一个地址
+		fmt.Fprintf(&buf, "# %s\n-a=static:%s\n", p.IpRange, p.cidr())
+	}
+	if p.ClientId != "" {
+		fmt.Fprintf(&buf, "-I=%s\n", singleLine(p.ClientId))
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,52 @@
+package clientconf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// wireguardKeepalive 客户端通常位于 NAT 之后，定期发送保活包维持映射
+const wireguardKeepalive = 25
+
+func init() {
+	Register(wireguardRenderer{})
+}
+
+// wireguardRenderer WireGuard 的 .conf 配置，可直接导入官方客户端
+// WireGuard 不支持 DHCP，设备没有保留地址时需要自行填写地址
+type wireguardRenderer struct{}
+
+func (wireguardRenderer) Format() string {
+	return "wireguard"
+}
+
+func (wireguardRenderer) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (wireguardRenderer) FileName(p *Profile) string {
+	return p.VnetId + ".conf"
+}
+
+func (wireguardRenderer) Render(p *Profile) ([]byte, error) {
+	privateKey := p.PrivateKey
+	if privateKey == "" {
+		privateKey = PrivateKeyPlaceholder
+	}
+	peerPublicKey := p.PeerPublicKey
+	if peerPublicKey == "" {
+		peerPublicKey = PublicKeyPlaceholder
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s (%s)\n", singleLine(p.Comment), p.VnetId)
+	buf.WriteString("[Interface]\n")
+	fmt.Fprintf(&buf, "PrivateKey = %s\n", privateKey)
+	fmt.Fprintf(&buf, "Address = %s\n", p.cidr())
+	buf.WriteString("\n[Peer]\n")
+	fmt.Fprintf(&buf, "PublicKey = %s\n", peerPublicKey)
+	fmt.Fprintf(&buf, "Endpoint = %s\n", p.ServerAddr)
+	fmt.Fprintf(&buf, "AllowedIPs = %s\n", p.IpRange)
+	fmt.Fprintf(&buf, "PersistentKeepalive = %d\n", wireguardKeepalive)
+	return buf.Bytes(), nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/spf13/viper"
 	"os"
+	"strings"
 )
 
 func NewConfig(p string) *viper.Viper {
@@ -15,8 +16,12 @@ func NewConfig(p string) *viper.Viper {
 	return getConfig(envConf)
 }
 
+// getConfig 读取配置文件，配置项可由环境变量覆盖，变量名为大写并以下划线代替点号，如 PAYMENT_MOCK_SECRET
+// 密钥等敏感配置应通过环境变量或密钥管理注入，不写入配置文件
 func getConfig(path string) *viper.Viper {
 	conf := viper.New()
+	conf.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	conf.AutomaticEnv()
 	conf.SetConfigFile(path)
 	err := conf.ReadInConfig()
 	if err != nil {
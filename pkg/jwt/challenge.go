@@ -0,0 +1,52 @@
+package jwt
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// challengeSubject 客户端接入挑战的 Subject
+const challengeSubject = "vnet-join-challenge"
+
+// ChallengeClaims 客户端接入虚拟网络时需要应答的挑战
+// 使用节点的签名密钥签名，控制面无需保存挑战即可在准入时校验
+type ChallengeClaims struct {
+	VnetId   string
+	ClientId string
+	jwt.RegisteredClaims
+}
+
+// GenChallenge 签发挑战，Audience 为发起接入的节点，nonce 保证每次挑战都不相同
+func GenChallenge(key []byte, nodeId string, vnetId string, clientId string, nonce string, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, ChallengeClaims{
+		VnetId:   vnetId,
+		ClientId: clientId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   challengeSubject,
+			Audience:  []string{nodeId},
+			ID:        nonce,
+		},
+	})
+	return token.SignedString(key)
+}
+
+// ParseChallenge 校验挑战，并确认其签发给了指定节点
+func ParseChallenge(key []byte, tokenString string, nodeId string) (*ChallengeClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ChallengeClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithSubject(challengeSubject), jwt.WithAudience(nodeId))
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*ChallengeClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid challenge")
+	}
+	return claims, nil
+}
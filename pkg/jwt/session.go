@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// sessionSubject 客户端会话凭证的 Subject
+const sessionSubject = "vnet-session"
+
+// SessionClaims 客户端接入虚拟网络的短期会话凭证
+// 使用节点的签名密钥签名，节点无需回调控制面即可在本地校验
+type SessionClaims struct {
+	VnetId    string
+	ClientId  string
+	VirtualIp string
+	jwt.RegisteredClaims
+}
+
+// NodeSigningKey 由控制面密钥与节点ID派生节点的签名密钥，控制面无需保存节点密钥原文即可签发凭证
+func NodeSigningKey(secret []byte, nodeId string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(nodeId))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenSessionToken 签发会话凭证，Audience 为承载会话的节点
+func GenSessionToken(key []byte, nodeId string, vnetId string, clientId string, virtualIp string, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, SessionClaims{
+		VnetId:    vnetId,
+		ClientId:  clientId,
+		VirtualIp: virtualIp,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   sessionSubject,
+			Audience:  []string{nodeId},
+		},
+	})
+	return token.SignedString(key)
+}
+
+// ParseSessionToken 校验会话凭证，并确认其签发给了指定节点
+func ParseSessionToken(key []byte, tokenString string, nodeId string) (*SessionClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &SessionClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithSubject(sessionSubject), jwt.WithAudience(nodeId))
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*SessionClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid session token")
+	}
+	return claims, nil
+}
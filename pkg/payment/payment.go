@@ -0,0 +1,97 @@
+// Package payment 支付渠道的抽象
+//
+// 控制面为订单向支付渠道发起支付，得到用户跳转的支付地址；渠道在用户付款或退款后回调通知，
+// 通知必须通过渠道的签名校验才会被处理。金额统一以分为单位，避免浮点误差。
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// 回调通知中的支付状态
+const (
+	StatusPaid     = "paid"
+	StatusRefunded = "refunded"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid payment notification signature")
+	// ErrUnavailable 未配置支付渠道，无法发起支付
+	ErrUnavailable = errors.New("no payment provider is configured")
+)
+
+// PaymentProvider 支付渠道
+type PaymentProvider interface {
+	// Name 渠道标识，回调地址按此区分渠道
+	Name() string
+	// CreatePayment 为订单发起支付，返回用户跳转的支付地址
+	CreatePayment(ctx context.Context, p *Payment) (string, error)
+	// ParseNotification 校验回调签名并解析通知，签名无效时返回 ErrInvalidSignature
+	ParseNotification(header http.Header, body []byte) (*Notification, error)
+}
+
+// Payment 发起支付的参数
+type Payment struct {
+	OrderId   string
+	Subject   string
+	Amount    int64 // 分
+	Currency  string
+	ExpiresAt time.Time
+}
+
+// Notification 渠道回调的支付结果
+type Notification struct {
+	OrderId string `json:"orderId"`
+	TradeNo string `json:"tradeNo"` // 渠道流水号
+	Amount  int64  `json:"amount"`
+	Status  string `json:"status"`
+}
+
+// NewPaymentProvider 按 payment.provider 配置创建支付渠道，未知的渠道直接拒绝启动
+// 未配置渠道时服务照常启动，只是不能下单购买；模拟网关访问支付地址即视为付款，
+// 须显式开启 payment.mock.enabled 才会使用，否则同样视为未配置
+func NewPaymentProvider(conf *viper.Viper) PaymentProvider {
+	switch provider := conf.GetString("payment.provider"); provider {
+	case MockName:
+		secret := conf.GetString("payment.mock.secret")
+		if !MockAllowed(conf) || secret == "" {
+			return disabledProvider{}
+		}
+		return NewMockGateway(secret, conf.GetString("payment.mock.checkout_url"))
+	case "":
+		return disabledProvider{}
+	default:
+		panic("unknown payment provider: " + provider)
+	}
+}
+
+// MockAllowed 是否使用模拟网关且已显式开启，模拟支付页只在此时注册
+func MockAllowed(conf *viper.Viper) bool {
+	return conf.GetString("payment.provider") == MockName && conf.GetBool("payment.mock.enabled")
+}
+
+// Available 支付渠道是否可以发起支付
+func Available(p PaymentProvider) bool {
+	_, disabled := p.(disabledProvider)
+	return !disabled
+}
+
+// disabledProvider 未配置支付渠道时使用，拒绝发起支付与回调
+type disabledProvider struct{}
+
+func (disabledProvider) Name() string {
+	return ""
+}
+
+func (disabledProvider) CreatePayment(ctx context.Context, p *Payment) (string, error) {
+	return "", ErrUnavailable
+}
+
+func (disabledProvider) ParseNotification(header http.Header, body []byte) (*Notification, error) {
+	return nil, ErrUnavailable
+}
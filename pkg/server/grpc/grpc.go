@@ -5,27 +5,38 @@ import (
 	"fmt"
 	"hyacinth-backend/pkg/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"net"
 	"time"
 )
 
 type Server struct {
 	*grpc.Server
-	host   string
-	port   int
-	logger *log.Logger
+	host       string
+	port       int
+	logger     *log.Logger
+	serverOpts []grpc.ServerOption
+	certFile   string
+	keyFile    string
 }
 
 type Option func(s *Server)
 
 func NewServer(logger *log.Logger, opts ...Option) *Server {
 	s := &Server{
-		Server: grpc.NewServer(),
 		logger: logger,
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.certFile != "" || s.keyFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(s.certFile, s.keyFile)
+		if err != nil {
+			s.logger.Sugar().Fatalf("Failed to load TLS credentials: %v", err)
+		}
+		s.serverOpts = append(s.serverOpts, grpc.Creds(creds))
+	}
+	s.Server = grpc.NewServer(s.serverOpts...)
 	return s
 }
 func WithServerHost(host string) Option {
@@ -39,6 +50,25 @@ func WithServerPort(port int) Option {
 	}
 }
 
+// WithTLS 使用证书与私钥文件提供 TLS 服务，未设置时为明文
+func WithTLS(certFile string, keyFile string) Option {
+	return func(s *Server) {
+		s.certFile = certFile
+		s.keyFile = keyFile
+	}
+}
+
+// TLSEnabled 是否以 TLS 提供服务
+func (s *Server) TLSEnabled() bool {
+	return s.certFile != "" || s.keyFile != ""
+}
+
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(s *Server) {
+		s.serverOpts = append(s.serverOpts, opts...)
+	}
+}
+
 func (s *Server) Start(ctx context.Context) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.host, s.port))
 	if err != nil {
@@ -1,6 +1,8 @@
 package sid
 
 import (
+	"crypto/rand"
+
 	"github.com/sony/sonyflake"
 )
 
@@ -25,3 +27,25 @@ func (s Sid) GenString() (string, error) {
 func (s Sid) GenUint64() (uint64, error) {
 	return s.sf.NextID()
 }
+
+// GenCode 生成可放入 URL 的邀请码等凭据：唯一 ID 后接 randomLength 位随机字符，保证唯一且无法猜测
+func (s Sid) GenCode(randomLength int) (string, error) {
+	id, err := s.GenString()
+	if err != nil {
+		return "", err
+	}
+	code := make([]byte, 0, randomLength)
+	buf := make([]byte, randomLength)
+	for len(code) < randomLength {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			// 丢弃超出 62 整数倍的取值，避免取模偏差
+			if int(b) < 256-256%len(base62) && len(code) < randomLength {
+				code = append(code, base62[int(b)%len(base62)])
+			}
+		}
+	}
+	return id + string(code), nil
+}
@@ -0,0 +1,138 @@
+// Package vnetpass 虚拟网络密码的存储与挑战应答校验
+//
+// 密码经 argon2id 加盐派生后按 SCRAM（RFC 5802）的方式保存：
+//
+//	SaltedPassword = argon2id(password, salt, t, m, p, 32)
+//	ClientKey      = HMAC-SHA256(SaltedPassword, "Client Key")
+//	StoredKey      = SHA256(ClientKey)
+//
+// 控制面与节点只保存 StoredKey，无法还原出密码，也无法据此伪造应答。
+// 客户端收到挑战后计算 ClientProof = ClientKey XOR HMAC-SHA256(StoredKey, AuthMessage)，
+// 校验方用 StoredKey 还原出 ClientKey 并比较其哈希，密码明文不会离开客户端。
+package vnetpass
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Scheme 校验值的格式标识
+const Scheme = "argon2id-scram"
+
+// 生成新校验值时使用的 argon2id 参数
+const (
+	DefaultTime    uint32 = 2
+	DefaultMemory  uint32 = 19 * 1024 // KiB
+	DefaultThreads uint8  = 1
+
+	saltLength = 16
+	keyLength  = 32
+)
+
+var errInvalidVerifier = errors.New("invalid password verifier")
+
+// Verifier 密码校验值
+type Verifier struct {
+	Time      uint32
+	Memory    uint32
+	Threads   uint8
+	Salt      []byte
+	StoredKey []byte
+}
+
+// Hash 使用随机盐为密码生成校验值，返回可直接保存的编码字符串
+func Hash(password string) (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	v := &Verifier{Time: DefaultTime, Memory: DefaultMemory, Threads: DefaultThreads, Salt: salt}
+	v.StoredKey = storedKey(ClientKey(password, v.Salt, v.Time, v.Memory, v.Threads))
+	return v.String(), nil
+}
+
+// Parse 解析 Hash 生成的编码字符串
+func Parse(encoded string) (*Verifier, error) {
+	// $argon2id-scram$t=2,m=19456,p=1$<salt>$<stored key>
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 || parts[0] != "" || parts[1] != Scheme {
+		return nil, errInvalidVerifier
+	}
+	v := &Verifier{}
+	if _, err := fmt.Sscanf(parts[2], "t=%d,m=%d,p=%d", &v.Time, &v.Memory, &v.Threads); err != nil {
+		return nil, errInvalidVerifier
+	}
+	var err error
+	if v.Salt, err = base64.RawStdEncoding.DecodeString(parts[3]); err != nil {
+		return nil, errInvalidVerifier
+	}
+	if v.StoredKey, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil || len(v.StoredKey) != sha256.Size {
+		return nil, errInvalidVerifier
+	}
+	return v, nil
+}
+
+func (v *Verifier) String() string {
+	return fmt.Sprintf("$%s$t=%d,m=%d,p=%d$%s$%s", Scheme, v.Time, v.Memory, v.Threads,
+		base64.RawStdEncoding.EncodeToString(v.Salt), base64.RawStdEncoding.EncodeToString(v.StoredKey))
+}
+
+// VerifyPassword 校验密码明文，仅用于尚不支持挑战应答的旧版节点
+func (v *Verifier) VerifyPassword(password string) bool {
+	expected := storedKey(ClientKey(password, v.Salt, v.Time, v.Memory, v.Threads))
+	return subtle.ConstantTimeCompare(expected, v.StoredKey) == 1
+}
+
+// VerifyProof 校验客户端针对 authMessage 计算的应答
+func (v *Verifier) VerifyProof(authMessage string, proof []byte) bool {
+	if len(proof) != sha256.Size {
+		return false
+	}
+	clientKey := xor(proof, signature(v.StoredKey, authMessage))
+	return subtle.ConstantTimeCompare(storedKey(clientKey), v.StoredKey) == 1
+}
+
+// ClientKey 由密码派生客户端密钥，客户端在本地计算
+func ClientKey(password string, salt []byte, time uint32, memory uint32, threads uint8) []byte {
+	salted := argon2.IDKey([]byte(password), salt, time, memory, threads, keyLength)
+	mac := hmac.New(sha256.New, salted)
+	mac.Write([]byte("Client Key"))
+	return mac.Sum(nil)
+}
+
+// ClientProof 客户端使用派生密钥对 authMessage 计算应答
+func ClientProof(clientKey []byte, authMessage string) []byte {
+	return xor(clientKey, signature(storedKey(clientKey), authMessage))
+}
+
+// AuthMessage 应答所签名的内容，绑定接入令牌、设备标识与挑战
+func AuthMessage(token string, clientId string, challenge string) string {
+	return token + "," + clientId + "," + challenge
+}
+
+func storedKey(clientKey []byte) []byte {
+	sum := sha256.Sum256(clientKey)
+	return sum[:]
+}
+
+func signature(storedKey []byte, authMessage string) []byte {
+	mac := hmac.New(sha256.New, storedKey)
+	mac.Write([]byte(authMessage))
+	return mac.Sum(nil)
+}
+
+func xor(a []byte, b []byte) []byte {
+	result := make([]byte, len(a))
+	for i := range a {
+		result[i] = a[i] ^ b[i]
+	}
+	return result
+}
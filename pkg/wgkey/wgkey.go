@@ -0,0 +1,51 @@
+// Package wgkey WireGuard 密钥的生成与校验
+//
+// 密钥为 Curve25519 的 32 字节标量或点，按 WireGuard 工具的惯例以标准 Base64 编码传输和保存。
+// 私钥应尽量由客户端生成（wg genkey），控制面只保存公钥；
+// 无法生成密钥的客户端可由控制面代为生成，私钥只返回一次，不保存也不写入日志。
+package wgkey
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// KeyLen 密钥的字节长度
+const KeyLen = 32
+
+var ErrInvalidKey = errors.New("invalid wireguard key")
+
+// GenerateKeyPair 生成一对密钥，返回 Base64 编码的私钥与公钥
+func GenerateKeyPair() (privateKey string, publicKey string, err error) {
+	var private [KeyLen]byte
+	if _, err := rand.Read(private[:]); err != nil {
+		return "", "", err
+	}
+	// 按 RFC 7748 对标量进行钳制，与 wg genkey 的输出一致
+	private[0] &= 248
+	private[31] &= 127
+	private[31] |= 64
+
+	public, err := curve25519.X25519(private[:], curve25519.Basepoint)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(private[:]), base64.StdEncoding.EncodeToString(public), nil
+}
+
+// ParsePublicKey 校验 Base64 编码的公钥并返回规范形式
+// 全零的点是低阶点，使用它的握手得不到有效的共享密钥，一并拒绝
+func ParsePublicKey(key string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(decoded) != KeyLen {
+		return "", ErrInvalidKey
+	}
+	var zero [KeyLen]byte
+	if string(decoded) == string(zero[:]) {
+		return "", ErrInvalidKey
+	}
+	return base64.StdEncoding.EncodeToString(decoded), nil
+}
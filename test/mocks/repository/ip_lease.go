@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/ip_lease.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIpLeaseRepository is a mock of IpLeaseRepository interface.
+type MockIpLeaseRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIpLeaseRepositoryMockRecorder
+}
+
+// MockIpLeaseRepositoryMockRecorder is the mock recorder for MockIpLeaseRepository.
+type MockIpLeaseRepositoryMockRecorder struct {
+	mock *MockIpLeaseRepository
+}
+
+// NewMockIpLeaseRepository creates a new mock instance.
+func NewMockIpLeaseRepository(ctrl *gomock.Controller) *MockIpLeaseRepository {
+	mock := &MockIpLeaseRepository{ctrl: ctrl}
+	mock.recorder = &MockIpLeaseRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIpLeaseRepository) EXPECT() *MockIpLeaseRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateLease mocks base method.
+func (m *MockIpLeaseRepository) CreateLease(ctx context.Context, lease *model.IpLease) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLease", ctx, lease)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLease indicates an expected call of CreateLease.
+func (mr *MockIpLeaseRepositoryMockRecorder) CreateLease(ctx, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLease", reflect.TypeOf((*MockIpLeaseRepository)(nil).CreateLease), ctx, lease)
+}
+
+// DeleteExpiredLeases mocks base method.
+func (m *MockIpLeaseRepository) DeleteExpiredLeases(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredLeases", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredLeases indicates an expected call of DeleteExpiredLeases.
+func (mr *MockIpLeaseRepositoryMockRecorder) DeleteExpiredLeases(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredLeases", reflect.TypeOf((*MockIpLeaseRepository)(nil).DeleteExpiredLeases), ctx, now)
+}
+
+// DeleteLeaseByClientId mocks base method.
+func (m *MockIpLeaseRepository) DeleteLeaseByClientId(ctx context.Context, vnetId, clientId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLeaseByClientId", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteLeaseByClientId indicates an expected call of DeleteLeaseByClientId.
+func (mr *MockIpLeaseRepositoryMockRecorder) DeleteLeaseByClientId(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLeaseByClientId", reflect.TypeOf((*MockIpLeaseRepository)(nil).DeleteLeaseByClientId), ctx, vnetId, clientId)
+}
+
+// DeleteLeasesByAddress mocks base method.
+func (m *MockIpLeaseRepository) DeleteLeasesByAddress(ctx context.Context, vnetId, address, exceptClientId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLeasesByAddress", ctx, vnetId, address, exceptClientId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLeasesByAddress indicates an expected call of DeleteLeasesByAddress.
+func (mr *MockIpLeaseRepositoryMockRecorder) DeleteLeasesByAddress(ctx, vnetId, address, exceptClientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLeasesByAddress", reflect.TypeOf((*MockIpLeaseRepository)(nil).DeleteLeasesByAddress), ctx, vnetId, address, exceptClientId)
+}
+
+// GetLeaseByClientId mocks base method.
+func (m *MockIpLeaseRepository) GetLeaseByClientId(ctx context.Context, vnetId, clientId string) (*model.IpLease, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeaseByClientId", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(*model.IpLease)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeaseByClientId indicates an expected call of GetLeaseByClientId.
+func (mr *MockIpLeaseRepositoryMockRecorder) GetLeaseByClientId(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeaseByClientId", reflect.TypeOf((*MockIpLeaseRepository)(nil).GetLeaseByClientId), ctx, vnetId, clientId)
+}
+
+// GetLeasesByVnetId mocks base method.
+func (m *MockIpLeaseRepository) GetLeasesByVnetId(ctx context.Context, vnetId string) (*[]model.IpLease, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeasesByVnetId", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.IpLease)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeasesByVnetId indicates an expected call of GetLeasesByVnetId.
+func (mr *MockIpLeaseRepositoryMockRecorder) GetLeasesByVnetId(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeasesByVnetId", reflect.TypeOf((*MockIpLeaseRepository)(nil).GetLeasesByVnetId), ctx, vnetId)
+}
+
+// UpdateLease mocks base method.
+func (m *MockIpLeaseRepository) UpdateLease(ctx context.Context, lease *model.IpLease) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLease", ctx, lease)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLease indicates an expected call of UpdateLease.
+func (mr *MockIpLeaseRepositoryMockRecorder) UpdateLease(ctx, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLease", reflect.TypeOf((*MockIpLeaseRepository)(nil).UpdateLease), ctx, lease)
+}
@@ -0,0 +1,170 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/node.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockNodeRepository is a mock of NodeRepository interface.
+type MockNodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNodeRepositoryMockRecorder
+}
+
+// MockNodeRepositoryMockRecorder is the mock recorder for MockNodeRepository.
+type MockNodeRepositoryMockRecorder struct {
+	mock *MockNodeRepository
+}
+
+// NewMockNodeRepository creates a new mock instance.
+func NewMockNodeRepository(ctrl *gomock.Controller) *MockNodeRepository {
+	mock := &MockNodeRepository{ctrl: ctrl}
+	mock.recorder = &MockNodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNodeRepository) EXPECT() *MockNodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeBootstrapToken mocks base method.
+func (m *MockNodeRepository) ConsumeBootstrapToken(ctx context.Context, tokenHash, nodeId string, now time.Time) (*model.NodeBootstrapToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeBootstrapToken", ctx, tokenHash, nodeId, now)
+	ret0, _ := ret[0].(*model.NodeBootstrapToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeBootstrapToken indicates an expected call of ConsumeBootstrapToken.
+func (mr *MockNodeRepositoryMockRecorder) ConsumeBootstrapToken(ctx, tokenHash, nodeId, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeBootstrapToken", reflect.TypeOf((*MockNodeRepository)(nil).ConsumeBootstrapToken), ctx, tokenHash, nodeId, now)
+}
+
+// CreateBootstrapToken mocks base method.
+func (m *MockNodeRepository) CreateBootstrapToken(ctx context.Context, token *model.NodeBootstrapToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBootstrapToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBootstrapToken indicates an expected call of CreateBootstrapToken.
+func (mr *MockNodeRepositoryMockRecorder) CreateBootstrapToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBootstrapToken", reflect.TypeOf((*MockNodeRepository)(nil).CreateBootstrapToken), ctx, token)
+}
+
+// CreateNode mocks base method.
+func (m *MockNodeRepository) CreateNode(ctx context.Context, node *model.Node) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNode", ctx, node)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNode indicates an expected call of CreateNode.
+func (mr *MockNodeRepositoryMockRecorder) CreateNode(ctx, node interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNode", reflect.TypeOf((*MockNodeRepository)(nil).CreateNode), ctx, node)
+}
+
+// DeleteNode mocks base method.
+func (m *MockNodeRepository) DeleteNode(ctx context.Context, nodeId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNode", ctx, nodeId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteNode indicates an expected call of DeleteNode.
+func (mr *MockNodeRepositoryMockRecorder) DeleteNode(ctx, nodeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNode", reflect.TypeOf((*MockNodeRepository)(nil).DeleteNode), ctx, nodeId)
+}
+
+// GetNodeByNodeId mocks base method.
+func (m *MockNodeRepository) GetNodeByNodeId(ctx context.Context, nodeId string) (*model.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNodeByNodeId", ctx, nodeId)
+	ret0, _ := ret[0].(*model.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNodeByNodeId indicates an expected call of GetNodeByNodeId.
+func (mr *MockNodeRepositoryMockRecorder) GetNodeByNodeId(ctx, nodeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodeByNodeId", reflect.TypeOf((*MockNodeRepository)(nil).GetNodeByNodeId), ctx, nodeId)
+}
+
+// ListNodes mocks base method.
+func (m *MockNodeRepository) ListNodes(ctx context.Context) (*[]model.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNodes", ctx)
+	ret0, _ := ret[0].(*[]model.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNodes indicates an expected call of ListNodes.
+func (mr *MockNodeRepositoryMockRecorder) ListNodes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNodes", reflect.TypeOf((*MockNodeRepository)(nil).ListNodes), ctx)
+}
+
+// MarkOfflineNodes mocks base method.
+func (m *MockNodeRepository) MarkOfflineNodes(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOfflineNodes", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkOfflineNodes indicates an expected call of MarkOfflineNodes.
+func (mr *MockNodeRepositoryMockRecorder) MarkOfflineNodes(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOfflineNodes", reflect.TypeOf((*MockNodeRepository)(nil).MarkOfflineNodes), ctx, before)
+}
+
+// SetDraining mocks base method.
+func (m *MockNodeRepository) SetDraining(ctx context.Context, nodeId string, draining bool) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDraining", ctx, nodeId, draining)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetDraining indicates an expected call of SetDraining.
+func (mr *MockNodeRepositoryMockRecorder) SetDraining(ctx, nodeId, draining interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDraining", reflect.TypeOf((*MockNodeRepository)(nil).SetDraining), ctx, nodeId, draining)
+}
+
+// UpdateHeartbeat mocks base method.
+func (m *MockNodeRepository) UpdateHeartbeat(ctx context.Context, node *model.Node) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHeartbeat", ctx, node)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateHeartbeat indicates an expected call of UpdateHeartbeat.
+func (mr *MockNodeRepositoryMockRecorder) UpdateHeartbeat(ctx, node interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHeartbeat", reflect.TypeOf((*MockNodeRepository)(nil).UpdateHeartbeat), ctx, node)
+}
@@ -0,0 +1,244 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/organization.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOrganizationRepository is a mock of OrganizationRepository interface.
+type MockOrganizationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationRepositoryMockRecorder
+}
+
+// MockOrganizationRepositoryMockRecorder is the mock recorder for MockOrganizationRepository.
+type MockOrganizationRepositoryMockRecorder struct {
+	mock *MockOrganizationRepository
+}
+
+// NewMockOrganizationRepository creates a new mock instance.
+func NewMockOrganizationRepository(ctrl *gomock.Controller) *MockOrganizationRepository {
+	mock := &MockOrganizationRepository{ctrl: ctrl}
+	mock.recorder = &MockOrganizationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationRepository) EXPECT() *MockOrganizationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateOrganization mocks base method.
+func (m *MockOrganizationRepository) CreateOrganization(ctx context.Context, org *model.Organization) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", ctx, org)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockOrganizationRepositoryMockRecorder) CreateOrganization(ctx, org interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockOrganizationRepository)(nil).CreateOrganization), ctx, org)
+}
+
+// DebitTraffic mocks base method.
+func (m *MockOrganizationRepository) DebitTraffic(ctx context.Context, orgId string, bytes int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DebitTraffic", ctx, orgId, bytes)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DebitTraffic indicates an expected call of DebitTraffic.
+func (mr *MockOrganizationRepositoryMockRecorder) DebitTraffic(ctx, orgId, bytes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DebitTraffic", reflect.TypeOf((*MockOrganizationRepository)(nil).DebitTraffic), ctx, orgId, bytes)
+}
+
+// DeleteMember mocks base method.
+func (m *MockOrganizationRepository) DeleteMember(ctx context.Context, orgId, userId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMember", ctx, orgId, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMember indicates an expected call of DeleteMember.
+func (mr *MockOrganizationRepositoryMockRecorder) DeleteMember(ctx, orgId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMember", reflect.TypeOf((*MockOrganizationRepository)(nil).DeleteMember), ctx, orgId, userId)
+}
+
+// GetMember mocks base method.
+func (m *MockOrganizationRepository) GetMember(ctx context.Context, orgId, userId string) (*model.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMember", ctx, orgId, userId)
+	ret0, _ := ret[0].(*model.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMember indicates an expected call of GetMember.
+func (mr *MockOrganizationRepositoryMockRecorder) GetMember(ctx, orgId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMember", reflect.TypeOf((*MockOrganizationRepository)(nil).GetMember), ctx, orgId, userId)
+}
+
+// GetMembers mocks base method.
+func (m *MockOrganizationRepository) GetMembers(ctx context.Context, orgId string) (*[]model.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", ctx, orgId)
+	ret0, _ := ret[0].(*[]model.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockOrganizationRepositoryMockRecorder) GetMembers(ctx, orgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockOrganizationRepository)(nil).GetMembers), ctx, orgId)
+}
+
+// GetMembershipsByUserId mocks base method.
+func (m *MockOrganizationRepository) GetMembershipsByUserId(ctx context.Context, userId string) (*[]model.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembershipsByUserId", ctx, userId)
+	ret0, _ := ret[0].(*[]model.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembershipsByUserId indicates an expected call of GetMembershipsByUserId.
+func (mr *MockOrganizationRepositoryMockRecorder) GetMembershipsByUserId(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembershipsByUserId", reflect.TypeOf((*MockOrganizationRepository)(nil).GetMembershipsByUserId), ctx, userId)
+}
+
+// GetOrganization mocks base method.
+func (m *MockOrganizationRepository) GetOrganization(ctx context.Context, orgId string) (*model.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganization", ctx, orgId)
+	ret0, _ := ret[0].(*model.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganization indicates an expected call of GetOrganization.
+func (mr *MockOrganizationRepositoryMockRecorder) GetOrganization(ctx, orgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganization", reflect.TypeOf((*MockOrganizationRepository)(nil).GetOrganization), ctx, orgId)
+}
+
+// GetOrganizationForUpdate mocks base method.
+func (m *MockOrganizationRepository) GetOrganizationForUpdate(ctx context.Context, orgId string) (*model.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationForUpdate", ctx, orgId)
+	ret0, _ := ret[0].(*model.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationForUpdate indicates an expected call of GetOrganizationForUpdate.
+func (mr *MockOrganizationRepositoryMockRecorder) GetOrganizationForUpdate(ctx, orgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationForUpdate", reflect.TypeOf((*MockOrganizationRepository)(nil).GetOrganizationForUpdate), ctx, orgId)
+}
+
+// GetOrganizationsByOrgIds mocks base method.
+func (m *MockOrganizationRepository) GetOrganizationsByOrgIds(ctx context.Context, orgIds []string) (*[]model.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationsByOrgIds", ctx, orgIds)
+	ret0, _ := ret[0].(*[]model.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationsByOrgIds indicates an expected call of GetOrganizationsByOrgIds.
+func (mr *MockOrganizationRepositoryMockRecorder) GetOrganizationsByOrgIds(ctx, orgIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationsByOrgIds", reflect.TypeOf((*MockOrganizationRepository)(nil).GetOrganizationsByOrgIds), ctx, orgIds)
+}
+
+// GetPrivilegeExpired mocks base method.
+func (m *MockOrganizationRepository) GetPrivilegeExpired(ctx context.Context, before time.Time, limit int) (*[]model.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrivilegeExpired", ctx, before, limit)
+	ret0, _ := ret[0].(*[]model.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrivilegeExpired indicates an expected call of GetPrivilegeExpired.
+func (mr *MockOrganizationRepositoryMockRecorder) GetPrivilegeExpired(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivilegeExpired", reflect.TypeOf((*MockOrganizationRepository)(nil).GetPrivilegeExpired), ctx, before, limit)
+}
+
+// GetScheduledPlanDue mocks base method.
+func (m *MockOrganizationRepository) GetScheduledPlanDue(ctx context.Context, now time.Time, limit int) (*[]model.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledPlanDue", ctx, now, limit)
+	ret0, _ := ret[0].(*[]model.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledPlanDue indicates an expected call of GetScheduledPlanDue.
+func (mr *MockOrganizationRepositoryMockRecorder) GetScheduledPlanDue(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledPlanDue", reflect.TypeOf((*MockOrganizationRepository)(nil).GetScheduledPlanDue), ctx, now, limit)
+}
+
+// GetTrafficResetDue mocks base method.
+func (m *MockOrganizationRepository) GetTrafficResetDue(ctx context.Context, now time.Time, limit int) (*[]model.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrafficResetDue", ctx, now, limit)
+	ret0, _ := ret[0].(*[]model.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrafficResetDue indicates an expected call of GetTrafficResetDue.
+func (mr *MockOrganizationRepositoryMockRecorder) GetTrafficResetDue(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrafficResetDue", reflect.TypeOf((*MockOrganizationRepository)(nil).GetTrafficResetDue), ctx, now, limit)
+}
+
+// SaveMember mocks base method.
+func (m *MockOrganizationRepository) SaveMember(ctx context.Context, member *model.OrganizationMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMember indicates an expected call of SaveMember.
+func (mr *MockOrganizationRepositoryMockRecorder) SaveMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMember", reflect.TypeOf((*MockOrganizationRepository)(nil).SaveMember), ctx, member)
+}
+
+// UpdateOrganization mocks base method.
+func (m *MockOrganizationRepository) UpdateOrganization(ctx context.Context, org *model.Organization) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrganization", ctx, org)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrganization indicates an expected call of UpdateOrganization.
+func (mr *MockOrganizationRepositoryMockRecorder) UpdateOrganization(ctx, org interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrganization", reflect.TypeOf((*MockOrganizationRepository)(nil).UpdateOrganization), ctx, org)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/plan.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPlanRepository is a mock of PlanRepository interface.
+type MockPlanRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPlanRepositoryMockRecorder
+}
+
+// MockPlanRepositoryMockRecorder is the mock recorder for MockPlanRepository.
+type MockPlanRepositoryMockRecorder struct {
+	mock *MockPlanRepository
+}
+
+// NewMockPlanRepository creates a new mock instance.
+func NewMockPlanRepository(ctrl *gomock.Controller) *MockPlanRepository {
+	mock := &MockPlanRepository{ctrl: ctrl}
+	mock.recorder = &MockPlanRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPlanRepository) EXPECT() *MockPlanRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetPlans mocks base method.
+func (m *MockPlanRepository) GetPlans(ctx context.Context) (*[]model.Plan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlans", ctx)
+	ret0, _ := ret[0].(*[]model.Plan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlans indicates an expected call of GetPlans.
+func (mr *MockPlanRepositoryMockRecorder) GetPlans(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlans", reflect.TypeOf((*MockPlanRepository)(nil).GetPlans), ctx)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/traffic_reset.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTrafficResetRepository is a mock of TrafficResetRepository interface.
+type MockTrafficResetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrafficResetRepositoryMockRecorder
+}
+
+// MockTrafficResetRepositoryMockRecorder is the mock recorder for MockTrafficResetRepository.
+type MockTrafficResetRepositoryMockRecorder struct {
+	mock *MockTrafficResetRepository
+}
+
+// NewMockTrafficResetRepository creates a new mock instance.
+func NewMockTrafficResetRepository(ctrl *gomock.Controller) *MockTrafficResetRepository {
+	mock := &MockTrafficResetRepository{ctrl: ctrl}
+	mock.recorder = &MockTrafficResetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrafficResetRepository) EXPECT() *MockTrafficResetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateReset mocks base method.
+func (m *MockTrafficResetRepository) CreateReset(ctx context.Context, reset *model.TrafficReset) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReset", ctx, reset)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReset indicates an expected call of CreateReset.
+func (mr *MockTrafficResetRepositoryMockRecorder) CreateReset(ctx, reset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReset", reflect.TypeOf((*MockTrafficResetRepository)(nil).CreateReset), ctx, reset)
+}
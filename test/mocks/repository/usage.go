@@ -7,6 +7,7 @@ package mock_repository
 import (
 	context "context"
 	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,34 @@ func (m *MockUsageRepository) EXPECT() *MockUsageRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CreateUsageBatch mocks base method.
+func (m *MockUsageRepository) CreateUsageBatch(ctx context.Context, batch *model.UsageBatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUsageBatch", ctx, batch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUsageBatch indicates an expected call of CreateUsageBatch.
+func (mr *MockUsageRepositoryMockRecorder) CreateUsageBatch(ctx, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUsageBatch", reflect.TypeOf((*MockUsageRepository)(nil).CreateUsageBatch), ctx, batch)
+}
+
+// CreateUsages mocks base method.
+func (m *MockUsageRepository) CreateUsages(ctx context.Context, usages []model.Usage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUsages", ctx, usages)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUsages indicates an expected call of CreateUsages.
+func (mr *MockUsageRepositoryMockRecorder) CreateUsages(ctx, usages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUsages", reflect.TypeOf((*MockUsageRepository)(nil).CreateUsages), ctx, usages)
+}
+
 // GetUsage mocks base method.
 func (m *MockUsageRepository) GetUsage(ctx context.Context, userId, vnetId, timeRange string) (*[]v1.UsageData, error) {
 	m.ctrl.T.Helper()
@@ -49,3 +78,18 @@ func (mr *MockUsageRepositoryMockRecorder) GetUsage(ctx, userId, vnetId, timeRan
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockUsageRepository)(nil).GetUsage), ctx, userId, vnetId, timeRange)
 }
+
+// GetUsageBatch mocks base method.
+func (m *MockUsageRepository) GetUsageBatch(ctx context.Context, nodeId, batchId string) (*model.UsageBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsageBatch", ctx, nodeId, batchId)
+	ret0, _ := ret[0].(*model.UsageBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsageBatch indicates an expected call of GetUsageBatch.
+func (mr *MockUsageRepositoryMockRecorder) GetUsageBatch(ctx, nodeId, batchId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsageBatch", reflect.TypeOf((*MockUsageRepository)(nil).GetUsageBatch), ctx, nodeId, batchId)
+}
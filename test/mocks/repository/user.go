@@ -8,6 +8,7 @@ import (
 	context "context"
 	model "hyacinth-backend/internal/model"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -49,6 +50,21 @@ func (mr *MockUserRepositoryMockRecorder) Create(ctx, user interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepository)(nil).Create), ctx, user)
 }
 
+// DebitTraffic mocks base method.
+func (m *MockUserRepository) DebitTraffic(ctx context.Context, id string, bytes int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DebitTraffic", ctx, id, bytes)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DebitTraffic indicates an expected call of DebitTraffic.
+func (mr *MockUserRepositoryMockRecorder) DebitTraffic(ctx, id, bytes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DebitTraffic", reflect.TypeOf((*MockUserRepository)(nil).DebitTraffic), ctx, id, bytes)
+}
+
 // GetByEmail mocks base method.
 func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +95,21 @@ func (mr *MockUserRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserRepository)(nil).GetByID), ctx, id)
 }
 
+// GetByIDForUpdate mocks base method.
+func (m *MockUserRepository) GetByIDForUpdate(ctx context.Context, id string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDForUpdate indicates an expected call of GetByIDForUpdate.
+func (mr *MockUserRepositoryMockRecorder) GetByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDForUpdate", reflect.TypeOf((*MockUserRepository)(nil).GetByIDForUpdate), ctx, id)
+}
+
 // GetByUsername mocks base method.
 func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +125,51 @@ func (mr *MockUserRepositoryMockRecorder) GetByUsername(ctx, username interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUsername", reflect.TypeOf((*MockUserRepository)(nil).GetByUsername), ctx, username)
 }
 
+// GetPrivilegeExpired mocks base method.
+func (m *MockUserRepository) GetPrivilegeExpired(ctx context.Context, before time.Time, limit int) (*[]model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrivilegeExpired", ctx, before, limit)
+	ret0, _ := ret[0].(*[]model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrivilegeExpired indicates an expected call of GetPrivilegeExpired.
+func (mr *MockUserRepositoryMockRecorder) GetPrivilegeExpired(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivilegeExpired", reflect.TypeOf((*MockUserRepository)(nil).GetPrivilegeExpired), ctx, before, limit)
+}
+
+// GetScheduledPlanDue mocks base method.
+func (m *MockUserRepository) GetScheduledPlanDue(ctx context.Context, now time.Time, limit int) (*[]model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledPlanDue", ctx, now, limit)
+	ret0, _ := ret[0].(*[]model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledPlanDue indicates an expected call of GetScheduledPlanDue.
+func (mr *MockUserRepositoryMockRecorder) GetScheduledPlanDue(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledPlanDue", reflect.TypeOf((*MockUserRepository)(nil).GetScheduledPlanDue), ctx, now, limit)
+}
+
+// GetTrafficResetDue mocks base method.
+func (m *MockUserRepository) GetTrafficResetDue(ctx context.Context, now time.Time, limit int) (*[]model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrafficResetDue", ctx, now, limit)
+	ret0, _ := ret[0].(*[]model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrafficResetDue indicates an expected call of GetTrafficResetDue.
+func (mr *MockUserRepositoryMockRecorder) GetTrafficResetDue(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrafficResetDue", reflect.TypeOf((*MockUserRepository)(nil).GetTrafficResetDue), ctx, now, limit)
+}
+
 // Update mocks base method.
 func (m *MockUserRepository) Update(ctx context.Context, user *model.User) error {
 	m.ctrl.T.Helper()
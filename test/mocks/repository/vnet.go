@@ -35,6 +35,21 @@ func (m *MockVnetRepository) EXPECT() *MockVnetRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AckVnetRevision mocks base method.
+func (m *MockVnetRepository) AckVnetRevision(ctx context.Context, vnetId string, revision int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AckVnetRevision", ctx, vnetId, revision)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AckVnetRevision indicates an expected call of AckVnetRevision.
+func (mr *MockVnetRepositoryMockRecorder) AckVnetRevision(ctx, vnetId, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AckVnetRevision", reflect.TypeOf((*MockVnetRepository)(nil).AckVnetRevision), ctx, vnetId, revision)
+}
+
 // CheckVnetTokenExists mocks base method.
 func (m *MockVnetRepository) CheckVnetTokenExists(ctx context.Context, token, excludeVnetId string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +65,21 @@ func (mr *MockVnetRepositoryMockRecorder) CheckVnetTokenExists(ctx, token, exclu
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckVnetTokenExists", reflect.TypeOf((*MockVnetRepository)(nil).CheckVnetTokenExists), ctx, token, excludeVnetId)
 }
 
+// ClearNodeAssignment mocks base method.
+func (m *MockVnetRepository) ClearNodeAssignment(ctx context.Context, nodeId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearNodeAssignment", ctx, nodeId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClearNodeAssignment indicates an expected call of ClearNodeAssignment.
+func (mr *MockVnetRepositoryMockRecorder) ClearNodeAssignment(ctx, nodeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearNodeAssignment", reflect.TypeOf((*MockVnetRepository)(nil).ClearNodeAssignment), ctx, nodeId)
+}
+
 // CreateVnet mocks base method.
 func (m *MockVnetRepository) CreateVnet(ctx context.Context, vnet *model.Vnet) error {
 	m.ctrl.T.Helper()
@@ -78,6 +108,21 @@ func (mr *MockVnetRepositoryMockRecorder) DeleteVnet(ctx, vnetId interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVnet", reflect.TypeOf((*MockVnetRepository)(nil).DeleteVnet), ctx, vnetId)
 }
 
+// GetEnabledVnets mocks base method.
+func (m *MockVnetRepository) GetEnabledVnets(ctx context.Context) (*[]model.Vnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnabledVnets", ctx)
+	ret0, _ := ret[0].(*[]model.Vnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnabledVnets indicates an expected call of GetEnabledVnets.
+func (mr *MockVnetRepositoryMockRecorder) GetEnabledVnets(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnabledVnets", reflect.TypeOf((*MockVnetRepository)(nil).GetEnabledVnets), ctx)
+}
+
 // GetOnlineDevicesCount mocks base method.
 func (m *MockVnetRepository) GetOnlineDevicesCount(ctx context.Context, userId string) (int, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +168,36 @@ func (mr *MockVnetRepositoryMockRecorder) GetRunningVnetCount(ctx, userId interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningVnetCount", reflect.TypeOf((*MockVnetRepository)(nil).GetRunningVnetCount), ctx, userId)
 }
 
+// GetRunningVnetCountByOrgId mocks base method.
+func (m *MockVnetRepository) GetRunningVnetCountByOrgId(ctx context.Context, orgId string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunningVnetCountByOrgId", ctx, orgId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunningVnetCountByOrgId indicates an expected call of GetRunningVnetCountByOrgId.
+func (mr *MockVnetRepositoryMockRecorder) GetRunningVnetCountByOrgId(ctx, orgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningVnetCountByOrgId", reflect.TypeOf((*MockVnetRepository)(nil).GetRunningVnetCountByOrgId), ctx, orgId)
+}
+
+// GetVnetByToken mocks base method.
+func (m *MockVnetRepository) GetVnetByToken(ctx context.Context, token string) (*model.Vnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetByToken", ctx, token)
+	ret0, _ := ret[0].(*model.Vnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetByToken indicates an expected call of GetVnetByToken.
+func (mr *MockVnetRepositoryMockRecorder) GetVnetByToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetByToken", reflect.TypeOf((*MockVnetRepository)(nil).GetVnetByToken), ctx, token)
+}
+
 // GetVnetByUserId mocks base method.
 func (m *MockVnetRepository) GetVnetByUserId(ctx context.Context, userId string) (*[]model.Vnet, error) {
 	m.ctrl.T.Helper()
@@ -153,6 +228,96 @@ func (mr *MockVnetRepositoryMockRecorder) GetVnetByVnetId(ctx, vnetId interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetByVnetId", reflect.TypeOf((*MockVnetRepository)(nil).GetVnetByVnetId), ctx, vnetId)
 }
 
+// GetVnetByVnetIdForUpdate mocks base method.
+func (m *MockVnetRepository) GetVnetByVnetIdForUpdate(ctx context.Context, vnetId string) (*model.Vnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetByVnetIdForUpdate", ctx, vnetId)
+	ret0, _ := ret[0].(*model.Vnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetByVnetIdForUpdate indicates an expected call of GetVnetByVnetIdForUpdate.
+func (mr *MockVnetRepositoryMockRecorder) GetVnetByVnetIdForUpdate(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetByVnetIdForUpdate", reflect.TypeOf((*MockVnetRepository)(nil).GetVnetByVnetIdForUpdate), ctx, vnetId)
+}
+
+// GetVnetIdsByLastUsage mocks base method.
+func (m *MockVnetRepository) GetVnetIdsByLastUsage(ctx context.Context, vnetIds []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetIdsByLastUsage", ctx, vnetIds)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetIdsByLastUsage indicates an expected call of GetVnetIdsByLastUsage.
+func (mr *MockVnetRepositoryMockRecorder) GetVnetIdsByLastUsage(ctx, vnetIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetIdsByLastUsage", reflect.TypeOf((*MockVnetRepository)(nil).GetVnetIdsByLastUsage), ctx, vnetIds)
+}
+
+// GetVnetsByNodeId mocks base method.
+func (m *MockVnetRepository) GetVnetsByNodeId(ctx context.Context, nodeId string) (*[]model.Vnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetsByNodeId", ctx, nodeId)
+	ret0, _ := ret[0].(*[]model.Vnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetsByNodeId indicates an expected call of GetVnetsByNodeId.
+func (mr *MockVnetRepositoryMockRecorder) GetVnetsByNodeId(ctx, nodeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetsByNodeId", reflect.TypeOf((*MockVnetRepository)(nil).GetVnetsByNodeId), ctx, nodeId)
+}
+
+// GetVnetsByOrgIds mocks base method.
+func (m *MockVnetRepository) GetVnetsByOrgIds(ctx context.Context, orgIds []string) (*[]model.Vnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetsByOrgIds", ctx, orgIds)
+	ret0, _ := ret[0].(*[]model.Vnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetsByOrgIds indicates an expected call of GetVnetsByOrgIds.
+func (mr *MockVnetRepositoryMockRecorder) GetVnetsByOrgIds(ctx, orgIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetsByOrgIds", reflect.TypeOf((*MockVnetRepository)(nil).GetVnetsByOrgIds), ctx, orgIds)
+}
+
+// GetVnetsByOwner mocks base method.
+func (m *MockVnetRepository) GetVnetsByOwner(ctx context.Context, userId, orgId string) (*[]model.Vnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetsByOwner", ctx, userId, orgId)
+	ret0, _ := ret[0].(*[]model.Vnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetsByOwner indicates an expected call of GetVnetsByOwner.
+func (mr *MockVnetRepositoryMockRecorder) GetVnetsByOwner(ctx, userId, orgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetsByOwner", reflect.TypeOf((*MockVnetRepository)(nil).GetVnetsByOwner), ctx, userId, orgId)
+}
+
+// GetVnetsByVnetIds mocks base method.
+func (m *MockVnetRepository) GetVnetsByVnetIds(ctx context.Context, vnetIds []string) (*[]model.Vnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetsByVnetIds", ctx, vnetIds)
+	ret0, _ := ret[0].(*[]model.Vnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetsByVnetIds indicates an expected call of GetVnetsByVnetIds.
+func (mr *MockVnetRepositoryMockRecorder) GetVnetsByVnetIds(ctx, vnetIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetsByVnetIds", reflect.TypeOf((*MockVnetRepository)(nil).GetVnetsByVnetIds), ctx, vnetIds)
+}
+
 // UpdateVnet mocks base method.
 func (m *MockVnetRepository) UpdateVnet(ctx context.Context, vnet *model.Vnet) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,153 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/vnet_acl.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetAclRepository is a mock of VnetAclRepository interface.
+type MockVnetAclRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetAclRepositoryMockRecorder
+}
+
+// MockVnetAclRepositoryMockRecorder is the mock recorder for MockVnetAclRepository.
+type MockVnetAclRepositoryMockRecorder struct {
+	mock *MockVnetAclRepository
+}
+
+// NewMockVnetAclRepository creates a new mock instance.
+func NewMockVnetAclRepository(ctrl *gomock.Controller) *MockVnetAclRepository {
+	mock := &MockVnetAclRepository{ctrl: ctrl}
+	mock.recorder = &MockVnetAclRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetAclRepository) EXPECT() *MockVnetAclRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountRules mocks base method.
+func (m *MockVnetAclRepository) CountRules(ctx context.Context, vnetId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRules", ctx, vnetId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRules indicates an expected call of CountRules.
+func (mr *MockVnetAclRepositoryMockRecorder) CountRules(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRules", reflect.TypeOf((*MockVnetAclRepository)(nil).CountRules), ctx, vnetId)
+}
+
+// CreateRule mocks base method.
+func (m *MockVnetAclRepository) CreateRule(ctx context.Context, rule *model.VnetAclRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRule indicates an expected call of CreateRule.
+func (mr *MockVnetAclRepositoryMockRecorder) CreateRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRule", reflect.TypeOf((*MockVnetAclRepository)(nil).CreateRule), ctx, rule)
+}
+
+// DeleteRule mocks base method.
+func (m *MockVnetAclRepository) DeleteRule(ctx context.Context, vnetId, ruleId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRule", ctx, vnetId, ruleId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRule indicates an expected call of DeleteRule.
+func (mr *MockVnetAclRepositoryMockRecorder) DeleteRule(ctx, vnetId, ruleId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRule", reflect.TypeOf((*MockVnetAclRepository)(nil).DeleteRule), ctx, vnetId, ruleId)
+}
+
+// GetMemberTags mocks base method.
+func (m *MockVnetAclRepository) GetMemberTags(ctx context.Context, vnetId string) (*[]model.VnetMemberTag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberTags", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.VnetMemberTag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberTags indicates an expected call of GetMemberTags.
+func (mr *MockVnetAclRepositoryMockRecorder) GetMemberTags(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberTags", reflect.TypeOf((*MockVnetAclRepository)(nil).GetMemberTags), ctx, vnetId)
+}
+
+// GetRule mocks base method.
+func (m *MockVnetAclRepository) GetRule(ctx context.Context, vnetId, ruleId string) (*model.VnetAclRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRule", ctx, vnetId, ruleId)
+	ret0, _ := ret[0].(*model.VnetAclRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRule indicates an expected call of GetRule.
+func (mr *MockVnetAclRepositoryMockRecorder) GetRule(ctx, vnetId, ruleId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRule", reflect.TypeOf((*MockVnetAclRepository)(nil).GetRule), ctx, vnetId, ruleId)
+}
+
+// GetRules mocks base method.
+func (m *MockVnetAclRepository) GetRules(ctx context.Context, vnetId string) (*[]model.VnetAclRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRules", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.VnetAclRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRules indicates an expected call of GetRules.
+func (mr *MockVnetAclRepositoryMockRecorder) GetRules(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRules", reflect.TypeOf((*MockVnetAclRepository)(nil).GetRules), ctx, vnetId)
+}
+
+// SetMemberTags mocks base method.
+func (m *MockVnetAclRepository) SetMemberTags(ctx context.Context, vnetId, clientId string, tags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMemberTags", ctx, vnetId, clientId, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMemberTags indicates an expected call of SetMemberTags.
+func (mr *MockVnetAclRepositoryMockRecorder) SetMemberTags(ctx, vnetId, clientId, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMemberTags", reflect.TypeOf((*MockVnetAclRepository)(nil).SetMemberTags), ctx, vnetId, clientId, tags)
+}
+
+// UpdateRule mocks base method.
+func (m *MockVnetAclRepository) UpdateRule(ctx context.Context, rule *model.VnetAclRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRule indicates an expected call of UpdateRule.
+func (mr *MockVnetAclRepositoryMockRecorder) UpdateRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRule", reflect.TypeOf((*MockVnetAclRepository)(nil).UpdateRule), ctx, rule)
+}
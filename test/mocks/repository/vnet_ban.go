@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/vnet_ban.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetBanRepository is a mock of VnetBanRepository interface.
+type MockVnetBanRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetBanRepositoryMockRecorder
+}
+
+// MockVnetBanRepositoryMockRecorder is the mock recorder for MockVnetBanRepository.
+type MockVnetBanRepositoryMockRecorder struct {
+	mock *MockVnetBanRepository
+}
+
+// NewMockVnetBanRepository creates a new mock instance.
+func NewMockVnetBanRepository(ctrl *gomock.Controller) *MockVnetBanRepository {
+	mock := &MockVnetBanRepository{ctrl: ctrl}
+	mock.recorder = &MockVnetBanRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetBanRepository) EXPECT() *MockVnetBanRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateBan mocks base method.
+func (m *MockVnetBanRepository) CreateBan(ctx context.Context, ban *model.VnetBan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBan", ctx, ban)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBan indicates an expected call of CreateBan.
+func (mr *MockVnetBanRepositoryMockRecorder) CreateBan(ctx, ban interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBan", reflect.TypeOf((*MockVnetBanRepository)(nil).CreateBan), ctx, ban)
+}
+
+// DeleteBan mocks base method.
+func (m *MockVnetBanRepository) DeleteBan(ctx context.Context, vnetId, banId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBan", ctx, vnetId, banId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBan indicates an expected call of DeleteBan.
+func (mr *MockVnetBanRepositoryMockRecorder) DeleteBan(ctx, vnetId, banId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBan", reflect.TypeOf((*MockVnetBanRepository)(nil).DeleteBan), ctx, vnetId, banId)
+}
+
+// GetActiveBans mocks base method.
+func (m *MockVnetBanRepository) GetActiveBans(ctx context.Context, vnetId string, now time.Time) (*[]model.VnetBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveBans", ctx, vnetId, now)
+	ret0, _ := ret[0].(*[]model.VnetBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveBans indicates an expected call of GetActiveBans.
+func (mr *MockVnetBanRepositoryMockRecorder) GetActiveBans(ctx, vnetId, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveBans", reflect.TypeOf((*MockVnetBanRepository)(nil).GetActiveBans), ctx, vnetId, now)
+}
+
+// GetBan mocks base method.
+func (m *MockVnetBanRepository) GetBan(ctx context.Context, vnetId, banId string) (*model.VnetBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBan", ctx, vnetId, banId)
+	ret0, _ := ret[0].(*model.VnetBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBan indicates an expected call of GetBan.
+func (mr *MockVnetBanRepositoryMockRecorder) GetBan(ctx, vnetId, banId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBan", reflect.TypeOf((*MockVnetBanRepository)(nil).GetBan), ctx, vnetId, banId)
+}
+
+// GetBans mocks base method.
+func (m *MockVnetBanRepository) GetBans(ctx context.Context, vnetId string) (*[]model.VnetBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBans", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.VnetBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBans indicates an expected call of GetBans.
+func (mr *MockVnetBanRepositoryMockRecorder) GetBans(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBans", reflect.TypeOf((*MockVnetBanRepository)(nil).GetBans), ctx, vnetId)
+}
+
+// UpdateBan mocks base method.
+func (m *MockVnetBanRepository) UpdateBan(ctx context.Context, ban *model.VnetBan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBan", ctx, ban)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBan indicates an expected call of UpdateBan.
+func (mr *MockVnetBanRepositoryMockRecorder) UpdateBan(ctx, ban interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBan", reflect.TypeOf((*MockVnetBanRepository)(nil).UpdateBan), ctx, ban)
+}
@@ -0,0 +1,169 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/vnet_client.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetClientRepository is a mock of VnetClientRepository interface.
+type MockVnetClientRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetClientRepositoryMockRecorder
+}
+
+// MockVnetClientRepositoryMockRecorder is the mock recorder for MockVnetClientRepository.
+type MockVnetClientRepositoryMockRecorder struct {
+	mock *MockVnetClientRepository
+}
+
+// NewMockVnetClientRepository creates a new mock instance.
+func NewMockVnetClientRepository(ctrl *gomock.Controller) *MockVnetClientRepository {
+	mock := &MockVnetClientRepository{ctrl: ctrl}
+	mock.recorder = &MockVnetClientRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetClientRepository) EXPECT() *MockVnetClientRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpiredChallenges mocks base method.
+func (m *MockVnetClientRepository) DeleteExpiredChallenges(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredChallenges", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredChallenges indicates an expected call of DeleteExpiredChallenges.
+func (mr *MockVnetClientRepositoryMockRecorder) DeleteExpiredChallenges(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredChallenges", reflect.TypeOf((*MockVnetClientRepository)(nil).DeleteExpiredChallenges), ctx, now)
+}
+
+// DeleteStaleVnetClients mocks base method.
+func (m *MockVnetClientRepository) DeleteStaleVnetClients(ctx context.Context, before time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleVnetClients", ctx, before)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStaleVnetClients indicates an expected call of DeleteStaleVnetClients.
+func (mr *MockVnetClientRepositoryMockRecorder) DeleteStaleVnetClients(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleVnetClients", reflect.TypeOf((*MockVnetClientRepository)(nil).DeleteStaleVnetClients), ctx, before)
+}
+
+// DeleteVnetClient mocks base method.
+func (m *MockVnetClientRepository) DeleteVnetClient(ctx context.Context, vnetId, clientId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVnetClient", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteVnetClient indicates an expected call of DeleteVnetClient.
+func (mr *MockVnetClientRepositoryMockRecorder) DeleteVnetClient(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVnetClient", reflect.TypeOf((*MockVnetClientRepository)(nil).DeleteVnetClient), ctx, vnetId, clientId)
+}
+
+// GetVnetClientsByNodeId mocks base method.
+func (m *MockVnetClientRepository) GetVnetClientsByNodeId(ctx context.Context, nodeId string) (*[]model.VnetClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetClientsByNodeId", ctx, nodeId)
+	ret0, _ := ret[0].(*[]model.VnetClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetClientsByNodeId indicates an expected call of GetVnetClientsByNodeId.
+func (mr *MockVnetClientRepositoryMockRecorder) GetVnetClientsByNodeId(ctx, nodeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetClientsByNodeId", reflect.TypeOf((*MockVnetClientRepository)(nil).GetVnetClientsByNodeId), ctx, nodeId)
+}
+
+// GetVnetClientsByVnetId mocks base method.
+func (m *MockVnetClientRepository) GetVnetClientsByVnetId(ctx context.Context, vnetId string) (*[]model.VnetClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetClientsByVnetId", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.VnetClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetClientsByVnetId indicates an expected call of GetVnetClientsByVnetId.
+func (mr *MockVnetClientRepositoryMockRecorder) GetVnetClientsByVnetId(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetClientsByVnetId", reflect.TypeOf((*MockVnetClientRepository)(nil).GetVnetClientsByVnetId), ctx, vnetId)
+}
+
+// SyncClientsOnline mocks base method.
+func (m *MockVnetClientRepository) SyncClientsOnline(ctx context.Context, vnetIds []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncClientsOnline", ctx, vnetIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncClientsOnline indicates an expected call of SyncClientsOnline.
+func (mr *MockVnetClientRepositoryMockRecorder) SyncClientsOnline(ctx, vnetIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncClientsOnline", reflect.TypeOf((*MockVnetClientRepository)(nil).SyncClientsOnline), ctx, vnetIds)
+}
+
+// TouchVnetClients mocks base method.
+func (m *MockVnetClientRepository) TouchVnetClients(ctx context.Context, ids []uint, lastSeen time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchVnetClients", ctx, ids, lastSeen)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchVnetClients indicates an expected call of TouchVnetClients.
+func (mr *MockVnetClientRepositoryMockRecorder) TouchVnetClients(ctx, ids, lastSeen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchVnetClients", reflect.TypeOf((*MockVnetClientRepository)(nil).TouchVnetClients), ctx, ids, lastSeen)
+}
+
+// UpsertVnetClient mocks base method.
+func (m *MockVnetClientRepository) UpsertVnetClient(ctx context.Context, client *model.VnetClient) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertVnetClient", ctx, client)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertVnetClient indicates an expected call of UpsertVnetClient.
+func (mr *MockVnetClientRepositoryMockRecorder) UpsertVnetClient(ctx, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertVnetClient", reflect.TypeOf((*MockVnetClientRepository)(nil).UpsertVnetClient), ctx, client)
+}
+
+// UseChallenge mocks base method.
+func (m *MockVnetClientRepository) UseChallenge(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseChallenge", ctx, nonce, expiresAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseChallenge indicates an expected call of UseChallenge.
+func (mr *MockVnetClientRepositoryMockRecorder) UseChallenge(ctx, nonce, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseChallenge", reflect.TypeOf((*MockVnetClientRepository)(nil).UseChallenge), ctx, nonce, expiresAt)
+}
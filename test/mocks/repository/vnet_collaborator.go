@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/vnet_collaborator.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetCollaboratorRepository is a mock of VnetCollaboratorRepository interface.
+type MockVnetCollaboratorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetCollaboratorRepositoryMockRecorder
+}
+
+// MockVnetCollaboratorRepositoryMockRecorder is the mock recorder for MockVnetCollaboratorRepository.
+type MockVnetCollaboratorRepositoryMockRecorder struct {
+	mock *MockVnetCollaboratorRepository
+}
+
+// NewMockVnetCollaboratorRepository creates a new mock instance.
+func NewMockVnetCollaboratorRepository(ctrl *gomock.Controller) *MockVnetCollaboratorRepository {
+	mock := &MockVnetCollaboratorRepository{ctrl: ctrl}
+	mock.recorder = &MockVnetCollaboratorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetCollaboratorRepository) EXPECT() *MockVnetCollaboratorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAuditLog mocks base method.
+func (m *MockVnetCollaboratorRepository) CreateAuditLog(ctx context.Context, log *model.VnetAuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLog", ctx, log)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockVnetCollaboratorRepositoryMockRecorder) CreateAuditLog(ctx, log interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockVnetCollaboratorRepository)(nil).CreateAuditLog), ctx, log)
+}
+
+// DeleteCollaborator mocks base method.
+func (m *MockVnetCollaboratorRepository) DeleteCollaborator(ctx context.Context, vnetId, userId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollaborator", ctx, vnetId, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCollaborator indicates an expected call of DeleteCollaborator.
+func (mr *MockVnetCollaboratorRepositoryMockRecorder) DeleteCollaborator(ctx, vnetId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollaborator", reflect.TypeOf((*MockVnetCollaboratorRepository)(nil).DeleteCollaborator), ctx, vnetId, userId)
+}
+
+// GetAuditLogs mocks base method.
+func (m *MockVnetCollaboratorRepository) GetAuditLogs(ctx context.Context, vnetId string, limit int) (*[]model.VnetAuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogs", ctx, vnetId, limit)
+	ret0, _ := ret[0].(*[]model.VnetAuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogs indicates an expected call of GetAuditLogs.
+func (mr *MockVnetCollaboratorRepositoryMockRecorder) GetAuditLogs(ctx, vnetId, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogs", reflect.TypeOf((*MockVnetCollaboratorRepository)(nil).GetAuditLogs), ctx, vnetId, limit)
+}
+
+// GetCollaborationsByUserId mocks base method.
+func (m *MockVnetCollaboratorRepository) GetCollaborationsByUserId(ctx context.Context, userId string) (*[]model.VnetCollaborator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollaborationsByUserId", ctx, userId)
+	ret0, _ := ret[0].(*[]model.VnetCollaborator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollaborationsByUserId indicates an expected call of GetCollaborationsByUserId.
+func (mr *MockVnetCollaboratorRepositoryMockRecorder) GetCollaborationsByUserId(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollaborationsByUserId", reflect.TypeOf((*MockVnetCollaboratorRepository)(nil).GetCollaborationsByUserId), ctx, userId)
+}
+
+// GetCollaborator mocks base method.
+func (m *MockVnetCollaboratorRepository) GetCollaborator(ctx context.Context, vnetId, userId string) (*model.VnetCollaborator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollaborator", ctx, vnetId, userId)
+	ret0, _ := ret[0].(*model.VnetCollaborator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollaborator indicates an expected call of GetCollaborator.
+func (mr *MockVnetCollaboratorRepositoryMockRecorder) GetCollaborator(ctx, vnetId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollaborator", reflect.TypeOf((*MockVnetCollaboratorRepository)(nil).GetCollaborator), ctx, vnetId, userId)
+}
+
+// GetCollaborators mocks base method.
+func (m *MockVnetCollaboratorRepository) GetCollaborators(ctx context.Context, vnetId string) (*[]model.VnetCollaborator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollaborators", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.VnetCollaborator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollaborators indicates an expected call of GetCollaborators.
+func (mr *MockVnetCollaboratorRepositoryMockRecorder) GetCollaborators(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollaborators", reflect.TypeOf((*MockVnetCollaboratorRepository)(nil).GetCollaborators), ctx, vnetId)
+}
+
+// SaveCollaborator mocks base method.
+func (m *MockVnetCollaboratorRepository) SaveCollaborator(ctx context.Context, collaborator *model.VnetCollaborator) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCollaborator", ctx, collaborator)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCollaborator indicates an expected call of SaveCollaborator.
+func (mr *MockVnetCollaboratorRepositoryMockRecorder) SaveCollaborator(ctx, collaborator interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCollaborator", reflect.TypeOf((*MockVnetCollaboratorRepository)(nil).SaveCollaborator), ctx, collaborator)
+}
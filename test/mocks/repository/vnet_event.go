@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/vnet_event.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetEventRepository is a mock of VnetEventRepository interface.
+type MockVnetEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetEventRepositoryMockRecorder
+}
+
+// MockVnetEventRepositoryMockRecorder is the mock recorder for MockVnetEventRepository.
+type MockVnetEventRepositoryMockRecorder struct {
+	mock *MockVnetEventRepository
+}
+
+// NewMockVnetEventRepository creates a new mock instance.
+func NewMockVnetEventRepository(ctrl *gomock.Controller) *MockVnetEventRepository {
+	mock := &MockVnetEventRepository{ctrl: ctrl}
+	mock.recorder = &MockVnetEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetEventRepository) EXPECT() *MockVnetEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockVnetEventRepository) Create(ctx context.Context, event *model.VnetEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockVnetEventRepositoryMockRecorder) Create(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockVnetEventRepository)(nil).Create), ctx, event)
+}
+
+// DeleteBefore mocks base method.
+func (m *MockVnetEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockVnetEventRepositoryMockRecorder) DeleteBefore(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockVnetEventRepository)(nil).DeleteBefore), ctx, before)
+}
+
+// GetLatestRevision mocks base method.
+func (m *MockVnetEventRepository) GetLatestRevision(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestRevision", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestRevision indicates an expected call of GetLatestRevision.
+func (mr *MockVnetEventRepositoryMockRecorder) GetLatestRevision(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestRevision", reflect.TypeOf((*MockVnetEventRepository)(nil).GetLatestRevision), ctx)
+}
+
+// GetOldestRevision mocks base method.
+func (m *MockVnetEventRepository) GetOldestRevision(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOldestRevision", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOldestRevision indicates an expected call of GetOldestRevision.
+func (mr *MockVnetEventRepositoryMockRecorder) GetOldestRevision(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOldestRevision", reflect.TypeOf((*MockVnetEventRepository)(nil).GetOldestRevision), ctx)
+}
+
+// ListAfter mocks base method.
+func (m *MockVnetEventRepository) ListAfter(ctx context.Context, afterRevision int64, limit int) (*[]model.VnetEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfter", ctx, afterRevision, limit)
+	ret0, _ := ret[0].(*[]model.VnetEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter.
+func (mr *MockVnetEventRepositoryMockRecorder) ListAfter(ctx, afterRevision, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*MockVnetEventRepository)(nil).ListAfter), ctx, afterRevision, limit)
+}
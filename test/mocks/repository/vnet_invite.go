@@ -0,0 +1,153 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/vnet_invite.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetInviteRepository is a mock of VnetInviteRepository interface.
+type MockVnetInviteRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetInviteRepositoryMockRecorder
+}
+
+// MockVnetInviteRepositoryMockRecorder is the mock recorder for MockVnetInviteRepository.
+type MockVnetInviteRepositoryMockRecorder struct {
+	mock *MockVnetInviteRepository
+}
+
+// NewMockVnetInviteRepository creates a new mock instance.
+func NewMockVnetInviteRepository(ctrl *gomock.Controller) *MockVnetInviteRepository {
+	mock := &MockVnetInviteRepository{ctrl: ctrl}
+	mock.recorder = &MockVnetInviteRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetInviteRepository) EXPECT() *MockVnetInviteRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateInvite mocks base method.
+func (m *MockVnetInviteRepository) CreateInvite(ctx context.Context, invite *model.VnetInvite) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvite", ctx, invite)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateInvite indicates an expected call of CreateInvite.
+func (mr *MockVnetInviteRepositoryMockRecorder) CreateInvite(ctx, invite interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvite", reflect.TypeOf((*MockVnetInviteRepository)(nil).CreateInvite), ctx, invite)
+}
+
+// GetInvite mocks base method.
+func (m *MockVnetInviteRepository) GetInvite(ctx context.Context, vnetId, inviteId string) (*model.VnetInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvite", ctx, vnetId, inviteId)
+	ret0, _ := ret[0].(*model.VnetInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvite indicates an expected call of GetInvite.
+func (mr *MockVnetInviteRepositoryMockRecorder) GetInvite(ctx, vnetId, inviteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvite", reflect.TypeOf((*MockVnetInviteRepository)(nil).GetInvite), ctx, vnetId, inviteId)
+}
+
+// GetInviteByCode mocks base method.
+func (m *MockVnetInviteRepository) GetInviteByCode(ctx context.Context, code string) (*model.VnetInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInviteByCode", ctx, code)
+	ret0, _ := ret[0].(*model.VnetInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInviteByCode indicates an expected call of GetInviteByCode.
+func (mr *MockVnetInviteRepositoryMockRecorder) GetInviteByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInviteByCode", reflect.TypeOf((*MockVnetInviteRepository)(nil).GetInviteByCode), ctx, code)
+}
+
+// GetInvites mocks base method.
+func (m *MockVnetInviteRepository) GetInvites(ctx context.Context, vnetId string) (*[]model.VnetInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvites", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.VnetInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvites indicates an expected call of GetInvites.
+func (mr *MockVnetInviteRepositoryMockRecorder) GetInvites(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvites", reflect.TypeOf((*MockVnetInviteRepository)(nil).GetInvites), ctx, vnetId)
+}
+
+// GetRedemption mocks base method.
+func (m *MockVnetInviteRepository) GetRedemption(ctx context.Context, vnetId, clientId string) (*model.VnetInviteRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRedemption", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(*model.VnetInviteRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRedemption indicates an expected call of GetRedemption.
+func (mr *MockVnetInviteRepositoryMockRecorder) GetRedemption(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRedemption", reflect.TypeOf((*MockVnetInviteRepository)(nil).GetRedemption), ctx, vnetId, clientId)
+}
+
+// GetRedemptions mocks base method.
+func (m *MockVnetInviteRepository) GetRedemptions(ctx context.Context, vnetId string) (*[]model.VnetInviteRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRedemptions", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.VnetInviteRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRedemptions indicates an expected call of GetRedemptions.
+func (mr *MockVnetInviteRepositoryMockRecorder) GetRedemptions(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRedemptions", reflect.TypeOf((*MockVnetInviteRepository)(nil).GetRedemptions), ctx, vnetId)
+}
+
+// SaveRedemption mocks base method.
+func (m *MockVnetInviteRepository) SaveRedemption(ctx context.Context, redemption *model.VnetInviteRedemption) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRedemption", ctx, redemption)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRedemption indicates an expected call of SaveRedemption.
+func (mr *MockVnetInviteRepositoryMockRecorder) SaveRedemption(ctx, redemption interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRedemption", reflect.TypeOf((*MockVnetInviteRepository)(nil).SaveRedemption), ctx, redemption)
+}
+
+// UpdateInvite mocks base method.
+func (m *MockVnetInviteRepository) UpdateInvite(ctx context.Context, invite *model.VnetInvite) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInvite", ctx, invite)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInvite indicates an expected call of UpdateInvite.
+func (mr *MockVnetInviteRepositoryMockRecorder) UpdateInvite(ctx, invite interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInvite", reflect.TypeOf((*MockVnetInviteRepository)(nil).UpdateInvite), ctx, invite)
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/vnet_member.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetMemberRepository is a mock of VnetMemberRepository interface.
+type MockVnetMemberRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetMemberRepositoryMockRecorder
+}
+
+// MockVnetMemberRepositoryMockRecorder is the mock recorder for MockVnetMemberRepository.
+type MockVnetMemberRepositoryMockRecorder struct {
+	mock *MockVnetMemberRepository
+}
+
+// NewMockVnetMemberRepository creates a new mock instance.
+func NewMockVnetMemberRepository(ctrl *gomock.Controller) *MockVnetMemberRepository {
+	mock := &MockVnetMemberRepository{ctrl: ctrl}
+	mock.recorder = &MockVnetMemberRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetMemberRepository) EXPECT() *MockVnetMemberRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteMember mocks base method.
+func (m *MockVnetMemberRepository) DeleteMember(ctx context.Context, vnetId, clientId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMember", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMember indicates an expected call of DeleteMember.
+func (mr *MockVnetMemberRepositoryMockRecorder) DeleteMember(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMember", reflect.TypeOf((*MockVnetMemberRepository)(nil).DeleteMember), ctx, vnetId, clientId)
+}
+
+// GetMember mocks base method.
+func (m *MockVnetMemberRepository) GetMember(ctx context.Context, vnetId, clientId string) (*model.VnetMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMember", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(*model.VnetMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMember indicates an expected call of GetMember.
+func (mr *MockVnetMemberRepositoryMockRecorder) GetMember(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMember", reflect.TypeOf((*MockVnetMemberRepository)(nil).GetMember), ctx, vnetId, clientId)
+}
+
+// GetMembers mocks base method.
+func (m *MockVnetMemberRepository) GetMembers(ctx context.Context, vnetId, status string) (*[]model.VnetMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", ctx, vnetId, status)
+	ret0, _ := ret[0].(*[]model.VnetMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockVnetMemberRepositoryMockRecorder) GetMembers(ctx, vnetId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockVnetMemberRepository)(nil).GetMembers), ctx, vnetId, status)
+}
+
+// SaveMember mocks base method.
+func (m *MockVnetMemberRepository) SaveMember(ctx context.Context, member *model.VnetMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMember indicates an expected call of SaveMember.
+func (mr *MockVnetMemberRepositoryMockRecorder) SaveMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMember", reflect.TypeOf((*MockVnetMemberRepository)(nil).SaveMember), ctx, member)
+}
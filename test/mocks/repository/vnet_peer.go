@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/vnet_peer.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetPeerRepository is a mock of VnetPeerRepository interface.
+type MockVnetPeerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetPeerRepositoryMockRecorder
+}
+
+// MockVnetPeerRepositoryMockRecorder is the mock recorder for MockVnetPeerRepository.
+type MockVnetPeerRepositoryMockRecorder struct {
+	mock *MockVnetPeerRepository
+}
+
+// NewMockVnetPeerRepository creates a new mock instance.
+func NewMockVnetPeerRepository(ctrl *gomock.Controller) *MockVnetPeerRepository {
+	mock := &MockVnetPeerRepository{ctrl: ctrl}
+	mock.recorder = &MockVnetPeerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetPeerRepository) EXPECT() *MockVnetPeerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountPeers mocks base method.
+func (m *MockVnetPeerRepository) CountPeers(ctx context.Context, vnetId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPeers", ctx, vnetId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPeers indicates an expected call of CountPeers.
+func (mr *MockVnetPeerRepositoryMockRecorder) CountPeers(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPeers", reflect.TypeOf((*MockVnetPeerRepository)(nil).CountPeers), ctx, vnetId)
+}
+
+// DeletePeer mocks base method.
+func (m *MockVnetPeerRepository) DeletePeer(ctx context.Context, vnetId, clientId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePeer", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePeer indicates an expected call of DeletePeer.
+func (mr *MockVnetPeerRepositoryMockRecorder) DeletePeer(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePeer", reflect.TypeOf((*MockVnetPeerRepository)(nil).DeletePeer), ctx, vnetId, clientId)
+}
+
+// GetPeer mocks base method.
+func (m *MockVnetPeerRepository) GetPeer(ctx context.Context, vnetId, clientId string) (*model.VnetPeer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPeer", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(*model.VnetPeer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPeer indicates an expected call of GetPeer.
+func (mr *MockVnetPeerRepositoryMockRecorder) GetPeer(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeer", reflect.TypeOf((*MockVnetPeerRepository)(nil).GetPeer), ctx, vnetId, clientId)
+}
+
+// GetPeerByPublicKey mocks base method.
+func (m *MockVnetPeerRepository) GetPeerByPublicKey(ctx context.Context, vnetId, publicKey string) (*model.VnetPeer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPeerByPublicKey", ctx, vnetId, publicKey)
+	ret0, _ := ret[0].(*model.VnetPeer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPeerByPublicKey indicates an expected call of GetPeerByPublicKey.
+func (mr *MockVnetPeerRepositoryMockRecorder) GetPeerByPublicKey(ctx, vnetId, publicKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeerByPublicKey", reflect.TypeOf((*MockVnetPeerRepository)(nil).GetPeerByPublicKey), ctx, vnetId, publicKey)
+}
+
+// GetPeers mocks base method.
+func (m *MockVnetPeerRepository) GetPeers(ctx context.Context, vnetId string) (*[]model.VnetPeer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPeers", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.VnetPeer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPeers indicates an expected call of GetPeers.
+func (mr *MockVnetPeerRepositoryMockRecorder) GetPeers(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeers", reflect.TypeOf((*MockVnetPeerRepository)(nil).GetPeers), ctx, vnetId)
+}
+
+// SavePeer mocks base method.
+func (m *MockVnetPeerRepository) SavePeer(ctx context.Context, peer *model.VnetPeer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePeer", ctx, peer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePeer indicates an expected call of SavePeer.
+func (mr *MockVnetPeerRepositoryMockRecorder) SavePeer(ctx, peer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePeer", reflect.TypeOf((*MockVnetPeerRepository)(nil).SavePeer), ctx, peer)
+}
@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/vnet_route.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetRouteRepository is a mock of VnetRouteRepository interface.
+type MockVnetRouteRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetRouteRepositoryMockRecorder
+}
+
+// MockVnetRouteRepositoryMockRecorder is the mock recorder for MockVnetRouteRepository.
+type MockVnetRouteRepositoryMockRecorder struct {
+	mock *MockVnetRouteRepository
+}
+
+// NewMockVnetRouteRepository creates a new mock instance.
+func NewMockVnetRouteRepository(ctrl *gomock.Controller) *MockVnetRouteRepository {
+	mock := &MockVnetRouteRepository{ctrl: ctrl}
+	mock.recorder = &MockVnetRouteRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetRouteRepository) EXPECT() *MockVnetRouteRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountRoutes mocks base method.
+func (m *MockVnetRouteRepository) CountRoutes(ctx context.Context, vnetId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRoutes", ctx, vnetId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRoutes indicates an expected call of CountRoutes.
+func (mr *MockVnetRouteRepositoryMockRecorder) CountRoutes(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRoutes", reflect.TypeOf((*MockVnetRouteRepository)(nil).CountRoutes), ctx, vnetId)
+}
+
+// CreateRoute mocks base method.
+func (m *MockVnetRouteRepository) CreateRoute(ctx context.Context, route *model.VnetRoute) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRoute", ctx, route)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRoute indicates an expected call of CreateRoute.
+func (mr *MockVnetRouteRepositoryMockRecorder) CreateRoute(ctx, route interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoute", reflect.TypeOf((*MockVnetRouteRepository)(nil).CreateRoute), ctx, route)
+}
+
+// DeleteRoute mocks base method.
+func (m *MockVnetRouteRepository) DeleteRoute(ctx context.Context, vnetId, routeId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRoute", ctx, vnetId, routeId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRoute indicates an expected call of DeleteRoute.
+func (mr *MockVnetRouteRepositoryMockRecorder) DeleteRoute(ctx, vnetId, routeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoute", reflect.TypeOf((*MockVnetRouteRepository)(nil).DeleteRoute), ctx, vnetId, routeId)
+}
+
+// GetRoute mocks base method.
+func (m *MockVnetRouteRepository) GetRoute(ctx context.Context, vnetId, routeId string) (*model.VnetRoute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoute", ctx, vnetId, routeId)
+	ret0, _ := ret[0].(*model.VnetRoute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoute indicates an expected call of GetRoute.
+func (mr *MockVnetRouteRepositoryMockRecorder) GetRoute(ctx, vnetId, routeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoute", reflect.TypeOf((*MockVnetRouteRepository)(nil).GetRoute), ctx, vnetId, routeId)
+}
+
+// GetRoutes mocks base method.
+func (m *MockVnetRouteRepository) GetRoutes(ctx context.Context, vnetId, status string) (*[]model.VnetRoute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoutes", ctx, vnetId, status)
+	ret0, _ := ret[0].(*[]model.VnetRoute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoutes indicates an expected call of GetRoutes.
+func (mr *MockVnetRouteRepositoryMockRecorder) GetRoutes(ctx, vnetId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoutes", reflect.TypeOf((*MockVnetRouteRepository)(nil).GetRoutes), ctx, vnetId, status)
+}
+
+// UpdateRoute mocks base method.
+func (m *MockVnetRouteRepository) UpdateRoute(ctx context.Context, route *model.VnetRoute) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRoute", ctx, route)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRoute indicates an expected call of UpdateRoute.
+func (mr *MockVnetRouteRepositoryMockRecorder) UpdateRoute(ctx, route interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRoute", reflect.TypeOf((*MockVnetRouteRepository)(nil).UpdateRoute), ctx, route)
+}
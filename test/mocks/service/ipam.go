@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/ipam.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIpamService is a mock of IpamService interface.
+type MockIpamService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIpamServiceMockRecorder
+}
+
+// MockIpamServiceMockRecorder is the mock recorder for MockIpamService.
+type MockIpamServiceMockRecorder struct {
+	mock *MockIpamService
+}
+
+// NewMockIpamService creates a new mock instance.
+func NewMockIpamService(ctrl *gomock.Controller) *MockIpamService {
+	mock := &MockIpamService{ctrl: ctrl}
+	mock.recorder = &MockIpamServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIpamService) EXPECT() *MockIpamServiceMockRecorder {
+	return m.recorder
+}
+
+// AcquireLease mocks base method.
+func (m *MockIpamService) AcquireLease(ctx context.Context, vnet *model.Vnet, clientId, macAddress, requestedIp string) (*model.IpLease, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireLease", ctx, vnet, clientId, macAddress, requestedIp)
+	ret0, _ := ret[0].(*model.IpLease)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireLease indicates an expected call of AcquireLease.
+func (mr *MockIpamServiceMockRecorder) AcquireLease(ctx, vnet, clientId, macAddress, requestedIp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireLease", reflect.TypeOf((*MockIpamService)(nil).AcquireLease), ctx, vnet, clientId, macAddress, requestedIp)
+}
+
+// AssignAddress mocks base method.
+func (m *MockIpamService) AssignAddress(ctx context.Context, vnet *model.Vnet, clientId string) (*model.IpLease, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignAddress", ctx, vnet, clientId)
+	ret0, _ := ret[0].(*model.IpLease)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssignAddress indicates an expected call of AssignAddress.
+func (mr *MockIpamServiceMockRecorder) AssignAddress(ctx, vnet, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignAddress", reflect.TypeOf((*MockIpamService)(nil).AssignAddress), ctx, vnet, clientId)
+}
+
+// DeleteReservation mocks base method.
+func (m *MockIpamService) DeleteReservation(ctx context.Context, vnetId, clientId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReservation", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReservation indicates an expected call of DeleteReservation.
+func (mr *MockIpamServiceMockRecorder) DeleteReservation(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReservation", reflect.TypeOf((*MockIpamService)(nil).DeleteReservation), ctx, vnetId, clientId)
+}
+
+// GetLeases mocks base method.
+func (m *MockIpamService) GetLeases(ctx context.Context, vnetId string) (*[]model.IpLease, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeases", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.IpLease)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeases indicates an expected call of GetLeases.
+func (mr *MockIpamServiceMockRecorder) GetLeases(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeases", reflect.TypeOf((*MockIpamService)(nil).GetLeases), ctx, vnetId)
+}
+
+// MigrateLeases mocks base method.
+func (m *MockIpamService) MigrateLeases(ctx context.Context, vnetId, ipRange string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateLeases", ctx, vnetId, ipRange)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateLeases indicates an expected call of MigrateLeases.
+func (mr *MockIpamServiceMockRecorder) MigrateLeases(ctx, vnetId, ipRange interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateLeases", reflect.TypeOf((*MockIpamService)(nil).MigrateLeases), ctx, vnetId, ipRange)
+}
+
+// ReserveAddress mocks base method.
+func (m *MockIpamService) ReserveAddress(ctx context.Context, vnetId string, req *v1.ReserveAddressRequest) (*model.IpLease, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveAddress", ctx, vnetId, req)
+	ret0, _ := ret[0].(*model.IpLease)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveAddress indicates an expected call of ReserveAddress.
+func (mr *MockIpamServiceMockRecorder) ReserveAddress(ctx, vnetId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveAddress", reflect.TypeOf((*MockIpamService)(nil).ReserveAddress), ctx, vnetId, req)
+}
+
+// ResolveIpRange mocks base method.
+func (m *MockIpamService) ResolveIpRange(ctx context.Context, userId, orgId, vnetId, ipRange string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveIpRange", ctx, userId, orgId, vnetId, ipRange)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveIpRange indicates an expected call of ResolveIpRange.
+func (mr *MockIpamServiceMockRecorder) ResolveIpRange(ctx, userId, orgId, vnetId, ipRange interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveIpRange", reflect.TypeOf((*MockIpamService)(nil).ResolveIpRange), ctx, userId, orgId, vnetId, ipRange)
+}
@@ -0,0 +1,198 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/node.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockNodeService is a mock of NodeService interface.
+type MockNodeService struct {
+	ctrl     *gomock.Controller
+	recorder *MockNodeServiceMockRecorder
+}
+
+// MockNodeServiceMockRecorder is the mock recorder for MockNodeService.
+type MockNodeServiceMockRecorder struct {
+	mock *MockNodeService
+}
+
+// NewMockNodeService creates a new mock instance.
+func NewMockNodeService(ctrl *gomock.Controller) *MockNodeService {
+	mock := &MockNodeService{ctrl: ctrl}
+	mock.recorder = &MockNodeServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNodeService) EXPECT() *MockNodeServiceMockRecorder {
+	return m.recorder
+}
+
+// AckVnetConfig mocks base method.
+func (m *MockNodeService) AckVnetConfig(ctx context.Context, nodeId string, req *v1.AckNodeVnetConfigRequest) (*v1.AckNodeVnetConfigResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AckVnetConfig", ctx, nodeId, req)
+	ret0, _ := ret[0].(*v1.AckNodeVnetConfigResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AckVnetConfig indicates an expected call of AckVnetConfig.
+func (mr *MockNodeServiceMockRecorder) AckVnetConfig(ctx, nodeId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AckVnetConfig", reflect.TypeOf((*MockNodeService)(nil).AckVnetConfig), ctx, nodeId, req)
+}
+
+// Authenticate mocks base method.
+func (m *MockNodeService) Authenticate(ctx context.Context, nodeId, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, nodeId, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockNodeServiceMockRecorder) Authenticate(ctx, nodeId, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockNodeService)(nil).Authenticate), ctx, nodeId, key)
+}
+
+// CreateBootstrapToken mocks base method.
+func (m *MockNodeService) CreateBootstrapToken(ctx context.Context, req *v1.CreateBootstrapTokenRequest) (*v1.CreateBootstrapTokenResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBootstrapToken", ctx, req)
+	ret0, _ := ret[0].(*v1.CreateBootstrapTokenResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBootstrapToken indicates an expected call of CreateBootstrapToken.
+func (mr *MockNodeServiceMockRecorder) CreateBootstrapToken(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBootstrapToken", reflect.TypeOf((*MockNodeService)(nil).CreateBootstrapToken), ctx, req)
+}
+
+// Enroll mocks base method.
+func (m *MockNodeService) Enroll(ctx context.Context, req *v1.EnrollNodeRequest) (*v1.EnrollNodeResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enroll", ctx, req)
+	ret0, _ := ret[0].(*v1.EnrollNodeResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enroll indicates an expected call of Enroll.
+func (mr *MockNodeServiceMockRecorder) Enroll(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockNodeService)(nil).Enroll), ctx, req)
+}
+
+// GetVnetConfig mocks base method.
+func (m *MockNodeService) GetVnetConfig(ctx context.Context, nodeId, vnetId string) (*v1.GetNodeVnetConfigResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetConfig", ctx, nodeId, vnetId)
+	ret0, _ := ret[0].(*v1.GetNodeVnetConfigResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetConfig indicates an expected call of GetVnetConfig.
+func (mr *MockNodeServiceMockRecorder) GetVnetConfig(ctx, nodeId, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetConfig", reflect.TypeOf((*MockNodeService)(nil).GetVnetConfig), ctx, nodeId, vnetId)
+}
+
+// Heartbeat mocks base method.
+func (m *MockNodeService) Heartbeat(ctx context.Context, nodeId string, req *v1.NodeHeartbeatRequest) (*v1.NodeHeartbeatResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Heartbeat", ctx, nodeId, req)
+	ret0, _ := ret[0].(*v1.NodeHeartbeatResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Heartbeat indicates an expected call of Heartbeat.
+func (mr *MockNodeServiceMockRecorder) Heartbeat(ctx, nodeId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Heartbeat", reflect.TypeOf((*MockNodeService)(nil).Heartbeat), ctx, nodeId, req)
+}
+
+// ListNodes mocks base method.
+func (m *MockNodeService) ListNodes(ctx context.Context) (*[]model.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNodes", ctx)
+	ret0, _ := ret[0].(*[]model.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNodes indicates an expected call of ListNodes.
+func (mr *MockNodeServiceMockRecorder) ListNodes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNodes", reflect.TypeOf((*MockNodeService)(nil).ListNodes), ctx)
+}
+
+// ListVnets mocks base method.
+func (m *MockNodeService) ListVnets(ctx context.Context, nodeId string) (*v1.ListNodeVnetsResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVnets", ctx, nodeId)
+	ret0, _ := ret[0].(*v1.ListNodeVnetsResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVnets indicates an expected call of ListVnets.
+func (mr *MockNodeServiceMockRecorder) ListVnets(ctx, nodeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVnets", reflect.TypeOf((*MockNodeService)(nil).ListVnets), ctx, nodeId)
+}
+
+// RemoveNode mocks base method.
+func (m *MockNodeService) RemoveNode(ctx context.Context, nodeId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveNode", ctx, nodeId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveNode indicates an expected call of RemoveNode.
+func (mr *MockNodeServiceMockRecorder) RemoveNode(ctx, nodeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveNode", reflect.TypeOf((*MockNodeService)(nil).RemoveNode), ctx, nodeId)
+}
+
+// SetDraining mocks base method.
+func (m *MockNodeService) SetDraining(ctx context.Context, nodeId string, draining bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDraining", ctx, nodeId, draining)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDraining indicates an expected call of SetDraining.
+func (mr *MockNodeServiceMockRecorder) SetDraining(ctx, nodeId, draining interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDraining", reflect.TypeOf((*MockNodeService)(nil).SetDraining), ctx, nodeId, draining)
+}
+
+// WatchVnets mocks base method.
+func (m *MockNodeService) WatchVnets(ctx context.Context, nodeId string, fromRevision int64, send func(*v1.NodeVnetEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchVnets", ctx, nodeId, fromRevision, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchVnets indicates an expected call of WatchVnets.
+func (mr *MockNodeServiceMockRecorder) WatchVnets(ctx, nodeId, fromRevision, send interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchVnets", reflect.TypeOf((*MockNodeService)(nil).WatchVnets), ctx, nodeId, fromRevision, send)
+}
@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/order.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	http "net/http"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOrderService is a mock of OrderService interface.
+type MockOrderService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderServiceMockRecorder
+}
+
+// MockOrderServiceMockRecorder is the mock recorder for MockOrderService.
+type MockOrderServiceMockRecorder struct {
+	mock *MockOrderService
+}
+
+// NewMockOrderService creates a new mock instance.
+func NewMockOrderService(ctrl *gomock.Controller) *MockOrderService {
+	mock := &MockOrderService{ctrl: ctrl}
+	mock.recorder = &MockOrderServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderService) EXPECT() *MockOrderServiceMockRecorder {
+	return m.recorder
+}
+
+// CancelOrder mocks base method.
+func (m *MockOrderService) CancelOrder(ctx context.Context, userId, orderId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", ctx, userId, orderId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockOrderServiceMockRecorder) CancelOrder(ctx, userId, orderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrderService)(nil).CancelOrder), ctx, userId, orderId)
+}
+
+// CompleteMockPayment mocks base method.
+func (m *MockOrderService) CompleteMockPayment(ctx context.Context, orderId string) (*v1.OrderItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteMockPayment", ctx, orderId)
+	ret0, _ := ret[0].(*v1.OrderItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteMockPayment indicates an expected call of CompleteMockPayment.
+func (mr *MockOrderServiceMockRecorder) CompleteMockPayment(ctx, orderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteMockPayment", reflect.TypeOf((*MockOrderService)(nil).CompleteMockPayment), ctx, orderId)
+}
+
+// CreateOrder mocks base method.
+func (m *MockOrderService) CreateOrder(ctx context.Context, userId, orgId string, req *v1.PurchasePackageRequest) (*v1.PurchasePackageResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, userId, orgId, req)
+	ret0, _ := ret[0].(*v1.PurchasePackageResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockOrderServiceMockRecorder) CreateOrder(ctx, userId, orgId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockOrderService)(nil).CreateOrder), ctx, userId, orgId, req)
+}
+
+// GetOrder mocks base method.
+func (m *MockOrderService) GetOrder(ctx context.Context, userId, orderId string) (*v1.OrderItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, userId, orderId)
+	ret0, _ := ret[0].(*v1.OrderItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderServiceMockRecorder) GetOrder(ctx, userId, orderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderService)(nil).GetOrder), ctx, userId, orderId)
+}
+
+// GetOrders mocks base method.
+func (m *MockOrderService) GetOrders(ctx context.Context, userId string) (*v1.GetOrdersResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrders", ctx, userId)
+	ret0, _ := ret[0].(*v1.GetOrdersResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrders indicates an expected call of GetOrders.
+func (mr *MockOrderServiceMockRecorder) GetOrders(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockOrderService)(nil).GetOrders), ctx, userId)
+}
+
+// HandleNotification mocks base method.
+func (m *MockOrderService) HandleNotification(ctx context.Context, provider string, header http.Header, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleNotification", ctx, provider, header, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleNotification indicates an expected call of HandleNotification.
+func (mr *MockOrderServiceMockRecorder) HandleNotification(ctx, provider, header, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleNotification", reflect.TypeOf((*MockOrderService)(nil).HandleNotification), ctx, provider, header, body)
+}
+
+// PreviewOrder mocks base method.
+func (m *MockOrderService) PreviewOrder(ctx context.Context, userId, orgId string, req *v1.PurchasePackageRequest) (*v1.PreviewPurchaseResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewOrder", ctx, userId, orgId, req)
+	ret0, _ := ret[0].(*v1.PreviewPurchaseResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewOrder indicates an expected call of PreviewOrder.
+func (mr *MockOrderServiceMockRecorder) PreviewOrder(ctx, userId, orgId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewOrder", reflect.TypeOf((*MockOrderService)(nil).PreviewOrder), ctx, userId, orgId, req)
+}
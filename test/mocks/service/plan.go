@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/plan.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPlanService is a mock of PlanService interface.
+type MockPlanService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPlanServiceMockRecorder
+}
+
+// MockPlanServiceMockRecorder is the mock recorder for MockPlanService.
+type MockPlanServiceMockRecorder struct {
+	mock *MockPlanService
+}
+
+// NewMockPlanService creates a new mock instance.
+func NewMockPlanService(ctrl *gomock.Controller) *MockPlanService {
+	mock := &MockPlanService{ctrl: ctrl}
+	mock.recorder = &MockPlanServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPlanService) EXPECT() *MockPlanServiceMockRecorder {
+	return m.recorder
+}
+
+// GetCatalog mocks base method.
+func (m *MockPlanService) GetCatalog(ctx context.Context) (*model.PlanCatalog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCatalog", ctx)
+	ret0, _ := ret[0].(*model.PlanCatalog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCatalog indicates an expected call of GetCatalog.
+func (mr *MockPlanServiceMockRecorder) GetCatalog(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCatalog", reflect.TypeOf((*MockPlanService)(nil).GetCatalog), ctx)
+}
+
+// GetPlans mocks base method.
+func (m *MockPlanService) GetPlans(ctx context.Context) (*v1.GetPlansResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlans", ctx)
+	ret0, _ := ret[0].(*v1.GetPlansResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlans indicates an expected call of GetPlans.
+func (mr *MockPlanServiceMockRecorder) GetPlans(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlans", reflect.TypeOf((*MockPlanService)(nil).GetPlans), ctx)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/scheduler.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSchedulerService is a mock of SchedulerService interface.
+type MockSchedulerService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchedulerServiceMockRecorder
+}
+
+// MockSchedulerServiceMockRecorder is the mock recorder for MockSchedulerService.
+type MockSchedulerServiceMockRecorder struct {
+	mock *MockSchedulerService
+}
+
+// NewMockSchedulerService creates a new mock instance.
+func NewMockSchedulerService(ctrl *gomock.Controller) *MockSchedulerService {
+	mock := &MockSchedulerService{ctrl: ctrl}
+	mock.recorder = &MockSchedulerServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSchedulerService) EXPECT() *MockSchedulerServiceMockRecorder {
+	return m.recorder
+}
+
+// Reconcile mocks base method.
+func (m *MockSchedulerService) Reconcile(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockSchedulerServiceMockRecorder) Reconcile(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockSchedulerService)(nil).Reconcile), ctx)
+}
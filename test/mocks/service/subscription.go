@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/subscription.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSubscriptionService is a mock of SubscriptionService interface.
+type MockSubscriptionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriptionServiceMockRecorder
+}
+
+// MockSubscriptionServiceMockRecorder is the mock recorder for MockSubscriptionService.
+type MockSubscriptionServiceMockRecorder struct {
+	mock *MockSubscriptionService
+}
+
+// NewMockSubscriptionService creates a new mock instance.
+func NewMockSubscriptionService(ctrl *gomock.Controller) *MockSubscriptionService {
+	mock := &MockSubscriptionService{ctrl: ctrl}
+	mock.recorder = &MockSubscriptionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscriptionService) EXPECT() *MockSubscriptionServiceMockRecorder {
+	return m.recorder
+}
+
+// ApplyScheduledPlans mocks base method.
+func (m *MockSubscriptionService) ApplyScheduledPlans(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyScheduledPlans", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyScheduledPlans indicates an expected call of ApplyScheduledPlans.
+func (mr *MockSubscriptionServiceMockRecorder) ApplyScheduledPlans(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyScheduledPlans", reflect.TypeOf((*MockSubscriptionService)(nil).ApplyScheduledPlans), ctx)
+}
+
+// ExpirePrivileges mocks base method.
+func (m *MockSubscriptionService) ExpirePrivileges(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePrivileges", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpirePrivileges indicates an expected call of ExpirePrivileges.
+func (mr *MockSubscriptionServiceMockRecorder) ExpirePrivileges(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePrivileges", reflect.TypeOf((*MockSubscriptionService)(nil).ExpirePrivileges), ctx)
+}
+
+// ResetMonthlyTraffic mocks base method.
+func (m *MockSubscriptionService) ResetMonthlyTraffic(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetMonthlyTraffic", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetMonthlyTraffic indicates an expected call of ResetMonthlyTraffic.
+func (mr *MockSubscriptionServiceMockRecorder) ResetMonthlyTraffic(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetMonthlyTraffic", reflect.TypeOf((*MockSubscriptionService)(nil).ResetMonthlyTraffic), ctx)
+}
@@ -49,3 +49,18 @@ func (mr *MockUsageServiceMockRecorder) GetUsage(ctx, req interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockUsageService)(nil).GetUsage), ctx, req)
 }
+
+// ReportUsage mocks base method.
+func (m *MockUsageService) ReportUsage(ctx context.Context, nodeId string, req *v1.ReportUsageRequest) (*v1.ReportUsageResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportUsage", ctx, nodeId, req)
+	ret0, _ := ret[0].(*v1.ReportUsageResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReportUsage indicates an expected call of ReportUsage.
+func (mr *MockUsageServiceMockRecorder) ReportUsage(ctx, nodeId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportUsage", reflect.TypeOf((*MockUsageService)(nil).ReportUsage), ctx, nodeId, req)
+}
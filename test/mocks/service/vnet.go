@@ -8,6 +8,7 @@ import (
 	context "context"
 	v1 "hyacinth-backend/api/v1"
 	model "hyacinth-backend/internal/model"
+	service "hyacinth-backend/internal/service"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -36,6 +37,37 @@ func (m *MockVnetService) EXPECT() *MockVnetServiceMockRecorder {
 	return m.recorder
 }
 
+// ApplyPlanLimits mocks base method.
+func (m *MockVnetService) ApplyPlanLimits(ctx context.Context, userId, orgId string, plan *model.Plan) (*service.PlanLimitResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyPlanLimits", ctx, userId, orgId, plan)
+	ret0, _ := ret[0].(*service.PlanLimitResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyPlanLimits indicates an expected call of ApplyPlanLimits.
+func (mr *MockVnetServiceMockRecorder) ApplyPlanLimits(ctx, userId, orgId, plan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyPlanLimits", reflect.TypeOf((*MockVnetService)(nil).ApplyPlanLimits), ctx, userId, orgId, plan)
+}
+
+// Authorize mocks base method.
+func (m *MockVnetService) Authorize(ctx context.Context, vnetId, userId, role string) (*model.Vnet, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, vnetId, userId, role)
+	ret0, _ := ret[0].(*model.Vnet)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockVnetServiceMockRecorder) Authorize(ctx, vnetId, userId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockVnetService)(nil).Authorize), ctx, vnetId, userId, role)
+}
+
 // CheckVnetTokenExists mocks base method.
 func (m *MockVnetService) CheckVnetTokenExists(ctx context.Context, token, excludeVnetId string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -137,6 +169,21 @@ func (mr *MockVnetServiceMockRecorder) GetOnlineTunnels(ctx, userId interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOnlineTunnels", reflect.TypeOf((*MockVnetService)(nil).GetOnlineTunnels), ctx, userId)
 }
 
+// GetOrgRunningVnetCount mocks base method.
+func (m *MockVnetService) GetOrgRunningVnetCount(ctx context.Context, orgId string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrgRunningVnetCount", ctx, orgId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrgRunningVnetCount indicates an expected call of GetOrgRunningVnetCount.
+func (mr *MockVnetServiceMockRecorder) GetOrgRunningVnetCount(ctx, orgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrgRunningVnetCount", reflect.TypeOf((*MockVnetService)(nil).GetOrgRunningVnetCount), ctx, orgId)
+}
+
 // GetRunningVnetCount mocks base method.
 func (m *MockVnetService) GetRunningVnetCount(ctx context.Context, userId string) (int, error) {
 	m.ctrl.T.Helper()
@@ -152,6 +199,37 @@ func (mr *MockVnetServiceMockRecorder) GetRunningVnetCount(ctx, userId interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningVnetCount", reflect.TypeOf((*MockVnetService)(nil).GetRunningVnetCount), ctx, userId)
 }
 
+// GetSharedVnets mocks base method.
+func (m *MockVnetService) GetSharedVnets(ctx context.Context, userId string) (*[]model.Vnet, map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedVnets", ctx, userId)
+	ret0, _ := ret[0].(*[]model.Vnet)
+	ret1, _ := ret[1].(map[string]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSharedVnets indicates an expected call of GetSharedVnets.
+func (mr *MockVnetServiceMockRecorder) GetSharedVnets(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedVnets", reflect.TypeOf((*MockVnetService)(nil).GetSharedVnets), ctx, userId)
+}
+
+// GetSubscriber mocks base method.
+func (m *MockVnetService) GetSubscriber(ctx context.Context, userId, orgId string) (*model.Entitlement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriber", ctx, userId, orgId)
+	ret0, _ := ret[0].(*model.Entitlement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriber indicates an expected call of GetSubscriber.
+func (mr *MockVnetServiceMockRecorder) GetSubscriber(ctx, userId, orgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriber", reflect.TypeOf((*MockVnetService)(nil).GetSubscriber), ctx, userId, orgId)
+}
+
 // GetVnetByUserId mocks base method.
 func (m *MockVnetService) GetVnetByUserId(ctx context.Context, id string) (*[]model.Vnet, error) {
 	m.ctrl.T.Helper()
@@ -182,6 +260,34 @@ func (mr *MockVnetServiceMockRecorder) GetVnetByVnetId(ctx, id interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetByVnetId", reflect.TypeOf((*MockVnetService)(nil).GetVnetByVnetId), ctx, id)
 }
 
+// SyncOrgTrafficSuspension mocks base method.
+func (m *MockVnetService) SyncOrgTrafficSuspension(ctx context.Context, orgId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncOrgTrafficSuspension", ctx, orgId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncOrgTrafficSuspension indicates an expected call of SyncOrgTrafficSuspension.
+func (mr *MockVnetServiceMockRecorder) SyncOrgTrafficSuspension(ctx, orgId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncOrgTrafficSuspension", reflect.TypeOf((*MockVnetService)(nil).SyncOrgTrafficSuspension), ctx, orgId)
+}
+
+// SyncTrafficSuspension mocks base method.
+func (m *MockVnetService) SyncTrafficSuspension(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncTrafficSuspension", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncTrafficSuspension indicates an expected call of SyncTrafficSuspension.
+func (mr *MockVnetServiceMockRecorder) SyncTrafficSuspension(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncTrafficSuspension", reflect.TypeOf((*MockVnetService)(nil).SyncTrafficSuspension), ctx, userId)
+}
+
 // UpdateVnet mocks base method.
 func (m *MockVnetService) UpdateVnet(ctx context.Context, req *v1.UpdateVnetRequest) error {
 	m.ctrl.T.Helper()
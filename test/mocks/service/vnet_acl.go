@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/vnet_acl.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetAclService is a mock of VnetAclService interface.
+type MockVnetAclService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetAclServiceMockRecorder
+}
+
+// MockVnetAclServiceMockRecorder is the mock recorder for MockVnetAclService.
+type MockVnetAclServiceMockRecorder struct {
+	mock *MockVnetAclService
+}
+
+// NewMockVnetAclService creates a new mock instance.
+func NewMockVnetAclService(ctrl *gomock.Controller) *MockVnetAclService {
+	mock := &MockVnetAclService{ctrl: ctrl}
+	mock.recorder = &MockVnetAclServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetAclService) EXPECT() *MockVnetAclServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateRule mocks base method.
+func (m *MockVnetAclService) CreateRule(ctx context.Context, vnetId string, req *v1.AclRuleRequest) (*v1.AclRuleItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRule", ctx, vnetId, req)
+	ret0, _ := ret[0].(*v1.AclRuleItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRule indicates an expected call of CreateRule.
+func (mr *MockVnetAclServiceMockRecorder) CreateRule(ctx, vnetId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRule", reflect.TypeOf((*MockVnetAclService)(nil).CreateRule), ctx, vnetId, req)
+}
+
+// DeleteRule mocks base method.
+func (m *MockVnetAclService) DeleteRule(ctx context.Context, vnetId, ruleId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRule", ctx, vnetId, ruleId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRule indicates an expected call of DeleteRule.
+func (mr *MockVnetAclServiceMockRecorder) DeleteRule(ctx, vnetId, ruleId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRule", reflect.TypeOf((*MockVnetAclService)(nil).DeleteRule), ctx, vnetId, ruleId)
+}
+
+// GetAcl mocks base method.
+func (m *MockVnetAclService) GetAcl(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetAclResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAcl", ctx, vnet)
+	ret0, _ := ret[0].(*v1.GetVnetAclResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAcl indicates an expected call of GetAcl.
+func (mr *MockVnetAclServiceMockRecorder) GetAcl(ctx, vnet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAcl", reflect.TypeOf((*MockVnetAclService)(nil).GetAcl), ctx, vnet)
+}
+
+// SetMemberTags mocks base method.
+func (m *MockVnetAclService) SetMemberTags(ctx context.Context, vnetId, clientId string, tags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMemberTags", ctx, vnetId, clientId, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMemberTags indicates an expected call of SetMemberTags.
+func (mr *MockVnetAclServiceMockRecorder) SetMemberTags(ctx, vnetId, clientId, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMemberTags", reflect.TypeOf((*MockVnetAclService)(nil).SetMemberTags), ctx, vnetId, clientId, tags)
+}
+
+// UpdateRule mocks base method.
+func (m *MockVnetAclService) UpdateRule(ctx context.Context, vnetId, ruleId string, req *v1.AclRuleRequest) (*v1.AclRuleItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRule", ctx, vnetId, ruleId, req)
+	ret0, _ := ret[0].(*v1.AclRuleItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRule indicates an expected call of UpdateRule.
+func (mr *MockVnetAclServiceMockRecorder) UpdateRule(ctx, vnetId, ruleId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRule", reflect.TypeOf((*MockVnetAclService)(nil).UpdateRule), ctx, vnetId, ruleId, req)
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/vnet_ban.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetBanService is a mock of VnetBanService interface.
+type MockVnetBanService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetBanServiceMockRecorder
+}
+
+// MockVnetBanServiceMockRecorder is the mock recorder for MockVnetBanService.
+type MockVnetBanServiceMockRecorder struct {
+	mock *MockVnetBanService
+}
+
+// NewMockVnetBanService creates a new mock instance.
+func NewMockVnetBanService(ctrl *gomock.Controller) *MockVnetBanService {
+	mock := &MockVnetBanService{ctrl: ctrl}
+	mock.recorder = &MockVnetBanServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetBanService) EXPECT() *MockVnetBanServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateBan mocks base method.
+func (m *MockVnetBanService) CreateBan(ctx context.Context, vnetId string, req *v1.CreateVnetBanRequest) (*model.VnetBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBan", ctx, vnetId, req)
+	ret0, _ := ret[0].(*model.VnetBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBan indicates an expected call of CreateBan.
+func (mr *MockVnetBanServiceMockRecorder) CreateBan(ctx, vnetId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBan", reflect.TypeOf((*MockVnetBanService)(nil).CreateBan), ctx, vnetId, req)
+}
+
+// DeleteBan mocks base method.
+func (m *MockVnetBanService) DeleteBan(ctx context.Context, vnetId, banId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBan", ctx, vnetId, banId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBan indicates an expected call of DeleteBan.
+func (mr *MockVnetBanServiceMockRecorder) DeleteBan(ctx, vnetId, banId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBan", reflect.TypeOf((*MockVnetBanService)(nil).DeleteBan), ctx, vnetId, banId)
+}
+
+// GetBans mocks base method.
+func (m *MockVnetBanService) GetBans(ctx context.Context, vnetId string) (*[]model.VnetBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBans", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.VnetBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBans indicates an expected call of GetBans.
+func (mr *MockVnetBanServiceMockRecorder) GetBans(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBans", reflect.TypeOf((*MockVnetBanService)(nil).GetBans), ctx, vnetId)
+}
+
+// KickClient mocks base method.
+func (m *MockVnetBanService) KickClient(ctx context.Context, vnetId, clientId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KickClient", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// KickClient indicates an expected call of KickClient.
+func (mr *MockVnetBanServiceMockRecorder) KickClient(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KickClient", reflect.TypeOf((*MockVnetBanService)(nil).KickClient), ctx, vnetId, clientId)
+}
+
+// UpdateBan mocks base method.
+func (m *MockVnetBanService) UpdateBan(ctx context.Context, vnetId, banId string, req *v1.UpdateVnetBanRequest) (*model.VnetBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBan", ctx, vnetId, banId, req)
+	ret0, _ := ret[0].(*model.VnetBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBan indicates an expected call of UpdateBan.
+func (mr *MockVnetBanServiceMockRecorder) UpdateBan(ctx, vnetId, banId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBan", reflect.TypeOf((*MockVnetBanService)(nil).UpdateBan), ctx, vnetId, banId, req)
+}
@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/vnet_client.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetClientService is a mock of VnetClientService interface.
+type MockVnetClientService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetClientServiceMockRecorder
+}
+
+// MockVnetClientServiceMockRecorder is the mock recorder for MockVnetClientService.
+type MockVnetClientServiceMockRecorder struct {
+	mock *MockVnetClientService
+}
+
+// NewMockVnetClientService creates a new mock instance.
+func NewMockVnetClientService(ctrl *gomock.Controller) *MockVnetClientService {
+	mock := &MockVnetClientService{ctrl: ctrl}
+	mock.recorder = &MockVnetClientServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetClientService) EXPECT() *MockVnetClientServiceMockRecorder {
+	return m.recorder
+}
+
+// AdmitClient mocks base method.
+func (m *MockVnetClientService) AdmitClient(ctx context.Context, nodeId string, req *v1.AdmitClientRequest) (*v1.AdmitClientResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdmitClient", ctx, nodeId, req)
+	ret0, _ := ret[0].(*v1.AdmitClientResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdmitClient indicates an expected call of AdmitClient.
+func (mr *MockVnetClientServiceMockRecorder) AdmitClient(ctx, nodeId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdmitClient", reflect.TypeOf((*MockVnetClientService)(nil).AdmitClient), ctx, nodeId, req)
+}
+
+// ClientChallenge mocks base method.
+func (m *MockVnetClientService) ClientChallenge(ctx context.Context, nodeId string, req *v1.ClientChallengeRequest) (*v1.ClientChallengeResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientChallenge", ctx, nodeId, req)
+	ret0, _ := ret[0].(*v1.ClientChallengeResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClientChallenge indicates an expected call of ClientChallenge.
+func (mr *MockVnetClientServiceMockRecorder) ClientChallenge(ctx, nodeId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientChallenge", reflect.TypeOf((*MockVnetClientService)(nil).ClientChallenge), ctx, nodeId, req)
+}
+
+// ClientHeartbeat mocks base method.
+func (m *MockVnetClientService) ClientHeartbeat(ctx context.Context, nodeId string, req *v1.ClientHeartbeatRequest) (*v1.ClientHeartbeatResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientHeartbeat", ctx, nodeId, req)
+	ret0, _ := ret[0].(*v1.ClientHeartbeatResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClientHeartbeat indicates an expected call of ClientHeartbeat.
+func (mr *MockVnetClientServiceMockRecorder) ClientHeartbeat(ctx, nodeId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientHeartbeat", reflect.TypeOf((*MockVnetClientService)(nil).ClientHeartbeat), ctx, nodeId, req)
+}
+
+// ClientJoin mocks base method.
+func (m *MockVnetClientService) ClientJoin(ctx context.Context, nodeId string, req *v1.ClientJoinRequest) (*v1.ClientJoinResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientJoin", ctx, nodeId, req)
+	ret0, _ := ret[0].(*v1.ClientJoinResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClientJoin indicates an expected call of ClientJoin.
+func (mr *MockVnetClientServiceMockRecorder) ClientJoin(ctx, nodeId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientJoin", reflect.TypeOf((*MockVnetClientService)(nil).ClientJoin), ctx, nodeId, req)
+}
+
+// ClientLeave mocks base method.
+func (m *MockVnetClientService) ClientLeave(ctx context.Context, nodeId string, req *v1.ClientLeaveRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientLeave", ctx, nodeId, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClientLeave indicates an expected call of ClientLeave.
+func (mr *MockVnetClientServiceMockRecorder) ClientLeave(ctx, nodeId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientLeave", reflect.TypeOf((*MockVnetClientService)(nil).ClientLeave), ctx, nodeId, req)
+}
+
+// GetVnetClients mocks base method.
+func (m *MockVnetClientService) GetVnetClients(ctx context.Context, vnetId string) (*[]model.VnetClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVnetClients", ctx, vnetId)
+	ret0, _ := ret[0].(*[]model.VnetClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVnetClients indicates an expected call of GetVnetClients.
+func (mr *MockVnetClientServiceMockRecorder) GetVnetClients(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVnetClients", reflect.TypeOf((*MockVnetClientService)(nil).GetVnetClients), ctx, vnetId)
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/vnet_collaborator.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetCollaboratorService is a mock of VnetCollaboratorService interface.
+type MockVnetCollaboratorService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetCollaboratorServiceMockRecorder
+}
+
+// MockVnetCollaboratorServiceMockRecorder is the mock recorder for MockVnetCollaboratorService.
+type MockVnetCollaboratorServiceMockRecorder struct {
+	mock *MockVnetCollaboratorService
+}
+
+// NewMockVnetCollaboratorService creates a new mock instance.
+func NewMockVnetCollaboratorService(ctrl *gomock.Controller) *MockVnetCollaboratorService {
+	mock := &MockVnetCollaboratorService{ctrl: ctrl}
+	mock.recorder = &MockVnetCollaboratorServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetCollaboratorService) EXPECT() *MockVnetCollaboratorServiceMockRecorder {
+	return m.recorder
+}
+
+// GetAuditLogs mocks base method.
+func (m *MockVnetCollaboratorService) GetAuditLogs(ctx context.Context, vnetId string) (*v1.GetVnetAuditLogsResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogs", ctx, vnetId)
+	ret0, _ := ret[0].(*v1.GetVnetAuditLogsResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogs indicates an expected call of GetAuditLogs.
+func (mr *MockVnetCollaboratorServiceMockRecorder) GetAuditLogs(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogs", reflect.TypeOf((*MockVnetCollaboratorService)(nil).GetAuditLogs), ctx, vnetId)
+}
+
+// GetCollaborators mocks base method.
+func (m *MockVnetCollaboratorService) GetCollaborators(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetCollaboratorsResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollaborators", ctx, vnet)
+	ret0, _ := ret[0].(*v1.GetVnetCollaboratorsResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollaborators indicates an expected call of GetCollaborators.
+func (mr *MockVnetCollaboratorServiceMockRecorder) GetCollaborators(ctx, vnet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollaborators", reflect.TypeOf((*MockVnetCollaboratorService)(nil).GetCollaborators), ctx, vnet)
+}
+
+// RemoveCollaborator mocks base method.
+func (m *MockVnetCollaboratorService) RemoveCollaborator(ctx context.Context, vnet *model.Vnet, actorId, actorRole, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCollaborator", ctx, vnet, actorId, actorRole, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveCollaborator indicates an expected call of RemoveCollaborator.
+func (mr *MockVnetCollaboratorServiceMockRecorder) RemoveCollaborator(ctx, vnet, actorId, actorRole, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCollaborator", reflect.TypeOf((*MockVnetCollaboratorService)(nil).RemoveCollaborator), ctx, vnet, actorId, actorRole, userId)
+}
+
+// SetCollaborator mocks base method.
+func (m *MockVnetCollaboratorService) SetCollaborator(ctx context.Context, vnet *model.Vnet, actorId, actorRole string, req *v1.SetVnetCollaboratorRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCollaborator", ctx, vnet, actorId, actorRole, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCollaborator indicates an expected call of SetCollaborator.
+func (mr *MockVnetCollaboratorServiceMockRecorder) SetCollaborator(ctx, vnet, actorId, actorRole, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCollaborator", reflect.TypeOf((*MockVnetCollaboratorService)(nil).SetCollaborator), ctx, vnet, actorId, actorRole, req)
+}
@@ -0,0 +1,68 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/vnet_config.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	service "hyacinth-backend/internal/service"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetConfigService is a mock of VnetConfigService interface.
+type MockVnetConfigService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetConfigServiceMockRecorder
+}
+
+// MockVnetConfigServiceMockRecorder is the mock recorder for MockVnetConfigService.
+type MockVnetConfigServiceMockRecorder struct {
+	mock *MockVnetConfigService
+}
+
+// NewMockVnetConfigService creates a new mock instance.
+func NewMockVnetConfigService(ctrl *gomock.Controller) *MockVnetConfigService {
+	mock := &MockVnetConfigService{ctrl: ctrl}
+	mock.recorder = &MockVnetConfigServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetConfigService) EXPECT() *MockVnetConfigServiceMockRecorder {
+	return m.recorder
+}
+
+// RenderConfig mocks base method.
+func (m *MockVnetConfigService) RenderConfig(ctx context.Context, vnet *model.Vnet, req *v1.GetVnetConfigRequest) (*service.ClientConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderConfig", ctx, vnet, req)
+	ret0, _ := ret[0].(*service.ClientConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderConfig indicates an expected call of RenderConfig.
+func (mr *MockVnetConfigServiceMockRecorder) RenderConfig(ctx, vnet, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderConfig", reflect.TypeOf((*MockVnetConfigService)(nil).RenderConfig), ctx, vnet, req)
+}
+
+// RenderQRCode mocks base method.
+func (m *MockVnetConfigService) RenderQRCode(ctx context.Context, vnet *model.Vnet, req *v1.GetVnetConfigRequest) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderQRCode", ctx, vnet, req)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderQRCode indicates an expected call of RenderQRCode.
+func (mr *MockVnetConfigServiceMockRecorder) RenderQRCode(ctx, vnet, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderQRCode", reflect.TypeOf((*MockVnetConfigService)(nil).RenderQRCode), ctx, vnet, req)
+}
@@ -0,0 +1,106 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/vnet_event.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetEventService is a mock of VnetEventService interface.
+type MockVnetEventService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetEventServiceMockRecorder
+}
+
+// MockVnetEventServiceMockRecorder is the mock recorder for MockVnetEventService.
+type MockVnetEventServiceMockRecorder struct {
+	mock *MockVnetEventService
+}
+
+// NewMockVnetEventService creates a new mock instance.
+func NewMockVnetEventService(ctrl *gomock.Controller) *MockVnetEventService {
+	mock := &MockVnetEventService{ctrl: ctrl}
+	mock.recorder = &MockVnetEventServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetEventService) EXPECT() *MockVnetEventServiceMockRecorder {
+	return m.recorder
+}
+
+// GetLatestRevision mocks base method.
+func (m *MockVnetEventService) GetLatestRevision(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestRevision", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestRevision indicates an expected call of GetLatestRevision.
+func (mr *MockVnetEventServiceMockRecorder) GetLatestRevision(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestRevision", reflect.TypeOf((*MockVnetEventService)(nil).GetLatestRevision), ctx)
+}
+
+// Notify mocks base method.
+func (m *MockVnetEventService) Notify() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Notify")
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockVnetEventServiceMockRecorder) Notify() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockVnetEventService)(nil).Notify))
+}
+
+// Record mocks base method.
+func (m *MockVnetEventService) Record(ctx context.Context, eventType string, vnet *model.Vnet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, eventType, vnet)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockVnetEventServiceMockRecorder) Record(ctx, eventType, vnet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockVnetEventService)(nil).Record), ctx, eventType, vnet)
+}
+
+// RecordKick mocks base method.
+func (m *MockVnetEventService) RecordKick(ctx context.Context, vnet *model.Vnet, clientId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordKick", ctx, vnet, clientId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordKick indicates an expected call of RecordKick.
+func (mr *MockVnetEventServiceMockRecorder) RecordKick(ctx, vnet, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordKick", reflect.TypeOf((*MockVnetEventService)(nil).RecordKick), ctx, vnet, clientId)
+}
+
+// Watch mocks base method.
+func (m *MockVnetEventService) Watch(ctx context.Context, nodeId string, fromRevision int64, send func(*v1.NodeVnetEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, nodeId, fromRevision, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockVnetEventServiceMockRecorder) Watch(ctx, nodeId, fromRevision, send interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockVnetEventService)(nil).Watch), ctx, nodeId, fromRevision, send)
+}
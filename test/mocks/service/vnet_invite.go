@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/vnet_invite.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetInviteService is a mock of VnetInviteService interface.
+type MockVnetInviteService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetInviteServiceMockRecorder
+}
+
+// MockVnetInviteServiceMockRecorder is the mock recorder for MockVnetInviteService.
+type MockVnetInviteServiceMockRecorder struct {
+	mock *MockVnetInviteService
+}
+
+// NewMockVnetInviteService creates a new mock instance.
+func NewMockVnetInviteService(ctrl *gomock.Controller) *MockVnetInviteService {
+	mock := &MockVnetInviteService{ctrl: ctrl}
+	mock.recorder = &MockVnetInviteServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetInviteService) EXPECT() *MockVnetInviteServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateInvite mocks base method.
+func (m *MockVnetInviteService) CreateInvite(ctx context.Context, vnetId string, req *v1.CreateVnetInviteRequest) (*v1.VnetInviteItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvite", ctx, vnetId, req)
+	ret0, _ := ret[0].(*v1.VnetInviteItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvite indicates an expected call of CreateInvite.
+func (mr *MockVnetInviteServiceMockRecorder) CreateInvite(ctx, vnetId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvite", reflect.TypeOf((*MockVnetInviteService)(nil).CreateInvite), ctx, vnetId, req)
+}
+
+// GetInvites mocks base method.
+func (m *MockVnetInviteService) GetInvites(ctx context.Context, vnetId string) (*v1.GetVnetInvitesResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvites", ctx, vnetId)
+	ret0, _ := ret[0].(*v1.GetVnetInvitesResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvites indicates an expected call of GetInvites.
+func (mr *MockVnetInviteServiceMockRecorder) GetInvites(ctx, vnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvites", reflect.TypeOf((*MockVnetInviteService)(nil).GetInvites), ctx, vnetId)
+}
+
+// RedeemInvite mocks base method.
+func (m *MockVnetInviteService) RedeemInvite(ctx context.Context, ip string, req *v1.RedeemInviteRequest) (*v1.RedeemInviteResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemInvite", ctx, ip, req)
+	ret0, _ := ret[0].(*v1.RedeemInviteResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemInvite indicates an expected call of RedeemInvite.
+func (mr *MockVnetInviteServiceMockRecorder) RedeemInvite(ctx, ip, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemInvite", reflect.TypeOf((*MockVnetInviteService)(nil).RedeemInvite), ctx, ip, req)
+}
+
+// RevokeInvite mocks base method.
+func (m *MockVnetInviteService) RevokeInvite(ctx context.Context, vnetId, inviteId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvite", ctx, vnetId, inviteId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeInvite indicates an expected call of RevokeInvite.
+func (mr *MockVnetInviteServiceMockRecorder) RevokeInvite(ctx, vnetId, inviteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvite", reflect.TypeOf((*MockVnetInviteService)(nil).RevokeInvite), ctx, vnetId, inviteId)
+}
@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/vnet_member.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetMemberService is a mock of VnetMemberService interface.
+type MockVnetMemberService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetMemberServiceMockRecorder
+}
+
+// MockVnetMemberServiceMockRecorder is the mock recorder for MockVnetMemberService.
+type MockVnetMemberServiceMockRecorder struct {
+	mock *MockVnetMemberService
+}
+
+// NewMockVnetMemberService creates a new mock instance.
+func NewMockVnetMemberService(ctrl *gomock.Controller) *MockVnetMemberService {
+	mock := &MockVnetMemberService{ctrl: ctrl}
+	mock.recorder = &MockVnetMemberServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetMemberService) EXPECT() *MockVnetMemberServiceMockRecorder {
+	return m.recorder
+}
+
+// ApproveMember mocks base method.
+func (m *MockVnetMemberService) ApproveMember(ctx context.Context, vnetId, clientId, deviceKeyHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveMember", ctx, vnetId, clientId, deviceKeyHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveMember indicates an expected call of ApproveMember.
+func (mr *MockVnetMemberServiceMockRecorder) ApproveMember(ctx, vnetId, clientId, deviceKeyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveMember", reflect.TypeOf((*MockVnetMemberService)(nil).ApproveMember), ctx, vnetId, clientId, deviceKeyHash)
+}
+
+// GetMembers mocks base method.
+func (m *MockVnetMemberService) GetMembers(ctx context.Context, vnetId, status string) (*[]model.VnetMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", ctx, vnetId, status)
+	ret0, _ := ret[0].(*[]model.VnetMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockVnetMemberServiceMockRecorder) GetMembers(ctx, vnetId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockVnetMemberService)(nil).GetMembers), ctx, vnetId, status)
+}
+
+// RejectMember mocks base method.
+func (m *MockVnetMemberService) RejectMember(ctx context.Context, vnetId, clientId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectMember", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RejectMember indicates an expected call of RejectMember.
+func (mr *MockVnetMemberServiceMockRecorder) RejectMember(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectMember", reflect.TypeOf((*MockVnetMemberService)(nil).RejectMember), ctx, vnetId, clientId)
+}
+
+// RemoveMember mocks base method.
+func (m *MockVnetMemberService) RemoveMember(ctx context.Context, vnetId, clientId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockVnetMemberServiceMockRecorder) RemoveMember(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockVnetMemberService)(nil).RemoveMember), ctx, vnetId, clientId)
+}
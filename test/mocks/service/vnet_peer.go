@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/vnet_peer.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetPeerService is a mock of VnetPeerService interface.
+type MockVnetPeerService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetPeerServiceMockRecorder
+}
+
+// MockVnetPeerServiceMockRecorder is the mock recorder for MockVnetPeerService.
+type MockVnetPeerServiceMockRecorder struct {
+	mock *MockVnetPeerService
+}
+
+// NewMockVnetPeerService creates a new mock instance.
+func NewMockVnetPeerService(ctrl *gomock.Controller) *MockVnetPeerService {
+	mock := &MockVnetPeerService{ctrl: ctrl}
+	mock.recorder = &MockVnetPeerServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetPeerService) EXPECT() *MockVnetPeerServiceMockRecorder {
+	return m.recorder
+}
+
+// DeletePeer mocks base method.
+func (m *MockVnetPeerService) DeletePeer(ctx context.Context, vnetId, clientId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePeer", ctx, vnetId, clientId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePeer indicates an expected call of DeletePeer.
+func (mr *MockVnetPeerServiceMockRecorder) DeletePeer(ctx, vnetId, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePeer", reflect.TypeOf((*MockVnetPeerService)(nil).DeletePeer), ctx, vnetId, clientId)
+}
+
+// GetPeers mocks base method.
+func (m *MockVnetPeerService) GetPeers(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetPeersResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPeers", ctx, vnet)
+	ret0, _ := ret[0].(*v1.GetVnetPeersResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPeers indicates an expected call of GetPeers.
+func (mr *MockVnetPeerServiceMockRecorder) GetPeers(ctx, vnet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeers", reflect.TypeOf((*MockVnetPeerService)(nil).GetPeers), ctx, vnet)
+}
+
+// RegisterPeer mocks base method.
+func (m *MockVnetPeerService) RegisterPeer(ctx context.Context, vnetId string, req *v1.RegisterVnetPeerRequest) (*v1.RegisterVnetPeerResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterPeer", ctx, vnetId, req)
+	ret0, _ := ret[0].(*v1.RegisterVnetPeerResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterPeer indicates an expected call of RegisterPeer.
+func (mr *MockVnetPeerServiceMockRecorder) RegisterPeer(ctx, vnetId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterPeer", reflect.TypeOf((*MockVnetPeerService)(nil).RegisterPeer), ctx, vnetId, req)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/vnet_route.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	v1 "hyacinth-backend/api/v1"
+	model "hyacinth-backend/internal/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVnetRouteService is a mock of VnetRouteService interface.
+type MockVnetRouteService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVnetRouteServiceMockRecorder
+}
+
+// MockVnetRouteServiceMockRecorder is the mock recorder for MockVnetRouteService.
+type MockVnetRouteServiceMockRecorder struct {
+	mock *MockVnetRouteService
+}
+
+// NewMockVnetRouteService creates a new mock instance.
+func NewMockVnetRouteService(ctrl *gomock.Controller) *MockVnetRouteService {
+	mock := &MockVnetRouteService{ctrl: ctrl}
+	mock.recorder = &MockVnetRouteServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVnetRouteService) EXPECT() *MockVnetRouteServiceMockRecorder {
+	return m.recorder
+}
+
+// AdvertiseRoute mocks base method.
+func (m *MockVnetRouteService) AdvertiseRoute(ctx context.Context, vnetId string, req *v1.AdvertiseRouteRequest) (*v1.VnetRouteItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvertiseRoute", ctx, vnetId, req)
+	ret0, _ := ret[0].(*v1.VnetRouteItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdvertiseRoute indicates an expected call of AdvertiseRoute.
+func (mr *MockVnetRouteServiceMockRecorder) AdvertiseRoute(ctx, vnetId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvertiseRoute", reflect.TypeOf((*MockVnetRouteService)(nil).AdvertiseRoute), ctx, vnetId, req)
+}
+
+// ApproveRoute mocks base method.
+func (m *MockVnetRouteService) ApproveRoute(ctx context.Context, vnetId, routeId string) (*v1.VnetRouteItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveRoute", ctx, vnetId, routeId)
+	ret0, _ := ret[0].(*v1.VnetRouteItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveRoute indicates an expected call of ApproveRoute.
+func (mr *MockVnetRouteServiceMockRecorder) ApproveRoute(ctx, vnetId, routeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveRoute", reflect.TypeOf((*MockVnetRouteService)(nil).ApproveRoute), ctx, vnetId, routeId)
+}
+
+// DeleteRoute mocks base method.
+func (m *MockVnetRouteService) DeleteRoute(ctx context.Context, vnetId, routeId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRoute", ctx, vnetId, routeId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRoute indicates an expected call of DeleteRoute.
+func (mr *MockVnetRouteServiceMockRecorder) DeleteRoute(ctx, vnetId, routeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoute", reflect.TypeOf((*MockVnetRouteService)(nil).DeleteRoute), ctx, vnetId, routeId)
+}
+
+// GetRoutes mocks base method.
+func (m *MockVnetRouteService) GetRoutes(ctx context.Context, vnet *model.Vnet) (*v1.GetVnetRoutesResponseData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoutes", ctx, vnet)
+	ret0, _ := ret[0].(*v1.GetVnetRoutesResponseData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoutes indicates an expected call of GetRoutes.
+func (mr *MockVnetRouteServiceMockRecorder) GetRoutes(ctx, vnet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoutes", reflect.TypeOf((*MockVnetRouteService)(nil).GetRoutes), ctx, vnet)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/handler"
+	"hyacinth-backend/internal/middleware"
+	"hyacinth-backend/internal/model"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/viper"
+)
+
+func adminConf(admins ...string) *viper.Viper {
+	conf := viper.New()
+	conf.Set("admin.user_ids", admins)
+	return conf
+}
+
+func TestAdminHandler_ListNodes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	mockNodeService := mock_service.NewMockNodeService(ctrl)
+	mockNodeService.EXPECT().ListNodes(gomock.Any()).Return(&[]model.Node{
+		{NodeId: "node_1", Region: "cn-east", Capacity: 500, Status: model.NodeStatusOnline, LastHeartbeat: &now, ClientsConnected: 12},
+	}, nil)
+
+	testRouter := createTestRouter()
+
+	adminHandler := handler.NewAdminHandler(hdl, mockNodeService)
+	testRouter.Use(middleware.StrictAuth(jwt, logger), middleware.AdminAuth(adminConf(userId), logger))
+	testRouter.GET("/admin/nodes", adminHandler.ListNodes)
+
+	obj := newHttpExcept(t, testRouter).GET("/admin/nodes").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("code").IsEqual(0)
+	nodes := obj.Value("data").Object().Value("nodes").Array()
+	nodes.Length().IsEqual(1)
+	nodes.Value(0).Object().Value("nodeId").IsEqual("node_1")
+	nodes.Value(0).Object().Value("status").IsEqual(model.NodeStatusOnline)
+	nodes.Value(0).Object().Value("clientsConnected").IsEqual(12)
+}
+
+func TestAdminHandler_ListNodes_Forbidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNodeService := mock_service.NewMockNodeService(ctrl)
+
+	testRouter := createTestRouter()
+
+	// 当前用户不在管理员列表中
+	adminHandler := handler.NewAdminHandler(hdl, mockNodeService)
+	testRouter.Use(middleware.StrictAuth(jwt, logger), middleware.AdminAuth(adminConf("admin_user"), logger))
+	testRouter.GET("/admin/nodes", adminHandler.ListNodes)
+
+	newHttpExcept(t, testRouter).GET("/admin/nodes").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusForbidden)
+}
+
+func TestAdminHandler_RemoveNode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNodeService := mock_service.NewMockNodeService(ctrl)
+	mockNodeService.EXPECT().RemoveNode(gomock.Any(), "relay-1").Return(v1.ErrNodeStatic)
+	mockNodeService.EXPECT().RemoveNode(gomock.Any(), "node_2").Return(v1.ErrNotFound)
+	mockNodeService.EXPECT().RemoveNode(gomock.Any(), "node_1").Return(nil)
+
+	testRouter := createTestRouter()
+
+	adminHandler := handler.NewAdminHandler(hdl, mockNodeService)
+	testRouter.Use(middleware.StrictAuth(jwt, logger), middleware.AdminAuth(adminConf(userId), logger))
+	testRouter.DELETE("/admin/nodes/:nodeId", adminHandler.RemoveNode)
+
+	e := newHttpExcept(t, testRouter)
+	// 静态节点只能从配置中移除
+	e.DELETE("/admin/nodes/relay-1").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.DELETE("/admin/nodes/node_2").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusNotFound)
+	e.DELETE("/admin/nodes/node_1").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/handler"
+	"hyacinth-backend/internal/middleware"
+	"hyacinth-backend/internal/model"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestNotificationHandler_GetNotifications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNotificationService := mock_service.NewMockNotificationService(ctrl)
+	mockNotificationService.EXPECT().GetNotifications(gomock.Any(), userId).Return(&v1.GetNotificationsResponseData{
+		Notifications: []v1.NotificationItem{
+			{Id: 2, Type: model.NotificationPrivilegeExpired, Title: "套餐已到期", CreatedAt: "2026-10-17 12:00:00"},
+			{Id: 1, Type: model.NotificationPrivilegeExpired, Title: "套餐已到期", CreatedAt: "2026-09-01 12:00:00", ReadAt: "2026-09-02 08:00:00"},
+		},
+		Unread: 1,
+	}, nil)
+
+	testRouter := createTestRouter()
+
+	notificationHandler := handler.NewNotificationHandler(hdl, mockNotificationService)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.GET("/user/notifications", notificationHandler.GetNotifications)
+
+	data := newHttpExcept(t, testRouter).GET("/user/notifications").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().Value("data").Object()
+	data.Value("unread").IsEqual(1)
+	notifications := data.Value("notifications").Array()
+	notifications.Length().IsEqual(2)
+	notifications.Value(0).Object().NotContainsKey("readAt")
+	notifications.Value(1).Object().Value("readAt").IsEqual("2026-09-02 08:00:00")
+}
+
+func TestNotificationHandler_MarkNotificationsRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNotificationService := mock_service.NewMockNotificationService(ctrl)
+	mockNotificationService.EXPECT().MarkAllRead(gomock.Any(), userId).Return(nil)
+
+	testRouter := createTestRouter()
+
+	notificationHandler := handler.NewNotificationHandler(hdl, mockNotificationService)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.POST("/user/notifications/read", notificationHandler.MarkNotificationsRead)
+
+	newHttpExcept(t, testRouter).POST("/user/notifications/read").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().Value("code").IsEqual(0)
+}
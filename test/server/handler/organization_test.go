@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/handler"
+	"hyacinth-backend/internal/middleware"
+	"hyacinth-backend/internal/model"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestOrganizationHandler_Members(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgId := "org_1"
+	org := &model.Organization{OrgId: orgId}
+
+	mockOrganizationService := mock_service.NewMockOrganizationService(ctrl)
+	mockOrganizationService.EXPECT().Authorize(gomock.Any(), orgId, userId, model.OrgRoleMember).Return(org, model.OrgRoleMember, nil)
+	mockOrganizationService.EXPECT().GetMembers(gomock.Any(), orgId).Return(&v1.GetOrganizationMembersResponseData{
+		Members: []v1.OrganizationMemberItem{{UserId: userId, Username: "testuser", Role: model.OrgRoleMember}},
+	}, nil)
+	// 普通成员不能管理成员
+	mockOrganizationService.EXPECT().Authorize(gomock.Any(), orgId, userId, model.OrgRoleAdmin).Return(nil, "", v1.ErrForbidden)
+
+	testRouter := createTestRouter()
+
+	organizationHandler := handler.NewOrganizationHandler(hdl, mockOrganizationService, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.GET("/org/:orgId/members", organizationHandler.GetOrganizationMembers)
+	testRouter.PUT("/org/:orgId/members", organizationHandler.SetOrganizationMember)
+
+	e := newHttpExcept(t, testRouter)
+	members := e.GET("/org/"+orgId+"/members").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().Value("data").Object().Value("members").Array()
+	members.Length().IsEqual(1)
+	members.Value(0).Object().Value("role").IsEqual(model.OrgRoleMember)
+
+	e.PUT("/org/"+orgId+"/members").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(v1.SetOrganizationMemberRequest{Account: "bob", Role: model.OrgRoleMember}).
+		Expect().
+		Status(http.StatusForbidden)
+}
+
+func TestUserHandler_CreateVNet_NotOrgMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := v1.CreateVnetRequest{
+		VnetProfile: v1.VnetProfile{
+			Comment:      "组织网络",
+			Enabled:      true,
+			Token:        "orgtoken",
+			Password:     "password",
+			IpRange:      "10.0.0.0/24",
+			ClientsLimit: 3,
+		},
+		OrgId: "org_1",
+	}
+
+	mockOrganizationService := mock_service.NewMockOrganizationService(ctrl)
+	mockOrganizationService.EXPECT().Authorize(gomock.Any(), "org_1", userId, model.OrgRoleMember).Return(nil, "", v1.ErrForbidden)
+
+	testRouter := createTestRouter()
+
+	userHandler := handler.NewUserHandler(hdl, nil, nil, mock_service.NewMockVnetService(ctrl), mockOrganizationService, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.POST("/vnet", userHandler.CreateVNet)
+
+	newHttpExcept(t, testRouter).POST("/vnet").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(params).
+		Expect().
+		Status(http.StatusForbidden)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/handler"
+	"hyacinth-backend/internal/middleware"
+	"hyacinth-backend/internal/model"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+)
+
+// bronzeTraffic 测试套餐目录中青铜套餐的月流量
+const bronzeTraffic int64 = 50 * 1024 * 1024 * 1024
+
+// testCatalog 与配置文件中初始套餐目录相同的套餐目录
+func testCatalog() *model.PlanCatalog {
+	features := []string{model.PlanFeatureWireGuard, model.PlanFeatureRoutes}
+	return model.NewPlanCatalog([]model.Plan{
+		{UserGroup: 1, Name: "普通用户", VnetLimit: 1, ClientsPerVnet: 3, AclRulesPerVnet: 10, Features: features},
+		{UserGroup: 2, Name: "青铜用户", MonthlyPrice: 1000, Durations: []int{1, 3, 6, 12}, MonthlyTraffic: bronzeTraffic, VnetLimit: 3, ClientsPerVnet: 5, AclRulesPerVnet: 50, Features: features, Purchasable: true},
+		{UserGroup: 3, Name: "白银用户", MonthlyPrice: 3000, Durations: []int{1, 3, 6, 12}, VnetLimit: 5, ClientsPerVnet: 10, AclRulesPerVnet: 100, Features: features, Purchasable: true},
+		{UserGroup: 4, Name: "黄金用户", MonthlyPrice: 10000, Durations: []int{1, 3, 6, 12}, VnetLimit: 10, ClientsPerVnet: 999999, AclRulesPerVnet: 500, Features: features, Purchasable: true},
+	})
+}
+
+// entitle 按测试套餐目录获取账户的权益
+func entitle(subscriber model.Subscriber) *model.Entitlement {
+	return testCatalog().Entitle(subscriber)
+}
+
+func TestPlanHandler_GetPlans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPlanService := mock_service.NewMockPlanService(ctrl)
+	mockPlanService.EXPECT().GetPlans(gomock.Any()).Return(&v1.GetPlansResponseData{
+		Plans: []v1.PlanItem{
+			{UserGroup: 1, Name: "普通用户", Durations: []int{}, Features: []string{}},
+			{UserGroup: 2, Name: "青铜用户", MonthlyPrice: 1000, Currency: model.OrderCurrency, Durations: []int{1, 3}, Features: []string{model.PlanFeatureWireGuard}, Purchasable: true},
+		},
+	}, nil)
+
+	testRouter := createTestRouter()
+
+	planHandler := handler.NewPlanHandler(hdl, mockPlanService)
+	// 商店页不需要登录
+	testRouter.GET("/plans", planHandler.GetPlans)
+
+	plans := newHttpExcept(t, testRouter).GET("/plans").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().Value("data").Object().Value("plans").Array()
+	plans.Length().IsEqual(2)
+	plans.Value(1).Object().Value("monthlyPrice").IsEqual(1000)
+	plans.Value(1).Object().Value("durations").Array().Length().IsEqual(2)
+}
+
+func TestUserHandler_CreateVNet_WireGuardNotInPlan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := v1.CreateVnetRequest{
+		VnetProfile: v1.VnetProfile{
+			Comment:      "wg",
+			ClientsLimit: 3,
+			Enabled:      true,
+		},
+		Type: model.VnetTypeWireGuard,
+	}
+
+	// 免费套餐未开通 WireGuard
+	catalog := model.NewPlanCatalog([]model.Plan{{UserGroup: 1, Name: "普通用户", VnetLimit: 1, ClientsPerVnet: 3}})
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetService.EXPECT().GetSubscriber(gomock.Any(), userId, "").Return(catalog.Entitle(&model.User{UserId: userId, UserGroup: 1, RemainingTraffic: 1024}), nil)
+
+	testRouter := createTestRouter()
+
+	userHandler := handler.NewUserHandler(hdl, mock_service.NewMockUserService(ctrl), mock_service.NewMockUsageService(ctrl), mockVnetService, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.POST("/vnet", userHandler.CreateVNet)
+
+	newHttpExcept(t, testRouter).POST("/vnet").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(params).
+		Expect().
+		Status(http.StatusForbidden).
+		JSON().
+		Object().Value("code").IsEqual(1031)
+}
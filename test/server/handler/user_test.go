@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	v1 "hyacinth-backend/api/v1"
 	"hyacinth-backend/internal/handler"
 	"hyacinth-backend/internal/middleware"
@@ -45,7 +46,7 @@ func TestUserHandler_Register(t *testing.T) {
 	// 设置期望的方法调用
 	mockUserService.EXPECT().Register(gomock.Any(), &params).Return(nil)
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.POST("/register", userHandler.Register)
 
 	obj := newHttpExcept(t, testRouter).POST("/register").
@@ -82,7 +83,7 @@ func TestUserHandler_Login(t *testing.T) {
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.POST("/login", userHandler.Login)
 
 	obj := newHttpExcept(t, testRouter).POST("/login").
@@ -126,7 +127,7 @@ func TestUserHandler_GetProfile(t *testing.T) {
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.NoStrictAuth(jwt, logger))
 	testRouter.GET("/user", userHandler.GetProfile)
 
@@ -173,7 +174,7 @@ func TestUserHandler_UpdateProfile(t *testing.T) {
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.PUT("/user", userHandler.UpdateProfile)
 
@@ -201,22 +202,24 @@ func TestUserHandler_PurchasePackage_Success(t *testing.T) {
 	mockUserService := mock_service.NewMockUserService(ctrl)
 	mockUsageService := mock_service.NewMockUsageService(ctrl)
 	mockVnetService := mock_service.NewMockVnetService(ctrl)
-
-	// 模拟当前用户信息（普通用户升级到白银）
-	currentUser := &model.User{
-		UserId:    userId,
-		Username:  "testuser",
-		Email:     "test@gmail.com",
-		UserGroup: 1, // 普通用户
-	}
-
-	// 设置期望的方法调用
-	mockUserService.EXPECT().GetUserByID(gomock.Any(), userId).Return(currentUser, nil)
-	mockUserService.EXPECT().PurchasePackage(gomock.Any(), userId, &params).Return(nil)
+	mockOrderService := mock_service.NewMockOrderService(ctrl)
+
+	// 下单后返回订单与支付地址，套餐在付款后才生效
+	mockOrderService.EXPECT().CreateOrder(gomock.Any(), userId, "", &params).Return(&v1.PurchasePackageResponseData{
+		Order: v1.OrderItem{
+			OrderId:     "order_1",
+			PackageType: 3,
+			Duration:    1,
+			Amount:      3000,
+			Currency:    model.OrderCurrency,
+			Status:      model.OrderPendingPayment,
+		},
+		PayUrl: "http://127.0.0.1:8000/v1/payment/mock/checkout?orderId=order_1",
+	}, nil)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, mockOrderService)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.POST("/user/purchase", userHandler.PurchasePackage)
 
@@ -230,36 +233,30 @@ func TestUserHandler_PurchasePackage_Success(t *testing.T) {
 		Object()
 	obj.Value("code").IsEqual(0)
 	obj.Value("message").IsEqual("ok")
+	obj.Value("data").Object().Value("payUrl").IsEqual("http://127.0.0.1:8000/v1/payment/mock/checkout?orderId=order_1")
+	obj.Value("data").Object().Value("order").Object().Value("status").IsEqual(model.OrderPendingPayment)
 }
 
-func TestUserHandler_PurchasePackage_SameLevel(t *testing.T) {
+func TestUserHandler_PurchasePackage_DowngradeWithClientsLimitExceeded(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	params := v1.PurchasePackageRequest{
-		PackageType: 2, // 青铜套餐
-		Duration:    3, // 3个月
+		PackageType: 2, // 降级到青铜套餐
+		Duration:    1, // 1个月
 	}
 
 	mockUserService := mock_service.NewMockUserService(ctrl)
 	mockUsageService := mock_service.NewMockUsageService(ctrl)
 	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockOrderService := mock_service.NewMockOrderService(ctrl)
 
-	// 模拟当前用户信息（青铜用户续费）
-	currentUser := &model.User{
-		UserId:    userId,
-		Username:  "testuser",
-		Email:     "test@gmail.com",
-		UserGroup: 2, // 青铜用户
-	}
-
-	// 设置期望的方法调用
-	mockUserService.EXPECT().GetUserByID(gomock.Any(), userId).Return(currentUser, nil)
-	mockUserService.EXPECT().PurchasePackage(gomock.Any(), userId, &params).Return(nil)
+	// 有虚拟网络的连接数超过青铜套餐限制时拒绝下单
+	mockOrderService.EXPECT().CreateOrder(gomock.Any(), userId, "", &params).Return(nil, v1.ErrVnetClientsLimitExceeded)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, mockOrderService)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.POST("/user/purchase", userHandler.PurchasePackage)
 
@@ -268,61 +265,82 @@ func TestUserHandler_PurchasePackage_SameLevel(t *testing.T) {
 		WithHeader("Authorization", "Bearer "+genToken(t)).
 		WithJSON(params).
 		Expect().
-		Status(http.StatusOK).
+		Status(http.StatusBadRequest).
 		JSON().
 		Object()
-	obj.Value("code").IsEqual(0)
-	obj.Value("message").IsEqual("ok")
+	obj.Value("code").IsEqual(1006)
 }
 
-func TestUserHandler_PurchasePackage_DowngradeWithClientsLimitExceeded(t *testing.T) {
+func TestUserHandler_PreviewPurchase(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	params := v1.PurchasePackageRequest{
-		PackageType: 2, // 降级到青铜套餐
-		Duration:    1, // 1个月
+		PackageType: 4, // 升级到黄金套餐
+		Duration:    1,
 	}
 
 	mockUserService := mock_service.NewMockUserService(ctrl)
 	mockUsageService := mock_service.NewMockUsageService(ctrl)
 	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockOrderService := mock_service.NewMockOrderService(ctrl)
 
-	// 模拟当前用户信息（白银用户降级到青铜）
-	currentUser := &model.User{
-		UserId:    userId,
-		Username:  "testuser",
-		Email:     "test@gmail.com",
-		UserGroup: 3, // 白银用户
-	}
+	mockOrderService.EXPECT().PreviewOrder(gomock.Any(), userId, "", &params).Return(&v1.PreviewPurchaseResponseData{
+		Kind:        model.PlanChangeUpgrade,
+		PackageType: 4,
+		Duration:    1,
+		Amount:      10000,
+		Currency:    model.OrderCurrency,
+		EffectiveAt: "2026-03-01 12:00:00",
+		ExpiresAt:   "2026-04-13 12:00:00",
+		CreditDays:  12,
+	}, nil)
 
-	// 模拟虚拟网络数据，其中有一个网络的连接数超过青铜套餐限制
-	vnets := &[]model.Vnet{
-		{
-			VnetId:       "vnet1",
-			UserId:       userId,
-			ClientsLimit: 8, // 超过青铜套餐的5人限制
-			Enabled:      true,
-		},
-		{
-			VnetId:       "vnet2",
-			UserId:       userId,
-			ClientsLimit: 3, // 未超过限制
-			Enabled:      true,
-		},
+	testRouter := createTestRouter()
+
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, mockOrderService)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.POST("/user/purchase/preview", userHandler.PreviewPurchase)
+
+	obj := newHttpExcept(t, testRouter).POST("/user/purchase/preview").
+		WithHeader("Content-Type", "application/json").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(params).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("code").IsEqual(0)
+	data := obj.Value("data").Object()
+	data.Value("kind").IsEqual(model.PlanChangeUpgrade)
+	data.Value("amount").IsEqual(10000)
+	data.Value("creditDays").IsEqual(12)
+}
+
+func TestUserHandler_PreviewPurchase_DowngradeScheduled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := v1.PurchasePackageRequest{
+		PackageType: 2,
+		Duration:    1,
 	}
 
-	// 设置期望的方法调用
-	mockUserService.EXPECT().GetUserByID(gomock.Any(), userId).Return(currentUser, nil)
-	mockVnetService.EXPECT().GetVnetByUserId(gomock.Any(), userId).Return(vnets, nil)
+	mockUserService := mock_service.NewMockUserService(ctrl)
+	mockUsageService := mock_service.NewMockUsageService(ctrl)
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockOrderService := mock_service.NewMockOrderService(ctrl)
+
+	// 已有待生效的降级时不能再降级到其他套餐
+	mockOrderService.EXPECT().PreviewOrder(gomock.Any(), userId, "", &params).Return(nil, v1.ErrCannotDowngrade)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, mockOrderService)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
-	testRouter.POST("/user/purchase", userHandler.PurchasePackage)
+	testRouter.POST("/user/purchase/preview", userHandler.PreviewPurchase)
 
-	obj := newHttpExcept(t, testRouter).POST("/user/purchase").
+	obj := newHttpExcept(t, testRouter).POST("/user/purchase/preview").
 		WithHeader("Content-Type", "application/json").
 		WithHeader("Authorization", "Bearer "+genToken(t)).
 		WithJSON(params).
@@ -330,8 +348,7 @@ func TestUserHandler_PurchasePackage_DowngradeWithClientsLimitExceeded(t *testin
 		Status(http.StatusBadRequest).
 		JSON().
 		Object()
-	// 验证返回的错误码不为0
-	obj.Value("code").Number().Gt(0)
+	obj.Value("code").IsEqual(1004)
 }
 
 func TestUserHandler_PurchasePackage_InvalidPackageType(t *testing.T) {
@@ -346,10 +363,13 @@ func TestUserHandler_PurchasePackage_InvalidPackageType(t *testing.T) {
 	mockUserService := mock_service.NewMockUserService(ctrl)
 	mockUsageService := mock_service.NewMockUsageService(ctrl)
 	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockOrderService := mock_service.NewMockOrderService(ctrl)
+	// 套餐是否存在由套餐目录决定
+	mockOrderService.EXPECT().CreateOrder(gomock.Any(), userId, "", &params).Return(nil, v1.ErrBadRequest)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, mockOrderService)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.POST("/user/purchase", userHandler.PurchasePackage)
 
@@ -377,13 +397,14 @@ func TestUserHandler_PurchasePackage_UserNotFound(t *testing.T) {
 	mockUserService := mock_service.NewMockUserService(ctrl)
 	mockUsageService := mock_service.NewMockUsageService(ctrl)
 	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockOrderService := mock_service.NewMockOrderService(ctrl)
 
 	// 设置期望的方法调用，模拟用户未找到
-	mockUserService.EXPECT().GetUserByID(gomock.Any(), userId).Return(nil, v1.ErrNotFound)
+	mockOrderService.EXPECT().CreateOrder(gomock.Any(), userId, "", &params).Return(nil, v1.ErrNotFound)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, mockOrderService)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.POST("/user/purchase", userHandler.PurchasePackage)
 
@@ -392,7 +413,7 @@ func TestUserHandler_PurchasePackage_UserNotFound(t *testing.T) {
 		WithHeader("Authorization", "Bearer "+genToken(t)).
 		WithJSON(params).
 		Expect().
-		Status(http.StatusInternalServerError).
+		Status(http.StatusNotFound).
 		JSON().
 		Object()
 	// 验证返回的错误码不为0
@@ -425,7 +446,7 @@ func TestUserHandler_GetUsage(t *testing.T) {
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.NoStrictAuth(jwt, logger))
 	testRouter.GET("/usage", userHandler.GetUsage)
 
@@ -442,6 +463,53 @@ func TestUserHandler_GetUsage(t *testing.T) {
 	objData.Value("usages").Array().Length().IsEqual(2)
 }
 
+func TestUserHandler_GetUsage_Vnet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mock_service.NewMockUserService(ctrl)
+	mockUsageService := mock_service.NewMockUsageService(ctrl)
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+
+	usageData := &v1.GetUsageResponseData{
+		Usages: []v1.UsageData{{Date: "2024-01", Usage: 1024}},
+	}
+
+	// 协作者按虚拟网络查询，不再限定为自己名下的流量记录
+	req := v1.GetUsageRequest{
+		VnetId: "vnet_1",
+		Range:  "7d",
+	}
+	mockVnetService.EXPECT().Authorize(gomock.Any(), "vnet_1", userId, model.VnetRoleViewer).
+		Return(&model.Vnet{VnetId: "vnet_1", UserId: "owner"}, model.VnetRoleViewer, nil)
+	mockUsageService.EXPECT().GetUsage(gomock.Any(), &req).Return(usageData, nil)
+	mockVnetService.EXPECT().Authorize(gomock.Any(), "vnet_2", userId, model.VnetRoleViewer).
+		Return(nil, "", v1.ErrForbidden)
+
+	testRouter := createTestRouter()
+
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
+	testRouter.Use(middleware.NoStrictAuth(jwt, logger))
+	testRouter.GET("/usage", userHandler.GetUsage)
+
+	obj := newHttpExcept(t, testRouter).GET("/usage").
+		WithQuery("range", "7d").
+		WithQuery("vnetId", "vnet_1").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("data").Object().Value("usages").Array().Length().IsEqual(1)
+
+	newHttpExcept(t, testRouter).GET("/usage").
+		WithQuery("range", "7d").
+		WithQuery("vnetId", "vnet_2").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusForbidden)
+}
+
 func TestUserHandler_GetVNetList(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -458,7 +526,7 @@ func TestUserHandler_GetVNetList(t *testing.T) {
 			Comment:       "测试网络1",
 			Enabled:       true,
 			Token:         "token1",
-			Password:      "password1",
+			PasswordHash:  "hash1",
 			IpRange:       "192.168.1.0/24",
 			EnableDHCP:    true,
 			ClientsLimit:  5,
@@ -470,7 +538,7 @@ func TestUserHandler_GetVNetList(t *testing.T) {
 			Comment:       "测试网络2",
 			Enabled:       false,
 			Token:         "token2",
-			Password:      "password2",
+			PasswordHash:  "hash2",
 			IpRange:       "192.168.2.0/24",
 			EnableDHCP:    false,
 			ClientsLimit:  10,
@@ -478,12 +546,18 @@ func TestUserHandler_GetVNetList(t *testing.T) {
 		},
 	}
 
+	// 其他用户共享的虚拟网络
+	shared := &[]model.Vnet{
+		{VnetId: "vnet3", UserId: "otheruser", Comment: "共享网络", Enabled: true, Token: "token3"},
+	}
+
 	// 设置期望的方法调用
 	mockVnetService.EXPECT().GetVnetByUserId(gomock.Any(), userId).Return(vnets, nil)
+	mockVnetService.EXPECT().GetSharedVnets(gomock.Any(), userId).Return(shared, map[string]string{"vnet3": model.VnetRoleViewer}, nil)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.GET("/vnet", userHandler.GetVNetList)
 
@@ -496,7 +570,17 @@ func TestUserHandler_GetVNetList(t *testing.T) {
 	obj.Value("code").IsEqual(0)
 	obj.Value("message").IsEqual("ok")
 	objData := obj.Value("data").Object()
-	objData.Value("vnets").Array().Length().IsEqual(2)
+	objData.Value("vnets").Array().Length().IsEqual(3)
+	// 不返回密码及其校验值
+	vnet := objData.Value("vnets").Array().Value(0).Object()
+	vnet.NotContainsKey("password")
+	vnet.NotContainsKey("passwordHash")
+	vnet.Value("hasPassword").IsEqual(true)
+	vnet.Value("role").IsEqual(model.VnetRoleOwner)
+	// 查看者看不到接入令牌
+	sharedVnet := objData.Value("vnets").Array().Value(2).Object()
+	sharedVnet.Value("role").IsEqual(model.VnetRoleViewer)
+	sharedVnet.Value("token").IsEqual("")
 }
 
 func TestUserHandler_CreateVNet(t *testing.T) {
@@ -522,22 +606,23 @@ func TestUserHandler_CreateVNet(t *testing.T) {
 	// 模拟用户信息
 	futureTime := time.Now().Add(30 * 24 * time.Hour) // 30天后过期
 	currentUser := &model.User{
-		UserId:          userId,
-		Username:        "testuser",
-		Email:           "test@gmail.com",
-		UserGroup:       2,           // 青铜用户
-		PrivilegeExpiry: &futureTime, // 设置未过期的特权
+		UserId:           userId,
+		Username:         "testuser",
+		Email:            "test@gmail.com",
+		UserGroup:        2,           // 青铜用户
+		PrivilegeExpiry:  &futureTime, // 设置未过期的特权
+		RemainingTraffic: bronzeTraffic,
 	}
 
 	// 设置期望的方法调用
 	mockVnetService.EXPECT().CheckVnetTokenExists(gomock.Any(), params.Token, "").Return(false, nil)
-	mockUserService.EXPECT().GetUserByID(gomock.Any(), userId).Return(currentUser, nil)
+	mockVnetService.EXPECT().GetSubscriber(gomock.Any(), userId, "").Return(entitle(currentUser), nil)
 	mockVnetService.EXPECT().GetRunningVnetCount(gomock.Any(), userId).Return(1, nil)
 	mockVnetService.EXPECT().CreateVnet(gomock.Any(), gomock.Any(), userId).Return(nil)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.POST("/vnet", userHandler.CreateVNet)
 
@@ -578,7 +663,7 @@ func TestUserHandler_CreateVNet_TokenExists(t *testing.T) {
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.POST("/vnet", userHandler.CreateVNet)
 
@@ -593,6 +678,99 @@ func TestUserHandler_CreateVNet_TokenExists(t *testing.T) {
 	obj.Value("code").Number().Gt(0)
 }
 
+func TestUserHandler_CreateVNet_IpRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := v1.CreateVnetRequest{
+		VnetProfile: v1.VnetProfile{
+			Comment:      "新建测试网络",
+			Enabled:      false,
+			Token:        "newtoken",
+			Password:     "newpassword",
+			ClientsLimit: 3,
+		},
+	}
+
+	mockUserService := mock_service.NewMockUserService(ctrl)
+	mockUsageService := mock_service.NewMockUsageService(ctrl)
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+
+	mockVnetService.EXPECT().CheckVnetTokenExists(gomock.Any(), params.Token, "").Return(false, nil).Times(2)
+	mockVnetService.EXPECT().GetSubscriber(gomock.Any(), userId, "").Return(entitle(&model.User{UserId: userId, UserGroup: 1, RemainingTraffic: 1024}), nil).Times(2)
+	// 网段留空时由服务自动分配并回填
+	mockVnetService.EXPECT().CreateVnet(gomock.Any(), gomock.Any(), userId).DoAndReturn(func(ctx context.Context, req *v1.CreateVnetRequest, userId string) error {
+		req.IpRange = "10.0.0.0/24"
+		return nil
+	})
+	mockVnetService.EXPECT().CreateVnet(gomock.Any(), gomock.Any(), userId).Return(v1.ErrIpRangeOverlap)
+
+	testRouter := createTestRouter()
+
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.POST("/vnet", userHandler.CreateVNet)
+
+	obj := newHttpExcept(t, testRouter).POST("/vnet").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(params).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("data").Object().Value("ipRange").IsEqual("10.0.0.0/24")
+
+	params.IpRange = "10.0.0.0/16"
+	obj = newHttpExcept(t, testRouter).POST("/vnet").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(params).
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON().
+		Object()
+	obj.Value("code").IsEqual(1014)
+}
+
+func TestUserHandler_CreateVNet_TrafficExhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := v1.CreateVnetRequest{
+		VnetProfile: v1.VnetProfile{
+			Comment:      "新建测试网络",
+			Enabled:      true,
+			Token:        "newtoken",
+			Password:     "newpassword",
+			IpRange:      "192.168.100.0/24",
+			ClientsLimit: 1,
+		},
+	}
+
+	mockUserService := mock_service.NewMockUserService(ctrl)
+	mockUsageService := mock_service.NewMockUsageService(ctrl)
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+
+	// 流量已耗尽的用户不能启用虚拟网络
+	mockVnetService.EXPECT().CheckVnetTokenExists(gomock.Any(), params.Token, "").Return(false, nil)
+	mockVnetService.EXPECT().GetSubscriber(gomock.Any(), userId, "").Return(entitle(&model.User{UserId: userId, UserGroup: 1}), nil)
+
+	testRouter := createTestRouter()
+
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.POST("/vnet", userHandler.CreateVNet)
+
+	obj := newHttpExcept(t, testRouter).POST("/vnet").
+		WithHeader("Content-Type", "application/json").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(params).
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON().
+		Object()
+	obj.Value("code").IsEqual(1009)
+}
+
 func TestUserHandler_UpdateVNet(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -617,33 +795,34 @@ func TestUserHandler_UpdateVNet(t *testing.T) {
 	// 模拟用户信息
 	futureTime := time.Now().Add(30 * 24 * time.Hour) // 30天后过期
 	currentUser := &model.User{
-		UserId:          userId,
-		Username:        "testuser",
-		Email:           "test@gmail.com",
-		UserGroup:       3,           // 白银用户
-		PrivilegeExpiry: &futureTime, // 设置未过期的特权
+		UserId:           userId,
+		Username:         "testuser",
+		Email:            "test@gmail.com",
+		UserGroup:        3,           // 白银用户
+		PrivilegeExpiry:  &futureTime, // 设置未过期的特权
+		RemainingTraffic: bronzeTraffic,
 	}
 
 	// 模拟现有的虚拟网络
 	existingVnet := &model.Vnet{
-		VnetId:   vnetId,
-		UserId:   userId,
-		Enabled:  false,
-		Comment:  "原始网络",
-		Token:    "oldtoken",
-		Password: "oldpassword",
+		VnetId:       vnetId,
+		UserId:       userId,
+		Enabled:      false,
+		Comment:      "原始网络",
+		Token:        "oldtoken",
+		PasswordHash: "oldhash",
 	}
 
 	// 设置期望的方法调用
-	mockVnetService.EXPECT().GetVnetByVnetId(gomock.Any(), vnetId).Return(existingVnet, nil)
-	mockUserService.EXPECT().GetUserByID(gomock.Any(), userId).Return(currentUser, nil)
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(existingVnet, model.VnetRoleOwner, nil)
+	mockVnetService.EXPECT().GetSubscriber(gomock.Any(), userId, "").Return(entitle(currentUser), nil)
 	mockVnetService.EXPECT().GetRunningVnetCount(gomock.Any(), userId).Return(1, nil)
 	mockVnetService.EXPECT().CheckVnetTokenExists(gomock.Any(), params.Token, vnetId).Return(false, nil)
 	mockVnetService.EXPECT().UpdateVnet(gomock.Any(), gomock.Any()).Return(nil)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.PUT("/vnet/:vnetId", userHandler.UpdateVNet)
 
@@ -681,11 +860,11 @@ func TestUserHandler_UpdateVNet_NotFound(t *testing.T) {
 	mockVnetService := mock_service.NewMockVnetService(ctrl)
 
 	// 设置期望的方法调用 - vnet不存在
-	mockVnetService.EXPECT().GetVnetByVnetId(gomock.Any(), vnetId).Return(nil, v1.ErrNotFound)
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(nil, "", v1.ErrNotFound)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.PUT("/vnet/:vnetId", userHandler.UpdateVNet)
 
@@ -712,21 +891,21 @@ func TestUserHandler_DeleteVNet(t *testing.T) {
 
 	// 模拟现有的虚拟网络
 	existingVnet := &model.Vnet{
-		VnetId:   vnetId,
-		UserId:   userId,
-		Enabled:  true,
-		Comment:  "待删除的网络",
-		Token:    "deletetoken",
-		Password: "deletepassword",
+		VnetId:       vnetId,
+		UserId:       userId,
+		Enabled:      true,
+		Comment:      "待删除的网络",
+		Token:        "deletetoken",
+		PasswordHash: "deletehash",
 	}
 
 	// 设置期望的方法调用
-	mockVnetService.EXPECT().GetVnetByVnetId(gomock.Any(), vnetId).Return(existingVnet, nil)
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(existingVnet, model.VnetRoleOwner, nil)
 	mockVnetService.EXPECT().DeleteVnet(gomock.Any(), gomock.Any()).Return(nil)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.DELETE("/vnet/:vnetId", userHandler.DeleteVNet)
 
@@ -750,22 +929,12 @@ func TestUserHandler_DeleteVNet_Forbidden(t *testing.T) {
 	mockUsageService := mock_service.NewMockUsageService(ctrl)
 	mockVnetService := mock_service.NewMockVnetService(ctrl)
 
-	// 模拟其他用户的虚拟网络
-	existingVnet := &model.Vnet{
-		VnetId:   vnetId,
-		UserId:   "otheruser", // 不同的用户
-		Enabled:  true,
-		Comment:  "其他用户的网络",
-		Token:    "othertoken",
-		Password: "otherpassword",
-	}
-
-	// 设置期望的方法调用
-	mockVnetService.EXPECT().GetVnetByVnetId(gomock.Any(), vnetId).Return(existingVnet, nil)
+	// 不是所有者，只有协作者角色
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, model.VnetRoleOwner).Return(nil, "", v1.ErrForbidden)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.DELETE("/vnet/:vnetId", userHandler.DeleteVNet)
 
@@ -799,7 +968,7 @@ func TestUserHandler_GetUserGroup(t *testing.T) {
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.NoStrictAuth(jwt, logger))
 	testRouter.GET("/user/group", userHandler.GetUserGroup)
 
@@ -836,12 +1005,12 @@ func TestUserHandler_GetVNetLimitInfo(t *testing.T) {
 	currentRunningCount := 2
 
 	// 设置期望的方法调用
-	mockUserService.EXPECT().GetUserByID(gomock.Any(), userId).Return(currentUser, nil)
+	mockVnetService.EXPECT().GetSubscriber(gomock.Any(), userId, "").Return(entitle(currentUser), nil)
 	mockVnetService.EXPECT().GetRunningVnetCount(gomock.Any(), userId).Return(currentRunningCount, nil)
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.GET("/vnet/limit", userHandler.GetVNetLimitInfo)
 
@@ -876,7 +1045,7 @@ func TestUserHandler_ChangePassword(t *testing.T) {
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.PUT("/user/password", userHandler.ChangePassword)
 
@@ -910,7 +1079,7 @@ func TestUserHandler_ChangePassword_WrongCurrentPassword(t *testing.T) {
 
 	testRouter := createTestRouter()
 
-	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService)
+	userHandler := handler.NewUserHandler(hdl, mockUserService, mockUsageService, mockVnetService, nil, nil)
 	testRouter.Use(middleware.StrictAuth(jwt, logger))
 	testRouter.PUT("/user/password", userHandler.ChangePassword)
 
@@ -924,3 +1093,34 @@ func TestUserHandler_ChangePassword_WrongCurrentPassword(t *testing.T) {
 		Object()
 	obj.Value("code").Number().Gt(0)
 }
+
+func TestOrderHandler_PaymentNotify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mock_service.NewMockOrderService(ctrl)
+
+	// 回调原样交给服务层校验签名
+	mockOrderService.EXPECT().HandleNotification(gomock.Any(), "mock", gomock.Any(), []byte(`{"orderId":"order_1"}`)).Return(nil)
+	mockOrderService.EXPECT().HandleNotification(gomock.Any(), "mock", gomock.Any(), []byte(`{"orderId":"order_2"}`)).Return(v1.ErrInvalidPaymentSignature)
+
+	testRouter := createTestRouter()
+
+	orderHandler := handler.NewOrderHandler(hdl, mockOrderService)
+	testRouter.POST("/payment/notify/:provider", orderHandler.PaymentNotify)
+
+	newHttpExcept(t, testRouter).POST("/payment/notify/mock").
+		WithHeader("Content-Type", "application/json").
+		WithBytes([]byte(`{"orderId":"order_1"}`)).
+		Expect().
+		Status(http.StatusOK)
+
+	newHttpExcept(t, testRouter).POST("/payment/notify/mock").
+		WithHeader("Content-Type", "application/json").
+		WithBytes([]byte(`{"orderId":"order_2"}`)).
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON().
+		Object().
+		Value("code").IsEqual(1029)
+}
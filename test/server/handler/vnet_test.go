@@ -0,0 +1,532 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/handler"
+	"hyacinth-backend/internal/middleware"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestVnetHandler_GetVnetClients(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+	now := time.Now()
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetClientService := mock_service.NewMockVnetClientService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(&model.Vnet{VnetId: vnetId, UserId: userId}, model.VnetRoleOwner, nil)
+	mockVnetClientService.EXPECT().GetVnetClients(gomock.Any(), vnetId).Return(&[]model.VnetClient{
+		{VnetId: vnetId, ClientId: "client_1", VirtualIp: "10.0.0.2", NodeId: "relay-1", ConnectedAt: now, LastSeen: now},
+	}, nil)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, mockVnetClientService, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.GET("/vnet/:vnetId/clients", vnetHandler.GetVnetClients)
+
+	obj := newHttpExcept(t, testRouter).GET("/vnet/"+vnetId+"/clients").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("code").IsEqual(0)
+	clients := obj.Value("data").Object().Value("clients").Array()
+	clients.Length().IsEqual(1)
+	clients.Value(0).Object().Value("clientId").IsEqual("client_1")
+	clients.Value(0).Object().Value("virtualIp").IsEqual("10.0.0.2")
+}
+
+func TestVnetHandler_GetVnetClients_Forbidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetClientService := mock_service.NewMockVnetClientService(ctrl)
+
+	// 虚拟网络属于其他用户
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(nil, "", v1.ErrForbidden)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, mockVnetClientService, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.GET("/vnet/:vnetId/clients", vnetHandler.GetVnetClients)
+
+	newHttpExcept(t, testRouter).GET("/vnet/"+vnetId+"/clients").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusForbidden)
+}
+
+func TestVnetHandler_GetVnetLeases(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+	expiry := time.Now().Add(time.Hour)
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockIpamService := mock_service.NewMockIpamService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(&model.Vnet{VnetId: vnetId, UserId: userId}, model.VnetRoleOwner, nil)
+	mockIpamService.EXPECT().GetLeases(gomock.Any(), vnetId).Return(&[]model.IpLease{
+		{VnetId: vnetId, ClientId: "client_1", Address: "10.0.0.2", ExpiresAt: &expiry},
+		{VnetId: vnetId, ClientId: "client_2", Address: "10.0.0.100", Static: true},
+	}, nil)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, mockIpamService, nil, nil, nil, nil, nil, nil, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.GET("/vnet/:vnetId/leases", vnetHandler.GetVnetLeases)
+
+	obj := newHttpExcept(t, testRouter).GET("/vnet/"+vnetId+"/leases").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	leases := obj.Value("data").Object().Value("leases").Array()
+	leases.Length().IsEqual(2)
+	leases.Value(0).Object().Value("active").IsEqual(true)
+	leases.Value(1).Object().Value("static").IsEqual(true)
+	leases.Value(1).Object().Value("expiresAt").IsEqual("")
+}
+
+func TestVnetHandler_ReserveAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockIpamService := mock_service.NewMockIpamService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(&model.Vnet{VnetId: vnetId, UserId: userId}, model.VnetRoleOwner, nil).Times(2)
+	mockIpamService.EXPECT().ReserveAddress(gomock.Any(), vnetId, &v1.ReserveAddressRequest{ClientId: "client_1", Address: "10.0.0.10"}).
+		Return(&model.IpLease{VnetId: vnetId, ClientId: "client_1", Address: "10.0.0.10", Static: true}, nil)
+	mockIpamService.EXPECT().ReserveAddress(gomock.Any(), vnetId, &v1.ReserveAddressRequest{ClientId: "client_2", Address: "10.0.0.10"}).
+		Return(nil, v1.ErrVirtualIpUnavailable)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, mockIpamService, nil, nil, nil, nil, nil, nil, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.POST("/vnet/:vnetId/leases/reservations", vnetHandler.ReserveAddress)
+
+	obj := newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/leases/reservations").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(map[string]string{"clientId": "client_1", "address": "10.0.0.10"}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("data").Object().Value("address").IsEqual("10.0.0.10")
+
+	newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/leases/reservations").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(map[string]string{"clientId": "client_2", "address": "10.0.0.10"}).
+		Expect().
+		Status(http.StatusConflict)
+}
+
+func TestVnetHandler_AclRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetAclService := mock_service.NewMockVnetAclService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(&model.Vnet{VnetId: vnetId, UserId: userId}, model.VnetRoleOwner, nil).AnyTimes()
+	mockVnetAclService.EXPECT().CreateRule(gomock.Any(), vnetId, &v1.AclRuleRequest{Action: "deny", Source: "tag:guest", Destination: "10.0.0.1", Protocol: "tcp", Ports: "22"}).
+		Return(&v1.AclRuleItem{RuleId: "acl_1", Priority: 10, Action: "deny", Source: "tag:guest", Destination: "10.0.0.1/32", Protocol: "tcp", Ports: "22"}, nil)
+	mockVnetAclService.EXPECT().CreateRule(gomock.Any(), vnetId, gomock.Any()).Return(nil, v1.ErrAclRuleLimitExceeded)
+	mockVnetAclService.EXPECT().DeleteRule(gomock.Any(), vnetId, "acl_2").Return(v1.ErrNotFound)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, nil, mockVnetAclService, nil, nil, nil, nil, nil, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.POST("/vnet/:vnetId/acl/rules", vnetHandler.CreateAclRule)
+	testRouter.DELETE("/vnet/:vnetId/acl/rules/:ruleId", vnetHandler.DeleteAclRule)
+
+	obj := newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/acl/rules").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(map[string]string{"action": "deny", "source": "tag:guest", "destination": "10.0.0.1", "protocol": "tcp", "ports": "22"}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("data").Object().Value("ruleId").IsEqual("acl_1")
+	obj.Value("data").Object().Value("destination").IsEqual("10.0.0.1/32")
+
+	// 动作不合法时直接拒绝
+	newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/acl/rules").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(map[string]string{"action": "drop", "source": "*", "destination": "*"}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/acl/rules").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(map[string]string{"action": "allow", "source": "*", "destination": "*"}).
+		Expect().
+		Status(http.StatusForbidden).
+		JSON().
+		Object().
+		Value("code").IsEqual(1019)
+
+	newHttpExcept(t, testRouter).DELETE("/vnet/"+vnetId+"/acl/rules/acl_2").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestVnetHandler_GetVnetAcl_Forbidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetAclService := mock_service.NewMockVnetAclService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(nil, "", v1.ErrForbidden)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, nil, mockVnetAclService, nil, nil, nil, nil, nil, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.GET("/vnet/:vnetId/acl", vnetHandler.GetVnetAcl)
+
+	newHttpExcept(t, testRouter).GET("/vnet/"+vnetId+"/acl").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusForbidden)
+}
+
+func TestVnetHandler_Members(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+	now := time.Now()
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetMemberService := mock_service.NewMockVnetMemberService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(&model.Vnet{VnetId: vnetId, UserId: userId}, model.VnetRoleOwner, nil).AnyTimes()
+	mockVnetMemberService.EXPECT().GetMembers(gomock.Any(), vnetId, "pending").Return(&[]model.VnetMember{
+		{VnetId: vnetId, ClientId: "client_1", Status: model.VnetMemberPending, PublicEndpoint: "203.0.113.5:4000", LastSeen: now},
+	}, nil)
+	mockVnetMemberService.EXPECT().ApproveMember(gomock.Any(), vnetId, "client_1", "").Return(nil)
+	mockVnetMemberService.EXPECT().RejectMember(gomock.Any(), vnetId, "client_2").Return(v1.ErrNotFound)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, nil, nil, mockVnetMemberService, nil, nil, nil, nil, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.GET("/vnet/:vnetId/members", vnetHandler.GetVnetMembers)
+	testRouter.POST("/vnet/:vnetId/members/:clientId/approve", vnetHandler.ApproveMember)
+	testRouter.POST("/vnet/:vnetId/members/:clientId/reject", vnetHandler.RejectMember)
+
+	obj := newHttpExcept(t, testRouter).GET("/vnet/"+vnetId+"/members").
+		WithQuery("status", "pending").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	members := obj.Value("data").Object().Value("members").Array()
+	members.Length().IsEqual(1)
+	members.Value(0).Object().Value("status").IsEqual("pending")
+	members.Value(0).Object().Value("reviewedAt").IsEqual("")
+
+	newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/members/client_1/approve").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK)
+
+	newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/members/client_2/reject").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestVnetHandler_Bans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetBanService := mock_service.NewMockVnetBanService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(&model.Vnet{VnetId: vnetId, UserId: userId}, model.VnetRoleOwner, nil).AnyTimes()
+	mockVnetBanService.EXPECT().CreateBan(gomock.Any(), vnetId, &v1.CreateVnetBanRequest{Ip: "203.0.113.0/24", Reason: "spam"}).
+		Return(&model.VnetBan{BanId: "ban_1", VnetId: vnetId, Ip: "203.0.113.0/24", Reason: "spam"}, nil)
+	mockVnetBanService.EXPECT().CreateBan(gomock.Any(), vnetId, &v1.CreateVnetBanRequest{ClientId: "client_1"}).
+		Return(&model.VnetBan{BanId: "ban_2", VnetId: vnetId, ClientId: "client_1"}, nil)
+	mockVnetBanService.EXPECT().KickClient(gomock.Any(), vnetId, "client_2").Return(v1.ErrNotFound)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, nil, nil, nil, mockVnetBanService, nil, nil, nil, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.POST("/vnet/:vnetId/bans", vnetHandler.CreateVnetBan)
+	testRouter.POST("/vnet/:vnetId/members/:clientId/ban", vnetHandler.BanMember)
+	testRouter.POST("/vnet/:vnetId/clients/:clientId/kick", vnetHandler.KickClient)
+
+	obj := newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/bans").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(v1.CreateVnetBanRequest{Ip: "203.0.113.0/24", Reason: "spam"}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	data := obj.Value("data").Object()
+	data.Value("banId").IsEqual("ban_1")
+	data.Value("active").IsEqual(true)
+	data.Value("expiresAt").IsEqual("")
+
+	// 审批列表中的封禁等同于按设备标识永久封禁
+	newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/members/client_1/ban").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK)
+
+	newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/clients/client_2/kick").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestVnetHandler_Invites(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetInviteService := mock_service.NewMockVnetInviteService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, gomock.Any()).Return(&model.Vnet{VnetId: vnetId, UserId: userId}, model.VnetRoleOwner, nil).AnyTimes()
+	mockVnetInviteService.EXPECT().GetInvites(gomock.Any(), vnetId).Return(&v1.GetVnetInvitesResponseData{Invites: []v1.VnetInviteItem{
+		{InviteId: "inv_1", Code: "code_1", MaxUses: 5, Uses: 1, Active: true, Redemptions: []v1.VnetInviteRedemptionItem{{ClientId: "client_1", Name: "laptop"}}},
+	}}, nil)
+	mockVnetInviteService.EXPECT().RedeemInvite(gomock.Any(), gomock.Any(), &v1.RedeemInviteRequest{Code: "code_1", ClientId: "client_2"}).
+		Return(nil, v1.ErrInviteUnavailable)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, nil, nil, nil, nil, mockVnetInviteService, nil, nil, nil, nil)
+	testRouter.POST("/invite/redeem", vnetHandler.RedeemInvite)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.GET("/vnet/:vnetId/invites", vnetHandler.GetVnetInvites)
+
+	obj := newHttpExcept(t, testRouter).GET("/vnet/"+vnetId+"/invites").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	invites := obj.Value("data").Object().Value("invites").Array()
+	invites.Length().IsEqual(1)
+	invites.Value(0).Object().Value("redemptions").Array().Value(0).Object().Value("name").IsEqual("laptop")
+
+	// 兑换邀请无需登录
+	newHttpExcept(t, testRouter).POST("/invite/redeem").
+		WithJSON(v1.RedeemInviteRequest{Code: "code_1", ClientId: "client_2"}).
+		Expect().
+		Status(http.StatusGone).
+		JSON().
+		Object().
+		Value("code").IsEqual(1022)
+}
+
+func TestVnetHandler_Collaborators(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+	vnet := &model.Vnet{VnetId: vnetId, UserId: "owner"}
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetCollaboratorService := mock_service.NewMockVnetCollaboratorService(ctrl)
+
+	// 当前用户是操作员：可以查看协作者，但不能授予角色
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, model.VnetRoleViewer).Return(vnet, model.VnetRoleOperator, nil).AnyTimes()
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, model.VnetRoleAdmin).Return(nil, "", v1.ErrForbidden).AnyTimes()
+	mockVnetCollaboratorService.EXPECT().GetCollaborators(gomock.Any(), vnet).Return(&v1.GetVnetCollaboratorsResponseData{Collaborators: []v1.VnetCollaboratorItem{
+		{UserId: "owner", Role: model.VnetRoleOwner},
+		{UserId: userId, Role: model.VnetRoleOperator},
+	}}, nil)
+	mockVnetCollaboratorService.EXPECT().RemoveCollaborator(gomock.Any(), vnet, userId, model.VnetRoleOperator, userId).Return(nil)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, nil, nil, nil, nil, nil, mockVnetCollaboratorService, nil, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.GET("/vnet/:vnetId/collaborators", vnetHandler.GetVnetCollaborators)
+	testRouter.PUT("/vnet/:vnetId/collaborators", vnetHandler.SetVnetCollaborator)
+	testRouter.DELETE("/vnet/:vnetId/collaborators/:userId", vnetHandler.RemoveVnetCollaborator)
+
+	obj := newHttpExcept(t, testRouter).GET("/vnet/"+vnetId+"/collaborators").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("data").Object().Value("collaborators").Array().Length().IsEqual(2)
+
+	newHttpExcept(t, testRouter).PUT("/vnet/"+vnetId+"/collaborators").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(v1.SetVnetCollaboratorRequest{Account: "bob", Role: model.VnetRoleViewer}).
+		Expect().
+		Status(http.StatusForbidden)
+
+	// 协作者可以退出协作
+	newHttpExcept(t, testRouter).DELETE("/vnet/"+vnetId+"/collaborators/"+userId).
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK)
+}
+
+func TestVnetHandler_GetVnetConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+	vnet := &model.Vnet{VnetId: vnetId, UserId: userId}
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetConfigService := mock_service.NewMockVnetConfigService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, model.VnetRoleOperator).Return(vnet, model.VnetRoleOwner, nil).Times(2)
+	mockVnetConfigService.EXPECT().RenderConfig(gomock.Any(), vnet, &v1.GetVnetConfigRequest{Format: "n2n"}).Return(&service.ClientConfig{
+		FileName:    "vnet1.edge.conf",
+		ContentType: "text/plain; charset=utf-8",
+		Content:     []byte("-c=token\n"),
+	}, nil)
+	mockVnetConfigService.EXPECT().RenderConfig(gomock.Any(), vnet, &v1.GetVnetConfigRequest{Format: "wireguard"}).Return(nil, v1.ErrVnetNodeUnassigned)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, nil, nil, nil, nil, nil, nil, mockVnetConfigService, nil, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.GET("/vnet/:vnetId/config", vnetHandler.GetVnetConfig)
+
+	resp := newHttpExcept(t, testRouter).GET("/vnet/"+vnetId+"/config").
+		WithQuery("format", "n2n").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK)
+	resp.Header("Content-Disposition").IsEqual(`attachment; filename="vnet1.edge.conf"`)
+	resp.Body().IsEqual("-c=token\n")
+
+	// 虚拟网络尚未分配节点
+	newHttpExcept(t, testRouter).GET("/vnet/"+vnetId+"/config").
+		WithQuery("format", "wireguard").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusConflict)
+}
+
+func TestVnetHandler_RegisterVnetPeer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+	vnet := &model.Vnet{VnetId: vnetId, UserId: userId, Type: model.VnetTypeWireGuard}
+	req := v1.RegisterVnetPeerRequest{ClientId: "client_1", Generate: true}
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetPeerService := mock_service.NewMockVnetPeerService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, model.VnetRoleOperator).Return(vnet, model.VnetRoleOwner, nil).Times(2)
+	mockVnetPeerService.EXPECT().RegisterPeer(gomock.Any(), vnetId, &req).Return(&v1.RegisterVnetPeerResponseData{
+		VnetPeerItem: v1.VnetPeerItem{ClientId: "client_1", PublicKey: "public", Address: "10.0.0.2", AllowedIps: []string{"10.0.0.2/32"}},
+		PrivateKey:   "private",
+	}, nil)
+	mockVnetPeerService.EXPECT().RegisterPeer(gomock.Any(), vnetId, gomock.Any()).Return(nil, v1.ErrInvalidWireGuardKey)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, nil, nil, nil, nil, nil, nil, nil, mockVnetPeerService, nil)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.PUT("/vnet/:vnetId/peers", vnetHandler.RegisterVnetPeer)
+
+	obj := newHttpExcept(t, testRouter).PUT("/vnet/"+vnetId+"/peers").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(req).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("code").IsEqual(0)
+	obj.Value("data").Object().Value("privateKey").IsEqual("private")
+	obj.Value("data").Object().Value("address").IsEqual("10.0.0.2")
+
+	newHttpExcept(t, testRouter).PUT("/vnet/"+vnetId+"/peers").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		WithJSON(v1.RegisterVnetPeerRequest{ClientId: "client_1", PublicKey: "bad"}).
+		Expect().
+		Status(http.StatusBadRequest)
+}
+
+func TestVnetHandler_ApproveRoute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vnetId := "vnet1"
+	vnet := &model.Vnet{VnetId: vnetId, UserId: userId}
+
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockVnetRouteService := mock_service.NewMockVnetRouteService(ctrl)
+
+	mockVnetService.EXPECT().Authorize(gomock.Any(), vnetId, userId, model.VnetRoleAdmin).Return(vnet, model.VnetRoleOwner, nil).Times(2)
+	mockVnetRouteService.EXPECT().ApproveRoute(gomock.Any(), vnetId, "route_1").Return(&v1.VnetRouteItem{
+		RouteId: "route_1", ClientId: "client_1", Prefix: "192.168.1.0/24", Status: model.VnetRouteApproved,
+	}, nil)
+	mockVnetRouteService.EXPECT().ApproveRoute(gomock.Any(), vnetId, "route_2").Return(nil, v1.ErrRouteConflict)
+
+	testRouter := createTestRouter()
+
+	vnetHandler := handler.NewVnetHandler(hdl, mockVnetService, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockVnetRouteService)
+	testRouter.Use(middleware.StrictAuth(jwt, logger))
+	testRouter.POST("/vnet/:vnetId/routes/:routeId/approve", vnetHandler.ApproveRoute)
+
+	obj := newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/routes/route_1/approve").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("code").IsEqual(0)
+	obj.Value("data").Object().Value("status").IsEqual(model.VnetRouteApproved)
+
+	newHttpExcept(t, testRouter).POST("/vnet/"+vnetId+"/routes/route_2/approve").
+		WithHeader("Authorization", "Bearer "+genToken(t)).
+		Expect().
+		Status(http.StatusConflict).
+		JSON().
+		Object().
+		Value("code").IsEqual(1027)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupIpLeaseRepository(t *testing.T) (repository.IpLeaseRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	ipLeaseRepo := repository.NewIpLeaseRepository(repo)
+
+	return ipLeaseRepo, mock
+}
+
+func TestIpLeaseRepository_GetLeaseByClientId(t *testing.T) {
+	ipLeaseRepo, mock := setupIpLeaseRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `ip_leases` WHERE (vnet_id = ? AND client_id = ?) AND `ip_leases`.`deleted_at` IS NULL ORDER BY `ip_leases`.`id` LIMIT ?")).
+		WithArgs("vnet_1", "client_1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vnet_id", "client_id", "address", "static"}).AddRow(1, "vnet_1", "client_1", "10.0.0.2", false))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `ip_leases`")).
+		WithArgs("vnet_1", "client_2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	lease, err := ipLeaseRepo.GetLeaseByClientId(ctx, "vnet_1", "client_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", lease.Address)
+
+	// 不存在时返回 nil 而不是错误
+	lease, err = ipLeaseRepo.GetLeaseByClientId(ctx, "vnet_1", "client_2")
+	assert.NoError(t, err)
+	assert.Nil(t, lease)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIpLeaseRepository_DeleteLeasesByAddress(t *testing.T) {
+	ipLeaseRepo, mock := setupIpLeaseRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `ip_leases` WHERE vnet_id = ? AND address = ? AND client_id <> ?")).
+		WithArgs("vnet_1", "10.0.0.3", "client_9").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := ipLeaseRepo.DeleteLeasesByAddress(ctx, "vnet_1", "10.0.0.3", "client_9")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIpLeaseRepository_DeleteExpiredLeases(t *testing.T) {
+	ipLeaseRepo, mock := setupIpLeaseRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `ip_leases` WHERE static = ? AND expires_at < ? AND NOT EXISTS (SELECT 1 FROM `vnet_clients` WHERE (vnet_clients.vnet_id = ip_leases.vnet_id AND vnet_clients.client_id = ip_leases.client_id) AND `vnet_clients`.`deleted_at` IS NULL)")).
+		WithArgs(false, now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `ip_leases` WHERE vnet_id NOT IN (SELECT `vnet_id` FROM `vnets` WHERE `vnets`.`deleted_at` IS NULL)")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	count, err := ipLeaseRepo.DeleteExpiredLeases(ctx, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
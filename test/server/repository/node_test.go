@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupNodeRepository(t *testing.T) (repository.NodeRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	nodeRepo := repository.NewNodeRepository(repo)
+
+	return nodeRepo, mock
+}
+
+func TestNodeRepository_ConsumeBootstrapToken(t *testing.T) {
+	nodeRepo, mock := setupNodeRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `node_bootstrap_tokens` SET `node_id`=?,`used_at`=?,`updated_at`=? WHERE (token_hash = ? AND used_at IS NULL AND expires_at > ?) AND `node_bootstrap_tokens`.`deleted_at` IS NULL")).
+		WithArgs("node_1", now, sqlmock.AnyArg(), "hash_1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `node_bootstrap_tokens` WHERE token_hash = ?")).
+		WithArgs("hash_1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "token_hash", "region", "node_id"}).AddRow(1, "hash_1", "cn-east", "node_1"))
+
+	token, err := nodeRepo.ConsumeBootstrapToken(ctx, "hash_1", "node_1", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "cn-east", token.Region)
+
+	// 令牌已被使用或已过期时不更新任何行，返回 nil
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `node_bootstrap_tokens`")).
+		WithArgs("node_2", now, sqlmock.AnyArg(), "hash_1", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	token, err = nodeRepo.ConsumeBootstrapToken(ctx, "hash_1", "node_2", now)
+	assert.NoError(t, err)
+	assert.Nil(t, token)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNodeRepository_MarkOfflineNodes(t *testing.T) {
+	nodeRepo, mock := setupNodeRepository(t)
+
+	ctx := context.Background()
+	before := time.Now().Add(-time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `nodes` SET `status`=?,`updated_at`=? WHERE (status = ? AND (last_heartbeat IS NULL OR last_heartbeat < ?)) AND `nodes`.`deleted_at` IS NULL")).
+		WithArgs(model.NodeStatusOffline, sqlmock.AnyArg(), model.NodeStatusOnline, before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	count, err := nodeRepo.MarkOfflineNodes(ctx, before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
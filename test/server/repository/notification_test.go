@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupNotificationRepository(t *testing.T) (repository.NotificationRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	notificationRepo := repository.NewNotificationRepository(repo)
+
+	return notificationRepo, mock
+}
+
+func TestNotificationRepository_GetNotifications(t *testing.T) {
+	notificationRepo, mock := setupNotificationRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "type", "title", "read_at"}).
+		AddRow(2, "user_1", "privilege_expired", "套餐已到期", nil).
+		AddRow(1, "user_1", "privilege_expired", "套餐已到期", now)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `notifications` WHERE user_id = ? AND `notifications`.`deleted_at` IS NULL ORDER BY id DESC LIMIT ?")).
+		WithArgs("user_1", 50).
+		WillReturnRows(rows)
+
+	notifications, err := notificationRepo.GetNotifications(ctx, "user_1", 50)
+	assert.NoError(t, err)
+	if assert.Len(t, *notifications, 2) {
+		assert.Nil(t, (*notifications)[0].ReadAt)
+		assert.NotNil(t, (*notifications)[1].ReadAt)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_MarkAllRead(t *testing.T) {
+	notificationRepo, mock := setupNotificationRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `notifications` SET `read_at`=?,`updated_at`=? WHERE (user_id = ? AND read_at IS NULL) AND `notifications`.`deleted_at` IS NULL")).
+		WithArgs(now, sqlmock.AnyArg(), "user_1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := notificationRepo.MarkAllRead(ctx, "user_1", now)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupOrderRepository(t *testing.T) (repository.OrderRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	orderRepo := repository.NewOrderRepository(repo)
+
+	return orderRepo, mock
+}
+
+func TestOrderRepository_GetOrderForUpdate(t *testing.T) {
+	orderRepo, mock := setupOrderRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `orders` WHERE order_id = ? AND `orders`.`deleted_at` IS NULL ORDER BY `orders`.`id` LIMIT ? FOR UPDATE")).
+		WithArgs("order_1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "user_id", "status"}).AddRow(1, "order_1", "user_1", model.OrderPaid))
+	// 不存在时返回 nil
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `orders` WHERE order_id = ? AND `orders`.`deleted_at` IS NULL ORDER BY `orders`.`id` LIMIT ? FOR UPDATE")).
+		WithArgs("order_2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	order, err := orderRepo.GetOrderForUpdate(ctx, "order_1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.OrderPaid, order.Status)
+
+	order, err = orderRepo.GetOrderForUpdate(ctx, "order_2")
+	assert.NoError(t, err)
+	assert.Nil(t, order)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_CancelExpiredOrders(t *testing.T) {
+	orderRepo, mock := setupOrderRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// 只取消尚未付款的订单
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `orders` SET `status`=?,`updated_at`=? WHERE (status IN (?,?) AND expires_at < ?) AND `orders`.`deleted_at` IS NULL")).
+		WithArgs(model.OrderCancelled, sqlmock.AnyArg(), model.OrderCreated, model.OrderPendingPayment, now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	count, err := orderRepo.CancelExpiredOrders(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupOrganizationRepository(t *testing.T) (repository.OrganizationRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	organizationRepo := repository.NewOrganizationRepository(repo)
+
+	return organizationRepo, mock
+}
+
+func TestOrganizationRepository_GetMember(t *testing.T) {
+	organizationRepo, mock := setupOrganizationRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `organization_members` WHERE (org_id = ? AND user_id = ?) AND `organization_members`.`deleted_at` IS NULL ORDER BY `organization_members`.`id` LIMIT ?")).
+		WithArgs("org_1", "user_1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "org_id", "user_id", "role"}).AddRow(1, "org_1", "user_1", "admin"))
+	// 不是成员时返回 nil
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `organization_members` WHERE (org_id = ? AND user_id = ?) AND `organization_members`.`deleted_at` IS NULL ORDER BY `organization_members`.`id` LIMIT ?")).
+		WithArgs("org_1", "user_2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	member, err := organizationRepo.GetMember(ctx, "org_1", "user_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", member.Role)
+
+	member, err = organizationRepo.GetMember(ctx, "org_1", "user_2")
+	assert.NoError(t, err)
+	assert.Nil(t, member)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrganizationRepository_DebitTraffic(t *testing.T) {
+	organizationRepo, mock := setupOrganizationRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `organizations` SET `remaining_traffic`=CASE WHEN remaining_traffic > ? THEN remaining_traffic - ? ELSE 0 END,`updated_at`=? WHERE org_id = ? AND `organizations`.`deleted_at` IS NULL")).
+		WithArgs(int64(300), int64(300), sqlmock.AnyArg(), "org_1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `remaining_traffic` FROM `organizations` WHERE org_id = ? AND `organizations`.`deleted_at` IS NULL")).
+		WithArgs("org_1").
+		WillReturnRows(sqlmock.NewRows([]string{"remaining_traffic"}).AddRow(int64(700)))
+
+	remaining, err := organizationRepo.DebitTraffic(ctx, "org_1", 300)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(700), remaining)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupPlanRepository(t *testing.T) (repository.PlanRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	planRepo := repository.NewPlanRepository(repo)
+
+	return planRepo, mock
+}
+
+func TestPlanRepository_GetPlans(t *testing.T) {
+	planRepo, mock := setupPlanRepository(t)
+
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "user_group", "name", "monthly_price", "durations", "features", "purchasable"}).
+		AddRow(1, 1, "普通用户", 0, "[]", `["wireguard"]`, false).
+		AddRow(2, 2, "青铜用户", 1000, "[1,3,6,12]", `["wireguard","routes"]`, true)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `plans` WHERE `plans`.`deleted_at` IS NULL ORDER BY sort_order ASC, user_group ASC")).
+		WillReturnRows(rows)
+
+	plans, err := planRepo.GetPlans(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, *plans, 2) {
+		// 可购买时长与功能以 JSON 保存
+		assert.Equal(t, []int{1, 3, 6, 12}, (*plans)[1].Durations)
+		assert.True(t, (*plans)[1].HasFeature("routes"))
+		assert.False(t, (*plans)[0].HasFeature("routes"))
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
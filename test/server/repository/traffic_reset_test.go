@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupTrafficResetRepository(t *testing.T) (repository.TrafficResetRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	trafficResetRepo := repository.NewTrafficResetRepository(repo)
+
+	return trafficResetRepo, mock
+}
+
+func TestTrafficResetRepository_CreateReset(t *testing.T) {
+	trafficResetRepo, mock := setupTrafficResetRepository(t)
+
+	ctx := context.Background()
+	cycleStart := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	insert := regexp.QuoteMeta("INSERT INTO `traffic_resets` (`created_at`,`updated_at`,`deleted_at`,`owner_type`,`owner_id`,`cycle_start`,`user_group`,`previous_traffic`,`traffic`) VALUES (?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `id`=`id`")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, model.TrafficResetOwnerUser, "user_1", cycleStart, 3, int64(100), int64(1024)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	created, err := trafficResetRepo.CreateReset(ctx, &model.TrafficReset{
+		OwnerType:       model.TrafficResetOwnerUser,
+		OwnerId:         "user_1",
+		CycleStart:      cycleStart,
+		UserGroup:       3,
+		PreviousTraffic: 100,
+		Traffic:         1024,
+	})
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	// 同一周期已有记录时不再写入
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	created, err = trafficResetRepo.CreateReset(ctx, &model.TrafficReset{
+		OwnerType:  model.TrafficResetOwnerUser,
+		OwnerId:    "user_1",
+		CycleStart: cycleStart,
+		UserGroup:  3,
+		Traffic:    1024,
+	})
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"regexp"
 	"testing"
+	"time"
 
+	"hyacinth-backend/internal/model"
 	"hyacinth-backend/internal/repository"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -241,3 +243,68 @@ func TestUsageRepository_GetUsage_DatabaseError(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUsageRepository_CreateUsages(t *testing.T) {
+	usageRepo, mock := setupUsageRepository(t)
+
+	ctx := context.Background()
+	windowStart := time.Now().Add(-time.Minute)
+	usages := []model.Usage{
+		{Model: gorm.Model{CreatedAt: windowStart}, UserId: "user_1", VnetId: "vnet_1", Usage: 300, ClientId: "client_1", NodeId: "relay-1", BatchId: "batch_1"},
+		{Model: gorm.Model{CreatedAt: windowStart}, UserId: "user_1", VnetId: "vnet_1", Usage: 50, ClientId: "client_2", NodeId: "relay-1", BatchId: "batch_1"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `usages`")).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	err := usageRepo.CreateUsages(ctx, usages)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageRepository_CreateUsages_Empty(t *testing.T) {
+	usageRepo, mock := setupUsageRepository(t)
+
+	err := usageRepo.CreateUsages(context.Background(), nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageRepository_GetUsageBatch(t *testing.T) {
+	usageRepo, mock := setupUsageRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "node_id", "batch_id", "window_start", "window_end", "accepted", "rejected", "total_bytes"}).
+		AddRow(1, now, now, nil, "relay-1", "batch_1", now, now, 2, 1, 350)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `usage_batches` WHERE (node_id = ? AND batch_id = ?) AND `usage_batches`.`deleted_at` IS NULL ORDER BY `usage_batches`.`id` LIMIT ?")).
+		WithArgs("relay-1", "batch_1", 1).
+		WillReturnRows(rows)
+
+	batch, err := usageRepo.GetUsageBatch(ctx, "relay-1", "batch_1")
+	assert.NoError(t, err)
+	assert.NotNil(t, batch)
+	assert.Equal(t, 2, batch.Accepted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageRepository_GetUsageBatch_NotFound(t *testing.T) {
+	usageRepo, mock := setupUsageRepository(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `usage_batches` WHERE (node_id = ? AND batch_id = ?)")).
+		WithArgs("relay-1", "batch_2", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	batch, err := usageRepo.GetUsageBatch(context.Background(), "relay-1", "batch_2")
+	assert.NoError(t, err)
+	assert.Nil(t, batch)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -60,8 +60,8 @@ func TestUserRepository_Create(t *testing.T) {
 	}
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`created_at`,`updated_at`,`deleted_at`,`user_id`,`username`,`password`,`email`,`user_group`,`remaining_traffic`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
-		WithArgs(user.CreatedAt, user.UpdatedAt, user.DeletedAt, user.UserId, user.Username, user.Password, user.Email, user.UserGroup, user.RemainingTraffic, user.ID).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`created_at`,`updated_at`,`deleted_at`,`user_id`,`username`,`password`,`email`,`user_group`,`remaining_traffic`,`scheduled_group`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs(user.CreatedAt, user.UpdatedAt, user.DeletedAt, user.UserId, user.Username, user.Password, user.Email, user.UserGroup, user.RemainingTraffic, user.ScheduledGroup, user.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -91,8 +91,8 @@ func TestUserRepository_Update(t *testing.T) {
 	}
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `created_at`=?,`updated_at`=?,`deleted_at`=?,`user_id`=?,`username`=?,`password`=?,`email`=?,`user_group`=?,`privilege_expiry`=?,`remaining_traffic`=? WHERE `users`.`deleted_at` IS NULL AND `id` = ?")).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), user.DeletedAt, user.UserId, user.Username, user.Password, user.Email, user.UserGroup, user.PrivilegeExpiry, user.RemainingTraffic, user.ID).
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `created_at`=?,`updated_at`=?,`deleted_at`=?,`user_id`=?,`username`=?,`password`=?,`email`=?,`user_group`=?,`privilege_expiry`=?,`remaining_traffic`=?,`billing_anchor`=?,`next_traffic_reset`=?,`scheduled_group`=?,`scheduled_expiry`=? WHERE `users`.`deleted_at` IS NULL AND `id` = ?")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), user.DeletedAt, user.UserId, user.Username, user.Password, user.Email, user.UserGroup, user.PrivilegeExpiry, user.RemainingTraffic, user.BillingAnchor, user.NextTrafficReset, user.ScheduledGroup, user.ScheduledExpiry, user.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -222,3 +222,89 @@ func TestUserRepository_GetByUsername_NotFound(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUserRepository_GetByIDForUpdate(t *testing.T) {
+	userRepo, mock := setupRepository(t)
+
+	ctx := context.Background()
+	userId := "user_123456"
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "user_id", "username", "user_group", "remaining_traffic"}).
+		AddRow(1, now, now, nil, userId, "testuser", 1, int64(1024))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE user_id = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT ? FOR UPDATE")).
+		WithArgs(userId, 1).
+		WillReturnRows(rows)
+
+	user, err := userRepo.GetByIDForUpdate(ctx, userId)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024), user.RemainingTraffic)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DebitTraffic(t *testing.T) {
+	userRepo, mock := setupRepository(t)
+
+	ctx := context.Background()
+	userId := "user_123456"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `remaining_traffic`=CASE WHEN remaining_traffic > ? THEN remaining_traffic - ? ELSE 0 END,`updated_at`=? WHERE user_id = ? AND `users`.`deleted_at` IS NULL")).
+		WithArgs(int64(300), int64(300), sqlmock.AnyArg(), userId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `remaining_traffic` FROM `users` WHERE user_id = ? AND `users`.`deleted_at` IS NULL")).
+		WithArgs(userId).
+		WillReturnRows(sqlmock.NewRows([]string{"remaining_traffic"}).AddRow(int64(0)))
+
+	remaining, err := userRepo.DebitTraffic(ctx, userId, 300)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), remaining)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_GetTrafficResetDue(t *testing.T) {
+	userRepo, mock := setupRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "user_group", "next_traffic_reset"}).
+		AddRow(1, "user_1", 3, now.Add(-time.Hour))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE (next_traffic_reset <= ? AND privilege_expiry > next_traffic_reset) AND `users`.`deleted_at` IS NULL ORDER BY next_traffic_reset ASC LIMIT ?")).
+		WithArgs(now, 200).
+		WillReturnRows(rows)
+
+	users, err := userRepo.GetTrafficResetDue(ctx, now, 200)
+	assert.NoError(t, err)
+	if assert.Len(t, *users, 1) {
+		assert.Equal(t, "user_1", (*users)[0].UserId)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_GetScheduledPlanDue(t *testing.T) {
+	userRepo, mock := setupRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "user_group", "privilege_expiry", "scheduled_group", "scheduled_expiry"}).
+		AddRow(1, "user_1", 4, now.Add(-time.Minute), 2, now.AddDate(0, 1, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE (scheduled_group <> 0 AND privilege_expiry <= ?) AND `users`.`deleted_at` IS NULL ORDER BY privilege_expiry ASC LIMIT ?")).
+		WithArgs(now, 200).
+		WillReturnRows(rows)
+
+	users, err := userRepo.GetScheduledPlanDue(ctx, now, 200)
+	assert.NoError(t, err)
+	if assert.Len(t, *users, 1) {
+		assert.Equal(t, 2, (*users)[0].ScheduledGroup)
+		assert.True(t, (*users)[0].HasScheduledPlan())
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
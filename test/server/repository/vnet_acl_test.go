@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupVnetAclRepository(t *testing.T) (repository.VnetAclRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	vnetAclRepo := repository.NewVnetAclRepository(repo)
+
+	return vnetAclRepo, mock
+}
+
+func TestVnetAclRepository_GetRules(t *testing.T) {
+	vnetAclRepo, mock := setupVnetAclRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_acl_rules` WHERE vnet_id = ? AND `vnet_acl_rules`.`deleted_at` IS NULL ORDER BY priority ASC, id ASC")).
+		WithArgs("vnet_1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "rule_id", "vnet_id", "priority", "action"}).
+			AddRow(2, "acl_2", "vnet_1", 10, "deny").
+			AddRow(1, "acl_1", "vnet_1", 20, "allow"))
+
+	rules, err := vnetAclRepo.GetRules(ctx, "vnet_1")
+
+	assert.NoError(t, err)
+	assert.Len(t, *rules, 2)
+	assert.Equal(t, "acl_2", (*rules)[0].RuleId)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetAclRepository_SetMemberTags(t *testing.T) {
+	vnetAclRepo, mock := setupVnetAclRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `vnet_member_tags` WHERE vnet_id = ? AND client_id = ?")).
+		WithArgs("vnet_1", "client_1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `vnet_member_tags`")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "vnet_1", "client_1", "guest", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "vnet_1", "client_1", "printer").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	err := vnetAclRepo.SetMemberTags(ctx, "vnet_1", "client_1", []string{"guest", "printer"})
+	assert.NoError(t, err)
+
+	// 清除标签时只删除
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `vnet_member_tags`")).
+		WithArgs("vnet_1", "client_1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err = vnetAclRepo.SetMemberTags(ctx, "vnet_1", "client_1", nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
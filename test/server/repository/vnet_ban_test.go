@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupVnetBanRepository(t *testing.T) (repository.VnetBanRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	vnetBanRepo := repository.NewVnetBanRepository(repo)
+
+	return vnetBanRepo, mock
+}
+
+func TestVnetBanRepository_GetActiveBans(t *testing.T) {
+	vnetBanRepo, mock := setupVnetBanRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// 永久封禁与尚未过期的封禁
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_bans` WHERE (vnet_id = ? AND (expires_at IS NULL OR expires_at > ?)) AND `vnet_bans`.`deleted_at` IS NULL")).
+		WithArgs("vnet_1", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ban_id", "vnet_id", "client_id", "ip"}).
+			AddRow(1, "ban_1", "vnet_1", "client_1", "").
+			AddRow(2, "ban_2", "vnet_1", "", "203.0.113.0/24"))
+
+	bans, err := vnetBanRepo.GetActiveBans(ctx, "vnet_1", now)
+	assert.NoError(t, err)
+	assert.Len(t, *bans, 2)
+	assert.Equal(t, "203.0.113.0/24", (*bans)[1].Ip)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetBanRepository_DeleteBan(t *testing.T) {
+	vnetBanRepo, mock := setupVnetBanRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `vnet_bans` WHERE vnet_id = ? AND ban_id = ?")).
+		WithArgs("vnet_1", "ban_1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	deleted, err := vnetBanRepo.DeleteBan(ctx, "vnet_1", "ban_1")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
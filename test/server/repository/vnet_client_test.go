@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupVnetClientRepository(t *testing.T) (repository.VnetClientRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	vnetClientRepo := repository.NewVnetClientRepository(repo)
+
+	return vnetClientRepo, mock
+}
+
+func TestVnetClientRepository_UpsertVnetClient(t *testing.T) {
+	vnetClientRepo, mock := setupVnetClientRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+	client := &model.VnetClient{
+		VnetId:         "vnet_123456",
+		ClientId:       "client_1",
+		VirtualIp:      "10.0.0.2",
+		MacAddress:     "02:42:ac:11:00:02",
+		PublicEndpoint: "203.0.113.5:51820",
+		NodeId:         "relay-1",
+		ConnectedAt:    now,
+		LastSeen:       now,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `vnet_clients` (`created_at`,`updated_at`,`deleted_at`,`vnet_id`,`client_id`,`virtual_ip`,`mac_address`,`public_endpoint`,`node_id`,`connected_at`,`last_seen`) VALUES (?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `updated_at`=VALUES(`updated_at`),`virtual_ip`=VALUES(`virtual_ip`),`mac_address`=VALUES(`mac_address`),`public_endpoint`=VALUES(`public_endpoint`),`node_id`=VALUES(`node_id`),`connected_at`=VALUES(`connected_at`),`last_seen`=VALUES(`last_seen`)")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, client.VnetId, client.ClientId, client.VirtualIp, client.MacAddress, client.PublicEndpoint, client.NodeId, client.ConnectedAt, client.LastSeen).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := vnetClientRepo.UpsertVnetClient(ctx, client)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetClientRepository_DeleteVnetClient(t *testing.T) {
+	vnetClientRepo, mock := setupVnetClientRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `vnet_clients` WHERE vnet_id = ? AND client_id = ?")).
+		WithArgs("vnet_123456", "client_1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	existed, err := vnetClientRepo.DeleteVnetClient(ctx, "vnet_123456", "client_1")
+	assert.NoError(t, err)
+	assert.False(t, existed)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetClientRepository_DeleteStaleVnetClients(t *testing.T) {
+	vnetClientRepo, mock := setupVnetClientRepository(t)
+
+	ctx := context.Background()
+	before := time.Now().Add(-90 * time.Second)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT `vnet_id` FROM `vnet_clients` WHERE last_seen < ? AND `vnet_clients`.`deleted_at` IS NULL")).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"vnet_id"}).AddRow("vnet_1").AddRow("vnet_2"))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `vnet_clients` WHERE last_seen < ?")).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	vnetIds, err := vnetClientRepo.DeleteStaleVnetClients(ctx, before)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vnet_1", "vnet_2"}, vnetIds)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetClientRepository_SyncClientsOnline(t *testing.T) {
+	vnetClientRepo, mock := setupVnetClientRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `vnets` SET `clients_online`=(SELECT COUNT(*) FROM `vnet_clients` WHERE vnet_clients.vnet_id = vnets.vnet_id AND `vnet_clients`.`deleted_at` IS NULL) WHERE vnet_id IN (?,?) AND `vnets`.`deleted_at` IS NULL")).
+		WithArgs("vnet_1", "vnet_2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := vnetClientRepo.SyncClientsOnline(ctx, []string{"vnet_1", "vnet_2"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetClientRepository_UseChallenge(t *testing.T) {
+	vnetClientRepo, mock := setupVnetClientRepository(t)
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+	query := regexp.QuoteMeta("INSERT INTO `vnet_client_challenges` (`created_at`,`updated_at`,`deleted_at`,`nonce`,`expires_at`) VALUES (?,?,?,?,?) ON DUPLICATE KEY UPDATE `id`=`id`")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(query).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "nonce_1", expiresAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	// 同一挑战再次使用时不写入
+	mock.ExpectBegin()
+	mock.ExpectExec(query).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "nonce_1", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	used, err := vnetClientRepo.UseChallenge(ctx, "nonce_1", expiresAt)
+	assert.NoError(t, err)
+	assert.True(t, used)
+	used, err = vnetClientRepo.UseChallenge(ctx, "nonce_1", expiresAt)
+	assert.NoError(t, err)
+	assert.False(t, used)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetClientRepository_DeleteExpiredChallenges(t *testing.T) {
+	vnetClientRepo, mock := setupVnetClientRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `vnet_client_challenges` WHERE expires_at < ?")).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectCommit()
+
+	count, err := vnetClientRepo.DeleteExpiredChallenges(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupVnetCollaboratorRepository(t *testing.T) (repository.VnetCollaboratorRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	vnetCollaboratorRepo := repository.NewVnetCollaboratorRepository(repo)
+
+	return vnetCollaboratorRepo, mock
+}
+
+func TestVnetCollaboratorRepository_GetCollaborator(t *testing.T) {
+	vnetCollaboratorRepo, mock := setupVnetCollaboratorRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_collaborators` WHERE (vnet_id = ? AND user_id = ?) AND `vnet_collaborators`.`deleted_at` IS NULL ORDER BY `vnet_collaborators`.`id` LIMIT ?")).
+		WithArgs("vnet_1", "user_1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vnet_id", "user_id", "role"}).AddRow(1, "vnet_1", "user_1", "operator"))
+	// 不是协作者时返回 nil
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_collaborators` WHERE (vnet_id = ? AND user_id = ?) AND `vnet_collaborators`.`deleted_at` IS NULL ORDER BY `vnet_collaborators`.`id` LIMIT ?")).
+		WithArgs("vnet_1", "user_2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	collaborator, err := vnetCollaboratorRepo.GetCollaborator(ctx, "vnet_1", "user_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "operator", collaborator.Role)
+
+	collaborator, err = vnetCollaboratorRepo.GetCollaborator(ctx, "vnet_1", "user_2")
+	assert.NoError(t, err)
+	assert.Nil(t, collaborator)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetCollaboratorRepository_GetAuditLogs(t *testing.T) {
+	vnetCollaboratorRepo, mock := setupVnetCollaboratorRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_audit_logs` WHERE vnet_id = ? AND `vnet_audit_logs`.`deleted_at` IS NULL ORDER BY id DESC LIMIT ?")).
+		WithArgs("vnet_1", 200).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vnet_id", "actor_id", "action", "target_id"}).
+			AddRow(2, "vnet_1", "owner", "collaborator.revoke", "user_1").
+			AddRow(1, "vnet_1", "owner", "collaborator.grant", "user_1"))
+
+	logs, err := vnetCollaboratorRepo.GetAuditLogs(ctx, "vnet_1", 200)
+	assert.NoError(t, err)
+	assert.Len(t, *logs, 2)
+	assert.Equal(t, "collaborator.revoke", (*logs)[0].Action)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupVnetEventRepository(t *testing.T) (repository.VnetEventRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	vnetEventRepo := repository.NewVnetEventRepository(repo)
+
+	return vnetEventRepo, mock
+}
+
+func TestVnetEventRepository_Create(t *testing.T) {
+	vnetEventRepo, mock := setupVnetEventRepository(t)
+
+	ctx := context.Background()
+	event := &model.VnetEvent{
+		VnetId:       "vnet_123456",
+		UserId:       "user_123456",
+		NodeId:       "relay-1",
+		Type:         model.VnetEventUpdate,
+		VnetRevision: 2,
+		Payload:      `{"vnetId":"vnet_123456"}`,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `vnet_events` (`created_at`,`updated_at`,`deleted_at`,`vnet_id`,`user_id`,`node_id`,`type`,`vnet_revision`,`client_id`,`payload`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, event.VnetId, event.UserId, event.NodeId, event.Type, event.VnetRevision, event.ClientId, event.Payload).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectCommit()
+
+	err := vnetEventRepo.Create(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), event.ID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetEventRepository_ListAfter(t *testing.T) {
+	vnetEventRepo, mock := setupVnetEventRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "vnet_id", "user_id", "node_id", "type", "vnet_revision", "payload"}).
+		AddRow(8, now, now, nil, "vnet_1", "user_1", "relay-1", "create", 1, "{}").
+		AddRow(9, now, now, nil, "vnet_1", "user_1", "relay-1", "delete", 2, "")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_events` WHERE id > ? AND `vnet_events`.`deleted_at` IS NULL ORDER BY id ASC LIMIT ?")).
+		WithArgs(int64(7), 100).
+		WillReturnRows(rows)
+
+	events, err := vnetEventRepo.ListAfter(ctx, 7, 100)
+	assert.NoError(t, err)
+	assert.Len(t, *events, 2)
+	assert.Equal(t, uint(8), (*events)[0].ID)
+	assert.Equal(t, model.VnetEventDelete, (*events)[1].Type)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetEventRepository_GetLatestRevision(t *testing.T) {
+	vnetEventRepo, mock := setupVnetEventRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(id), 0) FROM `vnet_events` WHERE `vnet_events`.`deleted_at` IS NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(42))
+
+	revision, err := vnetEventRepo.GetLatestRevision(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), revision)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetEventRepository_GetOldestRevision(t *testing.T) {
+	vnetEventRepo, mock := setupVnetEventRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MIN(id), 0) FROM `vnet_events` WHERE `vnet_events`.`deleted_at` IS NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(20))
+
+	revision, err := vnetEventRepo.GetOldestRevision(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), revision)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetEventRepository_DeleteBefore(t *testing.T) {
+	ctx := context.Background()
+	before := time.Now().Add(-7 * 24 * time.Hour)
+	cutoffQuery := regexp.QuoteMeta("SELECT COALESCE(MAX(id), 0) FROM `vnet_events` WHERE created_at < ? AND `vnet_events`.`deleted_at` IS NULL")
+	latestQuery := regexp.QuoteMeta("SELECT COALESCE(MAX(id), 0) FROM `vnet_events` WHERE `vnet_events`.`deleted_at` IS NULL")
+	deleteQuery := regexp.QuoteMeta("DELETE FROM `vnet_events` WHERE id <= ?")
+
+	t.Run("prunes up to the cutoff", func(t *testing.T) {
+		vnetEventRepo, mock := setupVnetEventRepository(t)
+
+		mock.ExpectQuery(cutoffQuery).WithArgs(before).WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(30))
+		mock.ExpectQuery(latestQuery).WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(42))
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQuery).WithArgs(int64(30)).WillReturnResult(sqlmock.NewResult(0, 30))
+		mock.ExpectCommit()
+
+		count, err := vnetEventRepo.DeleteBefore(ctx, before)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(30), count)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("keeps the latest event", func(t *testing.T) {
+		vnetEventRepo, mock := setupVnetEventRepository(t)
+
+		// 全部事件都已过期时保留最新的一条，当前版本号不回退
+		mock.ExpectQuery(cutoffQuery).WithArgs(before).WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(42))
+		mock.ExpectQuery(latestQuery).WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(42))
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQuery).WithArgs(int64(41)).WillReturnResult(sqlmock.NewResult(0, 41))
+		mock.ExpectCommit()
+
+		count, err := vnetEventRepo.DeleteBefore(ctx, before)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(41), count)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing expired", func(t *testing.T) {
+		vnetEventRepo, mock := setupVnetEventRepository(t)
+
+		mock.ExpectQuery(cutoffQuery).WithArgs(before).WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(0))
+		mock.ExpectQuery(latestQuery).WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(42))
+
+		count, err := vnetEventRepo.DeleteBefore(ctx, before)
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
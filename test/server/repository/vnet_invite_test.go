@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupVnetInviteRepository(t *testing.T) (repository.VnetInviteRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	vnetInviteRepo := repository.NewVnetInviteRepository(repo)
+
+	return vnetInviteRepo, mock
+}
+
+func TestVnetInviteRepository_GetInviteByCode(t *testing.T) {
+	vnetInviteRepo, mock := setupVnetInviteRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_invites` WHERE code = ? AND `vnet_invites`.`deleted_at` IS NULL ORDER BY `vnet_invites`.`id` LIMIT ?")).
+		WithArgs("code_1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "invite_id", "vnet_id", "code", "max_uses", "uses"}).AddRow(1, "inv_1", "vnet_1", "code_1", 5, 2))
+	// 邀请码不存在时返回 nil
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_invites` WHERE code = ? AND `vnet_invites`.`deleted_at` IS NULL ORDER BY `vnet_invites`.`id` LIMIT ?")).
+		WithArgs("code_2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	invite, err := vnetInviteRepo.GetInviteByCode(ctx, "code_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "inv_1", invite.InviteId)
+	assert.Equal(t, 2, invite.Uses)
+
+	invite, err = vnetInviteRepo.GetInviteByCode(ctx, "code_2")
+	assert.NoError(t, err)
+	assert.Nil(t, invite)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetInviteRepository_GetRedemptions(t *testing.T) {
+	vnetInviteRepo, mock := setupVnetInviteRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_invite_redemptions` WHERE vnet_id = ? AND `vnet_invite_redemptions`.`deleted_at` IS NULL ORDER BY redeemed_at DESC, id DESC")).
+		WithArgs("vnet_1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vnet_id", "client_id", "invite_id"}).
+			AddRow(2, "vnet_1", "client_2", "inv_1").
+			AddRow(1, "vnet_1", "client_1", "inv_1"))
+
+	redemptions, err := vnetInviteRepo.GetRedemptions(ctx, "vnet_1")
+	assert.NoError(t, err)
+	assert.Len(t, *redemptions, 2)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
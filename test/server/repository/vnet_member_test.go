@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupVnetMemberRepository(t *testing.T) (repository.VnetMemberRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	vnetMemberRepo := repository.NewVnetMemberRepository(repo)
+
+	return vnetMemberRepo, mock
+}
+
+func TestVnetMemberRepository_GetMembers(t *testing.T) {
+	vnetMemberRepo, mock := setupVnetMemberRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_members` WHERE vnet_id = ? AND status = ? AND `vnet_members`.`deleted_at` IS NULL ORDER BY last_seen DESC, id DESC")).
+		WithArgs("vnet_1", "pending").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vnet_id", "client_id", "status"}).AddRow(1, "vnet_1", "client_1", "pending"))
+	// 不指定状态时返回全部
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_members` WHERE vnet_id = ? AND `vnet_members`.`deleted_at` IS NULL ORDER BY last_seen DESC, id DESC")).
+		WithArgs("vnet_1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vnet_id", "client_id", "status"}).
+			AddRow(1, "vnet_1", "client_1", "pending").
+			AddRow(2, "vnet_1", "client_2", "approved"))
+
+	members, err := vnetMemberRepo.GetMembers(ctx, "vnet_1", "pending")
+	assert.NoError(t, err)
+	assert.Len(t, *members, 1)
+
+	members, err = vnetMemberRepo.GetMembers(ctx, "vnet_1", "")
+	assert.NoError(t, err)
+	assert.Len(t, *members, 2)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupVnetPeerRepository(t *testing.T) (repository.VnetPeerRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	vnetPeerRepo := repository.NewVnetPeerRepository(repo)
+
+	return vnetPeerRepo, mock
+}
+
+func TestVnetPeerRepository_GetPeerByPublicKey(t *testing.T) {
+	vnetPeerRepo, mock := setupVnetPeerRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_peers` WHERE (vnet_id = ? AND public_key = ?) AND `vnet_peers`.`deleted_at` IS NULL ORDER BY `vnet_peers`.`id` LIMIT ?")).
+		WithArgs("vnet_1", "key_1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vnet_id", "client_id", "public_key", "address"}).AddRow(1, "vnet_1", "client_1", "key_1", "10.0.0.1"))
+	// 不存在时返回 nil
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_peers` WHERE (vnet_id = ? AND public_key = ?) AND `vnet_peers`.`deleted_at` IS NULL ORDER BY `vnet_peers`.`id` LIMIT ?")).
+		WithArgs("vnet_1", "key_2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	peer, err := vnetPeerRepo.GetPeerByPublicKey(ctx, "vnet_1", "key_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "client_1", peer.ClientId)
+
+	peer, err = vnetPeerRepo.GetPeerByPublicKey(ctx, "vnet_1", "key_2")
+	assert.NoError(t, err)
+	assert.Nil(t, peer)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetPeerRepository_DeletePeer(t *testing.T) {
+	vnetPeerRepo, mock := setupVnetPeerRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `vnet_peers` WHERE vnet_id = ? AND client_id = ?")).
+		WithArgs("vnet_1", "client_1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	found, err := vnetPeerRepo.DeletePeer(ctx, "vnet_1", "client_1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
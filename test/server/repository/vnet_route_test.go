@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupVnetRouteRepository(t *testing.T) (repository.VnetRouteRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm connection: %v", err)
+	}
+
+	repo := repository.NewRepository(logger, db)
+	vnetRouteRepo := repository.NewVnetRouteRepository(repo)
+
+	return vnetRouteRepo, mock
+}
+
+func TestVnetRouteRepository_GetRoutes(t *testing.T) {
+	vnetRouteRepo, mock := setupVnetRouteRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_routes` WHERE vnet_id = ? AND `vnet_routes`.`deleted_at` IS NULL ORDER BY client_id ASC, prefix ASC")).
+		WithArgs("vnet_1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "route_id", "vnet_id", "client_id", "prefix", "status"}).
+			AddRow(1, "route_1", "vnet_1", "client_1", "192.168.1.0/24", model.VnetRouteApproved).
+			AddRow(2, "route_2", "vnet_1", "client_2", "0.0.0.0/0", model.VnetRoutePending))
+	// 指定状态时只返回该状态的路由
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnet_routes` WHERE vnet_id = ? AND status = ? AND `vnet_routes`.`deleted_at` IS NULL ORDER BY client_id ASC, prefix ASC")).
+		WithArgs("vnet_1", model.VnetRouteApproved).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "route_id", "vnet_id", "client_id", "prefix", "status"}).
+			AddRow(1, "route_1", "vnet_1", "client_1", "192.168.1.0/24", model.VnetRouteApproved))
+
+	routes, err := vnetRouteRepo.GetRoutes(ctx, "vnet_1", "")
+	assert.NoError(t, err)
+	assert.Len(t, *routes, 2)
+
+	routes, err = vnetRouteRepo.GetRoutes(ctx, "vnet_1", model.VnetRouteApproved)
+	assert.NoError(t, err)
+	assert.Len(t, *routes, 1)
+	assert.Equal(t, "route_1", (*routes)[0].RouteId)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetRouteRepository_DeleteRoute(t *testing.T) {
+	vnetRouteRepo, mock := setupVnetRouteRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `vnet_routes` WHERE vnet_id = ? AND route_id = ?")).
+		WithArgs("vnet_1", "route_1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	found, err := vnetRouteRepo.DeleteRoute(ctx, "vnet_1", "route_1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
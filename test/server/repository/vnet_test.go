@@ -49,10 +49,11 @@ func TestVnetRepository_CreateVnet(t *testing.T) {
 		},
 		VnetId:        "vnet_123456",
 		UserId:        "user_123456",
+		Type:          model.VnetTypeN2N,
 		Comment:       "Test VNet",
 		Enabled:       true,
 		Token:         "test_token",
-		Password:      "test_password",
+		PasswordHash:  "test_hash",
 		IpRange:       "10.0.0.0/24",
 		EnableDHCP:    true,
 		ClientsLimit:  10,
@@ -61,8 +62,8 @@ func TestVnetRepository_CreateVnet(t *testing.T) {
 	}
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `vnets` (`created_at`,`updated_at`,`deleted_at`,`vnet_id`,`user_id`,`comment`,`enabled`,`token`,`password`,`ip_range`,`enable_dhcp`,`clients_limit`,`clients_online`,`need_update`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
-		WithArgs(vnet.CreatedAt, vnet.UpdatedAt, vnet.DeletedAt, vnet.VnetId, vnet.UserId, vnet.Comment, vnet.Enabled, vnet.Token, vnet.Password, vnet.IpRange, vnet.EnableDHCP, vnet.ClientsLimit, vnet.ClientsOnline, vnet.NeedUpdate, vnet.ID).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `vnets` (`created_at`,`updated_at`,`deleted_at`,`vnet_id`,`user_id`,`org_id`,`type`,`comment`,`enabled`,`token`,`password_hash`,`require_approval`,`ip_range`,`enable_dhcp`,`clients_limit`,`clients_online`,`need_update`,`region`,`node_id`,`revision`,`suspend_reason`,`acl`,`acl_revision`,`peers`,`peer_revision`,`routes`,`route_revision`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs(vnet.CreatedAt, vnet.UpdatedAt, vnet.DeletedAt, vnet.VnetId, vnet.UserId, vnet.OrgId, vnet.Type, vnet.Comment, vnet.Enabled, vnet.Token, vnet.PasswordHash, vnet.RequireApproval, vnet.IpRange, vnet.EnableDHCP, vnet.ClientsLimit, vnet.ClientsOnline, vnet.NeedUpdate, vnet.Region, vnet.NodeId, vnet.Revision, vnet.SuspendReason, vnet.Acl, vnet.AclRevision, vnet.Peers, vnet.PeerRevision, vnet.Routes, vnet.RouteRevision, vnet.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -88,7 +89,7 @@ func TestVnetRepository_UpdateVnet(t *testing.T) {
 		Comment:       "Updated Test VNet",
 		Enabled:       false,
 		Token:         "updated_token",
-		Password:      "updated_password",
+		PasswordHash:  "updated_hash",
 		IpRange:       "10.0.1.0/24",
 		EnableDHCP:    false,
 		ClientsLimit:  20,
@@ -97,8 +98,8 @@ func TestVnetRepository_UpdateVnet(t *testing.T) {
 	}
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE `vnets` SET `created_at`=?,`updated_at`=?,`deleted_at`=?,`vnet_id`=?,`user_id`=?,`comment`=?,`enabled`=?,`token`=?,`password`=?,`ip_range`=?,`enable_dhcp`=?,`clients_limit`=?,`clients_online`=?,`need_update`=? WHERE `vnets`.`deleted_at` IS NULL AND `id` = ?")).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), vnet.DeletedAt, vnet.VnetId, vnet.UserId, vnet.Comment, vnet.Enabled, vnet.Token, vnet.Password, vnet.IpRange, vnet.EnableDHCP, vnet.ClientsLimit, vnet.ClientsOnline, vnet.NeedUpdate, vnet.ID).
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `vnets` SET `created_at`=?,`updated_at`=?,`deleted_at`=?,`vnet_id`=?,`user_id`=?,`org_id`=?,`type`=?,`comment`=?,`enabled`=?,`token`=?,`password_hash`=?,`require_approval`=?,`ip_range`=?,`enable_dhcp`=?,`clients_limit`=?,`clients_online`=?,`need_update`=?,`region`=?,`node_id`=?,`revision`=?,`suspend_reason`=?,`acl`=?,`acl_revision`=?,`peers`=?,`peer_revision`=?,`routes`=?,`route_revision`=? WHERE `vnets`.`deleted_at` IS NULL AND `id` = ?")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), vnet.DeletedAt, vnet.VnetId, vnet.UserId, vnet.OrgId, vnet.Type, vnet.Comment, vnet.Enabled, vnet.Token, vnet.PasswordHash, vnet.RequireApproval, vnet.IpRange, vnet.EnableDHCP, vnet.ClientsLimit, vnet.ClientsOnline, vnet.NeedUpdate, vnet.Region, vnet.NodeId, vnet.Revision, vnet.SuspendReason, vnet.Acl, vnet.AclRevision, vnet.Peers, vnet.PeerRevision, vnet.Routes, vnet.RouteRevision, vnet.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -300,7 +301,7 @@ func TestVnetRepository_GetRunningVnetCount(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"count"}).AddRow(2)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `vnets` WHERE (user_id = ? AND enabled = ? AND deleted_at IS NULL) AND `vnets`.`deleted_at` IS NULL")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `vnets` WHERE (user_id = ? AND org_id = '' AND enabled = ? AND deleted_at IS NULL) AND `vnets`.`deleted_at` IS NULL")).
 		WithArgs(userId, true).
 		WillReturnRows(rows)
 
@@ -310,3 +311,161 @@ func TestVnetRepository_GetRunningVnetCount(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestVnetRepository_GetVnetsByNodeId(t *testing.T) {
+	vnetRepo, mock := setupVnetRepository(t)
+
+	ctx := context.Background()
+	nodeId := "relay-1"
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "vnet_id", "user_id", "enabled", "node_id", "revision", "need_update"}).
+		AddRow(1, now, now, nil, "vnet_123456", "user_123456", true, "relay-1", 3, true).
+		AddRow(2, now, now, nil, "vnet_789012", "user_123456", false, "relay-1", 1, false)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnets` WHERE (node_id = ? AND deleted_at IS NULL) AND `vnets`.`deleted_at` IS NULL ORDER BY id ASC")).
+		WithArgs(nodeId).
+		WillReturnRows(rows)
+
+	vnets, err := vnetRepo.GetVnetsByNodeId(ctx, nodeId)
+	assert.NoError(t, err)
+	assert.Len(t, *vnets, 2)
+	assert.Equal(t, int64(3), (*vnets)[0].Revision)
+	assert.Equal(t, nodeId, (*vnets)[1].NodeId)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetRepository_AckVnetRevision(t *testing.T) {
+	vnetRepo, mock := setupVnetRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `vnets` SET `need_update`=?,`updated_at`=? WHERE (vnet_id = ? AND revision = ? AND deleted_at IS NULL) AND `vnets`.`deleted_at` IS NULL")).
+		WithArgs(false, sqlmock.AnyArg(), "vnet_123456", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	cleared, err := vnetRepo.AckVnetRevision(ctx, "vnet_123456", 3)
+	assert.NoError(t, err)
+	assert.True(t, cleared)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetRepository_AckVnetRevision_Stale(t *testing.T) {
+	vnetRepo, mock := setupVnetRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `vnets` SET `need_update`=?,`updated_at`=? WHERE (vnet_id = ? AND revision = ? AND deleted_at IS NULL) AND `vnets`.`deleted_at` IS NULL")).
+		WithArgs(false, sqlmock.AnyArg(), "vnet_123456", int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	cleared, err := vnetRepo.AckVnetRevision(ctx, "vnet_123456", 2)
+	assert.NoError(t, err)
+	assert.False(t, cleared)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetRepository_GetVnetByToken_NotFound(t *testing.T) {
+	vnetRepo, mock := setupVnetRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnets` WHERE token = ? AND `vnets`.`deleted_at` IS NULL ORDER BY `vnets`.`id` LIMIT ?")).
+		WithArgs("missing_token", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	vnet, err := vnetRepo.GetVnetByToken(ctx, "missing_token")
+	assert.NoError(t, err)
+	assert.Nil(t, vnet)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetRepository_GetVnetByVnetIdForUpdate(t *testing.T) {
+	vnetRepo, mock := setupVnetRepository(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "vnet_id", "user_id", "enabled", "clients_limit"}).
+		AddRow(1, now, now, nil, "vnet_123456", "user_123456", true, 5)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnets` WHERE vnet_id = ? AND `vnets`.`deleted_at` IS NULL ORDER BY `vnets`.`id` LIMIT ? FOR UPDATE")).
+		WithArgs("vnet_123456", 1).
+		WillReturnRows(rows)
+
+	vnet, err := vnetRepo.GetVnetByVnetIdForUpdate(ctx, "vnet_123456")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, vnet.ClientsLimit)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetRepository_GetEnabledVnets(t *testing.T) {
+	vnetRepo, mock := setupVnetRepository(t)
+
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnets` WHERE enabled = ? AND `vnets`.`deleted_at` IS NULL ORDER BY id ASC")).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vnet_id", "enabled", "node_id"}).AddRow(1, "vnet_1", true, "node_1"))
+
+	vnets, err := vnetRepo.GetEnabledVnets(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, *vnets, 1)
+	assert.Equal(t, "node_1", (*vnets)[0].NodeId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetRepository_GetVnetIdsByLastUsage(t *testing.T) {
+	vnetRepo, mock := setupVnetRepository(t)
+
+	ctx := context.Background()
+
+	// 按最近一条流量记录的时间排序，而不是记录ID
+	rows := sqlmock.NewRows([]string{"vnet_id"}).
+		AddRow("vnet_1").
+		AddRow("vnet_2")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `vnet_id` FROM `usages` WHERE vnet_id IN (?,?,?) AND `usages`.`deleted_at` IS NULL GROUP BY `vnet_id` ORDER BY MAX(created_at) DESC")).
+		WithArgs("vnet_1", "vnet_2", "vnet_3").
+		WillReturnRows(rows)
+
+	vnetIds, err := vnetRepo.GetVnetIdsByLastUsage(ctx, []string{"vnet_1", "vnet_2", "vnet_3"})
+	assert.NoError(t, err)
+	// 没有流量记录的虚拟网络不返回
+	assert.Equal(t, []string{"vnet_1", "vnet_2"}, vnetIds)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVnetRepository_GetVnetsByOwner(t *testing.T) {
+	vnetRepo, mock := setupVnetRepository(t)
+
+	ctx := context.Background()
+
+	// 个人虚拟网络不包括用户创建的组织虚拟网络
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnets` WHERE (user_id = ? AND org_id = '') AND `vnets`.`deleted_at` IS NULL ORDER BY id ASC")).
+		WithArgs("user_1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vnet_id", "user_id"}).AddRow(1, "vnet_1", "user_1"))
+	// 组织的虚拟网络不限创建者
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vnets` WHERE org_id = ? AND `vnets`.`deleted_at` IS NULL ORDER BY id ASC")).
+		WithArgs("org_1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vnet_id", "user_id", "org_id"}).AddRow(2, "vnet_2", "user_2", "org_1"))
+
+	vnets, err := vnetRepo.GetVnetsByOwner(ctx, "user_1", "")
+	assert.NoError(t, err)
+	assert.Len(t, *vnets, 1)
+
+	vnets, err = vnetRepo.GetVnetsByOwner(ctx, "user_1", "org_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "vnet_2", (*vnets)[0].VnetId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
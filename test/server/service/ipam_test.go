@@ -0,0 +1,319 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupIpamService(t *testing.T) (service.IpamService, *mock_repository.MockVnetRepository, *mock_repository.MockVnetClientRepository, *mock_repository.MockIpLeaseRepository) {
+	ipamService, mockVnetRepo, _, mockVnetClientRepo, mockIpLeaseRepo := setupIpamServiceWithUser(t)
+	return ipamService, mockVnetRepo, mockVnetClientRepo, mockIpLeaseRepo
+}
+
+func setupIpamServiceWithUser(t *testing.T) (service.IpamService, *mock_repository.MockVnetRepository, *mock_repository.MockUserRepository, *mock_repository.MockVnetClientRepository, *mock_repository.MockIpLeaseRepository) {
+	ctrl := gomock.NewController(t)
+
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockUserRepo := mock_repository.NewMockUserRepository(ctrl)
+	mockVnetClientRepo := mock_repository.NewMockVnetClientRepository(ctrl)
+	mockIpLeaseRepo := mock_repository.NewMockIpLeaseRepository(ctrl)
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+
+	conf := viper.New()
+	conf.Set("vnet.lease_duration", "1h")
+	conf.Set("vnet.ip_pool", "10.10.0.0/16")
+	conf.Set("vnet.ip_pool_prefix", 24)
+	ipamService := service.NewIpamService(srv, conf, mockVnetRepo, mockUserRepo, mockVnetClientRepo, mockIpLeaseRepo, mock_repository.NewMockOrganizationRepository(ctrl))
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return ipamService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, mockIpLeaseRepo
+}
+
+func TestIpamService_AcquireLease(t *testing.T) {
+	ipamService, _, mockVnetClientRepo, mockIpLeaseRepo := setupIpamService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/29", EnableDHCP: true}
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	// 10.0.0.1 有效租约占用，10.0.0.2 由在线会话占用，10.0.0.3 的租约已过期可被回收
+	mockIpLeaseRepo.EXPECT().GetLeasesByVnetId(ctx, "vnet_1").Return(&[]model.IpLease{
+		{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.1", ExpiresAt: &future},
+		{VnetId: "vnet_1", ClientId: "client_3", Address: "10.0.0.3", ExpiresAt: &past},
+	}, nil)
+	mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{
+		{ClientId: "client_2", VirtualIp: "10.0.0.2"},
+	}, nil)
+	mockIpLeaseRepo.EXPECT().DeleteLeasesByAddress(ctx, "vnet_1", "10.0.0.3", "client_9").Return(nil)
+	mockIpLeaseRepo.EXPECT().CreateLease(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, lease *model.IpLease) error {
+		assert.Equal(t, "client_9", lease.ClientId)
+		assert.Equal(t, "10.0.0.3", lease.Address)
+		assert.False(t, lease.Static)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *lease.ExpiresAt, time.Minute)
+		return nil
+	})
+
+	lease, err := ipamService.AcquireLease(ctx, vnet, "client_9", "", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.3", lease.Address)
+}
+
+func TestIpamService_AcquireLease_Renew(t *testing.T) {
+	ipamService, _, mockVnetClientRepo, mockIpLeaseRepo := setupIpamService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/24", EnableDHCP: true}
+	past := time.Now().Add(-time.Minute)
+
+	// 设备原租约已过期但地址未被占用时沿用原地址
+	mockIpLeaseRepo.EXPECT().GetLeasesByVnetId(ctx, "vnet_1").Return(&[]model.IpLease{
+		{Model: gorm.Model{ID: 7}, VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.42", ExpiresAt: &past},
+	}, nil)
+	mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+	mockIpLeaseRepo.EXPECT().DeleteLeasesByAddress(ctx, "vnet_1", "10.0.0.42", "client_1").Return(nil)
+	mockIpLeaseRepo.EXPECT().UpdateLease(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, lease *model.IpLease) error {
+		assert.Equal(t, uint(7), lease.ID)
+		assert.True(t, lease.ExpiresAt.After(time.Now()))
+		return nil
+	})
+
+	lease, err := ipamService.AcquireLease(ctx, vnet, "client_1", "", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.42", lease.Address)
+}
+
+func TestIpamService_AcquireLease_Static(t *testing.T) {
+	ipamService, _, mockVnetClientRepo, mockIpLeaseRepo := setupIpamService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/24", EnableDHCP: true}
+
+	mockIpLeaseRepo.EXPECT().GetLeasesByVnetId(ctx, "vnet_1").Return(&[]model.IpLease{
+		{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.100", Static: true},
+	}, nil)
+	mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+
+	lease, err := ipamService.AcquireLease(ctx, vnet, "client_1", "", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.100", lease.Address)
+}
+
+func TestIpamService_AcquireLease_Manual(t *testing.T) {
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/24", EnableDHCP: false}
+
+	for _, requested := range []string{"", "10.0.1.5", "10.0.0.0", "10.0.0.255", "10.0.0.2"} {
+		t.Run(requested, func(t *testing.T) {
+			ipamService, _, mockVnetClientRepo, mockIpLeaseRepo := setupIpamService(t)
+			mockIpLeaseRepo.EXPECT().GetLeasesByVnetId(ctx, "vnet_1").Return(&[]model.IpLease{}, nil)
+			mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{
+				{ClientId: "client_2", VirtualIp: "10.0.0.2"},
+			}, nil)
+
+			_, err := ipamService.AcquireLease(ctx, vnet, "client_1", "", requested)
+			assert.Equal(t, v1.ErrVirtualIpUnavailable, err)
+		})
+	}
+}
+
+func TestIpamService_AcquireLease_Exhausted(t *testing.T) {
+	ipamService, _, mockVnetClientRepo, mockIpLeaseRepo := setupIpamService(t)
+
+	ctx := context.Background()
+	// /30 网段只有两个可用主机地址
+	vnet := &model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/30", EnableDHCP: true}
+
+	mockIpLeaseRepo.EXPECT().GetLeasesByVnetId(ctx, "vnet_1").Return(&[]model.IpLease{
+		{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.1", Static: true},
+	}, nil)
+	mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{
+		{ClientId: "client_2", VirtualIp: "10.0.0.2"},
+	}, nil)
+
+	_, err := ipamService.AcquireLease(ctx, vnet, "client_9", "", "")
+
+	assert.Equal(t, v1.ErrVnetAddressExhausted, err)
+}
+
+func TestIpamService_ReserveAddress(t *testing.T) {
+	ipamService, mockVnetRepo, mockVnetClientRepo, mockIpLeaseRepo := setupIpamService(t)
+
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour)
+
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/24"}, nil).Times(2)
+	mockIpLeaseRepo.EXPECT().GetLeasesByVnetId(ctx, "vnet_1").Return(&[]model.IpLease{
+		{VnetId: "vnet_1", ClientId: "client_2", Address: "10.0.0.20", ExpiresAt: &future},
+	}, nil).Times(2)
+	mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil).Times(2)
+
+	// 地址被其他设备的有效租约占用
+	_, err := ipamService.ReserveAddress(ctx, "vnet_1", &v1.ReserveAddressRequest{ClientId: "client_1", Address: "10.0.0.20"})
+	assert.Equal(t, v1.ErrVirtualIpUnavailable, err)
+
+	mockIpLeaseRepo.EXPECT().DeleteLeasesByAddress(ctx, "vnet_1", "10.0.0.10", "client_1").Return(nil)
+	mockIpLeaseRepo.EXPECT().CreateLease(ctx, gomock.Any()).Return(nil)
+
+	lease, err := ipamService.ReserveAddress(ctx, "vnet_1", &v1.ReserveAddressRequest{ClientId: "client_1", Address: "10.0.0.10"})
+	assert.NoError(t, err)
+	assert.True(t, lease.Static)
+	assert.Nil(t, lease.ExpiresAt)
+	assert.Equal(t, "10.0.0.10", lease.Address)
+}
+
+func TestIpamService_AssignAddress(t *testing.T) {
+	ipamService, _, mockVnetClientRepo, mockIpLeaseRepo := setupIpamService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/29", Type: model.VnetTypeWireGuard}
+	future := time.Now().Add(time.Hour)
+
+	// 设备已有动态租约且地址空闲时转为静态租约
+	own := model.IpLease{Model: gorm.Model{ID: 5}, VnetId: "vnet_1", ClientId: "client_2", Address: "10.0.0.2", ExpiresAt: &future}
+	mockIpLeaseRepo.EXPECT().GetLeasesByVnetId(ctx, "vnet_1").Return(&[]model.IpLease{
+		{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.1", Static: true},
+		own,
+	}, nil)
+	mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+	mockIpLeaseRepo.EXPECT().DeleteLeasesByAddress(ctx, "vnet_1", "10.0.0.2", "client_2").Return(nil)
+	mockIpLeaseRepo.EXPECT().UpdateLease(ctx, gomock.Any()).Return(nil)
+
+	lease, err := ipamService.AssignAddress(ctx, vnet, "client_2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", lease.Address)
+	assert.True(t, lease.Static)
+	assert.Nil(t, lease.ExpiresAt)
+
+	// 新设备分配第一个空闲地址
+	mockIpLeaseRepo.EXPECT().GetLeasesByVnetId(ctx, "vnet_1").Return(&[]model.IpLease{
+		{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.1", Static: true},
+	}, nil)
+	mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+	mockIpLeaseRepo.EXPECT().DeleteLeasesByAddress(ctx, "vnet_1", "10.0.0.2", "client_3").Return(nil)
+	mockIpLeaseRepo.EXPECT().CreateLease(ctx, gomock.Any()).Return(nil)
+
+	lease, err = ipamService.AssignAddress(ctx, vnet, "client_3")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", lease.Address)
+	assert.True(t, lease.Static)
+}
+
+func TestIpamService_DeleteReservation(t *testing.T) {
+	ipamService, _, _, mockIpLeaseRepo := setupIpamService(t)
+
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour)
+
+	// 动态租约不是地址保留
+	mockIpLeaseRepo.EXPECT().GetLeaseByClientId(ctx, "vnet_1", "client_2").Return(&model.IpLease{ClientId: "client_2", ExpiresAt: &future}, nil)
+	assert.Equal(t, v1.ErrNotFound, ipamService.DeleteReservation(ctx, "vnet_1", "client_2"))
+
+	mockIpLeaseRepo.EXPECT().GetLeaseByClientId(ctx, "vnet_1", "client_1").Return(&model.IpLease{ClientId: "client_1", Static: true}, nil)
+	mockIpLeaseRepo.EXPECT().DeleteLeaseByClientId(ctx, "vnet_1", "client_1").Return(true, nil)
+	assert.NoError(t, ipamService.DeleteReservation(ctx, "vnet_1", "client_1"))
+}
+
+func TestIpamService_ResolveIpRange(t *testing.T) {
+	ctx := context.Background()
+	vnets := &[]model.Vnet{
+		{VnetId: "vnet_1", UserId: "user_1", IpRange: "192.168.1.0/24"},
+		{VnetId: "vnet_2", UserId: "user_1", IpRange: "10.10.0.0/23"},
+	}
+
+	tests := []struct {
+		name    string
+		vnetId  string
+		ipRange string
+		want    string
+		wantErr error
+	}{
+		{name: "normalized", ipRange: "172.16.5.9/24", want: "172.16.5.0/24"},
+		{name: "ipv6", ipRange: "fd00:1::/64", want: "fd00:1::/64"},
+		{name: "auto assign skips own ranges", ipRange: "", want: "10.10.2.0/24"},
+		{name: "public", ipRange: "8.8.8.0/24", wantErr: v1.ErrInvalidIpRange},
+		{name: "too large", ipRange: "10.0.0.0/8", wantErr: v1.ErrInvalidIpRange},
+		{name: "too small", ipRange: "10.0.0.0/31", wantErr: v1.ErrInvalidIpRange},
+		{name: "malformed", ipRange: "192.168.1.0", wantErr: v1.ErrInvalidIpRange},
+		{name: "overlap", ipRange: "192.168.0.0/16", wantErr: v1.ErrIpRangeOverlap},
+		{name: "own range is not overlap", vnetId: "vnet_1", ipRange: "192.168.1.0/25", want: "192.168.1.0/25"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ipamService, mockVnetRepo, mockUserRepo, _, _ := setupIpamServiceWithUser(t)
+			mockUserRepo.EXPECT().GetByIDForUpdate(ctx, "user_1").Return(&model.User{UserId: "user_1"}, nil)
+			mockVnetRepo.EXPECT().GetVnetsByOwner(ctx, "user_1", "").Return(vnets, nil)
+
+			got, err := ipamService.ResolveIpRange(ctx, "user_1", "", tt.vnetId, tt.ipRange)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIpamService_ResolveIpRange_Org(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockOrgRepo := mock_repository.NewMockOrganizationRepository(ctrl)
+	srv := service.NewService(mock_repository.NewMockTransaction(ctrl), logger, sf, j)
+	conf := viper.New()
+	conf.Set("vnet.ip_pool", "10.10.0.0/16")
+	conf.Set("vnet.ip_pool_prefix", 24)
+	ipamService := service.NewIpamService(srv, conf, mockVnetRepo, mock_repository.NewMockUserRepository(ctrl), mock_repository.NewMockVnetClientRepository(ctrl), mock_repository.NewMockIpLeaseRepository(ctrl), mockOrgRepo)
+
+	ctx := context.Background()
+
+	// 组织的虚拟网络只与同一组织的虚拟网络比较，锁定组织记录而不是创建者的用户记录
+	mockOrgRepo.EXPECT().GetOrganizationForUpdate(ctx, "org_1").Return(&model.Organization{OrgId: "org_1"}, nil).Times(2)
+	mockVnetRepo.EXPECT().GetVnetsByOwner(ctx, "user_1", "org_1").Return(&[]model.Vnet{
+		{VnetId: "vnet_9", UserId: "user_2", OrgId: "org_1", IpRange: "10.10.0.0/24"},
+	}, nil).Times(2)
+
+	got, err := ipamService.ResolveIpRange(ctx, "user_1", "org_1", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.10.1.0/24", got)
+
+	_, err = ipamService.ResolveIpRange(ctx, "user_1", "org_1", "", "10.10.0.128/25")
+	assert.Equal(t, v1.ErrIpRangeOverlap, err)
+}
+
+func TestIpamService_MigrateLeases(t *testing.T) {
+	ipamService, _, _, mockIpLeaseRepo := setupIpamService(t)
+
+	ctx := context.Background()
+
+	mockIpLeaseRepo.EXPECT().GetLeasesByVnetId(ctx, "vnet_1").Return(&[]model.IpLease{
+		{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.2"},
+		{VnetId: "vnet_1", ClientId: "client_2", Address: "10.0.1.2", Static: true},
+	}, nil)
+	// 只清理新网段之外的租约
+	mockIpLeaseRepo.EXPECT().DeleteLeaseByClientId(ctx, "vnet_1", "client_1").Return(true, nil)
+
+	err := ipamService.MigrateLeases(ctx, "vnet_1", "10.0.1.0/24")
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,312 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	"hyacinth-backend/pkg/jwt"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupNodeService(t *testing.T) (service.NodeService, *mock_repository.MockVnetRepository, *mock_service.MockVnetEventService) {
+	nodeService, mockVnetRepo, mockVnetEventService, _ := setupNodeServiceWithRegistry(t)
+	return nodeService, mockVnetRepo, mockVnetEventService
+}
+
+func setupNodeServiceWithRegistry(t *testing.T) (service.NodeService, *mock_repository.MockVnetRepository, *mock_service.MockVnetEventService, *mock_repository.MockNodeRepository) {
+	ctrl := gomock.NewController(t)
+
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockVnetEventService := mock_service.NewMockVnetEventService(ctrl)
+	mockNodeRepo := mock_repository.NewMockNodeRepository(ctrl)
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+
+	conf := viper.New()
+	conf.Set("node.keys", map[string]string{"relay-1": "secret-1"})
+	conf.Set("node.signing_secret", "signing-secret")
+	nodeService := service.NewNodeService(srv, conf, mockVnetRepo, mockVnetEventService, mockNodeRepo)
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return nodeService, mockVnetRepo, mockVnetEventService, mockNodeRepo
+}
+
+func TestNodeService_Authenticate(t *testing.T) {
+	nodeService, _, _, mockNodeRepo := setupNodeServiceWithRegistry(t)
+
+	ctx := context.Background()
+
+	// 静态节点使用配置中的密钥，其他节点与注册表中保存的密钥哈希比对
+	sum := sha256.Sum256([]byte("enrolled-key"))
+	mockNodeRepo.EXPECT().GetNodeByNodeId(ctx, "relay-2").Return(nil, nil)
+	mockNodeRepo.EXPECT().GetNodeByNodeId(ctx, "node_1").Return(&model.Node{NodeId: "node_1", KeyHash: hex.EncodeToString(sum[:])}, nil).Times(3)
+
+	assert.NoError(t, nodeService.Authenticate(ctx, "relay-1", "secret-1"))
+	assert.Equal(t, v1.ErrUnauthorized, nodeService.Authenticate(ctx, "relay-1", "wrong"))
+	assert.Equal(t, v1.ErrUnauthorized, nodeService.Authenticate(ctx, "relay-2", "secret-1"))
+	assert.Equal(t, v1.ErrUnauthorized, nodeService.Authenticate(ctx, "", ""))
+	assert.NoError(t, nodeService.Authenticate(ctx, "node_1", "enrolled-key"))
+	assert.Equal(t, v1.ErrUnauthorized, nodeService.Authenticate(ctx, "node_1", "secret-1"))
+	assert.Equal(t, v1.ErrUnauthorized, nodeService.Authenticate(ctx, "node_1", hex.EncodeToString(sum[:])))
+}
+
+func TestNodeService_ListVnets(t *testing.T) {
+	nodeService, mockVnetRepo, mockVnetEventService := setupNodeService(t)
+
+	ctx := context.Background()
+
+	mockVnetEventService.EXPECT().GetLatestRevision(ctx).Return(int64(42), nil)
+	mockVnetRepo.EXPECT().GetVnetsByNodeId(ctx, "relay-1").Return(&[]model.Vnet{
+		{VnetId: "vnet_1", Enabled: true, Revision: 2, NeedUpdate: true, NodeId: "relay-1"},
+		{VnetId: "vnet_2", Enabled: false, Revision: 1},
+	}, nil)
+
+	resp, err := nodeService.ListVnets(ctx, "relay-1")
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Vnets, 2)
+	assert.Equal(t, "vnet_1", resp.Vnets[0].VnetId)
+	assert.True(t, resp.Vnets[0].NeedUpdate)
+	assert.Equal(t, int64(2), resp.Vnets[0].Revision)
+	assert.Equal(t, int64(42), resp.Revision)
+}
+
+func TestNodeService_GetVnetConfig(t *testing.T) {
+	nodeService, mockVnetRepo, _ := setupNodeService(t)
+
+	ctx := context.Background()
+
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_1").Return(&model.Vnet{
+		VnetId:       "vnet_1",
+		Enabled:      true,
+		Token:        "token_1",
+		PasswordHash: "hash_1",
+		IpRange:      "10.0.0.0/24",
+		EnableDHCP:   true,
+		ClientsLimit: 5,
+		NodeId:       "relay-1",
+		Revision:     4,
+	}, nil)
+
+	resp, err := nodeService.GetVnetConfig(ctx, "relay-1", "vnet_1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "token_1", resp.Config.Token)
+	// 密码校验值不下发给节点
+	payload, _ := json.Marshal(resp.Config)
+	assert.NotContains(t, string(payload), "hash_1")
+	assert.Equal(t, "10.0.0.0/24", resp.Config.IpRange)
+	assert.Equal(t, 5, resp.Config.ClientsLimit)
+	assert.Equal(t, int64(4), resp.Config.Revision)
+}
+
+func TestNodeService_GetVnetConfig_OtherNode(t *testing.T) {
+	nodeService, mockVnetRepo, _ := setupNodeService(t)
+
+	ctx := context.Background()
+
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", NodeId: "relay-2"}, nil)
+
+	resp, err := nodeService.GetVnetConfig(ctx, "relay-1", "vnet_1")
+
+	assert.Nil(t, resp)
+	assert.Equal(t, v1.ErrForbidden, err)
+}
+
+func TestNodeService_GetVnetConfig_NotFound(t *testing.T) {
+	nodeService, mockVnetRepo, _ := setupNodeService(t)
+
+	ctx := context.Background()
+
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_x").Return(&model.Vnet{}, nil)
+
+	_, err := nodeService.GetVnetConfig(ctx, "relay-1", "vnet_x")
+
+	assert.Equal(t, v1.ErrNotFound, err)
+}
+
+func TestNodeService_AckVnetConfig(t *testing.T) {
+	nodeService, mockVnetRepo, _ := setupNodeService(t)
+
+	ctx := context.Background()
+
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", NodeId: "relay-1", Revision: 3, NeedUpdate: true}, nil)
+	mockVnetRepo.EXPECT().AckVnetRevision(ctx, "vnet_1", int64(3)).Return(true, nil)
+
+	resp, err := nodeService.AckVnetConfig(ctx, "relay-1", &v1.AckNodeVnetConfigRequest{VnetId: "vnet_1", Revision: 3})
+
+	assert.NoError(t, err)
+	assert.False(t, resp.NeedUpdate)
+	assert.Equal(t, int64(3), resp.Revision)
+}
+
+func TestNodeService_AckVnetConfig_StaleRevision(t *testing.T) {
+	nodeService, mockVnetRepo, _ := setupNodeService(t)
+
+	ctx := context.Background()
+
+	// 确认的是旧版本，不应清除 NeedUpdate
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", NodeId: "relay-1", Revision: 5, NeedUpdate: true}, nil)
+
+	resp, err := nodeService.AckVnetConfig(ctx, "relay-1", &v1.AckNodeVnetConfigRequest{VnetId: "vnet_1", Revision: 4})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.NeedUpdate)
+	assert.Equal(t, int64(5), resp.Revision)
+}
+
+func TestNodeService_AckVnetConfig_ChangedConcurrently(t *testing.T) {
+	nodeService, mockVnetRepo, _ := setupNodeService(t)
+
+	ctx := context.Background()
+
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", NodeId: "relay-1", Revision: 3, NeedUpdate: true}, nil)
+	mockVnetRepo.EXPECT().AckVnetRevision(ctx, "vnet_1", int64(3)).Return(false, nil)
+
+	resp, err := nodeService.AckVnetConfig(ctx, "relay-1", &v1.AckNodeVnetConfigRequest{VnetId: "vnet_1", Revision: 3})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.NeedUpdate)
+}
+
+func TestNodeService_AckVnetConfig_Unassigned(t *testing.T) {
+	nodeService, mockVnetRepo, _ := setupNodeService(t)
+
+	ctx := context.Background()
+
+	// 尚未分配节点的虚拟网络不能被任何节点确认
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", Revision: 3, NeedUpdate: true}, nil)
+
+	_, err := nodeService.AckVnetConfig(ctx, "relay-1", &v1.AckNodeVnetConfigRequest{VnetId: "vnet_1", Revision: 3})
+
+	assert.Equal(t, v1.ErrForbidden, err)
+}
+
+func TestNodeService_AckVnetConfig_FutureRevision(t *testing.T) {
+	nodeService, mockVnetRepo, _ := setupNodeService(t)
+
+	ctx := context.Background()
+
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", NodeId: "relay-1", Revision: 3}, nil)
+
+	_, err := nodeService.AckVnetConfig(ctx, "relay-1", &v1.AckNodeVnetConfigRequest{VnetId: "vnet_1", Revision: 9})
+
+	assert.Equal(t, v1.ErrBadRequest, err)
+}
+
+func TestNodeService_WatchVnets_InvalidRevision(t *testing.T) {
+	nodeService, _, _ := setupNodeService(t)
+
+	err := nodeService.WatchVnets(context.Background(), "relay-1", -1, func(event *v1.NodeVnetEvent) error {
+		return nil
+	})
+
+	assert.Equal(t, v1.ErrBadRequest, err)
+}
+
+func TestNodeService_Enroll(t *testing.T) {
+	nodeService, _, _, mockNodeRepo := setupNodeServiceWithRegistry(t)
+
+	ctx := context.Background()
+	req := &v1.EnrollNodeRequest{BootstrapToken: "bootstrap-1", PublicAddr: "203.0.113.10:7777", Capacity: 500, Version: "1.4.2"}
+
+	var nodeId, keyHash string
+	mockNodeRepo.EXPECT().ConsumeBootstrapToken(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, tokenHash string, id string, now time.Time) (*model.NodeBootstrapToken, error) {
+		// 只按哈希查找令牌
+		assert.NotEqual(t, "bootstrap-1", tokenHash)
+		assert.Len(t, tokenHash, 64)
+		nodeId = id
+		return &model.NodeBootstrapToken{TokenHash: tokenHash, Region: "cn-east"}, nil
+	})
+	mockNodeRepo.EXPECT().CreateNode(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, node *model.Node) error {
+		assert.Equal(t, nodeId, node.NodeId)
+		assert.Equal(t, "cn-east", node.Region)
+		assert.Equal(t, 500, node.Capacity)
+		assert.Equal(t, model.NodeStatusOffline, node.Status)
+		keyHash = node.KeyHash
+		return nil
+	})
+
+	resp, err := nodeService.Enroll(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, nodeId, resp.NodeId)
+	assert.NotEmpty(t, resp.NodeKey)
+	// 只保存密钥的哈希
+	sum := sha256.Sum256([]byte(resp.NodeKey))
+	assert.Equal(t, hex.EncodeToString(sum[:]), keyHash)
+	assert.Equal(t, jwt.NodeSigningKey([]byte("signing-secret"), nodeId), resp.SigningKey)
+}
+
+func TestNodeService_Enroll_InvalidToken(t *testing.T) {
+	nodeService, _, _, mockNodeRepo := setupNodeServiceWithRegistry(t)
+
+	ctx := context.Background()
+
+	// 令牌不存在、已过期或已被使用
+	mockNodeRepo.EXPECT().ConsumeBootstrapToken(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	_, err := nodeService.Enroll(ctx, &v1.EnrollNodeRequest{BootstrapToken: "used", PublicAddr: "203.0.113.10:7777"})
+
+	assert.Equal(t, v1.ErrUnauthorized, err)
+}
+
+func TestNodeService_Heartbeat(t *testing.T) {
+	nodeService, _, _, mockNodeRepo := setupNodeServiceWithRegistry(t)
+
+	ctx := context.Background()
+	req := &v1.NodeHeartbeatRequest{Version: "1.4.2", ClientsConnected: 12, CpuUsage: 35.5}
+
+	// 静态节点首次心跳时自动登记
+	mockNodeRepo.EXPECT().UpdateHeartbeat(ctx, gomock.Any()).Return(false, nil)
+	mockNodeRepo.EXPECT().CreateNode(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, node *model.Node) error {
+		assert.Equal(t, "relay-1", node.NodeId)
+		assert.Equal(t, model.NodeStatusOnline, node.Status)
+		assert.Equal(t, 12, node.ClientsConnected)
+		assert.NotNil(t, node.LastHeartbeat)
+		return nil
+	})
+	mockNodeRepo.EXPECT().GetNodeByNodeId(ctx, "relay-1").Return(&model.Node{NodeId: "relay-1"}, nil)
+
+	resp, err := nodeService.Heartbeat(ctx, "relay-1", req)
+	assert.NoError(t, err)
+	assert.False(t, resp.Draining)
+	assert.Equal(t, jwt.NodeSigningKey([]byte("signing-secret"), "relay-1"), resp.SigningKey)
+
+	mockNodeRepo.EXPECT().UpdateHeartbeat(ctx, gomock.Any()).Return(true, nil)
+	mockNodeRepo.EXPECT().GetNodeByNodeId(ctx, "node_1").Return(&model.Node{NodeId: "node_1", Draining: true}, nil)
+
+	resp, err = nodeService.Heartbeat(ctx, "node_1", req)
+	assert.NoError(t, err)
+	assert.True(t, resp.Draining)
+}
+
+func TestNodeService_RemoveNode(t *testing.T) {
+	nodeService, mockVnetRepo, _, mockNodeRepo := setupNodeServiceWithRegistry(t)
+
+	ctx := context.Background()
+
+	assert.Equal(t, v1.ErrNodeStatic, nodeService.RemoveNode(ctx, "relay-1"))
+
+	mockNodeRepo.EXPECT().DeleteNode(ctx, "node_missing").Return(false, nil)
+	assert.Equal(t, v1.ErrNotFound, nodeService.RemoveNode(ctx, "node_missing"))
+
+	mockNodeRepo.EXPECT().DeleteNode(ctx, "node_1").Return(true, nil)
+	mockVnetRepo.EXPECT().ClearNodeAssignment(ctx, "node_1").Return(int64(3), nil)
+	assert.NoError(t, nodeService.RemoveNode(ctx, "node_1"))
+}
@@ -0,0 +1,376 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	"hyacinth-backend/pkg/payment"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type orderFixture struct {
+	orderService            service.OrderService
+	gateway                 *payment.MockGateway
+	mockOrderRepo           *mock_repository.MockOrderRepository
+	mockUserRepo            *mock_repository.MockUserRepository
+	mockOrganizationRepo    *mock_repository.MockOrganizationRepository
+	mockVnetRepo            *mock_repository.MockVnetRepository
+	mockUserService         *mock_service.MockUserService
+	mockOrganizationService *mock_service.MockOrganizationService
+}
+
+func setupOrderService(t *testing.T) *orderFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &orderFixture{
+		gateway:                 payment.NewMockGateway("secret", "http://127.0.0.1:8000/v1/payment/mock/checkout"),
+		mockOrderRepo:           mock_repository.NewMockOrderRepository(ctrl),
+		mockUserRepo:            mock_repository.NewMockUserRepository(ctrl),
+		mockOrganizationRepo:    mock_repository.NewMockOrganizationRepository(ctrl),
+		mockVnetRepo:            mock_repository.NewMockVnetRepository(ctrl),
+		mockUserService:         mock_service.NewMockUserService(ctrl),
+		mockOrganizationService: mock_service.NewMockOrganizationService(ctrl),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	conf := viper.New()
+	conf.Set("payment.order_ttl", "15m")
+	f.orderService = service.NewOrderService(srv, conf, f.mockOrderRepo, f.mockUserRepo, f.mockOrganizationRepo, f.mockVnetRepo, f.mockUserService, f.mockOrganizationService, newTestPlanService(ctrl, testPlans()), f.gateway)
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return f
+}
+
+func (f *orderFixture) notify(t *testing.T, n *payment.Notification) (http.Header, []byte) {
+	header, body, err := f.gateway.SignNotification(n)
+	assert.NoError(t, err)
+	return header, body
+}
+
+func TestOrderService_CreateOrder(t *testing.T) {
+	f := setupOrderService(t)
+
+	ctx := context.Background()
+	var created *model.Order
+
+	f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1}, nil)
+	f.mockOrderRepo.EXPECT().CreateOrder(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, order *model.Order) error {
+		assert.Equal(t, model.OrderCreated, order.Status)
+		created = order
+		return nil
+	})
+	f.mockOrderRepo.EXPECT().UpdateOrder(ctx, gomock.Any()).Return(nil)
+
+	data, err := f.orderService.CreateOrder(ctx, "user_1", "", &v1.PurchasePackageRequest{PackageType: 3, Duration: 2})
+
+	assert.NoError(t, err)
+	// 购买套餐不会直接生效
+	assert.Equal(t, model.OrderPendingPayment, data.Order.Status)
+	assert.Equal(t, int64(2*3000), data.Order.Amount)
+	assert.Equal(t, "mock", created.Provider)
+	assert.True(t, strings.HasPrefix(data.PayUrl, "http://127.0.0.1:8000/v1/payment/mock/checkout?"))
+	assert.Contains(t, data.PayUrl, "orderId="+created.OrderId)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), created.ExpiresAt, time.Minute)
+}
+
+func TestOrderService_CreateOrder_Rejected(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalid package", func(t *testing.T) {
+		f := setupOrderService(t)
+		_, err := f.orderService.CreateOrder(ctx, "user_1", "", &v1.PurchasePackageRequest{PackageType: 1, Duration: 1})
+		assert.ErrorIs(t, err, v1.ErrBadRequest)
+		_, err = f.orderService.CreateOrder(ctx, "user_1", "", &v1.PurchasePackageRequest{PackageType: 2, Duration: 0})
+		assert.ErrorIs(t, err, v1.ErrBadRequest)
+		// 时长须为套餐可购买的时长之一
+		_, err = f.orderService.CreateOrder(ctx, "user_1", "", &v1.PurchasePackageRequest{PackageType: 2, Duration: 13})
+		assert.ErrorIs(t, err, v1.ErrBadRequest)
+	})
+
+	t.Run("downgrade below personal vnet clients limit", func(t *testing.T) {
+		f := setupOrderService(t)
+		f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 3}, nil)
+		f.mockVnetRepo.EXPECT().GetVnetByUserId(ctx, "user_1").Return(&[]model.Vnet{
+			{VnetId: "vnet_1", UserId: "user_1", ClientsLimit: 8},
+		}, nil)
+
+		_, err := f.orderService.CreateOrder(ctx, "user_1", "", &v1.PurchasePackageRequest{PackageType: 2, Duration: 1})
+		assert.ErrorIs(t, err, v1.ErrVnetClientsLimitExceeded)
+	})
+
+	t.Run("downgrade ignores organization vnets", func(t *testing.T) {
+		f := setupOrderService(t)
+		f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 3}, nil)
+		f.mockVnetRepo.EXPECT().GetVnetByUserId(ctx, "user_1").Return(&[]model.Vnet{
+			{VnetId: "vnet_1", UserId: "user_1", OrgId: "org_1", ClientsLimit: 8},
+		}, nil)
+		f.mockOrderRepo.EXPECT().CreateOrder(ctx, gomock.Any()).Return(nil)
+		f.mockOrderRepo.EXPECT().UpdateOrder(ctx, gomock.Any()).Return(nil)
+
+		_, err := f.orderService.CreateOrder(ctx, "user_1", "", &v1.PurchasePackageRequest{PackageType: 2, Duration: 1})
+		assert.NoError(t, err)
+	})
+
+	t.Run("downgrade below organization vnet clients limit", func(t *testing.T) {
+		f := setupOrderService(t)
+		f.mockOrganizationRepo.EXPECT().GetOrganization(ctx, "org_1").Return(&model.Organization{OrgId: "org_1", UserGroup: 3}, nil)
+		f.mockVnetRepo.EXPECT().GetVnetsByOrgIds(ctx, []string{"org_1"}).Return(&[]model.Vnet{{VnetId: "vnet_1", OrgId: "org_1", ClientsLimit: 10}}, nil)
+
+		_, err := f.orderService.CreateOrder(ctx, "user_1", "org_1", &v1.PurchasePackageRequest{PackageType: 2, Duration: 1})
+		assert.ErrorIs(t, err, v1.ErrVnetClientsLimitExceeded)
+	})
+
+	t.Run("downgrade with more running vnets than the lower plan allows", func(t *testing.T) {
+		f := setupOrderService(t)
+		expiry := time.Now().AddDate(0, 0, 10)
+		f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 3, PrivilegeExpiry: &expiry}, nil)
+		vnets := make([]model.Vnet, 0, 4)
+		for _, id := range []string{"vnet_1", "vnet_2", "vnet_3", "vnet_4"} {
+			vnets = append(vnets, model.Vnet{VnetId: id, UserId: "user_1", Enabled: true, ClientsLimit: 5})
+		}
+		f.mockVnetRepo.EXPECT().GetVnetByUserId(ctx, "user_1").Return(&vnets, nil)
+
+		_, err := f.orderService.CreateOrder(ctx, "user_1", "", &v1.PurchasePackageRequest{PackageType: 2, Duration: 1})
+		assert.ErrorIs(t, err, v1.ErrVnetLimitExceeded)
+	})
+
+	t.Run("another downgrade already scheduled", func(t *testing.T) {
+		f := setupOrderService(t)
+		expiry := time.Now().AddDate(0, 0, 10)
+		scheduled := expiry.AddDate(0, 1, 0)
+		f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{
+			UserId:          "user_1",
+			UserGroup:       4,
+			PrivilegeExpiry: &expiry,
+			ScheduledPlan:   model.ScheduledPlan{ScheduledGroup: 3, ScheduledExpiry: &scheduled},
+		}, nil)
+
+		_, err := f.orderService.CreateOrder(ctx, "user_1", "", &v1.PurchasePackageRequest{PackageType: 2, Duration: 1})
+		assert.ErrorIs(t, err, v1.ErrCannotDowngrade)
+	})
+}
+
+func TestOrderService_PreviewOrder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("upgrade prorates the remaining value", func(t *testing.T) {
+		f := setupOrderService(t)
+		// 青铜套餐剩余 30 天，按白银套餐的价格折算为 10 天
+		expiry := time.Now().AddDate(0, 0, 30).Add(time.Minute)
+		f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 2, PrivilegeExpiry: &expiry}, nil)
+
+		data, err := f.orderService.PreviewOrder(ctx, "user_1", "", &v1.PurchasePackageRequest{PackageType: 3, Duration: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PlanChangeUpgrade, data.Kind)
+		assert.Equal(t, int64(3000), data.Amount)
+		assert.Equal(t, 10, data.CreditDays)
+		expiresAt, err := time.ParseInLocation("2006-01-02 15:04:05", data.ExpiresAt, time.Local)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().AddDate(0, 1, 10), expiresAt, time.Minute)
+	})
+
+	t.Run("downgrade takes effect at the end of the period", func(t *testing.T) {
+		f := setupOrderService(t)
+		expiry := time.Now().AddDate(0, 0, 10)
+		f.mockOrganizationRepo.EXPECT().GetOrganization(ctx, "org_1").Return(&model.Organization{OrgId: "org_1", UserGroup: 4, PrivilegeExpiry: &expiry}, nil)
+		f.mockVnetRepo.EXPECT().GetVnetsByOrgIds(ctx, []string{"org_1"}).Return(&[]model.Vnet{{VnetId: "vnet_1", OrgId: "org_1", Enabled: true, ClientsLimit: 10}}, nil)
+
+		data, err := f.orderService.PreviewOrder(ctx, "user_1", "org_1", &v1.PurchasePackageRequest{PackageType: 3, Duration: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PlanChangeDowngrade, data.Kind)
+		assert.Equal(t, int64(2*3000), data.Amount)
+		assert.Equal(t, expiry.Format("2006-01-02 15:04:05"), data.EffectiveAt)
+		assert.Equal(t, expiry.AddDate(0, 2, 0).Format("2006-01-02 15:04:05"), data.ExpiresAt)
+		assert.Zero(t, data.CreditDays)
+	})
+}
+
+func TestOrderService_HandleNotification_Paid(t *testing.T) {
+	f := setupOrderService(t)
+
+	ctx := context.Background()
+	order := &model.Order{OrderId: "order_1", UserId: "user_1", PackageType: 3, Duration: 1, Amount: 3000, Status: model.OrderPendingPayment}
+	header, body := f.notify(t, &payment.Notification{OrderId: "order_1", TradeNo: "trade_1", Amount: 3000, Status: payment.StatusPaid})
+
+	f.mockOrderRepo.EXPECT().GetOrderForUpdate(ctx, "order_1").Return(order, nil).AnyTimes()
+	f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1}, nil)
+	f.mockOrderRepo.EXPECT().UpdateOrder(ctx, order).Return(nil).Times(1)
+	// 重复的通知只会使套餐生效一次
+	f.mockUserService.EXPECT().PurchasePackage(ctx, "user_1", &v1.PurchasePackageRequest{PackageType: 3, Duration: 1}).Return(nil).Times(1)
+
+	assert.NoError(t, f.orderService.HandleNotification(ctx, "mock", header, body))
+	assert.Equal(t, model.OrderFulfilled, order.Status)
+	assert.Equal(t, "trade_1", order.TradeNo)
+	assert.NotNil(t, order.PaidAt)
+	assert.NotNil(t, order.FulfilledAt)
+
+	assert.NoError(t, f.orderService.HandleNotification(ctx, "mock", header, body))
+	assert.Equal(t, model.OrderFulfilled, order.Status)
+}
+
+func TestOrderService_HandleNotification_FulfilmentRetried(t *testing.T) {
+	f := setupOrderService(t)
+
+	ctx := context.Background()
+	order := &model.Order{OrderId: "order_1", UserId: "user_1", OrgId: "org_1", PackageType: 2, Duration: 1, Amount: 1000, Status: model.OrderPendingPayment}
+	header, body := f.notify(t, &payment.Notification{OrderId: "order_1", TradeNo: "trade_1", Amount: 1000, Status: payment.StatusPaid})
+
+	f.mockOrderRepo.EXPECT().GetOrderForUpdate(ctx, "order_1").Return(order, nil).AnyTimes()
+	f.mockOrganizationRepo.EXPECT().GetOrganization(ctx, "org_1").Return(&model.Organization{OrgId: "org_1", UserGroup: 1}, nil).Times(2)
+	f.mockOrderRepo.EXPECT().UpdateOrder(ctx, order).Return(nil).Times(1)
+	f.mockOrganizationService.EXPECT().PurchasePackage(ctx, "org_1", gomock.Any()).Return(errors.New("connection reset"))
+	f.mockOrganizationService.EXPECT().PurchasePackage(ctx, "org_1", gomock.Any()).Return(nil)
+
+	// 临时故障时事务回滚，订单不会写入，渠道重试回调时再次履约
+	assert.Error(t, f.orderService.HandleNotification(ctx, "mock", header, body))
+
+	assert.NoError(t, f.orderService.HandleNotification(ctx, "mock", header, body))
+	assert.Equal(t, model.OrderFulfilled, order.Status)
+}
+
+func TestOrderService_HandleNotification_RefundRequired(t *testing.T) {
+	f := setupOrderService(t)
+
+	ctx := context.Background()
+	expiry := time.Now().AddDate(0, 1, 0)
+	order := &model.Order{OrderId: "order_2", UserId: "user_1", PackageType: 2, Duration: 1, Amount: 1000, Status: model.OrderPendingPayment}
+	header, body := f.notify(t, &payment.Notification{OrderId: "order_2", TradeNo: "trade_2", Amount: 1000, Status: payment.StatusPaid})
+
+	// 另一笔降级订单已先生效，付款时重新校验不能再次降级，订单转为待退款，重复通知也不再重试
+	f.mockOrderRepo.EXPECT().GetOrderForUpdate(ctx, "order_2").Return(order, nil).Times(2)
+	scheduledExpiry := expiry.AddDate(0, 1, 0)
+	f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 4, PrivilegeExpiry: &expiry, ScheduledPlan: model.ScheduledPlan{ScheduledGroup: 3, ScheduledExpiry: &scheduledExpiry}}, nil)
+	f.mockOrderRepo.EXPECT().UpdateOrder(ctx, order).Return(nil).Times(1)
+
+	assert.NoError(t, f.orderService.HandleNotification(ctx, "mock", header, body))
+	assert.Equal(t, model.OrderRefundRequired, order.Status)
+	assert.Equal(t, "trade_2", order.TradeNo)
+
+	assert.NoError(t, f.orderService.HandleNotification(ctx, "mock", header, body))
+	assert.Equal(t, model.OrderRefundRequired, order.Status)
+}
+
+func TestOrderService_HandleNotification_Rejected(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalid signature", func(t *testing.T) {
+		f := setupOrderService(t)
+		header, body := f.notify(t, &payment.Notification{OrderId: "order_1", Amount: 3000, Status: payment.StatusPaid})
+		tampered := []byte(strings.Replace(string(body), "3000", "1", 1))
+
+		err := f.orderService.HandleNotification(ctx, "mock", header, tampered)
+		assert.ErrorIs(t, err, v1.ErrInvalidPaymentSignature)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		f := setupOrderService(t)
+		header, body := f.notify(t, &payment.Notification{OrderId: "order_1", Amount: 3000, Status: payment.StatusPaid})
+
+		err := f.orderService.HandleNotification(ctx, "alipay", header, body)
+		assert.ErrorIs(t, err, v1.ErrNotFound)
+	})
+
+	t.Run("amount mismatch", func(t *testing.T) {
+		f := setupOrderService(t)
+		header, body := f.notify(t, &payment.Notification{OrderId: "order_1", Amount: 1, Status: payment.StatusPaid})
+		f.mockOrderRepo.EXPECT().GetOrderForUpdate(ctx, "order_1").Return(&model.Order{OrderId: "order_1", Amount: 3000, Status: model.OrderPendingPayment}, nil)
+
+		err := f.orderService.HandleNotification(ctx, "mock", header, body)
+		assert.ErrorIs(t, err, v1.ErrBadRequest)
+	})
+}
+
+func TestOrderService_HandleNotification_Refunded(t *testing.T) {
+	f := setupOrderService(t)
+
+	ctx := context.Background()
+	order := &model.Order{OrderId: "order_1", UserId: "user_1", Amount: 3000, Status: model.OrderFulfilled}
+	header, body := f.notify(t, &payment.Notification{OrderId: "order_1", TradeNo: "trade_1", Amount: 3000, Status: payment.StatusRefunded})
+
+	f.mockOrderRepo.EXPECT().GetOrderForUpdate(ctx, "order_1").Return(order, nil)
+	f.mockOrderRepo.EXPECT().UpdateOrder(ctx, order).Return(nil)
+
+	assert.NoError(t, f.orderService.HandleNotification(ctx, "mock", header, body))
+	assert.Equal(t, model.OrderRefunded, order.Status)
+}
+
+func TestOrderService_CancelOrder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pending", func(t *testing.T) {
+		f := setupOrderService(t)
+		order := &model.Order{OrderId: "order_1", UserId: "user_1", Status: model.OrderPendingPayment}
+		f.mockOrderRepo.EXPECT().GetOrderForUpdate(ctx, "order_1").Return(order, nil)
+		f.mockOrderRepo.EXPECT().UpdateOrder(ctx, order).Return(nil)
+
+		assert.NoError(t, f.orderService.CancelOrder(ctx, "user_1", "order_1"))
+		assert.Equal(t, model.OrderCancelled, order.Status)
+	})
+
+	t.Run("paid", func(t *testing.T) {
+		f := setupOrderService(t)
+		f.mockOrderRepo.EXPECT().GetOrderForUpdate(ctx, "order_1").Return(&model.Order{OrderId: "order_1", UserId: "user_1", Status: model.OrderPaid}, nil)
+
+		assert.ErrorIs(t, f.orderService.CancelOrder(ctx, "user_1", "order_1"), v1.ErrOrderStatus)
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		f := setupOrderService(t)
+		f.mockOrderRepo.EXPECT().GetOrderForUpdate(ctx, "order_1").Return(&model.Order{OrderId: "order_1", UserId: "user_2", Status: model.OrderPendingPayment}, nil)
+
+		assert.ErrorIs(t, f.orderService.CancelOrder(ctx, "user_1", "order_1"), v1.ErrNotFound)
+	})
+}
+
+func TestNewPaymentProvider(t *testing.T) {
+	conf := viper.New()
+	conf.Set("payment.mock.secret", "secret")
+
+	// 未配置支付渠道时照常启动，但不能发起支付
+	assert.False(t, payment.Available(payment.NewPaymentProvider(conf)))
+
+	// 模拟网关未显式开启时视为未配置，模拟支付页也不注册
+	conf.Set("payment.provider", payment.MockName)
+	assert.False(t, payment.MockAllowed(conf))
+	assert.False(t, payment.Available(payment.NewPaymentProvider(conf)))
+
+	conf.Set("payment.mock.enabled", true)
+	assert.True(t, payment.MockAllowed(conf))
+	assert.Equal(t, payment.MockName, payment.NewPaymentProvider(conf).Name())
+
+	conf.Set("payment.provider", "unknown")
+	assert.Panics(t, func() { payment.NewPaymentProvider(conf) })
+}
+
+func TestOrderService_PaymentUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srv := service.NewService(mock_repository.NewMockTransaction(ctrl), logger, sf, j)
+	provider := payment.NewPaymentProvider(viper.New())
+	orderService := service.NewOrderService(srv, viper.New(), mock_repository.NewMockOrderRepository(ctrl), mock_repository.NewMockUserRepository(ctrl), mock_repository.NewMockOrganizationRepository(ctrl), mock_repository.NewMockVnetRepository(ctrl), mock_service.NewMockUserService(ctrl), mock_service.NewMockOrganizationService(ctrl), newTestPlanService(ctrl, testPlans()), provider)
+
+	ctx := context.Background()
+
+	// 未配置支付渠道时下单返回业务错误，不创建订单
+	_, err := orderService.CreateOrder(ctx, "user_1", "", &v1.PurchasePackageRequest{PackageType: 3, Duration: 2})
+	assert.Equal(t, v1.ErrPaymentUnavailable, err)
+
+	err = orderService.HandleNotification(ctx, "", http.Header{}, []byte("{}"))
+	assert.Equal(t, v1.ErrNotFound, err)
+}
@@ -0,0 +1,174 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type organizationFixture struct {
+	organizationService  service.OrganizationService
+	mockUserRepo         *mock_repository.MockUserRepository
+	mockOrganizationRepo *mock_repository.MockOrganizationRepository
+	mockVnetRepo         *mock_repository.MockVnetRepository
+	mockVnetService      *mock_service.MockVnetService
+}
+
+func setupOrganizationService(t *testing.T) *organizationFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &organizationFixture{
+		mockUserRepo:         mock_repository.NewMockUserRepository(ctrl),
+		mockOrganizationRepo: mock_repository.NewMockOrganizationRepository(ctrl),
+		mockVnetRepo:         mock_repository.NewMockVnetRepository(ctrl),
+		mockVnetService:      mock_service.NewMockVnetService(ctrl),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	f.organizationService = service.NewOrganizationService(srv, f.mockUserRepo, f.mockOrganizationRepo, f.mockVnetRepo, f.mockVnetService, newTestPlanService(ctrl, testPlans()))
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return f
+}
+
+func TestOrganizationService_SetMember(t *testing.T) {
+	ctx := context.Background()
+	org := &model.Organization{OrgId: "org_1"}
+
+	t.Run("add member", func(t *testing.T) {
+		f := setupOrganizationService(t)
+		f.mockUserRepo.EXPECT().GetByEmail(ctx, "bob@example.com").Return(&model.User{UserId: "bob"}, nil)
+		f.mockOrganizationRepo.EXPECT().GetMember(ctx, "org_1", "bob").Return(nil, nil)
+		f.mockOrganizationRepo.EXPECT().SaveMember(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, member *model.OrganizationMember) error {
+			assert.Equal(t, "bob", member.UserId)
+			assert.Equal(t, model.OrgRoleAdmin, member.Role)
+			return nil
+		})
+
+		err := f.organizationService.SetMember(ctx, org, "owner", model.OrgRoleOwner, &v1.SetOrganizationMemberRequest{Account: "bob@example.com", Role: model.OrgRoleAdmin})
+		assert.NoError(t, err)
+	})
+
+	t.Run("admin cannot grant admin", func(t *testing.T) {
+		f := setupOrganizationService(t)
+
+		err := f.organizationService.SetMember(ctx, org, "alice", model.OrgRoleAdmin, &v1.SetOrganizationMemberRequest{Account: "bob", Role: model.OrgRoleAdmin})
+		assert.ErrorIs(t, err, v1.ErrForbidden)
+	})
+
+	t.Run("admin cannot demote another admin", func(t *testing.T) {
+		f := setupOrganizationService(t)
+		f.mockUserRepo.EXPECT().GetByUsername(ctx, "bob").Return(&model.User{UserId: "bob"}, nil)
+		f.mockOrganizationRepo.EXPECT().GetMember(ctx, "org_1", "bob").Return(&model.OrganizationMember{OrgId: "org_1", UserId: "bob", Role: model.OrgRoleAdmin}, nil)
+
+		err := f.organizationService.SetMember(ctx, org, "alice", model.OrgRoleAdmin, &v1.SetOrganizationMemberRequest{Account: "bob", Role: model.OrgRoleMember})
+		assert.ErrorIs(t, err, v1.ErrForbidden)
+	})
+}
+
+func TestOrganizationService_RemoveMember(t *testing.T) {
+	ctx := context.Background()
+	org := &model.Organization{OrgId: "org_1"}
+
+	t.Run("leave", func(t *testing.T) {
+		f := setupOrganizationService(t)
+		f.mockOrganizationRepo.EXPECT().GetMember(ctx, "org_1", "bob").Return(&model.OrganizationMember{OrgId: "org_1", UserId: "bob", Role: model.OrgRoleMember}, nil)
+		f.mockOrganizationRepo.EXPECT().DeleteMember(ctx, "org_1", "bob").Return(true, nil)
+
+		err := f.organizationService.RemoveMember(ctx, org, "bob", model.OrgRoleMember, "bob")
+		assert.NoError(t, err)
+	})
+
+	t.Run("owner cannot leave", func(t *testing.T) {
+		f := setupOrganizationService(t)
+		f.mockOrganizationRepo.EXPECT().GetMember(ctx, "org_1", "owner").Return(&model.OrganizationMember{OrgId: "org_1", UserId: "owner", Role: model.OrgRoleOwner}, nil)
+
+		err := f.organizationService.RemoveMember(ctx, org, "owner", model.OrgRoleOwner, "owner")
+		assert.ErrorIs(t, err, v1.ErrForbidden)
+	})
+}
+
+func TestOrganizationService_PurchasePackage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("new package", func(t *testing.T) {
+		f := setupOrganizationService(t)
+		f.mockOrganizationRepo.EXPECT().GetOrganizationForUpdate(ctx, "org_1").Return(&model.Organization{OrgId: "org_1", UserGroup: 1}, nil)
+		f.mockOrganizationRepo.EXPECT().UpdateOrganization(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, org *model.Organization) error {
+			assert.Equal(t, 3, org.UserGroup)
+			assert.Equal(t, silverTraffic, org.RemainingTraffic)
+			assert.NotNil(t, org.PrivilegeExpiry)
+			return nil
+		})
+		f.mockVnetService.EXPECT().SyncOrgTrafficSuspension(ctx, "org_1").Return(nil)
+
+		err := f.organizationService.PurchasePackage(ctx, "org_1", &v1.PurchasePackageRequest{PackageType: 3, Duration: 1})
+		assert.NoError(t, err)
+	})
+
+	t.Run("downgrade is scheduled for the end of the period", func(t *testing.T) {
+		f := setupOrganizationService(t)
+		expiry := time.Now().AddDate(0, 0, 10)
+		f.mockOrganizationRepo.EXPECT().GetOrganizationForUpdate(ctx, "org_1").Return(&model.Organization{OrgId: "org_1", UserGroup: 3, PrivilegeExpiry: &expiry, RemainingTraffic: 100}, nil)
+		f.mockOrganizationRepo.EXPECT().UpdateOrganization(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, org *model.Organization) error {
+			// 当前套餐保持到到期时间，到期后切换为青铜套餐
+			assert.Equal(t, 3, org.UserGroup)
+			assert.True(t, org.PrivilegeExpiry.Equal(expiry))
+			assert.Equal(t, int64(100), org.RemainingTraffic)
+			assert.Equal(t, 2, org.ScheduledGroup)
+			if assert.NotNil(t, org.ScheduledExpiry) {
+				assert.True(t, org.ScheduledExpiry.Equal(expiry.AddDate(0, 1, 0)))
+			}
+			return nil
+		})
+		f.mockVnetService.EXPECT().SyncOrgTrafficSuspension(ctx, "org_1").Return(nil)
+
+		err := f.organizationService.PurchasePackage(ctx, "org_1", &v1.PurchasePackageRequest{PackageType: 2, Duration: 1})
+		assert.NoError(t, err)
+	})
+}
+
+func TestUsageService_ReportUsage_OrgVnet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockUsageRepo := mock_repository.NewMockUsageRepository(ctrl)
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockOrganizationRepo := mock_repository.NewMockOrganizationRepository(ctrl)
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	usageService := service.NewUsageService(srv, mockUsageRepo, mockVnetRepo, mock_repository.NewMockUserRepository(ctrl), mockOrganizationRepo, mockVnetService)
+
+	ctx := context.Background()
+	req := newReportUsageRequest(v1.UsageRecord{VnetId: "vnet_1", ClientId: "client_1", BytesIn: 100, BytesOut: 200})
+
+	mockUsageRepo.EXPECT().GetUsageBatch(ctx, "relay-1", "batch_1").Return(nil, nil)
+	mockVnetRepo.EXPECT().GetVnetsByVnetIds(ctx, []string{"vnet_1"}).Return(&[]model.Vnet{
+		{VnetId: "vnet_1", UserId: "user_1", OrgId: "org_1", Enabled: true, NodeId: "relay-1"},
+	}, nil)
+	mockTm.EXPECT().Transaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mockUsageRepo.EXPECT().CreateUsageBatch(ctx, gomock.Any()).Return(nil)
+	mockUsageRepo.EXPECT().CreateUsages(ctx, gomock.Any()).Return(nil)
+	// 组织的虚拟网络从组织的流量池扣减，不扣减创建者的流量
+	mockOrganizationRepo.EXPECT().DebitTraffic(ctx, "org_1", int64(300)).Return(int64(0), nil)
+	mockVnetService.EXPECT().SyncOrgTrafficSuspension(ctx, "org_1").Return(nil)
+
+	result, err := usageService.ReportUsage(ctx, "relay-1", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Accepted)
+}
@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试套餐目录中各付费套餐的月流量
+const (
+	bronzeTraffic int64 = 50 * 1024 * 1024 * 1024
+	silverTraffic int64 = 200 * 1024 * 1024 * 1024
+	goldTraffic   int64 = 1024 * 1024 * 1024 * 1024
+)
+
+// testPlans 与配置文件中初始套餐目录相同的套餐
+func testPlans() []model.Plan {
+	months := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	features := []string{model.PlanFeatureWireGuard, model.PlanFeatureRoutes}
+	return []model.Plan{
+		{UserGroup: 1, Name: "普通用户", VnetLimit: 1, ClientsPerVnet: 3, AclRulesPerVnet: 10, Features: features, SortOrder: 1},
+		{UserGroup: 2, Name: "青铜用户", MonthlyPrice: 1000, Durations: months, MonthlyTraffic: bronzeTraffic, VnetLimit: 3, ClientsPerVnet: 5, AclRulesPerVnet: 50, Features: features, Purchasable: true, SortOrder: 2},
+		{UserGroup: 3, Name: "白银用户", MonthlyPrice: 3000, Durations: months, MonthlyTraffic: silverTraffic, VnetLimit: 5, ClientsPerVnet: 10, AclRulesPerVnet: 100, Features: features, Purchasable: true, SortOrder: 3},
+		{UserGroup: 4, Name: "黄金用户", MonthlyPrice: 10000, Durations: months, MonthlyTraffic: goldTraffic, VnetLimit: 10, ClientsPerVnet: 999999, AclRulesPerVnet: 500, Features: features, Purchasable: true, SortOrder: 4},
+	}
+}
+
+// newTestPlanService 以 plans 为套餐目录的 PlanService
+func newTestPlanService(ctrl *gomock.Controller, plans []model.Plan) service.PlanService {
+	mockPlanRepo := mock_repository.NewMockPlanRepository(ctrl)
+	mockPlanRepo.EXPECT().GetPlans(gomock.Any()).Return(&plans, nil).AnyTimes()
+	return service.NewPlanService(service.NewService(mock_repository.NewMockTransaction(ctrl), logger, sf, j), viper.New(), mockPlanRepo)
+}
+
+func TestPlanService_GetPlans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	plans := testPlans()
+	// 下架的套餐不在商店页展示
+	plans[3].Purchasable = false
+	planService := newTestPlanService(ctrl, plans)
+
+	data, err := planService.GetPlans(context.Background())
+
+	assert.NoError(t, err)
+	if assert.Len(t, data.Plans, 3) {
+		assert.Equal(t, 1, data.Plans[0].UserGroup)
+		assert.False(t, data.Plans[0].Purchasable)
+		assert.Empty(t, data.Plans[0].Durations)
+		assert.Equal(t, 3, data.Plans[2].UserGroup)
+		assert.True(t, data.Plans[2].Purchasable)
+		assert.Equal(t, int64(3000), data.Plans[2].MonthlyPrice)
+		assert.Equal(t, silverTraffic, data.Plans[2].MonthlyTraffic)
+	}
+}
+
+func TestPlanService_GetCatalog_Cached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	plans := testPlans()
+	mockPlanRepo := mock_repository.NewMockPlanRepository(ctrl)
+	mockPlanRepo.EXPECT().GetPlans(gomock.Any()).Return(&plans, nil).Times(1)
+	planService := service.NewPlanService(service.NewService(mock_repository.NewMockTransaction(ctrl), logger, sf, j), viper.New(), mockPlanRepo)
+
+	first, err := planService.GetCatalog(context.Background())
+	assert.NoError(t, err)
+	second, err := planService.GetCatalog(context.Background())
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+}
+
+func TestPlanCatalog_Effective(t *testing.T) {
+	catalog := model.NewPlanCatalog(testPlans()[1:])
+
+	// 特权过期后按免费套餐计算；目录中缺少免费套餐时没有任何额度
+	expired := &model.User{UserGroup: 3}
+	assert.Equal(t, model.FreeUserGroup, catalog.Effective(expired).UserGroup)
+	assert.Equal(t, 0, catalog.Entitle(expired).GetVirtualNetworkLimit())
+
+	catalog = model.NewPlanCatalog(testPlans())
+	assert.Equal(t, 3, catalog.Entitle(expired).GetMaxClientsLimitPerVNet())
+
+	// 用户组已从目录中移除时按免费套餐计算
+	expiry := time.Now().AddDate(0, 1, 0)
+	removed := &model.User{UserGroup: 9, PrivilegeExpiry: &expiry}
+	assert.Equal(t, 1, catalog.Entitle(removed).GetVirtualNetworkLimit())
+
+	active := &model.User{UserGroup: 4, PrivilegeExpiry: &expiry}
+	assert.Equal(t, 500, catalog.Entitle(active).GetMaxAclRulesPerVNet())
+	assert.True(t, catalog.Entitle(active).HasFeature(model.PlanFeatureRoutes))
+
+	assert.True(t, catalog.Get(2).CanPurchase(12))
+	assert.False(t, catalog.Get(2).CanPurchase(13))
+	assert.False(t, catalog.Get(1).CanPurchase(1))
+}
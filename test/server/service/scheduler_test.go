@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type schedulerFixture struct {
+	schedulerService     service.SchedulerService
+	mockVnetRepo         *mock_repository.MockVnetRepository
+	mockNodeRepo         *mock_repository.MockNodeRepository
+	mockVnetEventService *mock_service.MockVnetEventService
+	assigned             map[string]string
+}
+
+func setupSchedulerService(t *testing.T, maxMoves int, nodes []model.Node, vnets []model.Vnet) *schedulerFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &schedulerFixture{
+		mockVnetRepo:         mock_repository.NewMockVnetRepository(ctrl),
+		mockNodeRepo:         mock_repository.NewMockNodeRepository(ctrl),
+		mockVnetEventService: mock_service.NewMockVnetEventService(ctrl),
+		assigned:             make(map[string]string),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+
+	conf := viper.New()
+	conf.Set("scheduler.max_moves", maxMoves)
+	f.schedulerService = service.NewSchedulerService(srv, conf, f.mockVnetRepo, f.mockNodeRepo, f.mockVnetEventService)
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+	f.mockNodeRepo.EXPECT().ListNodes(gomock.Any()).Return(&nodes, nil)
+	f.mockVnetRepo.EXPECT().GetEnabledVnets(gomock.Any()).Return(&vnets, nil)
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, vnetId string) (*model.Vnet, error) {
+		for _, vnet := range vnets {
+			if vnet.VnetId == vnetId {
+				locked := vnet
+				return &locked, nil
+			}
+		}
+		t.Fatalf("unexpected vnet %s", vnetId)
+		return nil, nil
+	}).AnyTimes()
+	f.mockVnetRepo.EXPECT().UpdateVnet(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, vnet *model.Vnet) error {
+		assert.True(t, vnet.NeedUpdate)
+		f.assigned[vnet.VnetId] = vnet.NodeId
+		return nil
+	}).AnyTimes()
+
+	return f
+}
+
+func onlineNode(nodeId string, region string, capacity int) model.Node {
+	return model.Node{NodeId: nodeId, Region: region, Capacity: capacity, Status: model.NodeStatusOnline}
+}
+
+func TestSchedulerService_Reconcile_Failover(t *testing.T) {
+	offline := onlineNode("node_1", "cn-east", 100)
+	offline.Status = model.NodeStatusOffline
+	draining := onlineNode("node_3", "cn-east", 100)
+	draining.Draining = true
+	nodes := []model.Node{offline, onlineNode("node_2", "cn-east", 100), draining, onlineNode("node_4", "cn-north", 100)}
+	vnets := []model.Vnet{
+		{VnetId: "vnet_1", Enabled: true, NodeId: "node_1", ClientsLimit: 10, Region: "cn-east", Revision: 3},
+		{VnetId: "vnet_2", Enabled: true, NodeId: "node_removed", ClientsLimit: 10},
+		{VnetId: "vnet_3", Enabled: true, NodeId: "node_3", ClientsLimit: 10},
+	}
+	f := setupSchedulerService(t, 20, nodes, vnets)
+
+	// 先通知原节点移除，再通知新节点加载
+	gomock.InOrder(
+		f.mockVnetEventService.EXPECT().Record(gomock.Any(), model.VnetEventDelete, gomock.Any()).DoAndReturn(func(ctx context.Context, eventType string, vnet *model.Vnet) error {
+			assert.Equal(t, "node_1", vnet.NodeId)
+			assert.Equal(t, int64(4), vnet.Revision)
+			return nil
+		}),
+		f.mockVnetEventService.EXPECT().Record(gomock.Any(), model.VnetEventCreate, gomock.Any()).DoAndReturn(func(ctx context.Context, eventType string, vnet *model.Vnet) error {
+			assert.Equal(t, "node_2", vnet.NodeId)
+			assert.Equal(t, int64(4), vnet.Revision)
+			return nil
+		}),
+	)
+	f.mockVnetEventService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	moved, err := f.schedulerService.Reconcile(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	// 优先分配到同区域的节点，下线中的节点不承接新的虚拟网络但保留已分配的
+	assert.Equal(t, map[string]string{"vnet_1": "node_2", "vnet_2": "node_4"}, f.assigned)
+}
+
+func TestSchedulerService_Reconcile_Capacity(t *testing.T) {
+	nodes := []model.Node{onlineNode("node_1", "cn-east", 20), onlineNode("node_2", "cn-east", 30)}
+	vnets := []model.Vnet{
+		{VnetId: "vnet_1", Enabled: true, ClientsLimit: 15},
+		{VnetId: "vnet_2", Enabled: true, ClientsLimit: 15},
+		{VnetId: "vnet_3", Enabled: true, ClientsLimit: 15},
+		{VnetId: "vnet_4", Enabled: true, ClientsLimit: 15},
+	}
+	f := setupSchedulerService(t, 20, nodes, vnets)
+
+	// 尚未分配的虚拟网络只记录新节点的创建事件
+	f.mockVnetEventService.EXPECT().Record(gomock.Any(), model.VnetEventCreate, gomock.Any()).Return(nil).Times(3)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	moved, err := f.schedulerService.Reconcile(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, moved)
+	// 按占用比例分配，容量不足时不分配
+	assert.Equal(t, map[string]string{"vnet_1": "node_1", "vnet_2": "node_2", "vnet_3": "node_2"}, f.assigned)
+}
+
+func TestSchedulerService_Reconcile_MoveLimit(t *testing.T) {
+	offline := onlineNode("node_1", "", 0)
+	offline.Status = model.NodeStatusOffline
+	nodes := []model.Node{offline, onlineNode("node_2", "", 0)}
+	vnets := []model.Vnet{
+		{VnetId: "vnet_1", Enabled: true, ClientsLimit: 10},
+		{VnetId: "vnet_2", Enabled: true, NodeId: "node_1", ClientsLimit: 10},
+		{VnetId: "vnet_3", Enabled: true, NodeId: "node_1", ClientsLimit: 10},
+	}
+	f := setupSchedulerService(t, 2, nodes, vnets)
+
+	f.mockVnetEventService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(4)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	moved, err := f.schedulerService.Reconcile(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	// 故障转移优先于新分配，超出的留到下一轮
+	assert.Equal(t, map[string]string{"vnet_2": "node_2", "vnet_3": "node_2"}, f.assigned)
+}
+
+func TestSchedulerService_Reconcile_Rebalance(t *testing.T) {
+	nodes := []model.Node{onlineNode("node_1", "cn-east", 25), onlineNode("node_2", "cn-north", 100), onlineNode("node_3", "cn-north", 100)}
+	vnets := []model.Vnet{
+		// node_1 超载，迁出一个后恢复正常
+		{VnetId: "vnet_1", Enabled: true, NodeId: "node_1", ClientsLimit: 15},
+		{VnetId: "vnet_2", Enabled: true, NodeId: "node_1", ClientsLimit: 15},
+		// 优先区域没有可用节点时保持不变
+		{VnetId: "vnet_3", Enabled: true, NodeId: "node_2", ClientsLimit: 10, Region: "us-west"},
+		// 不在优先区域，迁入优先区域
+		{VnetId: "vnet_4", Enabled: true, NodeId: "node_2", ClientsLimit: 10, Region: "cn-east"},
+	}
+	f := setupSchedulerService(t, 20, nodes, vnets)
+
+	f.mockVnetEventService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(4)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	moved, err := f.schedulerService.Reconcile(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	assert.Equal(t, map[string]string{"vnet_1": "node_3", "vnet_4": "node_1"}, f.assigned)
+}
+
+func TestSchedulerService_Reconcile_NoChange(t *testing.T) {
+	nodes := []model.Node{onlineNode("node_1", "cn-east", 100)}
+	vnets := []model.Vnet{{VnetId: "vnet_1", Enabled: true, NodeId: "node_1", ClientsLimit: 10, Region: "cn-east"}}
+	f := setupSchedulerService(t, 20, nodes, vnets)
+
+	moved, err := f.schedulerService.Reconcile(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, moved)
+	assert.Empty(t, f.assigned)
+}
@@ -0,0 +1,383 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type subscriptionFixture struct {
+	subscriptionService  service.SubscriptionService
+	mockUserRepo         *mock_repository.MockUserRepository
+	mockOrganizationRepo *mock_repository.MockOrganizationRepository
+	mockTrafficResetRepo *mock_repository.MockTrafficResetRepository
+	mockNotificationRepo *mock_repository.MockNotificationRepository
+	mockVnetService      *mock_service.MockVnetService
+	mockVnetEventService *mock_service.MockVnetEventService
+}
+
+func setupSubscriptionService(t *testing.T) *subscriptionFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &subscriptionFixture{
+		mockUserRepo:         mock_repository.NewMockUserRepository(ctrl),
+		mockOrganizationRepo: mock_repository.NewMockOrganizationRepository(ctrl),
+		mockTrafficResetRepo: mock_repository.NewMockTrafficResetRepository(ctrl),
+		mockNotificationRepo: mock_repository.NewMockNotificationRepository(ctrl),
+		mockVnetService:      mock_service.NewMockVnetService(ctrl),
+		mockVnetEventService: mock_service.NewMockVnetEventService(ctrl),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	conf := viper.New()
+	conf.Set("subscription.expiry_grace", "72h")
+	f.subscriptionService = service.NewSubscriptionService(srv, conf, f.mockUserRepo, f.mockOrganizationRepo, f.mockTrafficResetRepo, f.mockNotificationRepo, newTestPlanService(ctrl, testPlans()), f.mockVnetService, f.mockVnetEventService)
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return f
+}
+
+// dueUser 白银用户，计费周期起点为 anchor，下一次重置时间为 next
+func dueUser(anchor time.Time, next time.Time, remaining int64) *model.User {
+	expiry := anchor.AddDate(0, 12, 0)
+	return &model.User{
+		UserId:           "user_1",
+		UserGroup:        3,
+		PrivilegeExpiry:  &expiry,
+		RemainingTraffic: remaining,
+		BillingCycle:     model.BillingCycle{BillingAnchor: &anchor, NextTrafficReset: &next},
+	}
+}
+
+func TestSubscriptionService_ResetMonthlyTraffic(t *testing.T) {
+	f := setupSubscriptionService(t)
+
+	anchor := time.Now().AddDate(0, -1, 0).Add(-time.Hour)
+	user := dueUser(anchor, anchor.AddDate(0, 1, 0), 1024)
+
+	f.mockUserRepo.EXPECT().GetTrafficResetDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.User{*user}, nil)
+	f.mockUserRepo.EXPECT().GetByIDForUpdate(gomock.Any(), "user_1").Return(user, nil)
+	f.mockTrafficResetRepo.EXPECT().CreateReset(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, reset *model.TrafficReset) (bool, error) {
+		assert.Equal(t, model.TrafficResetOwnerUser, reset.OwnerType)
+		assert.Equal(t, "user_1", reset.OwnerId)
+		assert.True(t, reset.CycleStart.Equal(anchor.AddDate(0, 1, 0)))
+		assert.Equal(t, int64(1024), reset.PreviousTraffic)
+		assert.Equal(t, silverTraffic, reset.Traffic)
+		return true, nil
+	})
+	f.mockUserRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, u *model.User) error {
+		assert.Equal(t, silverTraffic, u.RemainingTraffic)
+		assert.True(t, u.NextTrafficReset.Equal(anchor.AddDate(0, 2, 0)))
+		return nil
+	})
+	f.mockVnetService.EXPECT().SyncTrafficSuspension(gomock.Any(), "user_1").Return(nil)
+	f.mockOrganizationRepo.EXPECT().GetTrafficResetDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.Organization{}, nil)
+
+	err := f.subscriptionService.ResetMonthlyTraffic(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestSubscriptionService_ResetMonthlyTraffic_AlreadyReset(t *testing.T) {
+	f := setupSubscriptionService(t)
+
+	anchor := time.Now().AddDate(0, -1, 0).Add(-time.Hour)
+	user := dueUser(anchor, anchor.AddDate(0, 1, 0), 1024)
+
+	f.mockUserRepo.EXPECT().GetTrafficResetDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.User{*user}, nil)
+	f.mockUserRepo.EXPECT().GetByIDForUpdate(gomock.Any(), "user_1").Return(user, nil)
+	// 本周期已有重置记录，只推进下一次重置时间，不再重置流量
+	f.mockTrafficResetRepo.EXPECT().CreateReset(gomock.Any(), gomock.Any()).Return(false, nil)
+	f.mockUserRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, u *model.User) error {
+		assert.Equal(t, int64(1024), u.RemainingTraffic)
+		assert.True(t, u.NextTrafficReset.Equal(anchor.AddDate(0, 2, 0)))
+		return nil
+	})
+	f.mockOrganizationRepo.EXPECT().GetTrafficResetDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.Organization{}, nil)
+
+	err := f.subscriptionService.ResetMonthlyTraffic(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestSubscriptionService_ResetMonthlyTraffic_NotDueAfterLock(t *testing.T) {
+	f := setupSubscriptionService(t)
+
+	anchor := time.Now().AddDate(0, -1, 0).Add(-time.Hour)
+	stale := dueUser(anchor, anchor.AddDate(0, 1, 0), 1024)
+	// 另一次执行已在加锁前完成了重置
+	locked := dueUser(anchor, anchor.AddDate(0, 2, 0), silverTraffic)
+
+	f.mockUserRepo.EXPECT().GetTrafficResetDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.User{*stale}, nil)
+	f.mockUserRepo.EXPECT().GetByIDForUpdate(gomock.Any(), "user_1").Return(locked, nil)
+	f.mockOrganizationRepo.EXPECT().GetTrafficResetDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.Organization{}, nil)
+
+	err := f.subscriptionService.ResetMonthlyTraffic(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestSubscriptionService_ResetMonthlyTraffic_DelayedCycles(t *testing.T) {
+	f := setupSubscriptionService(t)
+
+	// 任务停止了两个多月，只重置最近的一个周期
+	anchor := time.Now().AddDate(0, -3, 0).Add(-time.Hour)
+	expiry := anchor.AddDate(0, 12, 0)
+	next := anchor.AddDate(0, 1, 0)
+	org := &model.Organization{
+		OrgId:            "org_1",
+		UserGroup:        4,
+		PrivilegeExpiry:  &expiry,
+		RemainingTraffic: 0,
+		BillingCycle:     model.BillingCycle{BillingAnchor: &anchor, NextTrafficReset: &next},
+	}
+
+	f.mockUserRepo.EXPECT().GetTrafficResetDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.User{}, nil)
+	f.mockOrganizationRepo.EXPECT().GetTrafficResetDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.Organization{*org}, nil)
+	f.mockOrganizationRepo.EXPECT().GetOrganizationForUpdate(gomock.Any(), "org_1").Return(org, nil)
+	f.mockTrafficResetRepo.EXPECT().CreateReset(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, reset *model.TrafficReset) (bool, error) {
+		assert.Equal(t, model.TrafficResetOwnerOrg, reset.OwnerType)
+		assert.True(t, reset.CycleStart.Equal(anchor.AddDate(0, 3, 0)))
+		return true, nil
+	}).Times(1)
+	f.mockOrganizationRepo.EXPECT().UpdateOrganization(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, o *model.Organization) error {
+		assert.Equal(t, goldTraffic, o.RemainingTraffic)
+		assert.True(t, o.NextTrafficReset.Equal(anchor.AddDate(0, 4, 0)))
+		return nil
+	})
+	f.mockVnetService.EXPECT().SyncOrgTrafficSuspension(gomock.Any(), "org_1").Return(nil)
+
+	err := f.subscriptionService.ResetMonthlyTraffic(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestSubscriptionService_ExpirePrivileges(t *testing.T) {
+	f := setupSubscriptionService(t)
+
+	// 到期 4 天，超过了 3 天的宽限期
+	expiry := time.Now().AddDate(0, 0, -4)
+	anchor := expiry.AddDate(0, -1, 0)
+	user := dueUser(anchor, expiry, 1024)
+	user.PrivilegeExpiry = &expiry
+
+	f.mockUserRepo.EXPECT().GetPrivilegeExpired(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, before time.Time, limit int) (*[]model.User, error) {
+		assert.WithinDuration(t, time.Now().Add(-72*time.Hour), before, time.Minute)
+		return &[]model.User{*user}, nil
+	})
+	f.mockUserRepo.EXPECT().GetByIDForUpdate(gomock.Any(), "user_1").Return(user, nil)
+	f.mockUserRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, u *model.User) error {
+		assert.Equal(t, model.FreeUserGroup, u.UserGroup)
+		assert.Nil(t, u.BillingAnchor)
+		assert.Nil(t, u.NextTrafficReset)
+		// 剩余流量保留
+		assert.Equal(t, int64(1024), u.RemainingTraffic)
+		return nil
+	})
+	f.mockVnetService.EXPECT().ApplyPlanLimits(gomock.Any(), "user_1", "", gomock.Any()).DoAndReturn(func(ctx context.Context, userId string, orgId string, plan *model.Plan) (*service.PlanLimitResult, error) {
+		assert.Equal(t, model.FreeUserGroup, plan.UserGroup)
+		return &service.PlanLimitResult{Disabled: []model.Vnet{{VnetId: "vnet_2", Comment: "家庭网络"}}}, nil
+	})
+	f.mockNotificationRepo.EXPECT().CreateNotifications(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, notifications []model.Notification) error {
+		if assert.Len(t, notifications, 1) {
+			assert.Equal(t, "user_1", notifications[0].UserId)
+			assert.Equal(t, model.NotificationPrivilegeExpired, notifications[0].Type)
+			assert.True(t, strings.Contains(notifications[0].Content, "白银用户"))
+			assert.True(t, strings.Contains(notifications[0].Content, "家庭网络"))
+		}
+		return nil
+	})
+	f.mockVnetEventService.EXPECT().Notify()
+	f.mockOrganizationRepo.EXPECT().GetPrivilegeExpired(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.Organization{}, nil)
+
+	err := f.subscriptionService.ExpirePrivileges(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestSubscriptionService_ExpirePrivileges_RenewedBeforeLock(t *testing.T) {
+	f := setupSubscriptionService(t)
+
+	expired := time.Now().AddDate(0, 0, -4)
+	renewed := time.Now().AddDate(0, 1, 0)
+
+	f.mockUserRepo.EXPECT().GetPrivilegeExpired(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.User{{UserId: "user_1", UserGroup: 3, PrivilegeExpiry: &expired}}, nil)
+	// 加锁前用户已续费，不再降级
+	f.mockUserRepo.EXPECT().GetByIDForUpdate(gomock.Any(), "user_1").Return(&model.User{UserId: "user_1", UserGroup: 3, PrivilegeExpiry: &renewed}, nil)
+	f.mockOrganizationRepo.EXPECT().GetPrivilegeExpired(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.Organization{}, nil)
+
+	err := f.subscriptionService.ExpirePrivileges(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestSubscriptionService_ExpirePrivileges_Org(t *testing.T) {
+	f := setupSubscriptionService(t)
+
+	expiry := time.Now().AddDate(0, 0, -10)
+	org := &model.Organization{OrgId: "org_1", Name: "研发部", UserGroup: 4, PrivilegeExpiry: &expiry}
+
+	f.mockUserRepo.EXPECT().GetPrivilegeExpired(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.User{}, nil)
+	f.mockOrganizationRepo.EXPECT().GetPrivilegeExpired(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.Organization{*org}, nil)
+	f.mockOrganizationRepo.EXPECT().GetOrganizationForUpdate(gomock.Any(), "org_1").Return(org, nil)
+	f.mockOrganizationRepo.EXPECT().UpdateOrganization(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, o *model.Organization) error {
+		assert.Equal(t, model.FreeUserGroup, o.UserGroup)
+		return nil
+	})
+	// 没有超出限制的虚拟网络时不唤醒订阅者
+	f.mockVnetService.EXPECT().ApplyPlanLimits(gomock.Any(), "", "org_1", gomock.Any()).Return(&service.PlanLimitResult{}, nil)
+	f.mockOrganizationRepo.EXPECT().GetMembers(gomock.Any(), "org_1").Return(&[]model.OrganizationMember{
+		{OrgId: "org_1", UserId: "owner", Role: model.OrgRoleOwner},
+		{OrgId: "org_1", UserId: "admin", Role: model.OrgRoleAdmin},
+		{OrgId: "org_1", UserId: "member", Role: model.OrgRoleMember},
+	}, nil)
+	f.mockNotificationRepo.EXPECT().CreateNotifications(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, notifications []model.Notification) error {
+		// 只通知所有者与管理员
+		if assert.Len(t, notifications, 2) {
+			assert.Equal(t, "owner", notifications[0].UserId)
+			assert.Equal(t, "admin", notifications[1].UserId)
+			assert.True(t, strings.Contains(notifications[0].Content, "研发部"))
+		}
+		return nil
+	})
+
+	err := f.subscriptionService.ExpirePrivileges(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestSubscriptionService_ApplyScheduledPlans(t *testing.T) {
+	f := setupSubscriptionService(t)
+
+	expiry := time.Now().Add(-time.Minute)
+	scheduled := expiry.AddDate(0, 2, 0)
+	user := &model.User{
+		UserId:           "user_1",
+		UserGroup:        4,
+		PrivilegeExpiry:  &expiry,
+		RemainingTraffic: 1024,
+		ScheduledPlan:    model.ScheduledPlan{ScheduledGroup: 2, ScheduledExpiry: &scheduled},
+	}
+
+	f.mockUserRepo.EXPECT().GetScheduledPlanDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.User{*user}, nil)
+	f.mockUserRepo.EXPECT().GetByIDForUpdate(gomock.Any(), "user_1").Return(user, nil)
+	f.mockUserRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, u *model.User) error {
+		assert.Equal(t, 2, u.UserGroup)
+		assert.True(t, u.PrivilegeExpiry.Equal(scheduled))
+		assert.Equal(t, bronzeTraffic, u.RemainingTraffic)
+		assert.False(t, u.HasScheduledPlan())
+		// 新的计费周期从原特权到期时间起算
+		if assert.NotNil(t, u.BillingAnchor) {
+			assert.True(t, u.BillingAnchor.Equal(expiry))
+			assert.True(t, u.NextTrafficReset.Equal(expiry.AddDate(0, 1, 0)))
+		}
+		return nil
+	})
+	f.mockVnetService.EXPECT().ApplyPlanLimits(gomock.Any(), "user_1", "", gomock.Any()).DoAndReturn(func(ctx context.Context, userId string, orgId string, plan *model.Plan) (*service.PlanLimitResult, error) {
+		assert.Equal(t, 2, plan.UserGroup)
+		return &service.PlanLimitResult{Clamped: []model.Vnet{{VnetId: "vnet_1"}}}, nil
+	})
+	f.mockNotificationRepo.EXPECT().CreateNotifications(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, notifications []model.Notification) error {
+		if assert.Len(t, notifications, 1) {
+			assert.Equal(t, model.NotificationPlanChanged, notifications[0].Type)
+			assert.True(t, strings.Contains(notifications[0].Content, "青铜用户"))
+			assert.True(t, strings.Contains(notifications[0].Content, "vnet_1"))
+		}
+		return nil
+	})
+	f.mockVnetEventService.EXPECT().Notify()
+	f.mockOrganizationRepo.EXPECT().GetScheduledPlanDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.Organization{}, nil)
+
+	err := f.subscriptionService.ApplyScheduledPlans(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestSubscriptionService_ApplyScheduledPlans_UpgradedBeforeLock(t *testing.T) {
+	f := setupSubscriptionService(t)
+
+	expiry := time.Now().Add(-time.Minute)
+	scheduled := expiry.AddDate(0, 1, 0)
+	upgraded := time.Now().AddDate(0, 1, 0)
+
+	f.mockUserRepo.EXPECT().GetScheduledPlanDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.User{{
+		UserId:          "user_1",
+		UserGroup:       3,
+		PrivilegeExpiry: &expiry,
+		ScheduledPlan:   model.ScheduledPlan{ScheduledGroup: 2, ScheduledExpiry: &scheduled},
+	}}, nil)
+	// 加锁前用户已升级，待生效的降级随之取消
+	f.mockUserRepo.EXPECT().GetByIDForUpdate(gomock.Any(), "user_1").Return(&model.User{UserId: "user_1", UserGroup: 4, PrivilegeExpiry: &upgraded}, nil)
+	f.mockOrganizationRepo.EXPECT().GetScheduledPlanDue(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.Organization{}, nil)
+
+	err := f.subscriptionService.ApplyScheduledPlans(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestNewPlanChange(t *testing.T) {
+	catalog := model.NewPlanCatalog(testPlans())
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiry := now.AddDate(0, 0, 30)
+
+	t.Run("renew extends the scheduled downgrade", func(t *testing.T) {
+		scheduled := expiry.AddDate(0, 1, 0)
+		c, err := model.NewPlanChange(catalog, 3, &expiry, model.ScheduledPlan{ScheduledGroup: 2, ScheduledExpiry: &scheduled}, catalog.Get(3), 1, now)
+		assert.NoError(t, err)
+		assert.Equal(t, model.PlanChangeRenew, c.Kind)
+		assert.Equal(t, expiry.AddDate(0, 1, 0), c.Expiry)
+		assert.Equal(t, scheduled.AddDate(0, 1, 0), *c.ScheduledExpiry)
+	})
+
+	t.Run("downgrade to the scheduled plan extends it", func(t *testing.T) {
+		scheduled := expiry.AddDate(0, 1, 0)
+		c, err := model.NewPlanChange(catalog, 3, &expiry, model.ScheduledPlan{ScheduledGroup: 2, ScheduledExpiry: &scheduled}, catalog.Get(2), 2, now)
+		assert.NoError(t, err)
+		assert.Equal(t, model.PlanChangeDowngrade, c.Kind)
+		assert.Equal(t, expiry, c.EffectiveAt)
+		assert.Equal(t, scheduled.AddDate(0, 2, 0), c.Expiry)
+	})
+
+	t.Run("upgrade converts the scheduled downgrade", func(t *testing.T) {
+		// 白银剩余 30 天与待生效的青铜 30 天，按黄金的价格折算为 12 天
+		scheduled := expiry.AddDate(0, 0, 30)
+		c, err := model.NewPlanChange(catalog, 3, &expiry, model.ScheduledPlan{ScheduledGroup: 2, ScheduledExpiry: &scheduled}, catalog.Get(4), 1, now)
+		assert.NoError(t, err)
+		assert.Equal(t, model.PlanChangeUpgrade, c.Kind)
+		assert.Equal(t, 12*24*time.Hour, c.Credit)
+		assert.Equal(t, now.AddDate(0, 1, 12), c.Expiry)
+
+		user := &model.User{UserGroup: 3, PrivilegeExpiry: &expiry, ScheduledPlan: model.ScheduledPlan{ScheduledGroup: 2, ScheduledExpiry: &scheduled}}
+		user.ApplyPlanChange(c)
+		assert.Equal(t, 4, user.UserGroup)
+		assert.Equal(t, goldTraffic, user.RemainingTraffic)
+		assert.False(t, user.HasScheduledPlan())
+		assert.Equal(t, now, *user.BillingAnchor)
+	})
+
+	t.Run("expired privilege starts a new period", func(t *testing.T) {
+		expired := now.AddDate(0, 0, -1)
+		c, err := model.NewPlanChange(catalog, 4, &expired, model.ScheduledPlan{}, catalog.Get(2), 1, now)
+		assert.NoError(t, err)
+		assert.Equal(t, model.PlanChangeNew, c.Kind)
+		assert.Equal(t, now.AddDate(0, 1, 0), c.Expiry)
+	})
+}
+
+func TestCycleBounds_MonthEnd(t *testing.T) {
+	anchor := time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC)
+
+	start, next := model.CycleBounds(anchor, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC))
+	// 2 月 31 日按 Go 的规则为 3 月 3 日
+	assert.Equal(t, time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 3, 31, 10, 0, 0, 0, time.UTC), next)
+
+	// 之后的周期仍以 31 日为起点，不会逐月漂移
+	start, next = model.CycleBounds(anchor, time.Date(2026, 5, 31, 10, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2026, 5, 31, 10, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC), next)
+}
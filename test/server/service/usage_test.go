@@ -3,24 +3,37 @@ package service_test
 import (
 	"context"
 	"errors"
+	"math"
 	"testing"
+	"time"
 
 	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
 	"hyacinth-backend/internal/service"
 	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
 func setupUsageService(t *testing.T) (service.UsageService, *mock_repository.MockUsageRepository) {
+	usageService, mockUsageRepo, _, _, _, _ := setupUsageServiceWithVnet(t)
+	return usageService, mockUsageRepo
+}
+
+func setupUsageServiceWithVnet(t *testing.T) (service.UsageService, *mock_repository.MockUsageRepository, *mock_repository.MockVnetRepository, *mock_repository.MockUserRepository, *mock_service.MockVnetService, *mock_repository.MockTransaction) {
 	ctrl := gomock.NewController(t)
 
 	mockUsageRepo := mock_repository.NewMockUsageRepository(ctrl)
-	srv := service.NewService(nil, logger, sf, j)
-	usageService := service.NewUsageService(srv, mockUsageRepo)
-
-	return usageService, mockUsageRepo
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockUserRepo := mock_repository.NewMockUserRepository(ctrl)
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	usageService := service.NewUsageService(srv, mockUsageRepo, mockVnetRepo, mockUserRepo, mock_repository.NewMockOrganizationRepository(ctrl), mockVnetService)
+
+	return usageService, mockUsageRepo, mockVnetRepo, mockUserRepo, mockVnetService, mockTm
 }
 
 func TestUsageService_GetUsage_24h(t *testing.T) {
@@ -392,3 +405,166 @@ func TestUsageService_GetUsage_ZeroUsage(t *testing.T) {
 		assert.Contains(t, usage.Date, "2024-01-")
 	}
 }
+
+func newReportUsageRequest(records ...v1.UsageRecord) *v1.ReportUsageRequest {
+	end := time.Now().Add(-time.Minute).Unix()
+	return &v1.ReportUsageRequest{
+		BatchId:     "batch_1",
+		WindowStart: end - 60,
+		WindowEnd:   end,
+		Records:     records,
+	}
+}
+
+func TestUsageService_ReportUsage(t *testing.T) {
+	usageService, mockUsageRepo, mockVnetRepo, mockUserRepo, _, mockTm := setupUsageServiceWithVnet(t)
+
+	ctx := context.Background()
+	req := newReportUsageRequest(
+		v1.UsageRecord{VnetId: "vnet_1", ClientId: "client_1", BytesIn: 100, BytesOut: 200},
+		v1.UsageRecord{VnetId: "vnet_1", ClientId: "client_2", BytesIn: 0, BytesOut: 50},
+		v1.UsageRecord{VnetId: "vnet_missing", BytesIn: 10},
+		v1.UsageRecord{VnetId: "vnet_disabled", BytesIn: 10},
+		v1.UsageRecord{VnetId: "vnet_other", BytesIn: 10},
+		v1.UsageRecord{VnetId: "vnet_unassigned", BytesIn: 10},
+	)
+
+	mockUsageRepo.EXPECT().GetUsageBatch(ctx, "relay-1", "batch_1").Return(nil, nil)
+	mockVnetRepo.EXPECT().GetVnetsByVnetIds(ctx, []string{"vnet_1", "vnet_missing", "vnet_disabled", "vnet_other", "vnet_unassigned"}).Return(&[]model.Vnet{
+		{VnetId: "vnet_1", UserId: "user_1", Enabled: true, NodeId: "relay-1"},
+		{VnetId: "vnet_disabled", UserId: "user_1", Enabled: false, NodeId: "relay-1"},
+		{VnetId: "vnet_other", UserId: "user_2", Enabled: true, NodeId: "relay-2"},
+		// 尚未分配节点的虚拟网络不能由任何节点计费
+		{VnetId: "vnet_unassigned", UserId: "user_2", Enabled: true},
+	}, nil)
+	mockTm.EXPECT().Transaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mockUsageRepo.EXPECT().CreateUsageBatch(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, batch *model.UsageBatch) error {
+		assert.Equal(t, 2, batch.Accepted)
+		assert.Equal(t, 4, batch.Rejected)
+		assert.Equal(t, int64(350), batch.TotalBytes)
+		return nil
+	})
+	mockUsageRepo.EXPECT().CreateUsages(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, usages []model.Usage) error {
+		assert.Len(t, usages, 2)
+		assert.Equal(t, "user_1", usages[0].UserId)
+		assert.Equal(t, int64(300), usages[0].Usage)
+		assert.Equal(t, "client_1", usages[0].ClientId)
+		assert.Equal(t, time.Unix(req.WindowStart, 0), usages[0].CreatedAt)
+		return nil
+	})
+	mockUserRepo.EXPECT().DebitTraffic(ctx, "user_1", int64(350)).Return(int64(1024), nil)
+
+	result, err := usageService.ReportUsage(ctx, "relay-1", req)
+
+	assert.NoError(t, err)
+	assert.False(t, result.Duplicate)
+	assert.Equal(t, 2, result.Accepted)
+	assert.Len(t, result.Rejected, 4)
+	assert.Equal(t, "vnet not found", result.Rejected[0].Reason)
+	assert.Equal(t, "vnet disabled", result.Rejected[1].Reason)
+	assert.Equal(t, "vnet not assigned to this node", result.Rejected[2].Reason)
+	assert.Equal(t, "vnet not assigned to this node", result.Rejected[3].Reason)
+}
+
+func TestUsageService_ReportUsage_TrafficExhausted(t *testing.T) {
+	usageService, mockUsageRepo, mockVnetRepo, mockUserRepo, mockVnetService, mockTm := setupUsageServiceWithVnet(t)
+
+	ctx := context.Background()
+	req := newReportUsageRequest(
+		v1.UsageRecord{VnetId: "vnet_2", BytesIn: 100},
+		v1.UsageRecord{VnetId: "vnet_1", BytesIn: 500},
+	)
+
+	mockUsageRepo.EXPECT().GetUsageBatch(ctx, "relay-1", "batch_1").Return(nil, nil)
+	mockVnetRepo.EXPECT().GetVnetsByVnetIds(ctx, []string{"vnet_2", "vnet_1"}).Return(&[]model.Vnet{
+		{VnetId: "vnet_1", UserId: "user_a", Enabled: true, NodeId: "relay-1"},
+		{VnetId: "vnet_2", UserId: "user_b", Enabled: true, NodeId: "relay-1"},
+	}, nil)
+	mockTm.EXPECT().Transaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mockUsageRepo.EXPECT().CreateUsageBatch(ctx, gomock.Any()).Return(nil)
+	mockUsageRepo.EXPECT().CreateUsages(ctx, gomock.Any()).Return(nil)
+	// 按用户ID顺序扣减
+	gomock.InOrder(
+		mockUserRepo.EXPECT().DebitTraffic(ctx, "user_a", int64(500)).Return(int64(0), nil),
+		mockUserRepo.EXPECT().DebitTraffic(ctx, "user_b", int64(100)).Return(int64(2048), nil),
+	)
+	// 仅流量耗尽的用户需要停用虚拟网络
+	mockVnetService.EXPECT().SyncTrafficSuspension(ctx, "user_a").Return(nil)
+
+	result, err := usageService.ReportUsage(ctx, "relay-1", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Accepted)
+}
+
+func TestUsageService_ReportUsage_DuplicateBatch(t *testing.T) {
+	usageService, mockUsageRepo, _, _, _, _ := setupUsageServiceWithVnet(t)
+
+	ctx := context.Background()
+	req := newReportUsageRequest(v1.UsageRecord{VnetId: "vnet_1", BytesIn: 100})
+
+	mockUsageRepo.EXPECT().GetUsageBatch(ctx, "relay-1", "batch_1").Return(&model.UsageBatch{BatchId: "batch_1", Accepted: 1}, nil)
+
+	result, err := usageService.ReportUsage(ctx, "relay-1", req)
+
+	assert.NoError(t, err)
+	assert.True(t, result.Duplicate)
+	assert.Equal(t, 1, result.Accepted)
+}
+
+func TestUsageService_ReportUsage_ConcurrentRetry(t *testing.T) {
+	usageService, mockUsageRepo, mockVnetRepo, _, _, mockTm := setupUsageServiceWithVnet(t)
+
+	ctx := context.Background()
+	req := newReportUsageRequest(v1.UsageRecord{VnetId: "vnet_1", BytesIn: 100})
+
+	gomock.InOrder(
+		mockUsageRepo.EXPECT().GetUsageBatch(ctx, "relay-1", "batch_1").Return(nil, nil),
+		mockUsageRepo.EXPECT().GetUsageBatch(ctx, "relay-1", "batch_1").Return(&model.UsageBatch{BatchId: "batch_1", Accepted: 1}, nil),
+	)
+	mockVnetRepo.EXPECT().GetVnetsByVnetIds(ctx, []string{"vnet_1"}).Return(&[]model.Vnet{{VnetId: "vnet_1", Enabled: true, NodeId: "relay-1"}}, nil)
+	// 另一个重试请求已先写入批次，唯一索引冲突
+	mockTm.EXPECT().Transaction(ctx, gomock.Any()).Return(errors.New("duplicate entry"))
+
+	result, err := usageService.ReportUsage(ctx, "relay-1", req)
+
+	assert.NoError(t, err)
+	assert.True(t, result.Duplicate)
+}
+
+func TestUsageService_ReportUsage_InvalidWindow(t *testing.T) {
+	usageService, _, _, _, _, _ := setupUsageServiceWithVnet(t)
+
+	ctx := context.Background()
+
+	req := newReportUsageRequest(v1.UsageRecord{VnetId: "vnet_1", BytesIn: 100})
+	req.WindowEnd = req.WindowStart
+	_, err := usageService.ReportUsage(ctx, "relay-1", req)
+	assert.Equal(t, v1.ErrBadRequest, err)
+
+	req = newReportUsageRequest(v1.UsageRecord{VnetId: "vnet_1", BytesIn: 100})
+	req.WindowEnd = time.Now().Add(time.Hour).Unix()
+	_, err = usageService.ReportUsage(ctx, "relay-1", req)
+	assert.Equal(t, v1.ErrBadRequest, err)
+
+	req = newReportUsageRequest(v1.UsageRecord{VnetId: "vnet_1", BytesIn: -1})
+	_, err = usageService.ReportUsage(ctx, "relay-1", req)
+	assert.Equal(t, v1.ErrBadRequest, err)
+
+	req = newReportUsageRequest()
+	_, err = usageService.ReportUsage(ctx, "relay-1", req)
+	assert.Equal(t, v1.ErrBadRequest, err)
+
+	// 超过线速上限的计数直接拒绝，相加也不会溢出为负数
+	req = newReportUsageRequest(v1.UsageRecord{VnetId: "vnet_1", BytesIn: math.MaxInt64, BytesOut: 1})
+	_, err = usageService.ReportUsage(ctx, "relay-1", req)
+	assert.Equal(t, v1.ErrBadRequest, err)
+
+	req = newReportUsageRequest(v1.UsageRecord{VnetId: "vnet_1", BytesIn: 60*12_500_000_000 + 1})
+	_, err = usageService.ReportUsage(ctx, "relay-1", req)
+	assert.Equal(t, v1.ErrBadRequest, err)
+}
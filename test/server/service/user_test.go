@@ -10,6 +10,7 @@ import (
 	"hyacinth-backend/internal/model"
 	"hyacinth-backend/internal/service"
 	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -21,9 +22,13 @@ func setupUserService(t *testing.T) (service.UserService, *mock_repository.MockU
 	ctrl := gomock.NewController(t)
 
 	mockUserRepo := mock_repository.NewMockUserRepository(ctrl)
+	mockVnetService := mock_service.NewMockVnetService(ctrl)
 	mockTm := mock_repository.NewMockTransaction(ctrl)
 	srv := service.NewService(mockTm, logger, sf, j)
-	userService := service.NewUserService(srv, mockUserRepo)
+	userService := service.NewUserService(srv, mockUserRepo, mockVnetService, newTestPlanService(ctrl, testPlans()))
+
+	// 购买套餐后会同步虚拟网络的流量停用状态
+	mockVnetService.EXPECT().SyncTrafficSuspension(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	return userService, mockUserRepo, mockTm
 }
@@ -473,7 +478,7 @@ func TestUserService_PurchasePackage_Success_Upgrade(t *testing.T) {
 	}
 
 	// Mock期望：获取用户信息
-	mockUserRepo.EXPECT().GetByID(ctx, userId).Return(existingUser, nil)
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, userId).Return(existingUser, nil)
 
 	// Mock期望：更新用户信息
 	mockUserRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, user *model.User) error {
@@ -482,7 +487,11 @@ func TestUserService_PurchasePackage_Success_Upgrade(t *testing.T) {
 		// 验证特权到期时间已设置（应该是3个月后）
 		assert.NotNil(t, user.PrivilegeExpiry)
 		// 验证剩余流量已设置为青铜套餐流量
-		assert.Equal(t, model.BronzeMonthlyTraffic, user.RemainingTraffic)
+		assert.Equal(t, bronzeTraffic, user.RemainingTraffic)
+		// 验证从现在起开始计费周期，一个月后重置流量
+		if assert.NotNil(t, user.NextTrafficReset) {
+			assert.True(t, user.NextTrafficReset.Equal(user.BillingAnchor.AddDate(0, 1, 0)))
+		}
 		return nil
 	})
 
@@ -510,7 +519,7 @@ func TestUserService_PurchasePackage_Success_SameLevel_ExpiredPrivilege(t *testi
 	}
 
 	// Mock期望：获取用户信息
-	mockUserRepo.EXPECT().GetByID(ctx, userId).Return(existingUser, nil)
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, userId).Return(existingUser, nil)
 
 	// Mock期望：更新用户信息
 	mockUserRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, user *model.User) error {
@@ -520,7 +529,7 @@ func TestUserService_PurchasePackage_Success_SameLevel_ExpiredPrivilege(t *testi
 		assert.NotNil(t, user.PrivilegeExpiry)
 		assert.True(t, user.PrivilegeExpiry.After(time.Now()))
 		// 验证剩余流量重新设置为白银套餐流量
-		assert.Equal(t, model.SilverMonthlyTraffic, user.RemainingTraffic)
+		assert.Equal(t, silverTraffic, user.RemainingTraffic)
 		return nil
 	})
 
@@ -548,7 +557,7 @@ func TestUserService_PurchasePackage_Success_SameLevel_ValidPrivilege(t *testing
 	}
 
 	// Mock期望：获取用户信息
-	mockUserRepo.EXPECT().GetByID(ctx, userId).Return(existingUser, nil)
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, userId).Return(existingUser, nil)
 
 	// Mock期望：更新用户信息
 	mockUserRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, user *model.User) error {
@@ -558,6 +567,8 @@ func TestUserService_PurchasePackage_Success_SameLevel_ValidPrivilege(t *testing
 		assert.NotNil(t, user.PrivilegeExpiry)
 		expectedExpiry := validTime.AddDate(0, 2, 0)
 		assert.True(t, user.PrivilegeExpiry.Equal(expectedExpiry) || user.PrivilegeExpiry.After(expectedExpiry.Add(-time.Minute)))
+		// 续费不改变计费周期
+		assert.Nil(t, user.BillingAnchor)
 		return nil
 	})
 
@@ -577,7 +588,7 @@ func TestUserService_PurchasePackage_UserNotFound(t *testing.T) {
 	}
 
 	// Mock期望：用户不存在
-	mockUserRepo.EXPECT().GetByID(ctx, userId).Return(nil, gorm.ErrRecordNotFound)
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, userId).Return(nil, gorm.ErrRecordNotFound)
 
 	err := userService.PurchasePackage(ctx, userId, req)
 
@@ -601,7 +612,7 @@ func TestUserService_PurchasePackage_InvalidPackageType(t *testing.T) {
 	}
 
 	// Mock期望：获取用户信息
-	mockUserRepo.EXPECT().GetByID(ctx, userId).Return(existingUser, nil)
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, userId).Return(existingUser, nil)
 
 	err := userService.PurchasePackage(ctx, userId, req)
 
@@ -625,7 +636,7 @@ func TestUserService_PurchasePackage_InvalidDuration_Zero(t *testing.T) {
 	}
 
 	// Mock期望：获取用户信息
-	mockUserRepo.EXPECT().GetByID(ctx, userId).Return(existingUser, nil)
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, userId).Return(existingUser, nil)
 
 	err := userService.PurchasePackage(ctx, userId, req)
 
@@ -649,7 +660,7 @@ func TestUserService_PurchasePackage_InvalidDuration_Negative(t *testing.T) {
 	}
 
 	// Mock期望：获取用户信息
-	mockUserRepo.EXPECT().GetByID(ctx, userId).Return(existingUser, nil)
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, userId).Return(existingUser, nil)
 
 	err := userService.PurchasePackage(ctx, userId, req)
 
@@ -673,7 +684,7 @@ func TestUserService_PurchasePackage_UpdateError(t *testing.T) {
 	}
 
 	// Mock期望：获取用户信息
-	mockUserRepo.EXPECT().GetByID(ctx, userId).Return(existingUser, nil)
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, userId).Return(existingUser, nil)
 
 	// Mock期望：更新用户信息失败
 	updateError := errors.New("database update error")
@@ -696,17 +707,17 @@ func TestUserService_PurchasePackage_DifferentPackageTypes(t *testing.T) {
 		{
 			name:         "Bronze Package",
 			packageType:  2,
-			expectedFlow: model.BronzeMonthlyTraffic,
+			expectedFlow: bronzeTraffic,
 		},
 		{
 			name:         "Silver Package",
 			packageType:  3,
-			expectedFlow: model.SilverMonthlyTraffic,
+			expectedFlow: silverTraffic,
 		},
 		{
 			name:         "Gold Package",
 			packageType:  4,
-			expectedFlow: model.GoldMonthlyTraffic,
+			expectedFlow: goldTraffic,
 		},
 	}
 
@@ -725,7 +736,7 @@ func TestUserService_PurchasePackage_DifferentPackageTypes(t *testing.T) {
 			}
 
 			// Mock期望：获取用户信息
-			mockUserRepo.EXPECT().GetByID(ctx, userId).Return(existingUser, nil)
+			mockUserRepo.EXPECT().GetByIDForUpdate(ctx, userId).Return(existingUser, nil)
 
 			// Mock期望：更新用户信息
 			mockUserRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, user *model.User) error {
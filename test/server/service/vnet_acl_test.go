@@ -0,0 +1,142 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type vnetAclFixture struct {
+	vnetAclService       service.VnetAclService
+	mockVnetRepo         *mock_repository.MockVnetRepository
+	mockUserRepo         *mock_repository.MockUserRepository
+	mockVnetAclRepo      *mock_repository.MockVnetAclRepository
+	mockVnetEventService *mock_service.MockVnetEventService
+}
+
+func setupVnetAclService(t *testing.T) *vnetAclFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &vnetAclFixture{
+		mockVnetRepo:         mock_repository.NewMockVnetRepository(ctrl),
+		mockUserRepo:         mock_repository.NewMockUserRepository(ctrl),
+		mockVnetAclRepo:      mock_repository.NewMockVnetAclRepository(ctrl),
+		mockVnetEventService: mock_service.NewMockVnetEventService(ctrl),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	f.vnetAclService = service.NewVnetAclService(srv, f.mockVnetRepo, f.mockUserRepo, f.mockVnetAclRepo, f.mockVnetEventService, mock_repository.NewMockOrganizationRepository(ctrl), newTestPlanService(ctrl, testPlans()))
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return f
+}
+
+func TestVnetAclService_CreateRule(t *testing.T) {
+	f := setupVnetAclService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "user_1", Revision: 3, AclRevision: 1}
+	existing := []model.VnetAclRule{
+		{RuleId: "acl_1", VnetId: "vnet_1", Priority: 10, Action: "allow", Source: "client:admin", Destination: "*", Protocol: "any"},
+	}
+	tags := []model.VnetMemberTag{
+		{VnetId: "vnet_1", ClientId: "client_3", Tag: "guest"},
+		{VnetId: "vnet_1", ClientId: "client_2", Tag: "guest"},
+	}
+
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1}, nil)
+	f.mockVnetAclRepo.EXPECT().CountRules(ctx, "vnet_1").Return(int64(1), nil)
+	f.mockVnetAclRepo.EXPECT().GetRules(ctx, "vnet_1").Return(&existing, nil)
+	f.mockVnetAclRepo.EXPECT().CreateRule(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, rule *model.VnetAclRule) error {
+		existing = append(existing, *rule)
+		return nil
+	})
+	f.mockVnetAclRepo.EXPECT().GetRules(ctx, "vnet_1").DoAndReturn(func(ctx context.Context, vnetId string) (*[]model.VnetAclRule, error) {
+		return &existing, nil
+	})
+	f.mockVnetAclRepo.EXPECT().GetMemberTags(ctx, "vnet_1").Return(&tags, nil)
+	f.mockVnetRepo.EXPECT().UpdateVnet(ctx, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Record(ctx, model.VnetEventUpdate, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	rule, err := f.vnetAclService.CreateRule(ctx, "vnet_1", &v1.AclRuleRequest{
+		Action: "deny", Source: "tag:Guest", Destination: "10.0.0.9/24", Protocol: "tcp", Ports: "8000-9000",
+	})
+
+	assert.NoError(t, err)
+	// 选择器转换为规范形式，未指定优先级时排在最后
+	assert.Equal(t, 20, rule.Priority)
+	assert.Equal(t, "tag:guest", rule.Source)
+	assert.Equal(t, "10.0.0.0/24", rule.Destination)
+	assert.Equal(t, "8000-9000", rule.Ports)
+
+	assert.True(t, vnet.NeedUpdate)
+	assert.Equal(t, int64(4), vnet.Revision)
+	assert.Equal(t, int64(2), vnet.AclRevision)
+	var acl v1.NodeVnetAcl
+	assert.NoError(t, json.Unmarshal([]byte(vnet.Acl), &acl))
+	assert.Equal(t, v1.NodeVnetAcl{Revision: 2, Rules: []v1.NodeAclRule{
+		{Action: "allow", Source: v1.NodeAclTarget{ClientIds: []string{"admin"}}, Destination: v1.NodeAclTarget{Any: true}, Protocol: "any"},
+		{Action: "deny", Source: v1.NodeAclTarget{ClientIds: []string{"client_2", "client_3"}}, Destination: v1.NodeAclTarget{Cidrs: []string{"10.0.0.0/24"}}, Protocol: "tcp", PortFrom: 8000, PortTo: 9000},
+	}}, acl)
+}
+
+func TestVnetAclService_CreateRule_Limit(t *testing.T) {
+	f := setupVnetAclService(t)
+
+	ctx := context.Background()
+
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", UserId: "user_1"}, nil)
+	f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1}, nil)
+	f.mockVnetAclRepo.EXPECT().CountRules(ctx, "vnet_1").Return(int64(10), nil)
+
+	_, err := f.vnetAclService.CreateRule(ctx, "vnet_1", &v1.AclRuleRequest{Action: "allow", Source: "*", Destination: "*"})
+
+	assert.ErrorIs(t, err, v1.ErrAclRuleLimitExceeded)
+}
+
+func TestVnetAclService_CreateRule_Invalid(t *testing.T) {
+	f := setupVnetAclService(t)
+
+	ctx := context.Background()
+
+	for name, req := range map[string]*v1.AclRuleRequest{
+		"bad cidr":          {Action: "deny", Source: "10.0.0.0/33", Destination: "*"},
+		"bad tag":           {Action: "deny", Source: "tag:", Destination: "*"},
+		"ports without l4":  {Action: "deny", Source: "*", Destination: "*", Protocol: "icmp", Ports: "22"},
+		"reversed ports":    {Action: "deny", Source: "*", Destination: "*", Protocol: "tcp", Ports: "90-80"},
+		"port out of range": {Action: "deny", Source: "*", Destination: "*", Protocol: "udp", Ports: "70000"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := f.vnetAclService.CreateRule(ctx, "vnet_1", req)
+			assert.ErrorIs(t, err, v1.ErrInvalidAclRule)
+		})
+	}
+}
+
+func TestCompileAcl(t *testing.T) {
+	rules := []model.VnetAclRule{
+		{Action: "deny", Source: "tag:nobody", Destination: "*", Protocol: "any"},
+		{Action: "deny", Source: "*", Destination: "10.0.0.1/32", Protocol: "icmp"},
+	}
+
+	acl := service.CompileAcl(rules, nil, 5)
+
+	// 标签没有成员时规则不会生效，直接省略
+	assert.Equal(t, int64(5), acl.Revision)
+	assert.Len(t, acl.Rules, 1)
+	assert.Equal(t, []string{"10.0.0.1/32"}, acl.Rules[0].Destination.Cidrs)
+}
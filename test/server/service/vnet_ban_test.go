@@ -0,0 +1,156 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type vnetBanFixture struct {
+	vnetBanService       service.VnetBanService
+	mockVnetRepo         *mock_repository.MockVnetRepository
+	mockVnetClientRepo   *mock_repository.MockVnetClientRepository
+	mockVnetMemberRepo   *mock_repository.MockVnetMemberRepository
+	mockVnetBanRepo      *mock_repository.MockVnetBanRepository
+	mockVnetEventService *mock_service.MockVnetEventService
+}
+
+func setupVnetBanService(t *testing.T) *vnetBanFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &vnetBanFixture{
+		mockVnetRepo:         mock_repository.NewMockVnetRepository(ctrl),
+		mockVnetClientRepo:   mock_repository.NewMockVnetClientRepository(ctrl),
+		mockVnetMemberRepo:   mock_repository.NewMockVnetMemberRepository(ctrl),
+		mockVnetBanRepo:      mock_repository.NewMockVnetBanRepository(ctrl),
+		mockVnetEventService: mock_service.NewMockVnetEventService(ctrl),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	f.vnetBanService = service.NewVnetBanService(srv, f.mockVnetRepo, f.mockVnetClientRepo, f.mockVnetMemberRepo, f.mockVnetBanRepo, f.mockVnetEventService)
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return f
+}
+
+func TestVnetBanService_CreateBan(t *testing.T) {
+	f := setupVnetBanService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", NodeId: "relay-1"}
+	clients := []model.VnetClient{
+		{VnetId: "vnet_1", ClientId: "client_1", PublicEndpoint: "203.0.113.7:4000"},
+		{VnetId: "vnet_1", ClientId: "client_2", PublicEndpoint: "198.51.100.2:4000"},
+	}
+
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	f.mockVnetBanRepo.EXPECT().CreateBan(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, ban *model.VnetBan) error {
+		assert.Equal(t, "203.0.113.0/24", ban.Ip)
+		assert.NotNil(t, ban.ExpiresAt)
+		return nil
+	})
+	f.mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&clients, nil)
+	// 只断开公网地址命中的客户端
+	f.mockVnetClientRepo.EXPECT().DeleteVnetClient(ctx, "vnet_1", "client_1").Return(true, nil)
+	f.mockVnetEventService.EXPECT().RecordKick(ctx, vnet, "client_1").Return(nil)
+	f.mockVnetClientRepo.EXPECT().SyncClientsOnline(ctx, []string{"vnet_1"}).Return(nil)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	ban, err := f.vnetBanService.CreateBan(ctx, "vnet_1", &v1.CreateVnetBanRequest{Ip: "203.0.113.9/24", Duration: 3600})
+
+	assert.NoError(t, err)
+	assert.Contains(t, ban.BanId, "ban_")
+
+	// 设备标识与地址只能二选一
+	_, err = f.vnetBanService.CreateBan(ctx, "vnet_1", &v1.CreateVnetBanRequest{ClientId: "client_1", Ip: "203.0.113.7"})
+	assert.Equal(t, v1.ErrBadRequest, err)
+	_, err = f.vnetBanService.CreateBan(ctx, "vnet_1", &v1.CreateVnetBanRequest{Ip: "not-an-ip"})
+	assert.Equal(t, v1.ErrBadRequest, err)
+}
+
+func TestVnetBanService_CreateBan_Device(t *testing.T) {
+	f := setupVnetBanService(t)
+
+	ctx := context.Background()
+
+	// 封禁离线设备时删除其审批记录，不产生断开事件
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1"}, nil)
+	f.mockVnetBanRepo.EXPECT().CreateBan(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, ban *model.VnetBan) error {
+		assert.Equal(t, "client_1", ban.ClientId)
+		assert.Nil(t, ban.ExpiresAt)
+		return nil
+	})
+	f.mockVnetMemberRepo.EXPECT().GetMembers(ctx, "vnet_1", "").Return(&[]model.VnetMember{}, nil)
+	f.mockVnetMemberRepo.EXPECT().DeleteMember(ctx, "vnet_1", "client_1").Return(true, nil)
+	f.mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+
+	_, err := f.vnetBanService.CreateBan(ctx, "vnet_1", &v1.CreateVnetBanRequest{ClientId: "client_1"})
+
+	assert.NoError(t, err)
+}
+
+func TestVnetBanService_CreateBan_DeviceKey(t *testing.T) {
+	f := setupVnetBanService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", NodeId: "relay-1"}
+	keyHash := strings.Repeat("a", 64)
+
+	// 封禁记录设备的设备密钥，以其他标识接入的同一设备也被断开并删除审批记录
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	f.mockVnetMemberRepo.EXPECT().GetMembers(ctx, "vnet_1", "").Return(&[]model.VnetMember{
+		{VnetId: "vnet_1", ClientId: "client_1", DeviceKeyHash: keyHash},
+		{VnetId: "vnet_1", ClientId: "client_2", DeviceKeyHash: keyHash},
+		{VnetId: "vnet_1", ClientId: "client_3", DeviceKeyHash: strings.Repeat("b", 64)},
+	}, nil)
+	f.mockVnetBanRepo.EXPECT().CreateBan(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, ban *model.VnetBan) error {
+		assert.Equal(t, "client_1", ban.ClientId)
+		assert.Equal(t, keyHash, ban.DeviceKeyHash)
+		return nil
+	})
+	f.mockVnetMemberRepo.EXPECT().DeleteMember(ctx, "vnet_1", "client_1").Return(true, nil)
+	f.mockVnetMemberRepo.EXPECT().DeleteMember(ctx, "vnet_1", "client_2").Return(true, nil)
+	f.mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{
+		{VnetId: "vnet_1", ClientId: "client_2"},
+		{VnetId: "vnet_1", ClientId: "client_3"},
+	}, nil)
+	f.mockVnetClientRepo.EXPECT().DeleteVnetClient(ctx, "vnet_1", "client_2").Return(true, nil)
+	f.mockVnetEventService.EXPECT().RecordKick(ctx, vnet, "client_2").Return(nil)
+	f.mockVnetClientRepo.EXPECT().SyncClientsOnline(ctx, []string{"vnet_1"}).Return(nil)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	_, err := f.vnetBanService.CreateBan(ctx, "vnet_1", &v1.CreateVnetBanRequest{ClientId: "client_1"})
+
+	assert.NoError(t, err)
+}
+
+func TestVnetBanService_KickClient(t *testing.T) {
+	f := setupVnetBanService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", NodeId: "relay-1"}
+	clients := []model.VnetClient{{VnetId: "vnet_1", ClientId: "client_1"}}
+
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil).Times(2)
+	f.mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&clients, nil).Times(2)
+	f.mockVnetClientRepo.EXPECT().DeleteVnetClient(ctx, "vnet_1", "client_1").Return(true, nil)
+	f.mockVnetEventService.EXPECT().RecordKick(ctx, vnet, "client_1").Return(nil)
+	f.mockVnetClientRepo.EXPECT().SyncClientsOnline(ctx, []string{"vnet_1"}).Return(nil)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	assert.NoError(t, f.vnetBanService.KickClient(ctx, "vnet_1", "client_1"))
+	// 不在线的客户端无法断开
+	assert.Equal(t, v1.ErrNotFound, f.vnetBanService.KickClient(ctx, "vnet_1", "client_2"))
+}
@@ -0,0 +1,639 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	"hyacinth-backend/pkg/jwt"
+	"hyacinth-backend/pkg/vnetpass"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// testSigningKey relay-1 的会话凭证签名密钥，由控制面密钥与节点ID派生
+var testSigningKey = []byte(jwt.NodeSigningKey([]byte("signing-secret"), "relay-1"))
+
+func setupVnetClientService(t *testing.T) (service.VnetClientService, *mock_repository.MockVnetRepository, *mock_repository.MockVnetClientRepository) {
+	vnetClientService, mockVnetRepo, _, mockVnetClientRepo, _ := setupVnetClientServiceWithUser(t)
+	return vnetClientService, mockVnetRepo, mockVnetClientRepo
+}
+
+func setupVnetClientServiceWithUser(t *testing.T) (service.VnetClientService, *mock_repository.MockVnetRepository, *mock_repository.MockUserRepository, *mock_repository.MockVnetClientRepository, *mock_service.MockIpamService) {
+	vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, mockIpamService, mockVnetMemberRepo, mockVnetBanRepo, _ := setupVnetClientServiceWithMembers(t)
+	// 设备未被封禁且没有审批记录
+	mockVnetBanRepo.EXPECT().GetActiveBans(gomock.Any(), gomock.Any(), gomock.Any()).Return(&[]model.VnetBan{}, nil).AnyTimes()
+	mockVnetMemberRepo.EXPECT().GetMember(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	return vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, mockIpamService
+}
+
+func setupVnetClientServiceWithMembers(t *testing.T) (service.VnetClientService, *mock_repository.MockVnetRepository, *mock_repository.MockUserRepository, *mock_repository.MockVnetClientRepository, *mock_service.MockIpamService, *mock_repository.MockVnetMemberRepository, *mock_repository.MockVnetBanRepository, *mock_repository.MockVnetInviteRepository) {
+	f := setupVnetClientFixture(t)
+	return f.vnetClientService, f.mockVnetRepo, f.mockUserRepo, f.mockVnetClientRepo, f.mockIpamService, f.mockVnetMemberRepo, f.mockVnetBanRepo, f.mockVnetInviteRepo
+}
+
+type vnetClientFixture struct {
+	vnetClientService  service.VnetClientService
+	mockVnetRepo       *mock_repository.MockVnetRepository
+	mockUserRepo       *mock_repository.MockUserRepository
+	mockVnetClientRepo *mock_repository.MockVnetClientRepository
+	mockIpamService    *mock_service.MockIpamService
+	mockIpLeaseRepo    *mock_repository.MockIpLeaseRepository
+	mockVnetMemberRepo *mock_repository.MockVnetMemberRepository
+	mockVnetBanRepo    *mock_repository.MockVnetBanRepository
+	mockVnetInviteRepo *mock_repository.MockVnetInviteRepository
+}
+
+func setupVnetClientFixture(t *testing.T) *vnetClientFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &vnetClientFixture{
+		mockVnetRepo:       mock_repository.NewMockVnetRepository(ctrl),
+		mockUserRepo:       mock_repository.NewMockUserRepository(ctrl),
+		mockVnetClientRepo: mock_repository.NewMockVnetClientRepository(ctrl),
+		mockIpamService:    mock_service.NewMockIpamService(ctrl),
+		mockIpLeaseRepo:    mock_repository.NewMockIpLeaseRepository(ctrl),
+		mockVnetMemberRepo: mock_repository.NewMockVnetMemberRepository(ctrl),
+		mockVnetBanRepo:    mock_repository.NewMockVnetBanRepository(ctrl),
+		mockVnetInviteRepo: mock_repository.NewMockVnetInviteRepository(ctrl),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+
+	conf := viper.New()
+	conf.Set("node.keys", map[string]string{"relay-1": "secret-1"})
+	conf.Set("node.signing_secret", "signing-secret")
+	// 覆盖旧版节点转发密码明文的准入
+	conf.Set("vnet.legacy_password", true)
+	f.vnetClientService = service.NewVnetClientService(srv, conf, f.mockVnetRepo, f.mockUserRepo, f.mockVnetClientRepo, f.mockIpamService, f.mockIpLeaseRepo, mock_repository.NewMockNodeRepository(ctrl), f.mockVnetMemberRepo, f.mockVnetBanRepo, f.mockVnetInviteRepo, mock_repository.NewMockOrganizationRepository(ctrl), newTestPlanService(ctrl, testPlans()))
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return f
+}
+
+func TestVnetClientService_ClientJoin(t *testing.T) {
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "user_1", Enabled: true, NodeId: "relay-1", IpRange: "10.0.0.0/24", ClientsLimit: 5, RequireApproval: true}
+	sessionToken, err := jwt.GenSessionToken(testSigningKey, "relay-1", "vnet_1", "client_1", "10.0.0.2", time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	req := &v1.ClientJoinRequest{VnetId: "vnet_1", ClientId: "client_1", SessionToken: sessionToken, PublicEndpoint: "203.0.113.5:51820"}
+
+	t.Run("valid session", func(t *testing.T) {
+		f := setupVnetClientFixture(t)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockIpLeaseRepo.EXPECT().GetLeaseByClientId(ctx, "vnet_1", "client_1").Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.2"}, nil)
+		f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1, RemainingTraffic: 1024}, nil)
+		f.mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		f.mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+		f.mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_1").Return(&model.VnetMember{VnetId: "vnet_1", ClientId: "client_1", Status: model.VnetMemberApproved}, nil)
+		f.mockVnetClientRepo.EXPECT().UpsertVnetClient(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, client *model.VnetClient) error {
+			assert.Equal(t, "client_1", client.ClientId)
+			assert.Equal(t, "relay-1", client.NodeId)
+			assert.Equal(t, "10.0.0.2", client.VirtualIp)
+			assert.False(t, client.LastSeen.IsZero())
+			return nil
+		})
+		f.mockVnetClientRepo.EXPECT().SyncClientsOnline(ctx, []string{"vnet_1"}).Return(nil)
+
+		resp, err := f.vnetClientService.ClientJoin(ctx, "relay-1", req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.0.2", resp.VirtualIp)
+		assert.NotEmpty(t, resp.ConnectedAt)
+	})
+
+	t.Run("invalid session token", func(t *testing.T) {
+		// 没有准入凭证、凭证签发给其他设备或其他节点时不能写入会话
+		f := setupVnetClientFixture(t)
+
+		_, err := f.vnetClientService.ClientJoin(ctx, "relay-1", &v1.ClientJoinRequest{VnetId: "vnet_1", ClientId: "client_1"})
+		assert.Equal(t, v1.ErrUnauthorized, err)
+
+		_, err = f.vnetClientService.ClientJoin(ctx, "relay-1", &v1.ClientJoinRequest{VnetId: "vnet_1", ClientId: "client_2", SessionToken: sessionToken})
+		assert.Equal(t, v1.ErrUnauthorized, err)
+
+		otherNode, err := jwt.GenSessionToken(testSigningKey, "relay-2", "vnet_1", "client_1", "10.0.0.2", time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+		_, err = f.vnetClientService.ClientJoin(ctx, "relay-1", &v1.ClientJoinRequest{VnetId: "vnet_1", ClientId: "client_1", SessionToken: otherNode})
+		assert.Equal(t, v1.ErrUnauthorized, err)
+	})
+
+	t.Run("lease reassigned", func(t *testing.T) {
+		// 租约过期后地址分配给了其他设备，凭证中的地址不再有效
+		f := setupVnetClientFixture(t)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockIpLeaseRepo.EXPECT().GetLeaseByClientId(ctx, "vnet_1", "client_1").Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.3"}, nil)
+
+		_, err := f.vnetClientService.ClientJoin(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrUnauthorized, err)
+	})
+
+	t.Run("approval revoked", func(t *testing.T) {
+		f := setupVnetClientFixture(t)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockIpLeaseRepo.EXPECT().GetLeaseByClientId(ctx, "vnet_1", "client_1").Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.2"}, nil)
+		f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1, RemainingTraffic: 1024}, nil)
+		f.mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		f.mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+		f.mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_1").Return(&model.VnetMember{VnetId: "vnet_1", ClientId: "client_1", Status: model.VnetMemberPending}, nil)
+
+		_, err := f.vnetClientService.ClientJoin(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrMemberPendingApproval, err)
+	})
+
+	t.Run("clients full", func(t *testing.T) {
+		// 会话过期期间名额被其他设备占用
+		f := setupVnetClientFixture(t)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockIpLeaseRepo.EXPECT().GetLeaseByClientId(ctx, "vnet_1", "client_1").Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.2"}, nil)
+		f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1, RemainingTraffic: 1024}, nil)
+		f.mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		f.mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{{ClientId: "client_2"}, {ClientId: "client_3"}, {ClientId: "client_4"}}, nil)
+		f.mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_1").Return(&model.VnetMember{VnetId: "vnet_1", ClientId: "client_1", Status: model.VnetMemberApproved}, nil)
+
+		_, err := f.vnetClientService.ClientJoin(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrVnetClientsFull, err)
+	})
+
+	t.Run("vnet moved to another node", func(t *testing.T) {
+		f := setupVnetClientFixture(t)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", Enabled: true, NodeId: "relay-2"}, nil)
+
+		_, err := f.vnetClientService.ClientJoin(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrForbidden, err)
+	})
+}
+
+func TestVnetClientService_ClientLeave(t *testing.T) {
+	vnetClientService, mockVnetRepo, mockVnetClientRepo := setupVnetClientService(t)
+
+	ctx := context.Background()
+	req := &v1.ClientLeaveRequest{VnetId: "vnet_1", ClientId: "client_1"}
+
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", NodeId: "relay-1"}, nil).Times(2)
+	mockVnetClientRepo.EXPECT().DeleteVnetClient(ctx, "vnet_1", "client_1").Return(true, nil)
+	mockVnetClientRepo.EXPECT().SyncClientsOnline(ctx, []string{"vnet_1"}).Return(nil)
+
+	err := vnetClientService.ClientLeave(ctx, "relay-1", req)
+	assert.NoError(t, err)
+
+	// 重复离开时会话已不存在，无需重新计算在线数量
+	mockVnetClientRepo.EXPECT().DeleteVnetClient(ctx, "vnet_1", "client_1").Return(false, nil)
+
+	err = vnetClientService.ClientLeave(ctx, "relay-1", req)
+	assert.NoError(t, err)
+}
+
+func TestVnetClientService_ClientHeartbeat(t *testing.T) {
+	vnetClientService, _, mockVnetClientRepo := setupVnetClientService(t)
+
+	ctx := context.Background()
+	req := &v1.ClientHeartbeatRequest{Clients: []v1.ClientSessionRef{
+		{VnetId: "vnet_1", ClientId: "client_1"},
+		{VnetId: "vnet_1", ClientId: "client_expired"},
+	}}
+
+	mockVnetClientRepo.EXPECT().GetVnetClientsByNodeId(ctx, "relay-1").Return(&[]model.VnetClient{
+		{Model: gorm.Model{ID: 7}, VnetId: "vnet_1", ClientId: "client_1"},
+		{Model: gorm.Model{ID: 8}, VnetId: "vnet_1", ClientId: "client_2"},
+	}, nil)
+	mockVnetClientRepo.EXPECT().TouchVnetClients(ctx, []uint{7}, gomock.Any()).DoAndReturn(func(ctx context.Context, ids []uint, lastSeen time.Time) error {
+		assert.WithinDuration(t, time.Now(), lastSeen, time.Second)
+		return nil
+	})
+
+	resp, err := vnetClientService.ClientHeartbeat(ctx, "relay-1", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, resp.Refreshed)
+	assert.Equal(t, []v1.ClientSessionRef{{VnetId: "vnet_1", ClientId: "client_expired"}}, resp.Unknown)
+}
+
+func TestVnetClientService_AdmitClient(t *testing.T) {
+	vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, mockIpamService := setupVnetClientServiceWithUser(t)
+
+	ctx := context.Background()
+	passwordHash := hashVnetPassword(t, "pass_1")
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "user_1", Enabled: true, NodeId: "relay-1", Token: "token_1", PasswordHash: passwordHash, IpRange: "10.0.0.0/29", ClientsLimit: 5}
+	leaseExpiry := time.Now().Add(12 * time.Hour)
+
+	mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet, nil).Times(2)
+
+	// 客户端按挑战中的参数派生密钥并计算应答，密码明文不发送给节点
+	challenge, err := vnetClientService.ClientChallenge(ctx, "relay-1", &v1.ClientChallengeRequest{Token: "token_1", ClientId: "client_3"})
+	assert.NoError(t, err)
+	assert.True(t, challenge.PasswordRequired)
+	salt, _ := base64.StdEncoding.DecodeString(challenge.Salt)
+	clientKey := vnetpass.ClientKey("pass_1", salt, challenge.Time, challenge.Memory, challenge.Threads)
+	proof := vnetpass.ClientProof(clientKey, vnetpass.AuthMessage("token_1", "client_3", challenge.Challenge))
+	req := &v1.AdmitClientRequest{Token: "token_1", Challenge: challenge.Challenge, Proof: base64.StdEncoding.EncodeToString(proof), ClientId: "client_3", MacAddress: "02:00:00:00:00:03"}
+
+	// 应答通过后记录挑战随机数，直到挑战过期
+	mockVnetClientRepo.EXPECT().UseChallenge(ctx, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+		assert.NotEmpty(t, nonce)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, 5*time.Second)
+		return true, nil
+	})
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1, RemainingTraffic: 1024}, nil)
+	mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{
+		{ClientId: "client_1", VirtualIp: "10.0.0.1"},
+	}, nil)
+	mockIpamService.EXPECT().AcquireLease(ctx, vnet, "client_3", "02:00:00:00:00:03", "").
+		Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_3", Address: "10.0.0.2", ExpiresAt: &leaseExpiry}, nil)
+	mockVnetClientRepo.EXPECT().UpsertVnetClient(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, client *model.VnetClient) error {
+		assert.Equal(t, "client_3", client.ClientId)
+		assert.Equal(t, "10.0.0.2", client.VirtualIp)
+		assert.Equal(t, "relay-1", client.NodeId)
+		return nil
+	})
+	mockVnetClientRepo.EXPECT().SyncClientsOnline(ctx, []string{"vnet_1"}).Return(nil)
+
+	resp, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", resp.VirtualIp)
+	assert.Equal(t, leaseExpiry.Unix(), resp.LeaseExpiresAt)
+	// 会话凭证使用节点密钥签名，节点可在本地校验
+	claims, err := jwt.ParseSessionToken(testSigningKey, resp.SessionToken, "relay-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "client_3", claims.ClientId)
+	assert.Equal(t, "10.0.0.2", claims.VirtualIp)
+	_, err = jwt.ParseSessionToken(testSigningKey, resp.SessionToken, "relay-2")
+	assert.Error(t, err)
+}
+
+func TestVnetClientService_AdmitClient_Rejoin(t *testing.T) {
+	vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, mockIpamService := setupVnetClientServiceWithUser(t)
+
+	ctx := context.Background()
+	// 普通用户每个虚拟网络最多 3 个客户端，已满时同一设备重新接入仍然允许并续期原租约
+	// 旧版节点直接转发密码明文
+	passwordHash := hashVnetPassword(t, "pass_1")
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "user_1", Enabled: true, NodeId: "relay-1", Token: "token_1", PasswordHash: passwordHash, IpRange: "10.0.0.0/24", ClientsLimit: 10}
+	req := &v1.AdmitClientRequest{Token: "token_1", Password: "pass_1", ClientId: "client_2"}
+
+	mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet, nil)
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1, RemainingTraffic: 1024}, nil)
+	mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{
+		{ClientId: "client_1", VirtualIp: "10.0.0.1"},
+		{ClientId: "client_2", VirtualIp: "10.0.0.7"},
+		{ClientId: "client_3", VirtualIp: "10.0.0.3"},
+	}, nil)
+	mockIpamService.EXPECT().AcquireLease(ctx, vnet, "client_2", "", "").
+		Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_2", Address: "10.0.0.7", Static: true}, nil)
+	mockVnetClientRepo.EXPECT().UpsertVnetClient(ctx, gomock.Any()).Return(nil)
+	mockVnetClientRepo.EXPECT().SyncClientsOnline(ctx, []string{"vnet_1"}).Return(nil)
+
+	resp, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.7", resp.VirtualIp)
+	assert.Zero(t, resp.LeaseExpiresAt)
+}
+
+func TestVnetClientService_AdmitClient_Rejected(t *testing.T) {
+	ctx := context.Background()
+	passwordHash := hashVnetPassword(t, "pass_1")
+	vnet := func() *model.Vnet {
+		return &model.Vnet{VnetId: "vnet_1", UserId: "user_1", Enabled: true, NodeId: "relay-1", Token: "token_1", PasswordHash: passwordHash, IpRange: "10.0.0.0/30", ClientsLimit: 5}
+	}
+	req := &v1.AdmitClientRequest{Token: "token_1", Password: "pass_1", ClientId: "client_9"}
+
+	t.Run("wrong password", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, _, _, _ := setupVnetClientServiceWithUser(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", &v1.AdmitClientRequest{Token: "token_1", Password: "wrong", ClientId: "client_9"})
+		assert.Equal(t, v1.ErrUnauthorized, err)
+	})
+
+	t.Run("proof for another client", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, _, _, _ := setupVnetClientServiceWithUser(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil).Times(2)
+
+		// 挑战绑定设备标识，不能用于其他设备
+		challenge, err := vnetClientService.ClientChallenge(ctx, "relay-1", &v1.ClientChallengeRequest{Token: "token_1", ClientId: "client_1"})
+		assert.NoError(t, err)
+		salt, _ := base64.StdEncoding.DecodeString(challenge.Salt)
+		clientKey := vnetpass.ClientKey("pass_1", salt, challenge.Time, challenge.Memory, challenge.Threads)
+		proof := vnetpass.ClientProof(clientKey, vnetpass.AuthMessage("token_1", "client_9", challenge.Challenge))
+
+		_, err = vnetClientService.AdmitClient(ctx, "relay-1", &v1.AdmitClientRequest{Token: "token_1", Challenge: challenge.Challenge, Proof: base64.StdEncoding.EncodeToString(proof), ClientId: "client_9"})
+		assert.Equal(t, v1.ErrUnauthorized, err)
+	})
+
+	t.Run("replayed proof", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, _ := setupVnetClientServiceWithUser(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil).Times(2)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet(), nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1, RemainingTraffic: 1024}, nil)
+		mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+
+		// 挑战已被使用过，截获的应答在挑战过期前也不能再次接入
+		challenge, err := vnetClientService.ClientChallenge(ctx, "relay-1", &v1.ClientChallengeRequest{Token: "token_1", ClientId: "client_9"})
+		assert.NoError(t, err)
+		salt, _ := base64.StdEncoding.DecodeString(challenge.Salt)
+		clientKey := vnetpass.ClientKey("pass_1", salt, challenge.Time, challenge.Memory, challenge.Threads)
+		proof := vnetpass.ClientProof(clientKey, vnetpass.AuthMessage("token_1", "client_9", challenge.Challenge))
+		mockVnetClientRepo.EXPECT().UseChallenge(ctx, gomock.Any(), gomock.Any()).Return(false, nil)
+
+		_, err = vnetClientService.AdmitClient(ctx, "relay-1", &v1.AdmitClientRequest{Token: "token_1", Challenge: challenge.Challenge, Proof: base64.StdEncoding.EncodeToString(proof), ClientId: "client_9"})
+		assert.Equal(t, v1.ErrUnauthorized, err)
+	})
+
+	t.Run("proof kept when clients full", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, _ := setupVnetClientServiceWithUser(t)
+		limited := vnet()
+		limited.ClientsLimit = 1
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(limited, nil).Times(2)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(limited, nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 4, RemainingTraffic: 1024}, nil)
+		mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{{ClientId: "client_1", VirtualIp: "10.0.0.1"}}, nil)
+
+		// 拒绝准入时不消耗挑战，客户端可在名额空出后用同一应答重试
+		challenge, err := vnetClientService.ClientChallenge(ctx, "relay-1", &v1.ClientChallengeRequest{Token: "token_1", ClientId: "client_9"})
+		assert.NoError(t, err)
+		salt, _ := base64.StdEncoding.DecodeString(challenge.Salt)
+		clientKey := vnetpass.ClientKey("pass_1", salt, challenge.Time, challenge.Memory, challenge.Threads)
+		proof := vnetpass.ClientProof(clientKey, vnetpass.AuthMessage("token_1", "client_9", challenge.Challenge))
+		mockVnetClientRepo.EXPECT().UseChallenge(ctx, gomock.Any(), gomock.Any()).Times(0)
+
+		_, err = vnetClientService.AdmitClient(ctx, "relay-1", &v1.AdmitClientRequest{Token: "token_1", Challenge: challenge.Challenge, Proof: base64.StdEncoding.EncodeToString(proof), ClientId: "client_9"})
+		assert.Equal(t, v1.ErrVnetClientsFull, err)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, _, _, _ := setupVnetClientServiceWithUser(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(nil, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrUnauthorized, err)
+	})
+
+	t.Run("traffic exhausted", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, mockUserRepo, _, _ := setupVnetClientServiceWithUser(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet(), nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1}, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrTrafficExhausted, err)
+	})
+
+	t.Run("clients full", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, _ := setupVnetClientServiceWithUser(t)
+		limited := vnet()
+		limited.ClientsLimit = 1
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(limited, nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(limited, nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 4, RemainingTraffic: 1024}, nil)
+		mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{{ClientId: "client_1", VirtualIp: "10.0.0.1"}}, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrVnetClientsFull, err)
+	})
+
+	t.Run("address exhausted", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, mockIpamService := setupVnetClientServiceWithUser(t)
+		expiry := time.Now().Add(time.Hour)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet(), nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 2, RemainingTraffic: 1024, PrivilegeExpiry: &expiry}, nil)
+		mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{
+			{ClientId: "client_1", VirtualIp: "10.0.0.1"},
+			{ClientId: "client_2", VirtualIp: "10.0.0.2"},
+		}, nil)
+		mockIpamService.EXPECT().AcquireLease(ctx, gomock.Any(), "client_9", "", "").Return(nil, v1.ErrVnetAddressExhausted)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrVnetAddressExhausted, err)
+	})
+}
+
+func TestVnetClientService_AdmitClient_LegacyPasswordDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	srv := service.NewService(mock_repository.NewMockTransaction(ctrl), logger, sf, j)
+
+	// 未开启 vnet.legacy_password 时不接受密码明文
+	conf := viper.New()
+	conf.Set("node.keys", map[string]string{"relay-1": "secret-1"})
+	vnetClientService := service.NewVnetClientService(srv, conf, mockVnetRepo, mock_repository.NewMockUserRepository(ctrl), mock_repository.NewMockVnetClientRepository(ctrl), mock_service.NewMockIpamService(ctrl), mock_repository.NewMockIpLeaseRepository(ctrl), mock_repository.NewMockNodeRepository(ctrl), mock_repository.NewMockVnetMemberRepository(ctrl), mock_repository.NewMockVnetBanRepository(ctrl), mock_repository.NewMockVnetInviteRepository(ctrl), mock_repository.NewMockOrganizationRepository(ctrl), newTestPlanService(ctrl, testPlans()))
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "user_1", Enabled: true, NodeId: "relay-1", Token: "token_1", PasswordHash: hashVnetPassword(t, "pass_1"), IpRange: "10.0.0.0/24", ClientsLimit: 5}
+	mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet, nil)
+
+	_, err := vnetClientService.AdmitClient(ctx, "relay-1", &v1.AdmitClientRequest{Token: "token_1", Password: "pass_1", ClientId: "client_9"})
+	assert.Equal(t, v1.ErrUnauthorized, err)
+}
+
+func TestVnetClientService_AdmitClient_InviteKey(t *testing.T) {
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "user_1", Enabled: true, NodeId: "relay-1", Token: "token_1", PasswordHash: hashVnetPassword(t, "pass_1"), IpRange: "10.0.0.0/24", ClientsLimit: 5}
+	keyHash := sha256.Sum256([]byte("key_1"))
+	redemption := &model.VnetInviteRedemption{VnetId: "vnet_1", ClientId: "client_9", InviteId: "inv_1", KeyHash: hex.EncodeToString(keyHash[:])}
+	req := &v1.AdmitClientRequest{Token: "token_1", InviteKey: "key_1", ClientId: "client_9"}
+
+	t.Run("valid key", func(t *testing.T) {
+		// 接入密钥代替密码，之后的检查照常进行
+		vnetClientService, mockVnetRepo, mockUserRepo, _, _, _, _, mockVnetInviteRepo := setupVnetClientServiceWithMembers(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet, nil)
+		mockVnetInviteRepo.EXPECT().GetRedemption(ctx, "vnet_1", "client_9").Return(redemption, nil)
+		mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(&model.VnetInvite{InviteId: "inv_1"}, nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1}, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrTrafficExhausted, err)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, _, _, _, _, _, mockVnetInviteRepo := setupVnetClientServiceWithMembers(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet, nil)
+		mockVnetInviteRepo.EXPECT().GetRedemption(ctx, "vnet_1", "client_9").Return(redemption, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", &v1.AdmitClientRequest{Token: "token_1", InviteKey: "key_2", ClientId: "client_9"})
+		assert.Equal(t, v1.ErrUnauthorized, err)
+	})
+
+	t.Run("revoked invite", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, _, _, _, _, _, mockVnetInviteRepo := setupVnetClientServiceWithMembers(t)
+		revokedAt := time.Now()
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet, nil)
+		mockVnetInviteRepo.EXPECT().GetRedemption(ctx, "vnet_1", "client_9").Return(redemption, nil)
+		mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(&model.VnetInvite{InviteId: "inv_1", RevokedAt: &revokedAt}, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrUnauthorized, err)
+	})
+}
+
+func TestVnetClientService_AdmitClient_Approval(t *testing.T) {
+	ctx := context.Background()
+	vnet := func() *model.Vnet {
+		return &model.Vnet{VnetId: "vnet_1", UserId: "user_1", Enabled: true, NodeId: "relay-1", Token: "token_1", IpRange: "10.0.0.0/24", ClientsLimit: 5, RequireApproval: true}
+	}
+	owner := &model.User{UserId: "user_1", UserGroup: 1, RemainingTraffic: 1024}
+	req := &v1.AdmitClientRequest{Token: "token_1", ClientId: "client_9", DeviceKey: "device_key_9", MacAddress: "02:00:00:00:00:09", PublicEndpoint: "203.0.113.9:4000"}
+	deviceKeyHash := sha256.Sum256([]byte("device_key_9"))
+
+	t.Run("new device pending", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, _, mockVnetMemberRepo, mockVnetBanRepo, _ := setupVnetClientServiceWithMembers(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet(), nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(owner, nil)
+		mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+		mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_9").Return(nil, nil)
+		mockVnetMemberRepo.EXPECT().SaveMember(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, member *model.VnetMember) error {
+			assert.Equal(t, model.VnetMemberPending, member.Status)
+			assert.Equal(t, "203.0.113.9:4000", member.PublicEndpoint)
+			assert.Nil(t, member.ReviewedAt)
+			// 审批记录与设备密钥绑定，只保存哈希
+			assert.Equal(t, hex.EncodeToString(deviceKeyHash[:]), member.DeviceKeyHash)
+			return nil
+		})
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrMemberPendingApproval, err)
+	})
+
+	t.Run("online device approved", func(t *testing.T) {
+		// 开启审批前已接入的设备视为已批准
+		vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, mockIpamService, mockVnetMemberRepo, mockVnetBanRepo, _ := setupVnetClientServiceWithMembers(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet(), nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(owner, nil)
+		mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{{ClientId: "client_9", VirtualIp: "10.0.0.9"}}, nil)
+		mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_9").Return(nil, nil)
+		mockVnetMemberRepo.EXPECT().SaveMember(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, member *model.VnetMember) error {
+			assert.Equal(t, model.VnetMemberApproved, member.Status)
+			assert.NotNil(t, member.ReviewedAt)
+			return nil
+		})
+		mockIpamService.EXPECT().AcquireLease(ctx, gomock.Any(), "client_9", "02:00:00:00:00:09", "").
+			Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_9", Address: "10.0.0.9"}, nil)
+		mockVnetClientRepo.EXPECT().UpsertVnetClient(ctx, gomock.Any()).Return(nil)
+		mockVnetClientRepo.EXPECT().SyncClientsOnline(ctx, []string{"vnet_1"}).Return(nil)
+
+		resp, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.0.9", resp.VirtualIp)
+	})
+
+	t.Run("device key required", func(t *testing.T) {
+		vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, _, _, mockVnetBanRepo, _ := setupVnetClientServiceWithMembers(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet(), nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(owner, nil)
+		mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", &v1.AdmitClientRequest{Token: "token_1", ClientId: "client_9"})
+		assert.Equal(t, v1.ErrDeviceKeyInvalid, err)
+	})
+
+	t.Run("approved client id used by another device", func(t *testing.T) {
+		// 冒用已批准设备的标识时密钥不匹配，不能继承审批结果
+		vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, _, mockVnetMemberRepo, mockVnetBanRepo, _ := setupVnetClientServiceWithMembers(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet(), nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(owner, nil)
+		mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+		mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_9").Return(&model.VnetMember{VnetId: "vnet_1", ClientId: "client_9", Status: model.VnetMemberApproved, DeviceKeyHash: hex.EncodeToString(deviceKeyHash[:])}, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", &v1.AdmitClientRequest{Token: "token_1", ClientId: "client_9", DeviceKey: "other_key"})
+		assert.Equal(t, v1.ErrDeviceKeyInvalid, err)
+	})
+
+	t.Run("pre-approved device binds its key", func(t *testing.T) {
+		// 所有者预先批准的设备在首次接入时绑定密钥
+		vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, mockIpamService, mockVnetMemberRepo, mockVnetBanRepo, mockVnetInviteRepo := setupVnetClientServiceWithMembers(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet(), nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(owner, nil)
+		mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+		mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_9").Return(&model.VnetMember{VnetId: "vnet_1", ClientId: "client_9", Status: model.VnetMemberApproved}, nil)
+		mockVnetInviteRepo.EXPECT().GetRedemption(ctx, "vnet_1", "client_9").Return(nil, nil)
+		mockVnetMemberRepo.EXPECT().SaveMember(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, member *model.VnetMember) error {
+			assert.Equal(t, model.VnetMemberApproved, member.Status)
+			assert.Equal(t, hex.EncodeToString(deviceKeyHash[:]), member.DeviceKeyHash)
+			return nil
+		})
+		mockIpamService.EXPECT().AcquireLease(ctx, gomock.Any(), "client_9", "02:00:00:00:00:09", "").
+			Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_9", Address: "10.0.0.9"}, nil)
+		mockVnetClientRepo.EXPECT().UpsertVnetClient(ctx, gomock.Any()).Return(nil)
+		mockVnetClientRepo.EXPECT().SyncClientsOnline(ctx, []string{"vnet_1"}).Return(nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("auto-approved client id without invite key", func(t *testing.T) {
+		// 兑换邀请自动批准的设备标识只能由持有接入密钥的设备绑定
+		vnetClientService, mockVnetRepo, mockUserRepo, mockVnetClientRepo, _, mockVnetMemberRepo, mockVnetBanRepo, mockVnetInviteRepo := setupVnetClientServiceWithMembers(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet(), nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(owner, nil)
+		mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		mockVnetClientRepo.EXPECT().GetVnetClientsByVnetId(ctx, "vnet_1").Return(&[]model.VnetClient{}, nil)
+		mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_9").Return(&model.VnetMember{VnetId: "vnet_1", ClientId: "client_9", Status: model.VnetMemberApproved}, nil)
+		mockVnetInviteRepo.EXPECT().GetRedemption(ctx, "vnet_1", "client_9").Return(&model.VnetInviteRedemption{VnetId: "vnet_1", ClientId: "client_9", InviteId: "inv_1"}, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrDeviceKeyInvalid, err)
+	})
+
+	t.Run("banned device key", func(t *testing.T) {
+		// 被封禁的设备更换设备标识后仍按设备密钥命中封禁
+		vnetClientService, mockVnetRepo, mockUserRepo, _, _, _, mockVnetBanRepo, _ := setupVnetClientServiceWithMembers(t)
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(vnet(), nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet(), nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(owner, nil)
+		mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{{VnetId: "vnet_1", ClientId: "client_1", DeviceKeyHash: hex.EncodeToString(deviceKeyHash[:])}}, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrMemberBanned, err)
+	})
+
+	t.Run("banned address", func(t *testing.T) {
+		// 封禁对未开启审批的虚拟网络同样有效
+		vnetClientService, mockVnetRepo, mockUserRepo, _, _, _, mockVnetBanRepo, _ := setupVnetClientServiceWithMembers(t)
+		open := vnet()
+		open.RequireApproval = false
+		mockVnetRepo.EXPECT().GetVnetByToken(ctx, "token_1").Return(open, nil)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(open, nil)
+		mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(owner, nil)
+		mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{{VnetId: "vnet_1", Ip: "203.0.113.0/24"}}, nil)
+
+		_, err := vnetClientService.AdmitClient(ctx, "relay-1", req)
+		assert.Equal(t, v1.ErrMemberBanned, err)
+	})
+}
+
+// hashVnetPassword 生成测试用的密码校验值
+func hashVnetPassword(t *testing.T, password string) string {
+	hash, err := vnetpass.Hash(password)
+	assert.NoError(t, err)
+	return hash
+}
@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type vnetCollaboratorFixture struct {
+	vnetCollaboratorService  service.VnetCollaboratorService
+	mockUserRepo             *mock_repository.MockUserRepository
+	mockVnetCollaboratorRepo *mock_repository.MockVnetCollaboratorRepository
+}
+
+func setupVnetCollaboratorService(t *testing.T) *vnetCollaboratorFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &vnetCollaboratorFixture{
+		mockUserRepo:             mock_repository.NewMockUserRepository(ctrl),
+		mockVnetCollaboratorRepo: mock_repository.NewMockVnetCollaboratorRepository(ctrl),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	f.vnetCollaboratorService = service.NewVnetCollaboratorService(srv, f.mockUserRepo, f.mockVnetCollaboratorRepo)
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return f
+}
+
+func TestVnetCollaboratorService_SetCollaborator(t *testing.T) {
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "owner"}
+
+	t.Run("grant", func(t *testing.T) {
+		f := setupVnetCollaboratorService(t)
+		f.mockUserRepo.EXPECT().GetByEmail(ctx, "bob@example.com").Return(&model.User{UserId: "bob"}, nil)
+		f.mockVnetCollaboratorRepo.EXPECT().GetCollaborator(ctx, "vnet_1", "bob").Return(nil, nil)
+		f.mockVnetCollaboratorRepo.EXPECT().SaveCollaborator(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, collaborator *model.VnetCollaborator) error {
+			assert.Equal(t, model.VnetRoleOperator, collaborator.Role)
+			assert.Equal(t, "owner", collaborator.GrantedBy)
+			return nil
+		})
+		f.mockVnetCollaboratorRepo.EXPECT().CreateAuditLog(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, log *model.VnetAuditLog) error {
+			assert.Equal(t, model.VnetAuditCollaboratorGrant, log.Action)
+			assert.Equal(t, "bob", log.TargetId)
+			return nil
+		})
+
+		err := f.vnetCollaboratorService.SetCollaborator(ctx, vnet, "owner", model.VnetRoleOwner, &v1.SetVnetCollaboratorRequest{Account: "bob@example.com", Role: model.VnetRoleOperator})
+		assert.NoError(t, err)
+	})
+
+	t.Run("admin cannot grant admin", func(t *testing.T) {
+		f := setupVnetCollaboratorService(t)
+
+		err := f.vnetCollaboratorService.SetCollaborator(ctx, vnet, "alice", model.VnetRoleAdmin, &v1.SetVnetCollaboratorRequest{Account: "bob", Role: model.VnetRoleAdmin})
+		assert.ErrorIs(t, err, v1.ErrForbidden)
+	})
+
+	t.Run("admin cannot change another admin", func(t *testing.T) {
+		f := setupVnetCollaboratorService(t)
+		f.mockUserRepo.EXPECT().GetByUsername(ctx, "bob").Return(&model.User{UserId: "bob"}, nil)
+		f.mockVnetCollaboratorRepo.EXPECT().GetCollaborator(ctx, "vnet_1", "bob").Return(&model.VnetCollaborator{VnetId: "vnet_1", UserId: "bob", Role: model.VnetRoleAdmin}, nil)
+
+		err := f.vnetCollaboratorService.SetCollaborator(ctx, vnet, "alice", model.VnetRoleAdmin, &v1.SetVnetCollaboratorRequest{Account: "bob", Role: model.VnetRoleViewer})
+		assert.ErrorIs(t, err, v1.ErrForbidden)
+	})
+
+	t.Run("owner cannot be targeted", func(t *testing.T) {
+		f := setupVnetCollaboratorService(t)
+		f.mockUserRepo.EXPECT().GetByUsername(ctx, "owner_name").Return(&model.User{UserId: "owner"}, nil)
+
+		err := f.vnetCollaboratorService.SetCollaborator(ctx, vnet, "alice", model.VnetRoleAdmin, &v1.SetVnetCollaboratorRequest{Account: "owner_name", Role: model.VnetRoleViewer})
+		assert.ErrorIs(t, err, v1.ErrBadRequest)
+	})
+}
+
+func TestVnetCollaboratorService_RemoveCollaborator(t *testing.T) {
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "owner"}
+
+	t.Run("leave", func(t *testing.T) {
+		f := setupVnetCollaboratorService(t)
+		f.mockVnetCollaboratorRepo.EXPECT().GetCollaborator(ctx, "vnet_1", "bob").Return(&model.VnetCollaborator{VnetId: "vnet_1", UserId: "bob", Role: model.VnetRoleViewer}, nil)
+		f.mockVnetCollaboratorRepo.EXPECT().DeleteCollaborator(ctx, "vnet_1", "bob").Return(true, nil)
+		f.mockVnetCollaboratorRepo.EXPECT().CreateAuditLog(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, log *model.VnetAuditLog) error {
+			assert.Equal(t, model.VnetAuditCollaboratorRevoke, log.Action)
+			assert.Equal(t, "bob", log.ActorId)
+			return nil
+		})
+
+		err := f.vnetCollaboratorService.RemoveCollaborator(ctx, vnet, "bob", model.VnetRoleViewer, "bob")
+		assert.NoError(t, err)
+	})
+
+	t.Run("operator cannot remove others", func(t *testing.T) {
+		f := setupVnetCollaboratorService(t)
+		f.mockVnetCollaboratorRepo.EXPECT().GetCollaborator(ctx, "vnet_1", "carol").Return(&model.VnetCollaborator{VnetId: "vnet_1", UserId: "carol", Role: model.VnetRoleOperator}, nil)
+
+		err := f.vnetCollaboratorService.RemoveCollaborator(ctx, vnet, "bob", model.VnetRoleOperator, "carol")
+		assert.ErrorIs(t, err, v1.ErrForbidden)
+	})
+}
@@ -0,0 +1,138 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/color"
+	"image/png"
+	"testing"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	"hyacinth-backend/pkg/clientconf"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type vnetConfigFixture struct {
+	vnetConfigService service.VnetConfigService
+	mockNodeRepo      *mock_repository.MockNodeRepository
+	mockIpLeaseRepo   *mock_repository.MockIpLeaseRepository
+}
+
+func setupVnetConfigService(t *testing.T) *vnetConfigFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &vnetConfigFixture{
+		mockNodeRepo:    mock_repository.NewMockNodeRepository(ctrl),
+		mockIpLeaseRepo: mock_repository.NewMockIpLeaseRepository(ctrl),
+	}
+	srv := service.NewService(mock_repository.NewMockTransaction(ctrl), logger, sf, j)
+	f.vnetConfigService = service.NewVnetConfigService(srv, f.mockNodeRepo, f.mockIpLeaseRepo)
+	return f
+}
+
+func TestVnetConfigService_RenderConfig(t *testing.T) {
+	ctx := context.Background()
+	vnet := &model.Vnet{
+		VnetId:       "vnet_1",
+		Comment:      "home",
+		Token:        "token_1",
+		PasswordHash: "hash",
+		IpRange:      "10.0.0.0/24",
+		EnableDHCP:   true,
+		NodeId:       "relay-1",
+	}
+
+	t.Run("n2n with reserved address", func(t *testing.T) {
+		f := setupVnetConfigService(t)
+		f.mockNodeRepo.EXPECT().GetNodeByNodeId(ctx, "relay-1").Return(&model.Node{NodeId: "relay-1", PublicAddr: "203.0.113.1:7654"}, nil)
+		f.mockIpLeaseRepo.EXPECT().GetLeaseByClientId(ctx, "vnet_1", "client_1").Return(&model.IpLease{Address: "10.0.0.5", Static: true}, nil)
+
+		config, err := f.vnetConfigService.RenderConfig(ctx, vnet, &v1.GetVnetConfigRequest{Format: "n2n", ClientId: "client_1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "vnet_1.edge.conf", config.FileName)
+		content := string(config.Content)
+		assert.Contains(t, content, "-c=token_1\n")
+		assert.Contains(t, content, "-k="+clientconf.PasswordPlaceholder+"\n")
+		assert.Contains(t, content, "-l=203.0.113.1:7654\n")
+		assert.Contains(t, content, "-a=static:10.0.0.5/24\n")
+		assert.NotContains(t, content, "dhcp")
+	})
+
+	t.Run("json defaults to dhcp", func(t *testing.T) {
+		f := setupVnetConfigService(t)
+		f.mockNodeRepo.EXPECT().GetNodeByNodeId(ctx, "relay-1").Return(&model.Node{NodeId: "relay-1", PublicAddr: "203.0.113.1:7654"}, nil)
+
+		config, err := f.vnetConfigService.RenderConfig(ctx, vnet, &v1.GetVnetConfigRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "application/json", config.ContentType)
+		var profile map[string]interface{}
+		assert.NoError(t, json.Unmarshal(config.Content, &profile))
+		assert.Equal(t, "203.0.113.1:7654", profile["server"])
+		assert.Equal(t, true, profile["dhcp"])
+		assert.NotContains(t, profile, "address")
+	})
+
+	t.Run("newline in comment", func(t *testing.T) {
+		f := setupVnetConfigService(t)
+		f.mockNodeRepo.EXPECT().GetNodeByNodeId(ctx, "relay-1").Return(&model.Node{NodeId: "relay-1", PublicAddr: "203.0.113.1:7654"}, nil).Times(2)
+		injected := *vnet
+		injected.Comment = "home\n-l=attacker:7654\r\n-k=x"
+
+		// 备注中的换行不能在配置中产生新的参数行
+		for _, format := range []string{"n2n", "wireguard"} {
+			config, err := f.vnetConfigService.RenderConfig(ctx, &injected, &v1.GetVnetConfigRequest{Format: format})
+			assert.NoError(t, err)
+			content := string(config.Content)
+			assert.NotContains(t, content, "\n-l=attacker")
+			assert.NotContains(t, content, "\r")
+			assert.Contains(t, content, "# home -l=attacker:7654  -k=x (vnet_1)\n")
+		}
+	})
+
+	t.Run("invalid client id", func(t *testing.T) {
+		f := setupVnetConfigService(t)
+
+		_, err := f.vnetConfigService.RenderConfig(ctx, vnet, &v1.GetVnetConfigRequest{Format: "n2n", ClientId: "client_1\n-l=attacker:7654"})
+		assert.ErrorIs(t, err, v1.ErrBadRequest)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		f := setupVnetConfigService(t)
+
+		_, err := f.vnetConfigService.RenderConfig(ctx, vnet, &v1.GetVnetConfigRequest{Format: "openvpn"})
+		assert.ErrorIs(t, err, v1.ErrBadRequest)
+	})
+
+	t.Run("node unassigned", func(t *testing.T) {
+		f := setupVnetConfigService(t)
+
+		_, err := f.vnetConfigService.RenderConfig(ctx, &model.Vnet{VnetId: "vnet_2", IpRange: "10.0.1.0/24"}, &v1.GetVnetConfigRequest{Format: "wireguard"})
+		assert.ErrorIs(t, err, v1.ErrVnetNodeUnassigned)
+	})
+}
+
+func TestVnetConfigService_RenderQRCode(t *testing.T) {
+	f := setupVnetConfigService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", Token: "token_1", IpRange: "10.0.0.0/24", EnableDHCP: true, NodeId: "relay-1"}
+	f.mockNodeRepo.EXPECT().GetNodeByNodeId(ctx, "relay-1").Return(&model.Node{NodeId: "relay-1", PublicAddr: "203.0.113.1:7654"}, nil)
+
+	data, err := f.vnetConfigService.RenderQRCode(ctx, vnet, &v1.GetVnetConfigRequest{})
+	assert.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	// 正方形，边长为（模块数 + 两侧空白区）× 8
+	bounds := img.Bounds()
+	assert.Equal(t, bounds.Dx(), bounds.Dy())
+	assert.Zero(t, bounds.Dx()%8)
+	// 四周留 4 个模块的空白区，之后是左上角定位图形
+	assert.Equal(t, color.Gray{Y: 0xff}, color.GrayModel.Convert(img.At(31, 31)))
+	assert.Equal(t, color.Gray{Y: 0}, color.GrayModel.Convert(img.At(32, 32)))
+}
@@ -0,0 +1,221 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupVnetEventService(t *testing.T) (service.VnetEventService, *mock_repository.MockVnetEventRepository) {
+	ctrl := gomock.NewController(t)
+
+	mockVnetEventRepo := mock_repository.NewMockVnetEventRepository(ctrl)
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	vnetEventService := service.NewVnetEventService(srv, mockVnetEventRepo)
+
+	return vnetEventService, mockVnetEventRepo
+}
+
+func TestVnetEventService_Record(t *testing.T) {
+	vnetEventService, mockVnetEventRepo := setupVnetEventService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "user_1", NodeId: "relay-1", Token: "token_1", Enabled: true, Revision: 2}
+
+	mockVnetEventRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *model.VnetEvent) error {
+		assert.Equal(t, model.VnetEventUpdate, event.Type)
+		assert.Equal(t, "relay-1", event.NodeId)
+		assert.Equal(t, int64(2), event.VnetRevision)
+
+		var config v1.NodeVnetConfig
+		assert.NoError(t, json.Unmarshal([]byte(event.Payload), &config))
+		assert.Equal(t, "token_1", config.Token)
+		return nil
+	})
+
+	assert.NoError(t, vnetEventService.Record(ctx, model.VnetEventUpdate, vnet))
+}
+
+func TestVnetEventService_Record_WireGuardRoutes(t *testing.T) {
+	vnetEventService, mockVnetEventRepo := setupVnetEventService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{
+		VnetId: "vnet_1", NodeId: "relay-1", Type: model.VnetTypeWireGuard, Enabled: true, Revision: 5,
+		Peers:  `{"revision":1,"peers":[{"clientId":"client_1","publicKey":"key_1","allowedIps":["10.0.0.1/32"]}]}`,
+		Routes: `{"revision":2,"routes":[{"prefix":"192.168.1.0/24","clientId":"client_1"},{"prefix":"0.0.0.0/0","clientId":"client_2","exit":true}]}`,
+	}
+
+	mockVnetEventRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *model.VnetEvent) error {
+		var config v1.NodeVnetConfig
+		assert.NoError(t, json.Unmarshal([]byte(event.Payload), &config))
+		assert.Equal(t, int64(2), config.Routes.Revision)
+		assert.Len(t, config.Routes.Routes, 2)
+		// 已批准的路由并入通告设备的 AllowedIps，没有登记的设备忽略
+		assert.Equal(t, []string{"10.0.0.1/32", "192.168.1.0/24"}, config.Peers.Peers[0].AllowedIps)
+		return nil
+	})
+
+	assert.NoError(t, vnetEventService.Record(ctx, model.VnetEventUpdate, vnet))
+}
+
+func TestVnetEventService_Record_Delete(t *testing.T) {
+	vnetEventService, mockVnetEventRepo := setupVnetEventService(t)
+
+	ctx := context.Background()
+
+	mockVnetEventRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *model.VnetEvent) error {
+		assert.Equal(t, model.VnetEventDelete, event.Type)
+		assert.Empty(t, event.Payload)
+		return nil
+	})
+
+	assert.NoError(t, vnetEventService.Record(ctx, model.VnetEventDelete, &model.Vnet{VnetId: "vnet_1"}))
+}
+
+func TestVnetEventService_Watch_FiltersOtherNodes(t *testing.T) {
+	vnetEventService, mockVnetEventRepo := setupVnetEventService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockVnetEventRepo.EXPECT().GetOldestRevision(ctx).Return(int64(1), nil)
+	old := time.Now().Add(-time.Minute)
+
+	mockVnetEventRepo.EXPECT().ListAfter(ctx, int64(10), gomock.Any()).Return(&[]model.VnetEvent{
+		{Model: gorm.Model{ID: 11, CreatedAt: old}, VnetId: "vnet_1", NodeId: "relay-1", Type: model.VnetEventCreate, Payload: `{"vnetId":"vnet_1"}`},
+		{Model: gorm.Model{ID: 12, CreatedAt: old}, VnetId: "vnet_2", NodeId: "relay-2", Type: model.VnetEventCreate},
+		// 尚未分配节点的虚拟网络的事件不推送给任何节点
+		{Model: gorm.Model{ID: 13, CreatedAt: old}, VnetId: "vnet_3", NodeId: "", Type: model.VnetEventCreate, Payload: `{"vnetId":"vnet_3"}`},
+		{Model: gorm.Model{ID: 14, CreatedAt: old}, VnetId: "vnet_4", NodeId: "relay-1", Type: model.VnetEventDelete},
+	}, nil)
+
+	var received []*v1.NodeVnetEvent
+	err := vnetEventService.Watch(ctx, "relay-1", 10, func(event *v1.NodeVnetEvent) error {
+		received = append(received, event)
+		if len(received) == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, received, 2)
+	assert.Equal(t, int64(11), received[0].Revision)
+	assert.NotNil(t, received[0].Config)
+	assert.Equal(t, int64(14), received[1].Revision)
+	assert.Nil(t, received[1].Config)
+}
+
+func TestVnetEventService_Watch_WaitsForRecentGap(t *testing.T) {
+	vnetEventService, mockVnetEventRepo := setupVnetEventService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockVnetEventRepo.EXPECT().GetOldestRevision(ctx).Return(int64(1), nil)
+	old := time.Now().Add(-time.Minute)
+
+	// 版本号 2 尚未提交，版本号 3 刚刚写入，应暂停在空洞之前
+	mockVnetEventRepo.EXPECT().ListAfter(ctx, int64(0), gomock.Any()).Return(&[]model.VnetEvent{
+		{Model: gorm.Model{ID: 1, CreatedAt: old}, VnetId: "vnet_1", NodeId: "relay-1", Type: model.VnetEventCreate},
+		{Model: gorm.Model{ID: 3, CreatedAt: time.Now()}, VnetId: "vnet_3", NodeId: "relay-1", Type: model.VnetEventCreate},
+	}, nil)
+
+	var received []int64
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	err := vnetEventService.Watch(ctx, "relay-1", 0, func(event *v1.NodeVnetEvent) error {
+		received = append(received, event.Revision)
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int64{1}, received)
+}
+
+func TestVnetEventService_Watch_SkipsStaleGap(t *testing.T) {
+	vnetEventService, mockVnetEventRepo := setupVnetEventService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockVnetEventRepo.EXPECT().GetOldestRevision(ctx).Return(int64(1), nil)
+	old := time.Now().Add(-time.Minute)
+
+	// 早已超时的空洞视为回滚的事务，直接跳过
+	mockVnetEventRepo.EXPECT().ListAfter(ctx, int64(0), gomock.Any()).Return(&[]model.VnetEvent{
+		{Model: gorm.Model{ID: 1, CreatedAt: old}, VnetId: "vnet_1", NodeId: "relay-1", Type: model.VnetEventCreate},
+		{Model: gorm.Model{ID: 3, CreatedAt: old}, VnetId: "vnet_3", NodeId: "relay-1", Type: model.VnetEventCreate},
+	}, nil)
+
+	var received []int64
+	err := vnetEventService.Watch(ctx, "relay-1", 0, func(event *v1.NodeVnetEvent) error {
+		received = append(received, event.Revision)
+		if len(received) == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int64{1, 3}, received)
+}
+
+func TestVnetEventService_Notify_WakesWatcher(t *testing.T) {
+	vnetEventService, mockVnetEventRepo := setupVnetEventService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockVnetEventRepo.EXPECT().GetOldestRevision(ctx).Return(int64(1), nil)
+
+	gomock.InOrder(
+		mockVnetEventRepo.EXPECT().ListAfter(ctx, int64(0), gomock.Any()).DoAndReturn(func(ctx context.Context, after int64, limit int) (*[]model.VnetEvent, error) {
+			go vnetEventService.Notify()
+			return &[]model.VnetEvent{}, nil
+		}),
+		mockVnetEventRepo.EXPECT().ListAfter(ctx, int64(0), gomock.Any()).Return(&[]model.VnetEvent{
+			{Model: gorm.Model{ID: 1, CreatedAt: time.Now()}, VnetId: "vnet_1", NodeId: "relay-1", Type: model.VnetEventCreate},
+		}, nil),
+	)
+
+	start := time.Now()
+	err := vnetEventService.Watch(ctx, "relay-1", 0, func(event *v1.NodeVnetEvent) error {
+		cancel()
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	// 通知应立即唤醒，而不是等待轮询间隔
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestVnetEventService_Watch_Resync(t *testing.T) {
+	vnetEventService, mockVnetEventRepo := setupVnetEventService(t)
+
+	ctx := context.Background()
+
+	// 版本号 11 至 19 的事件已被清理，节点须全量同步，不再从事件表续传
+	mockVnetEventRepo.EXPECT().GetOldestRevision(ctx).Return(int64(20), nil)
+
+	var received []*v1.NodeVnetEvent
+	err := vnetEventService.Watch(ctx, "relay-1", 10, func(event *v1.NodeVnetEvent) error {
+		received = append(received, event)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, received, 1)
+	assert.Equal(t, model.VnetEventResync, received[0].Type)
+	assert.Zero(t, received[0].Revision)
+}
@@ -0,0 +1,231 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type vnetInviteFixture struct {
+	vnetInviteService     service.VnetInviteService
+	mockVnetRepo          *mock_repository.MockVnetRepository
+	mockVnetBanRepo       *mock_repository.MockVnetBanRepository
+	mockVnetInviteRepo    *mock_repository.MockVnetInviteRepository
+	mockVnetMemberService *mock_service.MockVnetMemberService
+}
+
+func setupVnetInviteService(t *testing.T) *vnetInviteFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &vnetInviteFixture{
+		mockVnetRepo:          mock_repository.NewMockVnetRepository(ctrl),
+		mockVnetBanRepo:       mock_repository.NewMockVnetBanRepository(ctrl),
+		mockVnetInviteRepo:    mock_repository.NewMockVnetInviteRepository(ctrl),
+		mockVnetMemberService: mock_service.NewMockVnetMemberService(ctrl),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	f.vnetInviteService = service.NewVnetInviteService(srv, f.mockVnetRepo, f.mockVnetBanRepo, f.mockVnetInviteRepo, f.mockVnetMemberService)
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return f
+}
+
+func TestVnetInviteService_CreateInvite(t *testing.T) {
+	f := setupVnetInviteService(t)
+
+	ctx := context.Background()
+
+	f.mockVnetInviteRepo.EXPECT().CreateInvite(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, invite *model.VnetInvite) error {
+		assert.Equal(t, "vnet_1", invite.VnetId)
+		assert.Equal(t, 3, invite.MaxUses)
+		assert.NotNil(t, invite.ExpiresAt)
+		return nil
+	})
+
+	item, err := f.vnetInviteService.CreateInvite(ctx, "vnet_1", &v1.CreateVnetInviteRequest{MaxUses: 3, Duration: 3600, AutoApprove: true})
+
+	assert.NoError(t, err)
+	assert.Contains(t, item.InviteId, "inv_")
+	// 邀请码只包含可直接放入 URL 的字符
+	assert.Regexp(t, `^[0-9a-zA-Z]{17,}$`, item.Code)
+	assert.True(t, item.Active)
+}
+
+func TestVnetInviteService_RedeemInvite(t *testing.T) {
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", Token: "token_1", RequireApproval: true}
+	invite := func() *model.VnetInvite {
+		return &model.VnetInvite{InviteId: "inv_1", VnetId: "vnet_1", Code: "code_1", MaxUses: 2, Uses: 1, AutoApprove: true}
+	}
+	req := &v1.RedeemInviteRequest{Code: "code_1", ClientId: "client_1", Name: "laptop"}
+	oldKeyHash := sha256.Sum256([]byte("old_key"))
+
+	t.Run("new device", func(t *testing.T) {
+		f := setupVnetInviteService(t)
+		f.mockVnetInviteRepo.EXPECT().GetInviteByCode(ctx, "code_1").Return(invite(), nil)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(invite(), nil)
+		f.mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		f.mockVnetInviteRepo.EXPECT().GetRedemption(ctx, "vnet_1", "client_1").Return(nil, nil)
+		f.mockVnetInviteRepo.EXPECT().UpdateInvite(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, invite *model.VnetInvite) error {
+			assert.Equal(t, 2, invite.Uses)
+			return nil
+		})
+		var keyHash string
+		f.mockVnetInviteRepo.EXPECT().SaveRedemption(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, redemption *model.VnetInviteRedemption) error {
+			assert.Equal(t, "inv_1", redemption.InviteId)
+			assert.Equal(t, "laptop", redemption.Name)
+			assert.Equal(t, "203.0.113.5", redemption.Ip)
+			keyHash = redemption.KeyHash
+			return nil
+		})
+		// 自动批准的邀请同时批准设备，审批与兑换者提交的设备密钥绑定
+		deviceKeyHash := sha256.Sum256([]byte("device_key_1"))
+		f.mockVnetMemberService.EXPECT().ApproveMember(ctx, "vnet_1", "client_1", hex.EncodeToString(deviceKeyHash[:])).Return(nil)
+
+		data, err := f.vnetInviteService.RedeemInvite(ctx, "203.0.113.5", &v1.RedeemInviteRequest{Code: "code_1", ClientId: "client_1", Name: "laptop", DeviceKey: "device_key_1"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "token_1", data.Token)
+		assert.False(t, data.RequireApproval)
+		// 只保存接入密钥的哈希
+		assert.NotEmpty(t, data.InviteKey)
+		assert.NotEqual(t, data.InviteKey, keyHash)
+	})
+
+	t.Run("client id bound to another device", func(t *testing.T) {
+		// 设备标识的审批已绑定其他设备时不能通过兑换邀请顶替
+		f := setupVnetInviteService(t)
+		f.mockVnetInviteRepo.EXPECT().GetInviteByCode(ctx, "code_1").Return(invite(), nil)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(invite(), nil)
+		f.mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		f.mockVnetInviteRepo.EXPECT().GetRedemption(ctx, "vnet_1", "client_1").Return(nil, nil)
+		f.mockVnetInviteRepo.EXPECT().UpdateInvite(ctx, gomock.Any()).Return(nil)
+		f.mockVnetInviteRepo.EXPECT().SaveRedemption(ctx, gomock.Any()).Return(nil)
+		f.mockVnetMemberService.EXPECT().ApproveMember(ctx, "vnet_1", "client_1", "").Return(v1.ErrDeviceKeyInvalid)
+
+		_, err := f.vnetInviteService.RedeemInvite(ctx, "203.0.113.5", req)
+		assert.Equal(t, v1.ErrClientIdInUse, err)
+	})
+
+	t.Run("banned device key", func(t *testing.T) {
+		// 被封禁的设备不能换用新的设备标识兑换邀请
+		f := setupVnetInviteService(t)
+		deviceKeyHash := sha256.Sum256([]byte("device_key_1"))
+		f.mockVnetInviteRepo.EXPECT().GetInviteByCode(ctx, "code_1").Return(invite(), nil)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(invite(), nil)
+		f.mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{{VnetId: "vnet_1", ClientId: "client_0", DeviceKeyHash: hex.EncodeToString(deviceKeyHash[:])}}, nil)
+
+		_, err := f.vnetInviteService.RedeemInvite(ctx, "203.0.113.5", &v1.RedeemInviteRequest{Code: "code_1", ClientId: "client_1", DeviceKey: "device_key_1"})
+		assert.Equal(t, v1.ErrMemberBanned, err)
+	})
+
+	t.Run("used up", func(t *testing.T) {
+		f := setupVnetInviteService(t)
+		usedUp := invite()
+		usedUp.Uses = 2
+		f.mockVnetInviteRepo.EXPECT().GetInviteByCode(ctx, "code_1").Return(usedUp, nil)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(usedUp, nil)
+		f.mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		f.mockVnetInviteRepo.EXPECT().GetRedemption(ctx, "vnet_1", "client_1").Return(nil, nil)
+
+		_, err := f.vnetInviteService.RedeemInvite(ctx, "203.0.113.5", req)
+		assert.Equal(t, v1.ErrInviteUnavailable, err)
+	})
+
+	t.Run("redeem again", func(t *testing.T) {
+		// 已兑换过的设备更换接入密钥，不再计入次数
+		f := setupVnetInviteService(t)
+		usedUp := invite()
+		usedUp.Uses = 2
+		usedUp.AutoApprove = false
+		f.mockVnetInviteRepo.EXPECT().GetInviteByCode(ctx, "code_1").Return(usedUp, nil)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(usedUp, nil)
+		f.mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		f.mockVnetInviteRepo.EXPECT().GetRedemption(ctx, "vnet_1", "client_1").Return(&model.VnetInviteRedemption{VnetId: "vnet_1", ClientId: "client_1", InviteId: "inv_1", KeyHash: hex.EncodeToString(oldKeyHash[:])}, nil)
+		f.mockVnetInviteRepo.EXPECT().SaveRedemption(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, redemption *model.VnetInviteRedemption) error {
+			assert.NotEqual(t, hex.EncodeToString(oldKeyHash[:]), redemption.KeyHash)
+			return nil
+		})
+
+		data, err := f.vnetInviteService.RedeemInvite(ctx, "203.0.113.5", &v1.RedeemInviteRequest{Code: "code_1", ClientId: "client_1", InviteKey: "old_key"})
+		assert.NoError(t, err)
+		assert.True(t, data.RequireApproval)
+	})
+
+	t.Run("client id redeemed by another device", func(t *testing.T) {
+		// 不持有当前接入密钥时不能顶替已兑换的设备
+		f := setupVnetInviteService(t)
+		f.mockVnetInviteRepo.EXPECT().GetInviteByCode(ctx, "code_1").Return(invite(), nil)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(invite(), nil)
+		f.mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		f.mockVnetInviteRepo.EXPECT().GetRedemption(ctx, "vnet_1", "client_1").Return(&model.VnetInviteRedemption{VnetId: "vnet_1", ClientId: "client_1", InviteId: "inv_1", KeyHash: hex.EncodeToString(oldKeyHash[:])}, nil).Times(2)
+
+		_, err := f.vnetInviteService.RedeemInvite(ctx, "203.0.113.5", req)
+		assert.Equal(t, v1.ErrClientIdInUse, err)
+
+		f.mockVnetInviteRepo.EXPECT().GetInviteByCode(ctx, "code_1").Return(invite(), nil)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(invite(), nil)
+		f.mockVnetBanRepo.EXPECT().GetActiveBans(ctx, "vnet_1", gomock.Any()).Return(&[]model.VnetBan{}, nil)
+		_, err = f.vnetInviteService.RedeemInvite(ctx, "203.0.113.5", &v1.RedeemInviteRequest{Code: "code_1", ClientId: "client_1", InviteKey: "wrong_key"})
+		assert.Equal(t, v1.ErrClientIdInUse, err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		f := setupVnetInviteService(t)
+		expired := invite()
+		expiresAt := time.Now().Add(-time.Minute)
+		expired.ExpiresAt = &expiresAt
+		f.mockVnetInviteRepo.EXPECT().GetInviteByCode(ctx, "code_1").Return(expired, nil)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(expired, nil)
+
+		_, err := f.vnetInviteService.RedeemInvite(ctx, "203.0.113.5", req)
+		assert.Equal(t, v1.ErrInviteUnavailable, err)
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		f := setupVnetInviteService(t)
+		f.mockVnetInviteRepo.EXPECT().GetInviteByCode(ctx, "code_1").Return(nil, nil)
+
+		_, err := f.vnetInviteService.RedeemInvite(ctx, "203.0.113.5", req)
+		assert.Equal(t, v1.ErrNotFound, err)
+	})
+}
+
+func TestVnetInviteService_RevokeInvite(t *testing.T) {
+	f := setupVnetInviteService(t)
+
+	ctx := context.Background()
+
+	f.mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_1").Return(&model.VnetInvite{InviteId: "inv_1", VnetId: "vnet_1"}, nil)
+	f.mockVnetInviteRepo.EXPECT().UpdateInvite(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, invite *model.VnetInvite) error {
+		assert.NotNil(t, invite.RevokedAt)
+		return nil
+	})
+	f.mockVnetInviteRepo.EXPECT().GetInvite(ctx, "vnet_1", "inv_2").Return(nil, nil)
+
+	assert.NoError(t, f.vnetInviteService.RevokeInvite(ctx, "vnet_1", "inv_1"))
+	assert.Equal(t, v1.ErrNotFound, f.vnetInviteService.RevokeInvite(ctx, "vnet_1", "inv_2"))
+}
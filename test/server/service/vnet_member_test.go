@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupVnetMemberService(t *testing.T) (service.VnetMemberService, *mock_repository.MockVnetRepository, *mock_repository.MockVnetMemberRepository) {
+	ctrl := gomock.NewController(t)
+
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockVnetMemberRepo := mock_repository.NewMockVnetMemberRepository(ctrl)
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	vnetMemberService := service.NewVnetMemberService(srv, mockVnetRepo, mockVnetMemberRepo)
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return vnetMemberService, mockVnetRepo, mockVnetMemberRepo
+}
+
+func TestVnetMemberService_ApproveMember(t *testing.T) {
+	vnetMemberService, mockVnetRepo, mockVnetMemberRepo := setupVnetMemberService(t)
+
+	ctx := context.Background()
+	member := &model.VnetMember{VnetId: "vnet_1", ClientId: "client_1", Status: model.VnetMemberPending}
+
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1"}, nil)
+	mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_1").Return(member, nil)
+	mockVnetMemberRepo.EXPECT().SaveMember(ctx, member).Return(nil)
+
+	err := vnetMemberService.ApproveMember(ctx, "vnet_1", "client_1", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.VnetMemberApproved, member.Status)
+	assert.NotNil(t, member.ReviewedAt)
+}
+
+func TestVnetMemberService_ApproveMember_DeviceKey(t *testing.T) {
+	ctx := context.Background()
+	keyHash := strings.Repeat("a", 64)
+
+	t.Run("pre-approve bound device", func(t *testing.T) {
+		// 预先批准时指定设备密钥，其他设备不能以同一标识接入
+		vnetMemberService, mockVnetRepo, mockVnetMemberRepo := setupVnetMemberService(t)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1"}, nil)
+		mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_1").Return(nil, nil)
+		mockVnetMemberRepo.EXPECT().SaveMember(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, member *model.VnetMember) error {
+			assert.Equal(t, model.VnetMemberApproved, member.Status)
+			assert.Equal(t, keyHash, member.DeviceKeyHash)
+			return nil
+		})
+
+		assert.NoError(t, vnetMemberService.ApproveMember(ctx, "vnet_1", "client_1", keyHash))
+	})
+
+	t.Run("bound to another device", func(t *testing.T) {
+		vnetMemberService, mockVnetRepo, mockVnetMemberRepo := setupVnetMemberService(t)
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1"}, nil)
+		mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_1").Return(&model.VnetMember{VnetId: "vnet_1", ClientId: "client_1", DeviceKeyHash: strings.Repeat("b", 64)}, nil)
+
+		assert.Equal(t, v1.ErrDeviceKeyInvalid, vnetMemberService.ApproveMember(ctx, "vnet_1", "client_1", keyHash))
+	})
+
+	t.Run("malformed hash", func(t *testing.T) {
+		vnetMemberService, _, _ := setupVnetMemberService(t)
+
+		assert.Equal(t, v1.ErrBadRequest, vnetMemberService.ApproveMember(ctx, "vnet_1", "client_1", "device_key"))
+	})
+}
+
+func TestVnetMemberService_RejectMember(t *testing.T) {
+	vnetMemberService, mockVnetRepo, mockVnetMemberRepo := setupVnetMemberService(t)
+
+	ctx := context.Background()
+
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1"}, nil).Times(2)
+	mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_1").Return(&model.VnetMember{Status: model.VnetMemberPending}, nil)
+	mockVnetMemberRepo.EXPECT().DeleteMember(ctx, "vnet_1", "client_1").Return(true, nil)
+	// 只能拒绝待审批的设备
+	mockVnetMemberRepo.EXPECT().GetMember(ctx, "vnet_1", "client_2").Return(&model.VnetMember{Status: model.VnetMemberApproved}, nil)
+
+	assert.NoError(t, vnetMemberService.RejectMember(ctx, "vnet_1", "client_1"))
+	assert.Equal(t, v1.ErrNotFound, vnetMemberService.RejectMember(ctx, "vnet_1", "client_2"))
+}
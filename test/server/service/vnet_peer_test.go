@@ -0,0 +1,234 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	"hyacinth-backend/pkg/wgkey"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPublicKey = "xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg="
+
+type vnetPeerFixture struct {
+	vnetPeerService      service.VnetPeerService
+	mockVnetRepo         *mock_repository.MockVnetRepository
+	mockVnetPeerRepo     *mock_repository.MockVnetPeerRepository
+	mockIpamService      *mock_service.MockIpamService
+	mockIpLeaseRepo      *mock_repository.MockIpLeaseRepository
+	mockNodeRepo         *mock_repository.MockNodeRepository
+	mockVnetEventService *mock_service.MockVnetEventService
+	mockUserRepo         *mock_repository.MockUserRepository
+}
+
+func setupVnetPeerService(t *testing.T) *vnetPeerFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &vnetPeerFixture{
+		mockVnetRepo:         mock_repository.NewMockVnetRepository(ctrl),
+		mockVnetPeerRepo:     mock_repository.NewMockVnetPeerRepository(ctrl),
+		mockIpamService:      mock_service.NewMockIpamService(ctrl),
+		mockIpLeaseRepo:      mock_repository.NewMockIpLeaseRepository(ctrl),
+		mockNodeRepo:         mock_repository.NewMockNodeRepository(ctrl),
+		mockVnetEventService: mock_service.NewMockVnetEventService(ctrl),
+		mockUserRepo:         mock_repository.NewMockUserRepository(ctrl),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	f.vnetPeerService = service.NewVnetPeerService(srv, f.mockVnetRepo, f.mockVnetPeerRepo, f.mockIpamService, f.mockIpLeaseRepo, f.mockNodeRepo, f.mockVnetEventService, f.mockUserRepo, mock_repository.NewMockOrganizationRepository(ctrl), newTestPlanService(ctrl, testPlans()))
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return f
+}
+
+func TestVnetPeerService_RegisterPeer(t *testing.T) {
+	f := setupVnetPeerService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "user_1", Type: model.VnetTypeWireGuard, ClientsLimit: 5, Revision: 3, PeerRevision: 1}
+	existing := []model.VnetPeer{
+		{VnetId: "vnet_1", ClientId: "client_1", PublicKey: "HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw=", Address: "10.0.0.1"},
+	}
+
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	f.mockVnetPeerRepo.EXPECT().GetPeerByPublicKey(ctx, "vnet_1", testPublicKey).Return(nil, nil)
+	f.mockVnetPeerRepo.EXPECT().GetPeer(ctx, "vnet_1", "client_2").Return(nil, nil)
+	f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 2}, nil)
+	f.mockVnetPeerRepo.EXPECT().CountPeers(ctx, "vnet_1").Return(int64(1), nil)
+	f.mockIpamService.EXPECT().AssignAddress(ctx, vnet, "client_2").Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_2", Address: "10.0.0.2", Static: true}, nil)
+	f.mockVnetPeerRepo.EXPECT().SavePeer(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, peer *model.VnetPeer) error {
+		existing = append(existing, *peer)
+		return nil
+	})
+	f.mockVnetPeerRepo.EXPECT().GetPeers(ctx, "vnet_1").DoAndReturn(func(ctx context.Context, vnetId string) (*[]model.VnetPeer, error) {
+		return &existing, nil
+	})
+	f.mockVnetRepo.EXPECT().UpdateVnet(ctx, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Record(ctx, model.VnetEventUpdate, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	data, err := f.vnetPeerService.RegisterPeer(ctx, "vnet_1", &v1.RegisterVnetPeerRequest{ClientId: "client_2", Name: "laptop", PublicKey: testPublicKey})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", data.Address)
+	assert.Equal(t, []string{"10.0.0.2/32"}, data.AllowedIps)
+	// 客户端自行生成的密钥不会返回私钥
+	assert.Empty(t, data.PrivateKey)
+
+	assert.True(t, vnet.NeedUpdate)
+	assert.Equal(t, int64(4), vnet.Revision)
+	assert.Equal(t, int64(2), vnet.PeerRevision)
+	var peers v1.NodeVnetPeers
+	assert.NoError(t, json.Unmarshal([]byte(vnet.Peers), &peers))
+	assert.Equal(t, v1.NodeVnetPeers{Revision: 2, Peers: []v1.NodeWireGuardPeer{
+		{ClientId: "client_1", PublicKey: "HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw=", AllowedIps: []string{"10.0.0.1/32"}},
+		{ClientId: "client_2", PublicKey: testPublicKey, AllowedIps: []string{"10.0.0.2/32"}},
+	}}, peers)
+}
+
+func TestVnetPeerService_RegisterPeer_Generate(t *testing.T) {
+	f := setupVnetPeerService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", Type: model.VnetTypeWireGuard, ClientsLimit: 5}
+	var saved *model.VnetPeer
+
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	f.mockVnetPeerRepo.EXPECT().GetPeerByPublicKey(ctx, "vnet_1", gomock.Any()).Return(nil, nil)
+	// 已登记的设备轮换密钥时沿用原记录，不占用新的名额
+	f.mockVnetPeerRepo.EXPECT().GetPeer(ctx, "vnet_1", "client_1").Return(&model.VnetPeer{VnetId: "vnet_1", ClientId: "client_1", PublicKey: testPublicKey, Address: "10.0.0.1"}, nil)
+	f.mockIpamService.EXPECT().AssignAddress(ctx, vnet, "client_1").Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.1", Static: true}, nil)
+	f.mockVnetPeerRepo.EXPECT().SavePeer(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, peer *model.VnetPeer) error {
+		saved = peer
+		return nil
+	})
+	f.mockVnetPeerRepo.EXPECT().GetPeers(ctx, "vnet_1").DoAndReturn(func(ctx context.Context, vnetId string) (*[]model.VnetPeer, error) {
+		return &[]model.VnetPeer{*saved}, nil
+	})
+	f.mockVnetRepo.EXPECT().UpdateVnet(ctx, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Record(ctx, model.VnetEventUpdate, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	data, err := f.vnetPeerService.RegisterPeer(ctx, "vnet_1", &v1.RegisterVnetPeerRequest{ClientId: "client_1", Generate: true})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, data.PrivateKey)
+	assert.NotEqual(t, testPublicKey, data.PublicKey)
+	// 只保存公钥
+	assert.Equal(t, data.PublicKey, saved.PublicKey)
+	_, err = wgkey.ParsePublicKey(saved.PublicKey)
+	assert.NoError(t, err)
+	assert.NotContains(t, vnet.Peers, data.PrivateKey)
+}
+
+func TestVnetPeerService_RegisterPeer_Rejected(t *testing.T) {
+	f := setupVnetPeerService(t)
+
+	ctx := context.Background()
+
+	// 公钥格式错误时不会访问数据库
+	_, err := f.vnetPeerService.RegisterPeer(ctx, "vnet_1", &v1.RegisterVnetPeerRequest{ClientId: "client_1", PublicKey: "not-a-key"})
+	assert.ErrorIs(t, err, v1.ErrInvalidWireGuardKey)
+
+	// 令牌类型的虚拟网络不能登记公钥
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", Type: model.VnetTypeN2N}, nil)
+	_, err = f.vnetPeerService.RegisterPeer(ctx, "vnet_1", &v1.RegisterVnetPeerRequest{ClientId: "client_1", PublicKey: testPublicKey})
+	assert.ErrorIs(t, err, v1.ErrVnetTypeMismatch)
+
+	// 公钥已属于其他设备
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_2").Return(&model.Vnet{VnetId: "vnet_2", Type: model.VnetTypeWireGuard, ClientsLimit: 5}, nil)
+	f.mockVnetPeerRepo.EXPECT().GetPeerByPublicKey(ctx, "vnet_2", testPublicKey).Return(&model.VnetPeer{VnetId: "vnet_2", ClientId: "client_9", PublicKey: testPublicKey}, nil)
+	_, err = f.vnetPeerService.RegisterPeer(ctx, "vnet_2", &v1.RegisterVnetPeerRequest{ClientId: "client_1", PublicKey: testPublicKey})
+	assert.ErrorIs(t, err, v1.ErrInvalidWireGuardKey)
+
+	// 设备数量达到上限
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_3").Return(&model.Vnet{VnetId: "vnet_3", UserId: "user_1", Type: model.VnetTypeWireGuard, ClientsLimit: 2}, nil)
+	f.mockVnetPeerRepo.EXPECT().GetPeerByPublicKey(ctx, "vnet_3", testPublicKey).Return(nil, nil)
+	f.mockVnetPeerRepo.EXPECT().GetPeer(ctx, "vnet_3", "client_1").Return(nil, nil)
+	f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 2}, nil)
+	f.mockVnetPeerRepo.EXPECT().CountPeers(ctx, "vnet_3").Return(int64(2), nil)
+	_, err = f.vnetPeerService.RegisterPeer(ctx, "vnet_3", &v1.RegisterVnetPeerRequest{ClientId: "client_1", PublicKey: testPublicKey})
+	assert.ErrorIs(t, err, v1.ErrVnetClientsFull)
+}
+
+func TestVnetPeerService_RegisterPeer_PlanLimit(t *testing.T) {
+	ctx := context.Background()
+	// 未设置数量限制时按所有者套餐的限制计算，普通用户每个虚拟网络最多 3 台设备
+	vnet := &model.Vnet{VnetId: "vnet_1", UserId: "user_1", Type: model.VnetTypeWireGuard}
+
+	t.Run("within plan", func(t *testing.T) {
+		f := setupVnetPeerService(t)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockVnetPeerRepo.EXPECT().GetPeerByPublicKey(ctx, "vnet_1", testPublicKey).Return(nil, nil)
+		f.mockVnetPeerRepo.EXPECT().GetPeer(ctx, "vnet_1", "client_1").Return(nil, nil)
+		f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1}, nil)
+		f.mockVnetPeerRepo.EXPECT().CountPeers(ctx, "vnet_1").Return(int64(2), nil)
+		f.mockIpamService.EXPECT().AssignAddress(ctx, vnet, "client_1").Return(&model.IpLease{VnetId: "vnet_1", ClientId: "client_1", Address: "10.0.0.3", Static: true}, nil)
+		f.mockVnetPeerRepo.EXPECT().SavePeer(ctx, gomock.Any()).Return(nil)
+		f.mockVnetPeerRepo.EXPECT().GetPeers(ctx, "vnet_1").Return(&[]model.VnetPeer{}, nil)
+		f.mockVnetRepo.EXPECT().UpdateVnet(ctx, vnet).Return(nil)
+		f.mockVnetEventService.EXPECT().Record(ctx, model.VnetEventUpdate, vnet).Return(nil)
+		f.mockVnetEventService.EXPECT().Notify()
+
+		_, err := f.vnetPeerService.RegisterPeer(ctx, "vnet_1", &v1.RegisterVnetPeerRequest{ClientId: "client_1", PublicKey: testPublicKey})
+		assert.NoError(t, err)
+	})
+
+	t.Run("plan exhausted", func(t *testing.T) {
+		f := setupVnetPeerService(t)
+		f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+		f.mockVnetPeerRepo.EXPECT().GetPeerByPublicKey(ctx, "vnet_1", testPublicKey).Return(nil, nil)
+		f.mockVnetPeerRepo.EXPECT().GetPeer(ctx, "vnet_1", "client_1").Return(nil, nil)
+		f.mockUserRepo.EXPECT().GetByID(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1}, nil)
+		f.mockVnetPeerRepo.EXPECT().CountPeers(ctx, "vnet_1").Return(int64(3), nil)
+
+		_, err := f.vnetPeerService.RegisterPeer(ctx, "vnet_1", &v1.RegisterVnetPeerRequest{ClientId: "client_1", PublicKey: testPublicKey})
+		assert.ErrorIs(t, err, v1.ErrVnetClientsFull)
+	})
+}
+
+func TestVnetPeerService_DeletePeer(t *testing.T) {
+	f := setupVnetPeerService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", Type: model.VnetTypeWireGuard, Revision: 3, PeerRevision: 2}
+
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	f.mockVnetPeerRepo.EXPECT().DeletePeer(ctx, "vnet_1", "client_1").Return(true, nil)
+	f.mockIpLeaseRepo.EXPECT().DeleteLeaseByClientId(ctx, "vnet_1", "client_1").Return(true, nil)
+	f.mockVnetPeerRepo.EXPECT().GetPeers(ctx, "vnet_1").Return(&[]model.VnetPeer{}, nil)
+	f.mockVnetRepo.EXPECT().UpdateVnet(ctx, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Record(ctx, model.VnetEventUpdate, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	err := f.vnetPeerService.DeletePeer(ctx, "vnet_1", "client_1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), vnet.PeerRevision)
+	assert.JSONEq(t, `{"revision":3,"peers":[]}`, vnet.Peers)
+
+	// 设备未登记
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	f.mockVnetPeerRepo.EXPECT().DeletePeer(ctx, "vnet_1", "client_2").Return(false, nil)
+
+	err = f.vnetPeerService.DeletePeer(ctx, "vnet_1", "client_2")
+	assert.ErrorIs(t, err, v1.ErrNotFound)
+}
+
+func TestCompilePeers_IPv6(t *testing.T) {
+	peers := service.CompilePeers([]model.VnetPeer{{ClientId: "client_1", PublicKey: testPublicKey, Address: "fd00::2"}}, 7)
+
+	assert.Equal(t, int64(7), peers.Revision)
+	assert.Equal(t, []string{"fd00::2/128"}, peers.Peers[0].AllowedIps)
+}
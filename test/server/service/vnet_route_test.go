@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	v1 "hyacinth-backend/api/v1"
+	"hyacinth-backend/internal/model"
+	"hyacinth-backend/internal/service"
+	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type vnetRouteFixture struct {
+	vnetRouteService     service.VnetRouteService
+	mockVnetRepo         *mock_repository.MockVnetRepository
+	mockVnetRouteRepo    *mock_repository.MockVnetRouteRepository
+	mockVnetEventService *mock_service.MockVnetEventService
+}
+
+func setupVnetRouteService(t *testing.T) *vnetRouteFixture {
+	ctrl := gomock.NewController(t)
+
+	f := &vnetRouteFixture{
+		mockVnetRepo:         mock_repository.NewMockVnetRepository(ctrl),
+		mockVnetRouteRepo:    mock_repository.NewMockVnetRouteRepository(ctrl),
+		mockVnetEventService: mock_service.NewMockVnetEventService(ctrl),
+	}
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	f.vnetRouteService = service.NewVnetRouteService(srv, f.mockVnetRepo, f.mockVnetRouteRepo, f.mockVnetEventService)
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return f
+}
+
+func TestVnetRouteService_AdvertiseRoute(t *testing.T) {
+	f := setupVnetRouteService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/24", Revision: 3}
+
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	f.mockVnetRouteRepo.EXPECT().GetRoutes(ctx, "vnet_1", "").Return(&[]model.VnetRoute{}, nil)
+	f.mockVnetRouteRepo.EXPECT().CreateRoute(ctx, gomock.Any()).Return(nil)
+
+	// 前缀按网络地址保存
+	item, err := f.vnetRouteService.AdvertiseRoute(ctx, "vnet_1", &v1.AdvertiseRouteRequest{ClientId: "client_1", Prefix: "192.168.1.10/24"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.1.0/24", item.Prefix)
+	assert.Equal(t, model.VnetRoutePending, item.Status)
+	assert.False(t, item.Exit)
+	// 待批准的路由不会下发给节点
+	assert.False(t, vnet.NeedUpdate)
+	assert.Equal(t, int64(3), vnet.Revision)
+}
+
+func TestVnetRouteService_AdvertiseRoute_Rejected(t *testing.T) {
+	f := setupVnetRouteService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/24"}
+
+	// 前缀格式错误时不会访问数据库
+	_, err := f.vnetRouteService.AdvertiseRoute(ctx, "vnet_1", &v1.AdvertiseRouteRequest{ClientId: "client_1", Prefix: "192.168.1.0"})
+	assert.ErrorIs(t, err, v1.ErrInvalidRoute)
+
+	// 与虚拟网络网段重叠
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil).Times(2)
+	f.mockVnetRouteRepo.EXPECT().GetRoutes(ctx, "vnet_1", "").Return(&[]model.VnetRoute{
+		{RouteId: "route_1", VnetId: "vnet_1", ClientId: "client_2", Prefix: "192.168.0.0/16", Status: model.VnetRouteApproved},
+	}, nil).Times(2)
+
+	_, err = f.vnetRouteService.AdvertiseRoute(ctx, "vnet_1", &v1.AdvertiseRouteRequest{ClientId: "client_1", Prefix: "10.0.0.128/25"})
+	assert.ErrorIs(t, err, v1.ErrRouteConflict)
+
+	// 与其他设备已批准的子网路由重叠
+	_, err = f.vnetRouteService.AdvertiseRoute(ctx, "vnet_1", &v1.AdvertiseRouteRequest{ClientId: "client_1", Prefix: "192.168.1.0/24"})
+	assert.ErrorIs(t, err, v1.ErrRouteConflict)
+}
+
+func TestVnetRouteService_ApproveRoute(t *testing.T) {
+	f := setupVnetRouteService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/24", Revision: 3, RouteRevision: 1}
+	route := &model.VnetRoute{RouteId: "route_2", VnetId: "vnet_1", ClientId: "client_2", Prefix: "0.0.0.0/0", Exit: true, Status: model.VnetRoutePending}
+	approved := []model.VnetRoute{
+		{RouteId: "route_1", VnetId: "vnet_1", ClientId: "client_1", Prefix: "192.168.1.0/24", Status: model.VnetRouteApproved},
+		// 另一地址族的出口节点不冲突
+		{RouteId: "route_3", VnetId: "vnet_1", ClientId: "client_3", Prefix: "::/0", Exit: true, Status: model.VnetRouteApproved},
+	}
+
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	f.mockVnetRouteRepo.EXPECT().GetRoute(ctx, "vnet_1", "route_2").Return(route, nil)
+	f.mockVnetRouteRepo.EXPECT().GetRoutes(ctx, "vnet_1", model.VnetRouteApproved).Return(&approved, nil)
+	f.mockVnetRouteRepo.EXPECT().UpdateRoute(ctx, route).DoAndReturn(func(ctx context.Context, route *model.VnetRoute) error {
+		approved = append(approved, *route)
+		return nil
+	})
+	f.mockVnetRouteRepo.EXPECT().GetRoutes(ctx, "vnet_1", model.VnetRouteApproved).DoAndReturn(func(ctx context.Context, vnetId string, status string) (*[]model.VnetRoute, error) {
+		return &approved, nil
+	})
+	f.mockVnetRepo.EXPECT().UpdateVnet(ctx, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Record(ctx, model.VnetEventUpdate, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	item, err := f.vnetRouteService.ApproveRoute(ctx, "vnet_1", "route_2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.VnetRouteApproved, item.Status)
+	assert.NotEmpty(t, item.ApprovedAt)
+
+	assert.True(t, vnet.NeedUpdate)
+	assert.Equal(t, int64(4), vnet.Revision)
+	assert.Equal(t, int64(2), vnet.RouteRevision)
+	var routes v1.NodeVnetRoutes
+	assert.NoError(t, json.Unmarshal([]byte(vnet.Routes), &routes))
+	assert.Equal(t, int64(2), routes.Revision)
+	assert.Len(t, routes.Routes, 3)
+	assert.Equal(t, v1.NodeRoute{Prefix: "0.0.0.0/0", ClientId: "client_2", Exit: true}, routes.Routes[2])
+}
+
+func TestVnetRouteService_ApproveRoute_ExitConflict(t *testing.T) {
+	f := setupVnetRouteService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", IpRange: "10.0.0.0/24"}
+	route := &model.VnetRoute{RouteId: "route_2", VnetId: "vnet_1", ClientId: "client_2", Prefix: "0.0.0.0/0", Exit: true, Status: model.VnetRoutePending}
+
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil)
+	f.mockVnetRouteRepo.EXPECT().GetRoute(ctx, "vnet_1", "route_2").Return(route, nil)
+	// 每个地址族只能有一个出口节点
+	f.mockVnetRouteRepo.EXPECT().GetRoutes(ctx, "vnet_1", model.VnetRouteApproved).Return(&[]model.VnetRoute{
+		{RouteId: "route_1", VnetId: "vnet_1", ClientId: "client_1", Prefix: "0.0.0.0/0", Exit: true, Status: model.VnetRouteApproved},
+	}, nil)
+
+	_, err := f.vnetRouteService.ApproveRoute(ctx, "vnet_1", "route_2")
+
+	assert.ErrorIs(t, err, v1.ErrRouteConflict)
+	assert.False(t, vnet.NeedUpdate)
+}
+
+func TestVnetRouteService_DeleteRoute(t *testing.T) {
+	f := setupVnetRouteService(t)
+
+	ctx := context.Background()
+	vnet := &model.Vnet{VnetId: "vnet_1", Revision: 3, RouteRevision: 2}
+
+	// 删除待批准的路由不会重新下发
+	f.mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(vnet, nil).Times(2)
+	f.mockVnetRouteRepo.EXPECT().GetRoute(ctx, "vnet_1", "route_1").Return(&model.VnetRoute{RouteId: "route_1", Status: model.VnetRoutePending}, nil)
+	f.mockVnetRouteRepo.EXPECT().DeleteRoute(ctx, "vnet_1", "route_1").Return(true, nil)
+
+	assert.NoError(t, f.vnetRouteService.DeleteRoute(ctx, "vnet_1", "route_1"))
+	assert.Equal(t, int64(3), vnet.Revision)
+
+	// 删除已批准的路由时下发新的路由表
+	f.mockVnetRouteRepo.EXPECT().GetRoute(ctx, "vnet_1", "route_2").Return(&model.VnetRoute{RouteId: "route_2", Status: model.VnetRouteApproved}, nil)
+	f.mockVnetRouteRepo.EXPECT().DeleteRoute(ctx, "vnet_1", "route_2").Return(true, nil)
+	f.mockVnetRouteRepo.EXPECT().GetRoutes(ctx, "vnet_1", model.VnetRouteApproved).Return(&[]model.VnetRoute{}, nil)
+	f.mockVnetRepo.EXPECT().UpdateVnet(ctx, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Record(ctx, model.VnetEventUpdate, vnet).Return(nil)
+	f.mockVnetEventService.EXPECT().Notify()
+
+	assert.NoError(t, f.vnetRouteService.DeleteRoute(ctx, "vnet_1", "route_2"))
+	assert.True(t, vnet.NeedUpdate)
+	assert.Equal(t, int64(4), vnet.Revision)
+	assert.Equal(t, int64(3), vnet.RouteRevision)
+	assert.Equal(t, `{"revision":3,"routes":[]}`, vnet.Routes)
+}
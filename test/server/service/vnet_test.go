@@ -8,7 +8,9 @@ import (
 	v1 "hyacinth-backend/api/v1"
 	"hyacinth-backend/internal/model"
 	"hyacinth-backend/internal/service"
+	"hyacinth-backend/pkg/vnetpass"
 	mock_repository "hyacinth-backend/test/mocks/repository"
+	mock_service "hyacinth-backend/test/mocks/service"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -20,8 +22,25 @@ func setupVnetService(t *testing.T) (service.VnetService, *mock_repository.MockV
 
 	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
 	mockTm := mock_repository.NewMockTransaction(ctrl)
+	mockVnetEventService := mock_service.NewMockVnetEventService(ctrl)
 	srv := service.NewService(mockTm, logger, sf, j)
-	vnetService := service.NewVnetService(srv, mockVnetRepo)
+	mockIpamService := mock_service.NewMockIpamService(ctrl)
+	mockVnetRouteRepo := mock_repository.NewMockVnetRouteRepository(ctrl)
+	vnetService := service.NewVnetService(srv, mockVnetRepo, mock_repository.NewMockUserRepository(ctrl), mockVnetEventService, mockIpamService, mock_repository.NewMockVnetCollaboratorRepository(ctrl), mock_repository.NewMockOrganizationRepository(ctrl), mockVnetRouteRepo, newTestPlanService(ctrl, testPlans()))
+
+	// 网段校验与分配由 IpamService 负责，这里原样返回
+	mockIpamService.EXPECT().ResolveIpRange(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, userId string, orgId string, vnetId string, ipRange string) (string, error) {
+		return ipRange, nil
+	}).AnyTimes()
+	mockIpamService.EXPECT().MigrateLeases(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockVnetRouteRepo.EXPECT().GetRoutes(gomock.Any(), gomock.Any(), model.VnetRouteApproved).Return(&[]model.VnetRoute{}, nil).AnyTimes()
+
+	// 虚拟网络的修改均在事务中执行并记录变更事件
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+	mockVnetEventService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockVnetEventService.EXPECT().Notify().AnyTimes()
 
 	return vnetService, mockVnetRepo, mockTm
 }
@@ -39,7 +58,7 @@ func TestVnetService_GetVnetByUserId(t *testing.T) {
 			Comment:       "测试虚拟网络1",
 			Enabled:       true,
 			Token:         "token_1",
-			Password:      "password_1",
+			PasswordHash:  "hash_1",
 			IpRange:       "192.168.1.0/24",
 			EnableDHCP:    true,
 			ClientsLimit:  10,
@@ -52,7 +71,7 @@ func TestVnetService_GetVnetByUserId(t *testing.T) {
 			Comment:       "测试虚拟网络2",
 			Enabled:       false,
 			Token:         "token_2",
-			Password:      "password_2",
+			PasswordHash:  "hash_2",
 			IpRange:       "192.168.2.0/24",
 			EnableDHCP:    false,
 			ClientsLimit:  5,
@@ -103,7 +122,7 @@ func TestVnetService_GetVnetByVnetId(t *testing.T) {
 		Comment:       "测试虚拟网络",
 		Enabled:       true,
 		Token:         "test_token",
-		Password:      "test_password",
+		PasswordHash:  "test_hash",
 		IpRange:       "192.168.1.0/24",
 		EnableDHCP:    true,
 		ClientsLimit:  10,
@@ -164,7 +183,10 @@ func TestVnetService_CreateVnet(t *testing.T) {
 		assert.Equal(t, req.Comment, vnet.Comment)
 		assert.Equal(t, req.Enabled, vnet.Enabled)
 		assert.Equal(t, req.Token, vnet.Token)
-		assert.Equal(t, req.Password, vnet.Password)
+		// 只保存密码校验值
+		verifier, err := vnetpass.Parse(vnet.PasswordHash)
+		assert.NoError(t, err)
+		assert.True(t, verifier.VerifyPassword(req.Password))
 		assert.Equal(t, req.IpRange, vnet.IpRange)
 		assert.Equal(t, req.EnableDHCP, vnet.EnableDHCP)
 		assert.Equal(t, req.ClientsLimit, vnet.ClientsLimit)
@@ -227,23 +249,26 @@ func TestVnetService_UpdateVnet(t *testing.T) {
 		Comment:       "旧的虚拟网络",
 		Enabled:       true,
 		Token:         "old_token",
-		Password:      "old_password",
+		PasswordHash:  "old_hash",
 		IpRange:       "192.168.1.0/24",
 		EnableDHCP:    true,
 		ClientsLimit:  10,
-		ClientsOnline: 5,
+		ClientsOnline: 0, // 没有客户端在线时才允许更换网段
 		NeedUpdate:    false,
 	}
 
-	// Mock期望：先获取现有虚拟网络
-	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, req.VnetId).Return(existingVnet, nil)
+	// Mock期望：先加锁获取现有虚拟网络
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, req.VnetId).Return(existingVnet, nil)
 
 	// Mock期望：更新虚拟网络
 	mockVnetRepo.EXPECT().UpdateVnet(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, vnet *model.Vnet) error {
 		assert.Equal(t, req.Comment, vnet.Comment)
 		assert.Equal(t, req.Enabled, vnet.Enabled)
 		assert.Equal(t, req.Token, vnet.Token)
-		assert.Equal(t, req.Password, vnet.Password)
+		// 只保存密码校验值
+		verifier, err := vnetpass.Parse(vnet.PasswordHash)
+		assert.NoError(t, err)
+		assert.True(t, verifier.VerifyPassword(req.Password))
 		assert.Equal(t, req.IpRange, vnet.IpRange)
 		assert.Equal(t, req.EnableDHCP, vnet.EnableDHCP)
 		assert.Equal(t, req.ClientsLimit, vnet.ClientsLimit)
@@ -256,6 +281,57 @@ func TestVnetService_UpdateVnet(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestVnetService_UpdateVnet_IpRangeInUse(t *testing.T) {
+	vnetService, mockVnetRepo, _ := setupVnetService(t)
+
+	ctx := context.Background()
+	req := &v1.UpdateVnetRequest{
+		VnetProfile: v1.VnetProfile{VnetId: "vnet_123", Enabled: true, IpRange: "192.168.9.0/24"},
+	}
+
+	// 有客户端在线时不允许更换网段，仅修改主机位视为未变化
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_123").Return(&model.Vnet{VnetId: "vnet_123", Enabled: true, IpRange: "192.168.1.0/24", ClientsOnline: 2}, nil)
+	err := vnetService.UpdateVnet(ctx, req)
+	assert.Equal(t, v1.ErrIpRangeInUse, err)
+
+	req.IpRange = "192.168.1.1/24"
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_123").Return(&model.Vnet{VnetId: "vnet_123", Enabled: true, IpRange: "192.168.1.0/24", ClientsOnline: 2}, nil)
+	mockVnetRepo.EXPECT().UpdateVnet(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, vnet *model.Vnet) error {
+		assert.Equal(t, "192.168.1.0/24", vnet.IpRange)
+		return nil
+	})
+	err = vnetService.UpdateVnet(ctx, req)
+	assert.NoError(t, err)
+}
+
+func TestVnetService_UpdateVnet_RouteConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	mockIpamService := mock_service.NewMockIpamService(ctrl)
+	mockVnetRouteRepo := mock_repository.NewMockVnetRouteRepository(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	vnetService := service.NewVnetService(srv, mockVnetRepo, mock_repository.NewMockUserRepository(ctrl), mock_service.NewMockVnetEventService(ctrl), mockIpamService, mock_repository.NewMockVnetCollaboratorRepository(ctrl), mock_repository.NewMockOrganizationRepository(ctrl), mockVnetRouteRepo, newTestPlanService(ctrl, testPlans()))
+
+	ctx := context.Background()
+	mockTm.EXPECT().Transaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_123").Return(&model.Vnet{VnetId: "vnet_123", UserId: "user_123", OrgId: "org_1", IpRange: "192.168.1.0/24"}, nil)
+	// 组织的虚拟网络按组织范围检查网段重叠
+	mockIpamService.EXPECT().ResolveIpRange(ctx, "user_123", "org_1", "vnet_123", "10.1.0.0/16").Return("10.1.0.0/16", nil)
+	// 新网段覆盖了已批准的子网路由，不迁移租约也不保存
+	mockVnetRouteRepo.EXPECT().GetRoutes(ctx, "vnet_123", model.VnetRouteApproved).Return(&[]model.VnetRoute{
+		{RouteId: "route_1", Prefix: "0.0.0.0/0", Exit: true, Status: model.VnetRouteApproved},
+		{RouteId: "route_2", Prefix: "10.1.2.0/24", Status: model.VnetRouteApproved},
+	}, nil)
+
+	err := vnetService.UpdateVnet(ctx, &v1.UpdateVnetRequest{VnetProfile: v1.VnetProfile{VnetId: "vnet_123", IpRange: "10.1.0.0/16"}})
+
+	assert.Equal(t, v1.ErrRouteConflict, err)
+}
+
 func TestVnetService_UpdateVnet_VnetNotFound(t *testing.T) {
 	vnetService, mockVnetRepo, _ := setupVnetService(t)
 
@@ -274,7 +350,7 @@ func TestVnetService_UpdateVnet_VnetNotFound(t *testing.T) {
 	}
 
 	// Mock期望：虚拟网络不存在
-	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, req.VnetId).Return(nil, gorm.ErrRecordNotFound)
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, req.VnetId).Return(nil, gorm.ErrRecordNotFound)
 
 	err := vnetService.UpdateVnet(ctx, req)
 
@@ -291,17 +367,17 @@ func TestVnetService_DeleteVnet(t *testing.T) {
 	}
 
 	existingVnet := &model.Vnet{
-		VnetId:   req.VnetID,
-		UserId:   "user_123",
-		Comment:  "要删除的虚拟网络",
-		Enabled:  true,
-		Token:    "token",
-		Password: "password",
-		IpRange:  "192.168.7.0/24",
+		VnetId:       req.VnetID,
+		UserId:       "user_123",
+		Comment:      "要删除的虚拟网络",
+		Enabled:      true,
+		Token:        "token",
+		PasswordHash: "hash",
+		IpRange:      "192.168.7.0/24",
 	}
 
 	// Mock期望：先获取要删除的虚拟网络
-	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, req.VnetID).Return(existingVnet, nil)
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, req.VnetID).Return(existingVnet, nil)
 
 	// Mock期望：删除虚拟网络
 	mockVnetRepo.EXPECT().DeleteVnet(ctx, req.VnetID).Return(nil)
@@ -320,7 +396,7 @@ func TestVnetService_DeleteVnet_VnetNotFound(t *testing.T) {
 	}
 
 	// Mock期望：虚拟网络不存在
-	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, req.VnetID).Return(nil, gorm.ErrRecordNotFound)
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, req.VnetID).Return(nil, gorm.ErrRecordNotFound)
 
 	err := vnetService.DeleteVnet(ctx, req)
 
@@ -337,18 +413,18 @@ func TestVnetService_EnableVnet(t *testing.T) {
 	}
 
 	existingVnet := &model.Vnet{
-		VnetId:     req.VnetID,
-		UserId:     "user_123",
-		Comment:    "要启用的虚拟网络",
-		Enabled:    false, // 当前是禁用状态
-		Token:      "token",
-		Password:   "password",
-		IpRange:    "192.168.8.0/24",
-		NeedUpdate: false,
+		VnetId:       req.VnetID,
+		UserId:       "user_123",
+		Comment:      "要启用的虚拟网络",
+		Enabled:      false, // 当前是禁用状态
+		Token:        "token",
+		PasswordHash: "hash",
+		IpRange:      "192.168.8.0/24",
+		NeedUpdate:   false,
 	}
 
 	// Mock期望：先获取虚拟网络
-	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, req.VnetID).Return(existingVnet, nil)
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, req.VnetID).Return(existingVnet, nil)
 
 	// Mock期望：更新虚拟网络状态
 	mockVnetRepo.EXPECT().UpdateVnet(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, vnet *model.Vnet) error {
@@ -371,18 +447,18 @@ func TestVnetService_DisableVnet(t *testing.T) {
 	}
 
 	existingVnet := &model.Vnet{
-		VnetId:     req.VnetID,
-		UserId:     "user_123",
-		Comment:    "要禁用的虚拟网络",
-		Enabled:    true, // 当前是启用状态
-		Token:      "token",
-		Password:   "password",
-		IpRange:    "192.168.9.0/24",
-		NeedUpdate: false,
+		VnetId:       req.VnetID,
+		UserId:       "user_123",
+		Comment:      "要禁用的虚拟网络",
+		Enabled:      true, // 当前是启用状态
+		Token:        "token",
+		PasswordHash: "hash",
+		IpRange:      "192.168.9.0/24",
+		NeedUpdate:   false,
 	}
 
 	// Mock期望：先获取虚拟网络
-	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, req.VnetID).Return(existingVnet, nil)
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, req.VnetID).Return(existingVnet, nil)
 
 	// Mock期望：更新虚拟网络状态
 	mockVnetRepo.EXPECT().UpdateVnet(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, vnet *model.Vnet) error {
@@ -523,3 +599,243 @@ func TestVnetService_GetRunningVnetCount_Error(t *testing.T) {
 	assert.Equal(t, 0, count)
 	assert.Equal(t, "query error", err.Error())
 }
+
+func TestVnetService_DisableVnet_RecordsEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	mockVnetEventService := mock_service.NewMockVnetEventService(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	vnetService := service.NewVnetService(srv, mockVnetRepo, mock_repository.NewMockUserRepository(ctrl), mockVnetEventService, mock_service.NewMockIpamService(ctrl), mock_repository.NewMockVnetCollaboratorRepository(ctrl), mock_repository.NewMockOrganizationRepository(ctrl), mock_repository.NewMockVnetRouteRepository(ctrl), newTestPlanService(ctrl, testPlans()))
+
+	ctx := context.Background()
+	existingVnet := &model.Vnet{VnetId: "vnet_1", Enabled: true, Revision: 2}
+
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(existingVnet, nil)
+	gomock.InOrder(
+		mockTm.EXPECT().Transaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}),
+		mockVnetEventService.EXPECT().Notify(),
+	)
+	mockVnetRepo.EXPECT().UpdateVnet(ctx, existingVnet).Return(nil)
+	mockVnetEventService.EXPECT().Record(ctx, model.VnetEventDisable, gomock.Any()).DoAndReturn(func(ctx context.Context, eventType string, vnet *model.Vnet) error {
+		assert.False(t, vnet.Enabled)
+		assert.True(t, vnet.NeedUpdate)
+		assert.Equal(t, int64(3), vnet.Revision)
+		return nil
+	})
+
+	err := vnetService.DisableVnet(ctx, &v1.DisableVnetRequest{VnetID: "vnet_1"})
+
+	assert.NoError(t, err)
+}
+
+func TestVnetService_DeleteVnet_RecordEventFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	mockVnetEventService := mock_service.NewMockVnetEventService(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	vnetService := service.NewVnetService(srv, mockVnetRepo, mock_repository.NewMockUserRepository(ctrl), mockVnetEventService, mock_service.NewMockIpamService(ctrl), mock_repository.NewMockVnetCollaboratorRepository(ctrl), mock_repository.NewMockOrganizationRepository(ctrl), mock_repository.NewMockVnetRouteRepository(ctrl), newTestPlanService(ctrl, testPlans()))
+
+	ctx := context.Background()
+
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", Revision: 1}, nil)
+	mockTm.EXPECT().Transaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mockVnetRepo.EXPECT().DeleteVnet(ctx, "vnet_1").Return(nil)
+	mockVnetEventService.EXPECT().Record(ctx, model.VnetEventDelete, gomock.Any()).Return(errors.New("db error"))
+
+	// 事件记录失败时事务回滚，且不会唤醒订阅者
+	err := vnetService.DeleteVnet(ctx, &v1.DeleteVnetRequest{VnetID: "vnet_1"})
+
+	assert.Error(t, err)
+}
+
+func setupVnetServiceWithUser(t *testing.T) (service.VnetService, *mock_repository.MockVnetRepository, *mock_repository.MockUserRepository, *mock_service.MockVnetEventService) {
+	ctrl := gomock.NewController(t)
+
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockUserRepo := mock_repository.NewMockUserRepository(ctrl)
+	mockTm := mock_repository.NewMockTransaction(ctrl)
+	mockVnetEventService := mock_service.NewMockVnetEventService(ctrl)
+	srv := service.NewService(mockTm, logger, sf, j)
+	vnetService := service.NewVnetService(srv, mockVnetRepo, mockUserRepo, mockVnetEventService, mock_service.NewMockIpamService(ctrl), mock_repository.NewMockVnetCollaboratorRepository(ctrl), mock_repository.NewMockOrganizationRepository(ctrl), mock_repository.NewMockVnetRouteRepository(ctrl), newTestPlanService(ctrl, testPlans()))
+
+	mockTm.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	return vnetService, mockVnetRepo, mockUserRepo, mockVnetEventService
+}
+
+func TestVnetService_SyncTrafficSuspension_Exhausted(t *testing.T) {
+	vnetService, mockVnetRepo, mockUserRepo, mockVnetEventService := setupVnetServiceWithUser(t)
+
+	ctx := context.Background()
+	vnets := []model.Vnet{
+		{VnetId: "vnet_1", UserId: "user_1", Enabled: true},
+		{VnetId: "vnet_2", UserId: "user_1", Enabled: false},
+	}
+
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1, RemainingTraffic: 0}, nil)
+	mockVnetRepo.EXPECT().GetVnetByUserId(ctx, "user_1").Return(&vnets, nil)
+	// 修改前加锁重新读取，基于最新记录保存
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{VnetId: "vnet_1", UserId: "user_1", Enabled: true, NodeId: "relay-1"}, nil)
+	mockVnetRepo.EXPECT().UpdateVnet(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, vnet *model.Vnet) error {
+		assert.Equal(t, "relay-1", vnet.NodeId)
+		// 仅停用运行中的虚拟网络，手动停用的不会被标记
+		assert.Equal(t, "vnet_1", vnet.VnetId)
+		assert.False(t, vnet.Enabled)
+		assert.Equal(t, model.VnetSuspendTrafficExhausted, vnet.SuspendReason)
+		return nil
+	})
+	mockVnetEventService.EXPECT().Record(ctx, model.VnetEventDisable, gomock.Any()).Return(nil)
+	mockVnetEventService.EXPECT().Notify()
+
+	err := vnetService.SyncTrafficSuspension(ctx, "user_1")
+
+	assert.NoError(t, err)
+	assert.Empty(t, vnets[1].SuspendReason)
+}
+
+func TestVnetService_SyncTrafficSuspension_Resume(t *testing.T) {
+	vnetService, mockVnetRepo, mockUserRepo, mockVnetEventService := setupVnetServiceWithUser(t)
+
+	ctx := context.Background()
+	vnets := []model.Vnet{
+		{Model: gorm.Model{ID: 2}, VnetId: "vnet_2", UserId: "user_1", SuspendReason: model.VnetSuspendTrafficExhausted},
+		{Model: gorm.Model{ID: 1}, VnetId: "vnet_1", UserId: "user_1", SuspendReason: model.VnetSuspendTrafficExhausted},
+		{Model: gorm.Model{ID: 3}, VnetId: "vnet_3", UserId: "user_1"},
+	}
+
+	// 普通用户最多运行 1 个虚拟网络，按创建顺序恢复
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1, RemainingTraffic: 1024}, nil)
+	mockVnetRepo.EXPECT().GetVnetByUserId(ctx, "user_1").Return(&vnets, nil)
+	mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, "vnet_1").Return(&model.Vnet{Model: gorm.Model{ID: 1}, VnetId: "vnet_1", UserId: "user_1", SuspendReason: model.VnetSuspendTrafficExhausted}, nil)
+	mockVnetRepo.EXPECT().UpdateVnet(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, vnet *model.Vnet) error {
+		assert.Equal(t, "vnet_1", vnet.VnetId)
+		assert.True(t, vnet.Enabled)
+		assert.Empty(t, vnet.SuspendReason)
+		return nil
+	})
+	mockVnetEventService.EXPECT().Record(ctx, model.VnetEventEnable, gomock.Any()).Return(nil)
+	mockVnetEventService.EXPECT().Notify()
+
+	err := vnetService.SyncTrafficSuspension(ctx, "user_1")
+
+	assert.NoError(t, err)
+}
+
+func TestVnetService_SyncTrafficSuspension_NoChange(t *testing.T) {
+	vnetService, mockVnetRepo, mockUserRepo, _ := setupVnetServiceWithUser(t)
+
+	ctx := context.Background()
+
+	mockUserRepo.EXPECT().GetByIDForUpdate(ctx, "user_1").Return(&model.User{UserId: "user_1", UserGroup: 1, RemainingTraffic: 1024}, nil)
+	mockVnetRepo.EXPECT().GetVnetByUserId(ctx, "user_1").Return(&[]model.Vnet{{VnetId: "vnet_1", Enabled: true}}, nil)
+
+	err := vnetService.SyncTrafficSuspension(ctx, "user_1")
+
+	assert.NoError(t, err)
+}
+
+func TestVnetService_ApplyPlanLimits(t *testing.T) {
+	vnetService, mockVnetRepo, _, mockVnetEventService := setupVnetServiceWithUser(t)
+
+	ctx := context.Background()
+	vnets := []model.Vnet{
+		{Model: gorm.Model{ID: 1}, VnetId: "vnet_1", UserId: "user_1", Enabled: true, ClientsLimit: 3},
+		{Model: gorm.Model{ID: 2}, VnetId: "vnet_2", UserId: "user_1", Enabled: true, ClientsLimit: 10},
+		{Model: gorm.Model{ID: 3}, VnetId: "vnet_3", UserId: "user_1", Enabled: true, ClientsLimit: 3},
+		{Model: gorm.Model{ID: 4}, VnetId: "vnet_4", UserId: "user_1", Enabled: false, ClientsLimit: 3},
+		{Model: gorm.Model{ID: 5}, VnetId: "vnet_5", UserId: "user_1", OrgId: "org_1", Enabled: true, ClientsLimit: 50},
+	}
+	free := &testPlans()[0]
+
+	mockVnetRepo.EXPECT().GetVnetByUserId(ctx, "user_1").Return(&vnets, nil)
+	// vnet_2 最近使用过，vnet_1 与 vnet_3 中 vnet_1 较早使用过，vnet_3 从未使用
+	mockVnetRepo.EXPECT().GetVnetIdsByLastUsage(ctx, []string{"vnet_1", "vnet_2", "vnet_3"}).Return([]string{"vnet_2", "vnet_1"}, nil)
+	// 只加锁重新读取需要修改的虚拟网络
+	for _, vnet := range vnets[:3] {
+		fresh := vnet
+		mockVnetRepo.EXPECT().GetVnetByVnetIdForUpdate(ctx, vnet.VnetId).Return(&fresh, nil)
+	}
+	updated := map[string]model.Vnet{}
+	mockVnetRepo.EXPECT().UpdateVnet(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, vnet *model.Vnet) error {
+		assert.True(t, vnet.NeedUpdate)
+		updated[vnet.VnetId] = *vnet
+		return nil
+	}).Times(3)
+	mockVnetEventService.EXPECT().Record(ctx, model.VnetEventDisable, gomock.Any()).Return(nil).Times(2)
+	mockVnetEventService.EXPECT().Record(ctx, model.VnetEventUpdate, gomock.Any()).Return(nil)
+
+	result, err := vnetService.ApplyPlanLimits(ctx, "user_1", "", free)
+
+	assert.NoError(t, err)
+	// 普通用户最多运行 1 个虚拟网络，保留最近使用的 vnet_2，并将其在线人数上限调整为 3
+	assert.True(t, updated["vnet_2"].Enabled)
+	assert.Equal(t, 3, updated["vnet_2"].ClientsLimit)
+	for _, vnetId := range []string{"vnet_1", "vnet_3"} {
+		assert.False(t, updated[vnetId].Enabled)
+		assert.Equal(t, model.VnetSuspendPlanLimit, updated[vnetId].SuspendReason)
+	}
+	assert.Len(t, result.Disabled, 2)
+	if assert.Len(t, result.Clamped, 1) {
+		assert.Equal(t, "vnet_2", result.Clamped[0].VnetId)
+	}
+}
+
+func TestVnetService_Authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockVnetRepo := mock_repository.NewMockVnetRepository(ctrl)
+	mockVnetCollaboratorRepo := mock_repository.NewMockVnetCollaboratorRepository(ctrl)
+	mockOrganizationRepo := mock_repository.NewMockOrganizationRepository(ctrl)
+	srv := service.NewService(mock_repository.NewMockTransaction(ctrl), logger, sf, j)
+	vnetService := service.NewVnetService(srv, mockVnetRepo, mock_repository.NewMockUserRepository(ctrl), mock_service.NewMockVnetEventService(ctrl), mock_service.NewMockIpamService(ctrl), mockVnetCollaboratorRepo, mockOrganizationRepo, mock_repository.NewMockVnetRouteRepository(ctrl), newTestPlanService(ctrl, testPlans()))
+
+	ctx := context.Background()
+	existingVnet := &model.Vnet{VnetId: "vnet_1", UserId: "owner"}
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_1").Return(existingVnet, nil).AnyTimes()
+	mockVnetCollaboratorRepo.EXPECT().GetCollaborator(ctx, "vnet_1", "bob").Return(&model.VnetCollaborator{VnetId: "vnet_1", UserId: "bob", Role: model.VnetRoleOperator}, nil).AnyTimes()
+	mockVnetCollaboratorRepo.EXPECT().GetCollaborator(ctx, "vnet_1", "eve").Return(nil, nil)
+
+	vnet, role, err := vnetService.Authorize(ctx, "vnet_1", "owner", model.VnetRoleOwner)
+	assert.NoError(t, err)
+	assert.Equal(t, existingVnet, vnet)
+	assert.Equal(t, model.VnetRoleOwner, role)
+
+	_, role, err = vnetService.Authorize(ctx, "vnet_1", "bob", model.VnetRoleViewer)
+	assert.NoError(t, err)
+	assert.Equal(t, model.VnetRoleOperator, role)
+
+	// 操作员不能执行管理员的操作
+	_, _, err = vnetService.Authorize(ctx, "vnet_1", "bob", model.VnetRoleAdmin)
+	assert.ErrorIs(t, err, v1.ErrForbidden)
+
+	_, _, err = vnetService.Authorize(ctx, "vnet_1", "eve", model.VnetRoleViewer)
+	assert.ErrorIs(t, err, v1.ErrForbidden)
+
+	// 组织的虚拟网络：创建者不再是所有者，按组织角色计算
+	orgVnet := &model.Vnet{VnetId: "vnet_2", UserId: "carol", OrgId: "org_1"}
+	mockVnetRepo.EXPECT().GetVnetByVnetId(ctx, "vnet_2").Return(orgVnet, nil).AnyTimes()
+	mockOrganizationRepo.EXPECT().GetMember(ctx, "org_1", "carol").Return(&model.OrganizationMember{OrgId: "org_1", UserId: "carol", Role: model.OrgRoleMember}, nil).AnyTimes()
+	mockOrganizationRepo.EXPECT().GetMember(ctx, "org_1", "dave").Return(&model.OrganizationMember{OrgId: "org_1", UserId: "dave", Role: model.OrgRoleAdmin}, nil)
+	mockVnetCollaboratorRepo.EXPECT().GetCollaborator(ctx, "vnet_2", gomock.Any()).Return(nil, nil).AnyTimes()
+
+	_, role, err = vnetService.Authorize(ctx, "vnet_2", "carol", model.VnetRoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, model.VnetRoleAdmin, role)
+
+	_, _, err = vnetService.Authorize(ctx, "vnet_2", "carol", model.VnetRoleOwner)
+	assert.ErrorIs(t, err, v1.ErrForbidden)
+
+	_, role, err = vnetService.Authorize(ctx, "vnet_2", "dave", model.VnetRoleOwner)
+	assert.NoError(t, err)
+	assert.Equal(t, model.VnetRoleOwner, role)
+}